@@ -0,0 +1,416 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core/quorum"
+	"github.com/zees-dev/blockless-avs/core/validate"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// TaskSummary is the admin api representation of an in-flight task.
+type TaskSummary struct {
+	TaskIndex            types.TaskIndex   `json:"taskIndex"`
+	Symbol               string            `json:"symbol"`
+	ReferenceBlockNumber types.BlockNumber `json:"referenceBlockNumber"`
+	CreatedAt            time.Time         `json:"createdAt"`
+	ExpiryBlockNumber    types.BlockNumber `json:"expiryBlockNumber"`
+	NumResponses         int               `json:"numResponses"`
+}
+
+// CreateTaskRequest is the body of a task created by an external task generator.
+type CreateTaskRequest struct {
+	Symbol string `json:"symbol" validate:"required,max=64,slug"`
+	// quorums which must sign the task (default: all the avs quorums)
+	QuorumNumbers []int `json:"quorumNumbers,omitempty" validate:"max=192"`
+	// percentage of the stake of each quorum which must sign the task (default: 100)
+	QuorumThresholdPercentage uint8 `json:"quorumThresholdPercentage,omitempty" validate:"max=100"`
+}
+
+// QuorumStakeProgress is the stake which signed a response digest in a single quorum.
+type QuorumStakeProgress struct {
+	QuorumNumber     sdktypes.QuorumNum `json:"quorumNumber"`
+	SignedStake      *big.Int           `json:"signedStake"`
+	TotalStake       *big.Int           `json:"totalStake"`
+	SignedPercentage float64            `json:"signedPercentage"`
+	ThresholdPercent uint8              `json:"thresholdPercentage"`
+}
+
+// DigestProgress is the signing progress of a single response digest.
+type DigestProgress struct {
+	Digest    string                `json:"digest"`
+	Operators []string              `json:"operators"`
+	Quorums   []QuorumStakeProgress `json:"quorums"`
+}
+
+// TaskDetails is the admin api representation of a single task with its signing progress.
+type TaskDetails struct {
+	TaskSummary
+	// response digest signed by each operator, keyed by operator id
+	OperatorDigests map[string]string `json:"operatorDigests"`
+	Digests         []DigestProgress  `json:"digests"`
+}
+
+func (agg *Aggregator) startAdminServer(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", agg.handleListTasks)
+	if agg.externalTaskGeneration {
+		mux.HandleFunc("POST /tasks", agg.handleCreateTask)
+	}
+	mux.HandleFunc("GET /tasks/events", agg.handleTaskEvents)
+	mux.HandleFunc("GET /tasks/{index}", agg.handleGetTask)
+	mux.HandleFunc("POST /tasks/{index}/expire", agg.handleExpireTask)
+	mux.HandleFunc("GET /operators/rejections", agg.handleListRejections)
+	mux.HandleFunc("GET /operators/liveness", agg.handleListLiveness)
+	mux.HandleFunc("GET /operators/versions", agg.handleListVersions)
+	mux.HandleFunc("GET /quorums", agg.handleListQuorums)
+	mux.HandleFunc("GET /logs", agg.handleLogs)
+	if agg.archive != nil {
+		mux.HandleFunc("GET /history/tasks", agg.handleQueryArchive)
+		mux.HandleFunc("GET /history/tasks/{id}", agg.handleGetArchivedTask)
+	}
+
+	server := &http.Server{Addr: agg.adminApiAddr, Handler: agg.requireAdminToken(mux)}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	agg.logger.Info("Starting aggregator admin api", "address", agg.adminApiAddr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		agg.logger.Error("Admin api server failed", "err", err)
+	}
+}
+
+// requireAdminToken rejects requests which don't carry the configured bearer token.
+func (agg *Aggregator) requireAdminToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(agg.adminApiToken)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (agg *Aggregator) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks := agg.tasks.list()
+	summaries := make([]TaskSummary, 0, len(tasks))
+	for _, task := range tasks {
+		summaries = append(summaries, task.summary())
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].TaskIndex < summaries[j].TaskIndex })
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleCreateTask creates a task on behalf of an external task generator, referencing the current block.
+func (agg *Aggregator) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	var req CreateTaskRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		validate.WriteError(w, err)
+		return
+	}
+	quorumNumbers := types.QUORUM_NUMBERS
+	if len(req.QuorumNumbers) > 0 {
+		quorumNumbers = make(sdktypes.QuorumNums, 0, len(req.QuorumNumbers))
+		for _, quorumNumber := range req.QuorumNumbers {
+			if quorumNumber < 0 || quorumNumber > math.MaxUint8 || !slices.Contains(types.QUORUM_NUMBERS, sdktypes.QuorumNum(quorumNumber)) {
+				validate.WriteError(w, validate.FieldErr("quorumNumbers", fmt.Sprintf("%d is not a quorum of the avs", quorumNumber)))
+				return
+			}
+			quorumNumbers = append(quorumNumbers, sdktypes.QuorumNum(quorumNumber))
+		}
+	}
+	thresholdPercentage := types.QUORUM_THRESHOLD_NUMERATOR
+	if req.QuorumThresholdPercentage > 0 {
+		thresholdPercentage = sdktypes.QuorumThresholdPercentage(req.QuorumThresholdPercentage)
+	}
+	if _, ok := agg.tasks.get(agg.tasks.currentIndex()); ok {
+		writeJSONError(w, http.StatusConflict, "task already exists")
+		return
+	}
+	currentBlock, err := agg.clients.EthHttpClient.BlockNumber(r.Context())
+	if err != nil {
+		agg.logger.Error("Failed to get current block number", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get current block number")
+		return
+	}
+	task, err := agg.createTask(req.Symbol, uint32(currentBlock), quorumNumbers, thresholdPercentage)
+	if errors.Is(err, ShuttingDown503) {
+		writeJSONError(w, http.StatusServiceUnavailable, "aggregator is shutting down")
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to create task")
+		return
+	}
+	agg.logger.Info("Task created through admin api", "taskIndex", task.TaskIndex, "symbol", task.Symbol)
+	writeJSON(w, http.StatusCreated, task.summary())
+}
+
+func (agg *Aggregator) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	taskIndex, err := parseTaskIndex(r)
+	if err != nil {
+		validate.WriteError(w, err)
+		return
+	}
+	task, ok := agg.tasks.get(taskIndex)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	details, err := agg.taskDetails(r.Context(), task)
+	if err != nil {
+		agg.logger.Error("Failed to compute task signing progress", "taskIndex", taskIndex, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to compute task signing progress")
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+func (agg *Aggregator) handleExpireTask(w http.ResponseWriter, r *http.Request) {
+	taskIndex, err := parseTaskIndex(r)
+	if err != nil {
+		validate.WriteError(w, err)
+		return
+	}
+	if !agg.tasks.expire(taskIndex) {
+		writeJSONError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	agg.logger.Warn("Task force expired through admin api", "taskIndex", taskIndex)
+	agg.taskEvents.publish(TaskEvent{Type: TaskExpired, TaskIndex: taskIndex})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListRejections returns the number of signed responses rejected per operator and error.
+func (agg *Aggregator) handleListRejections(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, agg.rejections.snapshot())
+}
+
+// handleListLiveness returns the last heartbeat of every registered operator, including the ones which never sent one.
+func (agg *Aggregator) handleListLiveness(w http.ResponseWriter, r *http.Request) {
+	currentBlock, err := agg.clients.EthHttpClient.BlockNumber(r.Context())
+	if err != nil {
+		agg.logger.Error("Failed to get current block number", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get current block number")
+		return
+	}
+	operatorsState, err := agg.avsRegistryService.GetOperatorsAvsStateAtBlock(r.Context(), types.QUORUM_NUMBERS, uint32(currentBlock))
+	if err != nil {
+		agg.logger.Error("Failed to get operators state", "blockNumber", currentBlock, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get operators state")
+		return
+	}
+	operatorIds := make([]sdktypes.OperatorId, 0, len(operatorsState))
+	for operatorId := range operatorsState {
+		operatorIds = append(operatorIds, operatorId)
+	}
+	writeJSON(w, http.StatusOK, agg.heartbeats.liveness(operatorIds, time.Now()))
+}
+
+// handleListVersions returns the operators grouped by the software version they attested in their last response.
+func (agg *Aggregator) handleListVersions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, agg.versions.distribution())
+}
+
+// handleListQuorums returns the onchain quorum parameters the responses are verified against.
+func (agg *Aggregator) handleListQuorums(w http.ResponseWriter, r *http.Request) {
+	params := agg.quorumWatcher.Params()
+	quorums := make([]quorum.Params, 0, len(params))
+	for _, p := range params {
+		quorums = append(quorums, p)
+	}
+	sort.Slice(quorums, func(i, j int) bool { return quorums[i].QuorumNumber < quorums[j].QuorumNumber })
+	writeJSON(w, http.StatusOK, quorums)
+}
+
+// handleQueryArchive lists archived tasks, filtered by the index, operator, symbol, status, from and to (RFC3339) query
+// parameters.
+func (agg *Aggregator) handleQueryArchive(w http.ResponseWriter, r *http.Request) {
+	query, err := parseArchiveQuery(r)
+	if err != nil {
+		validate.WriteError(w, err)
+		return
+	}
+	tasks, err := agg.archive.query(r.Context(), query)
+	if err != nil {
+		agg.logger.Error("Failed to query task archive", "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to query task archive")
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (agg *Aggregator) handleGetArchivedTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		validate.WriteError(w, validate.FieldErr("id", "must be an integer"))
+		return
+	}
+	task, err := agg.archive.get(r.Context(), id)
+	if errors.Is(err, ErrArchivedTaskNotFound) {
+		writeJSONError(w, http.StatusNotFound, "archived task not found")
+		return
+	}
+	if err != nil {
+		agg.logger.Error("Failed to get archived task", "id", id, "err", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to get archived task")
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+const (
+	defaultArchiveQueryLimit = 100
+	maxArchiveQueryLimit     = 1000
+)
+
+func parseArchiveQuery(r *http.Request) (ArchiveQuery, error) {
+	params := r.URL.Query()
+	query := ArchiveQuery{
+		OperatorId: strings.ToLower(strings.TrimPrefix(params.Get("operator"), "0x")),
+		Symbol:     params.Get("symbol"),
+		Status:     params.Get("status"),
+		Limit:      defaultArchiveQueryLimit,
+	}
+	var invalid validate.Error
+	if index := params.Get("index"); index != "" {
+		taskIndex, err := strconv.ParseUint(index, 10, 32)
+		if err != nil {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "index", Message: "must be a non-negative 32 bit integer"})
+		}
+		query.TaskIndex = new(types.TaskIndex)
+		*query.TaskIndex = types.TaskIndex(taskIndex)
+	}
+	if query.OperatorId != "" {
+		if operatorId, err := hex.DecodeString(query.OperatorId); err != nil || len(operatorId) != 32 {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "operator", Message: "must be a 32 byte hex operator id"})
+		}
+	}
+	switch query.Status {
+	case "", ArchivedTaskPending, ArchivedTaskThresholdReached, ArchivedTaskSubmitted, ArchivedTaskSubmissionFailed, ArchivedTaskExpired:
+	default:
+		invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "status", Message: "must be pending, threshold_reached, submitted, submission_failed or expired"})
+	}
+	for field, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := params.Get(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: field, Message: "must be an RFC3339 time"})
+		}
+		*t = parsed
+	}
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxArchiveQueryLimit {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "limit", Message: "must be between 1 and " + strconv.Itoa(maxArchiveQueryLimit)})
+		}
+		query.Limit = n
+	}
+	if len(invalid.Fields) > 0 {
+		sort.Slice(invalid.Fields, func(i, j int) bool { return invalid.Fields[i].Field < invalid.Fields[j].Field })
+		return ArchiveQuery{}, &invalid
+	}
+	return query, nil
+}
+
+// taskDetails computes per-quorum signed stake for every response digest of the task, using stakes at the task reference block.
+func (agg *Aggregator) taskDetails(ctx context.Context, task taskInfo) (*TaskDetails, error) {
+	operatorsPerQuorum, err := agg.clients.AvsRegistryChainReader.GetOperatorsStakeInQuorumsAtBlock(&bind.CallOpts{Context: ctx}, task.QuorumNumbers, task.ReferenceBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &TaskDetails{
+		TaskSummary:     task.summary(),
+		OperatorDigests: make(map[string]string, len(task.OperatorDigests)),
+	}
+	signersPerDigest := make(map[sdktypes.TaskResponseDigest][]sdktypes.OperatorId)
+	for operatorId, digest := range task.OperatorDigests {
+		details.OperatorDigests[hex.EncodeToString(operatorId[:])] = hex.EncodeToString(digest[:])
+		signersPerDigest[digest] = append(signersPerDigest[digest], operatorId)
+	}
+
+	for digest, signers := range signersPerDigest {
+		progress := DigestProgress{Digest: hex.EncodeToString(digest[:])}
+		signed := make(map[sdktypes.OperatorId]bool, len(signers))
+		for _, operatorId := range signers {
+			signed[operatorId] = true
+			progress.Operators = append(progress.Operators, hex.EncodeToString(operatorId[:]))
+		}
+		sort.Strings(progress.Operators)
+		for i, quorumNum := range task.QuorumNumbers {
+			quorum := QuorumStakeProgress{
+				QuorumNumber:     quorumNum,
+				SignedStake:      big.NewInt(0),
+				TotalStake:       big.NewInt(0),
+				ThresholdPercent: uint8(task.QuorumThresholdPercentages[i]),
+			}
+			if i < len(operatorsPerQuorum) {
+				for _, operator := range operatorsPerQuorum[i] {
+					quorum.TotalStake.Add(quorum.TotalStake, operator.Stake)
+					if signed[operator.OperatorId] {
+						quorum.SignedStake.Add(quorum.SignedStake, operator.Stake)
+					}
+				}
+			}
+			if quorum.TotalStake.Sign() > 0 {
+				quorum.SignedPercentage, _ = new(big.Rat).SetFrac(new(big.Int).Mul(quorum.SignedStake, big.NewInt(100)), quorum.TotalStake).Float64()
+			}
+			progress.Quorums = append(progress.Quorums, quorum)
+		}
+		details.Digests = append(details.Digests, progress)
+	}
+	sort.Slice(details.Digests, func(i, j int) bool { return details.Digests[i].Digest < details.Digests[j].Digest })
+	return details, nil
+}
+
+func (task taskInfo) summary() TaskSummary {
+	return TaskSummary{
+		TaskIndex:            task.TaskIndex,
+		Symbol:               task.Symbol,
+		ReferenceBlockNumber: task.ReferenceBlockNumber,
+		CreatedAt:            task.CreatedAt,
+		ExpiryBlockNumber:    task.ExpiryBlockNumber,
+		NumResponses:         len(task.OperatorDigests),
+	}
+}
+
+func parseTaskIndex(r *http.Request) (types.TaskIndex, error) {
+	taskIndex, err := strconv.ParseUint(r.PathValue("index"), 10, 32)
+	if err != nil {
+		return 0, validate.FieldErr("index", "must be a non-negative 32 bit integer")
+	}
+	return types.TaskIndex(taskIndex), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
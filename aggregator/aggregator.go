@@ -2,16 +2,28 @@ package aggregator
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zees-dev/blockless-avs/aggregator/types"
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	"github.com/zees-dev/blockless-avs/core"
 	"github.com/zees-dev/blockless-avs/core/chainio"
+	"github.com/zees-dev/blockless-avs/core/clock"
 	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/quorum"
+	"github.com/zees-dev/blockless-avs/metrics"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
+	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
 	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
@@ -24,8 +36,7 @@ const (
 	// this hardcoded here because it's also hardcoded in the contracts, but should
 	// ideally be fetched from the contracts
 	taskChallengeWindowBlock = 100
-	blockTimeSeconds         = 12 * time.Second
-	avsName                  = "blocklessAVS"
+	defaultBlockTime         = 12 * time.Second
 )
 
 // Aggregator sends tasks (numbers to square) onchain, then listens for operator signed TaskResponses.
@@ -67,15 +78,62 @@ type Aggregator struct {
 	clients          *clients.Clients
 	avsWriter        chainio.AvsWriterer
 	avsSubscriber    chainio.AvsSubscriberer
+	clockMonitor     *clock.SkewMonitor
+	// simulate aggregated responses before sending them onchain
+	simulateResponses bool
+	batchConfig       config.BatchConfig
+	// finished aggregations waiting to be sent in a batch, only used when batching is enabled
+	batchChan chan batchedResponse
+	// closed once the batcher stopped, after which the finished aggregations are sent on their own
+	batcherDone chan struct{}
 	// aggregation related fields
 	blsAggregationService blsagg.BlsAggregationService
+	avsRegistryService    avsregistry.AvsRegistryService
+	// operators state shared by the response and heartbeat verification
+	operatorStates *operatorStateCache
+	tasks          *taskTracker
+	// consulted in order before accepting an operator response, see RegisterTaskValidator
+	taskValidators []TaskValidator
+	// task lifecycle events streamed by the admin api
+	taskEvents *taskEventBroker
+	// rate limits of the rpc server
+	ipRateLimiter       *rateLimiter
+	operatorRateLimiter *rateLimiter
+	metricsReg          *prometheus.Registry
+	metrics             *metrics.AggregatorMetrics
+	metricsAddr         string
+	// tasks are created by an external generator through the admin api rather than by incoming responses
+	externalTaskGeneration bool
+	shutdownConfig         config.ShutdownConfig
+	// set once shutdown was requested; no new tasks are created while in-flight ones are drained
+	draining atomic.Bool
+	// archive of the task history; nil when disabled
+	archive *taskArchive
+	// onchain quorum parameters, eg. the minimum stake enforced when verifying responses
+	quorumWatcher *quorum.Watcher
+	// scheduled stake updates of the operator set; stakeUpdater is nil when disabled
+	stakeUpdater       *chainio.StakeUpdater
+	stakeUpdatesConfig config.StakeUpdatesConfig
+	// signed responses rejected at the rpc boundary, per operator
+	rejections *rejectionCounter
+	// last heartbeat of every operator, reported by the admin api
+	heartbeats *heartbeatTracker
+	// last software version attested by every operator
+	versions *versionTracker
+	// admin api is disabled when the address is empty
+	adminApiAddr  string
+	adminApiToken string
+	// average time between two blocks of the chain, see taskTimeToExpiryFallback
+	blockTime time.Duration
 
 	// oracle price related fields
-	oracleRequestIndex  types.TaskIndex
+	// serializes the creation of the tasks, which take the next task index
+	taskCreationMu      sync.Mutex
 	prices              map[types.TaskIndex]csavs.IBlocklessAVSPrice
 	oracleResponses     map[types.TaskIndex]map[sdktypes.TaskResponseDigest]csavs.IBlocklessAVSOracleRequest
 	oracleResponsesMu   sync.RWMutex
 	oracleResponsesChan chan *csavs.ContractBlocklessAVSOracleUpdate
+	headsChan           chan *gethtypes.Header
 }
 
 // NewAggregator creates a new Aggregator with the provided config.
@@ -98,15 +156,7 @@ func NewAggregator(c *config.Config) (*Aggregator, error) {
 		return nil, err
 	}
 
-	chainioConfig := clients.BuildAllConfig{
-		EthHttpUrl:                 c.EthHttpRpcUrl,
-		EthWsUrl:                   c.EthWsRpcUrl,
-		RegistryCoordinatorAddr:    c.BlocklessAVSRegistryCoordinatorAddr.String(),
-		OperatorStateRetrieverAddr: c.OperatorStateRetrieverAddr.String(),
-		AvsName:                    avsName,
-		PromMetricsIpPortAddress:   ":9090",
-	}
-	clients, err := clients.BuildAll(chainioConfig, c.EcdsaPrivateKey, c.Logger)
+	clients, err := buildReadClients(c)
 	if err != nil {
 		c.Logger.Errorf("Cannot create sdk clients", "err", err)
 		return nil, err
@@ -116,37 +166,186 @@ func NewAggregator(c *config.Config) (*Aggregator, error) {
 	avsRegistryService := avsregistry.NewAvsRegistryServiceChainCaller(avsReader, operatorPubkeysService, c.Logger)
 	blsAggregationService := blsagg.NewBlsAggregatorService(avsRegistryService, c.Logger)
 
+	quorumWatcher, err := quorum.NewWatcher(c.BlocklessAVSRegistryCoordinatorAddr, *c.EthWsClient, types.QUORUM_NUMBERS.UnderlyingType(), c.Logger)
+	if err != nil {
+		c.Logger.Error("Cannot create quorum parameter watcher", "err", err)
+		return nil, err
+	}
+
+	var archive *taskArchive
+	if c.Archive.Driver != "" {
+		archive, err = newTaskArchive(c.Archive, c.Logger)
+		if err != nil {
+			c.Logger.Error("Cannot open task archive", "err", err)
+			return nil, err
+		}
+	}
+
+	var stakeUpdater *chainio.StakeUpdater
+	if c.StakeUpdates.Enabled {
+		stakeUpdater, err = chainio.NewStakeUpdater(c.BlocklessAVSRegistryCoordinatorAddr, clients.AvsRegistryChainReader, *c.EthHttpClient, avsWriter.TxMgr, c.Logger)
+		if err != nil {
+			c.Logger.Error("Cannot create stake updater", "err", err)
+			return nil, err
+		}
+	}
+
+	reg := c.MetricsRegistry
+	if reg == nil {
+		reg = prometheus.NewRegistry()
+	}
 	return &Aggregator{
-		logger:                c.Logger,
-		serverIpPortAddr:      c.AggregatorServerIpPortAddr,
-		clients:               clients,
-		avsWriter:             avsWriter,
-		avsSubscriber:         avsSubscriber,
-		blsAggregationService: blsAggregationService,
+		logger:                 c.Logger,
+		serverIpPortAddr:       c.AggregatorServerIpPortAddr,
+		clients:                clients,
+		avsWriter:              avsWriter,
+		avsSubscriber:          avsSubscriber,
+		clockMonitor:           clock.NewSkewMonitor(c.Clock, *c.EthHttpClient, c.Logger),
+		simulateResponses:      c.SimulateAggregatedResponses,
+		batchConfig:            c.Batch,
+		batchChan:              make(chan batchedResponse, c.Batch.MaxSize),
+		batcherDone:            make(chan struct{}),
+		blsAggregationService:  blsAggregationService,
+		avsRegistryService:     avsRegistryService,
+		operatorStates:         newOperatorStateCache(avsRegistryService, (*c.EthHttpClient).BlockNumber),
+		tasks:                  newTaskTracker(),
+		taskValidators:         []TaskValidator{PriceValidator{}},
+		taskEvents:             newTaskEventBroker(),
+		rejections:             newRejectionCounter(),
+		heartbeats:             newHeartbeatTracker(c.HeartbeatTimeout),
+		versions:               newVersionTracker(),
+		ipRateLimiter:          newRateLimiter(c.RateLimit.PerIp),
+		operatorRateLimiter:    newRateLimiter(c.RateLimit.PerOperator),
+		metricsReg:             reg,
+		metrics:                metrics.NewAggregatorMetrics(reg),
+		metricsAddr:            c.MetricsIpPortAddr,
+		externalTaskGeneration: c.TaskGeneration == config.ExternalTaskGeneration,
+		shutdownConfig:         c.Shutdown,
+		archive:                archive,
+		quorumWatcher:          quorumWatcher,
+		stakeUpdater:           stakeUpdater,
+		stakeUpdatesConfig:     c.StakeUpdates,
+		adminApiAddr:           c.AdminApiIpPortAddr,
+		adminApiToken:          c.AdminApiToken,
+		blockTime:              c.BlockTime,
 
 		prices:              make(map[types.TaskIndex]csavs.IBlocklessAVSPrice),
 		oracleResponses:     make(map[types.TaskIndex]map[sdktypes.TaskResponseDigest]csavs.IBlocklessAVSOracleRequest),
 		oracleResponsesChan: make(chan *csavs.ContractBlocklessAVSOracleUpdate),
+		headsChan:           make(chan *gethtypes.Header),
 	}, nil
 }
 
+// buildReadClients builds the sdk clients the aggregator reads the chain with. Unlike clients.BuildAll, it doesn't need
+// the ecdsa key, which isn't loaded when transactions are signed by a remote signer; they are sent by the avs writer.
+func buildReadClients(c *config.Config) (*clients.Clients, error) {
+	avsRegistryReader, err := sdkavsregistry.BuildAvsRegistryChainReader(c.BlocklessAVSRegistryCoordinatorAddr, c.OperatorStateRetrieverAddr, *c.EthHttpClient, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+	avsRegistrySubscriber, err := sdkavsregistry.BuildAvsRegistryChainSubscriber(c.BlocklessAVSRegistryCoordinatorAddr, *c.EthWsClient, c.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return &clients.Clients{
+		AvsRegistryChainReader:     avsRegistryReader,
+		AvsRegistryChainSubscriber: avsRegistrySubscriber,
+		EthHttpClient:              *c.EthHttpClient,
+		EthWsClient:                *c.EthWsClient,
+	}, nil
+}
+
+// taskTimeToExpiryFallback is the wall-clock expiry of the tasks given to the bls aggregation service. Tasks are expired
+// once the chain head passes their expiry block (see expireTasks); the bls aggregation service only supports wall-clock
+// expiry, so this generous estimate is just a fallback for when no new heads are received.
+func (agg *Aggregator) taskTimeToExpiryFallback() time.Duration {
+	blockTime := agg.blockTime
+	if blockTime == 0 {
+		blockTime = defaultBlockTime
+	}
+	return 2 * taskChallengeWindowBlock * blockTime
+}
+
 func (agg *Aggregator) Start(ctx context.Context) error {
 	agg.logger.Infof("Starting aggregator")
+	agg.clockMonitor.Start(ctx)
+	// the servers and the batcher keep running while in-flight aggregations are drained, so they use their own context
+	runCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	// the archive is stopped last, once the events of the drained aggregations were published
+	archiveCtx, stopArchive := context.WithCancel(context.Background())
+	defer stopArchive()
+	var archiveDone chan struct{}
+	if agg.archive != nil {
+		// subscribed before anything can publish, so the archive sees every task from its creation
+		events := agg.taskEvents.subscribeQueue()
+		archiveDone = make(chan struct{})
+		go func() {
+			defer close(archiveDone)
+			agg.archive.run(archiveCtx, events)
+		}()
+	}
+	agg.quorumWatcher.OnChange(func(_ map[uint8]quorum.Params, changes []quorum.Change) {
+		agg.logger.Warn("Quorum parameters changed onchain, responses are now verified against them", "changes", changes)
+	})
+	if err := agg.quorumWatcher.Start(ctx); err != nil {
+		agg.logger.Error("Failed to load quorum parameters, minimum stakes are not enforced", "err", err)
+	}
 	agg.logger.Infof("Starting aggregator rpc server.")
-	go agg.startServer(ctx)
+	go agg.startServer(runCtx)
+	if agg.adminApiAddr != "" {
+		go agg.startAdminServer(runCtx)
+	}
+	if agg.metricsAddr != "" {
+		go agg.startMetricsServer()
+	}
+	if agg.stakeUpdater != nil {
+		go agg.runStakeUpdates(runCtx)
+	}
+	if agg.batchConfig.Enabled {
+		agg.logger.Info("Batching aggregated responses", "window", agg.batchConfig.Window, "maxSize", agg.batchConfig.MaxSize)
+		go func() {
+			defer close(agg.batcherDone)
+			agg.startBatcher(runCtx)
+		}()
+	}
+	if agg.shutdownConfig.CheckpointFile != "" {
+		// restored in the background, since replayed responses reaching their threshold block until consumed by the loop below
+		go func() {
+			if err := agg.restoreCheckpoint(); err != nil {
+				agg.logger.Error("Failed to restore in-flight tasks from checkpoint", "file", agg.shutdownConfig.CheckpointFile, "err", err)
+			}
+		}()
+	}
 
-	subOracleUpdates := agg.avsSubscriber.SubscribeToOracleUpdateResponses(agg.oracleResponsesChan)
+	subOracleUpdates := chainio.Resubscribe(agg.logger, "oracle updates", func() (event.Subscription, error) {
+		return agg.avsSubscriber.SubscribeToOracleUpdateResponses(agg.oracleResponsesChan)
+	})
+	defer subOracleUpdates.Unsubscribe()
+	// the tasks only expire on new heads, so a failed subscription is retried rather than given up
+	subHeads := chainio.Resubscribe(agg.logger, "new heads", func() (event.Subscription, error) {
+		return agg.avsSubscriber.SubscribeToNewHeads(agg.headsChan)
+	})
+	defer subHeads.Unsubscribe()
 	for {
 		select {
 		case <-ctx.Done():
+			agg.drain()
+			// stopping the batcher flushes the aggregations which were batched while draining
+			stop()
+			if agg.batchConfig.Enabled {
+				<-agg.batcherDone
+			}
+			stopArchive()
+			if archiveDone != nil {
+				<-archiveDone
+			}
 			return nil
 		case blsAggServiceResp := <-agg.blsAggregationService.GetResponseChannel():
 			agg.logger.Info("Received response from blsAggregationService", "blsAggServiceResp", blsAggServiceResp)
 			agg.sendAggregatedOracleResponseToContract(blsAggServiceResp)
-		case err := <-subOracleUpdates.Err():
-			agg.logger.Error("Error in websocket subscription for OracleUpdate", "err", err)
-			subOracleUpdates.Unsubscribe()
-			subOracleUpdates = agg.avsSubscriber.SubscribeToOracleUpdateResponses(agg.oracleResponsesChan)
+		case head := <-agg.headsChan:
+			agg.expireTasks(uint32(head.Number.Uint64()))
 		case oracleUpd := <-agg.oracleResponsesChan:
 			agg.logger.Info("Received oracle update successfully!; oracleUpd: %#v", oracleUpd)
 			// TODO: update metrics
@@ -155,13 +354,69 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 	}
 }
 
+func (agg *Aggregator) startMetricsServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(agg.metricsReg, promhttp.HandlerOpts{}))
+	agg.logger.Info("Starting aggregator metrics server", "address", agg.metricsAddr)
+	if err := http.ListenAndServe(agg.metricsAddr, mux); err != nil {
+		agg.logger.Error("Aggregator metrics server failed", "err", err)
+	}
+}
+
+// expireTasks expires the in-flight tasks whose challenge window ended before the given block.
+// Their aggregation result is dropped when the bls aggregation service eventually returns it.
+func (agg *Aggregator) expireTasks(blockNumber uint32) {
+	for _, task := range agg.tasks.expireBefore(blockNumber) {
+		agg.logger.Warn("Task expired before reaching quorum threshold",
+			"taskIndex", task.TaskIndex, "expiryBlock", task.ExpiryBlockNumber, "blockNumber", blockNumber, "numResponses", len(task.OperatorDigests))
+		agg.taskEvents.publish(TaskEvent{Type: TaskExpired, TaskIndex: task.TaskIndex, Symbol: task.Symbol, BlockNumber: blockNumber})
+	}
+}
+
+// expireTimedOutTask handles the wall-clock expiry of a task by the bls aggregation service. The task is usually
+// expired by a new head already; otherwise, e.g. while the new heads subscription is down, it's expired here.
+func (agg *Aggregator) expireTimedOutTask(taskIndex types.TaskIndex) {
+	defer agg.forgetTask(taskIndex)
+	if agg.tasks.consumeExpired(taskIndex) {
+		agg.logger.Info("Aggregation of expired task timed out", "taskIndex", taskIndex)
+		return
+	}
+	task, ok := agg.tasks.get(taskIndex)
+	if !ok {
+		agg.logger.Warn("Aggregation of unknown task timed out", "taskIndex", taskIndex)
+		return
+	}
+	agg.tasks.remove(taskIndex)
+	agg.logger.Warn("Task timed out before reaching quorum threshold",
+		"taskIndex", taskIndex, "expiryBlock", task.ExpiryBlockNumber, "numResponses", len(task.OperatorDigests))
+	agg.taskEvents.publish(TaskEvent{Type: TaskExpired, TaskIndex: taskIndex, Symbol: task.Symbol})
+}
+
+// expiredTaskIndex returns the index of the task expired by a blsagg task expired error.
+func expiredTaskIndex(err error) (types.TaskIndex, bool) {
+	var taskIndex types.TaskIndex
+	if _, scanErr := fmt.Sscanf(err.Error(), "task %d expired", &taskIndex); scanErr != nil {
+		return 0, false
+	}
+	return taskIndex, err.Error() == blsagg.TaskExpiredErrorFn(taskIndex).Error()
+}
+
 func (agg *Aggregator) sendAggregatedOracleResponseToContract(blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
-	// TODO: check if blsAggServiceResp contains an err
 	if blsAggServiceResp.Err != nil {
+		// the aggregation of a task runs until its wall-clock expiry, whose error doesn't set the task index
+		if taskIndex, ok := expiredTaskIndex(blsAggServiceResp.Err); ok {
+			agg.expireTimedOutTask(taskIndex)
+			return
+		}
 		agg.logger.Error("BlsAggregationServiceResponse contains an error", "err", blsAggServiceResp.Err)
-		// panicing to help with debugging (fail fast), but we shouldn't panic if we run this in production
-		panic(blsAggServiceResp.Err)
+		return
 	}
+	if agg.tasks.consumeExpired(blsAggServiceResp.TaskIndex) {
+		agg.logger.Info("Dropping aggregation result of expired task", "taskIndex", blsAggServiceResp.TaskIndex)
+		agg.forgetTask(blsAggServiceResp.TaskIndex)
+		return
+	}
+	agg.tasks.remove(blsAggServiceResp.TaskIndex)
 	nonSignerPubkeys := []csavs.BN254G1Point{}
 	for _, nonSignerPubkey := range blsAggServiceResp.NonSignersPubkeysG1 {
 		nonSignerPubkeys = append(nonSignerPubkeys, core.ConvertToBN254G1Point(nonSignerPubkey))
@@ -188,8 +443,40 @@ func (agg *Aggregator) sendAggregatedOracleResponseToContract(blsAggServiceResp
 	price := agg.prices[blsAggServiceResp.TaskIndex]
 	oracleResponse := agg.oracleResponses[blsAggServiceResp.TaskIndex][blsAggServiceResp.TaskResponseDigest]
 	agg.oracleResponsesMu.Unlock()
-	_, err := agg.avsWriter.SendAggregatedOracleResponse(context.Background(), oracleResponse, price, nonSignerStakesAndSignature)
+	agg.forgetTask(blsAggServiceResp.TaskIndex)
+	agg.taskEvents.publish(TaskEvent{
+		Type:      TaskThresholdReached,
+		TaskIndex: blsAggServiceResp.TaskIndex,
+		Symbol:    price.Symbol,
+		Digest:    digestHex(blsAggServiceResp.TaskResponseDigest),
+	})
+	if agg.simulateResponses {
+		if err := agg.avsWriter.SimulateAggregatedOracleResponse(context.Background(), oracleResponse, price, nonSignerStakesAndSignature); err != nil {
+			agg.logger.Error("Aggregated response simulation failed, not sending it onchain", "taskIndex", blsAggServiceResp.TaskIndex, "err", err)
+			agg.taskEvents.publish(TaskEvent{Type: TaskSubmissionFailed, TaskIndex: blsAggServiceResp.TaskIndex, Symbol: price.Symbol, Error: err.Error()})
+			return
+		}
+	}
+	if agg.batchConfig.Enabled {
+		select {
+		case agg.batchChan <- batchedResponse{
+			taskIndex: blsAggServiceResp.TaskIndex,
+			AggregatedOracleResponse: chainio.AggregatedOracleResponse{
+				OracleRequest:               oracleResponse,
+				Price:                       price,
+				NonSignerStakesAndSignature: nonSignerStakesAndSignature,
+			},
+		}:
+			return
+		case <-agg.batcherDone:
+			agg.logger.Warn("Batcher stopped, sending aggregated response on its own", "taskIndex", blsAggServiceResp.TaskIndex)
+		}
+	}
+	receipt, err := agg.avsWriter.SendAggregatedOracleResponse(context.Background(), oracleResponse, price, nonSignerStakesAndSignature)
 	if err != nil {
 		agg.logger.Error("Aggregator failed to respond to task", "err", err)
+		agg.taskEvents.publish(TaskEvent{Type: TaskSubmissionFailed, TaskIndex: blsAggServiceResp.TaskIndex, Symbol: price.Symbol, Error: err.Error()})
+		return
 	}
+	agg.taskEvents.publish(TaskEvent{Type: TaskSubmitted, TaskIndex: blsAggServiceResp.TaskIndex, Symbol: price.Symbol, TxHash: receipt.TxHash.Hex()})
 }
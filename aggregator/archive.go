@@ -0,0 +1,323 @@
+package aggregator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core/config"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Status of an archived task.
+const (
+	ArchivedTaskPending          = "pending"
+	ArchivedTaskThresholdReached = "threshold_reached"
+	ArchivedTaskSubmitted        = "submitted"
+	ArchivedTaskSubmissionFailed = "submission_failed"
+	ArchivedTaskExpired          = "expired"
+)
+
+var ErrArchivedTaskNotFound = errors.New("archived task not found")
+
+// ArchivedResponse is a signed response accepted for an archived task.
+type ArchivedResponse struct {
+	OperatorId string    `json:"operatorId"`
+	Digest     string    `json:"digest"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// ArchivedTask is a task as recorded in the archive, with the responses accepted for it and its aggregation outcome.
+// Task indexes restart with the aggregator, so archived tasks are identified by their own id.
+type ArchivedTask struct {
+	Id                   int64             `json:"id"`
+	TaskIndex            types.TaskIndex   `json:"taskIndex"`
+	Symbol               string            `json:"symbol"`
+	ReferenceBlockNumber types.BlockNumber `json:"referenceBlockNumber"`
+	CreatedAt            time.Time         `json:"createdAt"`
+	Status               string            `json:"status"`
+	// digest which reached the quorum threshold
+	Digest     string             `json:"digest,omitempty"`
+	TxHash     string             `json:"txHash,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"`
+	Responses  []ArchivedResponse `json:"responses"`
+}
+
+// ArchiveQuery filters archived tasks; zero fields don't filter.
+type ArchiveQuery struct {
+	TaskIndex *types.TaskIndex
+	// only tasks the operator responded to
+	OperatorId string
+	Symbol     string
+	// one of the ArchivedTask statuses
+	Status string
+	// only tasks created in [From, To)
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+// taskArchive records the task lifecycle events in a sql database (sqlite or postgres).
+type taskArchive struct {
+	db     *sql.DB
+	driver config.ArchiveDriver
+	logger logging.Logger
+}
+
+var archiveSchema = []string{
+	`CREATE TABLE IF NOT EXISTS archived_tasks (
+		id %s,
+		task_index BIGINT NOT NULL,
+		symbol TEXT NOT NULL,
+		reference_block BIGINT NOT NULL,
+		created_at BIGINT NOT NULL,
+		status TEXT NOT NULL,
+		digest TEXT NOT NULL DEFAULT '',
+		tx_hash TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		finished_at BIGINT
+	)`,
+	`CREATE INDEX IF NOT EXISTS archived_tasks_task_index ON archived_tasks (task_index, id)`,
+	`CREATE INDEX IF NOT EXISTS archived_tasks_created_at ON archived_tasks (created_at)`,
+	`CREATE TABLE IF NOT EXISTS archived_responses (
+		task_id BIGINT NOT NULL REFERENCES archived_tasks (id),
+		operator_id TEXT NOT NULL,
+		digest TEXT NOT NULL,
+		received_at BIGINT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS archived_responses_task_id ON archived_responses (task_id)`,
+	`CREATE INDEX IF NOT EXISTS archived_responses_operator_id ON archived_responses (operator_id)`,
+}
+
+func newTaskArchive(cfg config.ArchiveConfig, logger logging.Logger) (*taskArchive, error) {
+	driverName, idColumn := "sqlite", "INTEGER PRIMARY KEY"
+	if cfg.Driver == config.PostgresArchiveDriver {
+		driverName, idColumn = "pgx", "BIGSERIAL PRIMARY KEY"
+	}
+	db, err := sql.Open(driverName, cfg.Dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open task archive: %w", err)
+	}
+	if cfg.Driver == config.SqliteArchiveDriver {
+		// sqlite doesn't support concurrent writers
+		db.SetMaxOpenConns(1)
+	}
+	for _, stmt := range archiveSchema {
+		if strings.Contains(stmt, "%s") {
+			stmt = fmt.Sprintf(stmt, idColumn)
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("could not create task archive schema: %w", err)
+		}
+	}
+	return &taskArchive{db: db, driver: cfg.Driver, logger: logger}, nil
+}
+
+// run archives the task events until the context is done, then archives the events still buffered and closes the database.
+// run records the queued events until the context is done, then records the remaining ones.
+// The queue isn't bounded, so no event is lost when the database falls behind.
+func (a *taskArchive) run(ctx context.Context, events *taskEventQueue) {
+	defer a.db.Close()
+	for {
+		select {
+		case <-events.ready:
+			for _, event := range events.drain() {
+				a.archive(event)
+			}
+		case <-ctx.Done():
+			for _, event := range events.drain() {
+				a.archive(event)
+			}
+			return
+		}
+	}
+}
+
+func (a *taskArchive) archive(event TaskEvent) {
+	if err := a.record(context.Background(), event); err != nil {
+		a.logger.Error("Failed to archive task event", "type", event.Type, "taskIndex", event.TaskIndex, "err", err)
+	}
+}
+
+func (a *taskArchive) record(ctx context.Context, event TaskEvent) error {
+	at := event.Time.UnixMilli()
+	if event.Type == TaskCreated {
+		_, err := a.db.ExecContext(ctx, a.rebind(
+			`INSERT INTO archived_tasks (task_index, symbol, reference_block, created_at, status) VALUES (?, ?, ?, ?, ?)`),
+			event.TaskIndex, event.Symbol, event.BlockNumber, at, ArchivedTaskPending)
+		return err
+	}
+
+	// events apply to the latest unfinished task with the index
+	var taskId int64
+	err := a.db.QueryRowContext(ctx, a.rebind(
+		`SELECT id FROM archived_tasks WHERE task_index = ? AND status IN (?, ?) ORDER BY id DESC LIMIT 1`),
+		event.TaskIndex, ArchivedTaskPending, ArchivedTaskThresholdReached).Scan(&taskId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no unfinished archived task with index %d", event.TaskIndex)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case TaskResponseReceived:
+		_, err = a.db.ExecContext(ctx, a.rebind(
+			`INSERT INTO archived_responses (task_id, operator_id, digest, received_at) VALUES (?, ?, ?, ?)`),
+			taskId, event.OperatorId, event.Digest, at)
+	case TaskThresholdReached:
+		_, err = a.db.ExecContext(ctx, a.rebind(`UPDATE archived_tasks SET status = ?, digest = ? WHERE id = ?`),
+			ArchivedTaskThresholdReached, event.Digest, taskId)
+	case TaskSubmitted:
+		_, err = a.db.ExecContext(ctx, a.rebind(`UPDATE archived_tasks SET status = ?, tx_hash = ?, finished_at = ? WHERE id = ?`),
+			ArchivedTaskSubmitted, event.TxHash, at, taskId)
+	case TaskSubmissionFailed:
+		_, err = a.db.ExecContext(ctx, a.rebind(`UPDATE archived_tasks SET status = ?, error = ?, finished_at = ? WHERE id = ?`),
+			ArchivedTaskSubmissionFailed, event.Error, at, taskId)
+	case TaskExpired:
+		_, err = a.db.ExecContext(ctx, a.rebind(`UPDATE archived_tasks SET status = ?, finished_at = ? WHERE id = ?`),
+			ArchivedTaskExpired, at, taskId)
+	}
+	return err
+}
+
+// query returns the archived tasks matching the query, most recent first.
+func (a *taskArchive) query(ctx context.Context, q ArchiveQuery) ([]ArchivedTask, error) {
+	var where []string
+	var args []any
+	if q.TaskIndex != nil {
+		where = append(where, "task_index = ?")
+		args = append(args, *q.TaskIndex)
+	}
+	if q.OperatorId != "" {
+		where = append(where, "id IN (SELECT task_id FROM archived_responses WHERE operator_id = ?)")
+		args = append(args, q.OperatorId)
+	}
+	if q.Symbol != "" {
+		where = append(where, "symbol = ?")
+		args = append(args, q.Symbol)
+	}
+	if q.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, q.Status)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "created_at >= ?")
+		args = append(args, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		where = append(where, "created_at < ?")
+		args = append(args, q.To.UnixMilli())
+	}
+	query := `SELECT id, task_index, symbol, reference_block, created_at, status, digest, tx_hash, error, finished_at FROM archived_tasks`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := a.db.QueryContext(ctx, a.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tasks := []ArchivedTask{}
+	for rows.Next() {
+		task, err := scanArchivedTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Responses, err = a.responses(ctx, tasks[i].Id); err != nil {
+			return nil, err
+		}
+	}
+	return tasks, nil
+}
+
+// get returns the archived task with the given id.
+func (a *taskArchive) get(ctx context.Context, id int64) (*ArchivedTask, error) {
+	row := a.db.QueryRowContext(ctx, a.rebind(
+		`SELECT id, task_index, symbol, reference_block, created_at, status, digest, tx_hash, error, finished_at FROM archived_tasks WHERE id = ?`), id)
+	task, err := scanArchivedTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrArchivedTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if task.Responses, err = a.responses(ctx, id); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (a *taskArchive) responses(ctx context.Context, taskId int64) ([]ArchivedResponse, error) {
+	rows, err := a.db.QueryContext(ctx, a.rebind(
+		`SELECT operator_id, digest, received_at FROM archived_responses WHERE task_id = ? ORDER BY received_at`), taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	responses := []ArchivedResponse{}
+	for rows.Next() {
+		var resp ArchivedResponse
+		var receivedAt int64
+		if err := rows.Scan(&resp.OperatorId, &resp.Digest, &receivedAt); err != nil {
+			return nil, err
+		}
+		resp.ReceivedAt = time.UnixMilli(receivedAt)
+		responses = append(responses, resp)
+	}
+	return responses, rows.Err()
+}
+
+func scanArchivedTask(row interface{ Scan(...any) error }) (ArchivedTask, error) {
+	var task ArchivedTask
+	var createdAt int64
+	var finishedAt sql.NullInt64
+	err := row.Scan(&task.Id, &task.TaskIndex, &task.Symbol, &task.ReferenceBlockNumber, &createdAt,
+		&task.Status, &task.Digest, &task.TxHash, &task.Error, &finishedAt)
+	if err != nil {
+		return ArchivedTask{}, err
+	}
+	task.CreatedAt = time.UnixMilli(createdAt)
+	if finishedAt.Valid {
+		finished := time.UnixMilli(finishedAt.Int64)
+		task.FinishedAt = &finished
+	}
+	return task, nil
+}
+
+// rebind replaces the ? placeholders of the query with the numbered placeholders used by postgres.
+func (a *taskArchive) rebind(query string) string {
+	if a.driver != config.PostgresArchiveDriver {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
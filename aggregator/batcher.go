@@ -0,0 +1,105 @@
+package aggregator
+
+import (
+	"context"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core/chainio"
+)
+
+// batchedResponse is a finished aggregation waiting to be sent in a batch.
+type batchedResponse struct {
+	chainio.AggregatedOracleResponse
+	taskIndex types.TaskIndex
+}
+
+// startBatcher collects finished aggregations and sends them onchain in a single updateOraclePrices transaction.
+// A batch is opened by the first aggregation received and sent once the batch window elapses or the batch is full.
+func (agg *Aggregator) startBatcher(ctx context.Context) {
+	for {
+		var batch []batchedResponse
+		select {
+		case <-ctx.Done():
+			agg.flushBatches(nil)
+			return
+		case resp := <-agg.batchChan:
+			batch = append(batch, resp)
+		}
+
+		timer := time.NewTimer(agg.batchConfig.Window)
+	collect:
+		for len(batch) < agg.batchConfig.MaxSize {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				agg.flushBatches(batch)
+				return
+			case resp := <-agg.batchChan:
+				batch = append(batch, resp)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		agg.sendBatch(batch)
+	}
+}
+
+// flushBatches sends the batch along with the aggregations still queued, without waiting for the batch window, so
+// finished aggregations aren't lost on shutdown. The aggregator stops queueing aggregations before stopping the batcher.
+func (agg *Aggregator) flushBatches(batch []batchedResponse) {
+	for {
+		drained := false
+		for !drained && len(batch) < agg.batchConfig.MaxSize {
+			select {
+			case resp := <-agg.batchChan:
+				batch = append(batch, resp)
+			default:
+				drained = true
+			}
+		}
+		if len(batch) > 0 {
+			agg.sendBatch(batch)
+		}
+		if drained {
+			return
+		}
+		batch = nil
+	}
+}
+
+func (agg *Aggregator) sendBatch(batch []batchedResponse) {
+	agg.logger.Info("Sending batch of aggregated responses onchain", "size", len(batch))
+	responses := make([]chainio.AggregatedOracleResponse, len(batch))
+	for i, resp := range batch {
+		responses[i] = resp.AggregatedOracleResponse
+	}
+	receipt, err := agg.avsWriter.SendAggregatedOracleResponses(context.Background(), responses)
+	if err != nil {
+		agg.logger.Error("Aggregator failed to send batch of aggregated responses", "size", len(batch), "err", err)
+		for _, resp := range batch {
+			agg.taskEvents.publish(TaskEvent{Type: TaskSubmissionFailed, TaskIndex: resp.taskIndex, Symbol: resp.Price.Symbol, Error: err.Error()})
+		}
+		return
+	}
+	agg.logger.Info("Batch of aggregated responses sent", "size", len(batch), "txHash", receipt.TxHash.Hex())
+	// the contract doesn't revert the batch for a failing response, but emits its failure instead
+	results, err := agg.avsWriter.BatchResults(receipt, len(batch))
+	if err != nil {
+		agg.logger.Error("Aggregator failed to read the results of the batch", "txHash", receipt.TxHash.Hex(), "err", err)
+		for _, resp := range batch {
+			agg.taskEvents.publish(TaskEvent{Type: TaskSubmissionFailed, TaskIndex: resp.taskIndex, Symbol: resp.Price.Symbol, TxHash: receipt.TxHash.Hex(), Error: err.Error()})
+		}
+		return
+	}
+	for i, resp := range batch {
+		if results[i] != nil {
+			agg.logger.Error("Aggregated response of the batch failed onchain", "taskIndex", resp.taskIndex, "symbol", resp.Price.Symbol, "err", results[i])
+			agg.taskEvents.publish(TaskEvent{Type: TaskSubmissionFailed, TaskIndex: resp.taskIndex, Symbol: resp.Price.Symbol, TxHash: receipt.TxHash.Hex(), Error: results[i].Error()})
+			continue
+		}
+		agg.taskEvents.publish(TaskEvent{Type: TaskSubmitted, TaskIndex: resp.taskIndex, Symbol: resp.Price.Symbol, TxHash: receipt.TxHash.Hex()})
+	}
+}
@@ -0,0 +1,62 @@
+package aggregator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/zees-dev/blockless-avs/core/chainio"
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+// fakeBatchWriter records the batches sent, all of whose responses are stored.
+type fakeBatchWriter struct {
+	chainio.AvsWriterer
+	batches []int
+}
+
+func (w *fakeBatchWriter) SendAggregatedOracleResponses(ctx context.Context, responses []chainio.AggregatedOracleResponse) (*types.Receipt, error) {
+	w.batches = append(w.batches, len(responses))
+	return &types.Receipt{}, nil
+}
+
+func (w *fakeBatchWriter) BatchResults(receipt *types.Receipt, size int) ([]error, error) {
+	return make([]error, size), nil
+}
+
+func TestBatcherFlushesOnShutdown(t *testing.T) {
+	tests := []struct {
+		queued   int
+		expected []int
+	}{
+		{0, nil},
+		{1, []int{1}},
+		// flushed in batches of at most the max size
+		{5, []int{2, 2, 1}},
+	}
+
+	for _, test := range tests {
+		writer := &fakeBatchWriter{}
+		agg := &Aggregator{
+			logger:      logging.NewNoopLogger(),
+			avsWriter:   writer,
+			batchConfig: config.BatchConfig{Enabled: true, Window: time.Hour, MaxSize: 2},
+			batchChan:   make(chan batchedResponse, test.queued),
+			taskEvents:  newTaskEventBroker(),
+		}
+		for i := 0; i < test.queued; i++ {
+			agg.batchChan <- batchedResponse{}
+		}
+		// the aggregations are still queued when the batcher is stopped
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		agg.startBatcher(ctx)
+		if !reflect.DeepEqual(writer.batches, test.expected) {
+			t.Errorf("Expected batches: %v, got: %v", test.expected, writer.batches)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package aggregator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+)
+
+type TaskEventType string
+
+const (
+	TaskCreated          TaskEventType = "task_created"
+	TaskResponseReceived TaskEventType = "response_received"
+	TaskThresholdReached TaskEventType = "threshold_reached"
+	TaskSubmitted        TaskEventType = "submitted"
+	TaskSubmissionFailed TaskEventType = "submission_failed"
+	TaskExpired          TaskEventType = "expired"
+)
+
+const (
+	taskEventBufferSize    = 64
+	taskEventKeepAliveTime = 15 * time.Second
+)
+
+// TaskEvent is a task lifecycle event streamed by the admin api.
+type TaskEvent struct {
+	Type      TaskEventType   `json:"type"`
+	TaskIndex types.TaskIndex `json:"taskIndex"`
+	Time      time.Time       `json:"time"`
+	Symbol    string          `json:"symbol,omitempty"`
+	// set for response_received
+	OperatorId string `json:"operatorId,omitempty"`
+	// response digest; set for response_received and threshold_reached
+	Digest string `json:"digest,omitempty"`
+	// set for task_created (reference block) and expired (block which expired the task, 0 if force expired or timed out)
+	BlockNumber types.BlockNumber `json:"blockNumber,omitempty"`
+	// set for submitted
+	TxHash string `json:"txHash,omitempty"`
+	// set for submission_failed
+	Error string `json:"error,omitempty"`
+}
+
+// taskEventBroker fans task events out to the admin api streams and the archive.
+// Events are dropped for streams which don't keep up, so publishing never blocks the aggregation, while queues,
+// which must see every event, grow until they're drained.
+type taskEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan TaskEvent]struct{}
+	queues      map[*taskEventQueue]struct{}
+}
+
+func newTaskEventBroker() *taskEventBroker {
+	return &taskEventBroker{
+		subscribers: make(map[chan TaskEvent]struct{}),
+		queues:      make(map[*taskEventQueue]struct{}),
+	}
+}
+
+// taskEventQueue is an unbounded queue of task events, for subscribers which can't miss any.
+type taskEventQueue struct {
+	mu     sync.Mutex
+	events []TaskEvent
+	// signaled when events are pushed to an empty queue
+	ready chan struct{}
+}
+
+func newTaskEventQueue() *taskEventQueue {
+	return &taskEventQueue{ready: make(chan struct{}, 1)}
+}
+
+func (q *taskEventQueue) push(event TaskEvent) {
+	q.mu.Lock()
+	q.events = append(q.events, event)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// drain removes and returns the queued events.
+func (q *taskEventQueue) drain() []TaskEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := q.events
+	q.events = nil
+	return events
+}
+
+func (b *taskEventBroker) subscribe(bufferSize int) chan TaskEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan TaskEvent, bufferSize)
+	b.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (b *taskEventBroker) unsubscribe(ch chan TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, ch)
+}
+
+// subscribeQueue subscribes a queue which receives every event published.
+func (b *taskEventBroker) subscribeQueue() *taskEventQueue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q := newTaskEventQueue()
+	b.queues[q] = struct{}{}
+	return q
+}
+
+func (b *taskEventBroker) publish(event TaskEvent) {
+	event.Time = time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	for q := range b.queues {
+		q.push(event)
+	}
+}
+
+// handleTaskEvents streams task lifecycle events as server-sent events until the client disconnects.
+func (agg *Aggregator) handleTaskEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	events := agg.taskEvents.subscribe(taskEventBufferSize)
+	defer agg.taskEvents.unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(taskEventKeepAliveTime)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			// comment line keeping idle connections open through proxies
+			fmt.Fprint(w, ": keepalive\n\n")
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				agg.logger.Error("Failed to encode task event", "err", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
+
+func digestHex(digest [32]byte) string {
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,153 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// operators which didn't send a heartbeat for this long are reported dead
+const defaultHeartbeatTimeout = 90 * time.Second
+
+var (
+	HeartbeatExpired400 = errors.New("400. Heartbeat timestamp is too far from the aggregator time")
+)
+
+// Heartbeat is sent periodically by the operators, so the aggregator reports which operators are alive before they
+// miss tasks. It is signed with the operator bls key.
+type Heartbeat struct {
+	OperatorId sdktypes.OperatorId
+	// version of the operator software
+	Version string
+	// latest block number seen by the operator
+	BlockNumber uint64
+	// when the operator last signed a task response; zero if it didn't since it started
+	LastTaskSignedAt time.Time
+	// when the heartbeat was sent; heartbeats further than the max clock skew from the aggregator time are rejected, so they can't be replayed
+	Timestamp    time.Time
+	BlsSignature bls.Signature
+}
+
+// Digest returns the digest of the heartbeat signed by the operator.
+func (h *Heartbeat) Digest() ([32]byte, error) {
+	unsigned := *h
+	unsigned.BlsSignature = bls.Signature{}
+	encoded, err := json.Marshal(unsigned)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// OperatorLiveness is the admin api representation of the last heartbeat of an operator.
+type OperatorLiveness struct {
+	OperatorId string `json:"operatorId"`
+	// false when no heartbeat was received within the heartbeat timeout
+	Alive            bool       `json:"alive"`
+	Version          string     `json:"version,omitempty"`
+	BlockNumber      uint64     `json:"blockNumber,omitempty"`
+	LastTaskSignedAt *time.Time `json:"lastTaskSignedAt,omitempty"`
+	// unset when the operator never sent a heartbeat since the aggregator started
+	LastHeartbeatAt *time.Time `json:"lastHeartbeatAt,omitempty"`
+}
+
+type receivedHeartbeat struct {
+	heartbeat  Heartbeat
+	receivedAt time.Time
+}
+
+// heartbeatTracker keeps the last heartbeat of every operator.
+type heartbeatTracker struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	last    map[sdktypes.OperatorId]receivedHeartbeat
+}
+
+func newHeartbeatTracker(timeout time.Duration) *heartbeatTracker {
+	if timeout == 0 {
+		timeout = defaultHeartbeatTimeout
+	}
+	return &heartbeatTracker{timeout: timeout, last: make(map[sdktypes.OperatorId]receivedHeartbeat)}
+}
+
+func (t *heartbeatTracker) record(h *Heartbeat, receivedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last[h.OperatorId] = receivedHeartbeat{heartbeat: *h, receivedAt: receivedAt}
+}
+
+// liveness returns the liveness of the given operators and of every operator which sent a heartbeat, sorted by
+// operator id.
+func (t *heartbeatTracker) liveness(operatorIds []sdktypes.OperatorId, now time.Time) []OperatorLiveness {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	seen := make(map[sdktypes.OperatorId]bool, len(t.last))
+	list := make([]OperatorLiveness, 0, len(t.last))
+	for operatorId, r := range t.last {
+		seen[operatorId] = true
+		l := OperatorLiveness{
+			OperatorId:      hex.EncodeToString(operatorId[:]),
+			Alive:           now.Sub(r.receivedAt) <= t.timeout,
+			Version:         r.heartbeat.Version,
+			BlockNumber:     r.heartbeat.BlockNumber,
+			LastHeartbeatAt: &r.receivedAt,
+		}
+		if !r.heartbeat.LastTaskSignedAt.IsZero() {
+			l.LastTaskSignedAt = &r.heartbeat.LastTaskSignedAt
+		}
+		list = append(list, l)
+	}
+	for _, operatorId := range operatorIds {
+		if !seen[operatorId] {
+			list = append(list, OperatorLiveness{OperatorId: hex.EncodeToString(operatorId[:])})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].OperatorId < list[j].OperatorId })
+	return list
+}
+
+// rpc endpoint called periodically by the operators
+func (agg *Aggregator) ProcessHeartbeat(heartbeat *Heartbeat, reply *bool) error {
+	now := time.Now()
+	// the timestamp bound can't be trusted with a skewed clock; operators send their next heartbeat anyway
+	if !agg.clockMonitor.WithinTolerance() {
+		return ClockSkewExceeded503
+	}
+	if d, maxSkew := now.Sub(heartbeat.Timestamp), agg.clockMonitor.MaxSkew(); d > maxSkew || d < -maxSkew {
+		return HeartbeatExpired400
+	}
+	if heartbeat.BlsSignature.G1Point == nil || heartbeat.BlsSignature.G1Affine == nil {
+		return MalformedSignature400
+	}
+	digest, err := heartbeat.Digest()
+	if err != nil {
+		return err
+	}
+	operatorsState, err := agg.operatorStates.current(context.Background(), now)
+	if err != nil {
+		agg.logger.Error("Failed to get operators state", "err", err)
+		return err
+	}
+	operatorState, ok := operatorsState[heartbeat.OperatorId]
+	if !ok {
+		return OperatorNotPartOfTaskQuorum400
+	}
+	valid, err := heartbeat.BlsSignature.Verify(operatorState.OperatorInfo.Pubkeys.G2Pubkey, digest)
+	if err != nil {
+		return UnknownErrorWhileVerifyingSignature400
+	}
+	if !valid {
+		return SignatureVerificationFailed400
+	}
+	agg.heartbeats.record(heartbeat, now)
+	agg.logger.Debug("Received operator heartbeat", "operatorId", heartbeat.OperatorId, "version", heartbeat.Version, "blockNumber", heartbeat.BlockNumber)
+	return nil
+}
@@ -0,0 +1,127 @@
+package aggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/validate"
+)
+
+const (
+	// default number of recent log entries returned by GET /logs
+	defaultLogsLimit   = 100
+	logEventBufferSize = 256
+)
+
+// handleLogs returns the recent log entries of the process, oldest first. With follow=true, they're streamed as
+// server-sent events, followed by the new entries until the client disconnects. The entries can be filtered to a
+// minimum level.
+func (agg *Aggregator) handleLogs(w http.ResponseWriter, r *http.Request) {
+	logs := logging.RecentLogs()
+	if logs == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "the recent log entries are not kept")
+		return
+	}
+	params := r.URL.Query()
+	var invalid validate.Error
+	limit := defaultLogsLimit
+	if value := params.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "limit", Message: "must be a positive number, or 0 for all the entries"})
+		}
+		limit = n
+	}
+	minLevel := zerolog.TraceLevel
+	if value := params.Get("level"); value != "" {
+		level, err := zerolog.ParseLevel(value)
+		if err != nil || level == zerolog.NoLevel {
+			invalid.Fields = append(invalid.Fields, validate.FieldError{Field: "level", Message: "must be trace, debug, info, warn or error"})
+		}
+		minLevel = level
+	}
+	follow := params.Get("follow") == "true"
+	if len(invalid.Fields) > 0 {
+		validate.WriteError(w, &invalid)
+		return
+	}
+
+	if !follow {
+		entries := make([]json.RawMessage, 0, limit)
+		for _, entry := range logs.Recent(0) {
+			if logEntryLevel(entry) >= minLevel {
+				entries = append(entries, entry)
+			}
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	recent, entries := logs.Subscribe(0, logEventBufferSize)
+	defer logs.Unsubscribe(entries)
+	var backlog []json.RawMessage
+	for _, entry := range recent {
+		if logEntryLevel(entry) >= minLevel {
+			backlog = append(backlog, entry)
+		}
+	}
+	if limit > 0 && len(backlog) > limit {
+		backlog = backlog[len(backlog)-limit:]
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	for _, entry := range backlog {
+		fmt.Fprintf(w, "event: log\ndata: %s\n\n", entry)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(taskEventKeepAliveTime)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case entry := <-entries:
+			if logEntryLevel(entry) < minLevel {
+				continue
+			}
+			fmt.Fprintf(w, "event: log\ndata: %s\n\n", entry)
+		}
+		flusher.Flush()
+	}
+}
+
+// logEntryLevel returns the level of a json log entry; entries without a level are logged with Msg on the logger
+// itself and are always kept.
+func logEntryLevel(entry json.RawMessage) zerolog.Level {
+	var fields struct {
+		Level string `json:"level"`
+	}
+	if err := json.Unmarshal(entry, &fields); err != nil || fields.Level == "" {
+		return zerolog.NoLevel
+	}
+	level, err := zerolog.ParseLevel(fields.Level)
+	if err != nil {
+		return zerolog.NoLevel
+	}
+	return level
+}
@@ -0,0 +1,102 @@
+package aggregator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+)
+
+const (
+	// number of blocks whose operators state is kept; the responses of a task all share its reference block
+	operatorStateCacheSize = 16
+	// how long heartbeats are verified against the latest operators state before it's refreshed
+	operatorStateMaxAge = 30 * time.Second
+)
+
+type operatorStateKey struct {
+	quorumNums  string
+	blockNumber uint32
+}
+
+type operatorStateEntry struct {
+	state     map[sdktypes.OperatorId]sdktypes.OperatorAvsState
+	fetchedAt time.Time
+}
+
+// operatorStateCache caches the operators avs state per block, so the responses and heartbeats of the operators don't
+// each query the registry. The state at a block never changes, only the latest state is refreshed.
+type operatorStateCache struct {
+	service avsregistry.AvsRegistryService
+	// returns the current block number
+	blockNumber func(ctx context.Context) (uint64, error)
+
+	mu      sync.Mutex
+	entries map[operatorStateKey]operatorStateEntry
+	// keys in insertion order, the oldest is evicted first
+	order []operatorStateKey
+	// latest state of all the quorums, for the heartbeats
+	latest operatorStateKey
+}
+
+func newOperatorStateCache(service avsregistry.AvsRegistryService, blockNumber func(ctx context.Context) (uint64, error)) *operatorStateCache {
+	return &operatorStateCache{
+		service:     service,
+		blockNumber: blockNumber,
+		entries:     make(map[operatorStateKey]operatorStateEntry),
+	}
+}
+
+// atBlock returns the state of the operators of the quorums at the given block.
+func (c *operatorStateCache) atBlock(ctx context.Context, quorumNums sdktypes.QuorumNums, blockNumber uint32, now time.Time) (map[sdktypes.OperatorId]sdktypes.OperatorAvsState, error) {
+	key := operatorStateKey{quorumNums: string(quorumNums.UnderlyingType()), blockNumber: blockNumber}
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return entry.state, nil
+	}
+
+	state, err := c.service.GetOperatorsAvsStateAtBlock(ctx, quorumNums, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+		if len(c.order) > operatorStateCacheSize {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.entries[key] = operatorStateEntry{state: state, fetchedAt: now}
+	return state, nil
+}
+
+// current returns the state of the operators of all the quorums, fetched at most operatorStateMaxAge ago.
+func (c *operatorStateCache) current(ctx context.Context, now time.Time) (map[sdktypes.OperatorId]sdktypes.OperatorAvsState, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[c.latest]
+	c.mu.Unlock()
+	if ok && now.Sub(entry.fetchedAt) <= operatorStateMaxAge {
+		return entry.state, nil
+	}
+
+	blockNumber, err := c.blockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state, err := c.atBlock(ctx, types.QUORUM_NUMBERS, uint32(blockNumber), now)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.latest = operatorStateKey{quorumNums: string(types.QUORUM_NUMBERS.UnderlyingType()), blockNumber: uint32(blockNumber)}
+	c.mu.Unlock()
+	return state, nil
+}
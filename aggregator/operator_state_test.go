@@ -0,0 +1,72 @@
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/services/avsregistry"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// fakeRegistryService counts the operators state queries.
+type fakeRegistryService struct {
+	avsregistry.AvsRegistryService
+	queries int
+}
+
+func (s *fakeRegistryService) GetOperatorsAvsStateAtBlock(ctx context.Context, quorumNumbers sdktypes.QuorumNums, blockNumber sdktypes.BlockNum) (map[sdktypes.OperatorId]sdktypes.OperatorAvsState, error) {
+	s.queries++
+	return map[sdktypes.OperatorId]sdktypes.OperatorAvsState{}, nil
+}
+
+func TestOperatorStateCache(t *testing.T) {
+	start := time.Now()
+	quorumNums := sdktypes.QuorumNums{0}
+	tests := []struct {
+		name string
+		// blocks queried in order; 0 queries the current state at the given offset from start
+		blocks  []uint32
+		offsets []time.Duration
+		queries int
+	}{
+		{"responses of a task share its block", []uint32{10, 10, 10}, []time.Duration{0, 0, 0}, 1},
+		{"distinct blocks", []uint32{10, 11, 10}, []time.Duration{0, 0, 0}, 2},
+		{"oldest block evicted", append(blockRange(operatorStateCacheSize+1), 1), make([]time.Duration, operatorStateCacheSize+2), operatorStateCacheSize + 2},
+		{"current state reused", []uint32{0, 0}, []time.Duration{0, operatorStateMaxAge}, 1},
+		{"current state refreshed", []uint32{0, 0}, []time.Duration{0, operatorStateMaxAge + time.Second}, 2},
+	}
+
+	for _, test := range tests {
+		service := &fakeRegistryService{}
+		block := uint64(100)
+		cache := newOperatorStateCache(service, func(ctx context.Context) (uint64, error) {
+			block++
+			return block, nil
+		})
+		for i, blockNumber := range test.blocks {
+			now := start.Add(test.offsets[i])
+			var err error
+			if blockNumber == 0 {
+				_, err = cache.current(context.Background(), now)
+			} else {
+				_, err = cache.atBlock(context.Background(), quorumNums, blockNumber, now)
+			}
+			if err != nil {
+				t.Errorf("%s: Unexpected error: %v", test.name, err)
+			}
+		}
+		if service.queries != test.queries {
+			t.Errorf("%s: Expected queries: %v, got: %v", test.name, test.queries, service.queries)
+		}
+	}
+}
+
+// blockRange returns the blocks 1 to n.
+func blockRange(n int) []uint32 {
+	blocks := make([]uint32, n)
+	for i := range blocks {
+		blocks[i] = uint32(i + 1)
+	}
+	return blocks
+}
@@ -0,0 +1,68 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	type request struct {
+		key     string
+		after   time.Duration
+		allowed bool
+	}
+	tests := []struct {
+		name     string
+		cfg      config.TokenBucketConfig
+		requests []request
+	}{
+		{"burst then refill", config.TokenBucketConfig{Rate: 2, Burst: 3}, []request{
+			// the bucket starts full
+			{"a", 0, true},
+			{"a", 0, true},
+			{"a", 0, true},
+			{"a", 0, false},
+			// a token is refilled every 500ms
+			{"a", 499 * time.Millisecond, false},
+			{"a", 500 * time.Millisecond, true},
+			{"a", 500 * time.Millisecond, false},
+		}},
+		{"refill is capped to the burst", config.TokenBucketConfig{Rate: 1, Burst: 2}, []request{
+			{"a", 0, true},
+			{"a", 0, true},
+			{"a", time.Hour, true},
+			{"a", time.Hour, true},
+			{"a", time.Hour, false},
+		}},
+		{"buckets are per key", config.TokenBucketConfig{Rate: 1, Burst: 1}, []request{
+			{"a", 0, true},
+			{"a", 0, false},
+			{"b", 0, true},
+			{"b", 0, false},
+			{"a", time.Second, true},
+		}},
+		{"pruned buckets start full", config.TokenBucketConfig{Rate: 1, Burst: 1}, []request{
+			{"a", 0, true},
+			// pruned by the request of b, a's bucket was full again
+			{"b", 2 * rateLimiterPruneInterval, true},
+			{"a", 2 * rateLimiterPruneInterval, true},
+			{"a", 2 * rateLimiterPruneInterval, false},
+		}},
+		{"unlimited without a rate", config.TokenBucketConfig{Rate: 0, Burst: 0}, []request{
+			{"a", 0, true},
+			{"a", 0, true},
+		}},
+	}
+
+	for _, test := range tests {
+		limiter := newRateLimiter(test.cfg)
+		for i, req := range test.requests {
+			if allowed := limiter.allow(req.key, start.Add(req.after)); allowed != req.allowed {
+				t.Errorf("%s: request %d: Expected allowed: %v, got: %v", test.name, i, req.allowed, allowed)
+			}
+		}
+	}
+}
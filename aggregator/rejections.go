@@ -0,0 +1,42 @@
+package aggregator
+
+import (
+	"encoding/hex"
+	"sync"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// rejectionCounter counts the signed responses rejected at the rpc boundary, per operator and error.
+type rejectionCounter struct {
+	mu     sync.Mutex
+	counts map[sdktypes.OperatorId]map[string]uint64
+}
+
+func newRejectionCounter() *rejectionCounter {
+	return &rejectionCounter{counts: make(map[sdktypes.OperatorId]map[string]uint64)}
+}
+
+func (r *rejectionCounter) inc(operatorId sdktypes.OperatorId, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.counts[operatorId]; !ok {
+		r.counts[operatorId] = make(map[string]uint64)
+	}
+	r.counts[operatorId][err.Error()]++
+}
+
+// snapshot returns the counts keyed by hex encoded operator id and error.
+func (r *rejectionCounter) snapshot() map[string]map[string]uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := make(map[string]map[string]uint64, len(r.counts))
+	for operatorId, counts := range r.counts {
+		cpy := make(map[string]uint64, len(counts))
+		for reason, count := range counts {
+			cpy[reason] = count
+		}
+		snapshot[hex.EncodeToString(operatorId[:])] = cpy
+	}
+	return snapshot
+}
@@ -2,9 +2,15 @@ package aggregator
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/rpc"
+	"strings"
+	"time"
 
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	"github.com/zees-dev/blockless-avs/core"
@@ -14,6 +20,9 @@ import (
 	"github.com/zees-dev/blockless-avs/aggregator/types"
 )
 
+// rejectionPrefix starts the messages of the errors rejecting a request, which resending can't fix.
+const rejectionPrefix = "400."
+
 var (
 	TaskNotFoundError400                     = errors.New("400. Task not found")
 	OperatorNotPartOfTaskQuorum400           = errors.New("400. Operator not part of quorum")
@@ -21,24 +30,93 @@ var (
 	UnknownErrorWhileVerifyingSignature400   = errors.New("400. Failed to verify signature")
 	SignatureVerificationFailed400           = errors.New("400. Signature verification failed")
 	CallToGetCheckSignaturesIndicesFailed500 = errors.New("500. Failed to get check signatures indices")
+	TimestampInFuture400                     = errors.New("400. Price timestamp is in the future")
+	TaskExpired400                           = errors.New("400. Task was expired")
+	MalformedSignature400                    = errors.New("400. Malformed signature")
+	InvalidTaskResponse400                   = errors.New("400. Invalid task response")
+	TaskSymbolMismatch400                    = errors.New("400. Response symbol doesn't match the task symbol")
+	OperatorBelowMinimumStake400             = errors.New("400. Operator stake is below the quorum minimum stake")
+	ShuttingDown503                          = errors.New("503. Aggregator is shutting down")
+	ClockSkewExceeded503                     = errors.New("503. Aggregator clock skew exceeds tolerance")
+	TooManyRequests429                       = errors.New("429. Too many requests")
 )
 
+// IsRejection reports whether err is one of the aggregator 400 errors, returned by the aggregator or received over rpc,
+// meaning the aggregator rejected the request itself.
+func IsRejection(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), rejectionPrefix)
+}
+
 func (agg *Aggregator) startServer(ctx context.Context) error {
-	if err := rpc.Register(agg); err != nil {
+	mux := http.NewServeMux()
+	mux.HandleFunc(rpc.DefaultRPCPath, agg.serveRpcConn)
+	if err := http.ListenAndServe(agg.serverIpPortAddr, mux); err != nil {
+		agg.logger.Fatal("ListenAndServe", "err", err)
+	}
+	return nil
+}
+
+// serveRpcConn serves the rpc methods on a connection hijacked from an http CONNECT request, like rpc.Server.ServeHTTP.
+// Each connection is served by its own rpc server so calls can be rate limited by the remote ip.
+func (agg *Aggregator) serveRpcConn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		io.WriteString(w, "405 must CONNECT\n")
+		return
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !agg.ipRateLimiter.allow(ip, time.Now()) {
+		agg.metrics.IncNumRateLimitedRequests("ip")
+		http.Error(w, TooManyRequests429.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("Aggregator", &rpcConn{agg: agg, ip: ip}); err != nil {
 		agg.logger.Fatal("Format of service TaskManager isn't correct. ", "err", err)
 	}
-	rpc.HandleHTTP()
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		agg.logger.Error("Failed to hijack rpc connection", "remoteAddr", r.RemoteAddr, "err", err)
+		return
+	}
+	io.WriteString(conn, "HTTP/1.0 200 Connected to Go RPC\n\n")
+	server.ServeConn(conn)
+}
 
-	if err := http.ListenAndServe(agg.serverIpPortAddr, nil); err != nil {
-		agg.logger.Fatal("ListenAndServe", "err", err)
+// rpcConn exposes the aggregator rpc methods to a single connection.
+type rpcConn struct {
+	agg *Aggregator
+	ip  string
+}
+
+func (c *rpcConn) ProcessSignedOracleResponse(signedOracleResponse *SignedOracleResponse, reply *bool) error {
+	if !c.agg.ipRateLimiter.allow(c.ip, time.Now()) {
+		c.agg.metrics.IncNumRateLimitedRequests("ip")
+		return TooManyRequests429
 	}
-	return nil
+	return c.agg.ProcessSignedOracleResponse(signedOracleResponse, reply)
+}
+
+func (c *rpcConn) ProcessHeartbeat(heartbeat *Heartbeat, reply *bool) error {
+	if !c.agg.ipRateLimiter.allow(c.ip, time.Now()) {
+		c.agg.metrics.IncNumRateLimitedRequests("ip")
+		return TooManyRequests429
+	}
+	return c.agg.ProcessHeartbeat(heartbeat, reply)
 }
 
 type SignedOracleResponse struct {
 	PriceResponse csavs.IBlocklessAVSPrice
 	BlsSignature  bls.Signature
 	OperatorId    sdktypes.OperatorId
+	// software the response was computed with, attested by VersionSignature; unset by operators predating attestation
+	Version          SoftwareVersion
+	VersionSignature bls.Signature
 }
 
 // rpc endpoint which is called by operator
@@ -46,6 +124,24 @@ type SignedOracleResponse struct {
 // rpc framework forces a reply type to exist, so we put bool as a placeholder
 func (agg *Aggregator) ProcessSignedOracleResponse(signedOracleResponse *SignedOracleResponse, reply *bool) error {
 	agg.logger.Infof("Received signed oracle response: %#v", signedOracleResponse)
+	err := agg.processSignedOracleResponse(signedOracleResponse)
+	if IsRejection(err) {
+		agg.rejections.inc(signedOracleResponse.OperatorId, err)
+	}
+	return err
+}
+
+func (agg *Aggregator) processSignedOracleResponse(signedOracleResponse *SignedOracleResponse) error {
+	// the timestamp checks can't be trusted with a skewed clock; operators retry the rejected response later
+	if !agg.clockMonitor.WithinTolerance() {
+		skew, _ := agg.clockMonitor.Skew()
+		agg.logger.Error("Rejecting signed oracle response, aggregator clock skew exceeds tolerance", "operatorId", signedOracleResponse.OperatorId, "skew", skew)
+		return ClockSkewExceeded503
+	}
+	if err := agg.clockMonitor.CheckTimestamp(time.Unix(int64(signedOracleResponse.PriceResponse.Timestamp), 0)); err != nil {
+		agg.logger.Error("Rejecting signed oracle response", "operatorId", signedOracleResponse.OperatorId, "err", err)
+		return TimestampInFuture400
+	}
 
 	oracleResponseDigest, err := core.GetPriceDigest(&signedOracleResponse.PriceResponse)
 	if err != nil {
@@ -53,81 +149,236 @@ func (agg *Aggregator) ProcessSignedOracleResponse(signedOracleResponse *SignedO
 		return TaskResponseDigestNotFoundError500
 	}
 
-	oracleReq, err := agg.processOracleUpdateRequest(signedOracleResponse)
+	currentBlock, err := agg.clients.EthHttpClient.BlockNumber(context.Background())
+	if err != nil {
+		agg.logger.Error("Failed to get current block number", "err", err)
+		return err
+	}
+	referenceBlock, quorumNums := agg.responseReference(signedOracleResponse, uint32(currentBlock))
+	if err := agg.verifySignedOracleResponse(signedOracleResponse, oracleResponseDigest, referenceBlock, quorumNums); err != nil {
+		agg.logger.Error("Rejecting signed oracle response", "operatorId", signedOracleResponse.OperatorId, "err", err)
+		return err
+	}
+	// operator ids can only be trusted once the signature is verified, otherwise a client could exhaust the limit of another operator
+	if !agg.operatorRateLimiter.allow(string(signedOracleResponse.OperatorId[:]), time.Now()) {
+		agg.metrics.IncNumRateLimitedRequests("operator")
+		return TooManyRequests429
+	}
+	if err := agg.validateSignedOracleResponse(context.Background(), signedOracleResponse); err != nil {
+		agg.logger.Error("Rejecting invalid oracle response", "operatorId", signedOracleResponse.OperatorId, "err", err)
+		return fmt.Errorf("%w: %v", InvalidTaskResponse400, err)
+	}
+
+	var task *taskInfo
+	if agg.externalTaskGeneration {
+		task, err = agg.externalTaskRequest(signedOracleResponse)
+	} else {
+		task, err = agg.processOracleUpdateRequest(signedOracleResponse, uint32(currentBlock))
+	}
 	if err != nil {
 		agg.logger.Error("Failed to process oracle update request", "err", err)
 		return err
 	}
 
+	return agg.aggregateSignedResponse(task.TaskIndex, task.oracleRequest(), signedOracleResponse, oracleResponseDigest)
+}
+
+// aggregateSignedResponse hands a verified response of a task over to the bls aggregation service.
+func (agg *Aggregator) aggregateSignedResponse(taskIndex types.TaskIndex, oracleReq *csavs.IBlocklessAVSOracleRequest, signedOracleResponse *SignedOracleResponse, oracleResponseDigest sdktypes.TaskResponseDigest) error {
 	agg.oracleResponsesMu.Lock()
-	if _, ok := agg.oracleResponses[agg.oracleRequestIndex]; !ok {
-		agg.oracleResponses[agg.oracleRequestIndex] = make(map[sdktypes.TaskResponseDigest]csavs.IBlocklessAVSOracleRequest)
+	if _, ok := agg.oracleResponses[taskIndex]; !ok {
+		agg.oracleResponses[taskIndex] = make(map[sdktypes.TaskResponseDigest]csavs.IBlocklessAVSOracleRequest)
 	}
-	if _, ok := agg.oracleResponses[agg.oracleRequestIndex][oracleResponseDigest]; !ok {
-		agg.oracleResponses[agg.oracleRequestIndex][oracleResponseDigest] = *oracleReq
+	if _, ok := agg.oracleResponses[taskIndex][oracleResponseDigest]; !ok {
+		agg.oracleResponses[taskIndex][oracleResponseDigest] = *oracleReq
 	}
 	agg.oracleResponsesMu.Unlock()
 
-	err = agg.blsAggregationService.ProcessNewSignature(
-		context.Background(), agg.oracleRequestIndex, oracleResponseDigest,
+	// the aggregation service adds the signatures of later signers to the first one, so the tracker keeps a copy
+	recorded := *signedOracleResponse
+	recorded.BlsSignature = *bls.NewZeroSignature().Add(&signedOracleResponse.BlsSignature)
+	err := agg.blsAggregationService.ProcessNewSignature(
+		context.Background(), taskIndex, oracleResponseDigest,
 		&signedOracleResponse.BlsSignature, signedOracleResponse.OperatorId,
 	)
 	if err != nil {
 		agg.logger.Error("Failed to process new signature", "err", err)
+		return err
 	}
-	return err
+	agg.tasks.recordResponse(taskIndex, &recorded, oracleResponseDigest)
+	agg.taskEvents.publish(TaskEvent{
+		Type:       TaskResponseReceived,
+		TaskIndex:  taskIndex,
+		Symbol:     signedOracleResponse.PriceResponse.Symbol,
+		OperatorId: hex.EncodeToString(signedOracleResponse.OperatorId[:]),
+		Digest:     digestHex(oracleResponseDigest),
+	})
+	return nil
 }
 
-func (agg *Aggregator) processOracleUpdateRequest(signedOracleResponse *SignedOracleResponse) (*csavs.IBlocklessAVSOracleRequest, error) {
-	currentBlock, err := agg.clients.EthHttpClient.BlockNumber(context.Background())
+// verifySignedOracleResponse checks that the operator is registered in the task quorums and that its signature is valid,
+// so invalid submissions are rejected before reaching the bls aggregation service.
+// responseReference returns the reference block and quorums of the task the response answers, which the operator must
+// have been registered at, as the bls aggregation service checks its stake at that block. Responses creating a task
+// reference the current block.
+func (agg *Aggregator) responseReference(signedOracleResponse *SignedOracleResponse, currentBlock uint32) (uint32, sdktypes.QuorumNums) {
+	var task taskInfo
+	var ok bool
+	if agg.externalTaskGeneration {
+		task, ok = agg.tasks.get(agg.tasks.currentIndex())
+	} else {
+		task, ok = agg.tasks.latest(signedOracleResponse.PriceResponse.Symbol)
+	}
+	if !ok {
+		return currentBlock, types.QUORUM_NUMBERS
+	}
+	return task.ReferenceBlockNumber, task.QuorumNumbers
+}
+
+func (agg *Aggregator) verifySignedOracleResponse(signedOracleResponse *SignedOracleResponse, digest [32]byte, blockNumber uint32, quorumNums sdktypes.QuorumNums) error {
+	if signedOracleResponse.BlsSignature.G1Point == nil || signedOracleResponse.BlsSignature.G1Affine == nil {
+		return MalformedSignature400
+	}
+	operatorsState, err := agg.operatorStates.atBlock(context.Background(), quorumNums, blockNumber, time.Now())
 	if err != nil {
-		agg.logger.Error("Failed to get current block number", "err", err)
-		return nil, err
+		agg.logger.Error("Failed to get operators state", "blockNumber", blockNumber, "err", err)
+		return err
+	}
+	operatorState, ok := operatorsState[signedOracleResponse.OperatorId]
+	if !ok {
+		return OperatorNotPartOfTaskQuorum400
+	}
+	// the minimum stake may have been raised since the operator registered; it's ejected on its next stake update
+	quorumParams := agg.quorumWatcher.Params()
+	for quorumNum, stake := range operatorState.StakePerQuorum {
+		if params, ok := quorumParams[uint8(quorumNum)]; ok && stake.Cmp(params.MinimumStake) < 0 {
+			return OperatorBelowMinimumStake400
+		}
+	}
+	valid, err := signedOracleResponse.BlsSignature.Verify(operatorState.OperatorInfo.Pubkeys.G2Pubkey, digest)
+	if err != nil {
+		return UnknownErrorWhileVerifyingSignature400
+	}
+	if !valid {
+		return SignatureVerificationFailed400
+	}
+	return agg.verifyVersionAttestation(signedOracleResponse, operatorState.OperatorInfo.Pubkeys.G2Pubkey, digest)
+}
+
+// verifyVersionAttestation checks the signature of the software version attested alongside the response, and records
+// the version of the operator. Responses without an attestation are accepted, so operators can upgrade gradually.
+func (agg *Aggregator) verifyVersionAttestation(signedOracleResponse *SignedOracleResponse, pubkey *bls.G2Point, responseDigest [32]byte) error {
+	if signedOracleResponse.VersionSignature.G1Point == nil || signedOracleResponse.VersionSignature.G1Affine == nil {
+		return nil
+	}
+	versionDigest, err := signedOracleResponse.Version.Digest(responseDigest)
+	if err != nil {
+		return UnknownErrorWhileVerifyingSignature400
 	}
+	valid, err := signedOracleResponse.VersionSignature.Verify(pubkey, versionDigest)
+	if err != nil {
+		return UnknownErrorWhileVerifyingSignature400
+	}
+	if !valid {
+		return SignatureVerificationFailed400
+	}
+	agg.versions.record(signedOracleResponse.OperatorId, signedOracleResponse.Version)
+	return nil
+}
 
-	// TODO: this may need to be provided from the oeprator
-	quorumNumbers := types.QUORUM_NUMBERS
-	quorumThresholdPercentage := types.QUORUM_THRESHOLD_NUMERATOR
+// processOracleUpdateRequest returns the in-flight task of the symbol the response answers, creating it if there's none.
+func (agg *Aggregator) processOracleUpdateRequest(signedOracleResponse *SignedOracleResponse, currentBlock uint32) (*taskInfo, error) {
+	agg.taskCreationMu.Lock()
+	defer agg.taskCreationMu.Unlock()
+	task, ok := agg.tasks.latest(signedOracleResponse.PriceResponse.Symbol)
+	if !ok {
+		created, err := agg.createTaskLocked(signedOracleResponse.PriceResponse.Symbol, currentBlock, types.QUORUM_NUMBERS, types.QUORUM_THRESHOLD_NUMERATOR)
+		if err != nil {
+			return nil, err
+		}
+		task = *created
+	}
+	agg.setTaskPrice(task.TaskIndex, signedOracleResponse.PriceResponse)
+	return &task, nil
+}
 
-	// TODO: remove this afterwards
-	// explicitly convert QuorumNums to []byte
-	byteSlice := make([]byte, len(quorumNumbers))
-	for i, num := range quorumNumbers {
-		byteSlice[i] = byte(num) // Explicit conversion from QuorumNum (uint8) to byte
+// externalTaskRequest returns the externally created task the response answers.
+func (agg *Aggregator) externalTaskRequest(signedOracleResponse *SignedOracleResponse) (*taskInfo, error) {
+	taskIndex := agg.tasks.currentIndex()
+	task, ok := agg.tasks.get(taskIndex)
+	if !ok {
+		if agg.tasks.isExpired(taskIndex) {
+			return nil, TaskExpired400
+		}
+		return nil, TaskNotFoundError400
+	}
+	if task.Symbol != signedOracleResponse.PriceResponse.Symbol {
+		return nil, TaskSymbolMismatch400
 	}
+	agg.setTaskPrice(taskIndex, signedOracleResponse.PriceResponse)
+	return &task, nil
+}
 
+// setTaskPrice records the price of the first response to the task, sent onchain with its aggregation.
+func (agg *Aggregator) setTaskPrice(taskIndex types.TaskIndex, price csavs.IBlocklessAVSPrice) {
 	agg.oracleResponsesMu.Lock()
-	agg.prices[agg.oracleRequestIndex] = signedOracleResponse.PriceResponse
-	agg.oracleResponsesMu.Unlock()
+	defer agg.oracleResponsesMu.Unlock()
+	if _, ok := agg.prices[taskIndex]; !ok {
+		agg.prices[taskIndex] = price
+	}
+}
+
+// forgetTask drops the prices and requests kept for the aggregation of a finished or expired task.
+func (agg *Aggregator) forgetTask(taskIndex types.TaskIndex) {
+	agg.oracleResponsesMu.Lock()
+	defer agg.oracleResponsesMu.Unlock()
+	delete(agg.prices, taskIndex)
+	delete(agg.oracleResponses, taskIndex)
+}
+
+// createTask initializes the aggregation of a new oracle task for the symbol, referencing the given block, which must be
+// signed by the given percentage of the stake of each quorum. The task takes the next task index.
+func (agg *Aggregator) createTask(symbol string, currentBlock uint32, quorumNums sdktypes.QuorumNums, quorumThresholdPercentage sdktypes.QuorumThresholdPercentage) (*taskInfo, error) {
+	agg.taskCreationMu.Lock()
+	defer agg.taskCreationMu.Unlock()
+	return agg.createTaskLocked(symbol, currentBlock, quorumNums, quorumThresholdPercentage)
+}
 
-	// TODO: introduce `QuorumNumbers []byte and QuorumThresholdPercentage uint32` to the initial HTTP POST request
-	quorumThresholdPercentages := make(sdktypes.QuorumThresholdPercentages, len(quorumNumbers))
-	for i := range quorumNumbers {
-		quorumThresholdPercentages[i] = sdktypes.QuorumThresholdPercentage(quorumThresholdPercentage)
-	}
-	// TODO(samlaf): we use seconds for now, but we should ideally pass a blocknumber to the blsAggregationService
-	// and it should monitor the chain and only expire the task aggregation once the chain has reached that block number.
-	taskTimeToExpiry := taskChallengeWindowBlock * blockTimeSeconds
-	var quorumNums sdktypes.QuorumNums
-	for _, quorumNum := range quorumNumbers {
-		quorumNums = append(quorumNums, sdktypes.QuorumNum(quorumNum))
-	}
-	err = agg.blsAggregationService.InitializeNewTask(
-		agg.oracleRequestIndex,
-		uint32(currentBlock),
+func (agg *Aggregator) createTaskLocked(symbol string, currentBlock uint32, quorumNums sdktypes.QuorumNums, quorumThresholdPercentage sdktypes.QuorumThresholdPercentage) (*taskInfo, error) {
+	if agg.draining.Load() {
+		return nil, ShuttingDown503
+	}
+	quorumThresholdPercentages := make(sdktypes.QuorumThresholdPercentages, len(quorumNums))
+	for i := range quorumNums {
+		quorumThresholdPercentages[i] = quorumThresholdPercentage
+	}
+	taskIndex := agg.tasks.nextIndex()
+	err := agg.blsAggregationService.InitializeNewTask(
+		taskIndex,
+		currentBlock,
 		quorumNums,
 		quorumThresholdPercentages,
-		taskTimeToExpiry,
+		agg.taskTimeToExpiryFallback(),
 	)
 	if err != nil {
 		agg.logger.Error("Failed to initialize new task", "err", err)
 		return nil, err
 	}
-
-	return &csavs.IBlocklessAVSOracleRequest{
-		Symbol:                    signedOracleResponse.PriceResponse.Symbol,
-		ReferenceBlockNumber:      uint32(currentBlock),
-		QuorumNumbers:             byteSlice,
-		QuorumThresholdPercentage: uint8(quorumThresholdPercentage),
-	}, nil
+	task := &taskInfo{
+		TaskIndex:                  taskIndex,
+		Symbol:                     symbol,
+		ReferenceBlockNumber:       currentBlock,
+		QuorumNumbers:              quorumNums,
+		QuorumThresholdPercentages: quorumThresholdPercentages,
+		CreatedAt:                  time.Now(),
+		ExpiryBlockNumber:          currentBlock + taskChallengeWindowBlock,
+	}
+	agg.tasks.add(task)
+	agg.taskEvents.publish(TaskEvent{
+		Type:        TaskCreated,
+		TaskIndex:   taskIndex,
+		Symbol:      symbol,
+		BlockNumber: currentBlock,
+	})
+	return task, nil
 }
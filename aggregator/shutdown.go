@@ -0,0 +1,165 @@
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// taskCheckpoint is an in-flight task as written to the checkpoint file on shutdown.
+type taskCheckpoint struct {
+	TaskIndex                  types.TaskIndex                     `json:"taskIndex"`
+	Symbol                     string                              `json:"symbol"`
+	ReferenceBlockNumber       types.BlockNumber                   `json:"referenceBlockNumber"`
+	QuorumNumbers              sdktypes.QuorumNums                 `json:"quorumNumbers"`
+	QuorumThresholdPercentages sdktypes.QuorumThresholdPercentages `json:"quorumThresholdPercentages"`
+	CreatedAt                  time.Time                           `json:"createdAt"`
+	ExpiryBlockNumber          types.BlockNumber                   `json:"expiryBlockNumber"`
+	Responses                  []SignedOracleResponse              `json:"responses"`
+}
+
+// drain keeps aggregating the in-flight tasks for up to the drain timeout once shutdown was requested,
+// so the aggregations reaching their threshold in the meantime are still submitted. No new tasks are created while draining.
+// Tasks still in flight afterwards are checkpointed.
+func (agg *Aggregator) drain() {
+	agg.draining.Store(true)
+	if agg.tasks.len() == 0 {
+		return
+	}
+	agg.logger.Info("Draining in-flight aggregations", "tasks", agg.tasks.len(), "timeout", agg.shutdownConfig.DrainTimeout)
+	timer := time.NewTimer(agg.shutdownConfig.DrainTimeout)
+	defer timer.Stop()
+	for agg.tasks.len() > 0 {
+		select {
+		case blsAggServiceResp := <-agg.blsAggregationService.GetResponseChannel():
+			agg.logger.Info("Received response from blsAggregationService while draining", "blsAggServiceResp", blsAggServiceResp)
+			agg.sendAggregatedOracleResponseToContract(blsAggServiceResp)
+		case head := <-agg.headsChan:
+			agg.expireTasks(uint32(head.Number.Uint64()))
+		case <-timer.C:
+			agg.checkpoint()
+			return
+		}
+	}
+	agg.logger.Info("Drained all in-flight aggregations")
+}
+
+// checkpoint writes the in-flight tasks to the checkpoint file, or logs them as abandoned when checkpoints are disabled.
+func (agg *Aggregator) checkpoint() {
+	tasks := agg.tasks.list()
+	if agg.shutdownConfig.CheckpointFile == "" {
+		for _, task := range tasks {
+			agg.logger.Warn("Abandoning in-flight task on shutdown", "taskIndex", task.TaskIndex, "symbol", task.Symbol, "numResponses", len(task.Responses))
+		}
+		return
+	}
+	checkpoints := make([]taskCheckpoint, 0, len(tasks))
+	for _, task := range tasks {
+		checkpoints = append(checkpoints, taskCheckpoint{
+			TaskIndex:                  task.TaskIndex,
+			Symbol:                     task.Symbol,
+			ReferenceBlockNumber:       task.ReferenceBlockNumber,
+			QuorumNumbers:              task.QuorumNumbers,
+			QuorumThresholdPercentages: task.QuorumThresholdPercentages,
+			CreatedAt:                  task.CreatedAt,
+			ExpiryBlockNumber:          task.ExpiryBlockNumber,
+			Responses:                  task.Responses,
+		})
+	}
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		agg.logger.Error("Failed to encode in-flight tasks checkpoint", "err", err)
+		return
+	}
+	tmpFile := agg.shutdownConfig.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		agg.logger.Error("Failed to write in-flight tasks checkpoint", "file", tmpFile, "err", err)
+		return
+	}
+	if err := os.Rename(tmpFile, agg.shutdownConfig.CheckpointFile); err != nil {
+		agg.logger.Error("Failed to write in-flight tasks checkpoint", "file", agg.shutdownConfig.CheckpointFile, "err", err)
+		return
+	}
+	agg.logger.Info("Checkpointed in-flight tasks", "file", agg.shutdownConfig.CheckpointFile, "tasks", len(checkpoints))
+}
+
+// restoreCheckpoint re-initializes the tasks checkpointed on the last shutdown and replays their signed responses.
+// Tasks which expired in the meantime are dropped. The checkpoint file is removed once restored.
+func (agg *Aggregator) restoreCheckpoint() error {
+	data, err := os.ReadFile(agg.shutdownConfig.CheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var checkpoints []taskCheckpoint
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return err
+	}
+	currentBlock, err := agg.clients.EthHttpClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, cp := range checkpoints {
+		if cp.ExpiryBlockNumber < uint32(currentBlock) {
+			agg.logger.Warn("Dropping checkpointed task which expired while the aggregator was stopped", "taskIndex", cp.TaskIndex, "expiryBlock", cp.ExpiryBlockNumber)
+			continue
+		}
+		if err := agg.restoreTask(cp); err != nil {
+			agg.logger.Error("Failed to restore checkpointed task", "taskIndex", cp.TaskIndex, "err", err)
+			continue
+		}
+		agg.logger.Info("Restored checkpointed task", "taskIndex", cp.TaskIndex, "symbol", cp.Symbol, "numResponses", len(cp.Responses))
+	}
+	return os.Remove(agg.shutdownConfig.CheckpointFile)
+}
+
+func (agg *Aggregator) restoreTask(cp taskCheckpoint) error {
+	agg.taskCreationMu.Lock()
+	defer agg.taskCreationMu.Unlock()
+	err := agg.blsAggregationService.InitializeNewTask(
+		cp.TaskIndex,
+		cp.ReferenceBlockNumber,
+		cp.QuorumNumbers,
+		cp.QuorumThresholdPercentages,
+		agg.taskTimeToExpiryFallback(),
+	)
+	if err != nil {
+		return err
+	}
+	task := &taskInfo{
+		TaskIndex:                  cp.TaskIndex,
+		Symbol:                     cp.Symbol,
+		ReferenceBlockNumber:       cp.ReferenceBlockNumber,
+		QuorumNumbers:              cp.QuorumNumbers,
+		QuorumThresholdPercentages: cp.QuorumThresholdPercentages,
+		CreatedAt:                  cp.CreatedAt,
+		ExpiryBlockNumber:          cp.ExpiryBlockNumber,
+	}
+	agg.tasks.add(task)
+	oracleReq := task.oracleRequest()
+	for i := range cp.Responses {
+		resp := &cp.Responses[i]
+		agg.oracleResponsesMu.Lock()
+		if _, ok := agg.prices[cp.TaskIndex]; !ok {
+			agg.prices[cp.TaskIndex] = resp.PriceResponse
+		}
+		agg.oracleResponsesMu.Unlock()
+		digest, err := core.GetPriceDigest(&resp.PriceResponse)
+		if err != nil {
+			return err
+		}
+		if err := agg.aggregateSignedResponse(cp.TaskIndex, oracleReq, resp, digest); err != nil {
+			agg.logger.Error("Failed to replay checkpointed response", "taskIndex", cp.TaskIndex, "operatorId", resp.OperatorId, "err", err)
+		}
+	}
+	return nil
+}
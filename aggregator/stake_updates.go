@@ -0,0 +1,69 @@
+package aggregator
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+)
+
+// stake updates are given this long to be included before the next attempt
+const stakeUpdateTimeout = 10 * time.Minute
+
+var weiPerGwei = big.NewFloat(1e9)
+
+// runStakeUpdates updates the stakes of the entire operator set on every interval until ctx is cancelled.
+func (agg *Aggregator) runStakeUpdates(ctx context.Context) {
+	agg.logger.Info("Scheduling operator set stake updates", "interval", agg.stakeUpdatesConfig.Interval,
+		"maxCostGwei", agg.stakeUpdatesConfig.MaxCostGwei, "dryRun", agg.stakeUpdatesConfig.DryRun)
+	ticker := time.NewTicker(agg.stakeUpdatesConfig.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			agg.updateStakes(ctx)
+		}
+	}
+}
+
+// updateStakes sends an UpdateOperatorsForQuorum transaction for the avs quorums, unless running dry or the estimated
+// cost of the transaction exceeds the budget.
+func (agg *Aggregator) updateStakes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, stakeUpdateTimeout)
+	defer cancel()
+	update, err := agg.stakeUpdater.BuildStakeUpdate(ctx, types.QUORUM_NUMBERS)
+	if err != nil {
+		agg.logger.Error("Failed to assemble operator set stake update", "err", err)
+		agg.metrics.IncNumStakeUpdates("failed")
+		return
+	}
+	numOperators := 0
+	for _, operators := range update.OperatorsPerQuorum {
+		numOperators += len(operators)
+	}
+	costGwei, _ := new(big.Float).Quo(new(big.Float).SetInt(update.Tx.Cost()), weiPerGwei).Float64()
+	if agg.stakeUpdatesConfig.DryRun {
+		agg.logger.Info("Dry run, not sending operator set stake update", "quorumNumbers", update.QuorumNumbers,
+			"operatorsPerQuorum", update.OperatorsPerQuorum, "gas", update.Tx.Gas(), "estimatedCostGwei", costGwei)
+		agg.metrics.IncNumStakeUpdates("dry_run")
+		return
+	}
+	if budget := agg.stakeUpdatesConfig.MaxCostGwei; budget > 0 && costGwei > budget {
+		agg.logger.Warn("Skipping operator set stake update, its estimated cost exceeds the budget",
+			"estimatedCostGwei", costGwei, "maxCostGwei", budget, "numOperators", numOperators)
+		agg.metrics.IncNumStakeUpdates("over_budget")
+		return
+	}
+	receipt, err := agg.stakeUpdater.SendStakeUpdate(ctx, update)
+	if err != nil {
+		agg.logger.Error("Failed to send operator set stake update", "err", err)
+		agg.metrics.IncNumStakeUpdates("failed")
+		return
+	}
+	agg.logger.Info("Updated operator set stakes", "quorumNumbers", update.QuorumNumbers, "numOperators", numOperators,
+		"txHash", receipt.TxHash.Hex(), "gasUsed", receipt.GasUsed)
+	agg.metrics.IncNumStakeUpdates("sent")
+}
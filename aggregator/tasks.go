@@ -0,0 +1,204 @@
+package aggregator
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// taskInfo is the aggregator's view of a task whose signatures are being aggregated.
+type taskInfo struct {
+	TaskIndex                  types.TaskIndex
+	Symbol                     string
+	ReferenceBlockNumber       types.BlockNumber
+	QuorumNumbers              sdktypes.QuorumNums
+	QuorumThresholdPercentages sdktypes.QuorumThresholdPercentages
+	CreatedAt                  time.Time
+	// the task expires once the chain head passes this block
+	ExpiryBlockNumber types.BlockNumber
+	// digest of the response each operator signed
+	OperatorDigests map[sdktypes.OperatorId]sdktypes.TaskResponseDigest
+	// signed responses accepted for the task, kept to restore the aggregation from a checkpoint
+	Responses []SignedOracleResponse
+}
+
+// taskTracker keeps track of in-flight tasks and which operators responded to them,
+// since the bls aggregation service doesn't expose its internal state.
+type taskTracker struct {
+	mu    sync.RWMutex
+	tasks map[types.TaskIndex]*taskInfo
+	// tasks force expired through the admin api or past their expiry block; their aggregation result is dropped
+	expired map[types.TaskIndex]bool
+	// index of the last task created and of the next one; each task takes its own index, so that an expired task,
+	// whose bls aggregation runs until its wall-clock expiry, doesn't block or reject the later ones
+	current types.TaskIndex
+	next    types.TaskIndex
+}
+
+func newTaskTracker() *taskTracker {
+	return &taskTracker{
+		tasks:   make(map[types.TaskIndex]*taskInfo),
+		expired: make(map[types.TaskIndex]bool),
+	}
+}
+
+func (t *taskTracker) add(task *taskInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	task.OperatorDigests = make(map[sdktypes.OperatorId]sdktypes.TaskResponseDigest)
+	t.tasks[task.TaskIndex] = task
+	delete(t.expired, task.TaskIndex)
+	if task.TaskIndex >= t.next {
+		t.current = task.TaskIndex
+		t.next = task.TaskIndex + 1
+	}
+}
+
+// currentIndex returns the index of the last task created.
+func (t *taskTracker) currentIndex() types.TaskIndex {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.current
+}
+
+// nextIndex returns the index of the next task created.
+func (t *taskTracker) nextIndex() types.TaskIndex {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.next
+}
+
+// latest returns a copy of the last in-flight task created for the symbol.
+func (t *taskTracker) latest(symbol string) (taskInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	var latest *taskInfo
+	for _, task := range t.tasks {
+		if task.Symbol == symbol && (latest == nil || task.TaskIndex > latest.TaskIndex) {
+			latest = task
+		}
+	}
+	if latest == nil {
+		return taskInfo{}, false
+	}
+	return latest.copy(), true
+}
+
+func (t *taskTracker) recordResponse(taskIndex types.TaskIndex, resp *SignedOracleResponse, digest sdktypes.TaskResponseDigest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if task, ok := t.tasks[taskIndex]; ok {
+		task.OperatorDigests[resp.OperatorId] = digest
+		task.Responses = append(task.Responses, *resp)
+	}
+}
+
+func (t *taskTracker) len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.tasks)
+}
+
+// get returns a copy of the task, safe to read without holding the lock.
+func (t *taskTracker) get(taskIndex types.TaskIndex) (taskInfo, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	task, ok := t.tasks[taskIndex]
+	if !ok {
+		return taskInfo{}, false
+	}
+	return task.copy(), true
+}
+
+func (t *taskTracker) list() []taskInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	tasks := make([]taskInfo, 0, len(t.tasks))
+	for _, task := range t.tasks {
+		tasks = append(tasks, task.copy())
+	}
+	return tasks
+}
+
+// remove drops a task once its aggregation finished.
+func (t *taskTracker) remove(taskIndex types.TaskIndex) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.tasks, taskIndex)
+}
+
+// expire removes an in-flight task and marks it as expired so its aggregation result is ignored.
+func (t *taskTracker) expire(taskIndex types.TaskIndex) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.tasks[taskIndex]; !ok {
+		return false
+	}
+	delete(t.tasks, taskIndex)
+	t.expired[taskIndex] = true
+	return true
+}
+
+// expireBefore expires the tasks whose expiry block is before the given block, returning them.
+func (t *taskTracker) expireBefore(blockNumber types.BlockNumber) []taskInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var expired []taskInfo
+	for taskIndex, task := range t.tasks {
+		if task.ExpiryBlockNumber < blockNumber {
+			expired = append(expired, task.copy())
+			delete(t.tasks, taskIndex)
+			t.expired[taskIndex] = true
+		}
+	}
+	return expired
+}
+
+// consumeExpired reports whether the task was force expired, clearing the mark.
+func (t *taskTracker) consumeExpired(taskIndex types.TaskIndex) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	expired := t.expired[taskIndex]
+	delete(t.expired, taskIndex)
+	return expired
+}
+
+func (t *taskTracker) isExpired(taskIndex types.TaskIndex) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.expired[taskIndex]
+}
+
+func (task *taskInfo) copy() taskInfo {
+	cpy := *task
+	cpy.OperatorDigests = make(map[sdktypes.OperatorId]sdktypes.TaskResponseDigest, len(task.OperatorDigests))
+	for operatorId, digest := range task.OperatorDigests {
+		cpy.OperatorDigests[operatorId] = digest
+	}
+	cpy.Responses = slices.Clone(task.Responses)
+	return cpy
+}
+
+// oracleRequest returns the onchain representation of the task.
+func (task *taskInfo) oracleRequest() *csavs.IBlocklessAVSOracleRequest {
+	quorumNumbers := make([]byte, len(task.QuorumNumbers))
+	for i, quorumNum := range task.QuorumNumbers {
+		quorumNumbers[i] = byte(quorumNum)
+	}
+	// the contract takes a single threshold for all quorums
+	var threshold uint8
+	if len(task.QuorumThresholdPercentages) > 0 {
+		threshold = uint8(task.QuorumThresholdPercentages[0])
+	}
+	return &csavs.IBlocklessAVSOracleRequest{
+		Symbol:                    task.Symbol,
+		ReferenceBlockNumber:      task.ReferenceBlockNumber,
+		QuorumNumbers:             quorumNumbers,
+		QuorumThresholdPercentage: threshold,
+	}
+}
@@ -0,0 +1,126 @@
+package aggregator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+)
+
+func TestTaskTrackerExpiry(t *testing.T) {
+	tracker := newTaskTracker()
+	tracker.add(&taskInfo{TaskIndex: tracker.nextIndex(), Symbol: "ETH"})
+	if !tracker.expire(0) {
+		t.Fatalf("Expected task 0 to expire")
+	}
+
+	// the next task takes its own index and isn't affected by the expired one
+	tracker.add(&taskInfo{TaskIndex: tracker.nextIndex(), Symbol: "ETH"})
+	if current := tracker.currentIndex(); current != 1 {
+		t.Errorf("Expected current task index: %v, got: %v", 1, current)
+	}
+	if tracker.isExpired(1) {
+		t.Errorf("Expected task 1 not to be expired")
+	}
+	if task, ok := tracker.latest("ETH"); !ok || task.TaskIndex != 1 {
+		t.Errorf("Expected latest ETH task: %v, got: %v (found: %v)", 1, task.TaskIndex, ok)
+	}
+	if _, ok := tracker.latest("BTC"); ok {
+		t.Errorf("Expected no BTC task")
+	}
+
+	// the timeout of the expired task consumes its expiry
+	if !tracker.consumeExpired(0) {
+		t.Errorf("Expected task 0 to be expired")
+	}
+	if tracker.isExpired(0) {
+		t.Errorf("Expected the expiry of task 0 to be consumed")
+	}
+}
+
+func TestTaskTrackerRestoredIndex(t *testing.T) {
+	tests := []struct {
+		restored []types.TaskIndex
+		current  types.TaskIndex
+		next     types.TaskIndex
+	}{
+		{nil, 0, 0},
+		{[]types.TaskIndex{3}, 3, 4},
+		// restoring an older task doesn't move the indexes back
+		{[]types.TaskIndex{5, 2}, 5, 6},
+	}
+
+	for _, test := range tests {
+		tracker := newTaskTracker()
+		for _, taskIndex := range test.restored {
+			tracker.add(&taskInfo{TaskIndex: taskIndex})
+		}
+		if current := tracker.currentIndex(); current != test.current {
+			t.Errorf("Expected current task index: %v, got: %v", test.current, current)
+		}
+		if next := tracker.nextIndex(); next != test.next {
+			t.Errorf("Expected next task index: %v, got: %v", test.next, next)
+		}
+	}
+}
+
+func TestAggregationTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		// the task was expired by a new head before its aggregation timed out
+		expiredByHead bool
+		// a TaskExpired event is published for the timeout
+		published bool
+	}{
+		{"expired by a new head", true, false},
+		{"not expired by a new head", false, true},
+	}
+
+	for _, test := range tests {
+		agg := &Aggregator{logger: logging.NewNoopLogger(), tasks: newTaskTracker(), taskEvents: newTaskEventBroker()}
+		events := agg.taskEvents.subscribe(1)
+		agg.tasks.add(&taskInfo{TaskIndex: 3, Symbol: "ETH"})
+		if test.expiredByHead {
+			agg.tasks.expire(3)
+		}
+
+		// blsagg doesn't set the task index of the timeout response
+		agg.sendAggregatedOracleResponseToContract(blsagg.BlsAggregationServiceResponse{Err: blsagg.TaskExpiredErrorFn(3)})
+		if _, ok := agg.tasks.get(3); ok || agg.tasks.isExpired(3) {
+			t.Errorf("%s: Expected task 3 to be forgotten", test.name)
+		}
+		select {
+		case event := <-events:
+			if !test.published || event.Type != TaskExpired || event.TaskIndex != 3 || event.Symbol != "ETH" {
+				t.Errorf("%s: Unexpected event: %+v", test.name, event)
+			}
+		default:
+			if test.published {
+				t.Errorf("%s: Expected a task expired event", test.name)
+			}
+		}
+	}
+}
+
+func TestExpiredTaskIndex(t *testing.T) {
+	tests := []struct {
+		err       error
+		taskIndex types.TaskIndex
+		ok        bool
+	}{
+		{blsagg.TaskExpiredErrorFn(0), 0, true},
+		{blsagg.TaskExpiredErrorFn(42), 42, true},
+		{blsagg.TaskNotFoundErrorFn(42), 0, false},
+		{errors.New("task 42 expired early"), 0, false},
+	}
+
+	for _, test := range tests {
+		taskIndex, ok := expiredTaskIndex(test.err)
+		if ok != test.ok || (ok && taskIndex != test.taskIndex) {
+			t.Errorf("Expected task index of %q: %v (ok: %v), got: %v (ok: %v)", test.err, test.taskIndex, test.ok, taskIndex, ok)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+)
+
+// TaskValidator validates the content of a signed oracle response before the aggregator accepts it.
+// Validators run after the operator signature was verified, so they only need to check AVS specific semantics.
+// A non-nil error rejects the response; the error is returned to the operator.
+type TaskValidator interface {
+	Validate(ctx context.Context, signedOracleResponse *SignedOracleResponse) error
+}
+
+// TaskValidatorFunc adapts a function to the TaskValidator interface.
+type TaskValidatorFunc func(ctx context.Context, signedOracleResponse *SignedOracleResponse) error
+
+func (f TaskValidatorFunc) Validate(ctx context.Context, signedOracleResponse *SignedOracleResponse) error {
+	return f(ctx, signedOracleResponse)
+}
+
+// PriceValidator is the default validator, rejecting oracle responses which can't be a valid price.
+type PriceValidator struct{}
+
+func (PriceValidator) Validate(_ context.Context, signedOracleResponse *SignedOracleResponse) error {
+	price := signedOracleResponse.PriceResponse
+	if price.Symbol == "" {
+		return errors.New("missing price symbol")
+	}
+	if price.Price == nil || price.Price.Sign() <= 0 {
+		return errors.New("price must be positive")
+	}
+	if price.Timestamp == 0 {
+		return errors.New("missing price timestamp")
+	}
+	return nil
+}
+
+// RegisterTaskValidator adds a validator consulted, after the ones already registered, before accepting operator responses.
+// It must be called before Start.
+func (agg *Aggregator) RegisterTaskValidator(validator TaskValidator) {
+	agg.taskValidators = append(agg.taskValidators, validator)
+}
+
+func (agg *Aggregator) validateSignedOracleResponse(ctx context.Context, signedOracleResponse *SignedOracleResponse) error {
+	for _, validator := range agg.taskValidators {
+		if err := validator.Validate(ctx, signedOracleResponse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
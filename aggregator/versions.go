@@ -0,0 +1,84 @@
+package aggregator
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SoftwareVersion identifies the software an operator computed and signed a response with.
+type SoftwareVersion struct {
+	// version of the operator binary
+	Version string `json:"version"`
+	// vcs revision the operator binary was built from; empty when built without vcs information
+	Commit string `json:"commit,omitempty"`
+	// version of the b7s module linked into the operator
+	B7sVersion string `json:"b7sVersion,omitempty"`
+	// version of the blockless runtime executing wasm functions; empty when wasm execution is disabled
+	RuntimeVersion string `json:"runtimeVersion,omitempty"`
+}
+
+// Digest returns the digest of the version attested alongside the response with the given digest. The response digest
+// is included, so the attestation of a response can't be replayed with another one.
+func (v *SoftwareVersion) Digest(responseDigest [32]byte) ([32]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return crypto.Keccak256Hash(responseDigest[:], encoded), nil
+}
+
+// VersionGroup is the admin api representation of the operators running the same software version.
+type VersionGroup struct {
+	SoftwareVersion
+	NumOperators int      `json:"numOperators"`
+	OperatorIds  []string `json:"operatorIds"`
+}
+
+// versionTracker keeps the last software version attested by every operator.
+type versionTracker struct {
+	mu   sync.Mutex
+	last map[sdktypes.OperatorId]SoftwareVersion
+}
+
+func newVersionTracker() *versionTracker {
+	return &versionTracker{last: make(map[sdktypes.OperatorId]SoftwareVersion)}
+}
+
+func (t *versionTracker) record(operatorId sdktypes.OperatorId, version SoftwareVersion) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.last[operatorId] = version
+}
+
+// distribution groups the operators by the last version they attested, most common version first.
+func (t *versionTracker) distribution() []VersionGroup {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	groups := make(map[SoftwareVersion]*VersionGroup)
+	for operatorId, version := range t.last {
+		group, ok := groups[version]
+		if !ok {
+			group = &VersionGroup{SoftwareVersion: version, OperatorIds: []string{}}
+			groups[version] = group
+		}
+		group.NumOperators++
+		group.OperatorIds = append(group.OperatorIds, hex.EncodeToString(operatorId[:]))
+	}
+	list := make([]VersionGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.OperatorIds)
+		list = append(list, *group)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].NumOperators != list[j].NumOperators {
+			return list[i].NumOperators > list[j].NumOperators
+		}
+		return list[i].Version > list[j].Version
+	})
+	return list
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	b7sConfig "github.com/blocklessnetwork/b7s/config"
 	"github.com/urfave/cli/v2"
+	avsconfig "github.com/zees-dev/blockless-avs/core/config"
 	"github.com/zees-dev/blockless-avs/operator"
 	"github.com/zees-dev/blockless-avs/types"
 )
@@ -13,11 +14,15 @@ const AppConfigKey = "appConfig"
 type AppConfig struct {
 	// AVSFlags config.Config
 
-	AppName         string
-	Headless        bool
-	DevMode         bool
-	Logger          logging.Logger
-	NodeConfig      *types.NodeConfig
+	AppName    string
+	Headless   bool
+	DevMode    bool
+	Logger     logging.Logger
+	NodeConfig *types.NodeConfig
+	// file NodeConfig was read from, reread by ReloadNodeConfig
+	NodeConfigPath string
+	// network preset applied to NodeConfig, reapplied by ReloadNodeConfig; nil without --network
+	Network         *avsconfig.Network
 	Operator        *operator.Operator
 	BlocklessConfig *b7sConfig.Config
 }
@@ -28,3 +33,16 @@ func GetAppConfig(ctx *cli.Context) *AppConfig {
 	}
 	return ctx.App.Metadata[AppConfigKey].(*AppConfig)
 }
+
+// ReloadNodeConfig rereads the operator config file and applies its reloadable settings to the running operator (see
+// operator.Reload). NodeConfig keeps the config the operator was started with.
+func (a *AppConfig) ReloadNodeConfig() error {
+	nodeConfig := types.NodeConfig{}
+	if err := avsconfig.ReadConfigFile(a.NodeConfigPath, avsconfig.OperatorEnvPrefix, &nodeConfig); err != nil {
+		return err
+	}
+	if err := nodeConfig.ApplyNetwork(a.Network); err != nil {
+		return err
+	}
+	return a.Operator.Reload(nodeConfig)
+}
@@ -0,0 +1,208 @@
+package challenger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+	"github.com/zees-dev/blockless-avs/core"
+	"github.com/zees-dev/blockless-avs/core/chainio"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/metrics"
+	"github.com/zees-dev/blockless-avs/operator"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// Challenger watches OracleUpdate events, recomputes the expected price and reports the operators which signed a
+// price deviating more than the configured tolerance.
+//
+// The challenger only detects incorrect prices, for alerting: the BlocklessAVS contract has no challenge entry point
+// and its freezeOperator doesn't slash yet, so no transaction is sent.
+type Challenger struct {
+	logger        logging.Logger
+	avsReader     chainio.AvsReaderer
+	avsSubscriber chainio.AvsSubscriberer
+	metrics       *metrics.ChallengerMetrics
+	registry      *prometheus.Registry
+	cfg           config.ChallengerConfig
+	// returns the expected price of a symbol at the given time, in 6dp
+	getPrice func(symbol string, at time.Time) (*big.Int, error)
+
+	oracleUpdatesChan chan *csavs.ContractBlocklessAVSOracleUpdate
+}
+
+// NewChallenger creates a new Challenger with the provided config.
+func NewChallenger(c *config.Config) (*Challenger, error) {
+	avsReader, err := chainio.BuildAvsReaderFromConfig(c)
+	if err != nil {
+		c.Logger.Error("Cannot create avsReader", "err", err)
+		return nil, err
+	}
+	avsSubscriber, err := chainio.BuildAvsSubscriberFromConfig(c)
+	if err != nil {
+		c.Logger.Error("Cannot create avsSubscriber", "err", err)
+		return nil, err
+	}
+
+	reg := prometheus.NewRegistry()
+	return &Challenger{
+		logger:            c.Logger,
+		avsReader:         avsReader,
+		avsSubscriber:     avsSubscriber,
+		metrics:           metrics.NewChallengerMetrics(reg),
+		registry:          reg,
+		cfg:               c.Challenger,
+		getPrice:          operator.GetPriceAt,
+		oracleUpdatesChan: make(chan *csavs.ContractBlocklessAVSOracleUpdate),
+	}, nil
+}
+
+func (c *Challenger) Start(ctx context.Context) error {
+	c.logger.Info("Starting challenger", "priceToleranceBps", c.cfg.PriceToleranceBps)
+	if c.cfg.MetricsIpPortAddr != "" {
+		go c.startMetricsServer()
+	}
+
+	sub := chainio.Resubscribe(c.logger, "oracle updates", func() (event.Subscription, error) {
+		return c.avsSubscriber.SubscribeToOracleUpdateResponses(c.oracleUpdatesChan)
+	})
+	defer sub.Unsubscribe()
+	// oracle updates are processed concurrently, so retrying the rpc calls of one doesn't hold up the next ones
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case oracleUpdate := <-c.oracleUpdatesChan:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := c.processOracleUpdate(ctx, oracleUpdate); err != nil {
+					c.logger.Error("Failed to process OracleUpdate", "txHash", oracleUpdate.Raw.TxHash, "err", err)
+				}
+			}()
+		}
+	}
+}
+
+func (c *Challenger) startMetricsServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}))
+	c.logger.Info("Starting challenger metrics server", "address", c.cfg.MetricsIpPortAddr)
+	if err := http.ListenAndServe(c.cfg.MetricsIpPortAddr, mux); err != nil {
+		c.logger.Error("Challenger metrics server failed", "err", err)
+	}
+}
+
+// processOracleUpdate reports the signers of the oracle update if its price deviates from the expected price at the
+// time the operators fetched it, so that the price moving since then isn't mistaken for an incorrect price.
+func (c *Challenger) processOracleUpdate(ctx context.Context, oracleUpdate *csavs.ContractBlocklessAVSOracleUpdate) error {
+	c.metrics.IncNumOracleUpdatesChecked()
+	submitted := oracleUpdate.PriceResponse
+	expected, err := c.getPrice(submitted.Symbol, time.Unix(int64(submitted.Timestamp), 0))
+	if err != nil {
+		return fmt.Errorf("could not get expected price for %s: %w", submitted.Symbol, err)
+	}
+	if withinTolerance(submitted.Price, expected, c.cfg.PriceToleranceBps) {
+		c.logger.Debug("Oracle update price is within tolerance", "symbol", submitted.Symbol, "price", submitted.Price, "expected", expected)
+		return nil
+	}
+	c.metrics.IncNumIncorrectResponses()
+	c.logger.Warn("Oracle update price deviates from expected price",
+		"symbol", submitted.Symbol, "price", submitted.Price, "expected", expected, "txHash", oracleUpdate.Raw.TxHash)
+
+	signers, err := c.getSignersWithRetry(ctx, oracleUpdate)
+	if err != nil {
+		c.metrics.IncNumSignerLookupsFailed()
+		return err
+	}
+	for _, signer := range signers {
+		c.metrics.IncNumIncorrectSigners()
+		c.logger.Warn("Operator signed an incorrect oracle price",
+			"operator", signer, "symbol", submitted.Symbol, "price", submitted.Price, "expected", expected, "txHash", oracleUpdate.Raw.TxHash)
+	}
+	return nil
+}
+
+func (c *Challenger) getSignersWithRetry(ctx context.Context, oracleUpdate *csavs.ContractBlocklessAVSOracleUpdate) ([]gethcommon.Address, error) {
+	var err error
+	for attempt := 1; attempt <= c.cfg.MaxRetries; attempt++ {
+		var signers []gethcommon.Address
+		signers, err = c.getSigners(ctx, oracleUpdate)
+		if err == nil {
+			return signers, nil
+		}
+		c.logger.Warn("Failed to get the signers of the oracle update", "txHash", oracleUpdate.Raw.TxHash, "attempt", attempt, "maxRetries", c.cfg.MaxRetries, "err", err)
+		if attempt == c.cfg.MaxRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.cfg.RetryInterval):
+		}
+	}
+	return nil, fmt.Errorf("could not get the signers of oracle update %s: %w", oracleUpdate.Raw.TxHash.Hex(), err)
+}
+
+// getSigners returns the addresses of the operators which signed the oracle update: every operator registered
+// in the request quorums at the reference block which isn't a non-signer.
+func (c *Challenger) getSigners(ctx context.Context, oracleUpdate *csavs.ContractBlocklessAVSOracleUpdate) ([]gethcommon.Address, error) {
+	resp, err := c.avsReader.GetAggregatedOracleResponseForEvent(ctx, oracleUpdate)
+	if err != nil {
+		return nil, err
+	}
+	nonSigners := make(map[sdktypes.OperatorId]bool)
+	for _, pubkey := range resp.NonSignerStakesAndSignature.NonSignerPubkeys {
+		nonSigners[sdktypes.OperatorIdFromG1Pubkey(core.ConvertFromBN254G1Point(pubkey))] = true
+	}
+
+	quorumNums := make(sdktypes.QuorumNums, len(resp.OracleRequest.QuorumNumbers))
+	for i, quorumNum := range resp.OracleRequest.QuorumNumbers {
+		quorumNums[i] = sdktypes.QuorumNum(quorumNum)
+	}
+	operatorsPerQuorum, err := c.avsReader.GetOperatorsStakeInQuorumsAtBlock(&bind.CallOpts{Context: ctx}, quorumNums, resp.OracleRequest.ReferenceBlockNumber)
+	if err != nil {
+		c.logger.Error("Failed to get operators in quorums at reference block", "err", err)
+		return nil, err
+	}
+
+	seen := make(map[gethcommon.Address]bool)
+	var signers []gethcommon.Address
+	for _, operators := range operatorsPerQuorum {
+		for _, operator := range operators {
+			if nonSigners[operator.OperatorId] || seen[operator.Operator] {
+				continue
+			}
+			seen[operator.Operator] = true
+			signers = append(signers, operator.Operator)
+		}
+	}
+	return signers, nil
+}
+
+// withinTolerance reports whether price deviates at most toleranceBps basis points from expected.
+func withinTolerance(price, expected *big.Int, toleranceBps uint64) bool {
+	if expected.Sign() == 0 {
+		return price.Sign() == 0
+	}
+	diff := new(big.Int).Sub(price, expected)
+	diff.Abs(diff)
+	// diff / expected <= toleranceBps / 10000
+	lhs := new(big.Int).Mul(diff, big.NewInt(10_000))
+	rhs := new(big.Int).Mul(expected, new(big.Int).SetUint64(toleranceBps))
+	return lhs.Cmp(rhs) <= 0
+}
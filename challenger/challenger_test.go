@@ -0,0 +1,76 @@
+package challenger
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	opstateretriever "github.com/Layr-Labs/eigensdk-go/contracts/bindings/OperatorStateRetriever"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+	"github.com/zees-dev/blockless-avs/core/chainio"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+// fakeReader returns a single signing operator, failing the first lookups.
+type fakeReader struct {
+	chainio.AvsReaderer
+	failures int
+	lookups  int
+}
+
+func (r *fakeReader) GetAggregatedOracleResponseForEvent(ctx context.Context, event *csavs.ContractBlocklessAVSOracleUpdate) (*chainio.AggregatedOracleResponse, error) {
+	r.lookups++
+	if r.lookups <= r.failures {
+		return nil, errors.New("connection refused")
+	}
+	return &chainio.AggregatedOracleResponse{Event: event, OracleRequest: csavs.IBlocklessAVSOracleRequest{QuorumNumbers: []byte{0}}}, nil
+}
+
+func (r *fakeReader) GetOperatorsStakeInQuorumsAtBlock(opts *bind.CallOpts, quorumNumbers sdktypes.QuorumNums, blockNumber uint32) ([][]opstateretriever.OperatorStateRetrieverOperator, error) {
+	return [][]opstateretriever.OperatorStateRetrieverOperator{{{Operator: gethcommon.HexToAddress("0x01")}}}, nil
+}
+
+func TestProcessOracleUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    int64
+		failures int
+		lookups  int
+		err      bool
+	}{
+		{"within tolerance", 1_000_000, 0, 0, false},
+		{"incorrect price", 2_000_000, 0, 1, false},
+		{"signers looked up after a failure", 2_000_000, 1, 2, false},
+		{"signers lookup failing every retry", 2_000_000, 3, 3, true},
+	}
+
+	for _, test := range tests {
+		reader := &fakeReader{failures: test.failures}
+		c := &Challenger{
+			logger:    logging.NewNoopLogger(),
+			avsReader: reader,
+			metrics:   metrics.NewChallengerMetrics(prometheus.NewRegistry()),
+			cfg:       config.ChallengerConfig{PriceToleranceBps: 100, MaxRetries: 3, RetryInterval: time.Millisecond},
+			getPrice: func(symbol string, at time.Time) (*big.Int, error) {
+				return big.NewInt(1_000_000), nil
+			},
+		}
+		update := &csavs.ContractBlocklessAVSOracleUpdate{PriceResponse: csavs.IBlocklessAVSPrice{Symbol: "eth", Price: big.NewInt(test.price)}}
+		err := c.processOracleUpdate(context.Background(), update)
+		if (err != nil) != test.err {
+			t.Errorf("%s: Expected error: %v, got: %v", test.name, test.err, err)
+		}
+		if reader.lookups != test.lookups {
+			t.Errorf("%s: Expected signer lookups: %v, got: %v", test.name, test.lookups, reader.lookups)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+	"github.com/zees-dev/blockless-avs/operator"
+	"github.com/zees-dev/blockless-avs/types"
+)
+
+const (
+	avsCommandName = "avs"
+
+	aggregatorRole = "aggregator"
+	operatorRole   = "operator"
+	nodeRole       = "node"
+
+	// how long to wait for the aggregator rpc server before starting the operator
+	aggregatorStartTimeout = 30 * time.Second
+)
+
+var (
+	RolesFlag = &cli.StringSliceFlag{
+		Name:  "roles",
+		Usage: "roles to run in this process (aggregator, operator, node)",
+		Value: cli.NewStringSlice(aggregatorRole, operatorRole, nodeRole),
+	}
+	AggregatorConfigFileFlag = &cli.StringFlag{
+		Name:  "aggregator-config",
+		Usage: "Load aggregator configuration from `FILE`",
+		Value: "config-files/aggregator.yaml",
+	}
+)
+
+func avsCommand() *cli.Command {
+	// the aggregator flags are only needed when running the aggregator role
+	deploymentFlag := *config.BlocklessAVSDeploymentFileFlag
+	deploymentFlag.Required = false
+
+	return &cli.Command{
+		Name:  avsCommandName,
+		Usage: "avs tools",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "all-in-one",
+				Usage:  "runs the aggregator, operator and p2p node in a single process (uses --config for the operator); with --devnet, against a local anvil chain",
+				Action: runAllInOne,
+				Flags: append([]cli.Flag{
+					config.ConfigFileFlag,
+					RolesFlag,
+					AggregatorConfigFileFlag,
+					&deploymentFlag,
+					config.EcdsaKeystoreFlag,
+					config.EcdsaKeystorePasswordFileFlag,
+					config.EcdsaPrivateKeyFlag,
+					config.AdminApiTokenFlag,
+					config.HeadlessFlag,
+					ShutdownTimeoutFlag,
+					node.RecordMessagesFile,
+					node.RecordMessagesBuffer,
+				}, append(devnetFlags(), nodeFlags()...)...),
+			},
+			replayCommand(),
+			avsOperatorCommand(),
+			drainCommand(),
+			configCommand(),
+			doctorCommand(),
+			initCommand(),
+			keysCommand(),
+			completionCommand(),
+			versionCommand(),
+			generateCommand(),
+			tasksCommand(),
+			sendTaskCommand(),
+			logsCommand(),
+			peersCommand(),
+			identityCommand(),
+			functionsCommand(),
+			workspaceCommand(),
+			dbCommand(),
+		},
+	}
+}
+
+// nodeFlags are the flags configuring the b7s host and node.
+func nodeFlags() []cli.Flag {
+	return []cli.Flag{
+		node.Role,
+		node.PeerDatabasePath,
+		node.FunctionDatabasePath,
+		node.Workspace,
+		node.RuntimePath,
+		node.RuntimeCLI,
+		node.CPUPercentage,
+		node.MemoryMaxKB,
+		node.Concurrency,
+		node.LoadAttributes,
+		node.AttributesFile,
+		node.PrivateKey,
+		node.HostAddress,
+		node.HostPort,
+		node.BootNodes,
+		node.Mdns,
+		node.MdnsService,
+		node.Dht,
+		node.DhtProtocolPrefix,
+		node.DhtNamespace,
+		node.DialBackAddress,
+		node.DialBackPort,
+		node.AutoNAT,
+		node.HolePunching,
+		node.Relays,
+		node.QUIC,
+		node.QUICPort,
+		node.QUICDialbackPort,
+		node.ListenAddresses,
+		node.AnnounceAddresses,
+		node.ConnLowWater,
+		node.ConnHighWater,
+		node.ConnGracePeriod,
+		node.AllowPeers,
+		node.DenyPeers,
+		node.StaticPeers,
+		node.PrivateNetworkKey,
+		node.PeerBandwidthLimit,
+		node.PeerScoring,
+		node.PeerBanThreshold,
+		node.PeerBanDuration,
+		node.PeerMessageRate,
+		node.WorkerAttributes,
+		node.ExecutorMinCPUs,
+		node.ExecutorMinMemory,
+		node.ExecutorRuntime,
+		node.ExecutorAttributes,
+		node.VerifyExecutions,
+		node.FunctionSource,
+		node.S3Endpoint,
+		node.S3Region,
+		node.IPFSGateway,
+		node.FunctionCacheSize,
+		node.WorkspaceGCMaxAge,
+		node.WorkspaceGCMaxSize,
+		node.WorkspaceGCInterval,
+		node.AdaptiveConcurrency,
+		node.MinConcurrency,
+		node.MaxConcurrency,
+		node.JobMaxAge,
+		node.Websocket,
+		node.WebsocketPort,
+		node.DialBackWebsocketPort,
+		node.WebsocketTLSCert,
+		node.WebsocketTLSKey,
+		node.WebsocketAutocert,
+		node.WebsocketAutocertCache,
+		node.LegacyProtocolUntil,
+		node.DisableLegacyProtocol,
+		node.TopicNamespace,
+		node.LogFile,
+		node.LogFileMaxSize,
+		node.LogFileRotateEvery,
+		node.LogFileMaxAge,
+		node.LogFileMaxBackups,
+	}
+}
+
+// runAllInOne runs the selected roles in one process.
+func runAllInOne(c *cli.Context) error {
+	return runRoles(c, c.StringSlice(RolesFlag.Name), c.String(AggregatorConfigFileFlag.Name))
+}
+
+// runRoles runs the roles in one process, sharing the logger, eth clients and metrics registry between them. The
+// operator config is read from --config, and the aggregator config from aggregatorConfigPath.
+func runRoles(c *cli.Context, roles []string, aggregatorConfigPath string) error {
+	for _, role := range roles {
+		if role != aggregatorRole && role != operatorRole && role != nodeRole {
+			return fmt.Errorf("unknown role %q", role)
+		}
+	}
+	if slices.Contains(roles, nodeRole) && !slices.Contains(roles, operatorRole) {
+		return fmt.Errorf("the %s role requires the %s role", nodeRole, operatorRole)
+	}
+	devnet := c.Bool(DevnetFlag.Name)
+	if devnet && !slices.Contains(roles, operatorRole) {
+		return fmt.Errorf("--%s requires the %s role", DevnetFlag.Name, operatorRole)
+	}
+	if devnet && len(c.StringSlice(DevnetSymbolsFlag.Name)) == 0 {
+		return fmt.Errorf("--%s requires at least one symbol", DevnetFlag.Name)
+	}
+
+	sdkLogger := logging.NewZeroLogger(logging.Development)
+	logger := sdkLogger.Inner()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	// receives the error of each role stopping unexpectedly, buffered so that none of them blocks
+	failed := make(chan error, 3)
+	// nil unless the node role runs; receiving from a nil channel blocks forever
+	var p2pNode *node.Node
+	var nodeDone <-chan struct{}
+	// closed once the operator was drained through the node api, nil unless the operator role runs
+	var operatorDrained <-chan struct{}
+	// closed once the aggregator drained its in-flight aggregations, nil unless the aggregator role runs
+	var aggDone chan struct{}
+	// closed once the operator stopped, nil unless the operator role runs
+	var operatorDone chan struct{}
+	// the components stopped on shutdown, in order: the api server and p2p node first, so that no new tasks come in,
+	// then the operator and aggregator
+	shutdown := &shutdownSequence{logger: logger}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if devnet {
+		stopDevnet, err := startDevnet(ctx, c, slices.Contains(roles, aggregatorRole))
+		if err != nil {
+			return err
+		}
+		defer stopDevnet()
+	}
+
+	shared := operator.SharedResources{MetricsRegistry: prometheus.NewRegistry()}
+
+	if slices.Contains(roles, aggregatorRole) {
+		aggConfig, err := config.NewConfigFromFile(c, aggregatorConfigPath)
+		if err != nil {
+			return err
+		}
+		aggLogger, err := sdkLogger.WithFile(aggConfig.LogFile)
+		if err != nil {
+			return err
+		}
+		aggConfig.Logger = aggLogger
+		aggConfig.MetricsRegistry = shared.MetricsRegistry
+		agg, err := aggregator.NewAggregator(aggConfig)
+		if err != nil {
+			return err
+		}
+		aggDone = make(chan struct{})
+		go func() {
+			defer reporting.Recover()
+			defer close(aggDone)
+			if err := agg.Start(ctx); err != nil {
+				failed <- fmt.Errorf("aggregator failed: %w", err)
+			}
+		}()
+		if err := waitForAggregator(ctx, aggConfig.AggregatorServerIpPortAddr); err != nil {
+			return err
+		}
+		shared.EthRpcClient = *aggConfig.EthHttpClient
+		shared.EthWsClient = *aggConfig.EthWsClient
+	}
+
+	if slices.Contains(roles, operatorRole) {
+		network, err := config.NetworkFromFlag(c)
+		if err != nil {
+			return err
+		}
+		nodeConfig, err := readNodeConfig(c, c.String(config.ConfigFileFlag.Name))
+		if err != nil {
+			return err
+		}
+		if err := node.ApplyConfig(c, nodeConfig.B7s); err != nil {
+			return err
+		}
+		operatorShared := shared
+		if shared.EthRpcClient != nil && !sameRpcUrls(aggregatorConfigPath, nodeConfig) {
+			// the operator talks to a different node than the aggregator
+			operatorShared.EthRpcClient, operatorShared.EthWsClient = nil, nil
+		}
+		op, err := operator.NewOperatorWithSharedResources(sdkLogger, nodeConfig, operatorShared)
+		if err != nil {
+			return err
+		}
+
+		nodeCfg, err := node.ParseFlags(c)
+		if err != nil {
+			return err
+		}
+		app := &avs.AppConfig{
+			AppName:         AppName,
+			Logger:          sdkLogger,
+			NodeConfig:      &nodeConfig,
+			NodeConfigPath:  c.String(config.ConfigFileFlag.Name),
+			Network:         network,
+			Headless:        c.Bool(config.HeadlessFlag.Name),
+			Operator:        op,
+			BlocklessConfig: &nodeCfg.Config,
+		}
+		c.App.Metadata[avs.AppConfigKey] = app
+		if devnet {
+			if err := registerDevnetOperator(app); err != nil {
+				return err
+			}
+		}
+
+		operatorDone = make(chan struct{})
+		go func() {
+			defer reporting.Recover()
+			defer close(operatorDone)
+			if err := op.Start(ctx); err != nil {
+				failed <- fmt.Errorf("operator failed: %w", err)
+			}
+		}()
+		go reloadOnSighup(ctx, app)
+		operatorDrained = op.Drained()
+		if devnet {
+			go generateDevnetTasks(ctx, app, c.StringSlice(DevnetSymbolsFlag.Name), c.Duration(DevnetTaskIntervalFlag.Name))
+		}
+
+		if slices.Contains(roles, nodeRole) {
+			recorder, err := node.ParseRecorderFlags(c)
+			if err != nil {
+				return err
+			}
+			p2pNode, err = startNode(ctx, c, app, nodeCfg, recorder)
+			if err != nil {
+				return err
+			}
+			// stopped by the shutdown, or here when returning early
+			defer stopNode(app, p2pNode)
+			nodeDone = p2pNode.Done()
+			apiServer := startApiServer(app, p2pNode, failed)
+			shutdown.add("api server", apiServer.Shutdown)
+			shutdown.add("p2p node", p2pNode.Shutdown)
+			if !app.Headless {
+				go openDashboard(app)
+			}
+		}
+	}
+
+	logger.Info().Strs("roles", roles).Msg("Blockless AVS started")
+	// the error the p2p node main loop or a role failed with, returned along the shutdown one
+	var roleErr error
+	select {
+	case <-sig:
+		logger.Info().Msg("Blockless AVS stopping")
+	case <-nodeDone:
+		if err := p2pNode.Err(); err != nil {
+			roleErr = fmt.Errorf("p2p node failed: %w", err)
+		}
+		logger.Info().Msg("Blockless AVS P2P stopped")
+	case <-operatorDrained:
+		logger.Info().Msg("Blockless AVS drained, stopping")
+	case roleErr = <-failed:
+		logger.Error().Err(roleErr).Msg("Blockless AVS aborted")
+	}
+
+	// the operator and aggregator stop once the context is cancelled, the aggregator after draining its in-flight
+	// aggregations; the operator is stopped first so that its last responses can still be aggregated
+	if operatorDone != nil {
+		shutdown.add("operator", func(ctx context.Context) error {
+			cancel()
+			return waitDone(operatorDone)(ctx)
+		})
+	}
+	if aggDone != nil {
+		shutdown.add("aggregator", func(ctx context.Context) error {
+			cancel()
+			return waitDone(aggDone)(ctx)
+		})
+	}
+	return errors.Join(roleErr, shutdown.run(c.Duration(ShutdownTimeoutFlag.Name), sig))
+}
+
+// waitForAggregator blocks until the aggregator rpc server accepts connections.
+func waitForAggregator(ctx context.Context, addr string) error {
+	deadline := time.Now().Add(aggregatorStartTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("aggregator rpc server at %s did not start: %w", addr, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// sameRpcUrls reports whether the operator and aggregator configs use the same eth nodes.
+func sameRpcUrls(aggregatorConfigPath string, nodeConfig types.NodeConfig) bool {
+	var aggConfigRaw config.ConfigRaw
+	if err := config.ReadConfigFile(aggregatorConfigPath, config.AggregatorEnvPrefix, &aggConfigRaw); err != nil {
+		return false
+	}
+	return aggConfigRaw.EthRpcUrl == nodeConfig.EthRpcUrl && aggConfigRaw.EthWsUrl == nodeConfig.EthWsUrl
+}
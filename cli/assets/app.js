@@ -0,0 +1,148 @@
+// Dashboard of the node api, served by the avs when run with --headless=false. The routes are resolved client side;
+// the server answers every path which isn't an asset with this page.
+"use strict";
+
+const api = "/v1";
+const app = document.getElementById("app");
+
+async function getJson(path) {
+  const resp = await fetch(api + path);
+  if (!resp.ok) {
+    throw new Error(`${resp.status} ${(await resp.text()).trim()}`);
+  }
+  return resp.json();
+}
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs)) {
+    node.setAttribute(name, value);
+  }
+  node.append(...children.filter((child) => child !== undefined && child !== null));
+  return node;
+}
+
+function link(href, text) {
+  return el("a", { href, "data-route": "" }, text);
+}
+
+function section(title, ...children) {
+  return el("section", {}, el("h2", {}, title), ...children);
+}
+
+function errorSection(title, err) {
+  return section(title, el("p", { class: "muted" }, err.message));
+}
+
+function formatTime(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+async function refreshHealth() {
+  const badge = document.getElementById("health");
+  try {
+    const resp = await fetch(api + "/health");
+    badge.textContent = resp.ok ? "healthy" : "unhealthy";
+    badge.className = resp.ok ? "badge ok" : "badge down";
+  } catch {
+    badge.textContent = "unreachable";
+    badge.className = "badge down";
+  }
+}
+
+async function versionSection() {
+  try {
+    const info = await getJson("/api/version");
+    const rows = [
+      ["Version", info.version],
+      ["Commit", info.commit],
+      ["Build date", info.buildDate],
+      ["Go", info.goVersion],
+      ["Eigensdk", info.eigensdkVersion],
+      ["Eigenlayer middleware", info.middlewareVersion],
+      ["P2p protocols", (info.protocolVersions || []).join(", ")],
+    ].filter(([, value]) => value);
+    return section("Node", el("dl", {}, ...rows.flatMap(([name, value]) => [el("dt", {}, name), el("dd", {}, value)])));
+  } catch (err) {
+    return errorSection("Node", err);
+  }
+}
+
+async function executionsSection() {
+  let executions;
+  try {
+    executions = await getJson("/api/executions");
+  } catch (err) {
+    return errorSection("Executions", err);
+  }
+  if (executions.length === 0) {
+    return section("Executions", el("p", { class: "muted" }, "No executions yet"));
+  }
+  const rows = executions.map((execution) =>
+    el(
+      "tr",
+      {},
+      el("td", {}, link(`/executions/${encodeURIComponent(execution.id)}`, execution.id)),
+      el("td", {}, execution.symbol),
+      el("td", {}, formatTime(execution.startedAt)),
+      el("td", {}, execution.price || ""),
+      el("td", {}, execution.error ? el("span", { class: "badge down" }, "failed") : el("span", { class: "badge ok" }, "ok")),
+    ),
+  );
+  const head = el("tr", {}, ...["Id", "Symbol", "Started", "Price", "Result"].map((name) => el("th", {}, name)));
+  return section("Executions", el("table", {}, el("thead", {}, head), el("tbody", {}, ...rows)));
+}
+
+async function executionPage(id) {
+  let execution;
+  try {
+    execution = await getJson(`/api/executions/${encodeURIComponent(id)}`);
+  } catch (err) {
+    return [errorSection(`Execution ${id}`, err)];
+  }
+  const rows = [
+    ["Symbol", execution.symbol],
+    ["Function", execution.function],
+    ["Started", formatTime(execution.startedAt)],
+    ["Finished", formatTime(execution.finishedAt)],
+    ["Price", execution.price],
+    ["Digest", execution.digest],
+    ["Error", execution.error],
+  ].filter(([, value]) => value);
+  const artifacts = (execution.artifacts || []).map((name) =>
+    el("li", {}, el("a", { href: `${api}/api/executions/${encodeURIComponent(id)}/artifacts/${encodeURIComponent(name)}` }, name)),
+  );
+  return [
+    section(`Execution ${id}`, el("dl", {}, ...rows.flatMap(([name, value]) => [el("dt", {}, name), el("dd", {}, value)]))),
+    section("Artifacts", artifacts.length ? el("ul", {}, ...artifacts) : el("p", { class: "muted" }, "No artifacts")),
+  ];
+}
+
+async function render() {
+  const path = window.location.pathname;
+  let sections;
+  const execution = path.match(/^\/executions\/([^/]+)$/);
+  if (execution) {
+    sections = await executionPage(decodeURIComponent(execution[1]));
+  } else if (path === "/") {
+    sections = await Promise.all([versionSection(), executionsSection()]);
+  } else {
+    sections = [section("Not found", el("p", {}, "No page at ", path, ". "), link("/", "Back to the overview"))];
+  }
+  app.replaceChildren(...sections);
+}
+
+document.addEventListener("click", (event) => {
+  const anchor = event.target.closest("a[data-route]");
+  if (!anchor || event.metaKey || event.ctrlKey || event.shiftKey) {
+    return;
+  }
+  event.preventDefault();
+  window.history.pushState(null, "", anchor.getAttribute("href"));
+  render();
+});
+window.addEventListener("popstate", render);
+
+refreshHealth();
+setInterval(refreshHealth, 10000);
+render();
@@ -2,127 +2,165 @@ package main
 
 import (
 	"context"
+	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/cockroachdb/pebble"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
 	avs "github.com/zees-dev/blockless-avs"
 	"github.com/zees-dev/blockless-avs/core/logging"
 	node "github.com/zees-dev/blockless-avs/node/pkg"
+	"github.com/zees-dev/blockless-avs/operator"
 )
 
-// //go:embed assets/*
-// var embeddedFiles embed.FS
+// address the node api is served on
+const apiServerAddr = ":8080"
 
-func RunAVS(c *cli.Context) error {
-	app := avs.GetAppConfig(c)
-	logger := app.Logger.(*logging.ZeroLogger).Inner()
-
-	// Signal catching for clean shutdown.
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt)
-	done := make(chan struct{})
-	failed := make(chan struct{})
-
-	logger.Info().Str("app_name", app.AppName).Bool("headless_mode", app.Headless).Bool("dev_mode", app.DevMode).Msg("server is starting..")
+// dashboard build, served on the node api address when not headless
+//
+//go:embed assets
+var embeddedFiles embed.FS
 
-	// Create the main context for p2p
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Start the operator.
-	go func() {
-		logger.Info().Msg("starting operator...")
-		if err := app.Operator.Start(ctx); err != nil {
-			logger.Error().Msg("failed to start operator")
-		} else {
-			logger.Info().Msg("started operator")
+// reloadOnSighup applies the reloadable operator settings from the config file whenever the process receives a SIGHUP,
+// until ctx is cancelled. The p2p host keeps running throughout.
+func reloadOnSighup(ctx context.Context, app *avs.AppConfig) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			app.Logger.Info("Received SIGHUP, reloading operator config", "path", app.NodeConfigPath)
+			if err := app.ReloadNodeConfig(); err != nil {
+				app.Logger.Error("Failed to reload operator config", "err", err)
+			}
 		}
-	}()
-
-	router := http.NewServeMux()
-
-	// assets, err := fs.Sub(embeddedFiles, "assets")
-	// if err != nil {
-	// 	logger.Info().Err(err).Msg("Failed to locate embedded assets")
-	// 	return
-	// }
-
-	// // Use the http.FileServer to serve the embedded assets.
-	// mux.Handle("/", http.FileServer(http.FS(assets)))
-
-	// Register API routes.
-	node.RegisterAPIRoutes(app, router)
-
-	// load vars
-
-	logger.Info().Msgf("Peer database path %s", app.BlocklessConfig.PeerDB)
-
-	// Open the pebble peer database.
-	pdb, err := pebble.Open(app.BlocklessConfig.PeerDB, &pebble.Options{Logger: &node.PebbleNoopLogger{}})
-	if err != nil {
-		logger.Error().Err(err).Str("db", app.BlocklessConfig.PeerDB).Msg("could not open pebble peer database")
 	}
-	defer pdb.Close()
+}
 
-	// Open the pebble function database.
-	fdb, err := pebble.Open(app.BlocklessConfig.FunctionDB, &pebble.Options{Logger: &node.PebbleNoopLogger{}})
+// startNode boots the p2p network. Messages are recorded or replayed through the recorder, if any.
+// The returned node must be stopped with stopNode.
+func startNode(ctx context.Context, c *cli.Context, app *avs.AppConfig, cfg node.NodeConfig, recorder *node.MessageRecorder) (*node.Node, error) {
+	nodeLogger, err := app.Logger.(*logging.ZeroLogger).WithFile(node.ParseLogFileFlags(c))
 	if err != nil {
-		logger.Error().Err(err).Str("db", app.BlocklessConfig.FunctionDB).Msg("could not open pebble function database")
+		return nil, err
+	}
+	logger := nodeLogger.Inner()
+	// the node metrics are served along the operator ones; they are only collected when running without an operator
+	reg := prometheus.NewRegistry()
+	if app.Operator != nil {
+		reg = app.Operator.MetricsRegistry()
+	}
+	p2pNode := node.NewNode(logger, cfg, recorder, reg)
+	if app.Operator != nil {
+		p2pNode.BindOperator(node.OperatorSigner{Address: app.Operator.OperatorAddress(), Sign: app.Operator.SignPersonalMessage})
+		p2pNode.OnVerification(func(v node.ExecutionVerification) {
+			if v.Outcome == node.VerificationDisputed {
+				app.Operator.AlertExecutionDispute(ctx, newExecutionDisputeAlert(v))
+			}
+		})
+	}
+	if err := p2pNode.Start(ctx); err != nil {
+		logger.Error().Err(err).Msg("could not start p2p node")
+		return nil, err
 	}
-	defer fdb.Close()
+	return p2pNode, nil
+}
 
-	// Boot P2P Network
-	node.RunP2P(ctx, logger, *app.BlocklessConfig, done, failed, pdb, fdb)
+// newExecutionDisputeAlert returns the alert of the workers disputing the result of an execution of the head node.
+func newExecutionDisputeAlert(v node.ExecutionVerification) operator.ExecutionDisputeAlert {
+	alert := operator.ExecutionDisputeAlert{RequestId: v.RequestID, FunctionId: v.FunctionID, Result: v.Result}
+	for _, worker := range v.Dissenting() {
+		dissenting := operator.DissentingWorker{PeerId: worker.Peer.String(), Result: worker.Result}
+		if worker.Operator != nil {
+			dissenting.Operator = worker.Operator.Hex()
+		}
+		alert.Dissenting = append(alert.Dissenting, dissenting)
+	}
+	return alert
+}
 
-	if !app.Headless {
-		logger.Info().Msg("Opening browser...")
-		// 	go func() {
-		// 		waitForServer(serverURL)
-		// 		openbrowser(serverURL)
-		// 	}()
+func stopNode(app *avs.AppConfig, p2pNode *node.Node) {
+	if err := p2pNode.Stop(); err != nil {
+		app.Logger.Error("Could not stop p2p node cleanly", "err", err)
 	}
+}
+
+// startApiServer serves the node api, with the routes of the p2p node, in a separate goroutine, sending the error to
+// failed if the server stops unexpectedly.
+func startApiServer(app *avs.AppConfig, p2pNode *node.Node, failed chan<- error) *http.Server {
+	logger := app.Logger.(*logging.ZeroLogger).Inner()
+	router := http.NewServeMux()
+
+	// Register API routes.
+	node.RegisterAPIRoutes(app, router)
+	node.RegisterP2PRoutes(p2pNode, router)
 
-	// Start API in a separate goroutine.
 	v1 := http.NewServeMux()
 	v1.Handle("/v1/", http.StripPrefix("/v1", router))
+	if !app.Headless {
+		v1.Handle("/", dashboardHandler())
+	}
 	middlewares := Middlewares(app)
 	server := &http.Server{
-		Addr:    ":8080",
+		Addr:    apiServerAddr,
 		Handler: middlewares(v1),
 	}
 	go func() {
 		logger.Info().Msgf("Server listening on %s", server.Addr)
 		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logger.Warn().Err(err).Msg("Closed Server")
-			close(failed)
+			failed <- fmt.Errorf("api server failed: %w", err)
 		}
 	}()
+	return server
+}
 
-	select {
-	case <-sig:
-		logger.Info().Msg("Blockless AVS stopping")
-	case <-done:
-		logger.Info().Msg("Blockless AVS P2P done")
-	case <-failed:
-		logger.Info().Msg("Blockless AVS P2P aborted")
+// dashboardHandler serves the embedded dashboard. Paths which aren't assets serve its index.html, so the dashboard
+// routes resolved client side can be loaded directly.
+func dashboardHandler() http.Handler {
+	assets, err := fs.Sub(embeddedFiles, "assets")
+	if err != nil {
+		// the directory is embedded at build time
+		panic(err)
 	}
+	fileServer := http.FileServer(http.FS(assets))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name != "" {
+			if _, err := fs.Stat(assets, name); err != nil {
+				http.ServeFileFS(w, r, assets, "index.html")
+				return
+			}
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
 
-	// If we receive a second interrupt signal, exit immediately.
-	go func() {
-		<-sig
-		logger.Warn().Msg("forcing exit")
-		os.Exit(1)
-	}()
-
-	return nil
+// openDashboard opens the dashboard in the browser once the api server answers.
+func openDashboard(app *avs.AppConfig) {
+	url := "http://localhost" + apiServerAddr + "/"
+	app.Logger.Info("Opening the dashboard in the browser", "url", url)
+	waitForServer(app, url+"v1/health")
+	openbrowser(app, url)
 }
 
 type wrappedWriter struct {
@@ -182,6 +220,6 @@ func openbrowser(app *avs.AppConfig, url string) {
 		err = fmt.Errorf("unsupported platform")
 	}
 	if err != nil {
-		app.Logger.Fatal("Failed to open browser", "err", err)
+		app.Logger.Error("Failed to open browser, open the dashboard manually", "url", url, "err", err)
 	}
 }
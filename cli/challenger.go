@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/zees-dev/blockless-avs/challenger"
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+const runChallengerCommandName = "run-challenger"
+
+func runChallengerCommand() *cli.Command {
+	return &cli.Command{
+		Name:   runChallengerCommandName,
+		Usage:  "watches oracle updates and reports the signers of incorrect prices (uses the challenger config, see config-files/challenger.yaml)",
+		Action: runChallenger,
+		Flags: []cli.Flag{config.ConfigFileFlag, config.BlocklessAVSDeploymentFileFlag,
+			config.EcdsaKeystoreFlag, config.EcdsaKeystorePasswordFileFlag, config.EcdsaPrivateKeyFlag},
+	}
+}
+
+func runChallenger(ctx *cli.Context) error {
+	c, err := config.NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	chal, err := challenger.NewChallenger(c)
+	if err != nil {
+		return err
+	}
+	return chal.Start(ctx.Context)
+}
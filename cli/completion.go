@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v2"
+)
+
+// bashCompletion and zshCompletion are the urfave/cli autocomplete scripts, with the program name filled in so they
+// work when sourced from a process substitution. They ask the binary for the completions of the words typed so far with
+// --generate-bash-completion, so they follow the command tree without being regenerated.
+const bashCompletion = `_%[1]s_bash_autocomplete() {
+  if [[ "${COMP_WORDS[0]}" != "source" ]]; then
+    local cur opts base words
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    if declare -F _init_completion >/dev/null 2>&1; then
+      _init_completion -n "=:" || return
+    else
+      COMPREPLY=()
+      _get_comp_words_by_ref -n "=:" cur prev words cword
+    fi
+    words=("${words[@]:0:$cword}")
+    if [[ "$cur" == "-"* ]]; then
+      requestComp="${words[*]} ${cur} --generate-bash-completion"
+    else
+      requestComp="${words[*]} --generate-bash-completion"
+    fi
+    opts=$(eval "${requestComp}" 2>/dev/null)
+    COMPREPLY=($(compgen -W "${opts}" -- ${cur}))
+    return 0
+  fi
+}
+
+complete -o bashdefault -o default -o nospace -F _%[1]s_bash_autocomplete %[2]s
+`
+
+const zshCompletion = `#compdef %[2]s
+
+_%[1]s_zsh_autocomplete() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} ${cur} --generate-bash-completion)}")
+  else
+    opts=("${(@f)$(${words[@]:0:#words[@]-1} --generate-bash-completion)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _%[1]s_zsh_autocomplete %[2]s
+`
+
+var CompletionProgramFlag = &cli.StringFlag{
+	Name:  "program",
+	Usage: "name of the binary the completions are for (default: name the binary was run with)",
+}
+
+func completionCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "completion",
+		Usage:     "prints the shell completion script for bash, zsh or fish, eg. source <(blockless-avs avs completion bash)",
+		ArgsUsage: "bash|zsh|fish",
+		Action:    printCompletion,
+		Flags:     []cli.Flag{CompletionProgramFlag},
+	}
+}
+
+func printCompletion(c *cli.Context) error {
+	program := c.String(CompletionProgramFlag.Name)
+	if program == "" {
+		program = filepath.Base(os.Args[0])
+	}
+	function := shellIdentifier(filepath.Base(program))
+
+	switch shell := c.Args().First(); shell {
+	case "bash":
+		fmt.Printf(bashCompletion, function, program)
+	case "zsh":
+		fmt.Printf(zshCompletion, function, program)
+	case "fish":
+		// the fish script is generated from the command tree, under the app name
+		c.App.Name = program
+		script, err := c.App.ToFishCompletion()
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+	default:
+		return fmt.Errorf("unknown shell %q, must be bash, zsh or fish", shell)
+	}
+	return nil
+}
+
+// shellIdentifier replaces the characters which aren't allowed in shell function names, like dashes.
+func shellIdentifier(name string) string {
+	identifier := []rune(name)
+	for i, r := range identifier {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			identifier[i] = '_'
+		}
+	}
+	return string(identifier)
+}
@@ -0,0 +1,536 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/blssigner"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/remotesigner"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+	"github.com/zees-dev/blockless-avs/operator"
+	"github.com/zees-dev/blockless-avs/types"
+)
+
+// timeout of every live connectivity check
+const connectivityCheckTimeout = 5 * time.Second
+
+var (
+	CheckConnectivityFlag = &cli.BoolFlag{
+		Name:  "check-connectivity",
+		Usage: "also check that the eth nodes and aggregators are reachable and the listening ports are free",
+	}
+	MigrateOutputFlag = &cli.StringFlag{
+		Name:  "output-file",
+		Usage: "write the migrated config to `FILE` (- for stdout) instead of replacing --config, which is kept as <config>.v<version>.bak",
+	}
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "operator config tools",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "validate",
+				Usage:  "validates the operator config file (uses --config) before starting the node; the p2p flags are checked for port collisions",
+				Action: validateConfig,
+				Flags: []cli.Flag{
+					config.ConfigFileFlag,
+					CheckConnectivityFlag,
+					node.HostPort,
+					node.Websocket,
+					node.WebsocketPort,
+					node.QUIC,
+					node.QUICPort,
+				},
+			},
+			{
+				Name:   "migrate",
+				Usage:  "upgrades an operator, aggregator or challenger config (uses --config) of an older version to the current one",
+				Action: migrateConfig,
+				Flags:  []cli.Flag{config.ConfigFileFlag, MigrateOutputFlag},
+			},
+		},
+	}
+}
+
+// configProblem is an invalid setting of the config file.
+type configProblem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// configValidation is the json output of config validate.
+type configValidation struct {
+	Path     string          `json:"path"`
+	Valid    bool            `json:"valid"`
+	Problems []configProblem `json:"problems"`
+}
+
+// configValidator collects the problems of a node config.
+type configValidator struct {
+	cfg      types.NodeConfig
+	problems []configProblem
+}
+
+func (v *configValidator) add(field, format string, args ...any) {
+	v.problems = append(v.problems, configProblem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// listener is an address the avs listens on.
+type listener struct {
+	field string
+	// udp for the QUIC port, tcp otherwise
+	network string
+	host    string
+	port    int
+}
+
+func validateConfig(c *cli.Context) error {
+	path := c.String(config.ConfigFileFlag.Name)
+	cfg, err := readNodeConfigStrict(c, path)
+	if err != nil {
+		return fmt.Errorf("could not load config file %s: %w", path, err)
+	}
+	if err := node.ApplyConfig(c, cfg.B7s); err != nil {
+		return err
+	}
+	v := &configValidator{cfg: cfg}
+	v.checkAddresses()
+	v.checkUrls()
+	v.checkKeys()
+	listeners := v.checkListeners(c)
+	if c.Bool(CheckConnectivityFlag.Name) {
+		v.checkConnectivity(c.Context, listeners)
+	}
+
+	if outputFormat == jsonOutput {
+		if err := printJson(configValidation{Path: path, Valid: len(v.problems) == 0, Problems: append([]configProblem{}, v.problems...)}); err != nil {
+			return err
+		}
+	} else if len(v.problems) == 0 {
+		fmt.Printf("%s is valid\n", path)
+	} else {
+		for _, problem := range v.problems {
+			fmt.Printf("%s: %s\n", problem.Field, problem.Message)
+		}
+	}
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errResultPrinted{fmt.Errorf("%s has %d problem(s)", path, len(v.problems))}
+}
+
+// configMigration is the json output of config migrate.
+type configMigration struct {
+	Path string `json:"path"`
+	// file the migrated config was written to, and the backup of the original one when it was replaced
+	Output string `json:"output,omitempty"`
+	Backup string `json:"backup,omitempty"`
+	config.ConfigMigrationResult
+}
+
+func migrateConfig(c *cli.Context) error {
+	path := c.String(config.ConfigFileFlag.Name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	migrated, result, err := config.MigrateConfig(content, strings.EqualFold(filepath.Ext(path), ".toml"))
+	if err != nil {
+		return fmt.Errorf("could not migrate %s: %w", path, err)
+	}
+	res := configMigration{Path: path, ConfigMigrationResult: result}
+	if result.From == result.To {
+		return printResult(res, fmt.Sprintf("%s is already config version %d", path, result.To))
+	}
+
+	output := c.String(MigrateOutputFlag.Name)
+	if output == "-" {
+		// the migrated config is the output
+		_, err := os.Stdout.Write(migrated)
+		return err
+	}
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if output == "" {
+		output = path
+		res.Backup = fmt.Sprintf("%s.v%d.bak", path, result.From)
+		if err := os.WriteFile(res.Backup, content, mode); err != nil {
+			return err
+		}
+	}
+	// written next to the output first, so that an interrupted migration never leaves a truncated config
+	tmp := output + ".migrating"
+	if err := os.WriteFile(tmp, migrated, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, output); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	res.Output = output
+
+	text := []string{fmt.Sprintf("Migrated %s from config version %d to %d", path, result.From, result.To)}
+	for _, change := range result.Changes {
+		text = append(text, "  "+change)
+	}
+	if res.Backup != "" {
+		text = append(text, fmt.Sprintf("The original config was kept as %s", res.Backup))
+	} else {
+		text = append(text, fmt.Sprintf("Wrote %s", output))
+	}
+	return printResult(res, strings.Join(text, "\n"))
+}
+
+// readNodeConfig reads the operator config with its env var overrides, filling the settings it doesn't set with the
+// network preset of --network.
+func readNodeConfig(c *cli.Context, path string) (types.NodeConfig, error) {
+	var cfg types.NodeConfig
+	if err := config.ReadConfigFile(path, config.OperatorEnvPrefix, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, applyNetwork(c, &cfg)
+}
+
+// readNodeConfigStrict is like readNodeConfig, but rejects the unknown keys (usually typos, silently ignored otherwise).
+func readNodeConfigStrict(c *cli.Context, path string) (types.NodeConfig, error) {
+	var cfg types.NodeConfig
+	if err := config.ReadConfigFileStrict(path, config.OperatorEnvPrefix, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, applyNetwork(c, &cfg)
+}
+
+func applyNetwork(c *cli.Context, cfg *types.NodeConfig) error {
+	network, err := config.NetworkFromFlag(c)
+	if err != nil {
+		return err
+	}
+	return cfg.ApplyNetwork(network)
+}
+
+func (v *configValidator) checkAddresses() {
+	v.checkAddress("operator_address", v.cfg.OperatorAddress, true)
+	v.checkAddress("avs_registry_coordinator_address", v.cfg.AVSRegistryCoordinatorAddress, true)
+	v.checkAddress("operator_state_retriever_address", v.cfg.OperatorStateRetrieverAddress, true)
+	v.checkAddress("token_strategy_addr", v.cfg.TokenStrategyAddr, v.cfg.RegisterOperatorOnStartup)
+	v.checkAddress("avs_service_manager_addr", v.cfg.AVSServiceManagerAddress, false)
+	if v.cfg.RemoteSigner.Enabled() {
+		if v.checkAddress("remote_signer.address", v.cfg.RemoteSigner.Address, true) &&
+			common.IsHexAddress(v.cfg.OperatorAddress) &&
+			common.HexToAddress(v.cfg.RemoteSigner.Address) != common.HexToAddress(v.cfg.OperatorAddress) {
+			v.add("remote_signer.address", "must be the operator_address %s, the transactions are sent from it", v.cfg.OperatorAddress)
+		}
+	}
+}
+
+// checkAddress checks that the address is a valid, non zero, ethereum address, and reports whether it is.
+func (v *configValidator) checkAddress(field, address string, required bool) bool {
+	if address == "" {
+		if required {
+			v.add(field, "is required")
+		}
+		return false
+	}
+	if !common.IsHexAddress(address) {
+		v.add(field, "%q is not a hex ethereum address (0x followed by 40 hex characters)", address)
+		return false
+	}
+	if common.HexToAddress(address) == (common.Address{}) {
+		v.add(field, "is the zero address")
+		return false
+	}
+	return true
+}
+
+func (v *configValidator) checkUrls() {
+	v.checkUrl("eth_rpc_url", v.cfg.EthRpcUrl, "http", "https", "ws", "wss")
+	v.checkUrl("eth_ws_url", v.cfg.EthWsUrl, "ws", "wss")
+	v.checkHostPort("aggregator_server_ip_port_address", v.cfg.AggregatorServerIpPortAddress, true)
+	for i, backup := range v.cfg.Aggregators.Backups {
+		v.checkHostPort(fmt.Sprintf("aggregators.backups[%d]", i), backup, true)
+	}
+	switch v.cfg.Aggregators.Mode {
+	case "", operator.FailoverMode, operator.FanoutMode:
+	default:
+		v.add("aggregators.mode", "%q must be %s or %s", v.cfg.Aggregators.Mode, operator.FailoverMode, operator.FanoutMode)
+	}
+	if v.cfg.RemoteSigner.Enabled() {
+		v.checkUrl("remote_signer.url", v.cfg.RemoteSigner.Url, "http", "https")
+		switch v.cfg.RemoteSigner.Api {
+		case "", remotesigner.Web3SignerApi, remotesigner.ClefApi:
+		default:
+			v.add("remote_signer.api", "%q must be %s or %s", v.cfg.RemoteSigner.Api, remotesigner.Web3SignerApi, remotesigner.ClefApi)
+		}
+	}
+	switch v.cfg.BlsSigner.Backend {
+	case "", blssigner.KeystoreBackend:
+	case blssigner.RemoteBackend:
+		v.checkUrl("bls_signer.url", v.cfg.BlsSigner.Url, "http", "https")
+	default:
+		v.add("bls_signer.backend", "%q must be %s or %s", v.cfg.BlsSigner.Backend, blssigner.KeystoreBackend, blssigner.RemoteBackend)
+	}
+}
+
+func (v *configValidator) checkUrl(field, rawUrl string, schemes ...string) {
+	if rawUrl == "" {
+		v.add(field, "is required")
+		return
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		v.add(field, "%q is not a valid url: %v", rawUrl, err)
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return
+		}
+	}
+	v.add(field, "%q must be a %v url, eg. %s://localhost:8545", rawUrl, schemes, schemes[0])
+}
+
+// checkHostPort checks that addr is a host:port address and returns its port, or -1 when it isn't valid.
+func (v *configValidator) checkHostPort(field, addr string, required bool) int {
+	if addr == "" {
+		if required {
+			v.add(field, "is required")
+		}
+		return -1
+	}
+	_, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		v.add(field, "%q must be a host:port address, eg. localhost:8090: %v", addr, err)
+		return -1
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 0 || port > 65535 {
+		v.add(field, "%q has an invalid port", addr)
+		return -1
+	}
+	return port
+}
+
+func (v *configValidator) checkKeys() {
+	if !v.cfg.RemoteSigner.Enabled() {
+		v.checkKeystore("ecdsa_private_key_store_path", v.cfg.EcdsaPrivateKeyStorePath)
+	}
+	if v.cfg.BlsSigner.Backend != blssigner.RemoteBackend {
+		v.checkKeystore("bls_private_key_store_path", v.cfg.BlsPrivateKeyStorePath)
+	}
+	v.checkFile("ecdsa_key_password_file", v.cfg.EcdsaKeyPasswordFile)
+	v.checkFile("bls_key_password_file", v.cfg.BlsKeyPasswordFile)
+	if path := v.cfg.B7s.PrivateNetworkKey; v.checkFile("b7s.private_network_key", path) {
+		if _, err := node.ReadPrivateNetworkKey(path); err != nil {
+			v.add("b7s.private_network_key", "%v", err)
+		}
+	}
+	v.checkFile("b7s.websocket_tls_cert", v.cfg.B7s.WebsocketTLSCert)
+	v.checkFile("b7s.websocket_tls_key", v.cfg.B7s.WebsocketTLSKey)
+	v.checkFile("b7s.attributes_file", v.cfg.B7s.AttributesFile)
+}
+
+// checkKeystore checks that the keystore file exists and is a json keystore. The key isn't decrypted.
+func (v *configValidator) checkKeystore(field, path string) {
+	if path == "" {
+		v.add(field, "is required")
+		return
+	}
+	if !v.checkFile(field, path) {
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		v.add(field, "could not read %s: %v", path, err)
+		return
+	}
+	var keystore struct {
+		Crypto json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(content, &keystore); err != nil || keystore.Crypto == nil {
+		v.add(field, "%s is not an encrypted json keystore", path)
+	}
+}
+
+// checkFile checks that the file, if set, exists and is a regular file, and reports whether it does.
+func (v *configValidator) checkFile(field, path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		v.add(field, "%s does not exist (relative paths are resolved from the working directory)", path)
+		return false
+	}
+	if err != nil {
+		v.add(field, "could not access %s: %v", path, err)
+		return false
+	}
+	if !info.Mode().IsRegular() {
+		v.add(field, "%s is not a file", path)
+		return false
+	}
+	return true
+}
+
+// checkListeners checks the addresses the avs listens on, and that no two of them use the same port.
+func (v *configValidator) checkListeners(c *cli.Context) []listener {
+	var listeners []listener
+	addListener := func(field, network, addr string) {
+		port := v.checkHostPort(field, addr, true)
+		if port < 0 {
+			return
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		listeners = append(listeners, listener{field: field, network: network, host: host, port: port})
+	}
+	if v.cfg.EnableMetrics {
+		addListener("eigen_metrics_ip_port_address", "tcp", v.cfg.EigenMetricsIpPortAddress)
+	}
+	if v.cfg.EnableNodeApi {
+		addListener("node_api_ip_port_address", "tcp", v.cfg.NodeApiIpPortAddress)
+	}
+	addListener("api server", "tcp", apiServerAddr)
+	addListener("--"+node.HostPort.Name, "tcp", net.JoinHostPort("", strconv.FormatUint(uint64(c.Uint(node.HostPort.Name)), 10)))
+	if c.Bool(node.Websocket.Name) {
+		addListener("--"+node.WebsocketPort.Name, "tcp", net.JoinHostPort("", strconv.FormatUint(uint64(c.Uint(node.WebsocketPort.Name)), 10)))
+	}
+	if c.Bool(node.QUIC.Name) {
+		addListener("--"+node.QUICPort.Name, "udp", net.JoinHostPort("", strconv.FormatUint(uint64(c.Uint(node.QUICPort.Name)), 10)))
+	}
+
+	for i, a := range listeners {
+		for _, b := range listeners[:i] {
+			// port 0 picks a free port
+			if a.port != 0 && a.port == b.port && a.network == b.network && hostsOverlap(a.host, b.host) {
+				v.add(a.field, "port %d is also used by %s", a.port, b.field)
+			}
+		}
+	}
+	return listeners
+}
+
+// hostsOverlap reports whether listening on both hosts conflicts, ie. they're the same or one is a wildcard.
+func hostsOverlap(a, b string) bool {
+	isWildcard := func(host string) bool { return host == "" || host == "0.0.0.0" || host == "::" }
+	return a == b || isWildcard(a) || isWildcard(b)
+}
+
+// checkConnectivity checks that the eth nodes and aggregators are reachable and the listening ports are free.
+func (v *configValidator) checkConnectivity(ctx context.Context, listeners []listener) {
+	rpcChainId := v.checkEthNode(ctx, "eth_rpc_url", v.cfg.EthRpcUrl, true)
+	wsChainId := v.checkEthNode(ctx, "eth_ws_url", v.cfg.EthWsUrl, false)
+	v.checkChainIds(rpcChainId, wsChainId)
+	v.checkAggregators()
+	v.checkPorts(listeners)
+}
+
+// checkChainIds checks that the eth nodes are on the same chain, the one of the config if set. A chain id of 0 is an
+// unreachable node and isn't checked.
+func (v *configValidator) checkChainIds(rpcChainId, wsChainId uint64) {
+	if rpcChainId != 0 && wsChainId != 0 && rpcChainId != wsChainId {
+		v.add("eth_ws_url", "is on chain %d but eth_rpc_url is on chain %d", wsChainId, rpcChainId)
+	}
+	if v.cfg.ChainId != 0 && rpcChainId != 0 && rpcChainId != v.cfg.ChainId {
+		v.add("chain_id", "is %d but eth_rpc_url is on chain %d, is it the right network?", v.cfg.ChainId, rpcChainId)
+	}
+}
+
+// checkAggregators checks that the aggregator and its backups accept connections.
+func (v *configValidator) checkAggregators() {
+	aggregators := append([]string{v.cfg.AggregatorServerIpPortAddress}, v.cfg.Aggregators.Backups...)
+	for i, addr := range aggregators {
+		field := "aggregator_server_ip_port_address"
+		if i > 0 {
+			field = fmt.Sprintf("aggregators.backups[%d]", i-1)
+		}
+		if addr == "" {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", addr, connectivityCheckTimeout)
+		if err != nil {
+			v.add(field, "aggregator %s is unreachable: %v", addr, err)
+			continue
+		}
+		conn.Close()
+	}
+}
+
+// checkPorts checks that the listening ports are free.
+func (v *configValidator) checkPorts(listeners []listener) {
+	for _, l := range listeners {
+		if l.port == 0 {
+			continue
+		}
+		addr := net.JoinHostPort(l.host, strconv.Itoa(l.port))
+		var ln io.Closer
+		var err error
+		if l.network == "udp" {
+			ln, err = net.ListenPacket("udp", addr)
+		} else {
+			ln, err = net.Listen("tcp", addr)
+		}
+		if err != nil {
+			v.add(l.field, "can't listen on %s port %d, is another process using it? %v", l.network, l.port, err)
+			continue
+		}
+		ln.Close()
+	}
+}
+
+// checkEthNode checks that the eth node is reachable, and optionally that the avs registry coordinator is deployed on
+// its chain. It returns the chain id, or 0 when the node is unreachable.
+func (v *configValidator) checkEthNode(ctx context.Context, field, rawUrl string, checkContract bool) uint64 {
+	if rawUrl == "" {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, connectivityCheckTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, rawUrl)
+	if err != nil {
+		v.add(field, "could not connect to %s: %v", rawUrl, err)
+		return 0
+	}
+	defer client.Close()
+	chainId, err := client.ChainID(ctx)
+	if err != nil {
+		v.add(field, "%s did not answer eth_chainId: %v", rawUrl, err)
+		return 0
+	}
+	if checkContract {
+		v.checkContractCode(ctx, client, chainId.Uint64(), "avs_registry_coordinator_address", v.cfg.AVSRegistryCoordinatorAddress)
+	}
+	return chainId.Uint64()
+}
+
+// checkContractCode checks that a contract is deployed at the address, if it is a valid one, on the chain of the client.
+func (v *configValidator) checkContractCode(ctx context.Context, client *ethclient.Client, chainId uint64, field, address string) {
+	if !common.IsHexAddress(address) {
+		return
+	}
+	code, err := client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		v.add(field, "could not read the code at %s: %v", address, err)
+	} else if len(code) == 0 {
+		v.add(field, "no contract is deployed at %s on chain %d, is it the right network?", address, chainId)
+	}
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+// maintainedDB is a database managed by avs db, named after its directory in the snapshots. Its path is set by flag.
+type maintainedDB struct {
+	name, flag, path string
+	schema           node.DBSchema
+}
+
+var maintainedDBs = []maintainedDB{
+	{name: "peer-db", flag: node.PeerDatabasePath.Name, schema: node.PeerDBSchema},
+	{name: "function-db", flag: node.FunctionDatabasePath.Name, schema: node.FunctionDBSchema},
+}
+
+var (
+	dbNamesFlag = &cli.StringSliceFlag{
+		Name:  "db",
+		Usage: "databases to maintain: peer-db, function-db (default: both)",
+	}
+	replaceDBFlag = &cli.BoolFlag{
+		Name:  "replace",
+		Usage: "replace the existing databases",
+	}
+)
+
+// dbResult is the result of avs db for a database.
+type dbResult struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// size of the database files in bytes, after the compaction for avs db compact
+	Size uint64 `json:"size"`
+	// size before the compaction
+	SizeBefore uint64 `json:"sizeBefore,omitempty"`
+}
+
+func dbCommand() *cli.Command {
+	// the databases are read from the b7s section of --config when it exists, unless --peer-db/--function-db are set
+	flags := []cli.Flag{config.ConfigFileFlag, node.PeerDatabasePath, node.FunctionDatabasePath, dbNamesFlag}
+	return &cli.Command{
+		Name:  "db",
+		Usage: "maintains the pebble peer and function databases of the p2p node; the node must be stopped",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "backup",
+				Usage:     "writes a consistent snapshot of the databases to a new directory, or to a tarball when it ends with .tar.gz or .tgz",
+				ArgsUsage: "<snapshot dir|tarball>",
+				Action:    backupDBs,
+				Flags:     flags,
+			},
+			{
+				Name:      "restore",
+				Usage:     "restores the databases from a snapshot directory or tarball written by avs db backup",
+				ArgsUsage: "<snapshot dir|tarball>",
+				Action:    restoreDBs,
+				Flags:     append(flags, replaceDBFlag),
+			},
+			{
+				Name:   "migrate",
+				Usage:  "upgrades the databases to the schema version of this release, as the node does on startup",
+				Action: migrateDBs,
+				Flags:  flags,
+			},
+			{
+				Name:   "compact",
+				Usage:  "compacts the databases, reclaiming the space of the deleted and overwritten records",
+				Action: compactDBs,
+				Flags:  flags,
+			},
+		},
+	}
+}
+
+// selectedDBs returns the databases selected with --db, with their path.
+func selectedDBs(c *cli.Context) ([]maintainedDB, error) {
+	if _, err := readOptionalNodeConfig(c); err != nil {
+		return nil, err
+	}
+	names := c.StringSlice(dbNamesFlag.Name)
+	for _, name := range names {
+		if !slices.ContainsFunc(maintainedDBs, func(db maintainedDB) bool { return db.name == name }) {
+			return nil, fmt.Errorf("invalid --db %s, expected peer-db or function-db", name)
+		}
+	}
+	var dbs []maintainedDB
+	for _, db := range maintainedDBs {
+		if len(names) == 0 || slices.Contains(names, db.name) {
+			db.path = c.String(db.flag)
+			dbs = append(dbs, db)
+		}
+	}
+	return dbs, nil
+}
+
+// progress prints the progress of avs db to stderr, keeping stdout for the result.
+func progress(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func isTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+func backupDBs(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("expected the snapshot dir or tarball")
+	}
+	dest := c.Args().First()
+	dbs, err := selectedDBs(c)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+
+	dir := dest
+	if isTarball(dest) {
+		if dir, err = os.MkdirTemp(filepath.Dir(dest), ".avs-db-backup-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	results := []dbResult{}
+	for _, db := range dbs {
+		if _, err := os.Stat(db.path); errors.Is(err, fs.ErrNotExist) {
+			progress("skipping %s, no database at %s", db.name, db.path)
+			continue
+		}
+		size, err := node.DBSize(db.path)
+		if err != nil {
+			return err
+		}
+		progress("backing up %s %s (%s)", db.name, db.path, formatBytes(size))
+		if err := node.BackupDB(db.path, filepath.Join(dir, db.name)); err != nil {
+			return err
+		}
+		results = append(results, dbResult{Name: db.name, Path: db.path, Size: size})
+	}
+	if len(results) == 0 {
+		return errors.New("no database to back up")
+	}
+	if isTarball(dest) {
+		progress("archiving the snapshot to %s", dest)
+		if err := writeTarball(dir, dest); err != nil {
+			os.Remove(dest)
+			return err
+		}
+	}
+	return printResult(struct {
+		Snapshot  string     `json:"snapshot"`
+		Databases []dbResult `json:"databases"`
+	}{Snapshot: dest, Databases: results}, fmt.Sprintf("Backed up %d database(s) to %s", len(results), dest))
+}
+
+func restoreDBs(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("expected the snapshot dir or tarball")
+	}
+	snapshot := c.Args().First()
+	dbs, err := selectedDBs(c)
+	if err != nil {
+		return err
+	}
+	dir := snapshot
+	if isTarball(snapshot) {
+		if dir, err = os.MkdirTemp("", "avs-db-restore-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		progress("extracting %s", snapshot)
+		if err := extractTarball(snapshot, dir); err != nil {
+			return fmt.Errorf("could not extract %s: %w", snapshot, err)
+		}
+	}
+
+	results := []dbResult{}
+	for _, db := range dbs {
+		src := filepath.Join(dir, db.name)
+		if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+			progress("skipping %s, not in the snapshot", db.name)
+			continue
+		}
+		size, err := node.DBSize(src)
+		if err != nil {
+			return err
+		}
+		progress("restoring %s to %s (%s)", db.name, db.path, formatBytes(size))
+		if err := node.RestoreDB(src, db.path, c.Bool(replaceDBFlag.Name)); err != nil {
+			if errors.Is(err, node.ErrDBExists) {
+				return fmt.Errorf("could not restore %s: %w, use --%s to replace it", db.name, err, replaceDBFlag.Name)
+			}
+			return fmt.Errorf("could not restore %s: %w", db.name, err)
+		}
+		results = append(results, dbResult{Name: db.name, Path: db.path, Size: size})
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no database to restore in %s", snapshot)
+	}
+	return printResult(struct {
+		Databases []dbResult `json:"databases"`
+	}{Databases: results}, fmt.Sprintf("Restored %d database(s) from %s", len(results), snapshot))
+}
+
+func compactDBs(c *cli.Context) error {
+	dbs, err := selectedDBs(c)
+	if err != nil {
+		return err
+	}
+	results := []dbResult{}
+	var text []string
+	for _, db := range dbs {
+		if _, err := os.Stat(db.path); errors.Is(err, fs.ErrNotExist) {
+			progress("skipping %s, no database at %s", db.name, db.path)
+			continue
+		}
+		before, err := node.DBSize(db.path)
+		if err != nil {
+			return err
+		}
+		progress("compacting %s %s (%s)", db.name, db.path, formatBytes(before))
+		if err := node.CompactDB(db.path); err != nil {
+			return err
+		}
+		after, err := node.DBSize(db.path)
+		if err != nil {
+			return err
+		}
+		results = append(results, dbResult{Name: db.name, Path: db.path, Size: after, SizeBefore: before})
+		text = append(text, fmt.Sprintf("Compacted %s from %s to %s", db.name, formatBytes(before), formatBytes(after)))
+	}
+	if len(results) == 0 {
+		return errors.New("no database to compact")
+	}
+	return printResult(struct {
+		Databases []dbResult `json:"databases"`
+	}{Databases: results}, strings.Join(text, "\n"))
+}
+
+func migrateDBs(c *cli.Context) error {
+	dbs, err := selectedDBs(c)
+	if err != nil {
+		return err
+	}
+	type dbMigration struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+		node.SchemaMigration
+	}
+	results := []dbMigration{}
+	var text []string
+	for _, db := range dbs {
+		if _, err := os.Stat(db.path); errors.Is(err, fs.ErrNotExist) {
+			progress("skipping %s, no database at %s", db.name, db.path)
+			continue
+		}
+		progress("migrating %s %s", db.name, db.path)
+		migration, err := node.MigrateDB(db.path, db.schema)
+		if err != nil {
+			return fmt.Errorf("could not migrate %s: %w", db.name, err)
+		}
+		results = append(results, dbMigration{Name: db.name, Path: db.path, SchemaMigration: migration})
+		if migration.From == migration.To {
+			text = append(text, fmt.Sprintf("%s is up to date (schema version %d)", db.name, migration.To))
+		} else {
+			text = append(text, fmt.Sprintf("Migrated %s from schema version %d to %d", db.name, migration.From, migration.To))
+		}
+	}
+	if len(results) == 0 {
+		return errors.New("no database to migrate")
+	}
+	return printResult(struct {
+		Databases []dbMigration `json:"databases"`
+	}{Databases: results}, strings.Join(text, "\n"))
+}
+
+// writeTarball writes the files of dir to a gzipped tarball at dest, relative to dir.
+func writeTarball(dir, dest string) error {
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// extractTarball extracts the directories and regular files of the gzipped tarball at path to dir.
+func extractTarball(path, dir string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %s in tarball", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// formatBytes formats a size in bytes with a binary unit, eg. 1.5 MiB.
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/operator"
+)
+
+const (
+	// how long to wait for the anvil rpc server started with --devnet-fork-url
+	anvilStartTimeout = 30 * time.Second
+	// mock tokens deposited into the strategy when registering the devnet operator
+	devnetDepositAmount = 1000
+)
+
+// balance the devnet operator is funded with, 100 eth
+var devnetOperatorBalance = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+
+var (
+	DevnetFlag = &cli.BoolFlag{
+		Name:  "devnet",
+		Usage: "run against a local anvil chain: register the operator and generate oracle tasks periodically",
+	}
+	DevnetForkUrlFlag = &cli.StringFlag{
+		Name:    "devnet-fork-url",
+		Usage:   "start anvil forking the chain at `URL`, listening on the port of the operator eth_rpc_url (default: use the running anvil)",
+		EnvVars: []string{"DEVNET_FORK_URL"},
+	}
+	DevnetTaskIntervalFlag = &cli.DurationFlag{
+		Name:  "devnet-task-interval",
+		Usage: "interval between the oracle tasks generated on the devnet",
+		Value: 30 * time.Second,
+	}
+	DevnetSymbolsFlag = &cli.StringSliceFlag{
+		Name:  "devnet-symbols",
+		Usage: "coingecko ids of the assets the generated oracle tasks are requested for, in turn",
+		Value: cli.NewStringSlice("bitcoin", "ethereum"),
+	}
+)
+
+func devnetFlags() []cli.Flag {
+	return []cli.Flag{DevnetFlag, DevnetForkUrlFlag, DevnetTaskIntervalFlag, DevnetSymbolsFlag}
+}
+
+// startDevnet starts anvil when a fork url is given, and makes sure the operator and aggregator configs point to an
+// anvil chain so the devnet never touches a live network. The returned stop function terminates anvil.
+func startDevnet(ctx context.Context, c *cli.Context, withAggregator bool) (stop func(), err error) {
+	nodeConfig, err := readNodeConfig(c, c.String(config.ConfigFileFlag.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	stop = func() {}
+	if forkUrl := c.String(DevnetForkUrlFlag.Name); forkUrl != "" {
+		stop, err = startAnvil(ctx, forkUrl, nodeConfig.EthRpcUrl)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rpcUrls := []string{nodeConfig.EthRpcUrl}
+	if withAggregator {
+		var aggConfigRaw config.ConfigRaw
+		if err := config.ReadConfigFile(c.String(AggregatorConfigFileFlag.Name), config.AggregatorEnvPrefix, &aggConfigRaw); err != nil {
+			stop()
+			return nil, err
+		}
+		rpcUrls = append(rpcUrls, aggConfigRaw.EthRpcUrl)
+	}
+	for _, rpcUrl := range rpcUrls {
+		if err := requireAnvil(ctx, rpcUrl); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+
+	if err := fundAccount(ctx, nodeConfig.EthRpcUrl, common.HexToAddress(nodeConfig.OperatorAddress), devnetOperatorBalance); err != nil {
+		stop()
+		return nil, err
+	}
+	return stop, nil
+}
+
+// startAnvil runs anvil forking forkUrl on the port of rpcUrl, and waits for its rpc server.
+func startAnvil(ctx context.Context, forkUrl string, rpcUrl string) (stop func(), err error) {
+	u, err := url.Parse(rpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_rpc_url: %w", err)
+	}
+	port := u.Port()
+	if port == "" {
+		return nil, fmt.Errorf("eth_rpc_url %s has no port to start anvil on", rpcUrl)
+	}
+
+	cmd := exec.Command("anvil", "--fork-url", forkUrl, "--port", port)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start anvil: %w", err)
+	}
+	stop = func() {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			cmd.Process.Kill()
+		}
+		cmd.Wait()
+	}
+
+	deadline := time.Now().Add(anvilStartTimeout)
+	for {
+		err := requireAnvil(ctx, rpcUrl)
+		if err == nil {
+			return stop, nil
+		}
+		if time.Now().After(deadline) {
+			stop()
+			return nil, fmt.Errorf("anvil did not start: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			stop()
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// requireAnvil returns an error unless the node at rpcUrl is anvil.
+func requireAnvil(ctx context.Context, rpcUrl string) error {
+	client, err := rpc.DialContext(ctx, rpcUrl)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	var version string
+	if err := client.CallContext(ctx, &version, "web3_clientVersion"); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(strings.ToLower(version), "anvil") {
+		return fmt.Errorf("devnet mode only runs against anvil, but %s is %s", rpcUrl, version)
+	}
+	return nil
+}
+
+// fundAccount sets the balance of the account on the anvil chain at rpcUrl.
+func fundAccount(ctx context.Context, rpcUrl string, account common.Address, balance *big.Int) error {
+	client, err := rpc.DialContext(ctx, rpcUrl)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.CallContext(ctx, nil, "anvil_setBalance", account, hexutil.EncodeBig(balance)); err != nil {
+		return fmt.Errorf("could not fund %s: %w", account, err)
+	}
+	return nil
+}
+
+// registerDevnetOperator registers the operator with eigenlayer and the avs unless it already is.
+func registerDevnetOperator(app *avs.AppConfig) error {
+	operatorEcdsaPrivKey, err := readOperatorEcdsaKey(app)
+	if err != nil {
+		return err
+	}
+	strategyAddr := common.HexToAddress(app.NodeConfig.TokenStrategyAddr)
+	return app.Operator.EnsureRegistered(operatorEcdsaPrivKey, strategyAddr, big.NewInt(devnetDepositAmount))
+}
+
+// generateDevnetTasks requests an oracle update every interval, cycling through the symbols, until ctx is cancelled.
+func generateDevnetTasks(ctx context.Context, app *avs.AppConfig, symbols []string, interval time.Duration) {
+	defer reporting.Recover()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			symbol := symbols[i%len(symbols)]
+			app.Logger.Info("Generating devnet oracle task", "symbol", symbol)
+			if err := app.Operator.RequestOracleTask(&operator.OracleTask{Symbol: symbol}); err != nil {
+				app.Logger.Error("Failed to generate devnet oracle task", "symbol", symbol, "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/blssigner"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/keystore"
+	"github.com/zees-dev/blockless-avs/core/logging"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+	"github.com/zees-dev/blockless-avs/types"
+)
+
+const (
+	checkPassed  = "pass"
+	checkFailed  = "fail"
+	checkSkipped = "skip"
+)
+
+func doctorCommand() *cli.Command {
+	return &cli.Command{
+		Name: "doctor",
+		Usage: "diagnoses the operator setup (uses --config): the config, the eth rpc and ws nodes, the chain id, the " +
+			"avs contracts, the keystores decryption, the aggregators and the listening ports; reports each check",
+		Action: runDoctor,
+		Flags: []cli.Flag{
+			config.ConfigFileFlag,
+			node.HostPort,
+			node.Websocket,
+			node.WebsocketPort,
+			node.QUIC,
+			node.QUICPort,
+		},
+	}
+}
+
+// doctorCheck is the result of one of the doctor checks.
+type doctorCheck struct {
+	Name     string          `json:"name"`
+	Status   string          `json:"status"`
+	Problems []configProblem `json:"problems"`
+	// why the check was skipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// doctorReport is the json output of avs doctor.
+type doctorReport struct {
+	Path    string        `json:"path"`
+	Healthy bool          `json:"healthy"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+// doctor runs the checks on the config, each collecting its problems with a configValidator of its own.
+type doctor struct {
+	cfg    types.NodeConfig
+	checks []doctorCheck
+}
+
+// run runs the check, which returns why it was skipped, if it was.
+func (d *doctor) run(name string, check func(v *configValidator) string) {
+	v := &configValidator{cfg: d.cfg}
+	reason := check(v)
+	result := doctorCheck{Name: name, Status: checkPassed, Problems: append([]configProblem{}, v.problems...), Reason: reason}
+	switch {
+	case len(v.problems) > 0:
+		result.Status = checkFailed
+	case reason != "":
+		result.Status = checkSkipped
+	}
+	d.checks = append(d.checks, result)
+}
+
+func runDoctor(c *cli.Context) error {
+	path := c.String(config.ConfigFileFlag.Name)
+	cfg, err := readNodeConfigStrict(c, path)
+	if err != nil {
+		return fmt.Errorf("could not load config file %s: %w", path, err)
+	}
+	if err := node.ApplyConfig(c, cfg.B7s); err != nil {
+		return err
+	}
+	d := &doctor{cfg: cfg}
+	logger := logging.NewZeroLogger(logging.Development)
+
+	var listeners []listener
+	d.run("config", func(v *configValidator) string {
+		v.checkAddresses()
+		v.checkUrls()
+		v.checkKeys()
+		listeners = v.checkListeners(c)
+		return ""
+	})
+	var rpcChainId, wsChainId uint64
+	d.run("eth rpc node", func(v *configValidator) string {
+		rpcChainId = v.checkEthNode(c.Context, "eth_rpc_url", cfg.EthRpcUrl, false)
+		return skipUnset("eth_rpc_url", cfg.EthRpcUrl)
+	})
+	d.run("eth ws node", func(v *configValidator) string {
+		wsChainId = v.checkEthNode(c.Context, "eth_ws_url", cfg.EthWsUrl, false)
+		return skipUnset("eth_ws_url", cfg.EthWsUrl)
+	})
+	d.run("chain id", func(v *configValidator) string {
+		if rpcChainId == 0 {
+			return "the eth rpc node is unreachable"
+		}
+		v.checkChainIds(rpcChainId, wsChainId)
+		return ""
+	})
+	d.run("avs contracts", func(v *configValidator) string {
+		if rpcChainId == 0 {
+			return "the eth rpc node is unreachable"
+		}
+		v.checkContracts(c.Context, rpcChainId)
+		return ""
+	})
+	d.run("ecdsa key", func(v *configValidator) string {
+		if cfg.RemoteSigner.Enabled() {
+			return "the transactions are signed by the remote signer"
+		}
+		if cfg.EcdsaPrivateKeyStorePath == "" {
+			return "ecdsa_private_key_store_path is not set"
+		}
+		key, err := keystore.ReadEcdsaKey(cfg.EcdsaPrivateKeyStorePath, cfg.EcdsaKeyPassword(), logger)
+		if err != nil {
+			v.add("ecdsa_private_key_store_path", "%v", err)
+			return ""
+		}
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		if common.IsHexAddress(cfg.OperatorAddress) && address != common.HexToAddress(cfg.OperatorAddress) {
+			v.add("ecdsa_private_key_store_path", "is the key of %s, not of the operator_address %s", address.Hex(), cfg.OperatorAddress)
+		}
+		return ""
+	})
+	d.run("bls key", func(v *configValidator) string {
+		if cfg.BlsSigner.Backend == blssigner.RemoteBackend {
+			return "the tasks are signed by the remote bls signer"
+		}
+		if cfg.BlsPrivateKeyStorePath == "" {
+			return "bls_private_key_store_path is not set"
+		}
+		if _, err := keystore.ReadBlsKey(cfg.BlsPrivateKeyStorePath, cfg.BlsKeyPassword(), logger); err != nil {
+			v.add("bls_private_key_store_path", "%v", err)
+		}
+		return ""
+	})
+	d.run("aggregators", func(v *configValidator) string {
+		v.checkAggregators()
+		return ""
+	})
+	d.run("listening ports", func(v *configValidator) string {
+		v.checkPorts(listeners)
+		return ""
+	})
+
+	report := doctorReport{Path: path, Healthy: true, Checks: d.checks}
+	var text strings.Builder
+	failed := 0
+	for _, check := range d.checks {
+		fmt.Fprintf(&text, "%-4s  %s", strings.ToUpper(check.Status), check.Name)
+		if check.Reason != "" {
+			fmt.Fprintf(&text, " (%s)", check.Reason)
+		}
+		text.WriteString("\n")
+		for _, problem := range check.Problems {
+			fmt.Fprintf(&text, "      %s: %s\n", problem.Field, problem.Message)
+		}
+		if check.Status == checkFailed {
+			report.Healthy = false
+			failed++
+		}
+	}
+	if err := printResult(report, strings.TrimSuffix(text.String(), "\n")); err != nil {
+		return err
+	}
+	if failed == 0 {
+		return nil
+	}
+	return errResultPrinted{fmt.Errorf("%d of %d checks failed", failed, len(d.checks))}
+}
+
+// skipUnset returns the reason to skip the check of an optional setting which isn't set.
+func skipUnset(field, value string) string {
+	if value == "" {
+		return field + " is not set"
+	}
+	return ""
+}
+
+// checkContracts checks that the avs contracts of the config are deployed on the chain of the eth rpc node.
+func (v *configValidator) checkContracts(ctx context.Context, chainId uint64) {
+	ctx, cancel := context.WithTimeout(ctx, connectivityCheckTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, v.cfg.EthRpcUrl)
+	if err != nil {
+		v.add("eth_rpc_url", "could not connect to %s: %v", v.cfg.EthRpcUrl, err)
+		return
+	}
+	defer client.Close()
+	v.checkContractCode(ctx, client, chainId, "avs_registry_coordinator_address", v.cfg.AVSRegistryCoordinatorAddress)
+	v.checkContractCode(ctx, client, chainId, "operator_state_retriever_address", v.cfg.OperatorStateRetrieverAddress)
+	v.checkContractCode(ctx, client, chainId, "token_strategy_addr", v.cfg.TokenStrategyAddr)
+	v.checkContractCode(ctx, client, chainId, "avs_service_manager_addr", v.cfg.AVSServiceManagerAddress)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var NodeApiUrlFlag = &cli.StringFlag{
+	Name:  "api-url",
+	Usage: "url of the node api of the running avs",
+	Value: "http://127.0.0.1:8080",
+}
+
+func drainCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "drain",
+		Usage:  "stops the running avs without missing tasks: no new tasks are accepted, and it shuts down once the in-flight responses were submitted to the aggregator",
+		Action: requestDrain,
+		Flags:  []cli.Flag{NodeApiUrlFlag},
+	}
+}
+
+// requestDrain asks the running avs to drain through its node api; the avs exits on its own once drained.
+func requestDrain(c *cli.Context) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	url := strings.TrimSuffix(c.String(NodeApiUrlFlag.Name), "/") + "/v1/api/drain"
+	resp, err := client.Post(url, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("could not request drain: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("drain request failed with status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return printResult(struct {
+		Draining bool `json:"draining"`
+	}{Draining: true}, "Draining, the avs shuts down once the in-flight responses were submitted")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/logging"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+var wasmFunctionsFlag = &cli.BoolFlag{
+	Name:  "wasm",
+	Usage: "manage the functions of the operator wasm runner, installed in wasm.workspace of --config, instead of the p2p node ones",
+}
+
+// functionEntry is a function listed by avs functions list.
+type functionEntry struct {
+	Cid  string `json:"cid"`
+	Name string `json:"name"`
+	// manifest url the function was installed from
+	Url       string    `json:"url"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// whether the archive and files are in the workspace
+	Installed bool `json:"installed"`
+}
+
+func functionsCommand() *cli.Command {
+	// the function db and workspace are read from the b7s section of --config when it exists, unless the flags are set
+	flags := []cli.Flag{config.ConfigFileFlag, node.FunctionDatabasePath, node.Workspace, wasmFunctionsFlag}
+	return &cli.Command{
+		Name:  "functions",
+		Usage: "manages the wasm functions installed in the function database, without the node; the node must be stopped",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists the installed functions, most recently updated first",
+				Action: listFunctions,
+				Flags:  flags,
+			},
+			{
+				Name:      "install",
+				Usage:     "downloads the function archive referenced by the manifest and installs it in the workspace, like the node does for a request",
+				ArgsUsage: "<cid> <manifest url>",
+				Action:    installFunction,
+				Flags:     flags,
+			},
+			{
+				Name:      "remove",
+				Usage:     "removes functions and their files from the workspace",
+				ArgsUsage: "<cid>...",
+				Action:    removeFunctions,
+				Flags:     flags,
+			},
+			{
+				Name:      "verify",
+				Usage:     "checks that the installed functions match the checksum of their manifest and that their method entries were unpacked",
+				ArgsUsage: "<cid>...",
+				Action:    verifyFunctions,
+				Flags:     flags,
+			},
+		},
+	}
+}
+
+// openFunctionDB opens the function database of the node, or of the operator wasm runner with --wasm, creating it if
+// create is set.
+func openFunctionDB(c *cli.Context, create bool) (*node.FunctionDB, error) {
+	nodeConfig, err := readOptionalNodeConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	log := *logging.NewZeroLogger(logging.Production).Inner()
+	if c.Bool(wasmFunctionsFlag.Name) {
+		workspace := nodeConfig.Wasm.Workspace
+		if workspace == "" {
+			return nil, fmt.Errorf("no wasm.workspace in config file %s", c.String(config.ConfigFileFlag.Name))
+		}
+		// like the operator wasm runner
+		return node.OpenFunctionDB(log, filepath.Join(workspace, "function-db"), workspace, create)
+	}
+	return node.OpenFunctionDB(log, c.String(node.FunctionDatabasePath.Name), c.String(node.Workspace.Name), create)
+}
+
+func listFunctions(c *cli.Context) error {
+	db, err := openFunctionDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	records, err := db.List()
+	if err != nil {
+		return err
+	}
+	functions := make([]functionEntry, 0, len(records))
+	for _, record := range records {
+		installed, err := db.Installed(record.CID)
+		if err != nil {
+			return err
+		}
+		functions = append(functions, functionEntry{
+			Cid:       record.CID,
+			Name:      record.Manifest.Name,
+			Url:       record.URL,
+			UpdatedAt: record.UpdatedAt,
+			Installed: installed,
+		})
+	}
+
+	if outputFormat == jsonOutput {
+		return printJson(functions)
+	}
+	if len(functions) == 0 {
+		fmt.Println("No functions")
+		return nil
+	}
+	w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CID\tNAME\tURL\tUPDATED\tINSTALLED")
+	for _, f := range functions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", f.Cid, f.Name, f.Url, f.UpdatedAt.Local().Format(time.DateTime), f.Installed)
+	}
+	return w.Flush()
+}
+
+func installFunction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return errors.New("expected the function cid and manifest url")
+	}
+	cid, manifestUrl := c.Args().Get(0), c.Args().Get(1)
+	if u, err := url.Parse(manifestUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid manifest url %q, it must be http(s)", manifestUrl)
+	}
+	db, err := openFunctionDB(c, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	record, installed, err := db.Install(cid, manifestUrl)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("Installed function %s (%s)", cid, record.Manifest.Name)
+	if !installed {
+		text = fmt.Sprintf("Function %s is already installed", cid)
+	}
+	return printResult(struct {
+		Cid       string `json:"cid"`
+		Name      string `json:"name"`
+		Installed bool   `json:"installed"`
+	}{Cid: cid, Name: record.Manifest.Name, Installed: installed}, text)
+}
+
+func removeFunctions(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("missing function cid")
+	}
+	db, err := openFunctionDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	removed := make([]string, 0, c.NArg())
+	var text []string
+	for _, cid := range c.Args().Slice() {
+		if err := db.Remove(cid); err != nil {
+			return fmt.Errorf("could not remove function %s: %w", cid, err)
+		}
+		removed = append(removed, cid)
+		text = append(text, fmt.Sprintf("Removed function %s", cid))
+	}
+	return printResult(struct {
+		Removed []string `json:"removed"`
+	}{Removed: removed}, strings.Join(text, "\n"))
+}
+
+func verifyFunctions(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("missing function cid")
+	}
+	db, err := openFunctionDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	results := make([]*node.FunctionVerification, 0, c.NArg())
+	failed := 0
+	for _, cid := range c.Args().Slice() {
+		v, err := db.Verify(cid)
+		if err != nil {
+			return fmt.Errorf("could not verify function %s: %w", cid, err)
+		}
+		results = append(results, v)
+		if len(v.Problems) > 0 {
+			failed++
+		}
+	}
+
+	if outputFormat == jsonOutput {
+		if err := printJson(results); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range results {
+			if len(v.Problems) == 0 {
+				fmt.Printf("%s is valid\n", v.CID)
+			}
+			for _, problem := range v.Problems {
+				fmt.Printf("%s: %s\n", v.CID, problem)
+			}
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return errResultPrinted{fmt.Errorf("%d function(s) failed verification", failed)}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+const (
+	composeFormat = "compose"
+	systemdFormat = "systemd"
+)
+
+var (
+	DeploymentFormatFlag = &cli.StringFlag{
+		Name:     "format",
+		Usage:    "format of the service definition: compose (docker compose service) or systemd (unit file)",
+		Required: true,
+	}
+	DeploymentImageFlag = &cli.StringFlag{
+		Name:  "image",
+		Usage: "docker image of the compose service",
+		Value: "bls-avs-tools:latest",
+	}
+	DeploymentBinaryFlag = &cli.StringFlag{
+		Name:  "binary",
+		Usage: "path of the bls-avs-tools binary run by the systemd unit",
+		Value: "/usr/local/bin/bls-avs-tools",
+	}
+)
+
+// deploymentMount is a path of the host used by the operator, mounted at the same path in the container.
+type deploymentMount struct {
+	Path     string
+	ReadOnly bool
+}
+
+// deployment is the operator service rendered by the deployment templates.
+type deployment struct {
+	Image      string
+	WorkingDir string
+	// run-operator command line, without the binary
+	Args   []string
+	Mounts []deploymentMount
+	Ports  []int
+}
+
+// WritableDirs returns the mounted paths the operator writes to.
+func (d deployment) WritableDirs() []string {
+	var dirs []string
+	for _, m := range d.Mounts {
+		if !m.ReadOnly {
+			dirs = append(dirs, m.Path)
+		}
+	}
+	return dirs
+}
+
+func generateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate",
+		Usage: "generates files from the operator config",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "deployment",
+				Usage:  "prints a docker compose service or systemd unit running the operator of --config with the given node flags (eg. --port), with its keystores, databases and ports",
+				Action: generateDeployment,
+				Flags: append([]cli.Flag{
+					config.ConfigFileFlag,
+					DeploymentFormatFlag,
+					DeploymentImageFlag,
+					DeploymentBinaryFlag,
+				}, nodeFlags()...),
+			},
+		},
+	}
+}
+
+func generateDeployment(c *cli.Context) error {
+	format := c.String(DeploymentFormatFlag.Name)
+	if format != composeFormat && format != systemdFormat {
+		return fmt.Errorf("unknown format %q, must be %s or %s", format, composeFormat, systemdFormat)
+	}
+	configPath := c.String(config.ConfigFileFlag.Name)
+	cfg, err := readNodeConfigStrict(c, configPath)
+	if err != nil {
+		return fmt.Errorf("could not load config file %s: %w", configPath, err)
+	}
+	if err := node.ApplyConfig(c, cfg.B7s); err != nil {
+		return err
+	}
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	d := deployment{
+		Image:      c.String(DeploymentImageFlag.Name),
+		WorkingDir: workingDir,
+		Args:       append([]string{runOperatorCommandName, "--" + config.ConfigFileFlag.Name, configPath}, nodeFlagArgs(c)...),
+	}
+
+	// the relative paths of the config resolve against the working dir, which is the same in the container
+	addMount := func(path string, readOnly bool) {
+		if path == "" {
+			return
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workingDir, path)
+		}
+		path = filepath.Clean(path)
+		for i, m := range d.Mounts {
+			if m.Path == path {
+				d.Mounts[i].ReadOnly = m.ReadOnly && readOnly
+				return
+			}
+		}
+		d.Mounts = append(d.Mounts, deploymentMount{Path: path, ReadOnly: readOnly})
+	}
+	addMount(configPath, true)
+	addMount(cfg.EcdsaPrivateKeyStorePath, true)
+	addMount(cfg.EcdsaKeyPasswordFile, true)
+	addMount(cfg.BlsPrivateKeyStorePath, true)
+	addMount(cfg.BlsKeyPasswordFile, true)
+	addMount(cfg.Wasm.RuntimeDir, true)
+	addMount(c.String(node.RuntimePath.Name), true)
+	// pebble databases
+	addMount(c.String(node.PeerDatabasePath.Name), false)
+	addMount(c.String(node.FunctionDatabasePath.Name), false)
+	addMount(cfg.ResponseQueue.Dir, false)
+	addMount(c.String(node.Workspace.Name), false)
+	addMount(cfg.Wasm.Workspace, false)
+	addMount(cfg.Artifacts.Dir, false)
+	if cfg.Standby.Enabled && cfg.Standby.LeaseFile != "" {
+		addMount(filepath.Dir(cfg.Standby.LeaseFile), false)
+	}
+	if cfg.LogFile.Path != "" {
+		addMount(filepath.Dir(cfg.LogFile.Path), false)
+	}
+	if path := c.String(node.LogFile.Name); path != "" {
+		addMount(filepath.Dir(path), false)
+	}
+
+	v := &configValidator{cfg: cfg}
+	for _, l := range v.checkListeners(c) {
+		if l.port == 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s listens on a random port, set it to publish it\n", l.field)
+		} else if !slices.Contains(d.Ports, l.port) {
+			d.Ports = append(d.Ports, l.port)
+		}
+	}
+	for _, problem := range v.problems {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", problem.Field, problem.Message)
+	}
+
+	tmpl := composeTemplate
+	if format == systemdFormat {
+		tmpl = systemdTemplate
+		d.Args = append([]string{c.String(DeploymentBinaryFlag.Name)}, d.Args...)
+		// the worker executes functions in its own cgroup
+		if c.String(node.Role.Name) == blockless.WorkerNodeLabel {
+			d.Mounts = append(d.Mounts, deploymentMount{Path: "/sys/fs/cgroup"})
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, d); err != nil {
+		return err
+	}
+	return printResult(struct {
+		Format  string `json:"format"`
+		Content string `json:"content"`
+	}{Format: format, Content: out.String()}, strings.TrimSuffix(out.String(), "\n"))
+}
+
+// nodeFlagArgs returns the node flags set on the command line, to pass them on to run-operator.
+func nodeFlagArgs(c *cli.Context) []string {
+	// the flags set from the b7s section of the config are IsSet too, but the config is passed on, so the command line is
+	// looked up instead
+	passed := map[string]bool{}
+	for _, arg := range os.Args[1:] {
+		if name, ok := strings.CutPrefix(arg, "-"); ok {
+			name, _, _ = strings.Cut(strings.TrimPrefix(name, "-"), "=")
+			passed[name] = true
+		}
+	}
+	var args []string
+	for _, f := range nodeFlags() {
+		name := f.Names()[0]
+		if !slices.ContainsFunc(f.Names(), func(n string) bool { return passed[n] }) {
+			continue
+		}
+		var value string
+		switch f := f.(type) {
+		case *cli.StringSliceFlag:
+			value = strings.Join(c.StringSlice(name), ",")
+		case *cli.TimestampFlag:
+			value = c.Timestamp(name).Format(f.Layout)
+		case *cli.DurationFlag:
+			value = c.Duration(name).String()
+		default:
+			value = fmt.Sprint(c.Value(name))
+		}
+		args = append(args, "--"+name+"="+value)
+	}
+	return args
+}
+
+var deploymentFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	// systemd splits ExecStart on whitespace unless quoted
+	"execArg": func(arg string) string {
+		if strings.ContainsAny(arg, " \t\"'\\") {
+			return strconv.Quote(arg)
+		}
+		return arg
+	},
+}
+
+var composeTemplate = template.Must(template.New("compose").Funcs(deploymentFuncs).Parse(`# generated by avs generate deployment; the paths used by the operator are mounted at the same location
+services:
+  blockless-avs-operator:
+    image: {{quote .Image}}
+    restart: unless-stopped
+    # the operator stops cleanly on SIGINT
+    stop_signal: SIGINT
+    working_dir: {{quote .WorkingDir}}
+    command:
+{{- range .Args}}
+      - {{quote .}}
+{{- end}}
+{{- if .Ports}}
+    ports:
+{{- range .Ports}}
+      - "{{.}}:{{.}}"
+{{- end}}
+{{- end}}
+{{- if .Mounts}}
+    volumes:
+{{- range .Mounts}}
+      - {{quote (printf "%s:%s%s" .Path .Path (or (and .ReadOnly ":ro") ""))}}
+{{- end}}
+{{- end}}
+`))
+
+var systemdTemplate = template.Must(template.New("systemd").Funcs(deploymentFuncs).Parse(`# generated by avs generate deployment, eg. /etc/systemd/system/blockless-avs-operator.service
+[Unit]
+Description=Blockless AVS operator
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+WorkingDirectory={{.WorkingDir}}
+ExecStart={{range $i, $arg := .Args}}{{if $i}} {{end}}{{execArg $arg}}{{end}}
+# the operator stops cleanly on SIGINT
+KillSignal=SIGINT
+Restart=on-failure
+RestartSec=5
+NoNewPrivileges=true
+{{- with .WritableDirs}}
+# the data directories are created before the filesystem is made read-only for the operator
+ExecStartPre=+/bin/mkdir -p{{range .}} {{execArg .}}{{end}}
+ProtectSystem=strict
+ReadWritePaths={{range $i, $dir := .}}{{if $i}} {{end}}{{execArg $dir}}{{end}}
+{{- end}}
+{{- if .Ports}}
+# listens on {{range $i, $port := .Ports}}{{if $i}}, {{end}}{{$port}}{{end}}
+{{- end}}
+
+[Install]
+WantedBy=multi-user.target
+`))
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+var replaceIdentityFlag = &cli.BoolFlag{
+	Name:  "replace",
+	Usage: "replace the key file when it holds another identity",
+}
+
+// identityResult is the output of the identity commands, which never print the private key except for export.
+type identityResult struct {
+	PeerId string `json:"peerId"`
+	// node key file
+	Path string `json:"path"`
+}
+
+func identityCommand() *cli.Command {
+	// the key file is read from the b7s section of --config when it exists, unless --private-key is set
+	flags := []cli.Flag{config.ConfigFileFlag, node.PrivateKey}
+	return &cli.Command{
+		Name: "identity",
+		Usage: "manages the libp2p identity (peer id) of the p2p node, stored in its key file (--private-key or " +
+			"b7s.private_key); without a key file the node gets a new identity on every start",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "show",
+				Usage:  "prints the peer id of the node key file",
+				Action: showIdentity,
+				Flags:  flags,
+			},
+			{
+				Name:   "generate",
+				Usage:  "writes a new identity to the node key file",
+				Action: generateIdentity,
+				Flags:  append(flags, replaceIdentityFlag),
+			},
+			{
+				Name:   "export",
+				Usage:  "prints the identity of the node key file as json, with its private key, to be imported on another machine",
+				Action: exportIdentity,
+				Flags:  flags,
+			},
+			{
+				Name:      "import",
+				Usage:     "writes the identity exported by avs identity export (- for stdin) to the node key file, keeping the peer id of the exporting node",
+				ArgsUsage: "<identity file>",
+				Action:    importIdentity,
+				Flags:     append(flags, replaceIdentityFlag),
+			},
+		},
+	}
+}
+
+// identityKeyPath returns the node key file of --private-key or of the b7s section of --config.
+func identityKeyPath(c *cli.Context) (string, error) {
+	if _, err := readOptionalNodeConfig(c); err != nil {
+		return "", err
+	}
+	path := c.String(node.PrivateKey.Name)
+	if path == "" {
+		return "", fmt.Errorf("the node key file is not set, set --%s or b7s.private_key of the config", node.PrivateKey.Name)
+	}
+	return path, nil
+}
+
+func showIdentity(c *cli.Context) error {
+	path, err := identityKeyPath(c)
+	if err != nil {
+		return err
+	}
+	key, err := node.ReadIdentityKey(path)
+	if err != nil {
+		return err
+	}
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	return printResult(identityResult{PeerId: id.String(), Path: path}, id.String())
+}
+
+func generateIdentity(c *cli.Context) error {
+	path, err := identityKeyPath(c)
+	if err != nil {
+		return err
+	}
+	key, err := node.GenerateIdentityKey()
+	if err != nil {
+		return err
+	}
+	return writeIdentity(c, path, key, "Generated")
+}
+
+func exportIdentity(c *cli.Context) error {
+	path, err := identityKeyPath(c)
+	if err != nil {
+		return err
+	}
+	key, err := node.ReadIdentityKey(path)
+	if err != nil {
+		return err
+	}
+	identity, err := node.NewIdentity(key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "The exported identity holds the private key of peer %s, keep it secret\n", identity.PeerId)
+	return printJson(identity)
+}
+
+func importIdentity(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("expected the identity file as single argument")
+	}
+	path, err := identityKeyPath(c)
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if file := c.Args().First(); file == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	var identity node.Identity
+	if err := json.Unmarshal(content, &identity); err != nil {
+		return fmt.Errorf("invalid identity file: %w", err)
+	}
+	key, err := identity.Key()
+	if err != nil {
+		return err
+	}
+	return writeIdentity(c, path, key, "Imported")
+}
+
+func writeIdentity(c *cli.Context, path string, key crypto.PrivKey, action string) error {
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := node.WriteIdentityKey(path, key, c.Bool(replaceIdentityFlag.Name)); err != nil {
+		if errors.Is(err, node.ErrIdentityExists) {
+			return fmt.Errorf("%w, pass --%s to replace it", err, replaceIdentityFlag.Name)
+		}
+		return err
+	}
+	return printResult(identityResult{PeerId: id.String(), Path: path}, fmt.Sprintf("%s identity %s in %s", action, id, path))
+}
@@ -0,0 +1,672 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+const (
+	anvilNetwork   = "anvil"
+	holeskyNetwork = "holesky"
+	customNetwork  = "custom"
+
+	generateKey = "generate"
+	importKey   = "import"
+	existingKey = "keystore"
+)
+
+var InitDirFlag = &cli.StringFlag{
+	Name:  "dir",
+	Usage: "directory the operator config and keystores are written to",
+	Value: "blockless-avs",
+}
+
+// initContracts are the avs contract addresses written to the operator config.
+type initContracts struct {
+	RegistryCoordinator    string
+	OperatorStateRetriever string
+	TokenStrategy          string
+	ServiceManager         string
+}
+
+// deploymentOutput is the blockless avs deployment output written by the contract deployment scripts.
+type deploymentOutput struct {
+	Addresses struct {
+		RegistryCoordinator    string `json:"registryCoordinator"`
+		OperatorStateRetriever string `json:"operatorStateRetriever"`
+		TokenStrategy          string `json:"erc20MockStrategy"`
+		ServiceManager         string `json:"blocklessAVSServiceManager"`
+	} `json:"addresses"`
+}
+
+// anvilContracts are the contracts deployed in the saved anvil state, of the local network preset.
+var anvilContracts = initContracts{
+	RegistryCoordinator:    config.LocalNetwork.RegistryCoordinator,
+	OperatorStateRetriever: config.LocalNetwork.OperatorStateRetriever,
+	TokenStrategy:          config.LocalNetwork.TokenStrategy,
+	ServiceManager:         config.LocalNetwork.ServiceManager,
+}
+
+// initConfig are the answers of the setup wizard, rendered with operatorConfigTemplate.
+type initConfig struct {
+	Network           string
+	Roles             []string
+	EthRpcUrl         string
+	EthWsUrl          string
+	ChainId           uint64
+	Contracts         initContracts
+	OperatorAddress   string
+	EcdsaKeystore     string
+	EcdsaPasswordFile string
+	BlsKeystore       string
+	BlsPasswordFile   string
+	AggregatorAddr    string
+	MetricsAddr       string
+	NodeApiAddr       string
+	P2PPort           int
+	ResponseQueueDir  string
+}
+
+// initResult is the json output of init; the prompts and next steps go to stderr.
+type initResult struct {
+	Config          string          `json:"config"`
+	OperatorAddress string          `json:"operatorAddress"`
+	EcdsaKeystore   string          `json:"ecdsaKeystore"`
+	BlsKeystore     string          `json:"blsKeystore"`
+	Problems        []configProblem `json:"problems"`
+}
+
+func initCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "init",
+		Usage:  "interactively creates the operator config and keystores (network, rpc endpoints, keys, ports and roles)",
+		Action: runInit,
+		Flags:  []cli.Flag{InitDirFlag},
+	}
+}
+
+func runInit(c *cli.Context) error {
+	dir := c.String(InitDirFlag.Name)
+	configPath := filepath.Join(dir, "operator.yaml")
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+	fmt.Fprintf(p.out, "Blockless AVS operator setup, writing to %s\n\n", dir)
+
+	if _, err := os.Stat(configPath); err == nil {
+		overwrite, err := p.yes(fmt.Sprintf("%s already exists, overwrite it?", configPath), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return errAborted
+		}
+	}
+
+	cfg := initConfig{}
+	var err error
+	if cfg.Network, err = p.choose("Network", []string{anvilNetwork, holeskyNetwork, customNetwork}, holeskyNetwork); err != nil {
+		return err
+	}
+	if err := askEndpoints(p, &cfg); err != nil {
+		return err
+	}
+	if err := askContracts(p, &cfg); err != nil {
+		return err
+	}
+	if err := askRoles(p, &cfg); err != nil {
+		return err
+	}
+
+	keysDir := filepath.Join(dir, "keys")
+	ecdsaKey, err := askEcdsaKey(p, keysDir, &cfg)
+	if err != nil {
+		return err
+	}
+	cfg.OperatorAddress = crypto.PubkeyToAddress(ecdsaKey.PublicKey).Hex()
+	if err := askBlsKey(p, keysDir, &cfg); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	cfg.ResponseQueueDir = filepath.Join(dir, "response-queue")
+	if err := writeOperatorConfig(configPath, cfg); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "\nWrote %s for operator %s\n", configPath, cfg.OperatorAddress)
+
+	// the same checks as avs config validate, so the next steps don't fail on a setting the wizard let through
+	written, err := readNodeConfigStrict(c, configPath)
+	if err != nil {
+		return fmt.Errorf("could not read back %s: %w", configPath, err)
+	}
+	v := &configValidator{cfg: written}
+	v.checkAddresses()
+	v.checkUrls()
+	v.checkKeys()
+	v.checkListeners(c)
+	for _, problem := range v.problems {
+		fmt.Fprintf(p.out, "warning: %s: %s\n", problem.Field, problem.Message)
+	}
+
+	printNextSteps(p.out, configPath, cfg)
+	if outputFormat == jsonOutput {
+		return printJson(initResult{
+			Config:          configPath,
+			OperatorAddress: cfg.OperatorAddress,
+			EcdsaKeystore:   cfg.EcdsaKeystore,
+			BlsKeystore:     cfg.BlsKeystore,
+			Problems:        append([]configProblem{}, v.problems...),
+		})
+	}
+	return nil
+}
+
+func askEndpoints(p *prompter, cfg *initConfig) error {
+	rpcDefault, wsDefault := "", ""
+	switch cfg.Network {
+	case anvilNetwork:
+		rpcDefault, wsDefault = "http://localhost:8545", "ws://localhost:8545"
+		cfg.ChainId = config.LocalNetwork.ChainId
+	case holeskyNetwork:
+		rpcDefault, wsDefault = "https://ethereum-holesky-rpc.publicnode.com", "wss://ethereum-holesky-rpc.publicnode.com"
+		cfg.ChainId = config.HoleskyNetwork.ChainId
+	}
+	var err error
+	if cfg.EthRpcUrl, err = p.askValid("Eth rpc url (http or websocket)", rpcDefault, urlCheck("http", "https", "ws", "wss")); err != nil {
+		return err
+	}
+	if strings.HasPrefix(cfg.EthRpcUrl, "ws") && wsDefault == "" {
+		wsDefault = cfg.EthRpcUrl
+	}
+	cfg.EthWsUrl, err = p.askValid("Eth websocket url (used for event subscriptions)", wsDefault, urlCheck("ws", "wss"))
+	return err
+}
+
+func askContracts(p *prompter, cfg *initConfig) error {
+	if cfg.Network == anvilNetwork {
+		cfg.Contracts = anvilContracts
+		return nil
+	}
+	path, err := p.ask("Blockless avs deployment output file (blockless_avs_deployment_output.json, leave empty to enter the addresses)", "")
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("could not read deployment output: %w", err)
+		}
+		var deployment deploymentOutput
+		if err := json.Unmarshal(content, &deployment); err != nil {
+			return fmt.Errorf("could not parse deployment output %s: %w", path, err)
+		}
+		cfg.Contracts = initContracts(deployment.Addresses)
+		return nil
+	}
+	if cfg.Contracts.RegistryCoordinator, err = p.askValid("Registry coordinator address", "", addressCheck(true)); err != nil {
+		return err
+	}
+	if cfg.Contracts.OperatorStateRetriever, err = p.askValid("Operator state retriever address", "", addressCheck(true)); err != nil {
+		return err
+	}
+	if cfg.Contracts.ServiceManager, err = p.askValid("Service manager address (optional)", "", addressCheck(false)); err != nil {
+		return err
+	}
+	cfg.Contracts.TokenStrategy, err = p.askValid("Token strategy address deposited into by `avs operator deposit` (optional)", "", addressCheck(false))
+	return err
+}
+
+func askRoles(p *prompter, cfg *initConfig) error {
+	answer, err := p.askValid(
+		fmt.Sprintf("Roles run by this machine (comma separated: %s, %s, %s)", aggregatorRole, operatorRole, nodeRole),
+		operatorRole+","+nodeRole,
+		func(answer string) error {
+			roles := splitList(answer)
+			for _, role := range roles {
+				if role != aggregatorRole && role != operatorRole && role != nodeRole {
+					return fmt.Errorf("unknown role %q", role)
+				}
+			}
+			if !slices.Contains(roles, operatorRole) {
+				return fmt.Errorf("the %s role is required, the config written is the operator config", operatorRole)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	cfg.Roles = splitList(answer)
+
+	aggregatorDefault := ""
+	if cfg.Network == anvilNetwork || slices.Contains(cfg.Roles, aggregatorRole) {
+		aggregatorDefault = "localhost:8090"
+	}
+	if cfg.AggregatorAddr, err = p.askValid("Aggregator rpc address (host:port)", aggregatorDefault, hostPortCheck); err != nil {
+		return err
+	}
+	if cfg.MetricsAddr, err = p.askValid("Metrics listen address", "localhost:9090", hostPortCheck); err != nil {
+		return err
+	}
+	if cfg.NodeApiAddr, err = p.askValid("Eigenlayer node api listen address", "localhost:9010", hostPortCheck); err != nil {
+		return err
+	}
+	if slices.Contains(cfg.Roles, nodeRole) {
+		port, err := p.askValid("P2p port (0 picks a free port)", "0", func(answer string) error {
+			if port, err := strconv.Atoi(answer); err != nil || port < 0 || port > 65535 {
+				return errors.New("must be a port number")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		cfg.P2PPort, _ = strconv.Atoi(port)
+	}
+	return nil
+}
+
+// askEcdsaKey generates, imports or references the operator ecdsa keystore, and returns the key.
+func askEcdsaKey(p *prompter, keysDir string, cfg *initConfig) (*ecdsa.PrivateKey, error) {
+	mode, err := p.choose("Operator ecdsa key", []string{generateKey, importKey, existingKey}, generateKey)
+	if err != nil {
+		return nil, err
+	}
+	password := keyPassword(ecdsaKeyType)
+	if mode == existingKey {
+		cfg.EcdsaKeystore, err = p.askValid("Ecdsa keystore path", "", fileCheck)
+		if err != nil {
+			return nil, err
+		}
+		pass, err := password.Password()
+		if err != nil {
+			return nil, err
+		}
+		key, err := sdkecdsa.ReadKey(cfg.EcdsaKeystore, pass)
+		if err != nil {
+			return nil, fmt.Errorf("could not decrypt ecdsa keystore %s: %w", cfg.EcdsaKeystore, err)
+		}
+		return key, nil
+	}
+
+	var key *ecdsa.PrivateKey
+	if mode == generateKey {
+		key, err = crypto.GenerateKey()
+	} else {
+		var hexKey string
+		hexKey, err = p.askValid("Ecdsa private key (hex)", "", func(answer string) error {
+			_, err := crypto.HexToECDSA(strings.TrimPrefix(answer, "0x"))
+			return err
+		})
+		if err == nil {
+			key, err = crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	pass, err := newKeystorePassword(password)
+	if err != nil {
+		return nil, err
+	}
+	cfg.EcdsaKeystore = filepath.Join(keysDir, "operator.ecdsa.key.json")
+	if err := sdkecdsa.WriteKey(cfg.EcdsaKeystore, key, pass); err != nil {
+		return nil, fmt.Errorf("could not write ecdsa keystore: %w", err)
+	}
+	if cfg.EcdsaPasswordFile, err = askPasswordFile(p, keysDir, "operator.ecdsa.password", pass); err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(p.out, "Wrote ecdsa keystore %s (address %s)\n", cfg.EcdsaKeystore, crypto.PubkeyToAddress(key.PublicKey).Hex())
+	return key, nil
+}
+
+// askBlsKey generates, imports or references the operator bls keystore.
+func askBlsKey(p *prompter, keysDir string, cfg *initConfig) error {
+	mode, err := p.choose("Operator bls key", []string{generateKey, importKey, existingKey}, generateKey)
+	if err != nil {
+		return err
+	}
+	if mode == existingKey {
+		cfg.BlsKeystore, err = p.askValid("Bls keystore path", "", fileCheck)
+		return err
+	}
+
+	var keyPair *bls.KeyPair
+	if mode == generateKey {
+		keyPair, err = bls.GenRandomBlsKeys()
+	} else {
+		var sk string
+		sk, err = p.askValid("Bls private key (decimal or 0x hex)", "", func(answer string) error {
+			_, err := bls.NewKeyPairFromString(answer)
+			return err
+		})
+		if err == nil {
+			keyPair, err = bls.NewKeyPairFromString(sk)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	pass, err := newKeystorePassword(keyPassword(blsKeyType))
+	if err != nil {
+		return err
+	}
+	cfg.BlsKeystore = filepath.Join(keysDir, "operator.bls.key.json")
+	if err := keyPair.SaveToFile(cfg.BlsKeystore, pass); err != nil {
+		return fmt.Errorf("could not write bls keystore: %w", err)
+	}
+	if cfg.BlsPasswordFile, err = askPasswordFile(p, keysDir, "operator.bls.password", pass); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "Wrote bls keystore %s (operator id %s)\n", cfg.BlsKeystore, blsKeyInfo(cfg.BlsKeystore, keyPair).OperatorId)
+	return nil
+}
+
+// askPasswordFile optionally writes the keystore password to a file readable by its owner only, so the operator can
+// restart unattended. It returns the path of the file, or an empty string when the password isn't written.
+func askPasswordFile(p *prompter, keysDir, name, password string) (string, error) {
+	write, err := p.yes("Write the password to a file, so the operator starts without asking for it?", false)
+	if err != nil || !write {
+		return "", err
+	}
+	path := filepath.Join(keysDir, name)
+	if err := os.WriteFile(path, []byte(password+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("could not write password file: %w", err)
+	}
+	return path, nil
+}
+
+func writeOperatorConfig(path string, cfg initConfig) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create operator config: %w", err)
+	}
+	defer file.Close()
+	if err := operatorConfigTemplate.Execute(file, cfg); err != nil {
+		return fmt.Errorf("could not write operator config: %w", err)
+	}
+	return file.Close()
+}
+
+func printNextSteps(out io.Writer, configPath string, cfg initConfig) {
+	fmt.Fprintln(out, "\nNext steps:")
+	fmt.Fprintf(out, "  avs config validate --config %s --check-connectivity\n", configPath)
+	if cfg.Network != anvilNetwork {
+		fmt.Fprintf(out, "  fund %s with eth for the registration transactions\n", cfg.OperatorAddress)
+	}
+	fmt.Fprintf(out, "  avs operator register --config %s\n", configPath)
+	run := fmt.Sprintf("  avs all-in-one --config %s --roles %s", configPath, strings.Join(cfg.Roles, ","))
+	if slices.Contains(cfg.Roles, nodeRole) {
+		run += fmt.Sprintf(" --port %d", cfg.P2PPort)
+	}
+	if slices.Contains(cfg.Roles, aggregatorRole) {
+		run += " --aggregator-config <aggregator.yaml> --blockless-avs-deployment <deployment output> --ecdsa-keystore <aggregator keystore>"
+	}
+	fmt.Fprintln(out, run)
+}
+
+// prompter asks questions on the terminal, reading the answers from in.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask returns the answer to the question, or def when the answer is empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, err := p.in.ReadString('\n')
+	if err != nil && (answer == "" || !errors.Is(err, io.EOF)) {
+		return "", errAborted
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// askValid asks the question until the answer passes check.
+func (p *prompter) askValid(question, def string, check func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if err := check(answer); err != nil {
+			fmt.Fprintf(p.out, "  invalid answer: %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+func (p *prompter) choose(question string, options []string, def string) (string, error) {
+	return p.askValid(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def, func(answer string) error {
+		if !slices.Contains(options, answer) {
+			return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+		}
+		return nil
+	})
+}
+
+func (p *prompter) yes(question string, def bool) (bool, error) {
+	defAnswer := "n"
+	if def {
+		defAnswer = "y"
+	}
+	answer, err := p.choose(question, []string{"y", "n"}, defAnswer)
+	return answer == "y", err
+}
+
+func splitList(answer string) []string {
+	var items []string
+	for _, item := range strings.Split(answer, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func urlCheck(schemes ...string) func(string) error {
+	return func(answer string) error {
+		v := &configValidator{}
+		v.checkUrl("url", answer, schemes...)
+		if len(v.problems) > 0 {
+			return errors.New(v.problems[0].Message)
+		}
+		return nil
+	}
+}
+
+func addressCheck(required bool) func(string) error {
+	return func(answer string) error {
+		if answer == "" && !required {
+			return nil
+		}
+		v := &configValidator{}
+		v.checkAddress("address", answer, true)
+		if len(v.problems) > 0 {
+			return errors.New(v.problems[0].Message)
+		}
+		return nil
+	}
+}
+
+func hostPortCheck(answer string) error {
+	if _, _, err := net.SplitHostPort(answer); err != nil {
+		return fmt.Errorf("must be a host:port address: %v", err)
+	}
+	return nil
+}
+
+func fileCheck(answer string) error {
+	if _, err := os.Stat(answer); err != nil {
+		return err
+	}
+	return nil
+}
+
+// operatorConfigTemplate renders a complete operator config; every setting is documented in
+// config-files/operator.anvil.yaml.
+var operatorConfigTemplate = template.Must(template.New("operator.yaml").Funcs(template.FuncMap{
+	"join":          strings.Join,
+	"configVersion": func() int { return config.ConfigVersion },
+	"address": func(address string) string {
+		if address == "" {
+			return `""`
+		}
+		return common.HexToAddress(address).Hex()
+	},
+}).Parse(`# generated by avs init for the {{.Network}} network, roles: {{join .Roles ", "}}
+# every setting is documented in config-files/operator.anvil.yaml of the blockless avs repository
+# settings marked (reloadable) are reapplied without restarting on SIGHUP or POST /v1/api/config/reload
+
+# schema version of the config, upgraded by avs config migrate
+version: {{configVersion}}
+
+# this sets the logger level (true = info, false = debug) (reloadable)
+production: {{ne .Network "anvil"}}
+
+operator_address: {{address .OperatorAddress}}
+
+avs_registry_coordinator_address: {{address .Contracts.RegistryCoordinator}}
+operator_state_retriever_address: {{address .Contracts.OperatorStateRetriever}}
+token_strategy_addr: {{address .Contracts.TokenStrategy}}
+avs_service_manager_addr: {{address .Contracts.ServiceManager}}
+
+eth_rpc_url: {{.EthRpcUrl}}
+eth_ws_url: {{.EthWsUrl}}
+# the operator refuses to start when eth_rpc_url is on another chain; 0 disables the check
+chain_id: {{.ChainId}}
+
+# the keystore passwords are read from the password files, else from the OPERATOR_ECDSA_KEY_PASSWORD and
+# OPERATOR_BLS_KEY_PASSWORD env vars, else they're asked for on the terminal
+ecdsa_private_key_store_path: {{.EcdsaKeystore}}
+ecdsa_key_password_file: "{{.EcdsaPasswordFile}}"
+bls_private_key_store_path: {{.BlsKeystore}}
+bls_key_password_file: "{{.BlsPasswordFile}}"
+
+remote_signer:
+  url: ""
+  api: web3signer
+  address: ""
+  timeout: 10s
+
+bls_signer:
+  backend: keystore
+  url: ""
+  timeout: 5s
+
+# (reloadable)
+gas:
+  tx_type: dynamic
+  max_fee_per_gas_gwei: 0
+  max_priority_fee_per_gas_gwei: 0
+  gas_limit_multiplier: 1.2
+
+tx_manager:
+  receipt_timeout: 1m
+  fee_bump_percentage: 20
+  send_deadline: 10m
+
+# (reloadable, as are the backups)
+aggregator_server_ip_port_address: {{.AggregatorAddr}}
+aggregators:
+  backups: []
+  mode: failover
+  health_check_interval: 10s
+
+# public operator profile registered by avs operator register-with-eigenlayer
+metadata_uri: ""
+socket: ""
+
+eigen_metrics_ip_port_address: {{.MetricsAddr}}
+# (reloadable)
+enable_metrics: true
+node_api_ip_port_address: {{.NodeApiAddr}}
+enable_node_api: true
+
+register_operator_on_startup: false
+
+clock:
+  max_skew: 2s
+  ntp_server: pool.ntp.org
+  check_interval: 5m
+
+digest:
+  schedule: daily
+  webhook:
+    url: ""
+    timeout: 10s
+
+standby:
+  enabled: false
+  lease_file: /tmp/blockless-avs-operator.lease
+  lease_duration: 30s
+  heartbeat_interval: 10s
+
+artifacts:
+  dir: ""
+  retention: 72h
+
+wasm:
+  runtime_dir: ""
+  workspace: /tmp/blockless-avs-operator/wasm
+  timeout: 30s
+
+response_queue:
+  dir: {{.ResponseQueueDir}}
+  max_age: 20m
+  initial_backoff: 1s
+  max_backoff: 1m
+
+# (reloadable)
+policy:
+  denied_task_indices: []
+  max_input_size: 0
+  allowed_function_cids: []
+  required_quorums: []
+
+quorums: []
+
+reregistration:
+  enabled: false
+  max_attempts: 5
+  initial_backoff: 1m
+  max_backoff: 1h
+
+log_file:
+  path: ""
+  max_size_mb: 100
+  rotate_every: 24h
+  max_age: 168h
+  max_backups: 7
+
+aggregator_heartbeat_interval: 30s
+
+drain_timeout: 2m
+`))
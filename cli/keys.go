@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/keystore"
+	"github.com/zees-dev/blockless-avs/core/logging"
+)
+
+const (
+	ecdsaKeyType = "ecdsa"
+	blsKeyType   = "bls"
+)
+
+var (
+	KeyTypeFlag = &cli.StringFlag{
+		Name:     "type",
+		Usage:    "type of the key: ecdsa or bls",
+		Required: true,
+	}
+	KeystorePathFlag = &cli.StringFlag{
+		Name:  "keystore",
+		Usage: "path of the keystore to write (default keys/operator.<type>.key.json)",
+	}
+	KeysDirFlag = &cli.StringFlag{
+		Name:  "dir",
+		Usage: "directory containing the keystores",
+		Value: "config-files/keys",
+	}
+)
+
+// bls keystores store the G1 pubkey as E([x,y])
+var blsPubKeyRegex = regexp.MustCompile(`^E\(\[(\d+),(\d+)\]\)$`)
+
+// keyInfo is the public information of a keystore, printed as json by the keys commands.
+type keyInfo struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	// ecdsa address
+	Address string `json:"address,omitempty"`
+	// bls pubkeys, hex encoded in the eigensdk serialization; G2 is only known once the keystore was decrypted
+	G1PubKey   string `json:"g1PubKey,omitempty"`
+	G2PubKey   string `json:"g2PubKey,omitempty"`
+	OperatorId string `json:"operatorId,omitempty"`
+}
+
+func keysCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "keys",
+		Usage: "operator key management",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "generate",
+				Usage:  "generates a new --type key into an encrypted keystore; the password is asked for, or read from OPERATOR_ECDSA_KEY_PASSWORD / OPERATOR_BLS_KEY_PASSWORD",
+				Action: generateKeystore,
+				Flags:  []cli.Flag{KeyTypeFlag, KeystorePathFlag},
+			},
+			{
+				Name:   "list",
+				Usage:  "lists the keystores in --dir with their address or pubkey and operator id, without decrypting them",
+				Action: listKeystores,
+				Flags:  []cli.Flag{KeysDirFlag},
+			},
+			{
+				Name:      "inspect",
+				Usage:     "decrypts the keystore and prints its public keys and operator id",
+				ArgsUsage: "KEYSTORE",
+				Action:    inspectKeystore,
+			},
+		},
+	}
+}
+
+func keyPassword(keyType string) keystore.PasswordSource {
+	if keyType == blsKeyType {
+		return keystore.PasswordSource{EnvVar: "OPERATOR_BLS_KEY_PASSWORD", Prompt: "Bls keystore password: "}
+	}
+	return keystore.PasswordSource{EnvVar: "OPERATOR_ECDSA_KEY_PASSWORD", Prompt: "Ecdsa keystore password: "}
+}
+
+func generateKeystore(c *cli.Context) error {
+	keyType := c.String(KeyTypeFlag.Name)
+	if keyType != ecdsaKeyType && keyType != blsKeyType {
+		return fmt.Errorf("unknown key type %q, must be %s or %s", keyType, ecdsaKeyType, blsKeyType)
+	}
+	path := c.String(KeystorePathFlag.Name)
+	if path == "" {
+		path = filepath.Join("keys", fmt.Sprintf("operator.%s.key.json", keyType))
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists, refusing to overwrite it", path)
+	}
+	password, err := newKeystorePassword(keyPassword(keyType))
+	if err != nil {
+		return err
+	}
+
+	var info *keyInfo
+	if keyType == ecdsaKeyType {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if err := sdkecdsa.WriteKey(path, key, password); err != nil {
+			return fmt.Errorf("could not write ecdsa keystore: %w", err)
+		}
+		info = &keyInfo{Path: path, Type: ecdsaKeyType, Address: crypto.PubkeyToAddress(key.PublicKey).Hex()}
+	} else {
+		keyPair, err := bls.GenRandomBlsKeys()
+		if err != nil {
+			return err
+		}
+		if err := keyPair.SaveToFile(path, password); err != nil {
+			return fmt.Errorf("could not write bls keystore: %w", err)
+		}
+		info = blsKeyInfo(path, keyPair)
+	}
+	return printJson(info)
+}
+
+func listKeystores(c *cli.Context) error {
+	dir := c.String(KeysDirFlag.Name)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+	keys := []*keyInfo{}
+	for _, path := range paths {
+		info, err := readKeyInfo(path)
+		if err != nil {
+			// not every json file is a keystore
+			continue
+		}
+		keys = append(keys, info)
+	}
+	return printJson(keys)
+}
+
+func inspectKeystore(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("the keystore path is required")
+	}
+	info, err := readKeyInfo(path)
+	if err != nil {
+		return err
+	}
+	logger := logging.NewZeroLogger(logging.Development)
+	if info.Type == ecdsaKeyType {
+		key, err := keystore.ReadEcdsaKey(path, keyPassword(ecdsaKeyType), logger)
+		if err != nil {
+			return err
+		}
+		info.Address = crypto.PubkeyToAddress(key.PublicKey).Hex()
+		return printJson(info)
+	}
+	keyPair, err := keystore.ReadBlsKey(path, keyPassword(blsKeyType), logger)
+	if err != nil {
+		return err
+	}
+	return printJson(blsKeyInfo(path, keyPair))
+}
+
+// readKeyInfo reads the public information stored unencrypted in the keystore: the address of ecdsa keystores and the
+// G1 pubkey of bls keystores.
+func readKeyInfo(path string) (*keyInfo, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var stored struct {
+		Address string          `json:"address"`
+		PubKey  string          `json:"pubKey"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(content, &stored); err != nil || stored.Crypto == nil {
+		return nil, fmt.Errorf("%s is not an encrypted json keystore", path)
+	}
+	switch {
+	case stored.Address != "":
+		return &keyInfo{Path: path, Type: ecdsaKeyType, Address: common.HexToAddress(stored.Address).Hex()}, nil
+	case stored.PubKey != "":
+		match := blsPubKeyRegex.FindStringSubmatch(stored.PubKey)
+		if match == nil {
+			return nil, fmt.Errorf("%s has an invalid bls pubkey %q", path, stored.PubKey)
+		}
+		x, _ := new(big.Int).SetString(match[1], 10)
+		y, _ := new(big.Int).SetString(match[2], 10)
+		g1 := bls.NewG1Point(x, y)
+		operatorId := sdktypes.OperatorIdFromG1Pubkey(g1)
+		return &keyInfo{
+			Path:       path,
+			Type:       blsKeyType,
+			G1PubKey:   hex.EncodeToString(g1.Serialize()),
+			OperatorId: hex.EncodeToString(operatorId[:]),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s is neither an ecdsa nor a bls keystore", path)
+	}
+}
+
+func blsKeyInfo(path string, keyPair *bls.KeyPair) *keyInfo {
+	operatorId := sdktypes.OperatorIdFromKeyPair(keyPair)
+	return &keyInfo{
+		Path:       path,
+		Type:       blsKeyType,
+		G1PubKey:   hex.EncodeToString(keyPair.GetPubKeyG1().Serialize()),
+		G2PubKey:   hex.EncodeToString(keyPair.GetPubKeyG2().Serialize()),
+		OperatorId: hex.EncodeToString(operatorId[:]),
+	}
+}
+
+// newKeystorePassword reads the password of a new keystore, asking for it twice when it's read from the terminal.
+func newKeystorePassword(source keystore.PasswordSource) (string, error) {
+	if _, ok := os.LookupEnv(source.EnvVar); ok {
+		return source.Password()
+	}
+	password, err := source.Password()
+	if err != nil {
+		return "", fmt.Errorf("%w: run in a terminal or set %s", err, source.EnvVar)
+	}
+	repeated, err := keystore.PasswordSource{Prompt: "Repeat the password: "}.Password()
+	if err != nil {
+		return "", err
+	}
+	if password != repeated {
+		return "", errors.New("the passwords don't match")
+	}
+	if password == "" {
+		fmt.Fprintln(os.Stderr, "warning: the keystore is encrypted with an empty password")
+	}
+	return password, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+var (
+	LogsLinesFlag = &cli.IntFlag{
+		Name:    "lines",
+		Aliases: []string{"n"},
+		Usage:   "number of recent entries printed, 0 for all the entries kept by the aggregator",
+		Value:   100,
+	}
+	LogsFollowFlag = &cli.BoolFlag{
+		Name:    "follow",
+		Aliases: []string{"f"},
+		Usage:   "keep printing the new entries until interrupted",
+	}
+	LogsLevelFlag = &cli.StringFlag{
+		Name:  "level",
+		Usage: "only the entries of the level or above: trace, debug, info, warn or error",
+	}
+)
+
+func logsCommand() *cli.Command {
+	return &cli.Command{
+		Name: "logs",
+		Usage: "prints the recent log entries of the aggregator, kept in memory, from its admin api; useful when its " +
+			"stderr isn't at hand, eg. in a container. With --output json the entries are printed as json, one per line",
+		Action: printLogs,
+		Flags: []cli.Flag{
+			AggregatorApiUrlFlag,
+			config.AdminApiTokenFlag,
+			LogsLinesFlag,
+			LogsFollowFlag,
+			LogsLevelFlag,
+		},
+	}
+}
+
+func printLogs(c *cli.Context) error {
+	tc := newTasksClient(c)
+	query := url.Values{"limit": {strconv.Itoa(c.Int(LogsLinesFlag.Name))}}
+	if level := c.String(LogsLevelFlag.Name); level != "" {
+		query.Set("level", level)
+	}
+	print := logEntryPrinter()
+	if !c.Bool(LogsFollowFlag.Name) {
+		var entries []json.RawMessage
+		if err := tc.get("/logs", query, &entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			print(entry)
+		}
+		return nil
+	}
+
+	query.Set("follow", "true")
+	req, err := http.NewRequestWithContext(c.Context, http.MethodGet, tc.baseUrl+"/logs?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tc.token)
+	// the stream stays open, only connecting is bounded
+	client := &http.Client{Transport: &http.Transport{ResponseHeaderTimeout: 10 * time.Second}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the aggregator admin api: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("GET /logs failed with status %s: %s", resp.Status, body.Error)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			print(json.RawMessage(data))
+		}
+	}
+	if c.Context.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("log stream interrupted: %w", err)
+	}
+	return fmt.Errorf("the aggregator closed the log stream")
+}
+
+// logEntryPrinter returns a function printing json log entries to stdout: as is with --output json, as the pretty log
+// lines otherwise.
+func logEntryPrinter() func(entry json.RawMessage) {
+	if outputFormat == jsonOutput {
+		return func(entry json.RawMessage) {
+			fmt.Println(string(entry))
+		}
+	}
+	pretty := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	return func(entry json.RawMessage) {
+		if _, err := pretty.Write(entry); err != nil {
+			fmt.Println(string(entry))
+		}
+	}
+}
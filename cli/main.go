@@ -3,63 +3,64 @@ package main
 import (
 	"os"
 
-	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
-	sdkutils "github.com/Layr-Labs/eigensdk-go/utils"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 	avs "github.com/zees-dev/blockless-avs"
 	"github.com/zees-dev/blockless-avs/core/config"
 	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/core/version"
 	"github.com/zees-dev/blockless-avs/operator"
-	"github.com/zees-dev/blockless-avs/types"
 )
 
 const AppName = "Blockless AVS Tools"
 
+// commands which load their own config and don't need the operator to be initialized
+var standaloneCommands = map[string]bool{
+	runOperatorCommandName:   true,
+	runAggregatorCommandName: true,
+	runChallengerCommandName: true,
+	avsCommandName:           true,
+}
+
 func main() {
+	defer reporting.Recover()
 	app := cli.NewApp()
 
 	app.Name = AppName
 	app.Usage = "Tools for interacting with Blockless AVS contracts"
+	// completions for the scripts printed by avs completion
+	app.EnableBashCompletion = true
 
 	// globally required flags
 	app.Flags = []cli.Flag{
 		// config.DevModeFlag,
 		config.ConfigFileFlag,
 		// config.HeadlessFlag,
+		config.NetworkFlag,
+		OutputFlag,
+		LogLevelFlag,
+		LogFormatFlag,
 	}
+	app.Flags = append(app.Flags, reporting.Flags...)
 
 	// init app state, store in context
 	app.Before = func(c *cli.Context) error {
-		logger := logging.NewZeroLogger(logging.Development)
-
-		// setup operator from config file - provided as flag
-		// devMode := c.Bool(config.DevModeFlag.Name)
-		configPath := c.String(config.ConfigFileFlag.Name)
-		// headless := c.Bool(config.HeadlessFlag.Name)
-
-		nodeConfig := types.NodeConfig{}
-		if err := sdkutils.ReadYamlConfig(configPath, &nodeConfig); err != nil {
+		if err := parseOutputFormat(c); err != nil {
 			return err
 		}
-		operator, err := operator.NewOperatorFromConfig(logger, nodeConfig)
-		if err != nil {
+		if err := configureLogging(c); err != nil {
 			return err
 		}
-
-		// if !headless {
-		// 	return errors.New("only headless mode is supported")
-		// }
-
-		c.App.Metadata[avs.AppConfigKey] = &avs.AppConfig{
-			AppName:    AppName,
-			Logger:     logger,
-			NodeConfig: &nodeConfig,
-			Operator:   operator,
-			// DevMode:    devMode,
-			// Headless:   headless,
+		reportingConfig := reporting.ParseFlags(c, c.Args().First())
+		reportingConfig.Release = version.Version
+		if err := reporting.Init(reportingConfig); err != nil {
+			return err
+		}
+		if standaloneCommands[c.Args().First()] {
+			return nil
 		}
-		return nil
+		return loadOperator(c)
 	}
 
 	app.Commands = []*cli.Command{
@@ -71,8 +72,8 @@ func main() {
 		// 		// get app config
 		// 		app := c.App.Metadata[avs.AppConfigKey].(*avs.AppConfig)
 
-		// 		b7sConfig := node.ParseFlags(c)
-		// 		app.BlocklessConfig = &b7sConfig
+		// 		nodeCfg, err := node.ParseFlags(c)
+		// 		app.BlocklessConfig = &nodeCfg.Config
 		// 		return nil
 		// 	},
 		// 	Flags: []cli.Flag{
@@ -93,6 +94,10 @@ func main() {
 		// 		node.DialBackWebsocketPort,
 		// 		node.CPUPercentage,
 		// 		node.MemoryMaxKB,
+		// 		node.LegacyProtocolUntil,
+		// 		node.DisableLegacyProtocol,
+		// 		node.RecordMessagesFile,
+		// 		node.RecordMessagesBuffer,
 		// 	},
 		// },
 		// {
@@ -142,29 +147,30 @@ func main() {
 			Usage:   "registers bls keys with pubkey-compendium, opts into slashing by avs service-manager, and registers operators with avs registry",
 			Action: func(ctx *cli.Context) error {
 				app := ctx.App.Metadata[avs.AppConfigKey].(*avs.AppConfig)
-				ecdsaKeyPassword, ok := os.LookupEnv("OPERATOR_ECDSA_KEY_PASSWORD")
-				if !ok {
-					app.Logger.Info("OPERATOR_ECDSA_KEY_PASSWORD env var not set. using empty string")
-				}
-				operatorEcdsaPrivKey, err := sdkecdsa.ReadKey(
-					app.NodeConfig.EcdsaPrivateKeyStorePath,
-					ecdsaKeyPassword,
-				)
+				operatorEcdsaPrivKey, err := readOperatorEcdsaKey(app)
 				if err != nil {
 					return err
 				}
 
-				return app.Operator.RegisterOperatorWithAvs(operatorEcdsaPrivKey)
+				report, err := app.Operator.RegisterOperatorWithAvs(operatorEcdsaPrivKey)
+				if err != nil {
+					return err
+				}
+				return printResult(report, registrationText(report, "registered in"))
 			},
 			Flags: []cli.Flag{config.ConfigFileFlag},
 		},
 		{
 			Name:    "deregister-operator-with-avs",
 			Aliases: []string{"dowa"},
+			Usage:   "deregisters the operator from the quorums of the config (see avs operator deregister for other quorums)",
 			Action: func(ctx *cli.Context) error {
 				app := ctx.App.Metadata[avs.AppConfigKey].(*avs.AppConfig)
-				app.Logger.Fatal("Command not implemented.")
-				return nil
+				report, err := app.Operator.DeregisterOperatorFromAvs(app.Operator.Quorums())
+				if err != nil {
+					return err
+				}
+				return printResult(report, registrationText(report, "deregistered from"))
 			},
 			Flags: []cli.Flag{config.ConfigFileFlag},
 		},
@@ -174,13 +180,69 @@ func main() {
 			Usage:   "prints operator status as viewed from blockless-avs contracts",
 			Action: func(ctx *cli.Context) error {
 				operator := ctx.App.Metadata[avs.AppConfigKey].(*avs.AppConfig).Operator
-				return operator.PrintOperatorStatus()
+				status, err := operator.Status()
+				if err != nil {
+					return err
+				}
+				return printResult(status, "")
 			},
 			Flags: []cli.Flag{config.ConfigFileFlag},
 		},
+		operatorCommand(),
+		runOperatorCommand(),
+		runAggregatorCommand(),
+		runChallengerCommand(),
+		avsCommand(),
 	}
 
-	if err := app.Run(os.Args); err != nil {
+	err := app.Run(os.Args)
+	reporting.Flush()
+	if err != nil {
+		if outputFormat == jsonOutput {
+			printError(err)
+			os.Exit(1)
+		}
 		log.Fatal().Err(err).Msg("Failed to run app")
 	}
 }
+
+// loadOperator creates the operator from the config file and stores it in the app metadata.
+func loadOperator(c *cli.Context) error {
+	logger := logging.NewZeroLogger(logging.Development)
+
+	// setup operator from config file - provided as flag
+	// devMode := c.Bool(config.DevModeFlag.Name)
+	configPath := c.String(config.ConfigFileFlag.Name)
+	// headless := c.Bool(config.HeadlessFlag.Name)
+
+	network, err := config.NetworkFromFlag(c)
+	if err != nil {
+		return err
+	}
+	nodeConfig, err := readNodeConfig(c, configPath)
+	if err != nil {
+		return err
+	}
+	operator, err := operator.NewOperatorFromConfig(logger, nodeConfig)
+	if err != nil {
+		return err
+	}
+	// the transaction sending commands only simulate their transactions with --dry-run
+	operator.SetDryRun(c.Bool(DryRunFlag.Name))
+
+	// if !headless {
+	// 	return errors.New("only headless mode is supported")
+	// }
+
+	c.App.Metadata[avs.AppConfigKey] = &avs.AppConfig{
+		AppName:        AppName,
+		Logger:         logger,
+		NodeConfig:     &nodeConfig,
+		NodeConfigPath: configPath,
+		Network:        network,
+		Operator:       operator,
+		// DevMode:    devMode,
+		// Headless:   headless,
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+var TaskIndexFlag = &cli.UintFlag{
+	Name:     "task",
+	Usage:    "index of the aggregated response (OracleUpdate event) onchain, starting at 0",
+	Required: true,
+}
+
+func operatorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "operator",
+		Usage: "operator tools",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "verify",
+				Usage:  "verifies that this operator's signature was included in an onchain aggregated response",
+				Action: verifyAttestation,
+				Flags:  []cli.Flag{config.ConfigFileFlag, TaskIndexFlag},
+			},
+		},
+	}
+}
+
+func verifyAttestation(ctx *cli.Context) error {
+	app := avs.GetAppConfig(ctx)
+	report, err := app.Operator.VerifyAttestation(ctx.Context, types.TaskIndex(ctx.Uint(TaskIndexFlag.Name)))
+	if err != nil {
+		return err
+	}
+	if err := printJson(report); err != nil {
+		return err
+	}
+	if len(report.Mismatches) > 0 {
+		return errResultPrinted{fmt.Errorf("attestation for task %d has %d mismatch(es)", report.TaskIndex, len(report.Mismatches))}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/logging"
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
+)
+
+// number of recent log entries kept in memory for the aggregator admin api (avs logs)
+const logBufferSize = 1000
+
+var OutputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   "output format, text or json; with json the result of the command, or {\"error\": ...} when it fails, is printed to stdout as a single json document, the logs go to stderr",
+	Value:   textOutput,
+	EnvVars: []string{"AVS_OUTPUT"},
+}
+
+var (
+	LogLevelFlag = &cli.StringFlag{
+		Name:    "log-level",
+		Usage:   "level of the logs of all the components, including the p2p node: trace, debug, info, warn or error (default: per the production/environment setting of the config)",
+		EnvVars: []string{"AVS_LOG_LEVEL"},
+	}
+	LogFormatFlag = &cli.StringFlag{
+		Name:    "log-format",
+		Usage:   "format of the logs, written to stderr: pretty or json",
+		Value:   string(logging.PrettyFormat),
+		EnvVars: []string{"AVS_LOG_FORMAT"},
+	}
+)
+
+// outputFormat is the --output format, set before the commands run.
+var outputFormat = textOutput
+
+func parseOutputFormat(c *cli.Context) error {
+	switch format := c.String(OutputFlag.Name); format {
+	case textOutput, jsonOutput:
+		outputFormat = format
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, must be %s or %s", format, textOutput, jsonOutput)
+	}
+}
+
+// configureLogging applies --log-level and --log-format; it must run before any logger is created.
+func configureLogging(c *cli.Context) error {
+	if err := logging.Configure(c.String(LogLevelFlag.Name), logging.LogFormat(c.String(LogFormatFlag.Name))); err != nil {
+		return err
+	}
+	// served by the aggregator admin api for avs logs
+	logging.EnableBuffer(logBufferSize)
+	// the global logger the app failures are logged with writes json unless told otherwise
+	if c.IsSet(LogFormatFlag.Name) {
+		log.Logger = log.Output(logging.Output())
+	}
+	return nil
+}
+
+// errResultPrinted wraps the error of a command which already printed a result describing the failure, like the
+// problems of an invalid config, so the error isn't printed as json on top of it.
+type errResultPrinted struct {
+	error
+}
+
+func (e errResultPrinted) Unwrap() error {
+	return e.error
+}
+
+// printResult prints the result of a command: v as json with --output json, text otherwise. Results without a text
+// form (empty text) are printed as json in both formats.
+func printResult(v any, text string) error {
+	if outputFormat == jsonOutput || text == "" {
+		return printJson(v)
+	}
+	fmt.Println(text)
+	return nil
+}
+
+// printError prints the error the app failed with as json to stdout, unless the command already printed its result.
+func printError(err error) {
+	if errors.As(err, &errResultPrinted{}) {
+		return
+	}
+	errJson, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	fmt.Println(string(errJson))
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+	"github.com/zees-dev/blockless-avs/types"
+)
+
+// peerEntry is a peer listed by avs peers list.
+type peerEntry struct {
+	Id string `json:"id"`
+	// multiaddrs the node dials the peer on, with the peer id
+	Multiaddrs []string   `json:"multiaddrs"`
+	LastSeen   *time.Time `json:"lastSeen,omitempty"`
+	// score of the peer, when the node scores the peers
+	Score       *int       `json:"score,omitempty"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+	// operator the peer is bound to, verified by the node
+	Operator string `json:"operator,omitempty"`
+	// resources and attributes advertised by the worker, in the json output only
+	Capabilities *node.WorkerCapabilities `json:"capabilities,omitempty"`
+}
+
+func newPeerEntry(record node.PeerRecord) peerEntry {
+	entry := peerEntry{Id: record.ID.String(), Multiaddrs: record.Dialable()}
+	if !record.LastSeen.IsZero() {
+		entry.LastSeen = &record.LastSeen
+	}
+	if rep := record.Reputation; rep != nil {
+		entry.Score = &rep.Score
+		if rep.Banned(time.Now()) {
+			entry.BannedUntil = &rep.BannedUntil
+		}
+	}
+	if binding := record.OperatorBinding; binding != nil {
+		entry.Operator = binding.Operator.Hex()
+	}
+	entry.Capabilities = record.Capabilities
+	return entry
+}
+
+func peersCommand() *cli.Command {
+	// the peer db is read from the b7s section of --config when it exists, unless --peer-db is set
+	flags := []cli.Flag{config.ConfigFileFlag, node.PeerDatabasePath}
+	return &cli.Command{
+		Name:  "peers",
+		Usage: "manages the dial-back peers of the p2p node peer database, which the node reconnects to on startup; the node must be stopped",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists the peers with their multiaddrs and the time they last connected, most recent first",
+				Action: listPeers,
+				Flags:  flags,
+			},
+			{
+				Name:      "add",
+				Usage:     "adds dial-back peers from their multiaddr, eg. /ip4/1.2.3.4/tcp/9000/p2p/<peer id>",
+				ArgsUsage: "<multiaddr>...",
+				Action:    addPeers,
+				Flags:     flags,
+			},
+			{
+				Name:      "remove",
+				Usage:     "removes peers, so the node doesn't dial them back anymore",
+				ArgsUsage: "<peer id>...",
+				Action:    removePeers,
+				Flags:     flags,
+			},
+			{
+				Name:   "export",
+				Usage:  "prints the multiaddrs of the peers, one per line, eg. for the --boot-nodes of another node",
+				Action: exportPeers,
+				Flags:  flags,
+			},
+		},
+	}
+}
+
+// readOptionalNodeConfig reads the operator config of --config when it exists, applying its b7s section to the node
+// flags of the command; the config is empty otherwise.
+func readOptionalNodeConfig(c *cli.Context) (types.NodeConfig, error) {
+	var nodeConfig types.NodeConfig
+	configPath := c.String(config.ConfigFileFlag.Name)
+	if _, err := os.Stat(configPath); err != nil {
+		return nodeConfig, nil
+	}
+	nodeConfig, err := readNodeConfig(c, configPath)
+	if err != nil {
+		return nodeConfig, fmt.Errorf("could not load config file %s: %w", configPath, err)
+	}
+	return nodeConfig, node.ApplyConfig(c, nodeConfig.B7s)
+}
+
+// openPeerDB opens the peer database of the node, creating it if create is set.
+func openPeerDB(c *cli.Context, create bool) (*node.PeerDB, error) {
+	if _, err := readOptionalNodeConfig(c); err != nil {
+		return nil, err
+	}
+	return node.OpenPeerDB(c.String(node.PeerDatabasePath.Name), create)
+}
+
+func listPeers(c *cli.Context) error {
+	db, err := openPeerDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	records, err := db.List()
+	if err != nil {
+		return err
+	}
+	peers := make([]peerEntry, 0, len(records))
+	for _, record := range records {
+		peers = append(peers, newPeerEntry(record))
+	}
+
+	if outputFormat == jsonOutput {
+		return printJson(peers)
+	}
+	if len(peers) == 0 {
+		fmt.Println("No peers")
+		return nil
+	}
+	w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tMULTIADDRS\tLAST SEEN\tSCORE\tOPERATOR")
+	for _, p := range peers {
+		lastSeen := "never"
+		if p.LastSeen != nil {
+			lastSeen = p.LastSeen.Local().Format(time.DateTime)
+		}
+		score := "-"
+		if p.Score != nil {
+			score = strconv.Itoa(*p.Score)
+		}
+		if p.BannedUntil != nil {
+			score += " (banned until " + p.BannedUntil.Local().Format(time.DateTime) + ")"
+		}
+		operator := "-"
+		if p.Operator != "" {
+			operator = p.Operator
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Id, strings.Join(p.Multiaddrs, ","), lastSeen, score, operator)
+	}
+	return w.Flush()
+}
+
+func addPeers(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("missing peer multiaddr")
+	}
+	addrs := make([]multiaddr.Multiaddr, 0, c.NArg())
+	for _, arg := range c.Args().Slice() {
+		addr, err := multiaddr.NewMultiaddr(arg)
+		if err != nil {
+			return fmt.Errorf("invalid multiaddr %q: %w", arg, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	db, err := openPeerDB(c, true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	added := make([]peerEntry, 0, len(addrs))
+	var text []string
+	for _, addr := range addrs {
+		record, err := db.Add(addr)
+		if err != nil {
+			return err
+		}
+		added = append(added, newPeerEntry(record))
+		text = append(text, fmt.Sprintf("Added peer %s", record.ID))
+	}
+	return printResult(added, strings.Join(text, "\n"))
+}
+
+func removePeers(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return errors.New("missing peer id")
+	}
+	ids := make([]peer.ID, 0, c.NArg())
+	for _, arg := range c.Args().Slice() {
+		id, err := peer.Decode(arg)
+		if err != nil {
+			return fmt.Errorf("invalid peer id %q: %w", arg, err)
+		}
+		ids = append(ids, id)
+	}
+	db, err := openPeerDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	removed := make([]string, 0, len(ids))
+	var text []string
+	for _, id := range ids {
+		if err := db.Remove(id); err != nil {
+			return fmt.Errorf("could not remove peer %s: %w", id, err)
+		}
+		removed = append(removed, id.String())
+		text = append(text, fmt.Sprintf("Removed peer %s", id))
+	}
+	return printResult(struct {
+		Removed []string `json:"removed"`
+	}{Removed: removed}, strings.Join(text, "\n"))
+}
+
+func exportPeers(c *cli.Context) error {
+	db, err := openPeerDB(c, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	records, err := db.List()
+	if err != nil {
+		return err
+	}
+	multiaddrs := []string{}
+	for _, record := range records {
+		multiaddrs = append(multiaddrs, record.Dialable()...)
+	}
+	if outputFormat == jsonOutput {
+		return printJson(multiaddrs)
+	}
+	for _, addr := range multiaddrs {
+		fmt.Println(addr)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/keystore"
+	"github.com/zees-dev/blockless-avs/operator"
+)
+
+var (
+	QuorumsFlag = &cli.UintSliceFlag{
+		Name:  "quorums",
+		Usage: "quorum numbers to register in, or deregister from (default: quorums from the config); registering is limited to the configured quorums",
+	}
+	FromBlockFlag = &cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: "block to scan the metadata uri updates from; set it to the operator registration block on chains with a long history",
+	}
+	SocketFlag = &cli.StringFlag{
+		Name:  "socket",
+		Usage: "socket to register with the avs registry coordinator (default: socket from the config)",
+	}
+)
+
+// avsOperatorCommand registers and deregisters the operator configured with --config.
+func avsOperatorCommand() *cli.Command {
+	flags := []cli.Flag{config.ConfigFileFlag, QuorumsFlag, SocketFlag, DryRunFlag}
+	return &cli.Command{
+		Name:  "operator",
+		Usage: "operator registration with eigenlayer and the avs",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "register",
+				Usage:  "registers the operator with eigenlayer (unless already registered), then its bls pubkey and socket with the avs registry coordinator in --quorums",
+				Action: registerOperator,
+				// the avs command is standalone, so the operator is only loaded by the subcommands which need it
+				Before: loadOperator,
+				Flags:  flags,
+			},
+			{
+				Name:   "register-with-eigenlayer",
+				Usage:  "registers the operator with the eigenlayer DelegationManager (registerAsOperator) with the metadata_uri of the config, unless already registered; the avs registration is done by register",
+				Action: registerWithEigenlayer,
+				Before: loadOperator,
+				Flags:  []cli.Flag{config.ConfigFileFlag, DryRunFlag},
+			},
+			{
+				Name:   "opt-in-quorums",
+				Usage:  "registers an operator already registered with the avs in additional --quorums",
+				Action: optInQuorums,
+				Before: loadOperator,
+				Flags:  flags,
+			},
+			{
+				Name:   "deregister",
+				Usage:  "deregisters the operator from --quorums of the avs",
+				Action: deregisterOperator,
+				Before: loadOperator,
+				Flags:  []cli.Flag{config.ConfigFileFlag, QuorumsFlag, DryRunFlag},
+			},
+			{
+				Name:   "deposit",
+				Usage:  "deposits --amount tokens of the operator into an eigenlayer --strategy, minting mock tokens first with --mint; prints the result as json",
+				Action: depositIntoStrategy,
+				Before: loadOperator,
+				Flags:  []cli.Flag{config.ConfigFileFlag, StrategyFlag, AmountFlag, MintFlag, YesFlag, DryRunFlag},
+			},
+			{
+				Name:   "delegate-self",
+				Usage:  "registers the operator with eigenlayer unless already registered, delegating its stake to itself; prints the result as json",
+				Action: delegateToSelf,
+				Before: loadOperator,
+				Flags:  []cli.Flag{config.ConfigFileFlag, YesFlag, DryRunFlag},
+			},
+			{
+				Name:  "metadata",
+				Usage: "operator metadata uri (public operator profile) on the eigenlayer DelegationManager",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "set",
+						Usage:     "validates the metadata at URI and sets it as the operator metadata uri",
+						ArgsUsage: "URI",
+						Action:    setOperatorMetadataURI,
+						Before:    loadOperator,
+						Flags:     []cli.Flag{config.ConfigFileFlag, DryRunFlag},
+					},
+					{
+						Name:   "get",
+						Usage:  "prints the current operator metadata uri and the metadata it points to",
+						Action: getOperatorMetadata,
+						Before: loadOperator,
+						Flags:  []cli.Flag{config.ConfigFileFlag, FromBlockFlag},
+					},
+				},
+			},
+		},
+	}
+}
+
+func registerOperator(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	quorums, err := parseQuorums(c)
+	if err != nil {
+		return err
+	}
+	ecdsaPrivateKey, err := readOperatorEcdsaKey(app)
+	if err != nil {
+		return err
+	}
+	report, err := app.Operator.Register(ecdsaPrivateKey, quorums, operatorSocket(c, app))
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(report, registrationText(report, "registered in"))
+}
+
+func registerWithEigenlayer(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	report, err := app.Operator.RegisterWithEigenlayer(c.Context, app.NodeConfig.MetadataURI)
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("%s is already registered with eigenlayer", report.Operator.Hex())
+	if report.TxHash != nil {
+		text = fmt.Sprintf("Registered %s with eigenlayer with metadata uri %q (tx %s)", report.Operator.Hex(), report.MetadataURI, report.TxHash.Hex())
+	}
+	return printResult(report, text)
+}
+
+func optInQuorums(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	quorums, err := parseQuorums(c)
+	if err != nil {
+		return err
+	}
+	ecdsaPrivateKey, err := readOperatorEcdsaKey(app)
+	if err != nil {
+		return err
+	}
+	report, err := app.Operator.OptInQuorums(ecdsaPrivateKey, quorums, operatorSocket(c, app))
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(report, registrationText(report, "registered in"))
+}
+
+func deregisterOperator(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	quorums, err := parseQuorums(c)
+	if err != nil {
+		return err
+	}
+	report, err := app.Operator.DeregisterOperatorFromAvs(quorums)
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(report, registrationText(report, "deregistered from"))
+}
+
+// registrationText is the text output of the registration commands; action is "registered in" or "deregistered from".
+func registrationText(report *operator.RegistrationReport, action string) string {
+	var lines []string
+	if report.Eigenlayer != nil && report.Eigenlayer.TxHash != nil {
+		lines = append(lines, fmt.Sprintf("Registered %s with eigenlayer (tx %s)", report.Operator.Hex(), report.Eigenlayer.TxHash.Hex()))
+	}
+	if len(report.AlreadyRegisteredQuorumNumbers) > 0 {
+		lines = append(lines, fmt.Sprintf("Already registered in quorums %v", report.AlreadyRegisteredQuorumNumbers))
+	}
+	if report.TxHash != nil {
+		lines = append(lines, fmt.Sprintf("Operator %s (id %s) %s quorums %v (tx %s)", report.Operator.Hex(), report.OperatorId, action, report.QuorumNumbers, report.TxHash.Hex()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseQuorums returns the --quorums, or the quorums the operator participates in when not set.
+func parseQuorums(c *cli.Context) (sdktypes.QuorumNums, error) {
+	if !c.IsSet(QuorumsFlag.Name) {
+		return avs.GetAppConfig(c).Operator.Quorums(), nil
+	}
+	values := c.UintSlice(QuorumsFlag.Name)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("at least one quorum is required")
+	}
+	quorums := make(sdktypes.QuorumNums, 0, len(values))
+	for _, v := range values {
+		if v > 255 {
+			return nil, fmt.Errorf("invalid quorum number %d", v)
+		}
+		quorums = append(quorums, sdktypes.QuorumNum(v))
+	}
+	return quorums, nil
+}
+
+func operatorSocket(c *cli.Context, app *avs.AppConfig) string {
+	if c.IsSet(SocketFlag.Name) {
+		return c.String(SocketFlag.Name)
+	}
+	return app.NodeConfig.Socket
+}
+
+// readOperatorEcdsaKey reads the operator ecdsa key from the keystore; the registration signatures aren't transactions,
+// so they can't be produced by a remote signer.
+func readOperatorEcdsaKey(app *avs.AppConfig) (*ecdsa.PrivateKey, error) {
+	return keystore.ReadEcdsaKey(app.NodeConfig.EcdsaPrivateKeyStorePath, app.NodeConfig.EcdsaKeyPassword(), app.Logger)
+}
+
+func setOperatorMetadataURI(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	if c.NArg() != 1 {
+		return fmt.Errorf("expected the metadata URI as single argument")
+	}
+	report, err := app.Operator.UpdateMetadataURI(c.Context, c.Args().First())
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(report, fmt.Sprintf("Set the metadata uri of %s to %s (tx %s)", report.Operator.Hex(), report.URI, report.TxHash.Hex()))
+}
+
+func getOperatorMetadata(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	report, err := app.Operator.Metadata(c.Context, c.Uint64(FromBlockFlag.Name))
+	if err != nil {
+		return err
+	}
+	return printJson(report)
+}
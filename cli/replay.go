@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/core/logging"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+var (
+	RecordingFileFlag = &cli.StringFlag{
+		Name:     "recording",
+		Usage:    "recording `FILE` created with --record-p2p-messages",
+		Required: true,
+	}
+	PreserveTimingFlag = &cli.BoolFlag{
+		Name:  "preserve-timing",
+		Usage: "keep the original delays between messages instead of replaying them back to back",
+	}
+)
+
+func replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "replay",
+		Usage:  "starts a p2p node and feeds it the direct messages of a recording, in order",
+		Action: replayMessages,
+		Flags:  append([]cli.Flag{RecordingFileFlag, PreserveTimingFlag}, nodeFlags()...),
+	}
+}
+
+func replayMessages(c *cli.Context) error {
+	sdkLogger := logging.NewZeroLogger(logging.Development)
+	logger := sdkLogger.Inner()
+
+	msgs, err := node.LoadRecording(c.String(RecordingFileFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nodeCfg, err := node.ParseFlags(c)
+	if err != nil {
+		return err
+	}
+	app := &avs.AppConfig{
+		AppName:         AppName,
+		Logger:          sdkLogger,
+		BlocklessConfig: &nodeCfg.Config,
+	}
+	replayer := node.NewReplayer()
+	p2pNode, err := startNode(ctx, c, app, nodeCfg, replayer)
+	if err != nil {
+		return err
+	}
+	defer stopNode(app, p2pNode)
+
+	logger.Info().Int("messages", len(msgs)).Msg("replaying recorded messages")
+	replayCtx, cancelReplay := context.WithCancel(ctx)
+	defer cancelReplay()
+	go func() {
+		select {
+		case <-p2pNode.Done():
+			cancelReplay()
+		case <-replayCtx.Done():
+		}
+	}()
+	if err := replayer.Replay(replayCtx, msgs, c.Bool(PreserveTimingFlag.Name)); err != nil {
+		return err
+	}
+	logger.Info().Msg("replay finished")
+	return nil
+}
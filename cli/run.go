@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+const (
+	runOperatorCommandName   = "run-operator"
+	runAggregatorCommandName = "run-aggregator"
+)
+
+// runOperatorCommand runs the operator with its p2p node and node api, see avs all-in-one to run it with the aggregator.
+func runOperatorCommand() *cli.Command {
+	return &cli.Command{
+		Name:   runOperatorCommandName,
+		Usage:  "runs the operator with its p2p node and node api (uses the operator config, see config-files/operator.anvil.yaml)",
+		Action: runOperator,
+		Flags: append([]cli.Flag{
+			config.ConfigFileFlag,
+			config.HeadlessFlag,
+			ShutdownTimeoutFlag,
+			node.RecordMessagesFile,
+			node.RecordMessagesBuffer,
+		}, nodeFlags()...),
+	}
+}
+
+func runOperator(c *cli.Context) error {
+	return runRoles(c, []string{operatorRole, nodeRole}, "")
+}
+
+// runAggregatorCommand runs the aggregator, reading its config from --config instead of --aggregator-config.
+func runAggregatorCommand() *cli.Command {
+	configFlag := *config.ConfigFileFlag
+	configFlag.Value = "config-files/aggregator.yaml"
+	return &cli.Command{
+		Name:   runAggregatorCommandName,
+		Usage:  "runs the aggregator, which aggregates the operator signatures and submits the results onchain (uses the aggregator config, see config-files/aggregator.yaml)",
+		Action: runAggregator,
+		Flags: []cli.Flag{&configFlag, config.BlocklessAVSDeploymentFileFlag, config.EcdsaKeystoreFlag,
+			config.EcdsaKeystorePasswordFileFlag, config.EcdsaPrivateKeyFlag, config.AdminApiTokenFlag, ShutdownTimeoutFlag},
+	}
+}
+
+func runAggregator(c *cli.Context) error {
+	return runRoles(c, []string{aggregatorRole}, c.String(config.ConfigFileFlag.Name))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+)
+
+var ShutdownTimeoutFlag = &cli.DurationFlag{
+	Name:    "shutdown-timeout",
+	Usage:   "how long the shutdown may take (api server, p2p node, then operator and aggregator, which drains its in-flight aggregations) before exiting anyway",
+	Value:   time.Minute,
+	EnvVars: []string{"AVS_SHUTDOWN_TIMEOUT"},
+}
+
+// shutdownStep stops a component of the running avs, returning once it stopped or ctx is done.
+type shutdownStep struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// shutdownSequence stops the components of the running avs in the order they were added to it.
+type shutdownSequence struct {
+	logger *zerolog.Logger
+	steps  []shutdownStep
+}
+
+func (s *shutdownSequence) add(name string, stop func(ctx context.Context) error) {
+	s.steps = append(s.steps, shutdownStep{name: name, stop: stop})
+}
+
+// run stops the components in order within the timeout. A signal received on sig cuts the shutdown short like the
+// timeout does: the remaining components are given up on and their resources are released by the process exit.
+func (s *shutdownSequence) run(timeout time.Duration, sig <-chan os.Signal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-sig:
+			s.logger.Warn().Msg("Received a second signal, forcing the shutdown")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var errs []error
+	for _, step := range s.steps {
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("%s was not stopped", step.name))
+			continue
+		}
+		start := time.Now()
+		if err := step.stop(ctx); err != nil {
+			s.logger.Error().Err(err).Str("component", step.name).Msg("Could not stop cleanly")
+			errs = append(errs, fmt.Errorf("could not stop %s: %w", step.name, err))
+			continue
+		}
+		s.logger.Info().Str("component", step.name).Dur("duration", time.Since(start)).Msg("Stopped")
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("shutdown did not complete within %s: %w", timeout, errors.Join(errs...))
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("shutdown was forced: %w", errors.Join(errs...))
+	}
+	return errors.Join(errs...)
+}
+
+// waitDone returns a shutdown step waiting for done to be closed.
+func waitDone(done <-chan struct{}) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
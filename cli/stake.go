@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/core/chainio"
+)
+
+// errAborted is returned when the user doesn't confirm a transaction.
+var errAborted = errors.New("aborted")
+
+var (
+	StrategyFlag = &cli.StringFlag{
+		Name:  "strategy",
+		Usage: "address of the eigenlayer strategy to deposit into (default: token_strategy_addr from the config)",
+	}
+	AmountFlag = &cli.StringFlag{
+		Name:     "amount",
+		Usage:    "amount of tokens to deposit, in the token base unit (eg. wei)",
+		Required: true,
+	}
+	MintFlag = &cli.BoolFlag{
+		Name:  "mint",
+		Usage: "mint the tokens before depositing; only the mock token of local deployments can be minted",
+	}
+	YesFlag = &cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "send the transactions without asking for confirmation",
+	}
+	DryRunFlag = &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "simulate the transactions and print their estimated gas and decoded calldata, without sending them",
+	}
+)
+
+// dryRunReport is the result of the commands run with --dry-run.
+type dryRunReport struct {
+	DryRun       bool               `json:"dryRun"`
+	Transactions []chainio.DryRunTx `json:"transactions"`
+}
+
+func depositIntoStrategy(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	strategy := app.NodeConfig.TokenStrategyAddr
+	if c.IsSet(StrategyFlag.Name) {
+		strategy = c.String(StrategyFlag.Name)
+	}
+	if !common.IsHexAddress(strategy) {
+		return fmt.Errorf("invalid strategy address %q", strategy)
+	}
+	amount, ok := new(big.Int).SetString(c.String(AmountFlag.Name), 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %q", c.String(AmountFlag.Name))
+	}
+
+	plan, err := app.Operator.PlanDeposit(c.Context, common.HexToAddress(strategy), amount, c.Bool(MintFlag.Name))
+	if err != nil {
+		return err
+	}
+	if err := confirm(c, "Deposit into strategy", plan); err != nil {
+		return err
+	}
+	report, err := app.Operator.Deposit(c.Context, plan)
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printJson(report)
+}
+
+func delegateToSelf(c *cli.Context) error {
+	app := avs.GetAppConfig(c)
+	if err := confirm(c, "Register the operator with eigenlayer, delegating its stake to itself", map[string]string{
+		"operator": app.NodeConfig.OperatorAddress,
+	}); err != nil {
+		return err
+	}
+	report, err := app.Operator.DelegateToSelf(c.Context)
+	if c.Bool(DryRunFlag.Name) {
+		return printDryRun(app, err)
+	}
+	if err != nil {
+		return err
+	}
+	return printJson(report)
+}
+
+// confirm prints the action and its details to stderr and asks for confirmation on stdin, unless --yes is set. It
+// returns errAborted unless the user confirmed.
+func confirm(c *cli.Context, action string, details any) error {
+	if c.Bool(YesFlag.Name) || c.Bool(DryRunFlag.Name) {
+		return nil
+	}
+	detailsJson, err := json.MarshalIndent(details, "", " ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s:\n%s\nProceed? [y/N] ", action, detailsJson)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		return errAborted
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errAborted
+	}
+}
+
+// printDryRun prints the transactions the operator simulated for a command run with --dry-run, which returned err, eg.
+// for a simulation which reverted.
+func printDryRun(app *avs.AppConfig, err error) error {
+	txs := app.Operator.DryRunTxs()
+	if len(txs) == 0 && err != nil {
+		return err
+	}
+	var lines []string
+	for _, tx := range txs {
+		method := tx.Method
+		if method == "" {
+			method = "unknown method"
+		}
+		if tx.Error != "" {
+			lines = append(lines, fmt.Sprintf("Would send %s to %s, which reverts: %s", method, tx.To, tx.Error))
+		} else {
+			lines = append(lines, fmt.Sprintf("Would send %s to %s (estimated gas %d, gas limit %d)", method, tx.To, tx.EstimatedGas, tx.GasLimit))
+		}
+		names := make([]string, 0, len(tx.Args))
+		for name := range tx.Args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value, _ := json.Marshal(tx.Args[name])
+			lines = append(lines, fmt.Sprintf("  %s: %s", name, value))
+		}
+		lines = append(lines, fmt.Sprintf("  calldata: %s", tx.Data))
+	}
+	if len(txs) == 0 {
+		lines = append(lines, "No transaction to send")
+	}
+	if perr := printResult(dryRunReport{DryRun: true, Transactions: txs}, strings.Join(lines, "\n")); perr != nil {
+		return perr
+	}
+	if err != nil {
+		return errResultPrinted{err}
+	}
+	return nil
+}
+
+func printJson(v any) error {
+	vJson, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(vJson))
+	return nil
+}
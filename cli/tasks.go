@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/core/config"
+)
+
+// task statuses shown by avs tasks list, and the archive status each one filters on
+var taskStatuses = map[string]string{
+	"pending":    aggregator.ArchivedTaskPending,
+	"aggregated": aggregator.ArchivedTaskThresholdReached,
+	"responded":  aggregator.ArchivedTaskSubmitted,
+	"failed":     aggregator.ArchivedTaskSubmissionFailed,
+	"expired":    aggregator.ArchivedTaskExpired,
+}
+
+var (
+	AggregatorApiUrlFlag = &cli.StringFlag{
+		Name:  "aggregator-api-url",
+		Usage: "url of the aggregator admin api (admin_api_ip_port_address of the aggregator config)",
+		Value: "http://localhost:8091",
+	}
+	TasksOperatorFlag = &cli.StringFlag{
+		Name:  "operator",
+		Usage: "only the tasks the operator responded to, by operator id (see avs operator status)",
+	}
+	TasksSymbolFlag = &cli.StringFlag{
+		Name:  "symbol",
+		Usage: "only the tasks of the symbol",
+	}
+	TasksStatusFlag = &cli.StringFlag{
+		Name:  "status",
+		Usage: "only the tasks with the status: pending, aggregated, responded, failed or expired",
+	}
+	TasksSinceFlag = &cli.DurationFlag{
+		Name:  "since",
+		Usage: "only the tasks created in the last duration, eg. 1h; ignored when --from is set",
+	}
+	TasksFromFlag = &cli.StringFlag{
+		Name:  "from",
+		Usage: "only the tasks created at or after the RFC3339 time",
+	}
+	TasksToFlag = &cli.StringFlag{
+		Name:  "to",
+		Usage: "only the tasks created before the RFC3339 time",
+	}
+	TasksLimitFlag = &cli.IntFlag{
+		Name:  "limit",
+		Usage: "maximum number of tasks, most recent first (at most 1000)",
+		Value: 100,
+	}
+	SendTaskSymbolFlag = &cli.StringFlag{
+		Name:     "symbol",
+		Usage:    "symbol the operators fetch the price of, eg. bitcoin",
+		Required: true,
+	}
+	SendTaskQuorumsFlag = &cli.UintSliceFlag{
+		Name:  "quorums",
+		Usage: "quorums which must sign the task (default: all the avs quorums)",
+	}
+	SendTaskThresholdFlag = &cli.UintFlag{
+		Name:  "threshold",
+		Usage: "percentage of the stake of each quorum which must sign the task",
+		Value: 100,
+	}
+)
+
+// taskEntry is a task listed by avs tasks list.
+type taskEntry struct {
+	TaskIndex            uint32     `json:"taskIndex"`
+	Symbol               string     `json:"symbol"`
+	ReferenceBlockNumber uint32     `json:"referenceBlockNumber"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	Status               string     `json:"status"`
+	NumResponses         int        `json:"numResponses"`
+	TxHash               string     `json:"txHash,omitempty"`
+	Error                string     `json:"error,omitempty"`
+	FinishedAt           *time.Time `json:"finishedAt,omitempty"`
+}
+
+func tasksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tasks",
+		Usage: "inspects the tasks of the aggregator",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists the recent tasks of the aggregator with their status, from its admin api; without the task archive (archive.enabled) only the in-flight tasks are listed",
+				Action: listTasks,
+				Flags: []cli.Flag{
+					AggregatorApiUrlFlag,
+					config.AdminApiTokenFlag,
+					TasksOperatorFlag,
+					TasksSymbolFlag,
+					TasksStatusFlag,
+					TasksSinceFlag,
+					TasksFromFlag,
+					TasksToFlag,
+					TasksLimitFlag,
+				},
+			},
+		},
+	}
+}
+
+func sendTaskCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "send-task",
+		Usage:  "creates a task for the operators through the aggregator admin api, like an external task generator; the aggregator must run with task_generation: external",
+		Action: sendTask,
+		Flags: []cli.Flag{
+			AggregatorApiUrlFlag,
+			config.AdminApiTokenFlag,
+			SendTaskSymbolFlag,
+			SendTaskQuorumsFlag,
+			SendTaskThresholdFlag,
+			DryRunFlag,
+		},
+	}
+}
+
+// tasksClient calls the aggregator admin api.
+type tasksClient struct {
+	client  *http.Client
+	baseUrl string
+	token   string
+}
+
+func newTasksClient(c *cli.Context) *tasksClient {
+	return &tasksClient{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseUrl: strings.TrimSuffix(c.String(AggregatorApiUrlFlag.Name), "/"),
+		token:   c.String(config.AdminApiTokenFlag.Name),
+	}
+}
+
+var (
+	// errNotFound is returned by tasksClient when the admin api answers 404.
+	errNotFound = errors.New("not found")
+	// errMethodNotAllowed is returned by tasksClient when the admin api answers 405, eg. for the routes it only serves
+	// in some modes.
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
+func (tc *tasksClient) get(path string, query url.Values, v any) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return tc.do(http.MethodGet, path, nil, http.StatusOK, v)
+}
+
+// post sends body as json and decodes the response into v.
+func (tc *tasksClient) post(path string, body any, v any) error {
+	return tc.do(http.MethodPost, path, body, http.StatusCreated, v)
+}
+
+func (tc *tasksClient) do(method, path string, body any, expectedStatus int, v any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, tc.baseUrl+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tc.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the aggregator admin api: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return errNotFound
+	case http.StatusMethodNotAllowed:
+		return errMethodNotAllowed
+	}
+	if resp.StatusCode != expectedStatus {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s failed with status %s: %s", method, path, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func listTasks(c *cli.Context) error {
+	status := c.String(TasksStatusFlag.Name)
+	archiveStatus, ok := taskStatuses[status]
+	if status != "" && !ok {
+		return fmt.Errorf("unknown status %q, must be pending, aggregated, responded, failed or expired", status)
+	}
+	var from, to time.Time
+	if value := c.String(TasksFromFlag.Name); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		from = t
+	} else if since := c.Duration(TasksSinceFlag.Name); since > 0 {
+		from = time.Now().Add(-since)
+	}
+	if value := c.String(TasksToFlag.Name); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+		to = t
+	}
+	operatorId := strings.ToLower(strings.TrimPrefix(c.String(TasksOperatorFlag.Name), "0x"))
+	limit := c.Int(TasksLimitFlag.Name)
+
+	tc := newTasksClient(c)
+
+	query := url.Values{}
+	for name, value := range map[string]string{"operator": operatorId, "symbol": c.String(TasksSymbolFlag.Name), "status": archiveStatus} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if !from.IsZero() {
+		query.Set("from", from.Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.Format(time.RFC3339))
+	}
+	query.Set("limit", strconv.Itoa(limit))
+
+	var archived []aggregator.ArchivedTask
+	err := tc.get("/history/tasks", query, &archived)
+	var tasks []taskEntry
+	switch {
+	case err == nil:
+		tasks = make([]taskEntry, 0, len(archived))
+		for _, task := range archived {
+			tasks = append(tasks, taskEntry{
+				TaskIndex:            uint32(task.TaskIndex),
+				Symbol:               task.Symbol,
+				ReferenceBlockNumber: uint32(task.ReferenceBlockNumber),
+				CreatedAt:            task.CreatedAt,
+				Status:               taskStatusName(task.Status),
+				NumResponses:         len(task.Responses),
+				TxHash:               task.TxHash,
+				Error:                task.Error,
+				FinishedAt:           task.FinishedAt,
+			})
+		}
+	case errors.Is(err, errNotFound):
+		// the archive is disabled, so only the in-flight tasks are known to the aggregator
+		tasks, err = listInFlightTasks(tc, c.String(TasksSymbolFlag.Name), status, operatorId, from, to)
+		if err != nil {
+			return err
+		}
+		if len(tasks) > limit {
+			tasks = tasks[:limit]
+		}
+	default:
+		return err
+	}
+
+	if outputFormat == jsonOutput {
+		return printJson(tasks)
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No tasks")
+		return nil
+	}
+	w := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tSYMBOL\tBLOCK\tCREATED\tSTATUS\tRESPONSES\tTX")
+	for _, task := range tasks {
+		txHash := task.TxHash
+		if task.Error != "" {
+			txHash = task.Error
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%d\t%s\n", task.TaskIndex, task.Symbol, task.ReferenceBlockNumber,
+			task.CreatedAt.Local().Format(time.DateTime), task.Status, task.NumResponses, txHash)
+	}
+	return w.Flush()
+}
+
+// listInFlightTasks lists the tasks the aggregator still waits responses for, most recent first, filtering them like
+// the archive does.
+func listInFlightTasks(tc *tasksClient, symbol, status, operatorId string, from, to time.Time) ([]taskEntry, error) {
+	var summaries []aggregator.TaskSummary
+	if err := tc.get("/tasks", nil, &summaries); err != nil {
+		return nil, err
+	}
+	tasks := []taskEntry{}
+	// the in-flight tasks are all pending
+	if status != "" && status != "pending" {
+		return tasks, nil
+	}
+	for i := len(summaries) - 1; i >= 0; i-- {
+		summary := summaries[i]
+		if symbol != "" && summary.Symbol != symbol ||
+			!from.IsZero() && summary.CreatedAt.Before(from) ||
+			!to.IsZero() && !summary.CreatedAt.Before(to) {
+			continue
+		}
+		if operatorId != "" {
+			var details aggregator.TaskDetails
+			err := tc.get(fmt.Sprintf("/tasks/%d", summary.TaskIndex), nil, &details)
+			// the task may have completed in the meantime
+			if errors.Is(err, errNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := details.OperatorDigests[operatorId]; !ok {
+				continue
+			}
+		}
+		tasks = append(tasks, taskEntry{
+			TaskIndex:            uint32(summary.TaskIndex),
+			Symbol:               summary.Symbol,
+			ReferenceBlockNumber: uint32(summary.ReferenceBlockNumber),
+			CreatedAt:            summary.CreatedAt,
+			Status:               "pending",
+			NumResponses:         summary.NumResponses,
+		})
+	}
+	return tasks, nil
+}
+
+// taskStatusName returns the avs tasks list name of an archive status.
+func taskStatusName(archiveStatus string) string {
+	for name, s := range taskStatuses {
+		if s == archiveStatus {
+			return name
+		}
+	}
+	return archiveStatus
+}
+
+func sendTask(c *cli.Context) error {
+	req := aggregator.CreateTaskRequest{Symbol: c.String(SendTaskSymbolFlag.Name)}
+	for _, quorum := range c.UintSlice(SendTaskQuorumsFlag.Name) {
+		if quorum > math.MaxUint8 {
+			return fmt.Errorf("invalid quorum %d", quorum)
+		}
+		req.QuorumNumbers = append(req.QuorumNumbers, int(quorum))
+	}
+	threshold := c.Uint(SendTaskThresholdFlag.Name)
+	if threshold == 0 || threshold > 100 {
+		return fmt.Errorf("invalid threshold %d, must be a percentage", threshold)
+	}
+	req.QuorumThresholdPercentage = uint8(threshold)
+
+	tc := newTasksClient(c)
+	if c.Bool(DryRunFlag.Name) {
+		// the task isn't a transaction, so there's nothing to simulate: the request is printed instead
+		body, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		return printResult(struct {
+			DryRun  bool                         `json:"dryRun"`
+			Url     string                       `json:"url"`
+			Request aggregator.CreateTaskRequest `json:"request"`
+		}{DryRun: true, Url: tc.baseUrl + "/tasks", Request: req}, fmt.Sprintf("Would POST %s/tasks: %s", tc.baseUrl, body))
+	}
+
+	var task aggregator.TaskSummary
+	err := tc.post("/tasks", req, &task)
+	if errors.Is(err, errMethodNotAllowed) {
+		return errors.New("the aggregator doesn't accept tasks from the admin api, run it with task_generation: external")
+	}
+	if err != nil {
+		return err
+	}
+	return printResult(task, fmt.Sprintf("Created task %d for %s at block %d", task.TaskIndex, task.Symbol, task.ReferenceBlockNumber))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+func versionCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "version",
+		Usage:  "prints the version, git commit, build date and go version of the binary, with the contract and protocol versions it supports",
+		Action: printVersion,
+	}
+}
+
+func printVersion(c *cli.Context) error {
+	info := node.BuildInfo()
+	var lines []string
+	for _, field := range []struct{ name, value string }{
+		{"version", info.Version},
+		{"commit", info.Commit},
+		{"build date", info.BuildDate},
+		{"go version", info.GoVersion},
+		{"eigensdk", info.EigensdkVersion},
+		{"middleware", info.MiddlewareVersion},
+		{"protocol versions", strings.Join(info.ProtocolVersions, ", ")},
+	} {
+		// unknown when built without the linker flags or vcs information
+		if field.value != "" {
+			lines = append(lines, fmt.Sprintf("%-18s %s", field.name+":", field.value))
+		}
+	}
+	return printResult(info, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	node "github.com/zees-dev/blockless-avs/node/pkg"
+)
+
+var gcDryRunFlag = &cli.BoolFlag{
+	Name:  "dry-run",
+	Usage: "print the execution directories which would be removed, without removing them",
+}
+
+func workspaceCommand() *cli.Command {
+	// the workspace and policies are read from the b7s section of --config when it exists, unless the flags are set
+	flags := []cli.Flag{config.ConfigFileFlag, node.Workspace, node.WorkspaceGCMaxAge, node.WorkspaceGCMaxSize, gcDryRunFlag}
+	return &cli.Command{
+		Name:  "workspace",
+		Usage: "maintains the workspace of the p2p node",
+		Subcommands: []*cli.Command{
+			{
+				Name: "gc",
+				Usage: "removes the execution directories left in the workspace older than --workspace-gc-max-age, then the oldest ones " +
+					"above --workspace-gc-max-size, like the worker node does periodically; stop the node first for the running executions to be kept",
+				Action: collectWorkspace,
+				Flags:  flags,
+			},
+		},
+	}
+}
+
+func collectWorkspace(c *cli.Context) error {
+	if _, err := readOptionalNodeConfig(c); err != nil {
+		return err
+	}
+	cfg := node.ParseWorkspaceGCFlags(c)
+	if !cfg.Enabled() {
+		return fmt.Errorf("no policy to collect with, set --%s or --%s", node.WorkspaceGCMaxAge.Name, node.WorkspaceGCMaxSize.Name)
+	}
+	dryRun := c.Bool(gcDryRunFlag.Name)
+	result, err := node.CollectWorkspace(c.String(node.Workspace.Name), cfg, nil, dryRun)
+	if err != nil {
+		return err
+	}
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	text := fmt.Sprintf("%s %d execution directories (%s), kept %d (%s)", verb, len(result.Removed),
+		formatBytes(uint64(result.Freed)), result.Kept, formatBytes(uint64(result.KeptSize)))
+	return printResult(result, text)
+}
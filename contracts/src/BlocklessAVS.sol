@@ -55,6 +55,7 @@ interface IBlocklessAVS {
     function price(string calldata symbol) external view returns (Price memory);
 
     function updateOraclePrice(OracleRequest calldata oracleRequest, Price calldata priceResponse, IBLSSignatureChecker.NonSignerStakesAndSignature calldata nonSignerStakesAndSignature) external;
+    function updateOraclePrices(OracleRequest[] calldata oracleRequests, Price[] calldata priceResponses, IBLSSignatureChecker.NonSignerStakesAndSignature[] calldata nonSignerStakesAndSignatures) external;
     function freezeOperator(address _operatorAddr) external;
     function setAggregator(address _newAggregator) external;
 }
@@ -107,6 +108,8 @@ contract BlocklessAVS is IBlocklessAVS, ServiceManagerBase, BLSSignatureChecker,
 
     event OracleUpdate(Price priceResponse, OraclePriceResponseMetadata oraclePriceResponseMetadata);
 
+    event OracleUpdateFailed(string symbol, bytes reason);
+
     event AggregatorUpdated(address indexed previousAggregator, address indexed newAggregator);
 
     /*//////////////////////////////////////////////////////////////
@@ -152,6 +155,56 @@ contract BlocklessAVS is IBlocklessAVS, ServiceManagerBase, BLSSignatureChecker,
         Price calldata priceResponse,
         NonSignerStakesAndSignature calldata nonSignerStakesAndSignature
     ) external onlyAggregator {
+        _updateOraclePrice(oracleRequest, priceResponse, nonSignerStakesAndSignature);
+    }
+
+    /// @notice Updates the prices of several aggregated responses in a single transaction.
+    /// @dev A response failing its checks, e.g. a stale one, doesn't revert the others: its failure is emitted instead.
+    function updateOraclePrices(
+        OracleRequest[] calldata oracleRequests,
+        Price[] calldata priceResponses,
+        NonSignerStakesAndSignature[] calldata nonSignerStakesAndSignatures
+    ) external onlyAggregator {
+        require(
+            oracleRequests.length == priceResponses.length && priceResponses.length == nonSignerStakesAndSignatures.length,
+            "BlocklessAVS.updateOraclePrices: responses length mismatch"
+        );
+        for (uint256 i = 0; i < priceResponses.length; i++) {
+            try this.updateOraclePriceFromBatch(oracleRequests[i], priceResponses[i], nonSignerStakesAndSignatures[i]) {
+            } catch (bytes memory reason) {
+                emit OracleUpdateFailed(priceResponses[i].symbol, reason);
+            }
+        }
+    }
+
+    /// @dev external so that updateOraclePrices can catch the failure of each response; only the contract itself can call it.
+    function updateOraclePriceFromBatch(
+        OracleRequest calldata oracleRequest,
+        Price calldata priceResponse,
+        NonSignerStakesAndSignature calldata nonSignerStakesAndSignature
+    ) external {
+        require(msg.sender == address(this), "BlocklessAVS.updateOraclePriceFromBatch: only callable by updateOraclePrices");
+        _updateOraclePrice(oracleRequest, priceResponse, nonSignerStakesAndSignature);
+    }
+
+    // TODO: look into introducing this function
+    /// @notice Called in the event of challenge resolution, in order to forward a call to the Slasher, which 'freezes' the `operator`.
+    /// @dev The Slasher contract is under active development and its interface expected to change.
+    ///      We recommend writing slashing logic without integrating with the Slasher at this point in time.
+    function freezeOperator(address operatorAddr) external onlyAggregator {
+        // slasher.freezeOperator(operatorAddr);
+    }
+
+    /*//////////////////////////////////////////////////////////////
+                            INTERNAL FUNCTIONS
+    //////////////////////////////////////////////////////////////*/
+
+    /// @dev checks the aggregated signatures of the response against the quorum thresholds and stores its price.
+    function _updateOraclePrice(
+        OracleRequest calldata oracleRequest,
+        Price calldata priceResponse,
+        NonSignerStakesAndSignature calldata nonSignerStakesAndSignature
+    ) internal {
         require(oracleRequest.referenceBlockNumber <= uint32(block.number), "BlocklessAVS.updateOraclePrice: specified referenceBlockNumber is in future");
         require(
             (oracleRequest.referenceBlockNumber + BLOCK_STALE_MEASURE) >= uint32(block.number),
@@ -198,18 +251,6 @@ contract BlocklessAVS is IBlocklessAVS, ServiceManagerBase, BLSSignatureChecker,
         emit OracleUpdate(priceResponse, oraclePriceResponseMetadata);
     }
 
-    // TODO: look into introducing this function
-    /// @notice Called in the event of challenge resolution, in order to forward a call to the Slasher, which 'freezes' the `operator`.
-    /// @dev The Slasher contract is under active development and its interface expected to change.
-    ///      We recommend writing slashing logic without integrating with the Slasher at this point in time.
-    function freezeOperator(address operatorAddr) external onlyAggregator {
-        // slasher.freezeOperator(operatorAddr);
-    }
-
-    /*//////////////////////////////////////////////////////////////
-                            INTERNAL FUNCTIONS
-    //////////////////////////////////////////////////////////////*/
-
     /// @dev only the aggregator can call this function.
     /// @param price The new price.
     function validateOraclePrice(Price calldata price) internal {
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: UNLICENSED
+pragma solidity ^0.8.12;
+
+import "@openzeppelin/contracts/proxy/transparent/ProxyAdmin.sol";
+import "@openzeppelin/contracts/proxy/transparent/TransparentUpgradeableProxy.sol";
+
+import "@eigenlayer/contracts/permissions/PauserRegistry.sol";
+import {IAVSDirectory} from "@eigenlayer/contracts/interfaces/IAVSDirectory.sol";
+import {IRegistryCoordinator} from "@eigenlayer-middleware/src/interfaces/IRegistryCoordinator.sol";
+import {IStakeRegistry} from "@eigenlayer-middleware/src/interfaces/IStakeRegistry.sol";
+import {IBLSSignatureChecker} from "@eigenlayer-middleware/src/interfaces/IBLSSignatureChecker.sol";
+
+import {BlocklessAVS, IBlocklessAVS} from "../src/BlocklessAVS.sol";
+
+import "forge-std/Test.sol";
+
+contract BlocklessAVSTest is Test {
+    event OracleUpdateFailed(string symbol, bytes reason);
+
+    address public constant AGGREGATOR_ADDR = address(0xa66);
+
+    BlocklessAVS public blocklessAVS;
+
+    function setUp() public {
+        // the signature checker reads its registries from the registry coordinator when constructed
+        address registryCoordinator = address(0xc00);
+        address stakeRegistry = address(0x5a4e);
+        vm.mockCall(registryCoordinator, abi.encodeWithSignature("stakeRegistry()"), abi.encode(stakeRegistry));
+        vm.mockCall(registryCoordinator, abi.encodeWithSignature("blsApkRegistry()"), abi.encode(address(0xb15)));
+        vm.mockCall(stakeRegistry, abi.encodeWithSignature("delegation()"), abi.encode(address(0xde1)));
+
+        BlocklessAVS implementation = new BlocklessAVS(
+            IAVSDirectory(address(0xd1)),
+            IRegistryCoordinator(registryCoordinator),
+            IStakeRegistry(stakeRegistry)
+        );
+        address[] memory pausers = new address[](1);
+        pausers[0] = address(this);
+        PauserRegistry pauserRegistry = new PauserRegistry(pausers, address(this));
+        ProxyAdmin proxyAdmin = new ProxyAdmin();
+        blocklessAVS = BlocklessAVS(
+            address(
+                new TransparentUpgradeableProxy(
+                    address(implementation),
+                    address(proxyAdmin),
+                    abi.encodeWithSelector(BlocklessAVS.initialize.selector, pauserRegistry, address(this), AGGREGATOR_ADDR)
+                )
+            )
+        );
+
+        vm.roll(1000);
+    }
+
+    /// @dev a batch of responses whose reference block is older than BLOCK_STALE_MEASURE, failing before the signature check.
+    function _staleBatch(uint256 size)
+        internal
+        view
+        returns (
+            IBlocklessAVS.OracleRequest[] memory oracleRequests,
+            IBlocklessAVS.Price[] memory priceResponses,
+            IBLSSignatureChecker.NonSignerStakesAndSignature[] memory signatures
+        )
+    {
+        oracleRequests = new IBlocklessAVS.OracleRequest[](size);
+        priceResponses = new IBlocklessAVS.Price[](size);
+        signatures = new IBLSSignatureChecker.NonSignerStakesAndSignature[](size);
+        for (uint256 i = 0; i < size; i++) {
+            oracleRequests[i] = IBlocklessAVS.OracleRequest("eth", 1, hex"00", 100);
+            priceResponses[i] = IBlocklessAVS.Price("eth", 3000e6, uint32(block.timestamp));
+        }
+    }
+
+    function test_updateOraclePrices_onlyAggregator() public {
+        (
+            IBlocklessAVS.OracleRequest[] memory oracleRequests,
+            IBlocklessAVS.Price[] memory priceResponses,
+            IBLSSignatureChecker.NonSignerStakesAndSignature[] memory signatures
+        ) = _staleBatch(1);
+        vm.expectRevert("Aggregator must be the caller");
+        blocklessAVS.updateOraclePrices(oracleRequests, priceResponses, signatures);
+    }
+
+    function test_updateOraclePrices_lengthMismatch() public {
+        (
+            IBlocklessAVS.OracleRequest[] memory oracleRequests,
+            IBlocklessAVS.Price[] memory priceResponses,
+        ) = _staleBatch(2);
+        IBLSSignatureChecker.NonSignerStakesAndSignature[] memory signatures =
+            new IBLSSignatureChecker.NonSignerStakesAndSignature[](1);
+        vm.prank(AGGREGATOR_ADDR);
+        vm.expectRevert("BlocklessAVS.updateOraclePrices: responses length mismatch");
+        blocklessAVS.updateOraclePrices(oracleRequests, priceResponses, signatures);
+    }
+
+    function test_updateOraclePrices_emitsFailures() public {
+        (
+            IBlocklessAVS.OracleRequest[] memory oracleRequests,
+            IBlocklessAVS.Price[] memory priceResponses,
+            IBLSSignatureChecker.NonSignerStakesAndSignature[] memory signatures
+        ) = _staleBatch(2);
+        bytes memory reason = abi.encodeWithSignature(
+            "Error(string)", "BlocklessAVS.updateOraclePrice: specified referenceBlockNumber is too far in past"
+        );
+        // each failing response emits its failure instead of reverting the batch
+        for (uint256 i = 0; i < 2; i++) {
+            vm.expectEmit(false, false, false, true, address(blocklessAVS));
+            emit OracleUpdateFailed("eth", reason);
+        }
+        vm.prank(AGGREGATOR_ADDR);
+        blocklessAVS.updateOraclePrices(oracleRequests, priceResponses, signatures);
+
+        assertEq(blocklessAVS.price("eth").price, 0);
+    }
+
+    function test_updateOraclePriceFromBatch_onlySelf() public {
+        (
+            IBlocklessAVS.OracleRequest[] memory oracleRequests,
+            IBlocklessAVS.Price[] memory priceResponses,
+            IBLSSignatureChecker.NonSignerStakesAndSignature[] memory signatures
+        ) = _staleBatch(1);
+        vm.prank(AGGREGATOR_ADDR);
+        vm.expectRevert("BlocklessAVS.updateOraclePriceFromBatch: only callable by updateOraclePrices");
+        blocklessAVS.updateOraclePriceFromBatch(oracleRequests[0], priceResponses[0], signatures[0]);
+    }
+}
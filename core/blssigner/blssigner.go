@@ -0,0 +1,198 @@
+// Package blssigner produces the operator bls signatures, either with the key loaded from the keystore or by a remote
+// signing service, so the key doesn't need to be loaded into the operator process.
+//
+// AWS KMS and CloudHSM don't support the bn254 curve natively, so keys held in KMS or an HSM are used through a signing
+// service in front of them (eg. a Nitro Enclave unsealing a KMS encrypted key, or an HSM with custom bn254 firmware)
+// which implements the remote signing api:
+//
+//	GET  <url>/v1/pubkey -> {"g1": "<hex>", "g2": "<hex>"}
+//	POST <url>/v1/sign {"message": "<hex 32 bytes>"} -> {"signature": "<hex>"}
+//
+// Points are hex encoded in the eigensdk serialization (64 bytes for G1, 128 bytes for G2).
+//
+// This package only implements the client of the remote signing api; it has no KMS or HSM backend, which would need
+// bn254 support from the provider.
+package blssigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Backend is the backend producing the bls signatures.
+type Backend string
+
+const (
+	KeystoreBackend Backend = "keystore"
+	RemoteBackend   Backend = "remote"
+)
+
+// Config configures the bls signing backend.
+type Config struct {
+	// keystore or remote (default keystore)
+	Backend Backend `yaml:"backend"`
+	// url of the remote signing service
+	Url string `yaml:"url"`
+	// timeout of a single remote signing request (default 5s)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Signer signs messages with the operator bls key.
+type Signer interface {
+	SignMessage(ctx context.Context, message [32]byte) (*bls.Signature, error)
+	GetPubKeyG1() *bls.G1Point
+	GetPubKeyG2() *bls.G2Point
+}
+
+// keystoreSigner signs with the key pair loaded from the keystore.
+type keystoreSigner struct {
+	*bls.KeyPair
+}
+
+func NewKeystoreSigner(keyPair *bls.KeyPair) Signer {
+	return keystoreSigner{keyPair}
+}
+
+func (s keystoreSigner) SignMessage(_ context.Context, message [32]byte) (*bls.Signature, error) {
+	return s.KeyPair.SignMessage(message), nil
+}
+
+type pubkeyResponse struct {
+	G1 string `json:"g1"`
+	G2 string `json:"g2"`
+}
+
+type signRequest struct {
+	Message string `json:"message"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+// remoteSigner signs with a key held by a remote signing service.
+type remoteSigner struct {
+	url    string
+	token  string
+	client *http.Client
+	g1     *bls.G1Point
+	g2     *bls.G2Point
+}
+
+// NewRemoteSigner connects to the remote signing service and fetches the public keys of the signing key. The bearer
+// token sent to the service is read from the OPERATOR_BLS_SIGNER_TOKEN env var.
+func NewRemoteSigner(ctx context.Context, cfg Config) (Signer, error) {
+	if cfg.Url == "" {
+		return nil, errors.New("remote bls signer url is not set")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	s := &remoteSigner{
+		url:    strings.TrimSuffix(cfg.Url, "/"),
+		token:  os.Getenv("OPERATOR_BLS_SIGNER_TOKEN"),
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+	var pubkey pubkeyResponse
+	if err := s.call(ctx, http.MethodGet, "/v1/pubkey", nil, &pubkey); err != nil {
+		return nil, fmt.Errorf("could not get remote bls signer pubkey: %w", err)
+	}
+	g1, err := decodePoint(pubkey.G1, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote bls signer g1 pubkey: %w", err)
+	}
+	g2, err := decodePoint(pubkey.G2, 128)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote bls signer g2 pubkey: %w", err)
+	}
+	s.g1 = new(bls.G1Point).Deserialize(g1)
+	s.g2 = new(bls.G2Point).Deserialize(g2)
+	if !s.g1.IsOnCurve() || !s.g1.IsInSubGroup() || !s.g2.IsOnCurve() || !s.g2.IsInSubGroup() {
+		return nil, errors.New("remote bls signer pubkey is not a valid bn254 point")
+	}
+	if ok, err := s.g1.VerifyEquivalence(s.g2); err != nil || !ok {
+		return nil, errors.New("remote bls signer g1 and g2 pubkeys don't belong to the same key")
+	}
+	return s, nil
+}
+
+func (s *remoteSigner) GetPubKeyG1() *bls.G1Point {
+	return s.g1
+}
+
+func (s *remoteSigner) GetPubKeyG2() *bls.G2Point {
+	return s.g2
+}
+
+// SignMessage requests the signature from the signing service and verifies it against the pubkey, so a misbehaving
+// service can't make the operator send invalid signatures.
+func (s *remoteSigner) SignMessage(ctx context.Context, message [32]byte) (*bls.Signature, error) {
+	var resp signResponse
+	if err := s.call(ctx, http.MethodPost, "/v1/sign", signRequest{Message: hex.EncodeToString(message[:])}, &resp); err != nil {
+		return nil, fmt.Errorf("remote bls signer failed to sign: %w", err)
+	}
+	data, err := decodePoint(resp.Signature, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote bls signature: %w", err)
+	}
+	sig := &bls.Signature{G1Point: new(bls.G1Point).Deserialize(data)}
+	if ok, err := sig.Verify(s.g2, message); err != nil || !ok {
+		return nil, errors.New("remote bls signature doesn't verify against the signer pubkey")
+	}
+	return sig, nil
+}
+
+func (s *remoteSigner) call(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("signer responded with status %d: %s", resp.StatusCode, respBody)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func decodePoint(s string, size int) ([]byte, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(data))
+	}
+	return data, nil
+}
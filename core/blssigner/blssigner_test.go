@@ -0,0 +1,97 @@
+package blssigner
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+)
+
+// newSigningService serves the remote signing api, signing with the sign key and reporting the pubkeys of the pubkey one.
+func newSigningService(t *testing.T, pubkey, sign *bls.KeyPair) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/pubkey", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(pubkeyResponse{
+			G1: hex.EncodeToString(pubkey.GetPubKeyG1().Serialize()),
+			G2: hex.EncodeToString(pubkey.GetPubKeyG2().Serialize()),
+		})
+	})
+	mux.HandleFunc("POST /v1/sign", func(w http.ResponseWriter, r *http.Request) {
+		var req signRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := hex.DecodeString(req.Message)
+		if err != nil || len(data) != 32 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sig := sign.SignMessage([32]byte(data))
+		json.NewEncoder(w).Encode(signResponse{Signature: hex.EncodeToString(sig.Serialize())})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteSigner(t *testing.T) {
+	t.Setenv("OPERATOR_BLS_SIGNER_TOKEN", "secret")
+	key, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate bls key: %v", err)
+	}
+	other, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate bls key: %v", err)
+	}
+	message := [32]byte{1, 2, 3}
+
+	tests := []struct {
+		name string
+		sign *bls.KeyPair
+		// the signature must verify against the pubkey of key
+		valid bool
+	}{
+		{"signs with the pubkey's key", key, true},
+		{"signs with another key", other, false},
+	}
+
+	for _, test := range tests {
+		server := newSigningService(t, key, test.sign)
+		signer, err := NewRemoteSigner(context.Background(), Config{Backend: RemoteBackend, Url: server.URL + "/"})
+		if err != nil {
+			t.Fatalf("%s: Failed to create remote signer: %v", test.name, err)
+		}
+		if !signer.GetPubKeyG1().Equal(key.GetPubKeyG1().G1Affine) {
+			t.Errorf("%s: Expected the g1 pubkey of the service", test.name)
+		}
+		sig, err := signer.SignMessage(context.Background(), message)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: Expected valid signature: %v, got error: %v", test.name, test.valid, err)
+		}
+		if err == nil && !sig.Equal(key.SignMessage(message).G1Affine) {
+			t.Errorf("%s: Expected the signature of the service", test.name)
+		}
+	}
+}
+
+func TestRemoteSignerUnauthorized(t *testing.T) {
+	t.Setenv("OPERATOR_BLS_SIGNER_TOKEN", "wrong")
+	key, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		t.Fatalf("Failed to generate bls key: %v", err)
+	}
+	server := newSigningService(t, key, key)
+	if _, err := NewRemoteSigner(context.Background(), Config{Backend: RemoteBackend, Url: server.URL}); err == nil {
+		t.Errorf("Expected unauthorized error")
+	}
+}
@@ -2,11 +2,16 @@ package chainio
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/zees-dev/blockless-avs/aggregator/types"
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	erc20mock "github.com/zees-dev/blockless-avs/contracts/bindings/ERC20Mock"
+	"github.com/zees-dev/blockless-avs/core"
 	"github.com/zees-dev/blockless-avs/core/config"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	gethcommon "github.com/ethereum/go-ethereum/common"
 
@@ -22,6 +27,18 @@ type AvsReaderer interface {
 		ctx context.Context, msgHash [32]byte, quorumNumbers []byte, referenceBlockNumber uint32, nonSignerStakesAndSignature csavs.IBLSSignatureCheckerNonSignerStakesAndSignature,
 	) (csavs.IBLSSignatureCheckerQuorumStakeTotals, error)
 	GetErc20Mock(ctx context.Context, tokenAddr gethcommon.Address) (*erc20mock.ContractERC20Mock, error)
+	GetAggregatedOracleResponse(ctx context.Context, taskIndex types.TaskIndex) (*AggregatedOracleResponse, error)
+	GetAggregatedOracleResponseForEvent(ctx context.Context, event *csavs.ContractBlocklessAVSOracleUpdate) (*AggregatedOracleResponse, error)
+}
+
+// AggregatedOracleResponse is an aggregated oracle response that was accepted onchain.
+// The OracleUpdate event only contains the price, so the request and signature are decoded from the calldata of
+// the updateOraclePrice transaction that emitted it.
+type AggregatedOracleResponse struct {
+	Event                       *csavs.ContractBlocklessAVSOracleUpdate
+	OracleRequest               csavs.IBlocklessAVSOracleRequest
+	Price                       csavs.IBlocklessAVSPrice
+	NonSignerStakesAndSignature csavs.IBLSSignatureCheckerNonSignerStakesAndSignature
 }
 
 type AvsReader struct {
@@ -74,3 +91,77 @@ func (r *AvsReader) GetErc20Mock(ctx context.Context, tokenAddr gethcommon.Addre
 	}
 	return erc20Mock, nil
 }
+
+// GetAggregatedOracleResponse returns the aggregated oracle response with the given task index, where the task index is
+// the (zero based) position of its OracleUpdate event in the service manager's event history.
+func (r *AvsReader) GetAggregatedOracleResponse(ctx context.Context, taskIndex types.TaskIndex) (*AggregatedOracleResponse, error) {
+	iter, err := r.AvsServiceBindings.ServiceManager.FilterOracleUpdate(&bind.FilterOpts{Start: types.QUERY_FILTER_FROM_BLOCK, Context: ctx})
+	if err != nil {
+		r.logger.Error("Failed to filter OracleUpdate events", "err", err)
+		return nil, err
+	}
+	defer iter.Close()
+
+	var event *csavs.ContractBlocklessAVSOracleUpdate
+	for i := types.TaskIndex(0); iter.Next(); i++ {
+		if i == taskIndex {
+			event = iter.Event
+			break
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, fmt.Errorf("no aggregated oracle response found for task index %d", taskIndex)
+	}
+	return r.GetAggregatedOracleResponseForEvent(ctx, event)
+}
+
+// GetAggregatedOracleResponseForEvent decodes the updateOraclePrice arguments from the transaction which emitted the given OracleUpdate event.
+func (r *AvsReader) GetAggregatedOracleResponseForEvent(ctx context.Context, event *csavs.ContractBlocklessAVSOracleUpdate) (*AggregatedOracleResponse, error) {
+	tx, _, err := r.AvsServiceBindings.ethClient.TransactionByHash(ctx, event.Raw.TxHash)
+	if err != nil {
+		r.logger.Error("Failed to fetch updateOraclePrice transaction", "txHash", event.Raw.TxHash, "err", err)
+		return nil, err
+	}
+	var resp *AggregatedOracleResponse
+	if isBatchCalldata(tx.Data()) {
+		// the response was sent as part of a batch
+		priceDigest, err := core.GetPriceDigest(&event.PriceResponse)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = decodeUpdateOraclePricesCalldata(tx.Data(), priceDigest)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err = decodeUpdateOraclePriceCalldata(tx.Data())
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.Event = event
+	return resp, nil
+}
+
+func decodeUpdateOraclePriceCalldata(data []byte) (*AggregatedOracleResponse, error) {
+	contractAbi, err := csavs.ContractBlocklessAVSMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method := contractAbi.Methods["updateOraclePrice"]
+	if len(data) < 4 || string(data[:4]) != string(method.ID) {
+		return nil, errors.New("transaction is not an updateOraclePrice call")
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("could not unpack updateOraclePrice calldata: %w", err)
+	}
+	return &AggregatedOracleResponse{
+		OracleRequest:               *abi.ConvertType(args[0], new(csavs.IBlocklessAVSOracleRequest)).(*csavs.IBlocklessAVSOracleRequest),
+		Price:                       *abi.ConvertType(args[1], new(csavs.IBlocklessAVSPrice)).(*csavs.IBlocklessAVSPrice),
+		NonSignerStakesAndSignature: *abi.ConvertType(args[2], new(csavs.IBLSSignatureCheckerNonSignerStakesAndSignature)).(*csavs.IBLSSignatureCheckerNonSignerStakesAndSignature),
+	}, nil
+}
@@ -1,11 +1,16 @@
 package chainio
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	"github.com/zees-dev/blockless-avs/core/config"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
@@ -13,9 +18,13 @@ import (
 )
 
 type AvsSubscriberer interface {
-	SubscribeToOracleUpdateResponses(oracleUpdateChan chan *csavs.ContractBlocklessAVSOracleUpdate) event.Subscription
+	SubscribeToOracleUpdateResponses(oracleUpdateChan chan *csavs.ContractBlocklessAVSOracleUpdate) (event.Subscription, error)
+	SubscribeToNewHeads(headsChan chan *types.Header) (event.Subscription, error)
 }
 
+// max wait between two subscription attempts, see Resubscribe
+const maxResubscribeBackoff = time.Minute
+
 // Subscribers use a ws connection instead of http connection like Readers
 // kind of stupid that the geth client doesn't have a unified interface for both...
 // it takes a single url, so the bindings, even though they have watcher functions, those can't be used
@@ -50,13 +59,38 @@ func NewAvsSubscriber(avsContractBindings *AvsManagersBindings, logger sdkloggin
 	}
 }
 
-func (s *AvsSubscriber) SubscribeToOracleUpdateResponses(oracleUpdateChan chan *csavs.ContractBlocklessAVSOracleUpdate) event.Subscription {
+func (s *AvsSubscriber) SubscribeToOracleUpdateResponses(oracleUpdateChan chan *csavs.ContractBlocklessAVSOracleUpdate) (event.Subscription, error) {
 	sub, err := s.AvsContractBindings.ServiceManager.WatchOracleUpdate(
 		&bind.WatchOpts{}, oracleUpdateChan,
 	)
 	if err != nil {
-		s.logger.Error("Failed to subscribe to ORacleUpdate events", "err", err)
+		return nil, fmt.Errorf("failed to subscribe to OracleUpdate events: %w", err)
 	}
 	s.logger.Infof("Subscribed to OracleUpdate events")
-	return sub
+	return sub, nil
+}
+
+func (s *AvsSubscriber) SubscribeToNewHeads(headsChan chan *types.Header) (event.Subscription, error) {
+	sub, err := s.AvsContractBindings.ethClient.SubscribeNewHead(context.Background(), headsChan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	s.logger.Infof("Subscribed to new heads")
+	return sub, nil
+}
+
+// Resubscribe keeps the subscription made by subscribe alive until it's unsubscribed, subscribing again whenever it
+// fails and retrying the failed attempts with an exponential backoff up to maxResubscribeBackoff. The failures are
+// logged; the error channel of the returned subscription is only closed once unsubscribed.
+func Resubscribe(logger sdklogging.Logger, name string, subscribe func() (event.Subscription, error)) event.Subscription {
+	return event.ResubscribeErr(maxResubscribeBackoff, func(_ context.Context, lastErr error) (event.Subscription, error) {
+		if lastErr != nil {
+			logger.Error("Websocket subscription failed, resubscribing", "subscription", name, "err", lastErr)
+		}
+		sub, err := subscribe()
+		if err != nil {
+			logger.Error("Failed to subscribe, retrying", "subscription", name, "err", err)
+		}
+		return sub, err
+	})
 }
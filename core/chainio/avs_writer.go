@@ -2,12 +2,18 @@ package chainio
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	"github.com/zees-dev/blockless-avs/core/config"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
@@ -31,8 +37,24 @@ type AvsWriterer interface {
 		price csavs.IBlocklessAVSPrice,
 		nonSignerStakesAndSignature csavs.IBLSSignatureCheckerNonSignerStakesAndSignature,
 	) (*types.Receipt, error)
+
+	// SimulateAggregatedOracleResponse eth_calls updateOraclePrice with the given arguments, returning the decoded revert reason if it would fail.
+	SimulateAggregatedOracleResponse(ctx context.Context,
+		oracleResponse csavs.IBlocklessAVSOracleRequest,
+		price csavs.IBlocklessAVSPrice,
+		nonSignerStakesAndSignature csavs.IBLSSignatureCheckerNonSignerStakesAndSignature,
+	) error
+
+	// SendAggregatedOracleResponses sends several aggregated responses in a single updateOraclePrices transaction.
+	SendAggregatedOracleResponses(ctx context.Context, responses []AggregatedOracleResponse) (*types.Receipt, error)
+
+	// BatchResults returns the outcome of each response of an updateOraclePrices receipt, in the order they were sent.
+	BatchResults(receipt *types.Receipt, size int) ([]error, error)
 }
 
+// ErrSimulationReverted is returned when a simulated transaction reverts.
+var ErrSimulationReverted = errors.New("simulated transaction reverted")
+
 type AvsWriter struct {
 	avsregistry.AvsRegistryWriter
 	AvsContractBindings *AvsManagersBindings
@@ -44,7 +66,8 @@ type AvsWriter struct {
 var _ AvsWriterer = (*AvsWriter)(nil)
 
 func BuildAvsWriterFromConfig(c *config.Config) (*AvsWriter, error) {
-	return BuildAvsWriter(c.TxMgr, c.BlocklessAVSRegistryCoordinatorAddr, c.OperatorStateRetrieverAddr, *c.EthHttpClient, c.Logger)
+	txMgr := NewTxManager(*c.EthHttpClient, c.SignerFn, c.AggregatorAddress, c.Gas, c.TxMgr, c.Logger)
+	return BuildAvsWriter(txMgr, c.BlocklessAVSRegistryCoordinatorAddr, c.OperatorStateRetrieverAddr, *c.EthHttpClient, c.Logger)
 }
 
 func BuildAvsWriter(txMgr txmgr.TxManager, registryCoordinatorAddr, operatorStateRetrieverAddr gethcommon.Address, ethHttpClient eth.Client, logger logging.Logger) (*AvsWriter, error) {
@@ -92,6 +115,49 @@ func (w *AvsWriter) SendAggregatedOracleResponse(
 	return receipt, nil
 }
 
+func (w *AvsWriter) SimulateAggregatedOracleResponse(
+	ctx context.Context,
+	oracleResponse csavs.IBlocklessAVSOracleRequest,
+	price csavs.IBlocklessAVSPrice,
+	nonSignerStakesAndSignature csavs.IBLSSignatureCheckerNonSignerStakesAndSignature,
+) error {
+	txOpts, err := w.TxMgr.GetNoSendTxOpts()
+	if err != nil {
+		w.logger.Errorf("Error getting tx opts")
+		return err
+	}
+	tx, err := w.AvsContractBindings.ServiceManager.ContractBlocklessAVSTransactor.UpdateOraclePrice(txOpts, oracleResponse, price, nonSignerStakesAndSignature)
+	if err != nil {
+		// the bindings estimate gas when assembling the tx, which already fails for reverting calls
+		return fmt.Errorf("%w: %s", ErrSimulationReverted, revertReason(err))
+	}
+	_, err = w.AvsContractBindings.ethClient.CallContract(ctx, ethereum.CallMsg{
+		From:  txOpts.From,
+		To:    tx.To(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrSimulationReverted, revertReason(err))
+	}
+	return nil
+}
+
+// revertReason extracts the decoded revert reason from an eth_call/eth_estimateGas error if the node returned revert data.
+func revertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if revertData, decodeErr := hexutil.Decode(data); decodeErr == nil {
+				if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return err.Error()
+}
+
 // func (w *AvsWriter) RaiseChallenge(
 // 	ctx context.Context,
 // 	task cstaskmanager.IIncredibleSquaringTaskManagerTask,
@@ -0,0 +1,130 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+	"github.com/zees-dev/blockless-avs/core"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrOracleUpdateFailed is the outcome of a batched response the contract didn't store, see BatchResults.
+var ErrOracleUpdateFailed = errors.New("oracle update failed")
+
+// SendAggregatedOracleResponses sends several aggregated responses in a single updateOraclePrices transaction.
+// A response failing its checks, e.g. a stale one, doesn't revert the whole batch: the contract emits its failure in an
+// OracleUpdateFailed event instead, see BatchResults.
+func (w *AvsWriter) SendAggregatedOracleResponses(ctx context.Context, responses []AggregatedOracleResponse) (*types.Receipt, error) {
+	if len(responses) == 0 {
+		return nil, errors.New("no aggregated responses to send")
+	}
+	oracleRequests := make([]csavs.IBlocklessAVSOracleRequest, len(responses))
+	prices := make([]csavs.IBlocklessAVSPrice, len(responses))
+	signatures := make([]csavs.IBLSSignatureCheckerNonSignerStakesAndSignature, len(responses))
+	for i, resp := range responses {
+		oracleRequests[i] = resp.OracleRequest
+		prices[i] = resp.Price
+		signatures[i] = resp.NonSignerStakesAndSignature
+	}
+
+	txOpts, err := w.TxMgr.GetNoSendTxOpts()
+	if err != nil {
+		w.logger.Errorf("Error getting tx opts")
+		return nil, err
+	}
+	tx, err := w.AvsContractBindings.ServiceManager.ContractBlocklessAVSTransactor.UpdateOraclePrices(txOpts, oracleRequests, prices, signatures)
+	if err != nil {
+		w.logger.Error("Error assembling updateOraclePrices tx", "err", err)
+		return nil, err
+	}
+	receipt, err := w.TxMgr.Send(ctx, tx)
+	if err != nil {
+		w.logger.Errorf("Error submitting updateOraclePrices tx")
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// BatchResults returns the outcome of each response of an updateOraclePrices receipt, in the order they were sent: nil
+// when its price was stored, or an ErrOracleUpdateFailed with the revert reason of its OracleUpdateFailed event.
+// The contract emits either an OracleUpdate or an OracleUpdateFailed event per response, in order.
+func (w *AvsWriter) BatchResults(receipt *types.Receipt, size int) ([]error, error) {
+	contractAbi, err := csavs.ContractBlocklessAVSMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	updated := contractAbi.Events["OracleUpdate"].ID
+	failed := contractAbi.Events["OracleUpdateFailed"].ID
+
+	results := make([]error, 0, size)
+	for _, log := range receipt.Logs {
+		if log.Address != w.AvsContractBindings.serviceManagerAddr || len(log.Topics) == 0 {
+			continue
+		}
+		switch log.Topics[0] {
+		case updated:
+			results = append(results, nil)
+		case failed:
+			event, err := w.AvsContractBindings.ServiceManager.ParseOracleUpdateFailed(*log)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, fmt.Errorf("%w: %s", ErrOracleUpdateFailed, failureReason(event.Reason)))
+		}
+	}
+	if len(results) != size {
+		return nil, fmt.Errorf("updateOraclePrices receipt has %d oracle update events, expected %d", len(results), size)
+	}
+	return results, nil
+}
+
+// failureReason decodes the revert reason of an OracleUpdateFailed event, falling back to its raw bytes.
+func failureReason(reason []byte) string {
+	if decoded, err := abi.UnpackRevert(reason); err == nil {
+		return decoded
+	}
+	return hexutil.Encode(reason)
+}
+
+// decodeUpdateOraclePricesCalldata finds the response of an updateOraclePrices calldata whose price matches the given digest.
+func decodeUpdateOraclePricesCalldata(data []byte, priceDigest [32]byte) (*AggregatedOracleResponse, error) {
+	contractAbi, err := csavs.ContractBlocklessAVSMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method := contractAbi.Methods["updateOraclePrices"]
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("could not unpack updateOraclePrices calldata: %w", err)
+	}
+	oracleRequests := *abi.ConvertType(args[0], new([]csavs.IBlocklessAVSOracleRequest)).(*[]csavs.IBlocklessAVSOracleRequest)
+	prices := *abi.ConvertType(args[1], new([]csavs.IBlocklessAVSPrice)).(*[]csavs.IBlocklessAVSPrice)
+	signatures := *abi.ConvertType(args[2], new([]csavs.IBLSSignatureCheckerNonSignerStakesAndSignature)).(*[]csavs.IBLSSignatureCheckerNonSignerStakesAndSignature)
+	if len(oracleRequests) != len(prices) || len(prices) != len(signatures) {
+		return nil, errors.New("updateOraclePrices calldata with mismatched lengths")
+	}
+	for i := range prices {
+		digest, err := core.GetPriceDigest(&prices[i])
+		if err == nil && digest == priceDigest {
+			return &AggregatedOracleResponse{
+				OracleRequest:               oracleRequests[i],
+				Price:                       prices[i],
+				NonSignerStakesAndSignature: signatures[i],
+			}, nil
+		}
+	}
+	return nil, errors.New("no matching response found in updateOraclePrices transaction")
+}
+
+func isBatchCalldata(data []byte) bool {
+	contractAbi, err := csavs.ContractBlocklessAVSMetaData.GetAbi()
+	if err != nil {
+		return false
+	}
+	return len(data) >= 4 && string(data[:4]) == string(contractAbi.Methods["updateOraclePrices"].ID)
+}
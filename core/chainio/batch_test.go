@@ -0,0 +1,88 @@
+package chainio
+
+import (
+	"errors"
+	"testing"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBatchResults(t *testing.T) {
+	serviceManagerAddr := gethcommon.HexToAddress("0x95775fD3Afb1F4072794CA4ddA27F2444BCf8Ac3")
+	serviceManager, err := csavs.NewContractBlocklessAVS(serviceManagerAddr, nil)
+	if err != nil {
+		t.Fatalf("Failed to create service manager binding: %v", err)
+	}
+	w := &AvsWriter{AvsContractBindings: &AvsManagersBindings{ServiceManager: serviceManager, serviceManagerAddr: serviceManagerAddr}}
+
+	contractAbi, err := csavs.ContractBlocklessAVSMetaData.GetAbi()
+	if err != nil {
+		t.Fatalf("Failed to get service manager abi: %v", err)
+	}
+	updated := &types.Log{Address: serviceManagerAddr, Topics: []gethcommon.Hash{contractAbi.Events["OracleUpdate"].ID}}
+	failed := func(symbol string, reason []byte) *types.Log {
+		data, err := contractAbi.Events["OracleUpdateFailed"].Inputs.Pack(symbol, reason)
+		if err != nil {
+			t.Fatalf("Failed to pack OracleUpdateFailed event: %v", err)
+		}
+		return &types.Log{Address: serviceManagerAddr, Topics: []gethcommon.Hash{contractAbi.Events["OracleUpdateFailed"].ID}, Data: data}
+	}
+	staleReason, err := (abi.Arguments{{Type: mustNewType(t, "string")}}).Pack("specified referenceBlockNumber is too far in past")
+	if err != nil {
+		t.Fatalf("Failed to pack revert reason: %v", err)
+	}
+	// Error(string) selector
+	staleReason = append([]byte{0x08, 0xc3, 0x79, 0xa0}, staleReason...)
+	otherContract := &types.Log{Address: gethcommon.HexToAddress("0x01"), Topics: []gethcommon.Hash{contractAbi.Events["OracleUpdate"].ID}}
+
+	tests := []struct {
+		name string
+		logs []*types.Log
+		size int
+		// expected failure reasons per response, empty for stored responses; nil when the results can't be read
+		expected []string
+	}{
+		{"all stored", []*types.Log{updated, updated}, 2, []string{"", ""}},
+		{"failures in order", []*types.Log{updated, failed("eth", staleReason), failed("eth", []byte{0x01})}, 3,
+			[]string{"", "oracle update failed: specified referenceBlockNumber is too far in past", "oracle update failed: 0x01"}},
+		{"logs of other contracts are ignored", []*types.Log{otherContract, updated}, 1, []string{""}},
+		{"missing results", []*types.Log{updated}, 2, nil},
+	}
+
+	for _, test := range tests {
+		results, err := w.BatchResults(&types.Receipt{Logs: test.logs}, test.size)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%s: Expected error, got results: %v", test.name, results)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %v", test.name, err)
+			continue
+		}
+		for i, result := range results {
+			if test.expected[i] == "" {
+				if result != nil {
+					t.Errorf("%s: response %d: Expected stored, got: %v", test.name, i, result)
+				}
+				continue
+			}
+			if !errors.Is(result, ErrOracleUpdateFailed) || result.Error() != test.expected[i] {
+				t.Errorf("%s: response %d: Expected failure: %v, got: %v", test.name, i, test.expected[i], result)
+			}
+		}
+	}
+}
+
+func mustNewType(t *testing.T, typ string) abi.Type {
+	abiType, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create abi type %s: %v", typ, err)
+	}
+	return abiType
+}
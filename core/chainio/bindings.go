@@ -14,9 +14,10 @@ import (
 )
 
 type AvsManagersBindings struct {
-	ServiceManager *csavs.ContractBlocklessAVS
-	ethClient      eth.Client
-	logger         logging.Logger
+	ServiceManager     *csavs.ContractBlocklessAVS
+	serviceManagerAddr gethcommon.Address
+	ethClient          eth.Client
+	logger             logging.Logger
 }
 
 func NewAvsManagersBindings(registryCoordinatorAddr, operatorStateRetrieverAddr gethcommon.Address, ethclient eth.Client, logger logging.Logger) (*AvsManagersBindings, error) {
@@ -34,9 +35,10 @@ func NewAvsManagersBindings(registryCoordinatorAddr, operatorStateRetrieverAddr
 		return nil, err
 	}
 	return &AvsManagersBindings{
-		ServiceManager: contractServiceManager,
-		ethClient:      ethclient,
-		logger:         logger,
+		ServiceManager:     contractServiceManager,
+		serviceManagerAddr: serviceManagerAddr,
+		ethClient:          ethclient,
+		logger:             logger,
 	}, nil
 }
 
@@ -0,0 +1,169 @@
+package chainio
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	ierc20 "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IERC20"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	strategymanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StrategyManager"
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+	erc20mock "github.com/zees-dev/blockless-avs/contracts/bindings/ERC20Mock"
+)
+
+// DryRunTx is a transaction a dry run TxManager simulated instead of sending it, see TxManager.SetDryRun.
+type DryRunTx struct {
+	From  gethcommon.Address  `json:"from"`
+	To    *gethcommon.Address `json:"to"`
+	Value *big.Int            `json:"value,omitempty"`
+	Data  hexutil.Bytes       `json:"data"`
+	// decoded calldata, when the method belongs to one of the contracts the avs calls
+	Method string         `json:"method,omitempty"`
+	Args   map[string]any `json:"args,omitempty"`
+	// estimated gas, and the gas limit the transaction would be sent with; both 0 when the simulation reverted
+	EstimatedGas uint64 `json:"estimatedGas"`
+	GasLimit     uint64 `json:"gasLimit"`
+	// revert reason of the simulation
+	Error string `json:"error,omitempty"`
+}
+
+// abis of the contracts the avs sends transactions to, to decode the dry run calldata
+var dryRunMetaData = []*bind.MetaData{
+	csavs.ContractBlocklessAVSMetaData,
+	regcoord.ContractRegistryCoordinatorMetaData,
+	delegationmanager.ContractDelegationManagerMetaData,
+	strategymanager.ContractStrategyManagerMetaData,
+	ierc20.ContractIERC20MetaData,
+	erc20mock.ContractERC20MockMetaData,
+}
+
+var (
+	dryRunAbisOnce sync.Once
+	dryRunAbis     []*abi.ABI
+)
+
+// SetDryRun makes Send simulate the transactions instead of sending them, recording them for DryRunTxs.
+func (m *TxManager) SetDryRun(dryRun bool) {
+	m.dryRunMu.Lock()
+	defer m.dryRunMu.Unlock()
+	m.dryRun = dryRun
+}
+
+func (m *TxManager) isDryRun() bool {
+	m.dryRunMu.Lock()
+	defer m.dryRunMu.Unlock()
+	return m.dryRun
+}
+
+// DryRunTxs returns the transactions simulated since dry run was set, in order.
+func (m *TxManager) DryRunTxs() []DryRunTx {
+	m.dryRunMu.Lock()
+	defer m.dryRunMu.Unlock()
+	return append([]DryRunTx{}, m.dryRunTxs...)
+}
+
+// simulate estimates the gas of tx and records it, returning a successful receipt so that the callers proceed with
+// their next transactions; these are simulated against the current chain state, without the effects of the previous
+// ones. It returns ErrSimulationReverted when the estimation reverts.
+func (m *TxManager) simulate(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	dryRunTx := DryRunTx{From: m.sender, To: tx.To(), Data: tx.Data()}
+	if tx.Value() != nil && tx.Value().Sign() > 0 {
+		dryRunTx.Value = tx.Value()
+	}
+	dryRunTx.Method, dryRunTx.Args = decodeCalldata(tx.Data())
+
+	gas, err := m.client.EstimateGas(ctx, ethereum.CallMsg{From: m.sender, To: tx.To(), Value: tx.Value(), Data: tx.Data()})
+	if err != nil {
+		dryRunTx.Error = revertReason(err)
+	} else {
+		dryRunTx.EstimatedGas = gas
+		dryRunTx.GasLimit = uint64(float64(gas) * m.gasConfig().GasLimitMultiplier)
+	}
+	m.dryRunMu.Lock()
+	m.dryRunTxs = append(m.dryRunTxs, dryRunTx)
+	m.dryRunMu.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSimulationReverted, dryRunTx.Error)
+	}
+	m.logger.Info("Dry run, not sending transaction", "to", tx.To(), "method", dryRunTx.Method, "gas", gas)
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), GasUsed: gas}, nil
+}
+
+// decodeCalldata returns the method and arguments of the calldata, or empty values when the method is unknown.
+func decodeCalldata(data []byte) (string, map[string]any) {
+	if len(data) < 4 {
+		return "", nil
+	}
+	dryRunAbisOnce.Do(func() {
+		for _, metaData := range dryRunMetaData {
+			if parsed, err := metaData.GetAbi(); err == nil {
+				dryRunAbis = append(dryRunAbis, parsed)
+			}
+		}
+	})
+	for _, parsed := range dryRunAbis {
+		method, err := parsed.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		args := map[string]any{}
+		if err := method.Inputs.UnpackIntoMap(args, data[4:]); err != nil {
+			return method.Name, nil
+		}
+		for name, value := range args {
+			args[name] = printableArg(reflect.ValueOf(value))
+		}
+		return method.Name, args
+	}
+	return "", nil
+}
+
+// printableArg converts a decoded abi argument to a value which marshals to readable json: bytes as hex instead of
+// base64 or arrays of numbers, and the anonymous structs of the tuples as objects.
+func printableArg(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		if b, ok := v.Interface().(*big.Int); ok {
+			return b.String()
+		}
+		return printableArg(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = printableArg(v.Index(i))
+		}
+		return values
+	case reflect.Struct:
+		fields := map[string]any{}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fields[v.Type().Field(i).Name] = printableArg(v.Field(i))
+			}
+		}
+		return fields
+	default:
+		return v.Interface()
+	}
+}
@@ -0,0 +1,88 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	logging "github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+// ErrOperatorMetadataNotSet is returned when the operator never set a metadata uri.
+var ErrOperatorMetadataNotSet = errors.New("operator metadata uri not set")
+
+// OperatorMetadataURI is the metadata uri of an operator, as last set on the DelegationManager.
+type OperatorMetadataURI struct {
+	Operator    gethcommon.Address `json:"operator"`
+	URI         string             `json:"uri"`
+	BlockNumber uint64             `json:"blockNumber"`
+	TxHash      gethcommon.Hash    `json:"txHash"`
+}
+
+// OperatorMetadataClient updates and reads back the operator metadata uri (the public operator profile) on the
+// eigenlayer DelegationManager.
+type OperatorMetadataClient struct {
+	delegationManager *delegationmanager.ContractDelegationManager
+	txMgr             txmgr.TxManager
+	logger            logging.Logger
+}
+
+func NewOperatorMetadataClient(delegationManagerAddr gethcommon.Address, ethClient eth.Client, txMgr txmgr.TxManager, logger logging.Logger) (*OperatorMetadataClient, error) {
+	delegationManager, err := delegationmanager.NewContractDelegationManager(delegationManagerAddr, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	return &OperatorMetadataClient{delegationManager: delegationManager, txMgr: txMgr, logger: logger}, nil
+}
+
+// UpdateOperatorMetadataURI sets the metadata uri of the operator sending the transaction.
+func (c *OperatorMetadataClient) UpdateOperatorMetadataURI(ctx context.Context, uri string) (*types.Receipt, error) {
+	txOpts, err := c.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.delegationManager.UpdateOperatorMetadataURI(txOpts, uri)
+	if err != nil {
+		c.logger.Error("Error assembling UpdateOperatorMetadataURI tx", "err", err)
+		return nil, err
+	}
+	receipt, err := c.txMgr.Send(ctx, tx)
+	if err != nil {
+		c.logger.Error("Error submitting UpdateOperatorMetadataURI tx", "err", err)
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// GetOperatorMetadataURI returns the metadata uri the operator set last. The DelegationManager only emits the uri in
+// the OperatorMetadataURIUpdated event, so the events are scanned from fromBlock.
+func (c *OperatorMetadataClient) GetOperatorMetadataURI(ctx context.Context, operator gethcommon.Address, fromBlock uint64) (*OperatorMetadataURI, error) {
+	it, err := c.delegationManager.FilterOperatorMetadataURIUpdated(&bind.FilterOpts{Start: fromBlock, Context: ctx}, []gethcommon.Address{operator})
+	if err != nil {
+		return nil, fmt.Errorf("could not filter OperatorMetadataURIUpdated events: %w", err)
+	}
+	defer it.Close()
+	var latest *OperatorMetadataURI
+	for it.Next() {
+		latest = &OperatorMetadataURI{
+			Operator:    it.Event.Operator,
+			URI:         it.Event.MetadataURI,
+			BlockNumber: it.Event.Raw.BlockNumber,
+			TxHash:      it.Event.Raw.TxHash,
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return nil, ErrOperatorMetadataNotSet
+	}
+	return latest, nil
+}
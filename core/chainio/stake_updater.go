@@ -0,0 +1,83 @@
+package chainio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	logging "github.com/Layr-Labs/eigensdk-go/logging"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// StakeUpdate is an UpdateOperatorsForQuorum transaction updating the stakes of the entire operator set of the quorums,
+// assembled but not sent yet.
+type StakeUpdate struct {
+	Tx                 *types.Transaction
+	QuorumNumbers      sdktypes.QuorumNums
+	OperatorsPerQuorum [][]gethcommon.Address
+}
+
+// StakeUpdater updates the stakes of the entire operator set of quorums through the registry coordinator, so the stake
+// snapshots signatures are checked against follow the shares delegated to the operators.
+type StakeUpdater struct {
+	registryCoordinator *regcoord.ContractRegistryCoordinator
+	avsRegistryReader   *avsregistry.AvsRegistryChainReader
+	txMgr               txmgr.TxManager
+	logger              logging.Logger
+}
+
+func NewStakeUpdater(registryCoordinatorAddr gethcommon.Address, avsRegistryReader *avsregistry.AvsRegistryChainReader, ethClient eth.Client, txMgr txmgr.TxManager, logger logging.Logger) (*StakeUpdater, error) {
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinator(registryCoordinatorAddr, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	return &StakeUpdater{
+		registryCoordinator: registryCoordinator,
+		avsRegistryReader:   avsRegistryReader,
+		txMgr:               txMgr,
+		logger:              logger,
+	}, nil
+}
+
+// BuildStakeUpdate assembles the transaction updating the stakes of all the operators currently registered in the
+// quorums. The gas limit and fees of the transaction are estimated, so its cost can be checked before sending it.
+func (u *StakeUpdater) BuildStakeUpdate(ctx context.Context, quorumNumbers sdktypes.QuorumNums) (*StakeUpdate, error) {
+	operatorsPerQuorum, err := u.avsRegistryReader.GetOperatorAddrsInQuorumsAtCurrentBlock(&bind.CallOpts{Context: ctx}, quorumNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the operators of quorums %v: %w", quorumNumbers, err)
+	}
+	// the registry coordinator requires the operators of each quorum in ascending address order
+	for _, operators := range operatorsPerQuorum {
+		slices.SortFunc(operators, func(a, b gethcommon.Address) int { return bytes.Compare(a[:], b[:]) })
+	}
+	txOpts, err := u.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return nil, err
+	}
+	txOpts.Context = ctx
+	tx, err := u.registryCoordinator.UpdateOperatorsForQuorum(txOpts, operatorsPerQuorum, quorumNumbers.UnderlyingType())
+	if err != nil {
+		u.logger.Error("Error assembling UpdateOperatorsForQuorum tx", "quorumNumbers", quorumNumbers, "err", err)
+		return nil, err
+	}
+	return &StakeUpdate{Tx: tx, QuorumNumbers: quorumNumbers, OperatorsPerQuorum: operatorsPerQuorum}, nil
+}
+
+// SendStakeUpdate sends the assembled stake update transaction and waits for its receipt.
+func (u *StakeUpdater) SendStakeUpdate(ctx context.Context, update *StakeUpdate) (*types.Receipt, error) {
+	receipt, err := u.txMgr.Send(ctx, update.Tx)
+	if err != nil {
+		u.logger.Error("Error submitting UpdateOperatorsForQuorum tx", "quorumNumbers", update.QuorumNumbers, "err", err)
+		return nil, err
+	}
+	return receipt, nil
+}
@@ -0,0 +1,359 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	logging "github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+)
+
+var (
+	ErrSendDeadlineExceeded = errors.New("send: transaction was not included before the send deadline")
+	ErrFeeCapReached        = errors.New("send: transaction was not included and its fees reached the configured max fee")
+)
+
+// TxManager is a txmgr.TxManager which prices transactions according to a config.GasConfig and makes sure they get included.
+// Unlike the eigensdk SimpleTxManager, it
+//   - respects fee caps, fixed priority fees and legacy (type 0) transactions, so the aggregator can control its costs
+//   - tracks the sender nonce locally, so concurrent sends don't reuse the same nonce
+//   - resubmits transactions with bumped fees when no receipt arrives within the receipt timeout,
+//     until one of the submitted transactions is included or the send deadline is reached
+type TxManager struct {
+	client   eth.Client
+	signerFn signerv2.SignerFn
+	sender   gethcommon.Address
+	cfg      config.TxMgrConfig
+	logger   logging.Logger
+
+	gasMu sync.RWMutex
+	gas   config.GasConfig
+
+	nonceMu sync.Mutex
+	// next nonce to use; nil until it is fetched from the chain
+	nonce *uint64
+
+	dryRunMu  sync.Mutex
+	dryRun    bool
+	dryRunTxs []DryRunTx
+}
+
+var _ txmgr.TxManager = (*TxManager)(nil)
+
+func NewTxManager(client eth.Client, signerFn signerv2.SignerFn, sender gethcommon.Address, gas config.GasConfig, cfg config.TxMgrConfig, logger logging.Logger) *TxManager {
+	return &TxManager{
+		client:   client,
+		signerFn: signerFn,
+		sender:   sender,
+		gas:      gas,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+// SetGas replaces the gas config; transactions priced from now on use the new config.
+func (m *TxManager) SetGas(gas config.GasConfig) {
+	m.gasMu.Lock()
+	defer m.gasMu.Unlock()
+	m.gas = gas
+}
+
+func (m *TxManager) gasConfig() config.GasConfig {
+	m.gasMu.RLock()
+	defer m.gasMu.RUnlock()
+	return m.gas
+}
+
+// GetNoSendTxOpts generates a noSend TransactOpts which can be used to assemble a transaction with the
+// contract bindings without sending it. The assembled transaction is then priced and signed by Send.
+func (m *TxManager) GetNoSendTxOpts() (*bind.TransactOpts, error) {
+	return &bind.TransactOpts{
+		From:   m.sender,
+		NoSend: true,
+		Signer: txmgr.NoopSigner,
+	}, nil
+}
+
+// Send assigns the next nonce to the (unsigned) transaction, prices it according to the gas config, signs and sends it,
+// and waits for its receipt, resubmitting it with bumped fees whenever the receipt timeout elapses. In dry run, it only
+// simulates the transaction, see SetDryRun.
+func (m *TxManager) Send(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	if m.isDryRun() {
+		return m.simulate(ctx, tx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.SendDeadline)
+	defer cancel()
+
+	nonce, err := m.nextNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pricedTx, err := m.priceTx(ctx, tx, nonce)
+	if err != nil {
+		m.releaseNonce(nonce)
+		return nil, err
+	}
+
+	// all the transactions sent for this nonce; any of them may end up being included
+	var sentTxs []gethcommon.Hash
+	for {
+		signedTx, err := m.signTx(ctx, pricedTx)
+		if err != nil {
+			m.releaseNonce(nonce)
+			return nil, err
+		}
+		if err := m.client.SendTransaction(ctx, signedTx); err != nil {
+			if isNonceTooLow(err) {
+				// some other process used our nonce, or a previous submission got included: refetch it on the next send
+				m.resetNonce()
+			}
+			if len(sentTxs) == 0 {
+				m.releaseNonce(nonce)
+				return nil, errors.Join(errors.New("send: failed to send transaction"), err)
+			}
+			m.logger.Warn("Failed to resubmit transaction, still waiting for previous submissions", "nonce", nonce, "err", err)
+		} else {
+			sentTxs = append(sentTxs, signedTx.Hash())
+			m.logger.Info("Sent transaction", "txHash", signedTx.Hash().Hex(), "nonce", nonce, "gas", signedTx.Gas(), "attempt", len(sentTxs))
+		}
+
+		receipt, err := m.waitForReceipt(ctx, sentTxs, m.cfg.ReceiptTimeout)
+		if err == nil {
+			return receipt, nil
+		}
+		if ctx.Err() != nil {
+			m.logger.Error("Transaction not included before the send deadline", "nonce", nonce, "submissions", len(sentTxs))
+			// the nonce may or may not be consumed by a late inclusion, so refetch it on the next send
+			m.resetNonce()
+			return nil, errors.Join(ErrSendDeadlineExceeded, err)
+		}
+
+		pricedTx, err = bumpFees(pricedTx, m.cfg.FeeBumpPercentage, m.gasConfig().MaxFeePerGas)
+		if err != nil {
+			m.logger.Error("Transaction not included and its fees can't be bumped above the max fee", "nonce", nonce, "submissions", len(sentTxs))
+			// the nonce may or may not be consumed by a late inclusion, so refetch it on the next send
+			m.resetNonce()
+			return nil, err
+		}
+		m.logger.Info("Transaction receipt not found within timeout, resubmitting with bumped fees",
+			"nonce", nonce, "timeout", m.cfg.ReceiptTimeout, "bumpPercentage", m.cfg.FeeBumpPercentage)
+	}
+}
+
+// nextNonce returns the nonce to use for the next transaction, fetching it from the chain if it isn't tracked yet.
+func (m *TxManager) nextNonce(ctx context.Context) (uint64, error) {
+	m.nonceMu.Lock()
+	defer m.nonceMu.Unlock()
+	if m.nonce == nil {
+		nonce, err := m.client.PendingNonceAt(ctx, m.sender)
+		if err != nil {
+			return 0, errors.Join(errors.New("send: failed to get pending nonce"), err)
+		}
+		m.nonce = &nonce
+	}
+	nonce := *m.nonce
+	*m.nonce++
+	return nonce, nil
+}
+
+// releaseNonce gives back a nonce which was never sent, so that it isn't skipped (which would block every later transaction).
+func (m *TxManager) releaseNonce(nonce uint64) {
+	m.nonceMu.Lock()
+	defer m.nonceMu.Unlock()
+	if m.nonce != nil && *m.nonce == nonce+1 {
+		*m.nonce = nonce
+	} else {
+		// other transactions were assigned later nonces in the meantime, resync with the chain instead
+		m.nonce = nil
+	}
+}
+
+func (m *TxManager) resetNonce() {
+	m.nonceMu.Lock()
+	defer m.nonceMu.Unlock()
+	m.nonce = nil
+}
+
+// priceTx rebuilds tx as either a legacy or dynamic fee transaction with the given nonce, and the gas limit and fees set by the gas config.
+func (m *TxManager) priceTx(ctx context.Context, tx *types.Transaction, nonce uint64) (*types.Transaction, error) {
+	gas := m.gasConfig()
+	gasLimit, err := m.estimateGasLimit(ctx, tx, gas.GasLimitMultiplier)
+	if err != nil {
+		return nil, err
+	}
+
+	if gas.TxType == config.LegacyTxType {
+		gasPrice, err := m.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Join(errors.New("send: failed to get gas price"), err)
+		}
+		gasPrice = capFee(gasPrice, gas.MaxFeePerGas)
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      gasLimit,
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	}
+
+	gasTipCap := gas.MaxPriorityFeePerGas
+	if gasTipCap == nil {
+		gasTipCap, err = m.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			// the backend might not support eth_maxPriorityFeePerGas
+			m.logger.Info("eth_maxPriorityFeePerGas is unsupported by current backend, using fallback gasTipCap")
+			gasTipCap = txmgr.FallbackGasTipCap
+		}
+	}
+	header, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("send: chain does not support EIP-1559 transactions, set gas tx_type to legacy")
+	}
+	// 2*baseFee + gasTipCap makes sure that the tx remains includeable for 6 consecutive 100% full blocks.
+	// see https://www.blocknative.com/blog/eip-1559-fees
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+	gasFeeCap = capFee(gasFeeCap, gas.MaxFeePerGas)
+	gasTipCap = capFee(gasTipCap, gasFeeCap)
+
+	chainId, err := m.client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasLimit,
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}), nil
+}
+
+// bumpFees returns a copy of tx with its fees increased by percentage, capped to max (nil means uncapped).
+// A replacement tx must pay more than the one it replaces, so ErrFeeCapReached is returned once the fees are at the cap.
+func bumpFees(tx *types.Transaction, percentage uint64, max *big.Int) (*types.Transaction, error) {
+	if tx.Type() == types.LegacyTxType {
+		gasPrice := capFee(bumpFee(tx.GasPrice(), percentage), max)
+		if gasPrice.Cmp(tx.GasPrice()) <= 0 {
+			return nil, ErrFeeCapReached
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	}
+	gasFeeCap := capFee(bumpFee(tx.GasFeeCap(), percentage), max)
+	if gasFeeCap.Cmp(tx.GasFeeCap()) <= 0 {
+		return nil, ErrFeeCapReached
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   tx.ChainId(),
+		Nonce:     tx.Nonce(),
+		GasTipCap: capFee(bumpFee(tx.GasTipCap(), percentage), gasFeeCap),
+		GasFeeCap: gasFeeCap,
+		Gas:       tx.Gas(),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}), nil
+}
+
+// estimateGasLimit returns the gas limit of tx (estimating it if unset) scaled by the configured multiplier.
+func (m *TxManager) estimateGasLimit(ctx context.Context, tx *types.Transaction, multiplier float64) (uint64, error) {
+	gasLimit := tx.Gas()
+	if gasLimit == 0 {
+		var err error
+		gasLimit, err = m.client.EstimateGas(ctx, ethereum.CallMsg{
+			From:  m.sender,
+			To:    tx.To(),
+			Value: tx.Value(),
+			Data:  tx.Data(),
+		})
+		if err != nil {
+			return 0, errors.Join(errors.New("send: failed to estimate gas"), err)
+		}
+	}
+	return uint64(float64(gasLimit) * multiplier), nil
+}
+
+func (m *TxManager) signTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	signer, err := m.signerFn(ctx, m.sender)
+	if err != nil {
+		return nil, errors.Join(errors.New("send: failed to get signer"), err)
+	}
+	signedTx, err := signer(m.sender, tx)
+	if err != nil {
+		return nil, errors.Join(errors.New("send: failed to sign transaction"), err)
+	}
+	return signedTx, nil
+}
+
+// waitForReceipt polls for the receipt of any of the given transactions until one is found or the timeout elapses.
+func (m *TxManager) waitForReceipt(ctx context.Context, txHashes []gethcommon.Hash, timeout time.Duration) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	queryTicker := time.NewTicker(2 * time.Second)
+	defer queryTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-queryTicker.C:
+			for _, txHash := range txHashes {
+				receipt, err := m.client.TransactionReceipt(ctx, txHash)
+				if errors.Is(err, ethereum.NotFound) {
+					m.logger.Debug("Transaction not yet mined", "txHash", txHash.Hex())
+					continue
+				} else if err != nil {
+					m.logger.Info("Receipt retrieval failed", "txHash", txHash.Hex(), "err", err)
+					continue
+				}
+				return receipt, nil
+			}
+		}
+	}
+}
+
+// capFee returns the smaller of fee and max; a nil max means uncapped.
+func capFee(fee, max *big.Int) *big.Int {
+	if max != nil && fee.Cmp(max) > 0 {
+		return new(big.Int).Set(max)
+	}
+	return fee
+}
+
+// bumpFee increases fee by percentage, rounding up so small fees still increase.
+func bumpFee(fee *big.Int, percentage uint64) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percentage))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
@@ -0,0 +1,84 @@
+package chainio
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee        int64
+		percentage uint64
+		expected   int64
+	}{
+		{100, 10, 110},
+		{1_000_000_000, 20, 1_200_000_000},
+		// rounds up so that small fees still increase
+		{1, 10, 2},
+		{0, 10, 0},
+	}
+
+	for _, test := range tests {
+		bumped := bumpFee(big.NewInt(test.fee), test.percentage)
+		if bumped.Cmp(big.NewInt(test.expected)) != 0 {
+			t.Errorf("Expected bumped fee: %v, got: %v", test.expected, bumped)
+		}
+	}
+}
+
+func TestCapFee(t *testing.T) {
+	tests := []struct {
+		fee      *big.Int
+		max      *big.Int
+		expected *big.Int
+	}{
+		{big.NewInt(100), nil, big.NewInt(100)},
+		{big.NewInt(100), big.NewInt(50), big.NewInt(50)},
+		{big.NewInt(100), big.NewInt(150), big.NewInt(100)},
+	}
+
+	for _, test := range tests {
+		capped := capFee(test.fee, test.max)
+		if capped.Cmp(test.expected) != 0 {
+			t.Errorf("Expected capped fee: %v, got: %v", test.expected, capped)
+		}
+	}
+}
+
+func TestBumpFees(t *testing.T) {
+	tests := []struct {
+		tx  *types.Transaction
+		max *big.Int
+		// expected gas price (legacy) or fee cap, and tip cap; nil when the bump fails
+		expectedFeeCap *big.Int
+		expectedTipCap *big.Int
+	}{
+		{types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)}), nil, big.NewInt(110), big.NewInt(110)},
+		{types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)}), big.NewInt(105), big.NewInt(105), big.NewInt(105)},
+		{types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)}), big.NewInt(100), nil, nil},
+		{types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(100)}), nil, big.NewInt(220), big.NewInt(110)},
+		// the tip is capped to the fee cap
+		{types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(200)}), big.NewInt(210), big.NewInt(210), big.NewInt(210)},
+		{types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(200), GasTipCap: big.NewInt(100)}), big.NewInt(200), nil, nil},
+	}
+
+	for _, test := range tests {
+		bumped, err := bumpFees(test.tx, 10, test.max)
+		if test.expectedFeeCap == nil {
+			if !errors.Is(err, ErrFeeCapReached) {
+				t.Errorf("Expected error: %v, got: %v", ErrFeeCapReached, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if bumped.GasFeeCap().Cmp(test.expectedFeeCap) != 0 || bumped.GasTipCap().Cmp(test.expectedTipCap) != 0 {
+			t.Errorf("Expected bumped fees: %v/%v, got: %v/%v", test.expectedFeeCap, test.expectedTipCap, bumped.GasFeeCap(), bumped.GasTipCap())
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package clock
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const (
+	defaultMaxSkew       = 2 * time.Second
+	defaultCheckInterval = 5 * time.Minute
+	ntpTimeout           = 5 * time.Second
+	// seconds between the ntp epoch (1900) and the unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+// Config configures the clock skew checks. Several anti-replay checks (eg. timestamped submissions) depend on sane clocks.
+type Config struct {
+	// maximum tolerated difference between the local clock and the reference clock
+	MaxSkew time.Duration `yaml:"max_skew"`
+	// ntp server (host or host:port) used as reference clock; if empty only the chain is used as reference
+	NtpServer string `yaml:"ntp_server"`
+	// how often the skew is re-checked after startup
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// SkewMonitor periodically measures the skew of the local clock against NTP and the latest block timestamp.
+//
+// The block timestamp is a coarse reference: blocks are produced at irregular intervals (or only on demand on devnets),
+// so it can only reveal a local clock running behind the chain. NTP reveals skew in both directions.
+type SkewMonitor struct {
+	cfg       Config
+	ethClient eth.Client
+	logger    logging.Logger
+
+	mu sync.RWMutex
+	// last measured skew (local clock - reference clock)
+	skew    time.Duration
+	checked bool
+}
+
+func NewSkewMonitor(cfg Config, ethClient eth.Client, logger logging.Logger) *SkewMonitor {
+	if cfg.MaxSkew == 0 {
+		cfg.MaxSkew = defaultMaxSkew
+	}
+	if cfg.CheckInterval == 0 {
+		cfg.CheckInterval = defaultCheckInterval
+	}
+	return &SkewMonitor{
+		cfg:       cfg,
+		ethClient: ethClient,
+		logger:    logger,
+	}
+}
+
+// Start checks the clock skew once and then keeps re-checking it every CheckInterval until ctx is done.
+func (m *SkewMonitor) Start(ctx context.Context) {
+	m.check(ctx)
+	go func() {
+		ticker := time.NewTicker(m.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.check(ctx)
+			}
+		}
+	}()
+}
+
+func (m *SkewMonitor) check(ctx context.Context) {
+	skew, err := m.Measure(ctx)
+	if err != nil {
+		m.logger.Warn("Could not measure clock skew", "err", err)
+		return
+	}
+	m.mu.Lock()
+	m.skew = skew
+	m.checked = true
+	m.mu.Unlock()
+
+	if !m.withinTolerance(skew) {
+		m.logger.Error("Local clock skew exceeds tolerance; oracle responses are neither signed nor accepted until it is fixed", "skew", skew, "maxSkew", m.cfg.MaxSkew)
+	} else {
+		m.logger.Debug("Local clock skew within tolerance", "skew", skew, "maxSkew", m.cfg.MaxSkew)
+	}
+}
+
+// Measure returns the skew of the local clock (local - reference). NTP is preferred when configured since the
+// block timestamp only provides a lower bound.
+func (m *SkewMonitor) Measure(ctx context.Context) (time.Duration, error) {
+	if m.cfg.NtpServer != "" {
+		offset, err := queryNtpOffset(ctx, m.cfg.NtpServer)
+		if err == nil {
+			return -offset, nil
+		}
+		m.logger.Warn("NTP query failed, falling back to block timestamp", "server", m.cfg.NtpServer, "err", err)
+	}
+
+	header, err := m.ethClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	skew := time.Since(time.Unix(int64(header.Time), 0))
+	if skew > 0 {
+		// a block timestamp in the past is expected and tells us nothing about the local clock
+		return 0, nil
+	}
+	return skew, nil
+}
+
+// Skew returns the last measured skew and whether it was measured at all.
+func (m *SkewMonitor) Skew() (time.Duration, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.skew, m.checked
+}
+
+// WithinTolerance reports whether the last measured skew is within MaxSkew.
+// It returns true when the skew could not be measured yet, so an unreachable reference doesn't halt the node.
+func (m *SkewMonitor) WithinTolerance() bool {
+	skew, _ := m.Skew()
+	return m.withinTolerance(skew)
+}
+
+func (m *SkewMonitor) withinTolerance(skew time.Duration) bool {
+	return skew <= m.cfg.MaxSkew && skew >= -m.cfg.MaxSkew
+}
+
+// MaxSkew returns the configured tolerance.
+func (m *SkewMonitor) MaxSkew() time.Duration {
+	return m.cfg.MaxSkew
+}
+
+// CheckTimestamp returns an error if the timestamp is further in the future than the tolerated skew.
+func (m *SkewMonitor) CheckTimestamp(ts time.Time) error {
+	if ahead := time.Until(ts); ahead > m.cfg.MaxSkew {
+		return fmt.Errorf("timestamp %s is %s in the future (max skew %s)", ts.UTC().Format(time.RFC3339), ahead, m.cfg.MaxSkew)
+	}
+	return nil
+}
+
+// queryNtpOffset performs a single SNTP (RFC 4330) request and returns the offset of the server clock relative to the local clock.
+func queryNtpOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ntpTimeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, 48)
+	// LI = 0 (no warning), VN = 4, Mode = 3 (client)
+	req[0] = 0x23
+	t1 := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	t4 := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	if n < 48 {
+		return 0, errors.New("short ntp response")
+	}
+	if mode := resp[0] & 0x7; mode != 4 {
+		return 0, fmt.Errorf("unexpected ntp response mode %d", mode)
+	}
+
+	t2 := ntpTimestamp(resp[32:40]) // server receive time
+	t3 := ntpTimestamp(resp[40:48]) // server transmit time
+	return (t2.Sub(t1) + t3.Sub(t4)) / 2, nil
+}
+
+func ntpTimestamp(b []byte) time.Time {
+	seconds := binary.BigEndian.Uint32(b[0:4])
+	fraction := binary.BigEndian.Uint32(b[4:8])
+	nanos := (uint64(fraction) * 1e9) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, int64(nanos))
+}
@@ -4,17 +4,24 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/zees-dev/blockless-avs/core/clock"
+	"github.com/zees-dev/blockless-avs/core/keystore"
 	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/remotesigner"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
 
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
-	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	sdklogging "github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/Layr-Labs/eigensdk-go/signerv2"
@@ -40,17 +47,382 @@ type Config struct {
 	RegisterOperatorOnStartup           bool
 	// json:"-" skips this field when marshaling (only used for logging to stdout), since SignerFn doesnt implement marshalJson
 	SignerFn          signerv2.SignerFn `json:"-"`
-	TxMgr             txmgr.TxManager
 	AggregatorAddress common.Address
+	// Gas controls how the aggregator prices the transactions it sends onchain
+	Gas GasConfig
+	// TxMgr controls how the aggregator handles transactions which are not included in time
+	TxMgr TxMgrConfig
+	// Clock configures the tolerated clock skew; submissions timestamped too far in the future are rejected
+	Clock clock.Config
+	// SimulateAggregatedResponses eth_calls aggregated responses before sending them, so reverts don't burn gas
+	SimulateAggregatedResponses bool
+	// Batch collects finished aggregations for a short window and submits them in a single updateOraclePrices transaction
+	Batch BatchConfig
+	// admin api for inspecting in-flight tasks; disabled when the address is empty
+	AdminApiIpPortAddr string
+	AdminApiToken      string `json:"-"`
+	Challenger         ChallengerConfig
+	// RateLimit limits the signed responses accepted by the rpc server
+	RateLimit RateLimitConfig
+	// address to serve the aggregator prometheus metrics on; disabled when empty
+	MetricsIpPortAddr string
+	// registry of the aggregator metrics, shared with the other roles of the process (see avs all-in-one); created
+	// by the aggregator when nil
+	MetricsRegistry *prometheus.Registry `json:"-"`
+	// TaskGeneration selects whether the aggregator creates tasks itself or only aggregates externally created ones
+	TaskGeneration TaskGenerationMode
+	// Shutdown controls how in-flight aggregations are drained when the aggregator stops
+	Shutdown ShutdownConfig
+	// Archive stores the history of all tasks in a sql database; disabled when no driver is set
+	Archive ArchiveConfig
+	// StakeUpdates periodically updates the stakes of the entire operator set; disabled unless enabled
+	StakeUpdates StakeUpdatesConfig
+	// LogFile writes the logs to a rotated file besides stderr; disabled when no path is set
+	LogFile logging.FileConfig
+	// operators which didn't send a heartbeat for this long are reported dead by the admin api
+	HeartbeatTimeout time.Duration
+	// chain the eth rpc node was checked to be on; 0 when not checked
+	ChainId uint64
+	// average time between two blocks, used to estimate when tasks expire
+	BlockTime time.Duration
+}
+
+// TxMgrConfig configures receipt timeouts and fee bumping of stuck transactions.
+type TxMgrConfig struct {
+	// how long to wait for a receipt before resubmitting the transaction with bumped fees
+	ReceiptTimeout time.Duration `yaml:"receipt_timeout"`
+	// percentage by which fees are bumped on resubmission (nodes require at least 10% to replace a pending tx)
+	FeeBumpPercentage uint64 `yaml:"fee_bump_percentage"`
+	// how long to keep resubmitting a transaction before giving up
+	SendDeadline time.Duration `yaml:"send_deadline"`
+}
+
+const (
+	defaultReceiptTimeout    = 1 * time.Minute
+	defaultFeeBumpPercentage = 20
+	defaultSendDeadline      = 10 * time.Minute
+	minFeeBumpPercentage     = 10
+)
+
+// NewTxMgrConfig returns a copy of the raw yaml tx manager config with unset fields set to their defaults.
+func NewTxMgrConfig(raw TxMgrConfig) (TxMgrConfig, error) {
+	return raw.withDefaults()
+}
+
+// withDefaults returns a copy of the config with unset fields set to their defaults.
+func (c TxMgrConfig) withDefaults() (TxMgrConfig, error) {
+	if c.ReceiptTimeout == 0 {
+		c.ReceiptTimeout = defaultReceiptTimeout
+	}
+	if c.FeeBumpPercentage == 0 {
+		c.FeeBumpPercentage = defaultFeeBumpPercentage
+	}
+	if c.SendDeadline == 0 {
+		c.SendDeadline = defaultSendDeadline
+	}
+	if c.FeeBumpPercentage < minFeeBumpPercentage {
+		return TxMgrConfig{}, fmt.Errorf("tx_manager fee_bump_percentage must be at least %d", minFeeBumpPercentage)
+	}
+	if c.SendDeadline < c.ReceiptTimeout {
+		return TxMgrConfig{}, errors.New("tx_manager send_deadline must be greater than receipt_timeout")
+	}
+	return c, nil
+}
+
+// BatchConfig configures batching of aggregated responses through the updateOraclePrices function of the service manager.
+type BatchConfig struct {
+	Enabled bool
+	// how long to wait for more aggregations after the first one of a batch has finished
+	Window time.Duration
+	// a batch is sent as soon as it holds this many responses
+	MaxSize int
+}
+
+// BatchConfigRaw is the yaml representation of BatchConfig.
+type BatchConfigRaw struct {
+	Enabled bool          `yaml:"enabled"`
+	Window  time.Duration `yaml:"window"`
+	MaxSize int           `yaml:"max_size"`
+}
+
+const (
+	defaultBatchWindow  = 2 * time.Second
+	defaultBatchMaxSize = 10
+)
+
+// NewBatchConfig validates the raw batch config and fills in defaults.
+func NewBatchConfig(raw BatchConfigRaw) (BatchConfig, error) {
+	cfg := BatchConfig{
+		Enabled: raw.Enabled,
+		Window:  raw.Window,
+		MaxSize: raw.MaxSize,
+	}
+	if cfg.Window == 0 {
+		cfg.Window = defaultBatchWindow
+	}
+	if cfg.MaxSize == 0 {
+		cfg.MaxSize = defaultBatchMaxSize
+	}
+	if cfg.Window < 0 || cfg.MaxSize < 0 {
+		return BatchConfig{}, errors.New("batch window and max_size must be positive")
+	}
+	return cfg, nil
+}
+
+// TokenBucketConfig configures a token bucket refilled with Rate tokens per second and holding at most Burst tokens.
+// A zero rate disables the limit.
+type TokenBucketConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// RateLimitConfig configures the rate limits of the aggregator rpc server.
+type RateLimitConfig struct {
+	// per remote ip, applied to new connections and to every call
+	PerIp TokenBucketConfig `yaml:"per_ip"`
+	// per operator id, applied once the signature of the response was verified
+	PerOperator TokenBucketConfig `yaml:"per_operator"`
+}
+
+// withDefaults returns a copy of the config, checking the limits are valid and using the rate as burst when it is unset.
+func (c RateLimitConfig) withDefaults() (RateLimitConfig, error) {
+	for _, bucket := range []*TokenBucketConfig{&c.PerIp, &c.PerOperator} {
+		if bucket.Rate < 0 || bucket.Burst < 0 {
+			return RateLimitConfig{}, errors.New("rate_limit rate and burst cannot be negative")
+		}
+		if bucket.Rate > 0 && bucket.Burst == 0 {
+			bucket.Burst = int(math.Ceil(bucket.Rate))
+		}
+	}
+	return c, nil
+}
+
+// ShutdownConfig configures the drain phase of the aggregator shutdown.
+type ShutdownConfig struct {
+	// how long to keep aggregating in-flight tasks after shutdown was requested
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// tasks still in flight after the drain are written to this file and restored on startup; disabled when empty
+	CheckpointFile string `yaml:"checkpoint_file"`
+}
+
+const defaultDrainTimeout = 30 * time.Second
+
+// withDefaults returns a copy of the config with unset fields set to their defaults.
+func (c ShutdownConfig) withDefaults() (ShutdownConfig, error) {
+	if c.DrainTimeout == 0 {
+		c.DrainTimeout = defaultDrainTimeout
+	}
+	if c.DrainTimeout < 0 {
+		return ShutdownConfig{}, errors.New("shutdown drain_timeout cannot be negative")
+	}
+	return c, nil
+}
+
+// ArchiveDriver selects the database the task history is archived in.
+type ArchiveDriver string
+
+const (
+	SqliteArchiveDriver   ArchiveDriver = "sqlite"
+	PostgresArchiveDriver ArchiveDriver = "postgres"
+)
+
+// ArchiveConfig configures the sql archive of tasks, responses, aggregation outcomes and transaction hashes.
+type ArchiveConfig struct {
+	// sqlite or postgres; the archive is disabled when empty
+	Driver ArchiveDriver `yaml:"driver"`
+	// database file for sqlite, connection string for postgres
+	Dsn string `yaml:"dsn" json:"-"`
+}
+
+// validate checks the archive driver is supported and has a dsn.
+func (c ArchiveConfig) validate() error {
+	switch c.Driver {
+	case "":
+		return nil
+	case SqliteArchiveDriver, PostgresArchiveDriver:
+	default:
+		return fmt.Errorf("unknown archive driver %q, expected %q or %q", c.Driver, SqliteArchiveDriver, PostgresArchiveDriver)
+	}
+	if c.Dsn == "" {
+		return errors.New("archive dsn must be set")
+	}
+	return nil
+}
+
+// StakeUpdatesConfig configures the scheduled stake updates of the entire operator set of the avs quorums, which keep
+// the stake snapshots responses are checked against in line with the shares delegated to the operators.
+type StakeUpdatesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// time between two stake updates (default 24h)
+	Interval time.Duration `yaml:"interval"`
+	// updates whose estimated cost (gas limit times fee cap) exceeds this budget are skipped; 0 means unlimited
+	MaxCostGwei float64 `yaml:"max_cost_gwei"`
+	// only log the operators which would be updated and the estimated cost, without sending the transaction
+	DryRun bool `yaml:"dry_run"`
+}
+
+const defaultStakeUpdatesInterval = 24 * time.Hour
+
+// withDefaults returns a copy of the config with unset fields set to their defaults.
+func (c StakeUpdatesConfig) withDefaults() (StakeUpdatesConfig, error) {
+	if c.Interval == 0 {
+		c.Interval = defaultStakeUpdatesInterval
+	}
+	if c.Interval < 0 || c.MaxCostGwei < 0 {
+		return StakeUpdatesConfig{}, errors.New("stake_updates interval and max_cost_gwei cannot be negative")
+	}
+	return c, nil
+}
+
+// ChallengerConfig configures when the challenger considers an onchain price incorrect and how it retries looking up
+// the signers of an incorrect price.
+type ChallengerConfig struct {
+	// maximum tolerated deviation between the onchain price and the historical price at the time of the response, in
+	// basis points
+	PriceToleranceBps uint64 `yaml:"price_tolerance_bps"`
+	// number of attempts to look up the signers of an incorrect price before giving up
+	MaxRetries    int           `yaml:"max_retries"`
+	RetryInterval time.Duration `yaml:"retry_interval"`
+	// address to serve prometheus metrics on; disabled when empty
+	MetricsIpPortAddr string `yaml:"metrics_ip_port_address"`
+}
+
+const (
+	defaultPriceToleranceBps      = 100
+	defaultChallengeMaxRetries    = 3
+	defaultChallengeRetryInterval = 10 * time.Second
+)
+
+// withDefaults returns a copy of the config with unset fields set to their defaults.
+func (c ChallengerConfig) withDefaults() (ChallengerConfig, error) {
+	if c.PriceToleranceBps == 0 {
+		c.PriceToleranceBps = defaultPriceToleranceBps
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultChallengeMaxRetries
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = defaultChallengeRetryInterval
+	}
+	if c.MaxRetries < 0 || c.RetryInterval < 0 {
+		return ChallengerConfig{}, errors.New("challenger max_retries and retry_interval cannot be negative")
+	}
+	return c, nil
+}
+
+// TaskGenerationMode selects who creates the tasks aggregated by the aggregator.
+type TaskGenerationMode string
+
+const (
+	AggregatorTaskGeneration TaskGenerationMode = "aggregator" // a task is created by the first response for it (default)
+	ExternalTaskGeneration   TaskGenerationMode = "external"   // tasks are only created by an external generator through the admin api
+)
+
+// NewTaskGenerationMode validates the raw task generation mode, defaulting to AggregatorTaskGeneration.
+func NewTaskGenerationMode(raw string) (TaskGenerationMode, error) {
+	switch mode := TaskGenerationMode(raw); mode {
+	case "":
+		return AggregatorTaskGeneration, nil
+	case AggregatorTaskGeneration, ExternalTaskGeneration:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown task_generation %q, expected %q or %q", raw, AggregatorTaskGeneration, ExternalTaskGeneration)
+	}
+}
+
+// TxType selects the transaction envelope used when sending transactions onchain.
+type TxType string
+
+const (
+	DynamicFeeTxType TxType = "dynamic" // EIP-1559 transactions (default)
+	LegacyTxType     TxType = "legacy"  // pre EIP-1559 transactions priced with a single gas price
+)
+
+// GasConfig contains the gas pricing strategy used by the aggregator when submitting aggregated responses.
+// A nil fee means "use whatever the rpc node suggests".
+type GasConfig struct {
+	TxType TxType
+	// caps the fee cap of dynamic fee txs, or the gas price of legacy txs
+	MaxFeePerGas *big.Int
+	// priority fee (tip) of dynamic fee txs; ignored for legacy txs
+	MaxPriorityFeePerGas *big.Int
+	// multiplier applied to the estimated gas limit
+	GasLimitMultiplier float64
+}
+
+// GasConfigRaw is the yaml representation of GasConfig. Fees are expressed in gwei.
+type GasConfigRaw struct {
+	TxType                   string  `yaml:"tx_type"`
+	MaxFeePerGasGwei         float64 `yaml:"max_fee_per_gas_gwei"`
+	MaxPriorityFeePerGasGwei float64 `yaml:"max_priority_fee_per_gas_gwei"`
+	GasLimitMultiplier       float64 `yaml:"gas_limit_multiplier"`
+}
+
+const defaultGasLimitMultiplier = 1.20
+
+// NewGasConfig converts the raw yaml gas config into a GasConfig, applying defaults for unset fields.
+func NewGasConfig(raw GasConfigRaw) (GasConfig, error) {
+	gas := GasConfig{
+		TxType:             TxType(raw.TxType),
+		GasLimitMultiplier: raw.GasLimitMultiplier,
+	}
+	switch gas.TxType {
+	case "":
+		gas.TxType = DynamicFeeTxType
+	case DynamicFeeTxType, LegacyTxType:
+	default:
+		return GasConfig{}, fmt.Errorf("unknown gas tx_type %q, expected %q or %q", raw.TxType, DynamicFeeTxType, LegacyTxType)
+	}
+	if raw.MaxFeePerGasGwei < 0 || raw.MaxPriorityFeePerGasGwei < 0 || raw.GasLimitMultiplier < 0 {
+		return GasConfig{}, errors.New("gas config values cannot be negative")
+	}
+	if gas.GasLimitMultiplier == 0 {
+		gas.GasLimitMultiplier = defaultGasLimitMultiplier
+	}
+	if raw.MaxFeePerGasGwei > 0 {
+		gas.MaxFeePerGas = gweiToWei(raw.MaxFeePerGasGwei)
+	}
+	if raw.MaxPriorityFeePerGasGwei > 0 {
+		gas.MaxPriorityFeePerGas = gweiToWei(raw.MaxPriorityFeePerGasGwei)
+	}
+	if gas.MaxFeePerGas != nil && gas.MaxPriorityFeePerGas != nil && gas.MaxPriorityFeePerGas.Cmp(gas.MaxFeePerGas) > 0 {
+		return GasConfig{}, errors.New("gas max_priority_fee_per_gas_gwei cannot exceed max_fee_per_gas_gwei")
+	}
+	return gas, nil
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return wei
 }
 
 // These are read from ConfigFileFlag
 type ConfigRaw struct {
-	Environment                sdklogging.LogLevel `yaml:"environment"`
-	EthRpcUrl                  string              `yaml:"eth_rpc_url"`
-	EthWsUrl                   string              `yaml:"eth_ws_url"`
-	AggregatorServerIpPortAddr string              `yaml:"aggregator_server_ip_port_address"`
-	RegisterOperatorOnStartup  bool                `yaml:"register_operator_on_startup"`
+	Version                     int                 `yaml:"version"`
+	Environment                 sdklogging.LogLevel `yaml:"environment"`
+	EthRpcUrl                   string              `yaml:"eth_rpc_url"`
+	EthWsUrl                    string              `yaml:"eth_ws_url"`
+	AggregatorServerIpPortAddr  string              `yaml:"aggregator_server_ip_port_address"`
+	RegisterOperatorOnStartup   bool                `yaml:"register_operator_on_startup"`
+	Gas                         GasConfigRaw        `yaml:"gas"`
+	TxMgr                       TxMgrConfig         `yaml:"tx_manager"`
+	Clock                       clock.Config        `yaml:"clock"`
+	SimulateAggregatedResponses bool                `yaml:"simulate_aggregated_responses"`
+	Batch                       BatchConfigRaw      `yaml:"batch"`
+	AdminApiIpPortAddr          string              `yaml:"admin_api_ip_port_address"`
+	Challenger                  ChallengerConfig    `yaml:"challenger"`
+	RateLimit                   RateLimitConfig     `yaml:"rate_limit"`
+	MetricsIpPortAddr           string              `yaml:"metrics_ip_port_address"`
+	TaskGeneration              string              `yaml:"task_generation"`
+	Shutdown                    ShutdownConfig      `yaml:"shutdown"`
+	Archive                     ArchiveConfig       `yaml:"archive"`
+	StakeUpdates                StakeUpdatesConfig  `yaml:"stake_updates"`
+	LogFile                     logging.FileConfig  `yaml:"log_file"`
+	HeartbeatTimeout            time.Duration       `yaml:"heartbeat_timeout"`
+	ChainId                     uint64              `yaml:"chain_id"`
+	BlockTime                   time.Duration       `yaml:"block_time"`
+	// signs the transactions with a remote signer instead of the ecdsa key of the flags; disabled when no url is set
+	RemoteSigner remotesigner.Config `yaml:"remote_signer"`
 }
 
 // These are read from BlocklessAVSDeploymentFileFlag
@@ -66,21 +438,49 @@ type BlocklessAVSContractsRaw struct {
 // Note: This config is shared by challenger and aggregator and so we put in the core.
 // Operator has a different config and is meant to be used by the operator CLI.
 func NewConfig(ctx *cli.Context) (*Config, error) {
+	return NewConfigFromFile(ctx, ctx.String(ConfigFileFlag.Name))
+}
 
+// NewConfigFromFile is like NewConfig but reads the config from the given file instead of the ConfigFileFlag,
+// for commands which load several config files.
+func NewConfigFromFile(ctx *cli.Context, configFilePath string) (*Config, error) {
 	var configRaw ConfigRaw
-	configFilePath := ctx.String(ConfigFileFlag.Name)
 	if configFilePath != "" {
-		sdkutils.ReadYamlConfig(configFilePath, &configRaw)
+		if err := ReadConfigFile(configFilePath, AggregatorEnvPrefix, &configRaw); err != nil {
+			return nil, err
+		}
+	}
+
+	network, err := NetworkFromFlag(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainIdSetting, blockTime, err := chainParams(configRaw.ChainId, configRaw.BlockTime, network)
+	if err != nil {
+		return nil, err
 	}
 
+	// the addresses of the deployment file take precedence over the network preset
 	var blocklessAVSDeploymentRaw BlocklessAVSDeploymentRaw
-	blocklessAVSDeploymentFilePath := ctx.String(BlocklessAVSDeploymentFileFlag.Name)
-	if _, err := os.Stat(blocklessAVSDeploymentFilePath); errors.Is(err, os.ErrNotExist) {
-		panic("Path " + blocklessAVSDeploymentFilePath + " does not exist")
+	if blocklessAVSDeploymentFilePath := ctx.String(BlocklessAVSDeploymentFileFlag.Name); blocklessAVSDeploymentFilePath != "" {
+		if _, err := os.Stat(blocklessAVSDeploymentFilePath); errors.Is(err, os.ErrNotExist) {
+			panic("Path " + blocklessAVSDeploymentFilePath + " does not exist")
+		}
+		sdkutils.ReadJsonConfig(blocklessAVSDeploymentFilePath, &blocklessAVSDeploymentRaw)
+	}
+	if network != nil {
+		if blocklessAVSDeploymentRaw.Addresses.RegistryCoordinatorAddr == "" {
+			blocklessAVSDeploymentRaw.Addresses.RegistryCoordinatorAddr = network.RegistryCoordinator
+		}
+		if blocklessAVSDeploymentRaw.Addresses.OperatorStateRetrieverAddr == "" {
+			blocklessAVSDeploymentRaw.Addresses.OperatorStateRetrieverAddr = network.OperatorStateRetriever
+		}
 	}
-	sdkutils.ReadJsonConfig(blocklessAVSDeploymentFilePath, &blocklessAVSDeploymentRaw)
 
-	logger := logging.NewZeroLogger(logging.LogLevel(configRaw.Environment))
+	logger, err := logging.NewZeroLogger(logging.LogLevel(configRaw.Environment)).WithFile(configRaw.LogFile)
+	if err != nil {
+		return nil, err
+	}
 
 	ethRpcClient, err := eth.NewClient(configRaw.EthRpcUrl)
 	if err != nil {
@@ -94,41 +494,95 @@ func NewConfig(ctx *cli.Context) (*Config, error) {
 		return nil, err
 	}
 
-	ecdsaPrivateKeyString := ctx.String(EcdsaPrivateKeyFlag.Name)
-	if ecdsaPrivateKeyString[:2] == "0x" {
-		ecdsaPrivateKeyString = ecdsaPrivateKeyString[2:]
-	}
-	ecdsaPrivateKey, err := crypto.HexToECDSA(ecdsaPrivateKeyString)
+	chainId, err := ethRpcClient.ChainID(context.Background())
 	if err != nil {
-		logger.Errorf("Cannot parse ecdsa private key", "err", err)
+		logger.Error("Cannot get chainId", "err", err)
+		return nil, err
+	}
+	if err := CheckChainId(chainIdSetting, chainId); err != nil {
 		return nil, err
 	}
 
-	aggregatorAddr, err := sdkutils.EcdsaPrivateKeyToAddress(ecdsaPrivateKey)
+	// transactions are signed either by a remote signer (web3signer or clef) or with the ecdsa key of the flags
+	var ecdsaPrivateKey *ecdsa.PrivateKey
+	var aggregatorAddr common.Address
+	var signerV2 signerv2.SignerFn
+	if configRaw.RemoteSigner.Enabled() {
+		remoteSigner, err := remotesigner.NewSigner(context.Background(), configRaw.RemoteSigner, chainId)
+		if err != nil {
+			logger.Error("Cannot create remote signer", "err", err)
+			return nil, err
+		}
+		logger.Infof("Signing transactions with remote signer at %s", configRaw.RemoteSigner.Url)
+		aggregatorAddr = remoteSigner.Address()
+		signerV2 = remoteSigner.SignerFn()
+	} else {
+		ecdsaPrivateKey, err = readEcdsaPrivateKey(ctx, logger)
+		if err != nil {
+			logger.Error("Cannot parse ecdsa private key", "err", err)
+			return nil, err
+		}
+		aggregatorAddr, err = sdkutils.EcdsaPrivateKeyToAddress(ecdsaPrivateKey)
+		if err != nil {
+			logger.Error("Cannot get operator address", "err", err)
+			return nil, err
+		}
+		signerV2, _, err = signerv2.SignerFromConfig(signerv2.Config{PrivateKey: ecdsaPrivateKey}, chainId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	gasConfig, err := NewGasConfig(configRaw.Gas)
 	if err != nil {
-		logger.Error("Cannot get operator address", "err", err)
 		return nil, err
 	}
-
-	chainId, err := ethRpcClient.ChainID(context.Background())
+	txMgrConfig, err := configRaw.TxMgr.withDefaults()
 	if err != nil {
-		logger.Error("Cannot get chainId", "err", err)
 		return nil, err
 	}
-
-	signerV2, _, err := signerv2.SignerFromConfig(signerv2.Config{PrivateKey: ecdsaPrivateKey}, chainId)
+	batchConfig, err := NewBatchConfig(configRaw.Batch)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitConfig, err := configRaw.RateLimit.withDefaults()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	skWallet, err := wallet.NewPrivateKeyWallet(ethRpcClient, signerV2, aggregatorAddr, logger)
+	shutdownConfig, err := configRaw.Shutdown.withDefaults()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if err := configRaw.Archive.validate(); err != nil {
+		return nil, err
+	}
+	stakeUpdatesConfig, err := configRaw.StakeUpdates.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	challengerConfig, err := configRaw.Challenger.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	adminApiToken := ctx.String(AdminApiTokenFlag.Name)
+	if configRaw.AdminApiIpPortAddr != "" && adminApiToken == "" {
+		return nil, errors.New("admin api requires an auth token to be set")
+	}
+	if configRaw.HeartbeatTimeout < 0 {
+		return nil, errors.New("heartbeat_timeout cannot be negative")
+	}
+	taskGeneration, err := NewTaskGenerationMode(configRaw.TaskGeneration)
+	if err != nil {
+		return nil, err
+	}
+	if taskGeneration == ExternalTaskGeneration && configRaw.AdminApiIpPortAddr == "" {
+		return nil, errors.New("external task_generation requires the admin api to be enabled")
 	}
-	txMgr := txmgr.NewSimpleTxManager(skWallet, ethRpcClient, logger, aggregatorAddr)
 
 	config := &Config{
 		EcdsaPrivateKey:                     ecdsaPrivateKey,
 		Logger:                              logger,
+		LogFile:                             configRaw.LogFile,
 		EthWsRpcUrl:                         configRaw.EthWsUrl,
 		EthHttpRpcUrl:                       configRaw.EthRpcUrl,
 		EthHttpClient:                       &ethRpcClient,
@@ -138,8 +592,24 @@ func NewConfig(ctx *cli.Context) (*Config, error) {
 		AggregatorServerIpPortAddr:          configRaw.AggregatorServerIpPortAddr,
 		RegisterOperatorOnStartup:           configRaw.RegisterOperatorOnStartup,
 		SignerFn:                            signerV2,
-		TxMgr:                               txMgr,
 		AggregatorAddress:                   aggregatorAddr,
+		Gas:                                 gasConfig,
+		TxMgr:                               txMgrConfig,
+		Clock:                               configRaw.Clock,
+		SimulateAggregatedResponses:         configRaw.SimulateAggregatedResponses,
+		Batch:                               batchConfig,
+		AdminApiIpPortAddr:                  configRaw.AdminApiIpPortAddr,
+		AdminApiToken:                       adminApiToken,
+		Challenger:                          challengerConfig,
+		RateLimit:                           rateLimitConfig,
+		MetricsIpPortAddr:                   configRaw.MetricsIpPortAddr,
+		TaskGeneration:                      taskGeneration,
+		Shutdown:                            shutdownConfig,
+		Archive:                             configRaw.Archive,
+		StakeUpdates:                        stakeUpdatesConfig,
+		HeartbeatTimeout:                    configRaw.HeartbeatTimeout,
+		ChainId:                             chainIdSetting,
+		BlockTime:                           blockTime,
 	}
 	config.validate()
 	return config, nil
@@ -160,7 +630,7 @@ var (
 	HeadlessFlag = &cli.BoolFlag{
 		Name:       "headless",
 		Required:   true,
-		Usage:      "Run blockless node in headless mode",
+		Usage:      "Run blockless node in headless mode; with --headless=false the web dashboard is served on the node api address and opened in the browser",
 		Value:      true,
 		HasBeenSet: true,
 	}
@@ -179,26 +649,61 @@ var (
 		HasBeenSet: true,
 	}
 	BlocklessAVSDeploymentFileFlag = &cli.StringFlag{
-		Name:     "blockless-avs-deployment",
-		Required: true,
-		Usage:    "Load blockless avs contract addresses from `FILE`",
+		Name:  "blockless-avs-deployment",
+		Usage: "Load blockless avs contract addresses from `FILE`, taking precedence over the ones of --network",
+	}
+	/* Optional Flags */
+	EcdsaKeystoreFlag = &cli.StringFlag{
+		Name:    "ecdsa-keystore",
+		Usage:   "Load the ethereum private key from the encrypted keystore `FILE`",
+		EnvVars: []string{"ECDSA_KEYSTORE"},
+	}
+	EcdsaKeystorePasswordFileFlag = &cli.StringFlag{
+		Name:    "ecdsa-keystore-password-file",
+		Usage:   "Read the ecdsa keystore password from `FILE` (default: the ECDSA_KEYSTORE_PASSWORD env var, or the terminal)",
+		EnvVars: []string{"ECDSA_KEYSTORE_PASSWORD_FILE"},
 	}
 	EcdsaPrivateKeyFlag = &cli.StringFlag{
-		Name:     "ecdsa-private-key",
-		Usage:    "Ethereum private key",
-		Required: true,
-		EnvVars:  []string{"ECDSA_PRIVATE_KEY"},
+		Name:    "ecdsa-private-key",
+		Usage:   "Ethereum private key in plaintext (deprecated, use --ecdsa-keystore)",
+		EnvVars: []string{"ECDSA_PRIVATE_KEY"},
+	}
+	AdminApiTokenFlag = &cli.StringFlag{
+		Name:    "admin-api-token",
+		Usage:   "Bearer token required to access the aggregator admin api",
+		EnvVars: []string{"ADMIN_API_TOKEN"},
 	}
-	/* Optional Flags */
 )
 
 var requiredFlags = []cli.Flag{
 	ConfigFileFlag,
+}
+
+var optionalFlags = []cli.Flag{
 	BlocklessAVSDeploymentFileFlag,
+	EcdsaKeystoreFlag,
+	EcdsaKeystorePasswordFileFlag,
 	EcdsaPrivateKeyFlag,
+	AdminApiTokenFlag,
 }
 
-var optionalFlags = []cli.Flag{}
+// readEcdsaPrivateKey decrypts the keystore set with EcdsaKeystoreFlag, falling back to the deprecated plaintext
+// EcdsaPrivateKeyFlag.
+func readEcdsaPrivateKey(ctx *cli.Context, logger sdklogging.Logger) (*ecdsa.PrivateKey, error) {
+	if path := ctx.String(EcdsaKeystoreFlag.Name); path != "" {
+		return keystore.ReadEcdsaKey(path, keystore.PasswordSource{
+			File:   ctx.String(EcdsaKeystorePasswordFileFlag.Name),
+			EnvVar: "ECDSA_KEYSTORE_PASSWORD",
+			Prompt: "Ecdsa keystore password: ",
+		}, logger)
+	}
+	privateKey := ctx.String(EcdsaPrivateKeyFlag.Name)
+	if privateKey == "" {
+		return nil, fmt.Errorf("an ecdsa keystore must be set with --%s", EcdsaKeystoreFlag.Name)
+	}
+	logger.Warn("Passing the ecdsa private key in plaintext is deprecated, use an encrypted keystore", "flag", EcdsaKeystoreFlag.Name)
+	return crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+}
 
 func init() {
 	Flags = append(requiredFlags, optionalFlags...)
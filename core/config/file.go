@@ -0,0 +1,143 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Prefixes of the environment variables overriding the config file settings, see ReadConfigFile.
+const (
+	OperatorEnvPrefix   = "AVS"
+	AggregatorEnvPrefix = "AVS_AGGREGATOR"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	yamlUnmarshalType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// ReadConfigFile reads the config file at path into cfg, a pointer to a struct with yaml tags, and overrides its
+// settings with the environment variables named after them. The file is toml when its extension is .toml, yaml
+// otherwise. The variable of a setting is the envPrefix followed by the upper-cased keys leading to it, joined by
+// underscores, eg. AVS_ETH_RPC_URL for eth_rpc_url, or AVS_GAS_MAX_FEE_GWEI for max_fee_gwei in the gas section; lists
+// are comma separated. The flags of the commands take precedence over both. Configs of another version than
+// ConfigVersion are refused.
+func ReadConfigFile(path, envPrefix string, cfg any) error {
+	return readConfigFile(path, envPrefix, cfg, false)
+}
+
+// ReadConfigFileStrict is like ReadConfigFile, but rejects the unknown keys of the file (usually typos, silently
+// ignored otherwise).
+func ReadConfigFileStrict(path, envPrefix string, cfg any) error {
+	return readConfigFile(path, envPrefix, cfg, true)
+}
+
+func readConfigFile(path, envPrefix string, cfg any, strict bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	isToml := strings.EqualFold(filepath.Ext(path), ".toml")
+	settings := map[string]any{}
+	if isToml {
+		err = toml.Unmarshal(content, &settings)
+	} else {
+		err = yaml.Unmarshal(content, &settings)
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if err := checkConfigVersion(path, settings); err != nil {
+		return err
+	}
+
+	// the settings are merged and decoded as yaml, so both formats decode like the yaml files always did; yaml files
+	// without overrides are decoded as is, to keep the line numbers in the errors
+	overridden := setEnvOverrides(settings, reflect.TypeOf(cfg).Elem(), envPrefix)
+	if isToml || overridden {
+		if content, err = yaml.Marshal(settings); err != nil {
+			return err
+		}
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(strict)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// setEnvOverrides sets the settings of t, a struct with yaml tags, which have an environment variable, and reports
+// whether any was set.
+func setEnvOverrides(settings map[string]any, t reflect.Type, envPrefix string) bool {
+	overridden := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || key == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if options == "inline" {
+			overridden = setEnvOverrides(settings, fieldType, envPrefix) || overridden
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+		envName := envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !reflect.PointerTo(fieldType).Implements(yamlUnmarshalType) {
+			section, ok := settings[key].(map[string]any)
+			if !ok {
+				section = map[string]any{}
+			}
+			if setEnvOverrides(section, fieldType, envName) {
+				settings[key] = section
+				overridden = true
+			}
+			continue
+		}
+		if value, ok := os.LookupEnv(envName); ok {
+			settings[key] = envValue(value, fieldType)
+			overridden = true
+		}
+	}
+	return overridden
+}
+
+// envValue converts the value of an environment variable to the setting of type t.
+func envValue(value string, t reflect.Type) any {
+	switch t.Kind() {
+	case reflect.String:
+		return value
+	case reflect.Slice:
+		values := []any{}
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, envValue(v, t.Elem()))
+			}
+		}
+		return values
+	default:
+		// numbers, booleans and durations are parsed like in the yaml file
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil || v == nil {
+			return value
+		}
+		return v
+	}
+}
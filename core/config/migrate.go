@@ -0,0 +1,213 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the schema version of the operator, aggregator and challenger configs, set in their version field.
+// Configs without a version field are version 0, written before the configs were versioned. Older configs are refused
+// by ReadConfigFile until they're upgraded with MigrateConfig (avs config migrate).
+const ConfigVersion = 1
+
+// ErrConfigNotMigrated is returned when reading a config of an older version.
+var ErrConfigNotMigrated = errors.New("config is not migrated to the current version")
+
+// configMigration upgrades a config from the previous version to version.
+type configMigration struct {
+	version     int
+	description string
+	// migrate changes the settings of the config, a yaml mapping node, in place
+	migrate func(settings *yaml.Node) error
+}
+
+// configMigrations upgrade the configs one version at a time, in order. The migrations apply to every kind of config,
+// so a migration renaming a setting only moves the keys the config has.
+var configMigrations = []configMigration{
+	{
+		version:     1,
+		description: "adds the version field, the settings are unchanged",
+		migrate:     func(*yaml.Node) error { return nil },
+	},
+}
+
+// ConfigMigrationResult describes the migrations applied by MigrateConfig.
+type ConfigMigrationResult struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// descriptions of the applied migrations, in order
+	Changes []string `json:"changes"`
+}
+
+// MigrateConfig upgrades the content of a yaml (or toml when isToml is set) config file to ConfigVersion, returning the
+// upgraded content. The comments of yaml files are kept, toml files are rewritten without theirs. Configs of the
+// current version are returned as is.
+func MigrateConfig(content []byte, isToml bool) ([]byte, ConfigMigrationResult, error) {
+	settings, err := parseConfigNode(content, isToml)
+	if err != nil {
+		return nil, ConfigMigrationResult{}, err
+	}
+	version, err := nodeConfigVersion(settings)
+	if err != nil {
+		return nil, ConfigMigrationResult{}, err
+	}
+	result := ConfigMigrationResult{From: version, To: version, Changes: []string{}}
+	if version > ConfigVersion {
+		return nil, result, fmt.Errorf("config version %d is newer than the version %d supported by this binary", version, ConfigVersion)
+	}
+	if version == ConfigVersion {
+		return content, result, nil
+	}
+
+	for _, migration := range configMigrations {
+		if migration.version <= version {
+			continue
+		}
+		if err := migration.migrate(settings); err != nil {
+			return nil, result, fmt.Errorf("could not migrate config to version %d: %w", migration.version, err)
+		}
+		result.To = migration.version
+		result.Changes = append(result.Changes, fmt.Sprintf("version %d: %s", migration.version, migration.description))
+	}
+	setNodeConfigVersion(settings, result.To)
+
+	if isToml {
+		var values map[string]any
+		if err := settings.Decode(&values); err != nil {
+			return nil, result, err
+		}
+		migrated, err := toml.Marshal(values)
+		return migrated, result, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{settings}}); err != nil {
+		return nil, result, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, result, err
+	}
+	return keepBlankLines(content, buf.Bytes()), result, nil
+}
+
+// keepBlankLines adds the blank lines of the original yaml file, which the yaml encoder drops, to the migrated one: a
+// blank line is added before the lines following one in the original file, matched in order.
+func keepBlankLines(original, migrated []byte) []byte {
+	originalLines := strings.Split(string(original), "\n")
+	var out []string
+	next := 0
+	for _, line := range strings.Split(string(migrated), "\n") {
+		for i := next; i < len(originalLines); i++ {
+			if strings.TrimRight(originalLines[i], " \t") != line {
+				continue
+			}
+			if i > 0 && strings.TrimSpace(originalLines[i-1]) == "" && line != "" && len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			next = i + 1
+			break
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// parseConfigNode parses a config file to the yaml mapping node of its settings.
+func parseConfigNode(content []byte, isToml bool) (*yaml.Node, error) {
+	var doc yaml.Node
+	if isToml {
+		values := map[string]any{}
+		if err := toml.Unmarshal(content, &values); err != nil {
+			return nil, err
+		}
+		if err := doc.Encode(values); err != nil {
+			return nil, err
+		}
+		return &doc, nil
+	}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		// empty file
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the config is not a mapping of settings")
+	}
+	settings := doc.Content[0]
+	// keeps the comments at the top of the file
+	settings.HeadComment = joinComments(doc.HeadComment, settings.HeadComment)
+	return settings, nil
+}
+
+// nodeConfigVersion returns the version field of the settings, 0 when unset.
+func nodeConfigVersion(settings *yaml.Node) (int, error) {
+	for i := 0; i+1 < len(settings.Content); i += 2 {
+		if settings.Content[i].Value != "version" {
+			continue
+		}
+		var version int
+		if err := settings.Content[i+1].Decode(&version); err != nil || version < 0 {
+			return 0, fmt.Errorf("invalid config version %q", settings.Content[i+1].Value)
+		}
+		return version, nil
+	}
+	return 0, nil
+}
+
+// setNodeConfigVersion sets the version field of the settings, adding it as the first setting when unset.
+func setNodeConfigVersion(settings *yaml.Node, version int) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(version)}
+	for i := 0; i+1 < len(settings.Content); i += 2 {
+		if settings.Content[i].Value == "version" {
+			settings.Content[i+1] = value
+			return
+		}
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version",
+		HeadComment: "# schema version of the config, upgraded by avs config migrate"}
+	settings.Content = append([]*yaml.Node{key, value}, settings.Content...)
+}
+
+func joinComments(comments ...string) string {
+	joined := ""
+	for _, comment := range comments {
+		if comment == "" {
+			continue
+		}
+		if joined != "" {
+			joined += "\n\n"
+		}
+		joined += comment
+	}
+	return joined
+}
+
+// checkConfigVersion returns ErrConfigNotMigrated when the settings of the config at path are of an older version.
+func checkConfigVersion(path string, settings map[string]any) error {
+	version := 0
+	switch v := settings["version"].(type) {
+	case nil:
+	case int:
+		version = v
+	case int64:
+		version = int(v)
+	default:
+		return fmt.Errorf("invalid config version %v in %s", v, path)
+	}
+	if version < ConfigVersion {
+		return fmt.Errorf("%w: %s is version %d, the current version is %d; upgrade it with `avs config migrate --config %s`",
+			ErrConfigNotMigrated, path, version, ConfigVersion, path)
+	}
+	if version > ConfigVersion {
+		return fmt.Errorf("%s is config version %d, newer than the version %d supported by this binary", path, version, ConfigVersion)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Network is a preset of the avs contract addresses and chain parameters of a known deployment, selected with
+// NetworkFlag so that only the rpc urls and keys need to be configured. The settings of the config files take
+// precedence over it; an empty address means the preset doesn't know the contract.
+type Network struct {
+	Name      string
+	ChainId   uint64
+	BlockTime time.Duration
+
+	RegistryCoordinator    string
+	OperatorStateRetriever string
+	TokenStrategy          string
+	ServiceManager         string
+}
+
+const defaultBlockTime = 12 * time.Second
+
+var (
+	// LocalNetwork is the anvil chain of the saved anvil state (see config-files/operator.anvil.yaml).
+	LocalNetwork = Network{
+		Name:                   "local",
+		ChainId:                31337,
+		BlockTime:              defaultBlockTime,
+		RegistryCoordinator:    "0xd9fEc8238711935D6c8d79Bef2B9546ef23FC046",
+		OperatorStateRetriever: "0xCBBe2A5c3A22BE749D5DDF24e9534f98951983e2",
+		TokenStrategy:          "0x80528D6e9A2BAbFc766965E0E26d5aB08D9CFaF9",
+		ServiceManager:         "0x95775fD3Afb1F4072794CA4ddA27F2444BCf8Ac3",
+	}
+	// HoleskyNetwork is the holesky testnet. The avs contracts are deployed by each operator set with
+	// `make blockless-holesky-deploy-avs` for now, so their addresses are left to the config.
+	HoleskyNetwork = Network{
+		Name:      "holesky",
+		ChainId:   17000,
+		BlockTime: defaultBlockTime,
+	}
+	// MainnetNetwork is ethereum mainnet, where the avs isn't deployed yet.
+	MainnetNetwork = Network{
+		Name:      "mainnet",
+		ChainId:   1,
+		BlockTime: defaultBlockTime,
+	}
+
+	Networks = []Network{LocalNetwork, HoleskyNetwork, MainnetNetwork}
+)
+
+var NetworkFlag = &cli.StringFlag{
+	Name:    "network",
+	Usage:   "preset of the contract addresses and chain parameters: " + strings.Join(networkNames(), ", ") + "; the config files take precedence",
+	EnvVars: []string{"AVS_NETWORK"},
+}
+
+func networkNames() []string {
+	names := make([]string, len(Networks))
+	for i, network := range Networks {
+		names[i] = network.Name
+	}
+	return names
+}
+
+// LookupNetwork returns the network preset named name, or nil when name is empty.
+func LookupNetwork(name string) (*Network, error) {
+	if name == "" {
+		return nil, nil
+	}
+	i := slices.IndexFunc(Networks, func(n Network) bool { return n.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("unknown network %q, expected one of %s", name, strings.Join(networkNames(), ", "))
+	}
+	network := Networks[i]
+	return &network, nil
+}
+
+// NetworkFromFlag returns the network preset selected with NetworkFlag, or nil when it isn't set.
+func NetworkFromFlag(ctx *cli.Context) (*Network, error) {
+	return LookupNetwork(ctx.String(NetworkFlag.Name))
+}
+
+// ResolveChainId returns the chain id of the network, or an error when the config sets another one (chainId, 0 when
+// unset).
+func (n *Network) ResolveChainId(chainId uint64) (uint64, error) {
+	if chainId != 0 && chainId != n.ChainId {
+		return 0, fmt.Errorf("chain_id %d does not match the chain %d of network %s", chainId, n.ChainId, n.Name)
+	}
+	return n.ChainId, nil
+}
+
+// CheckChainId returns an error when the rpc node is on another chain than expected, so that a config pointing at the
+// wrong network doesn't sign transactions for it. An expected chain id of 0 is not checked.
+func CheckChainId(expected uint64, actual *big.Int) error {
+	if expected == 0 || (actual.IsUint64() && actual.Uint64() == expected) {
+		return nil
+	}
+	return fmt.Errorf("the eth rpc node is on chain %s but the config expects chain %d, is it the right network?", actual, expected)
+}
+
+// chainParams returns the chain id and block time of the config, falling back to the ones of the network preset.
+func chainParams(chainId uint64, blockTime time.Duration, network *Network) (uint64, time.Duration, error) {
+	if blockTime < 0 {
+		return 0, 0, errors.New("block_time cannot be negative")
+	}
+	if network != nil {
+		var err error
+		if chainId, err = network.ResolveChainId(chainId); err != nil {
+			return 0, 0, err
+		}
+		if blockTime == 0 {
+			blockTime = network.BlockTime
+		}
+	}
+	if blockTime == 0 {
+		blockTime = defaultBlockTime
+	}
+	return chainId, blockTime, nil
+}
@@ -0,0 +1,91 @@
+// Package keystore reads the encrypted ecdsa (geth web3 secret storage) and bls (eigensdk, EIP-2335 style) keystores,
+// with their password read from a file, an env var or an interactive prompt.
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+// ErrNoPassword is returned when none of the sources of a password is set.
+var ErrNoPassword = errors.New("keystore password not set")
+
+// PasswordSource tells where the password of a keystore is read from. The sources are tried in order: the file, the env
+// var, then an interactive prompt when stdin is a terminal.
+type PasswordSource struct {
+	// file containing the password; trailing newlines are ignored
+	File string
+	// env var containing the password
+	EnvVar string
+	// shown when asking for the password on the terminal; the password isn't asked for when empty
+	Prompt string
+}
+
+// Password reads the password from the first source which is set.
+func (s PasswordSource) Password() (string, error) {
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("could not read keystore password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if s.EnvVar != "" {
+		if password, ok := os.LookupEnv(s.EnvVar); ok {
+			return password, nil
+		}
+	}
+	if s.Prompt != "" {
+		password, ok, err := readPassword(s.Prompt)
+		if err != nil {
+			return "", fmt.Errorf("could not read keystore password: %w", err)
+		}
+		if ok {
+			return password, nil
+		}
+	}
+	return "", ErrNoPassword
+}
+
+// ReadEcdsaKey decrypts the ecdsa keystore at path.
+func ReadEcdsaKey(path string, password PasswordSource, logger logging.Logger) (*ecdsa.PrivateKey, error) {
+	pass, err := passwordOrEmpty(password, logger)
+	if err != nil {
+		return nil, err
+	}
+	key, err := sdkecdsa.ReadKey(path, pass)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt ecdsa keystore %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ReadBlsKey decrypts the bls keystore at path.
+func ReadBlsKey(path string, password PasswordSource, logger logging.Logger) (*bls.KeyPair, error) {
+	pass, err := passwordOrEmpty(password, logger)
+	if err != nil {
+		return nil, err
+	}
+	keyPair, err := bls.ReadPrivateKeyFromFile(path, pass)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt bls keystore %s: %w", path, err)
+	}
+	return keyPair, nil
+}
+
+// passwordOrEmpty falls back to the empty password when none is set, which is what the local test keystores use.
+func passwordOrEmpty(password PasswordSource, logger logging.Logger) (string, error) {
+	pass, err := password.Password()
+	if errors.Is(err, ErrNoPassword) {
+		logger.Warn("Keystore password not set, using empty string", "envVar", password.EnvVar)
+		return "", nil
+	}
+	return pass, err
+}
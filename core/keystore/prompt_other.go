@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package keystore
+
+// readPassword never prompts; passwords must be given with a file or an env var.
+func readPassword(_ string) (string, bool, error) {
+	return "", false, nil
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin
+
+package keystore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// readPassword prompts for a password on the terminal without echoing it. It reports false when stdin isn't a terminal.
+func readPassword(prompt string) (string, bool, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return "", false, nil
+	}
+	noEcho := *state
+	noEcho.Lflag &^= unix.ECHO
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &noEcho); err != nil {
+		return "", true, err
+	}
+	defer unix.IoctlSetTermios(fd, ioctlSetTermios, state)
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", true, err
+	}
+	return strings.TrimRight(line, "\r\n"), true, nil
+}
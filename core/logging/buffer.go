@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// buffer of the recent log entries, set by EnableBuffer; nil when disabled
+var buffer *Buffer
+
+// EnableBuffer keeps the last size log entries of the loggers created from then on in a ring buffer, served by the
+// aggregator admin api (GET /logs), and returns it.
+func EnableBuffer(size int) *Buffer {
+	buffer = NewBuffer(size)
+	return buffer
+}
+
+// RecentLogs returns the buffer enabled with EnableBuffer, or nil when the recent log entries aren't kept.
+func RecentLogs() *Buffer {
+	return buffer
+}
+
+// Buffer is a ring buffer of the most recent log entries, in json whatever the log format, which the subscribers also
+// receive as they're written. Entries are dropped for subscribers which don't keep up, so logging never blocks.
+type Buffer struct {
+	mu          sync.Mutex
+	entries     []json.RawMessage
+	next        int
+	full        bool
+	subscribers map[chan json.RawMessage]struct{}
+}
+
+func NewBuffer(size int) *Buffer {
+	return &Buffer{
+		entries:     make([]json.RawMessage, size),
+		subscribers: make(map[chan json.RawMessage]struct{}),
+	}
+}
+
+// Write adds a log entry; zerolog writes each entry, a json object, with a single call.
+func (b *Buffer) Write(p []byte) (int, error) {
+	entry := json.RawMessage(bytes.TrimSpace(bytes.Clone(p)))
+	if len(entry) == 0 || len(b.entries) == 0 {
+		return len(p), nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+	if b.next == 0 {
+		b.full = true
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Recent returns up to limit of the most recent entries, oldest first; all of them when limit is 0.
+func (b *Buffer) Recent(limit int) []json.RawMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.recent(limit)
+}
+
+func (b *Buffer) recent(limit int) []json.RawMessage {
+	var entries []json.RawMessage
+	if b.full {
+		entries = append(entries, b.entries[b.next:]...)
+	}
+	entries = append(entries, b.entries[:b.next]...)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries
+}
+
+// Subscribe returns up to limit of the most recent entries, like Recent, and a channel receiving the entries written
+// after them, with room for bufferSize entries. It must be released with Unsubscribe.
+func (b *Buffer) Subscribe(limit, bufferSize int) ([]json.RawMessage, chan json.RawMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan json.RawMessage, bufferSize)
+	b.subscribers[ch] = struct{}{}
+	return b.recent(limit), ch
+}
+
+func (b *Buffer) Unsubscribe(ch chan json.RawMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subscribers, ch)
+}
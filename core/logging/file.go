@@ -0,0 +1,145 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxFileSizeMB = 100
+	// suffix of the rotated files, sortable by rotation time
+	rotatedFileTimeFormat = "20060102T150405.000"
+)
+
+// FileConfig configures writing the logs to a file as json, besides stderr. The file is rotated once it reaches the
+// max size or age; the rotated files are named after the file with the rotation time appended (eg. operator-20240501T120000.000.log).
+type FileConfig struct {
+	// path of the log file; logs are only written to stderr when empty
+	Path string `yaml:"path"`
+	// the file is rotated once larger than this, in megabytes (default 100)
+	MaxSizeMB int `yaml:"max_size_mb"`
+	// the file is rotated once it was written to for this long; only rotated by size when 0
+	RotateEvery time.Duration `yaml:"rotate_every"`
+	// rotated files older than this are deleted; kept regardless of age when 0
+	MaxAge time.Duration `yaml:"max_age"`
+	// number of rotated files kept; all are kept when 0
+	MaxBackups int `yaml:"max_backups"`
+}
+
+// withDefaults returns a copy of the config with unset fields set to their defaults.
+func (c FileConfig) withDefaults() (FileConfig, error) {
+	if c.MaxSizeMB == 0 {
+		c.MaxSizeMB = defaultMaxFileSizeMB
+	}
+	if c.MaxSizeMB < 0 || c.RotateEvery < 0 || c.MaxAge < 0 || c.MaxBackups < 0 {
+		return FileConfig{}, errors.New("log file max_size_mb, rotate_every, max_age and max_backups cannot be negative")
+	}
+	return c, nil
+}
+
+// rotatingFile is a log file rotated by size and age, pruning the rotated files past the retention.
+type rotatingFile struct {
+	cfg FileConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func openRotatingFile(cfg FileConfig) (*rotatingFile, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("could not create log directory: %w", err)
+	}
+	f := &rotatingFile{cfg: cfg}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	maxSize := int64(f.cfg.MaxSizeMB) << 20
+	expired := f.cfg.RotateEvery > 0 && time.Since(f.openedAt) >= f.cfg.RotateEvery
+	if f.size > 0 && (f.size+int64(len(p)) > maxSize || expired) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	ext := filepath.Ext(f.cfg.Path)
+	rotated := strings.TrimSuffix(f.cfg.Path, ext) + "-" + time.Now().UTC().Format(rotatedFileTimeFormat) + ext
+	if err := os.Rename(f.cfg.Path, rotated); err != nil {
+		return fmt.Errorf("could not rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune deletes the rotated files exceeding max_backups, oldest first, and the ones older than max_age.
+func (f *rotatingFile) prune() {
+	ext := filepath.Ext(f.cfg.Path)
+	prefix := strings.TrimSuffix(f.cfg.Path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+	var rotated []string
+	for _, path := range matches {
+		if _, err := time.Parse(rotatedFileTimeFormat, strings.TrimSuffix(strings.TrimPrefix(path, prefix), ext)); err == nil {
+			rotated = append(rotated, path)
+		}
+	}
+	// newest first
+	slices.Sort(rotated)
+	slices.Reverse(rotated)
+	for i, path := range rotated {
+		remove := f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups
+		if !remove && f.cfg.MaxAge > 0 {
+			info, err := os.Stat(path)
+			remove = err == nil && time.Since(info.ModTime()) > f.cfg.MaxAge
+		}
+		if remove {
+			os.Remove(path)
+		}
+	}
+}
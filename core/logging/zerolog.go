@@ -2,11 +2,14 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
 )
 
 type LogLevel string
@@ -16,23 +19,110 @@ const (
 	Production  LogLevel = "production"  // prints info and above
 )
 
+// LogFormat is the output format of the loggers.
+type LogFormat string
+
+const (
+	PrettyFormat LogFormat = "pretty" // human readable lines
+	JsonFormat   LogFormat = "json"   // a json object per line
+)
+
+var (
+	// format of the loggers, set by Configure
+	format = PrettyFormat
+	// level set by Configure, which takes precedence over the environment of the loggers; nil when unset
+	configuredLevel *zerolog.Level
+)
+
+// Configure sets the level and format of the loggers created from then on. An empty level keeps the level of the environment each logger is created with; a set
+// one also makes SetLevel a no-op, so that it isn't overridden by the production setting of the configs.
+func Configure(level string, logFormat LogFormat) error {
+	switch logFormat {
+	case PrettyFormat, JsonFormat:
+	default:
+		return fmt.Errorf("unknown log format %q, expected %s or %s", logFormat, PrettyFormat, JsonFormat)
+	}
+	format = logFormat
+	if level != "" {
+		parsed, err := zerolog.ParseLevel(level)
+		if err != nil || parsed == zerolog.NoLevel {
+			return fmt.Errorf("unknown log level %q, expected trace, debug, info, warn or error", level)
+		}
+		configuredLevel = &parsed
+		zerolog.SetGlobalLevel(parsed)
+	}
+	return nil
+}
+
+// Output returns a writer to stderr in the configured format, for the loggers not created with NewZeroLogger. The
+// entries are also kept in the buffer of EnableBuffer, if any.
+func Output() io.Writer {
+	var out io.Writer = os.Stderr
+	if format != JsonFormat {
+		out = zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
+	}
+	if buffer != nil {
+		return zerolog.MultiLevelWriter(out, buffer)
+	}
+	return out
+}
+
 type ZeroLogger struct {
 	logger *zerolog.Logger
+	// writer the logger outputs to
+	out io.Writer
 }
 
 var _ logging.Logger = (*ZeroLogger)(nil)
 
 func NewZeroLogger(env LogLevel) *ZeroLogger {
-	output := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339}
+	output := zerolog.MultiLevelWriter(Output(), reporting.Writer{})
+	var level zerolog.Level
 	if env == Production {
-		logger := zerolog.New(output).With().Timestamp().Logger().Level(zerolog.InfoLevel)
-		return &ZeroLogger{logger: &logger}
+		level = zerolog.InfoLevel
 	} else if env == Development {
-		logger := zerolog.New(output).With().Timestamp().Logger().Level(zerolog.DebugLevel)
-		return &ZeroLogger{logger: &logger}
+		level = zerolog.DebugLevel
 	} else {
 		panic(fmt.Sprintf("Unknown environment. Expected %s or %s. Received %s.", Development, Production, env))
 	}
+	if configuredLevel != nil {
+		level = *configuredLevel
+	}
+	logger := zerolog.New(output).With().Timestamp().Logger().Level(level)
+	return &ZeroLogger{logger: &logger, out: output}
+}
+
+// SetLevel changes the level of all the zerolog loggers of the process, including the ones of the p2p host. Loggers only
+// print the levels they were created with, so a development logger can be switched to production and back but not the
+// other way around.
+func SetLevel(env LogLevel) error {
+	if configuredLevel != nil {
+		return nil
+	}
+	switch env {
+	case Production:
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	case Development:
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	default:
+		return fmt.Errorf("unknown environment. Expected %s or %s. Received %s", Development, Production, env)
+	}
+	return nil
+}
+
+// WithFile returns a logger writing to the rotated log file besides the outputs of z, with the same level. z is
+// returned as is when no file is configured.
+func (z *ZeroLogger) WithFile(cfg FileConfig) (*ZeroLogger, error) {
+	if cfg.Path == "" {
+		return z, nil
+	}
+	file, err := openRotatingFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+	out := zerolog.MultiLevelWriter(z.out, file)
+	logger := z.logger.Output(out)
+	return &ZeroLogger{logger: &logger, out: out}, nil
 }
 
 // Inner gets the inner logger.
@@ -85,5 +175,6 @@ func (z *ZeroLogger) With(tags ...any) logging.Logger {
 	return &ZeroLogger{
 		// logger: z.logger.Sugar().With(tags...).Desugar(),
 		logger: z.logger,
+		out:    z.out,
 	}
 }
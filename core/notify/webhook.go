@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookConfig configures the delivery of notifications to a webhook.
+type WebhookConfig struct {
+	// url the notifications are POSTed to as json; notifications are disabled when empty
+	Url string `yaml:"url"`
+	// timeout of a single delivery
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Webhook delivers notifications by POSTing them as json to a webhook url.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(cfg WebhookConfig) *Webhook {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultWebhookTimeout
+	}
+	return &Webhook{
+		url:    cfg.Url,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Send POSTs the json encoding of msg to the webhook. Any non 2xx response is returned as an error.
+func (w *Webhook) Send(ctx context.Context, msg any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded with status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Package operatorevents watches the onchain events which take an operator out of the AVS or slash it: ejections,
+// churns, deregistrations and slasher freezes.
+package operatorevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	delegationmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	slasher "github.com/Layr-Labs/eigensdk-go/contracts/bindings/ISlasher"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+// Kind is the kind of an operator event.
+type Kind string
+
+const (
+	// the operator was deregistered by the registry coordinator ejector
+	Ejected Kind = "ejected"
+	// the operator was deregistered to make room for another operator registering with churn
+	Churned Kind = "churned"
+	// the operator deregistered itself
+	Deregistered Kind = "deregistered"
+	// the operator was frozen by the eigenlayer slasher
+	Frozen Kind = "frozen"
+	// the frozen status of the operator was reset
+	FrozenStatusReset Kind = "frozen_status_reset"
+)
+
+// Event is an onchain event concerning the watched operator.
+type Event struct {
+	Kind        Kind           `json:"kind"`
+	Operator    common.Address `json:"operator"`
+	BlockNumber uint64         `json:"blockNumber"`
+	TxHash      common.Hash    `json:"txHash"`
+	// sender of the transaction which emitted the event; unset when it couldn't be retrieved
+	Sender *common.Address `json:"sender,omitempty"`
+	// contract which froze the operator, for frozen events
+	SlashingContract *common.Address `json:"slashingContract,omitempty"`
+}
+
+// Watcher subscribes to the registry coordinator and slasher events of a single operator.
+type Watcher struct {
+	operator            common.Address
+	ethClient           eth.Client
+	registryCoordinator *regcoord.ContractRegistryCoordinator
+	slasher             *slasher.ContractISlasher
+	logger              logging.Logger
+}
+
+// NewWatcher creates a watcher of the operator events. The eth client must be a websocket client, since the watcher subscribes to contract events.
+func NewWatcher(operator, registryCoordinatorAddr, delegationManagerAddr common.Address, ethWsClient eth.Client, logger logging.Logger) (*Watcher, error) {
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinator(registryCoordinatorAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	delegationManager, err := delegationmanager.NewContractDelegationManager(delegationManagerAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	slasherAddr, err := delegationManager.Slasher(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get slasher address: %w", err)
+	}
+	slasherContract, err := slasher.NewContractISlasher(slasherAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		operator:            operator,
+		ethClient:           ethWsClient,
+		registryCoordinator: registryCoordinator,
+		slasher:             slasherContract,
+		logger:              logger,
+	}, nil
+}
+
+type events struct {
+	deregistered      chan *regcoord.ContractRegistryCoordinatorOperatorDeregistered
+	frozen            chan *slasher.ContractISlasherOperatorFrozen
+	frozenStatusReset chan *slasher.ContractISlasherFrozenStatusReset
+}
+
+func (w *Watcher) subscribe(events *events) (event.Subscription, error) {
+	opts := &bind.WatchOpts{}
+	operators := []common.Address{w.operator}
+	deregistered, err := w.registryCoordinator.WatchOperatorDeregistered(opts, events.deregistered, operators, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not subscribe to operator deregistrations: %w", err)
+	}
+	frozen, err := w.slasher.WatchOperatorFrozen(opts, events.frozen, operators, nil)
+	if err != nil {
+		deregistered.Unsubscribe()
+		return nil, fmt.Errorf("could not subscribe to operator freezes: %w", err)
+	}
+	frozenStatusReset, err := w.slasher.WatchFrozenStatusReset(opts, events.frozenStatusReset, operators)
+	if err != nil {
+		deregistered.Unsubscribe()
+		frozen.Unsubscribe()
+		return nil, fmt.Errorf("could not subscribe to operator frozen status resets: %w", err)
+	}
+	return event.JoinSubscriptions(deregistered, frozen, frozenStatusReset), nil
+}
+
+// Watch calls handler with every event of the operator until ctx is done. Subscription errors are retried by
+// resubscribing; Watch only returns an error when subscribing fails.
+func (w *Watcher) Watch(ctx context.Context, handler func(Event)) error {
+	events := &events{
+		deregistered:      make(chan *regcoord.ContractRegistryCoordinatorOperatorDeregistered),
+		frozen:            make(chan *slasher.ContractISlasherOperatorFrozen),
+		frozenStatusReset: make(chan *slasher.ContractISlasherFrozenStatusReset),
+	}
+	sub, err := w.subscribe(events)
+	if err != nil {
+		return err
+	}
+	defer func() { sub.Unsubscribe() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-sub.Err():
+			w.logger.Error("Error in websocket subscription for operator events", "err", err)
+			sub.Unsubscribe()
+			if sub, err = w.subscribe(events); err != nil {
+				return err
+			}
+		case e := <-events.deregistered:
+			handler(w.deregistration(ctx, e))
+		case e := <-events.frozen:
+			handler(Event{
+				Kind:             Frozen,
+				Operator:         e.SlashedOperator,
+				BlockNumber:      e.Raw.BlockNumber,
+				TxHash:           e.Raw.TxHash,
+				Sender:           w.sender(ctx, e.Raw),
+				SlashingContract: &e.SlashingContract,
+			})
+		case e := <-events.frozenStatusReset:
+			handler(Event{
+				Kind:        FrozenStatusReset,
+				Operator:    e.PreviouslySlashedAddress,
+				BlockNumber: e.Raw.BlockNumber,
+				TxHash:      e.Raw.TxHash,
+				Sender:      w.sender(ctx, e.Raw),
+			})
+		}
+	}
+}
+
+// deregistration tells ejections, churns and voluntary deregistrations apart by the sender of the transaction.
+func (w *Watcher) deregistration(ctx context.Context, e *regcoord.ContractRegistryCoordinatorOperatorDeregistered) Event {
+	ev := Event{
+		Kind:        Deregistered,
+		Operator:    e.Operator,
+		BlockNumber: e.Raw.BlockNumber,
+		TxHash:      e.Raw.TxHash,
+		Sender:      w.sender(ctx, e.Raw),
+	}
+	if ev.Sender == nil || *ev.Sender == w.operator {
+		return ev
+	}
+	ejector, err := w.registryCoordinator.Ejector(&bind.CallOpts{Context: ctx, BlockNumber: nil})
+	if err != nil {
+		w.logger.Error("Failed to get registry coordinator ejector", "err", err)
+	}
+	if err == nil && *ev.Sender == ejector {
+		ev.Kind = Ejected
+	} else {
+		// deregistered by a transaction the operator didn't send, ie. another operator registering with churn
+		ev.Kind = Churned
+	}
+	return ev
+}
+
+func (w *Watcher) sender(ctx context.Context, log types.Log) *common.Address {
+	tx, _, err := w.ethClient.TransactionByHash(ctx, log.TxHash)
+	if err != nil {
+		w.logger.Error("Failed to get transaction of operator event", "txHash", log.TxHash, "err", err)
+		return nil
+	}
+	sender, err := w.ethClient.TransactionSender(ctx, tx, log.BlockHash, log.TxIndex)
+	if err != nil {
+		w.logger.Error("Failed to get sender of operator event transaction", "txHash", log.TxHash, "err", err)
+		return nil
+	}
+	return &sender
+}
@@ -0,0 +1,340 @@
+// Package quorum watches the onchain parameters of the AVS quorums.
+package quorum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	indexreg "github.com/Layr-Labs/eigensdk-go/contracts/bindings/IndexRegistry"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	stakereg "github.com/Layr-Labs/eigensdk-go/contracts/bindings/StakeRegistry"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+// StrategyParams is a strategy counted towards the stake of a quorum, with its multiplier.
+type StrategyParams struct {
+	Strategy   common.Address `json:"strategy"`
+	Multiplier *big.Int       `json:"multiplier"`
+}
+
+// Params are the onchain parameters of a quorum.
+type Params struct {
+	QuorumNumber uint8 `json:"quorumNumber"`
+	// operators weighing less than this can't register and get ejected when their stake is updated
+	MinimumStake            *big.Int         `json:"minimumStake"`
+	Strategies              []StrategyParams `json:"strategies"`
+	MaxOperatorCount        uint32           `json:"maxOperatorCount"`
+	KickBIPsOfOperatorStake uint16           `json:"kickBIPsOfOperatorStake"`
+	KickBIPsOfTotalStake    uint16           `json:"kickBIPsOfTotalStake"`
+}
+
+// Change is a single quorum parameter which changed onchain.
+type Change struct {
+	QuorumNumber uint8  `json:"quorumNumber"`
+	Param        string `json:"param"`
+	Old          string `json:"old"`
+	New          string `json:"new"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("quorum %d %s: %s -> %s", c.QuorumNumber, c.Param, c.Old, c.New)
+}
+
+// Diff lists the parameters which differ between two snapshots of the same quorums.
+func Diff(old, new map[uint8]Params) []Change {
+	var changes []Change
+	for quorumNumber, n := range new {
+		o, ok := old[quorumNumber]
+		if !ok {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "quorum", Old: "", New: "created"})
+			continue
+		}
+		if o.MinimumStake.Cmp(n.MinimumStake) != 0 {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "minimumStake", Old: o.MinimumStake.String(), New: n.MinimumStake.String()})
+		}
+		if o.MaxOperatorCount != n.MaxOperatorCount {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "maxOperatorCount", Old: fmt.Sprint(o.MaxOperatorCount), New: fmt.Sprint(n.MaxOperatorCount)})
+		}
+		if o.KickBIPsOfOperatorStake != n.KickBIPsOfOperatorStake {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "kickBIPsOfOperatorStake", Old: fmt.Sprint(o.KickBIPsOfOperatorStake), New: fmt.Sprint(n.KickBIPsOfOperatorStake)})
+		}
+		if o.KickBIPsOfTotalStake != n.KickBIPsOfTotalStake {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "kickBIPsOfTotalStake", Old: fmt.Sprint(o.KickBIPsOfTotalStake), New: fmt.Sprint(n.KickBIPsOfTotalStake)})
+		}
+		oldStrategies := make(map[common.Address]*big.Int, len(o.Strategies))
+		for _, s := range o.Strategies {
+			oldStrategies[s.Strategy] = s.Multiplier
+		}
+		for _, s := range n.Strategies {
+			multiplier, ok := oldStrategies[s.Strategy]
+			delete(oldStrategies, s.Strategy)
+			switch {
+			case !ok:
+				changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "strategy " + s.Strategy.Hex(), Old: "", New: s.Multiplier.String()})
+			case multiplier.Cmp(s.Multiplier) != 0:
+				changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "strategy " + s.Strategy.Hex(), Old: multiplier.String(), New: s.Multiplier.String()})
+			}
+		}
+		for strategy, multiplier := range oldStrategies {
+			changes = append(changes, Change{QuorumNumber: quorumNumber, Param: "strategy " + strategy.Hex(), Old: multiplier.String(), New: "removed"})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		if a.QuorumNumber != b.QuorumNumber {
+			return int(a.QuorumNumber) - int(b.QuorumNumber)
+		}
+		if a.Param < b.Param {
+			return -1
+		}
+		if a.Param > b.Param {
+			return 1
+		}
+		return 0
+	})
+	return changes
+}
+
+// Watcher keeps a snapshot of the quorum parameters, reloading it whenever the registry contracts emit a parameter
+// change, and notifies the registered handlers of the parameters which changed.
+type Watcher struct {
+	quorumNumbers       []uint8
+	registryCoordinator *regcoord.ContractRegistryCoordinator
+	stakeRegistry       *stakereg.ContractStakeRegistry
+	indexRegistry       *indexreg.ContractIndexRegistry
+	logger              logging.Logger
+
+	mu       sync.RWMutex
+	params   map[uint8]Params
+	handlers []func(params map[uint8]Params, changes []Change)
+}
+
+// NewWatcher creates a watcher of the given quorums. The eth client must be a websocket client, since the watcher subscribes to contract events.
+func NewWatcher(registryCoordinatorAddr common.Address, ethWsClient eth.Client, quorumNumbers []uint8, logger logging.Logger) (*Watcher, error) {
+	registryCoordinator, err := regcoord.NewContractRegistryCoordinator(registryCoordinatorAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	stakeRegistryAddr, err := registryCoordinator.StakeRegistry(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get stake registry address: %w", err)
+	}
+	stakeRegistry, err := stakereg.NewContractStakeRegistry(stakeRegistryAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	indexRegistryAddr, err := registryCoordinator.IndexRegistry(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get index registry address: %w", err)
+	}
+	indexRegistry, err := indexreg.NewContractIndexRegistry(indexRegistryAddr, ethWsClient)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		quorumNumbers:       quorumNumbers,
+		registryCoordinator: registryCoordinator,
+		stakeRegistry:       stakeRegistry,
+		indexRegistry:       indexRegistry,
+		logger:              logger,
+	}, nil
+}
+
+// OnChange registers a handler called with the new parameters every time some of them changed. It must be called before Start.
+func (w *Watcher) OnChange(handler func(params map[uint8]Params, changes []Change)) {
+	w.handlers = append(w.handlers, handler)
+}
+
+// Params returns the last loaded parameters, nil until they were loaded.
+func (w *Watcher) Params() map[uint8]Params {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.params
+}
+
+// Start loads the parameters once and then reloads them on every parameter change event until ctx is done.
+func (w *Watcher) Start(ctx context.Context) error {
+	params, err := w.Load(ctx)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.params = params
+	w.mu.Unlock()
+	go w.watch(ctx)
+	return nil
+}
+
+// OperatorWeight returns the current weight of the operator in the quorum, to be compared against its minimum stake.
+func (w *Watcher) OperatorWeight(ctx context.Context, quorumNumber uint8, operator common.Address) (*big.Int, error) {
+	return w.stakeRegistry.WeightOfOperatorForQuorum(&bind.CallOpts{Context: ctx}, quorumNumber, operator)
+}
+
+// OperatorCount returns the number of operators currently registered in the quorum. Once it reaches the max operator
+// count, operators can only register by churning out another one.
+func (w *Watcher) OperatorCount(ctx context.Context, quorumNumber uint8) (uint32, error) {
+	return w.indexRegistry.TotalOperatorsForQuorum(&bind.CallOpts{Context: ctx}, quorumNumber)
+}
+
+// OperatorQuorums returns the quorums the operator is currently registered in.
+func (w *Watcher) OperatorQuorums(ctx context.Context, operatorId [32]byte) ([]uint8, error) {
+	bitmap, err := w.registryCoordinator.GetCurrentQuorumBitmap(&bind.CallOpts{Context: ctx}, operatorId)
+	if err != nil {
+		return nil, err
+	}
+	var quorums []uint8
+	for i := 0; i < bitmap.BitLen(); i++ {
+		if bitmap.Bit(i) == 1 {
+			quorums = append(quorums, uint8(i))
+		}
+	}
+	return quorums, nil
+}
+
+// Load reads the current parameters of the watched quorums.
+func (w *Watcher) Load(ctx context.Context) (map[uint8]Params, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	params := make(map[uint8]Params, len(w.quorumNumbers))
+	for _, quorumNumber := range w.quorumNumbers {
+		minimumStake, err := w.stakeRegistry.MinimumStakeForQuorum(opts, quorumNumber)
+		if err != nil {
+			return nil, fmt.Errorf("could not get minimum stake of quorum %d: %w", quorumNumber, err)
+		}
+		numStrategies, err := w.stakeRegistry.StrategyParamsLength(opts, quorumNumber)
+		if err != nil {
+			return nil, fmt.Errorf("could not get strategies of quorum %d: %w", quorumNumber, err)
+		}
+		strategies := make([]StrategyParams, 0, numStrategies.Int64())
+		for i := int64(0); i < numStrategies.Int64(); i++ {
+			strategy, err := w.stakeRegistry.StrategyParamsByIndex(opts, quorumNumber, big.NewInt(i))
+			if err != nil {
+				return nil, fmt.Errorf("could not get strategy %d of quorum %d: %w", i, quorumNumber, err)
+			}
+			strategies = append(strategies, StrategyParams{Strategy: strategy.Strategy, Multiplier: strategy.Multiplier})
+		}
+		operatorSetParams, err := w.registryCoordinator.GetOperatorSetParams(opts, quorumNumber)
+		if err != nil {
+			return nil, fmt.Errorf("could not get operator set params of quorum %d: %w", quorumNumber, err)
+		}
+		params[quorumNumber] = Params{
+			QuorumNumber:            quorumNumber,
+			MinimumStake:            minimumStake,
+			Strategies:              strategies,
+			MaxOperatorCount:        operatorSetParams.MaxOperatorCount,
+			KickBIPsOfOperatorStake: operatorSetParams.KickBIPsOfOperatorStake,
+			KickBIPsOfTotalStake:    operatorSetParams.KickBIPsOfTotalStake,
+		}
+	}
+	return params, nil
+}
+
+// paramEvents receives the events of all the contract parameter changes; each of them only triggers a reload.
+type paramEvents struct {
+	minimumStake       chan *stakereg.ContractStakeRegistryMinimumStakeForQuorumUpdated
+	strategyAdded      chan *stakereg.ContractStakeRegistryStrategyAddedToQuorum
+	strategyRemoved    chan *stakereg.ContractStakeRegistryStrategyRemovedFromQuorum
+	strategyMultiplier chan *stakereg.ContractStakeRegistryStrategyMultiplierUpdated
+	operatorSetParams  chan *regcoord.ContractRegistryCoordinatorOperatorSetParamsUpdated
+}
+
+func (w *Watcher) subscribe(events *paramEvents) (event.Subscription, error) {
+	opts := &bind.WatchOpts{}
+	var subs []event.Subscription
+	fail := func(err error) (event.Subscription, error) {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+		return nil, fmt.Errorf("could not subscribe to quorum parameter changes: %w", err)
+	}
+	sub, err := w.stakeRegistry.WatchMinimumStakeForQuorumUpdated(opts, events.minimumStake, w.quorumNumbers)
+	if err != nil {
+		return fail(err)
+	}
+	subs = append(subs, sub)
+	if sub, err = w.stakeRegistry.WatchStrategyAddedToQuorum(opts, events.strategyAdded, w.quorumNumbers); err != nil {
+		return fail(err)
+	}
+	subs = append(subs, sub)
+	if sub, err = w.stakeRegistry.WatchStrategyRemovedFromQuorum(opts, events.strategyRemoved, w.quorumNumbers); err != nil {
+		return fail(err)
+	}
+	subs = append(subs, sub)
+	if sub, err = w.stakeRegistry.WatchStrategyMultiplierUpdated(opts, events.strategyMultiplier, w.quorumNumbers); err != nil {
+		return fail(err)
+	}
+	subs = append(subs, sub)
+	if sub, err = w.registryCoordinator.WatchOperatorSetParamsUpdated(opts, events.operatorSetParams, w.quorumNumbers); err != nil {
+		return fail(err)
+	}
+	subs = append(subs, sub)
+	return event.JoinSubscriptions(subs...), nil
+}
+
+func (w *Watcher) watch(ctx context.Context) {
+	events := &paramEvents{
+		minimumStake:       make(chan *stakereg.ContractStakeRegistryMinimumStakeForQuorumUpdated),
+		strategyAdded:      make(chan *stakereg.ContractStakeRegistryStrategyAddedToQuorum),
+		strategyRemoved:    make(chan *stakereg.ContractStakeRegistryStrategyRemovedFromQuorum),
+		strategyMultiplier: make(chan *stakereg.ContractStakeRegistryStrategyMultiplierUpdated),
+		operatorSetParams:  make(chan *regcoord.ContractRegistryCoordinatorOperatorSetParamsUpdated),
+	}
+	sub, err := w.subscribe(events)
+	if err != nil {
+		w.logger.Error("Failed to watch quorum parameters", "err", err)
+		return
+	}
+	defer func() { sub.Unsubscribe() }()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-sub.Err():
+			w.logger.Error("Error in websocket subscription for quorum parameter changes", "err", err)
+			sub.Unsubscribe()
+			if sub, err = w.subscribe(events); err != nil {
+				w.logger.Error("Failed to watch quorum parameters", "err", err)
+				return
+			}
+			// changes may have been missed while resubscribing
+			w.reload(ctx)
+		case <-events.minimumStake:
+			w.reload(ctx)
+		case <-events.strategyAdded:
+			w.reload(ctx)
+		case <-events.strategyRemoved:
+			w.reload(ctx)
+		case <-events.strategyMultiplier:
+			w.reload(ctx)
+		case <-events.operatorSetParams:
+			w.reload(ctx)
+		}
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context) {
+	params, err := w.Load(ctx)
+	if err != nil {
+		w.logger.Error("Failed to reload quorum parameters", "err", err)
+		return
+	}
+	w.mu.Lock()
+	changes := Diff(w.params, params)
+	w.params = params
+	w.mu.Unlock()
+	if len(changes) == 0 {
+		return
+	}
+	for _, change := range changes {
+		w.logger.Warn("Quorum parameter changed onchain", "quorum", change.QuorumNumber, "param", change.Param, "old", change.Old, "new", change.New)
+	}
+	for _, handler := range w.handlers {
+		handler(params, changes)
+	}
+}
@@ -0,0 +1,210 @@
+// Package remotesigner signs transactions with an ecdsa key held by a remote signer, so the key never needs to be on disk.
+// Both Web3Signer (eth1 mode) and Clef are supported through their json-rpc apis.
+package remotesigner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Api is the json-rpc api spoken by the remote signer.
+type Api string
+
+const (
+	Web3SignerApi Api = "web3signer"
+	ClefApi       Api = "clef"
+)
+
+// Config configures a remote signer. The remote signer is disabled when the url is empty.
+type Config struct {
+	// json-rpc url of the signer, eg. http://localhost:9000 for web3signer or http://localhost:8550 for clef
+	Url string `yaml:"url"`
+	// web3signer or clef (default web3signer)
+	Api Api `yaml:"api"`
+	// address of the key the transactions are signed with; it must be served by the signer
+	Address string `yaml:"address"`
+	// timeout of a single signing request (default 10s); clef requests may need longer when they're confirmed manually
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (c Config) Enabled() bool {
+	return c.Url != ""
+}
+
+// signTxArgs are the transaction arguments of eth_signTransaction (web3signer) and account_signTransaction (clef).
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainId              *hexutil.Big    `json:"chainId"`
+}
+
+// clefSignTxResult is the result of clef's account_signTransaction.
+type clefSignTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+// Signer signs transactions with a key held by a remote signer.
+type Signer struct {
+	client  *rpc.Client
+	api     Api
+	address common.Address
+	chainId *big.Int
+	timeout time.Duration
+}
+
+// NewSigner connects to the remote signer and checks that it serves the configured address.
+func NewSigner(ctx context.Context, cfg Config, chainId *big.Int) (*Signer, error) {
+	if cfg.Api == "" {
+		cfg.Api = Web3SignerApi
+	}
+	if cfg.Api != Web3SignerApi && cfg.Api != ClefApi {
+		return nil, fmt.Errorf("invalid remote signer api %q, must be web3signer or clef", cfg.Api)
+	}
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid remote signer address %q", cfg.Address)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	client, err := rpc.DialContext(ctx, cfg.Url)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to remote signer: %w", err)
+	}
+	s := &Signer{
+		client:  client,
+		api:     cfg.Api,
+		address: common.HexToAddress(cfg.Address),
+		chainId: chainId,
+		timeout: cfg.Timeout,
+	}
+	accounts, err := s.accounts(ctx)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not list remote signer accounts: %w", err)
+	}
+	if !slices.Contains(accounts, s.address) {
+		client.Close()
+		return nil, fmt.Errorf("remote signer doesn't serve address %s", s.address.Hex())
+	}
+	return s, nil
+}
+
+func (s *Signer) accounts(ctx context.Context) ([]common.Address, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	method := "eth_accounts"
+	if s.api == ClefApi {
+		method = "account_list"
+	}
+	var accounts []common.Address
+	err := s.client.CallContext(ctx, &accounts, method)
+	return accounts, err
+}
+
+// Address returns the address the transactions are signed with.
+func (s *Signer) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs the transaction with the remote key. The signed transaction returned by the signer is checked
+// against the requested one, so a misbehaving signer can't substitute another transaction.
+func (s *Signer) SignTx(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainId: (*hexutil.Big)(s.chainId),
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("remote signer doesn't support transaction type %d", tx.Type())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	var raw hexutil.Bytes
+	if s.api == ClefApi {
+		var res clefSignTxResult
+		if err := s.client.CallContext(ctx, &res, "account_signTransaction", args); err != nil {
+			return nil, fmt.Errorf("remote signer failed to sign transaction: %w", err)
+		}
+		raw = res.Raw
+	} else if err := s.client.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer failed to sign transaction: %w", err)
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("could not decode transaction signed by remote signer: %w", err)
+	}
+	if err := s.checkSigned(tx, signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *Signer) checkSigned(tx, signed *types.Transaction) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(s.chainId), signed)
+	if err != nil {
+		return fmt.Errorf("could not recover sender of transaction signed by remote signer: %w", err)
+	}
+	if sender != s.address {
+		return fmt.Errorf("transaction signed by remote signer with %s instead of %s", sender.Hex(), s.address.Hex())
+	}
+	sameTo := (tx.To() == nil && signed.To() == nil) || (tx.To() != nil && signed.To() != nil && *tx.To() == *signed.To())
+	if signed.Type() != tx.Type() || signed.Nonce() != tx.Nonce() || signed.Gas() != tx.Gas() || !sameTo ||
+		signed.Value().Cmp(tx.Value()) != 0 || signed.GasFeeCap().Cmp(tx.GasFeeCap()) != 0 ||
+		signed.GasTipCap().Cmp(tx.GasTipCap()) != 0 || !slices.Equal(signed.Data(), tx.Data()) {
+		return errors.New("transaction signed by remote signer differs from the requested transaction")
+	}
+	return nil
+}
+
+// SignerFn returns a signerv2.SignerFn signing with the remote key, to be used by the transaction managers.
+func (s *Signer) SignerFn() signerv2.SignerFn {
+	return func(ctx context.Context, address common.Address) (bind.SignerFn, error) {
+		if address != s.address {
+			return nil, fmt.Errorf("remote signer can only sign for %s, not %s", s.address.Hex(), address.Hex())
+		}
+		return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if from != s.address {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(ctx, tx)
+		}, nil
+	}
+}
+
+// Close closes the connection to the remote signer.
+func (s *Signer) Close() {
+	s.client.Close()
+}
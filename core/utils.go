@@ -81,3 +81,13 @@ func ConvertToBN254G2Point(input *bls.G2Point) csavs.BN254G2Point {
 	}
 	return output
 }
+
+// ConvertFromBN254G1Point is the inverse of ConvertToBN254G1Point
+func ConvertFromBN254G1Point(input csavs.BN254G1Point) *bls.G1Point {
+	return bls.NewG1Point(input.X, input.Y)
+}
+
+// ConvertFromBN254G2Point is the inverse of ConvertToBN254G2Point
+func ConvertFromBN254G2Point(input csavs.BN254G2Point) *bls.G2Point {
+	return bls.NewG2Point(input.X, input.Y)
+}
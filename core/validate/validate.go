@@ -0,0 +1,201 @@
+// Package validate decodes and validates json api request bodies.
+//
+// Struct fields are validated with a comma separated `validate` tag:
+//
+//	required   the field must not be its zero value
+//	min=N      minimum string length, slice length or numeric value
+//	max=N      maximum string length, slice length or numeric value
+//	oneof=a|b  the string value must be one of the listed values
+//	slug       the string value may only contain lowercase letters, digits and dashes
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// request bodies larger than this are rejected
+const maxBodySize = 1 << 20
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// FieldError describes why a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error lists every invalid field of a request.
+type Error struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		msgs[i] = field.Field + ": " + field.Message
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// FieldErr returns an Error for a single invalid field, eg. a path parameter.
+func FieldErr(field, message string) *Error {
+	return &Error{Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// DecodeJSON strictly decodes the json request body into v, which must be a pointer to a struct, and validates it.
+// Unknown fields, trailing data, bodies larger than 1MiB and malformed values are reported as an *Error.
+func DecodeJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	// More doesn't report a trailing closing bracket, so the rest of the body must be empty
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeError(err)
+		}
+		return FieldErr("body", "must contain a single json object")
+	}
+	return Struct(v)
+}
+
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return FieldErr("body", fmt.Sprintf("must be at most %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, io.EOF):
+		return FieldErr("body", "must not be empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return FieldErr("body", "malformed json, unexpected end of body")
+	case errors.As(err, &syntaxErr):
+		return FieldErr("body", fmt.Sprintf("malformed json at offset %d", syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		return FieldErr(typeErr.Field, fmt.Sprintf("must be a %s", typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return FieldErr(strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`), "unknown field")
+	default:
+		return FieldErr("body", err.Error())
+	}
+}
+
+// Struct validates the fields of the struct pointed to by v according to their validate tags.
+func Struct(v any) error {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected a struct, got %s", val.Kind())
+	}
+	var verr Error
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		for _, rule := range strings.Split(tag, ",") {
+			if msg := check(val.Field(i), rule); msg != "" {
+				verr.Fields = append(verr.Fields, FieldError{Field: name, Message: msg})
+				// report the first broken rule of each field only
+				break
+			}
+		}
+	}
+	if len(verr.Fields) > 0 {
+		return &verr
+	}
+	return nil
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// check returns why the value breaks the rule, or an empty string if it doesn't.
+func check(value reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if value.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s rule %q", name, rule))
+		}
+		size, unit := measure(value)
+		if name == "min" && size < limit {
+			return fmt.Sprintf("must be at least %s%s", arg, unit)
+		}
+		if name == "max" && size > limit {
+			return fmt.Sprintf("must be at most %s%s", arg, unit)
+		}
+	case "oneof":
+		options := strings.Split(arg, "|")
+		for _, option := range options {
+			if value.String() == option {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	case "slug":
+		if value.String() != "" && !slugRegex.MatchString(value.String()) {
+			return "may only contain lowercase letters, digits and dashes"
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+	return ""
+}
+
+// measure returns the length of strings and slices, or the value of numbers.
+func measure(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(len(value.String())), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	default:
+		panic(fmt.Sprintf("validate: cannot measure a %s", value.Kind()))
+	}
+}
+
+// WriteError writes err as a json 400 response listing the invalid fields, or as a 500 if it isn't an *Error.
+func WriteError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	var verr *Error
+	if !errors.As(err, &verr) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}{Error: "invalid request", Fields: verr.Fields})
+}
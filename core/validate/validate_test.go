@@ -0,0 +1,89 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testRequest struct {
+	Symbol string   `json:"symbol" validate:"required,slug,max=10"`
+	Count  int      `json:"count" validate:"min=1"`
+	Tags   []string `json:"tags,omitempty" validate:"max=2"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []FieldError
+	}{
+		{"valid", `{"symbol":"eth","count":1}`, nil},
+		{"trailing whitespace", "{\"symbol\":\"eth\",\"count\":1}\n", nil},
+		{"empty body", ``, []FieldError{{"body", "must not be empty"}}},
+		{"unknown field", `{"symbol":"eth","count":1,"price":2}`, []FieldError{{"price", "unknown field"}}},
+		{"trailing object", `{"symbol":"eth","count":1}{"symbol":"btc"}`, []FieldError{{"body", "must contain a single json object"}}},
+		{"trailing bracket", `{"symbol":"eth","count":1}}`, []FieldError{{"body", "must contain a single json object"}}},
+		{"trailing garbage", `{"symbol":"eth","count":1} x`, []FieldError{{"body", "must contain a single json object"}}},
+		{"truncated", `{"symbol":"eth"`, []FieldError{{"body", "malformed json, unexpected end of body"}}},
+		{"wrong type", `{"symbol":"eth","count":"1"}`, []FieldError{{"count", "must be a int"}}},
+		{"oversized", `{"symbol":"` + strings.Repeat("a", maxBodySize) + `"}`, []FieldError{{"body", "must be at most 1048576 bytes"}}},
+		{"oversized trailing data", `{"symbol":"eth","count":1}` + strings.Repeat(" ", maxBodySize), []FieldError{{"body", "must be at most 1048576 bytes"}}},
+		// every invalid field is reported, with the first rule it breaks
+		{"invalid fields", `{"symbol":"ETH!","count":0,"tags":["a","b","c"]}`, []FieldError{
+			{"symbol", "may only contain lowercase letters, digits and dashes"},
+			{"count", "must be at least 1"},
+			{"tags", "must be at most 2 items"},
+		}},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		var req testRequest
+		err := DecodeJSON(r, &req)
+		if test.expected == nil {
+			if err != nil {
+				t.Errorf("%s: Expected no error, got: %v", test.name, err)
+			}
+			continue
+		}
+		verr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: Expected *Error, got: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(verr.Fields, test.expected) {
+			t.Errorf("%s: Expected fields: %v, got: %v", test.name, test.expected, verr.Fields)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		err      error
+		status   int
+		expected string
+	}{
+		{FieldErr("symbol", "is required"), http.StatusBadRequest, `{"error":"invalid request","fields":[{"field":"symbol","message":"is required"}]}`},
+		{&Error{Fields: []FieldError{{"symbol", "is required"}, {"count", "must be at least 1"}}}, http.StatusBadRequest,
+			`{"error":"invalid request","fields":[{"field":"symbol","message":"is required"},{"field":"count","message":"must be at least 1"}]}`},
+		{json.Unmarshal([]byte(`{`), &struct{}{}), http.StatusInternalServerError, `{"error":"unexpected end of JSON input"}`},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		WriteError(w, test.err)
+		if w.Code != test.status {
+			t.Errorf("Expected status: %v, got: %v", test.status, w.Code)
+		}
+		if body := strings.TrimSpace(w.Body.String()); body != test.expected {
+			t.Errorf("Expected body: %v, got: %v", test.expected, body)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Expected content type: %v, got: %v", "application/json", contentType)
+		}
+	}
+}
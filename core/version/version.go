@@ -0,0 +1,84 @@
+// Package version holds the build metadata of the binaries, injected at build time with the linker flags, eg.
+//
+//	go build -ldflags "-X github.com/zees-dev/blockless-avs/core/version.Version=0.1.0 \
+//		-X github.com/zees-dev/blockless-avs/core/version.Commit=$(git rev-parse HEAD) \
+//		-X github.com/zees-dev/blockless-avs/core/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// see the build target of the Makefile.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+const eigensdkModulePath = "github.com/Layr-Labs/eigensdk-go"
+
+// set with -ldflags -X, so they must stay uninitialized vars or string literals
+var (
+	// semver of the release
+	Version = "0.0.1"
+	// vcs revision; read from the go build info when not set
+	Commit = ""
+	// RFC 3339 build time
+	BuildDate = ""
+	// release of eigenlayer-middleware the avs contracts were built against, see contracts/lib
+	MiddlewareVersion = ""
+)
+
+// Info is the build metadata of the binary.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion"`
+	// version of the eigensdk the eigenlayer and middleware contract bindings come from
+	EigensdkVersion   string `json:"eigensdkVersion,omitempty"`
+	MiddlewareVersion string `json:"middlewareVersion,omitempty"`
+	// p2p protocol versions the node supports, current version first; set by the node package, see node/pkg.BuildInfo
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	info := Info{
+		Version:           Version,
+		Commit:            Commit,
+		BuildDate:         BuildDate,
+		GoVersion:         runtime.Version(),
+		MiddlewareVersion: MiddlewareVersion,
+	}
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Commit == "" {
+		modified := false
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	info.EigensdkVersion = DependencyVersion(buildInfo, eigensdkModulePath)
+	return info
+}
+
+// DependencyVersion returns the version of the module the binary was built with, following replace directives.
+func DependencyVersion(buildInfo *debug.BuildInfo, path string) string {
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == path {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
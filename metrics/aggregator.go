@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AggregatorMetrics contains the metrics incremented by the aggregator
+type AggregatorMetrics struct {
+	numRateLimitedRequests *prometheus.CounterVec
+	numStakeUpdates        *prometheus.CounterVec
+}
+
+func NewAggregatorMetrics(reg prometheus.Registerer) *AggregatorMetrics {
+	return &AggregatorMetrics{
+		numRateLimitedRequests: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "aggregator",
+				Name:      "num_rate_limited_requests",
+				Help:      "The number of rpc connections and calls rejected by the aggregator rate limits",
+			}, []string{"limit"}),
+		numStakeUpdates: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "aggregator",
+				Name:      "num_stake_updates",
+				Help:      "The number of scheduled operator set stake updates, by result",
+			}, []string{"result"}),
+	}
+}
+
+// IncNumRateLimitedRequests increments the rejections of the given limit ("ip" or "operator").
+func (m *AggregatorMetrics) IncNumRateLimitedRequests(limit string) {
+	m.numRateLimitedRequests.WithLabelValues(limit).Inc()
+}
+
+// IncNumStakeUpdates increments the scheduled stake updates with the given result ("sent", "dry_run", "over_budget"
+// or "failed").
+func (m *AggregatorMetrics) IncNumStakeUpdates(result string) {
+	m.numStakeUpdates.WithLabelValues(result).Inc()
+}
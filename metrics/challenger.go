@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ChallengerMetrics contains the metrics incremented by the challenger
+type ChallengerMetrics struct {
+	numOracleUpdatesChecked prometheus.Counter
+	numIncorrectResponses   prometheus.Counter
+	numIncorrectSigners     prometheus.Counter
+	numSignerLookupsFailed  prometheus.Counter
+}
+
+func NewChallengerMetrics(reg prometheus.Registerer) *ChallengerMetrics {
+	return &ChallengerMetrics{
+		numOracleUpdatesChecked: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "challenger",
+				Name:      "num_oracle_updates_checked",
+				Help:      "The number of onchain oracle updates checked by the challenger",
+			}),
+		numIncorrectResponses: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "challenger",
+				Name:      "num_incorrect_responses",
+				Help:      "The number of oracle updates whose price deviated from the expected price",
+			}),
+		numIncorrectSigners: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "challenger",
+				Name:      "num_incorrect_signers",
+				Help:      "The number of operators detected signing an oracle update whose price deviated from the expected price",
+			}),
+		numSignerLookupsFailed: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "challenger",
+				Name:      "num_signer_lookups_failed",
+				Help:      "The number of incorrect oracle updates whose signers couldn't be looked up after all retries",
+			}),
+	}
+}
+
+func (m *ChallengerMetrics) IncNumOracleUpdatesChecked() {
+	m.numOracleUpdatesChecked.Inc()
+}
+
+func (m *ChallengerMetrics) IncNumIncorrectResponses() {
+	m.numIncorrectResponses.Inc()
+}
+
+func (m *ChallengerMetrics) IncNumIncorrectSigners() {
+	m.numIncorrectSigners.Inc()
+}
+
+func (m *ChallengerMetrics) IncNumSignerLookupsFailed() {
+	m.numSignerLookupsFailed.Inc()
+}
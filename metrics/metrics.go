@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/Layr-Labs/eigensdk-go/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -9,7 +11,20 @@ import (
 type Metrics interface {
 	metrics.Metrics
 	IncNumTasksReceived()
+	IncNumTasksSigned()
+	// ObserveSigningLatency records the time from receiving a task to having signed its response
+	ObserveSigningLatency(latency time.Duration)
 	IncNumTasksAcceptedByAggregator()
+	// IncNumTaskResponseSubmissionFailures counts signed responses which couldn't be sent to the aggregator
+	IncNumTaskResponseSubmissionFailures()
+	// IncNumAggregatorSubmissions counts the submissions to an aggregator endpoint by result (accepted, rejected or error)
+	IncNumAggregatorSubmissions(endpoint string, result string)
+	// SetAggregatorEndpointHealthy records the result of the last health check of an aggregator endpoint
+	SetAggregatorEndpointHealthy(endpoint string, healthy bool)
+	// IncNumOperatorEvents counts the ejection, churn, deregistration and slashing events of the operator by kind
+	IncNumOperatorEvents(kind string)
+	// IncNumTasksRejectedByPolicy counts the tasks the operator policy didn't allow signing, by rule
+	IncNumTasksRejectedByPolicy(rule string)
 	// This metric would either need to be tracked by the aggregator itself,
 	// or we would need to write a collector that queries onchain for this info
 	// AddPercentageStakeSigned(percentage float64)
@@ -19,8 +34,15 @@ type Metrics interface {
 type AvsAndEigenMetrics struct {
 	metrics.Metrics
 	numTasksReceived prometheus.Counter
+	numTasksSigned   prometheus.Counter
+	signingLatency   prometheus.Histogram
 	// if numSignedTaskResponsesAcceptedByAggregator != numTasksReceived, then there is a bug
 	numSignedTaskResponsesAcceptedByAggregator prometheus.Counter
+	numTaskResponseSubmissionFailures          prometheus.Counter
+	aggregatorSubmissions                      *prometheus.CounterVec
+	aggregatorEndpointHealthy                  *prometheus.GaugeVec
+	operatorEvents                             *prometheus.CounterVec
+	tasksRejectedByPolicy                      *prometheus.CounterVec
 }
 
 const blocklessAVSNamespace = "blsavs"
@@ -34,12 +56,55 @@ func NewAvsAndEigenMetrics(avsName string, eigenMetrics *metrics.EigenMetrics, r
 				Name:      "num_tasks_received",
 				Help:      "The number of tasks received by reading from the avs service manager contract",
 			}),
+		numTasksSigned: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "num_tasks_signed",
+				Help:      "The number of tasks whose response was signed by the operator",
+			}),
+		signingLatency: promauto.With(reg).NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "signing_latency_seconds",
+				Help:      "The time from receiving a task to having signed its response, including fetching the price",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			}),
 		numSignedTaskResponsesAcceptedByAggregator: promauto.With(reg).NewCounter(
 			prometheus.CounterOpts{
 				Namespace: blocklessAVSNamespace,
 				Name:      "num_signed_task_responses_accepted_by_aggregator",
 				Help:      "The number of signed task responses accepted by the aggregator",
 			}),
+		numTaskResponseSubmissionFailures: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "num_signed_task_responses_submission_failures",
+				Help:      "The number of signed task responses which could not be sent to the aggregator after all retries",
+			}),
+		aggregatorSubmissions: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "aggregator_submissions_total",
+				Help:      "The number of signed task responses submitted to each aggregator endpoint, by result",
+			}, []string{"endpoint", "result"}),
+		aggregatorEndpointHealthy: promauto.With(reg).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "aggregator_endpoint_healthy",
+				Help:      "Whether the last health check of the aggregator endpoint succeeded (1) or failed (0)",
+			}, []string{"endpoint"}),
+		operatorEvents: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "operator_events_total",
+				Help:      "The number of ejection, churn, deregistration and slashing events of the operator, by kind",
+			}, []string{"kind"}),
+		tasksRejectedByPolicy: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Name:      "tasks_rejected_by_policy_total",
+				Help:      "The number of tasks the operator policy didn't allow signing, by rule",
+			}, []string{"rule"}),
 	}
 }
 
@@ -47,6 +112,38 @@ func (m *AvsAndEigenMetrics) IncNumTasksReceived() {
 	m.numTasksReceived.Inc()
 }
 
+func (m *AvsAndEigenMetrics) IncNumTasksSigned() {
+	m.numTasksSigned.Inc()
+}
+
+func (m *AvsAndEigenMetrics) ObserveSigningLatency(latency time.Duration) {
+	m.signingLatency.Observe(latency.Seconds())
+}
+
 func (m *AvsAndEigenMetrics) IncNumTasksAcceptedByAggregator() {
 	m.numSignedTaskResponsesAcceptedByAggregator.Inc()
 }
+
+func (m *AvsAndEigenMetrics) IncNumTaskResponseSubmissionFailures() {
+	m.numTaskResponseSubmissionFailures.Inc()
+}
+
+func (m *AvsAndEigenMetrics) IncNumAggregatorSubmissions(endpoint string, result string) {
+	m.aggregatorSubmissions.WithLabelValues(endpoint, result).Inc()
+}
+
+func (m *AvsAndEigenMetrics) SetAggregatorEndpointHealthy(endpoint string, healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.aggregatorEndpointHealthy.WithLabelValues(endpoint).Set(value)
+}
+
+func (m *AvsAndEigenMetrics) IncNumOperatorEvents(kind string) {
+	m.operatorEvents.WithLabelValues(kind).Inc()
+}
+
+func (m *AvsAndEigenMetrics) IncNumTasksRejectedByPolicy(rule string) {
+	m.tasksRejectedByPolicy.WithLabelValues(rule).Inc()
+}
@@ -0,0 +1,306 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	libp2pmetrics "github.com/libp2p/go-libp2p/core/metrics"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// NodeMetrics contains the metrics of the p2p node, ie. the throttling of the functions executed by worker nodes, the
+// trimming of the connections, the scoring of the peers, the verification of the executions and of the function
+// archives, the concurrency of the executions, the pending executions of the head node, and the b7s messages,
+// bandwidth and throttling of the p2p protocols
+type NodeMetrics struct {
+	cpuThrottledPeriods prometheus.Counter
+	cpuThrottledSeconds prometheus.Counter
+	memoryLimitEvents   *prometheus.CounterVec
+	connectionTrims     prometheus.Counter
+	trimmedConnections  prometheus.Counter
+	peerPenalties       *prometheus.CounterVec
+	peerBans            prometheus.Counter
+	messages            *prometheus.CounterVec
+	executeResponses    *prometheus.CounterVec
+	verifications       *prometheus.CounterVec
+	functionQuarantines *prometheus.CounterVec
+	concurrency         prometheus.Gauge
+	runningExecutions   prometheus.Gauge
+	pendingExecutions   prometheus.Gauge
+	recoveredExecutions *prometheus.CounterVec
+	throttledSeconds    *prometheus.CounterVec
+	// metered by the node, b7s creating the libp2p host without a bandwidth reporter
+	bandwidth *libp2pmetrics.BandwidthCounter
+}
+
+func NewNodeMetrics(reg prometheus.Registerer) *NodeMetrics {
+	bandwidth := libp2pmetrics.NewBandwidthCounter()
+	reg.MustRegister(&bandwidthCollector{bandwidth: bandwidth})
+	return &NodeMetrics{
+		bandwidth: bandwidth,
+		cpuThrottledPeriods: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "cpu_throttled_periods_total",
+				Help:      "The number of cpu periods in which the function executions were throttled by the cpu percentage limit",
+			}),
+		cpuThrottledSeconds: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "cpu_throttled_seconds_total",
+				Help:      "The time the function executions were throttled for by the cpu percentage limit",
+			}),
+		memoryLimitEvents: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "memory_limit_events_total",
+				Help:      "The number of times the function executions hit the memory limit, by event",
+			}, []string{"event"}),
+		connectionTrims: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "connection_trims_total",
+				Help:      "The number of times the connections were trimmed for exceeding the high watermark",
+			}),
+		trimmedConnections: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "trimmed_connections_total",
+				Help:      "The number of connections closed by the connection trims",
+			}),
+		peerPenalties: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "peer_penalties_total",
+				Help:      "The number of times the score of a peer was lowered, by reason",
+			}, []string{"reason"}),
+		peerBans: promauto.With(reg).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "peer_bans_total",
+				Help:      "The number of peers banned for their score dropping to the ban threshold",
+			}),
+		messages: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "direct_messages_total",
+				Help:      "The number of b7s direct messages sent to and received from the peers, by direction and message type",
+			}, []string{"direction", "type"}),
+		executeResponses: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "execute_responses_total",
+				Help:      "The number of function execution responses sent to and received from the peers, by direction and response code",
+			}, []string{"direction", "code"}),
+		verifications: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "execution_verifications_total",
+				Help:      "The number of executions whose results were compared across the workers, by outcome",
+			}, []string{"outcome"}),
+		functionQuarantines: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "function_quarantines_total",
+				Help:      "The number of functions quarantined for an archive not matching their manifest checksum or CID, by reason",
+			}, []string{"reason"}),
+		concurrency: promauto.With(reg).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "execution_concurrency",
+				Help:      "The number of functions the worker node executes at once with adaptive concurrency",
+			}),
+		runningExecutions: promauto.With(reg).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "running_executions",
+				Help:      "The number of functions the worker node is executing",
+			}),
+		pendingExecutions: promauto.With(reg).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "pending_executions",
+				Help:      "The number of execution requests the head node has yet to respond to, kept across restarts",
+			}),
+		recoveredExecutions: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "recovered_executions_total",
+				Help:      "The number of execution requests left pending by a head node restart, by outcome",
+			}, []string{"outcome"}),
+		throttledSeconds: promauto.With(reg).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: blocklessAVSNamespace,
+				Subsystem: "node",
+				Name:      "p2p_throttled_seconds_total",
+				Help:      "The time the streams of the p2p protocols were delayed for by the per-peer bandwidth limit, by direction",
+			}, []string{"direction"}),
+	}
+}
+
+// AddCPUThrottled adds the periods and time the function executions were throttled for.
+func (m *NodeMetrics) AddCPUThrottled(periods uint64, throttled time.Duration) {
+	m.cpuThrottledPeriods.Add(float64(periods))
+	m.cpuThrottledSeconds.Add(throttled.Seconds())
+}
+
+// AddMemoryLimitEvents adds memory limit events ("max" when the executions were reclaimed at the limit, "oom_kill" when
+// an execution was killed).
+func (m *NodeMetrics) AddMemoryLimitEvents(event string, n uint64) {
+	m.memoryLimitEvents.WithLabelValues(event).Add(float64(n))
+}
+
+// AddConnectionTrim records a trim of the connections, which closed n of them.
+func (m *NodeMetrics) AddConnectionTrim(n int) {
+	m.connectionTrims.Inc()
+	m.trimmedConnections.Add(float64(n))
+}
+
+// AddPeerPenalty records a penalty of a peer ("failed_execution", "protocol_violation", "excessive_messages" or
+// "result_mismatch").
+func (m *NodeMetrics) AddPeerPenalty(reason string) {
+	m.peerPenalties.WithLabelValues(reason).Inc()
+}
+
+// AddPeerBan records the ban of a peer.
+func (m *NodeMetrics) AddPeerBan() {
+	m.peerBans.Inc()
+}
+
+// AddDirectMessage records a b7s direct message "sent" or "received".
+func (m *NodeMetrics) AddDirectMessage(direction string, msgType string) {
+	m.messages.WithLabelValues(direction, msgType).Inc()
+}
+
+// AddExecuteResponse records a function execution response "sent" or "received", with its b7s response code.
+func (m *NodeMetrics) AddExecuteResponse(direction string, code string) {
+	m.executeResponses.WithLabelValues(direction, code).Inc()
+}
+
+// AddExecutionVerification records the comparison of the results of an execution ("agreed", "disputed" or
+// "unverified").
+func (m *NodeMetrics) AddExecutionVerification(outcome string) {
+	m.verifications.WithLabelValues(outcome).Inc()
+}
+
+// AddFunctionQuarantine records the quarantine of a function whose archive didn't match ("checksum_mismatch" or
+// "cid_mismatch").
+func (m *NodeMetrics) AddFunctionQuarantine(reason string) {
+	m.functionQuarantines.WithLabelValues(reason).Inc()
+}
+
+// SetExecutionConcurrency sets the number of functions executed at once by a worker node with adaptive concurrency.
+func (m *NodeMetrics) SetExecutionConcurrency(n int) {
+	m.concurrency.Set(float64(n))
+}
+
+// AddRunningExecutions adds delta to the number of functions being executed.
+func (m *NodeMetrics) AddRunningExecutions(delta int) {
+	m.runningExecutions.Add(float64(delta))
+}
+
+// SetPendingExecutions sets the number of execution requests the head node has yet to respond to.
+func (m *NodeMetrics) SetPendingExecutions(n int) {
+	m.pendingExecutions.Set(float64(n))
+}
+
+// AddRecoveredExecution records an execution request left pending by a restart of the head node ("redispatched",
+// "expired" past the max age, or "abandoned" after the max attempts).
+func (m *NodeMetrics) AddRecoveredExecution(outcome string) {
+	m.recoveredExecutions.WithLabelValues(outcome).Inc()
+}
+
+// AddThrottledTime records a stream delayed by the per-peer bandwidth limit, "sent" or "received".
+func (m *NodeMetrics) AddThrottledTime(direction string, delay time.Duration) {
+	m.throttledSeconds.WithLabelValues(direction).Add(delay.Seconds())
+}
+
+// Bandwidth returns the counter of the bytes sent and received on the p2p protocols, by protocol and peer.
+func (m *NodeMetrics) Bandwidth() *libp2pmetrics.BandwidthCounter {
+	return m.bandwidth
+}
+
+// number of peers the node exchanged the most bytes with exported by the collector, so the peer label stays bounded
+const exportedTopTalkers = 10
+
+// PeerBandwidth is the bandwidth of the p2p protocols with a peer.
+type PeerBandwidth struct {
+	Peer peer.ID `json:"peer"`
+	// bytes received from and sent to the peer since the node started
+	BytesIn  int64 `json:"bytesIn"`
+	BytesOut int64 `json:"bytesOut"`
+	// current rates, in bytes per second
+	RateIn  float64 `json:"rateIn"`
+	RateOut float64 `json:"rateOut"`
+}
+
+// TopTalkers returns the n peers the node exchanged the most bytes with, the most first; all of them when n is 0.
+func (m *NodeMetrics) TopTalkers(n int) []PeerBandwidth {
+	return topTalkers(m.bandwidth, n)
+}
+
+func topTalkers(bandwidth *libp2pmetrics.BandwidthCounter, n int) []PeerBandwidth {
+	talkers := []PeerBandwidth{}
+	for p, stats := range bandwidth.GetBandwidthByPeer() {
+		talkers = append(talkers, PeerBandwidth{Peer: p, BytesIn: stats.TotalIn, BytesOut: stats.TotalOut, RateIn: stats.RateIn, RateOut: stats.RateOut})
+	}
+	sort.Slice(talkers, func(i, j int) bool {
+		return talkers[i].BytesIn+talkers[i].BytesOut > talkers[j].BytesIn+talkers[j].BytesOut
+	})
+	if n > 0 && len(talkers) > n {
+		talkers = talkers[:n]
+	}
+	return talkers
+}
+
+var (
+	p2pBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(blocklessAVSNamespace, "node", "p2p_bytes_total"),
+		"The number of bytes sent and received on the streams of the p2p protocols, by direction and protocol",
+		[]string{"direction", "protocol"}, nil,
+	)
+	peerBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(blocklessAVSNamespace, "node", "peer_bytes_total"),
+		"The number of bytes sent to and received from the peers the node exchanged the most bytes with on the p2p protocols, by direction and peer",
+		[]string{"direction", "peer"}, nil,
+	)
+)
+
+// bandwidthCollector exports the totals of the bandwidth counter, which keeps them by protocol and peer, only for the
+// top talkers among the peers.
+type bandwidthCollector struct {
+	bandwidth *libp2pmetrics.BandwidthCounter
+}
+
+func (c *bandwidthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p2pBytesDesc
+	ch <- peerBytesDesc
+}
+
+func (c *bandwidthCollector) Collect(ch chan<- prometheus.Metric) {
+	for protocol, stats := range c.bandwidth.GetBandwidthByProtocol() {
+		ch <- prometheus.MustNewConstMetric(p2pBytesDesc, prometheus.CounterValue, float64(stats.TotalIn), "received", string(protocol))
+		ch <- prometheus.MustNewConstMetric(p2pBytesDesc, prometheus.CounterValue, float64(stats.TotalOut), "sent", string(protocol))
+	}
+	for _, talker := range topTalkers(c.bandwidth, exportedTopTalkers) {
+		ch <- prometheus.MustNewConstMetric(peerBytesDesc, prometheus.CounterValue, float64(talker.BytesIn), "received", talker.Peer.String())
+		ch <- prometheus.MustNewConstMetric(peerBytesDesc, prometheus.CounterValue, float64(talker.BytesOut), "sent", talker.Peer.String())
+	}
+}
@@ -1,6 +1,8 @@
 package metrics
 
 import (
+	"time"
+
 	eigenmetrics "github.com/Layr-Labs/eigensdk-go/metrics"
 )
 
@@ -16,4 +18,18 @@ func NewNoopMetrics() *NoopMetrics {
 
 func (m *NoopMetrics) IncNumTasksReceived() {}
 
+func (m *NoopMetrics) IncNumTasksSigned() {}
+
+func (m *NoopMetrics) ObserveSigningLatency(latency time.Duration) {}
+
 func (m *NoopMetrics) IncNumTasksAcceptedByAggregator() {}
+
+func (m *NoopMetrics) IncNumTaskResponseSubmissionFailures() {}
+
+func (m *NoopMetrics) IncNumAggregatorSubmissions(endpoint string, result string) {}
+
+func (m *NoopMetrics) SetAggregatorEndpointHealthy(endpoint string, healthy bool) {}
+
+func (m *NoopMetrics) IncNumOperatorEvents(kind string) {}
+
+func (m *NoopMetrics) IncNumTasksRejectedByPolicy(rule string) {}
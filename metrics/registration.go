@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+// RegistrationCollector exports whether the operator is registered with the avs. Like the eigensdk economic
+// collector, the registration status is read from the chain on every scrape.
+type RegistrationCollector struct {
+	avsRegistryReader avsregistry.AvsRegistryReader
+	operatorAddr      common.Address
+	logger            logging.Logger
+	// registered is 1 when the operator is registered with the avs registry coordinator, and 0 otherwise
+	registered *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*RegistrationCollector)(nil)
+
+func NewRegistrationCollector(avsRegistryReader avsregistry.AvsRegistryReader, operatorAddr common.Address, logger logging.Logger) *RegistrationCollector {
+	return &RegistrationCollector{
+		avsRegistryReader: avsRegistryReader,
+		operatorAddr:      operatorAddr,
+		logger:            logger,
+		registered: prometheus.NewDesc(
+			prometheus.BuildFQName(blocklessAVSNamespace, "", "operator_registered"),
+			"Whether the operator is registered with the avs registry coordinator (1) or not (0)",
+			nil, nil,
+		),
+	}
+}
+
+func (c *RegistrationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.registered
+}
+
+func (c *RegistrationCollector) Collect(ch chan<- prometheus.Metric) {
+	registered, err := c.avsRegistryReader.IsOperatorRegistered(&bind.CallOpts{}, c.operatorAddr)
+	if err != nil {
+		c.logger.Error("Failed to get operator registration status", "err", err)
+		return
+	}
+	value := 0.0
+	if registered {
+		value = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.registered, prometheus.GaugeValue, value)
+}
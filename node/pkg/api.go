@@ -1,14 +1,32 @@
 package pkg
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	avs "github.com/zees-dev/blockless-avs"
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/core/validate"
 	proto "github.com/zees-dev/blockless-avs/node/proto"
+	"github.com/zees-dev/blockless-avs/operator"
 )
 
+// OracleUpdateRequest is the body of POST /api/oracle.
+type OracleUpdateRequest struct {
+	// coingecko id of the asset, eg. bitcoin
+	Symbol string `json:"symbol" validate:"required,max=64,slug"`
+	// blockless wasm function computing the price; the price is fetched from coingecko when not set
+	Function *operator.WasmFunction `json:"function,omitempty"`
+	// index of the aggregator task the request answers, checked against the operator policy
+	TaskIndex *uint32 `json:"taskIndex,omitempty"`
+	// quorums of the aggregator task, eg. [0]; the request is skipped unless the operator participates in one of them
+	QuorumNumbers []uint8 `json:"quorumNumbers,omitempty" validate:"max=256"`
+}
+
 // RegisterAPIRoutes sets up the API routes.
 func RegisterAPIRoutes(cfg *avs.AppConfig, mux *http.ServeMux) {
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
@@ -61,20 +79,47 @@ func RegisterAPIRoutes(cfg *avs.AppConfig, mux *http.ServeMux) {
 		w.Write(jsonData)
 	})
 
+	// build metadata of the running binary, see avs version
+	mux.HandleFunc("GET /api/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, BuildInfo())
+	})
+
 	// newOracleUpdateChan
 	mux.HandleFunc("POST /api/oracle", func(w http.ResponseWriter, r *http.Request) {
-		// Parse the JSON body
-		var req struct {
-			Symbol string `json:"symbol"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			cfg.Logger.Error("Failed to decode JSON request: %v", err)
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		// Parse and validate the JSON body
+		var req OracleUpdateRequest
+		if err := validate.DecodeJSON(r, &req); err != nil {
+			cfg.Logger.Debug("Invalid oracle update request", "err", err)
+			validate.WriteError(w, err)
 			return
 		}
 
+		if req.Function != nil {
+			if err := validate.Struct(req.Function); err != nil {
+				var verr *validate.Error
+				if errors.As(err, &verr) {
+					for i := range verr.Fields {
+						verr.Fields[i].Field = "function." + verr.Fields[i].Field
+					}
+				}
+				validate.WriteError(w, err)
+				return
+			}
+			if err := req.Function.Validate(); err != nil {
+				validate.WriteError(w, validate.FieldErr("function", err.Error()))
+				return
+			}
+		}
+
 		// Request an oracle update
-		cfg.Operator.RequestOracleUpdate(req.Symbol)
+		if err := cfg.Operator.RequestOracleTask(&operator.OracleTask{Symbol: req.Symbol, Function: req.Function, TaskIndex: req.TaskIndex, QuorumNumbers: req.QuorumNumbers}); err != nil {
+			if errors.Is(err, operator.ErrDraining) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			validate.WriteError(w, validate.FieldErr("function", err.Error()))
+			return
+		}
 
 		// Construct the response
 		response := struct {
@@ -93,4 +138,123 @@ func RegisterAPIRoutes(cfg *avs.AppConfig, mux *http.ServeMux) {
 			http.Error(w, "Error encoding response", http.StatusInternalServerError)
 		}
 	})
+
+	// rereads the config file and applies its reloadable settings, like sending the process a SIGHUP
+	mux.HandleFunc("POST /api/config/reload", func(w http.ResponseWriter, r *http.Request) {
+		if err := cfg.ReloadNodeConfig(); err != nil {
+			cfg.Logger.Error("Failed to reload operator config", "err", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, struct {
+			Reloaded bool `json:"reloaded"`
+		}{Reloaded: true})
+	})
+
+	// stops accepting tasks and shuts the process down once the in-flight responses were submitted, see Operator.Drain
+	mux.HandleFunc("POST /api/drain", func(w http.ResponseWriter, r *http.Request) {
+		go func() {
+			defer reporting.Recover()
+			cfg.Operator.Drain(context.Background())
+		}()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(struct {
+			Draining bool `json:"draining"`
+		}{Draining: true})
+	})
+
+	// execution artifacts, for debugging why a response digest diverged from the other operators
+	mux.HandleFunc("GET /api/executions", func(w http.ResponseWriter, r *http.Request) {
+		store := cfg.Operator.Artifacts()
+		if store == nil {
+			http.Error(w, "execution artifacts are disabled", http.StatusNotFound)
+			return
+		}
+		executions, err := store.List(r.URL.Query().Get("digest"))
+		if err != nil {
+			cfg.Logger.Error("Failed to list executions", "err", err)
+			http.Error(w, "Error listing executions", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, executions)
+	})
+
+	mux.HandleFunc("GET /api/executions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		store := cfg.Operator.Artifacts()
+		if store == nil {
+			http.Error(w, "execution artifacts are disabled", http.StatusNotFound)
+			return
+		}
+		execution, err := store.Get(r.PathValue("id"))
+		if err != nil {
+			writeArtifactError(cfg, w, err)
+			return
+		}
+		writeJSON(w, execution)
+	})
+
+	mux.HandleFunc("GET /api/executions/{id}/artifacts/{name}", func(w http.ResponseWriter, r *http.Request) {
+		store := cfg.Operator.Artifacts()
+		if store == nil {
+			http.Error(w, "execution artifacts are disabled", http.StatusNotFound)
+			return
+		}
+		name := r.PathValue("name")
+		content, err := store.Artifact(r.PathValue("id"), name)
+		if err != nil {
+			writeArtifactError(cfg, w, err)
+			return
+		}
+		contentType := "text/plain; charset=utf-8"
+		if name == operator.OutputArtifact {
+			contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	})
+}
+
+// RegisterP2PRoutes sets up the API routes of the p2p node.
+func RegisterP2PRoutes(p2pNode *Node, mux *http.ServeMux) {
+	// connectivity of the node; unavailable while it is isolated or down, for monitoring to alert on
+	mux.HandleFunc("GET /p2p/health", func(w http.ResponseWriter, r *http.Request) {
+		health := p2pNode.Health()
+		status := http.StatusOK
+		if health.Status == P2PIsolated || health.Status == P2PDown {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(health)
+	})
+
+	// peers the node exchanged the most bytes with, ?top=N of them (default 10, 0 for all)
+	mux.HandleFunc("GET /p2p/bandwidth", func(w http.ResponseWriter, r *http.Request) {
+		top := defaultTopTalkers
+		if value := r.URL.Query().Get("top"); value != "" {
+			var err error
+			if top, err = strconv.Atoi(value); err != nil || top < 0 {
+				validate.WriteError(w, validate.FieldErr("top", "must be a non-negative integer"))
+				return
+			}
+		}
+		writeJSON(w, p2pNode.Bandwidth(top))
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeArtifactError(cfg *avs.AppConfig, w http.ResponseWriter, err error) {
+	if errors.Is(err, operator.ErrExecutionNotFound) || errors.Is(err, operator.ErrArtifactNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	cfg.Logger.Error("Failed to read execution artifacts", "err", err)
+	http.Error(w, "Error reading execution artifacts", http.StatusInternalServerError)
 }
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/blocklessnetwork/b7s-attributes/attributes"
+	"github.com/ipfs/boxo/ipns"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// time allowed to fetch the attestation of a worker from IPFS
+const attestationTimeout = 30 * time.Second
+
+// loadAttestation returns the attributes attestation of the worker, read from the file, or fetched from IPFS under the
+// IPNS name of the worker like b7s does with attribute loading; nil without either. The attestation is verified, so a
+// worker whose attestation the head nodes would reject doesn't start.
+func loadAttestation(id peer.ID, file string, fromIPFS bool) (*attributes.Attestation, error) {
+	var reader io.Reader
+	switch {
+	case file != "":
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not open attributes attestation: %w", err)
+		}
+		defer f.Close()
+		reader = f
+	case fromIPFS:
+		client := http.Client{Timeout: attestationTimeout}
+		res, err := client.Get(fmt.Sprintf("https://%s.ipns.cf-ipfs.com/attributes.bin", ipns.NameFromPeer(id)))
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch attributes attestation: %w", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("could not fetch attributes attestation: %s", res.Status)
+		}
+		reader = res.Body
+	default:
+		return nil, nil
+	}
+
+	attestation, err := attributes.ImportAttestation(reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not read attributes attestation: %w", err)
+	}
+	if err := verifyAttestation(id, attestation); err != nil {
+		return nil, err
+	}
+	return &attestation, nil
+}
+
+// verifyAttestation checks the signatures of the attestation of the worker: its own, if signed, and the ones of its
+// attestors, which are only valid along with the signature of the worker.
+func verifyAttestation(id peer.ID, attestation attributes.Attestation) error {
+	if attestation.Signature != nil && attestation.Signature.Signer != id {
+		return fmt.Errorf("attributes attestation signed by %s, not by the worker", attestation.Signature.Signer)
+	}
+	if err := attributes.Validate(attestation); err != nil {
+		return fmt.Errorf("invalid attributes attestation: %w", err)
+	}
+	if len(attestation.Attributes) == 0 {
+		return errors.New("attributes attestation without attributes")
+	}
+	return nil
+}
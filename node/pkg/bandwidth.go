@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	// full buckets are forgotten this often; missing buckets start full, so this doesn't change the limits
+	bandwidthPruneInterval = time.Minute
+	// peers reported by the bandwidth API, by default
+	defaultTopTalkers = 10
+)
+
+// bandwidthLimiter throttles the bytes received from and sent to each peer to a rate, with a token bucket per peer and
+// direction holding a second of bytes. The bytes are taken whether available or not, a bucket going into debt for the
+// ones exceeding it, which the stream waits for to be refilled; the messages larger than the bucket still go through.
+type bandwidthLimiter struct {
+	// bytes per second
+	rate float64
+
+	mu        sync.Mutex
+	buckets   map[bandwidthKey]*byteBucket
+	lastPrune time.Time
+}
+
+type bandwidthKey struct {
+	peer peer.ID
+	// "sent" or "received"
+	direction string
+}
+
+type byteBucket struct {
+	bytes float64
+	last  time.Time
+}
+
+// newBandwidthLimiter returns the limiter of the KiB per second exchanged with each peer, nil when unlimited.
+func newBandwidthLimiter(limit uint) *bandwidthLimiter {
+	if limit == 0 {
+		return nil
+	}
+	return &bandwidthLimiter{rate: float64(limit) * 1024, buckets: make(map[bandwidthKey]*byteBucket)}
+}
+
+// reserve takes n bytes from the bucket of the peer and direction, returning how long to wait for them.
+func (l *bandwidthLimiter) reserve(p peer.ID, direction string, n int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastPrune) > bandwidthPruneInterval {
+		l.prune(now)
+	}
+	key := bandwidthKey{peer: p, direction: direction}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &byteBucket{bytes: l.rate, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.bytes = l.refill(bucket, now) - float64(n)
+	bucket.last = now
+	if bucket.bytes >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.bytes / l.rate * float64(time.Second))
+}
+
+func (l *bandwidthLimiter) refill(bucket *byteBucket, now time.Time) float64 {
+	return math.Min(l.rate, bucket.bytes+now.Sub(bucket.last).Seconds()*l.rate)
+}
+
+func (l *bandwidthLimiter) prune(now time.Time) {
+	for key, bucket := range l.buckets {
+		if l.refill(bucket, now) >= l.rate {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastPrune = now
+}
+
+// P2PBandwidth reports the bandwidth of the node protocols with the peers, to find the ones saturating the link.
+type P2PBandwidth struct {
+	// KiB per second exchanged with each peer in each direction, 0 when unlimited
+	PeerLimit uint `json:"peerLimit"`
+	// peers the node exchanged the most bytes with, the most first
+	TopTalkers []metrics.PeerBandwidth `json:"topTalkers"`
+}
+
+// Bandwidth returns the bandwidth of the top peers the node exchanged the most bytes with, all of them when top is 0.
+func (n *Node) Bandwidth(top int) P2PBandwidth {
+	return P2PBandwidth{PeerLimit: n.connectivity.PeerBandwidthLimit, TopTalkers: n.metrics.TopTalkers(top)}
+}
@@ -0,0 +1,209 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/event"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+)
+
+const (
+	operatorBindingProtocol = protocol.ID("/blockless-avs/operator-binding/1.0.0")
+	// bindings are a few hundred bytes
+	maxOperatorBindingSize = 4096
+	operatorBindingTimeout = 10 * time.Second
+)
+
+// OperatorBinding binds the peer id of a node to the address of the EigenLayer operator running it. It is signed by
+// the operator ecdsa key, so the peers of the node can attribute its behaviour to the operator and its stake.
+type OperatorBinding struct {
+	PeerID   peer.ID        `json:"peerId"`
+	Operator common.Address `json:"operator"`
+	Issued   time.Time      `json:"issued"`
+	// EIP-191 personal message signature of Message, as returned by personal_sign
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// OperatorSigner signs the personal messages of the operator running the node, see operator.SignPersonalMessage.
+type OperatorSigner struct {
+	Address common.Address
+	Sign    func(msg []byte) ([]byte, error)
+}
+
+// NewOperatorBinding returns the binding of the peer id to the operator, signed by signer.
+func NewOperatorBinding(id peer.ID, signer OperatorSigner) (*OperatorBinding, error) {
+	binding := &OperatorBinding{PeerID: id, Operator: signer.Address, Issued: time.Now().UTC().Truncate(time.Second)}
+	signature, err := signer.Sign(binding.Message())
+	if err != nil {
+		return nil, fmt.Errorf("could not sign operator binding: %w", err)
+	}
+	binding.Signature = signature
+	if err := binding.Verify(); err != nil {
+		return nil, err
+	}
+	return binding, nil
+}
+
+// Message returns the message signed by the operator, readable when signing with a wallet.
+func (b *OperatorBinding) Message() []byte {
+	return []byte(fmt.Sprintf("Blockless AVS p2p identity\npeer id: %s\noperator: %s\nissued: %s",
+		b.PeerID, b.Operator.Hex(), b.Issued.UTC().Format(time.RFC3339)))
+}
+
+// Verify checks that the binding is signed by its operator.
+func (b *OperatorBinding) Verify() error {
+	if len(b.Signature) != crypto.SignatureLength {
+		return fmt.Errorf("invalid operator binding signature length %d", len(b.Signature))
+	}
+	signature := slices.Clone(b.Signature)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	key, err := crypto.SigToPub(accounts.TextHash(b.Message()), signature)
+	if err != nil {
+		return fmt.Errorf("invalid operator binding signature: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*key); signer != b.Operator {
+		return fmt.Errorf("operator binding of %s is signed by %s", b.Operator.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// operatorBindings serves the binding of the node, if any, and fetches and verifies the bindings of the peers once
+// they're identified. The verified bindings are stored with the peer records.
+type operatorBindings struct {
+	log   *zerolog.Logger
+	host  host.Host
+	peers *lastSeenPeerStore
+
+	mu       sync.Mutex
+	verified map[peer.ID]*OperatorBinding
+}
+
+// startOperatorBindings serves the binding of the node to the operator of signer, unless nil, and verifies the bindings
+// of the peers until ctx is done.
+func startOperatorBindings(ctx context.Context, log *zerolog.Logger, h host.Host, signer *OperatorSigner, peers *lastSeenPeerStore) (*operatorBindings, error) {
+	b := &operatorBindings{log: log, host: h, peers: peers, verified: map[peer.ID]*OperatorBinding{}}
+	if signer != nil {
+		binding, err := NewOperatorBinding(h.ID(), *signer)
+		if err != nil {
+			// eg. with a remote signer; the node still verifies the bindings of its peers
+			log.Warn().Err(err).Msg("not advertising the operator binding of the node")
+		} else {
+			b.serve(binding)
+			log.Info().Str("operator", binding.Operator.Hex()).Msg("advertising operator binding")
+		}
+	}
+
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		return nil, fmt.Errorf("could not subscribe to peer identification events: %w", err)
+	}
+	go func() {
+		defer reporting.Recover()
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				id := e.(event.EvtPeerIdentificationCompleted).Peer
+				// the peers which don't advertise a binding, such as the nodes without an operator, are skipped
+				if supported, err := h.Peerstore().SupportsProtocols(id, operatorBindingProtocol); err == nil && len(supported) > 0 {
+					go b.verify(ctx, id)
+				}
+			}
+		}
+	}()
+	return b, nil
+}
+
+func (b *operatorBindings) serve(binding *OperatorBinding) {
+	payload, _ := json.Marshal(binding)
+	b.host.SetStreamHandler(operatorBindingProtocol, func(stream network.Stream) {
+		defer stream.Close()
+		stream.SetWriteDeadline(time.Now().Add(operatorBindingTimeout))
+		if _, err := stream.Write(payload); err != nil {
+			b.log.Debug().Err(err).Str("peer", stream.Conn().RemotePeer().String()).Msg("could not send operator binding")
+			stream.Reset()
+		}
+	})
+}
+
+// verify fetches the binding of the peer and stores it once verified.
+func (b *operatorBindings) verify(ctx context.Context, id peer.ID) {
+	defer reporting.Recover()
+	binding, err := b.fetch(ctx, id)
+	if err != nil {
+		b.log.Warn().Err(err).Str("peer", id.String()).Msg("could not verify operator binding of peer")
+		return
+	}
+	b.mu.Lock()
+	b.verified[id] = binding
+	b.mu.Unlock()
+	err = b.peers.update(id, func(record *PeerRecord, found bool) bool {
+		if !found {
+			return false
+		}
+		record.OperatorBinding = binding
+		return true
+	})
+	if err != nil {
+		b.log.Error().Err(err).Str("peer", id.String()).Msg("could not store operator binding of peer")
+	}
+	b.log.Debug().Str("peer", id.String()).Str("operator", binding.Operator.Hex()).Msg("verified operator binding of peer")
+}
+
+func (b *operatorBindings) fetch(ctx context.Context, id peer.ID) (*OperatorBinding, error) {
+	ctx, cancel := context.WithTimeout(ctx, operatorBindingTimeout)
+	defer cancel()
+	stream, err := b.host.NewStream(ctx, id, operatorBindingProtocol)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	stream.SetReadDeadline(time.Now().Add(operatorBindingTimeout))
+	payload, err := io.ReadAll(io.LimitReader(stream, maxOperatorBindingSize))
+	if err != nil {
+		return nil, err
+	}
+	var binding OperatorBinding
+	if err := json.Unmarshal(payload, &binding); err != nil {
+		return nil, fmt.Errorf("invalid operator binding: %w", err)
+	}
+	if binding.PeerID != id {
+		return nil, errors.New("the operator binding is the one of another peer")
+	}
+	if err := binding.Verify(); err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// operator returns the verified binding of the peer, if any.
+func (b *operatorBindings) operator(id peer.ID) (*OperatorBinding, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	binding, ok := b.verified[id]
+	return binding, ok
+}
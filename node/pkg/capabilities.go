@@ -0,0 +1,386 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s-attributes/attributes"
+	"github.com/blocklessnetwork/b7s/config"
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/response"
+	"github.com/libp2p/go-libp2p/core/event"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/pbnjay/memory"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+)
+
+const (
+	capabilitiesProtocol = protocol.ID("/blockless-avs/capabilities/1.0.0")
+	maxCapabilitiesSize  = 16 * 1024
+	capabilitiesTimeout  = 10 * time.Second
+	// time allowed to the runtime to print its version
+	runtimeVersionTimeout = 5 * time.Second
+)
+
+// WorkerCapabilities are the resources and attributes a worker advertises to the head nodes.
+type WorkerCapabilities struct {
+	CPUs int `json:"cpus"`
+	// bytes of memory available to the function executions, the memory limit when set
+	Memory uint64 `json:"memory"`
+	// version of the blockless runtime, as printed by bls-runtime --version
+	Runtime    string            `json:"runtime,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// attributes signed by the worker and its attestors, loaded from --attributes-file or IPFS; verified by the head
+	// nodes, which drop it when invalid
+	Attestation *attributes.Attestation `json:"attestation,omitempty"`
+}
+
+// WorkerRequirements are the capabilities a head node requires of the workers executing its tasks; the zero value
+// accepts every worker.
+type WorkerRequirements struct {
+	MinCPUs int
+	// bytes
+	MinMemory uint64
+	// substring of the runtime version, eg. v0.3
+	Runtime string
+	// attributes the workers must advertise with the same value
+	Attributes map[string]string
+}
+
+// Empty returns whether the requirements accept every worker.
+func (r WorkerRequirements) Empty() bool {
+	return r.MinCPUs == 0 && r.MinMemory == 0 && r.Runtime == "" && len(r.Attributes) == 0
+}
+
+// Match returns why the capabilities don't meet the requirements, if they don't.
+func (r WorkerRequirements) Match(c WorkerCapabilities) error {
+	if c.CPUs < r.MinCPUs {
+		return fmt.Errorf("%d cpus, %d required", c.CPUs, r.MinCPUs)
+	}
+	if c.Memory < r.MinMemory {
+		return fmt.Errorf("%d MiB of memory, %d MiB required", c.Memory>>20, r.MinMemory>>20)
+	}
+	if r.Runtime != "" && !strings.Contains(c.Runtime, r.Runtime) {
+		return fmt.Errorf("runtime %q, %q required", c.Runtime, r.Runtime)
+	}
+	for name, value := range r.Attributes {
+		if have, ok := c.Attributes[name]; !ok || have != value {
+			return fmt.Errorf("attribute %s=%q, %q required", name, have, value)
+		}
+	}
+	return nil
+}
+
+// CapabilityConfig configures the attributes a worker advertises with its resources, and the requirements a head node
+// selects the workers of its executions on.
+type CapabilityConfig struct {
+	// advertised by the worker nodes, eg. region=eu
+	Attributes map[string]string
+	// attributes attestation file advertised by the worker nodes, instead of the one loaded from IPFS with --attributes
+	AttestationFile string
+	Requirements    WorkerRequirements
+}
+
+func ParseCapabilityFlags(c *cli.Context) (CapabilityConfig, error) {
+	attributes, err := parseAttributes(c.StringSlice(WorkerAttributes.Name))
+	if err != nil {
+		return CapabilityConfig{}, fmt.Errorf("invalid --%s: %w", WorkerAttributes.Name, err)
+	}
+	required, err := parseAttributes(c.StringSlice(ExecutorAttributes.Name))
+	if err != nil {
+		return CapabilityConfig{}, fmt.Errorf("invalid --%s: %w", ExecutorAttributes.Name, err)
+	}
+	return CapabilityConfig{
+		Attributes:      attributes,
+		AttestationFile: c.String(AttributesFile.Name),
+		Requirements: WorkerRequirements{
+			MinCPUs:    c.Int(ExecutorMinCPUs.Name),
+			MinMemory:  c.Uint64(ExecutorMinMemory.Name) << 20,
+			Runtime:    c.String(ExecutorRuntime.Name),
+			Attributes: required,
+		},
+	}, nil
+}
+
+// parseAttributes parses name=value attributes.
+func parseAttributes(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	attributes := make(map[string]string, len(values))
+	for _, attribute := range values {
+		name, value, ok := strings.Cut(attribute, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("attribute %q is not in the name=value format", attribute)
+		}
+		attributes[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return attributes, nil
+}
+
+// localCapabilities returns the capabilities of the worker running with cfg.
+func localCapabilities(log *zerolog.Logger, cfg config.Config, attributes map[string]string, attestation *attributes.Attestation) WorkerCapabilities {
+	c := WorkerCapabilities{CPUs: runtime.NumCPU(), Memory: memory.TotalMemory(), Attributes: attributes, Attestation: attestation}
+	if limit := uint64(cfg.Worker.MemoryLimitKB) << 10; limit > 0 && limit < c.Memory {
+		c.Memory = limit
+	}
+
+	runtimeCLI := cfg.Worker.RuntimeCLI
+	if runtimeCLI == "" {
+		runtimeCLI = blockless.RuntimeCLI()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, filepath.Join(cfg.Worker.RuntimePath, runtimeCLI), "--version").Output()
+	if err != nil {
+		log.Warn().Err(err).Msg("could not get the runtime version, not advertising it")
+	} else {
+		c.Runtime = strings.TrimSpace(string(out))
+	}
+	return c
+}
+
+// capabilities serves the capabilities of a worker node, and fetches the capabilities of the workers connecting to a
+// head node once they're identified. The fetched capabilities are stored with the peer records.
+type capabilities struct {
+	log          *zerolog.Logger
+	host         libp2phost.Host
+	peers        *lastSeenPeerStore
+	head         bool
+	requirements WorkerRequirements
+
+	mu      sync.Mutex
+	workers map[peer.ID]WorkerCapabilities
+	// executions of a head node by function, see route
+	routes map[string]*functionRoute
+}
+
+// startCapabilities advertises the capabilities of a worker node, or fetches the ones of the workers connecting to a
+// head node until ctx is done.
+func startCapabilities(ctx context.Context, log *zerolog.Logger, h libp2phost.Host, role blockless.NodeRole, cfg config.Config, capabilityCfg CapabilityConfig, peers *lastSeenPeerStore) (*capabilities, error) {
+	c := &capabilities{log: log, host: h, peers: peers, head: role == blockless.HeadNode, requirements: capabilityCfg.Requirements, workers: map[peer.ID]WorkerCapabilities{}, routes: map[string]*functionRoute{}}
+	if role == blockless.WorkerNode {
+		attestation, err := loadAttestation(h.ID(), capabilityCfg.AttestationFile, cfg.LoadAttributes)
+		if err != nil {
+			return nil, err
+		}
+		local := localCapabilities(log, cfg, capabilityCfg.Attributes, attestation)
+		c.serve(local)
+		log.Info().Int("cpus", local.CPUs).Uint64("memory_mb", local.Memory>>20).Str("runtime", local.Runtime).Strs("attributes", attributeList(local.Attributes)).Msg("advertising worker capabilities")
+		if attestation != nil {
+			log.Info().Strs("attributes", attestedAttributeList(*attestation)).Int("attestors", len(attestation.Attestors)).Msg("advertising attested worker attributes")
+		}
+		return c, nil
+	}
+
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		return nil, fmt.Errorf("could not subscribe to peer identification events: %w", err)
+	}
+	go func() {
+		defer reporting.Recover()
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				id := e.(event.EvtPeerIdentificationCompleted).Peer
+				// only the workers advertise their capabilities
+				if supported, err := h.Peerstore().SupportsProtocols(id, capabilitiesProtocol); err == nil && len(supported) > 0 {
+					go c.update(ctx, id)
+				}
+			}
+		}
+	}()
+	if !c.requirements.Empty() {
+		log.Info().Int("min_cpus", c.requirements.MinCPUs).Uint64("min_memory_mb", c.requirements.MinMemory>>20).Str("runtime", c.requirements.Runtime).Strs("attributes", attributeList(c.requirements.Attributes)).Msg("selecting workers on their capabilities")
+	}
+	return c, nil
+}
+
+func (c *capabilities) serve(local WorkerCapabilities) {
+	payload, _ := json.Marshal(local)
+	c.host.SetStreamHandler(capabilitiesProtocol, func(stream network.Stream) {
+		defer stream.Close()
+		stream.SetWriteDeadline(time.Now().Add(capabilitiesTimeout))
+		if _, err := stream.Write(payload); err != nil {
+			c.log.Debug().Err(err).Str("peer", stream.Conn().RemotePeer().String()).Msg("could not send worker capabilities")
+			stream.Reset()
+		}
+	})
+}
+
+// update fetches the capabilities of the worker and stores them.
+func (c *capabilities) update(ctx context.Context, id peer.ID) {
+	defer reporting.Recover()
+	worker, err := c.fetch(ctx, id)
+	if err != nil {
+		c.log.Warn().Err(err).Str("peer", id.String()).Msg("could not get worker capabilities")
+		return
+	}
+	if worker.Attestation != nil {
+		if err := verifyAttestation(id, *worker.Attestation); err != nil {
+			c.log.Warn().Err(err).Str("peer", id.String()).Msg("ignoring attested attributes of worker")
+			worker.Attestation = nil
+		}
+	}
+	c.mu.Lock()
+	c.workers[id] = worker
+	c.mu.Unlock()
+	err = c.peers.update(id, func(record *PeerRecord, found bool) bool {
+		if !found {
+			return false
+		}
+		record.Capabilities = &worker
+		return true
+	})
+	if err != nil {
+		c.log.Error().Err(err).Str("peer", id.String()).Msg("could not store worker capabilities")
+	}
+	c.log.Debug().Str("peer", id.String()).Int("cpus", worker.CPUs).Uint64("memory_mb", worker.Memory>>20).Str("runtime", worker.Runtime).Msg("got worker capabilities")
+}
+
+func (c *capabilities) fetch(ctx context.Context, id peer.ID) (WorkerCapabilities, error) {
+	ctx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+	defer cancel()
+	stream, err := c.host.NewStream(ctx, id, capabilitiesProtocol)
+	if err != nil {
+		return WorkerCapabilities{}, err
+	}
+	defer stream.Close()
+	stream.SetReadDeadline(time.Now().Add(capabilitiesTimeout))
+	payload, err := io.ReadAll(io.LimitReader(stream, maxCapabilitiesSize))
+	if err != nil {
+		return WorkerCapabilities{}, err
+	}
+	var worker WorkerCapabilities
+	if err := json.Unmarshal(payload, &worker); err != nil {
+		return WorkerCapabilities{}, fmt.Errorf("invalid worker capabilities: %w", err)
+	}
+	return worker, nil
+}
+
+// worker returns the capabilities of the worker, if it advertised them since the node started.
+func (c *capabilities) worker(id peer.ID) (WorkerCapabilities, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	worker, ok := c.workers[id]
+	return worker, ok
+}
+
+// eligible returns why the worker may not execute the function for the node, if it may not.
+func (c *capabilities) eligible(id peer.ID, functionID string) error {
+	required := c.required(functionID)
+	if c.requirements.Empty() && required == nil {
+		return nil
+	}
+	worker, ok := c.worker(id)
+	if !ok {
+		return errors.New("the worker did not advertise its capabilities")
+	}
+	if err := c.requirements.Match(worker); err != nil {
+		return err
+	}
+	if required != nil {
+		return matchAttributes(*required, worker)
+	}
+	return nil
+}
+
+// attach returns the host of a head node wrapped to route the executions requiring attributes, and to drop the roll
+// call responses of the workers which don't meet the requirements, so b7s only selects the eligible workers for the
+// executions.
+func (c *capabilities) attach(h libp2phost.Host) libp2phost.Host {
+	if !c.head {
+		return h
+	}
+	return &capabilityHost{Host: h, capabilities: c}
+}
+
+// capabilityHost reads the direct messages ahead of the node handlers, which read a single newline terminated message
+// per stream, to route the execution requests and filter the roll call responses.
+type capabilityHost struct {
+	libp2phost.Host
+	capabilities *capabilities
+}
+
+func (h *capabilityHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		reader := bufio.NewReader(stream)
+		// on error, the handler reads the bytes read so far followed by the error
+		payload, _ := reader.ReadBytes('\n')
+		msg, ok := parseDirectMessage(bytes.TrimSpace(payload))
+		switch {
+		case ok && msg.Type == blockless.MessageRollCallResponse:
+			var res response.RollCall
+			json.Unmarshal(bytes.TrimSpace(payload), &res)
+			from := stream.Conn().RemotePeer()
+			if err := h.capabilities.eligible(from, res.FunctionID); err != nil {
+				h.capabilities.log.Info().Err(err).Str("peer", from.String()).Str("function", res.FunctionID).Msg("skipping roll call response of ineligible worker")
+				stream.Close()
+				return
+			}
+		case ok && msg.Type == blockless.MessageExecute:
+			// b7s handles the execution requests synchronously, so the execution is done once the handler returns
+			var release func()
+			payload, release = h.capabilities.routeMessage(payload)
+			defer release()
+		}
+		handler(&peekedStream{Stream: stream, reader: io.MultiReader(bytes.NewReader(payload), reader)})
+	})
+}
+
+// peekedStream is a stream whose first bytes were read ahead, and are read again.
+type peekedStream struct {
+	network.Stream
+	reader io.Reader
+}
+
+func (s *peekedStream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// attestedAttributeList returns the attributes of the attestation in the name=value format.
+func attestedAttributeList(attestation attributes.Attestation) []string {
+	list := make([]string, 0, len(attestation.Attributes))
+	for _, attribute := range attestation.Attributes {
+		list = append(list, attribute.Name+"="+attribute.Value)
+	}
+	return list
+}
+
+// attributeList returns the attributes in the name=value format, sorted by name.
+func attributeList(attributes map[string]string) []string {
+	list := make([]string, 0, len(attributes))
+	for name, value := range attributes {
+		list = append(list, name+"="+value)
+	}
+	sort.Strings(list)
+	return list
+}
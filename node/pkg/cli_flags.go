@@ -60,6 +60,11 @@ func serverFlags() []cli.Flag {
 			Name:  "topic",
 			Usage: "topics node should subscribe to",
 		},
+		LegacyProtocolUntil,
+		DisableLegacyProtocol,
+		TopicNamespace,
+		RecordMessagesFile,
+		RecordMessagesBuffer,
 
 		// Host configuration.
 		&cli.StringFlag{
@@ -0,0 +1,312 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/codes"
+	"github.com/blocklessnetwork/b7s/models/execute"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	// how often the system load is sampled and the concurrency adjusted
+	concurrencyInterval = 5 * time.Second
+	// the concurrency is lowered above the high load marks, and raised below the low ones while every slot is used;
+	// cpu is the busy fraction of all the cpus, memory the fraction not available
+	highCPULoad    = 0.9
+	lowCPULoad     = 0.7
+	highMemoryLoad = 0.9
+	lowMemoryLoad  = 0.8
+)
+
+// ConcurrencyConfig controls the number of functions a worker node executes at once. By default the executions aren't
+// limited by the node; with Adaptive, the limit follows the cpu load and memory pressure of the system, within Min
+// and Max.
+type ConcurrencyConfig struct {
+	Adaptive bool
+	Min      int
+	Max      int
+}
+
+func ParseConcurrencyFlags(c *cli.Context) (ConcurrencyConfig, error) {
+	cfg := ConcurrencyConfig{
+		Adaptive: c.Bool(AdaptiveConcurrency.Name),
+		Min:      c.Int(MinConcurrency.Name),
+		Max:      c.Int(MaxConcurrency.Name),
+	}
+	if cfg.Min <= 0 {
+		cfg.Min = 1
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = int(c.Uint(Concurrency.Name))
+	}
+	if cfg.Adaptive && cfg.Min > cfg.Max {
+		return cfg, fmt.Errorf("--%s %d is above --%s %d", MinConcurrency.Name, cfg.Min, MaxConcurrency.Name, cfg.Max)
+	}
+	return cfg, nil
+}
+
+// systemLoad is a sample of the load of the system.
+type systemLoad struct {
+	// busy fraction of the cpus since the previous sample
+	cpu float64
+	// fraction of the memory which isn't available
+	memory float64
+}
+
+// loadSampler samples the load of the system from /proc.
+type loadSampler struct {
+	busy, total uint64
+}
+
+func (s *loadSampler) sample() (systemLoad, error) {
+	var load systemLoad
+	busy, total, err := readCPUTimes()
+	if err != nil {
+		return load, err
+	}
+	if total > s.total {
+		load.cpu = float64(busy-min(busy, s.busy)) / float64(total-s.total)
+	}
+	s.busy, s.total = busy, total
+	load.memory, err = readMemoryLoad()
+	return load, err
+}
+
+// readCPUTimes returns the busy and total times of the cpus, in clock ticks, from /proc/stat.
+func readCPUTimes() (busy uint64, total uint64, err error) {
+	stat, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	line, _, _ := bytes.Cut(stat, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, errors.New("unexpected /proc/stat format")
+	}
+	for i, field := range fields[1:] {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("unexpected /proc/stat format: %w", err)
+		}
+		total += v
+		// idle and iowait
+		if i != 3 && i != 4 {
+			busy += v
+		}
+	}
+	return busy, total, nil
+}
+
+// readMemoryLoad returns the fraction of the memory which isn't available, from /proc/meminfo.
+func readMemoryLoad() (float64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var total, available uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total, _ = strconv.ParseUint(fields[1], 10, 64)
+		case "MemAvailable:":
+			available, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	if total == 0 || available > total {
+		return 0, errors.New("unexpected /proc/meminfo format")
+	}
+	return 1 - float64(available)/float64(total), nil
+}
+
+// adaptiveConcurrency limits the executions of a worker node to a number of slots adjusted to the system load: lowered
+// by a quarter above the high load marks, and raised by one below the low ones while every slot is used. The
+// executions wait for a free slot, and the worker declines the roll calls while none is.
+type adaptiveConcurrency struct {
+	log     *zerolog.Logger
+	cfg     ConcurrencyConfig
+	metrics *metrics.NodeMetrics
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	running int
+}
+
+func newAdaptiveConcurrency(log *zerolog.Logger, cfg ConcurrencyConfig, metrics *metrics.NodeMetrics) *adaptiveConcurrency {
+	a := &adaptiveConcurrency{log: log, cfg: cfg, metrics: metrics, limit: min(max(cfg.Min, runtime.NumCPU()), cfg.Max)}
+	a.cond = sync.NewCond(&a.mu)
+	metrics.SetExecutionConcurrency(a.limit)
+	return a
+}
+
+// run adjusts the limit to the system load until ctx is cancelled.
+func (a *adaptiveConcurrency) run(ctx context.Context) {
+	defer reporting.Recover()
+	var sampler loadSampler
+	// the first cpu sample is relative to the boot
+	if _, err := sampler.sample(); err != nil {
+		a.log.Error().Err(err).Int("concurrency", a.limit).Msg("could not read system load, concurrency is not adjusted")
+		return
+	}
+	ticker := time.NewTicker(concurrencyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		load, err := sampler.sample()
+		if err != nil {
+			a.log.Warn().Err(err).Msg("could not read system load")
+			continue
+		}
+		a.adjust(load)
+	}
+}
+
+func (a *adaptiveConcurrency) adjust(load systemLoad) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	limit := a.limit
+	switch {
+	case load.cpu > highCPULoad || load.memory > highMemoryLoad:
+		limit = max(a.cfg.Min, limit-max(1, limit/4))
+	case load.cpu < lowCPULoad && load.memory < lowMemoryLoad && a.running >= a.limit:
+		limit = min(a.cfg.Max, limit+1)
+	}
+	if limit == a.limit {
+		return
+	}
+	a.log.Debug().Int("from", a.limit).Int("to", limit).Float64("cpu", load.cpu).Float64("memory", load.memory).Int("running", a.running).Msg("adjusted execution concurrency")
+	a.limit = limit
+	a.metrics.SetExecutionConcurrency(limit)
+	a.cond.Broadcast()
+}
+
+// acquire waits for a free execution slot.
+func (a *adaptiveConcurrency) acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.running >= a.limit {
+		a.cond.Wait()
+	}
+	a.running++
+}
+
+func (a *adaptiveConcurrency) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running--
+	a.cond.Signal()
+}
+
+// saturated reports whether every execution slot is used.
+func (a *adaptiveConcurrency) saturated() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.running >= a.limit
+}
+
+// executor returns the executor limited to the execution slots.
+func (a *adaptiveConcurrency) executor(executor blockless.Executor) blockless.Executor {
+	return &limitedExecutor{Executor: executor, concurrency: a}
+}
+
+// attach returns the host wrapped to decline the roll calls while every execution slot is used.
+func (a *adaptiveConcurrency) attach(h libp2phost.Host) libp2phost.Host {
+	return &concurrencyHost{Host: h, concurrency: a}
+}
+
+// limitedExecutor runs the executions in the slots of the adaptive concurrency.
+type limitedExecutor struct {
+	blockless.Executor
+	concurrency *adaptiveConcurrency
+}
+
+func (e *limitedExecutor) ExecuteFunction(requestID string, req execute.Request) (execute.Result, error) {
+	e.concurrency.acquire()
+	defer e.concurrency.release()
+	return e.Executor.ExecuteFunction(requestID, req)
+}
+
+// concurrencyHost answers the roll calls with NotAvailable instead of Accepted while every execution slot is used,
+// so the head nodes select other workers.
+type concurrencyHost struct {
+	libp2phost.Host
+	concurrency *adaptiveConcurrency
+}
+
+func (h *concurrencyHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil || stream.Protocol() != blockless.ProtocolID {
+		return stream, err
+	}
+	return &concurrencyStream{Stream: stream, concurrency: h.concurrency}, nil
+}
+
+// concurrencyStream rewrites the roll call response written, b7s writing its messages at once.
+type concurrencyStream struct {
+	network.Stream
+	concurrency *adaptiveConcurrency
+}
+
+func (s *concurrencyStream) Write(p []byte) (int, error) {
+	msg, ok := parseDirectMessage(bytes.TrimSpace(p))
+	if !ok || msg.Type != blockless.MessageRollCallResponse || msg.Code != codes.Accepted || !s.concurrency.saturated() {
+		return s.Stream.Write(p)
+	}
+	declined, ok := declineRollCall(p)
+	if !ok {
+		return s.Stream.Write(p)
+	}
+	if _, err := s.Stream.Write(declined); err != nil {
+		return 0, err
+	}
+	s.concurrency.log.Debug().Msg("declined roll call, every execution slot is used")
+	return len(p), nil
+}
+
+// declineRollCall returns the roll call response with the NotAvailable code, keeping its other fields.
+func declineRollCall(payload []byte) ([]byte, bool) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, false
+	}
+	msg["code"], _ = json.Marshal(codes.NotAvailable)
+	out, err := json.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	if bytes.HasSuffix(payload, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out, true
+}
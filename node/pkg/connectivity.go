@@ -0,0 +1,245 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/config"
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
+	quic "github.com/libp2p/go-libp2p/p2p/transport/quic"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	"github.com/libp2p/go-libp2p/p2p/transport/websocket"
+	webtransport "github.com/libp2p/go-libp2p/p2p/transport/webtransport"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/urfave/cli/v2"
+)
+
+// ConnectivityConfig configures the libp2p host beyond the b7s connectivity settings, which b7s creates the host from.
+//
+// NAT traversal is for the nodes which aren't dialable, typically workers behind NAT. The host always runs an AutoNAT
+// client, finding out whether the node is reachable from the AutoNAT servers among its peers, and can dial and listen
+// through circuit relays.
+type ConnectivityConfig struct {
+	// answer the AutoNAT dial-back requests of the peers, so they find out whether they're reachable; suits the
+	// publicly reachable nodes
+	AutoNAT bool
+	// upgrade the relayed connections to direct ones with DCUtR hole punching
+	HolePunching bool
+	// multiaddrs of circuit relays on which the node reserves a slot, and advertises the relayed addresses, when it
+	// isn't reachable
+	Relays []string
+	// listen for QUIC connections on the UDP port QUICPort (0 for a random one), besides TCP and websocket
+	QUIC     bool
+	QUICPort uint
+	// external UDP port advertised with the dialback address (default QUICPort)
+	QUICDialbackPort uint
+	// multiaddrs the host listens on besides the b7s address and ports, eg. an IPv6 address or another interface
+	ListenAddresses []string
+	// multiaddrs advertised to the peers instead of the listen and dialback ones, eg. the ones of a load balancer
+	AnnounceAddresses []string
+	// the connections of the least valuable peers are closed once there are more than ConnHighWater, until
+	// ConnLowWater are left; new connections are kept for ConnGracePeriod. Disabled when ConnHighWater is 0.
+	ConnLowWater    int
+	ConnHighWater   int
+	ConnGracePeriod time.Duration
+	// peer ids and CIDRs of the peers allowed to connect, all of them when empty, and of the peers denied, which wins
+	AllowPeers []string
+	DenyPeers  []string
+	// multiaddrs of the peers kept connected, reconnected whenever they disconnect and never trimmed
+	StaticPeers []string
+	// pre-shared key file of the private network to join, whose nodes only connect to each other
+	PrivateNetworkKey string
+	// KiB per second received from and sent to each peer on the node protocols, unlimited when 0
+	PeerBandwidthLimit uint
+	// serve the websocket transport over TLS (wss), with the certificate and key files, reloaded when they change, or
+	// with the certificates of the autocert domains obtained from Let's Encrypt and cached in WebsocketAutocertCache
+	// (default <workspace>/autocert)
+	WebsocketTLSCert       string
+	WebsocketTLSKey        string
+	WebsocketAutocert      []string
+	WebsocketAutocertCache string
+}
+
+func ParseConnectivityFlags(c *cli.Context) ConnectivityConfig {
+	return ConnectivityConfig{
+		AutoNAT:                c.Bool(AutoNAT.Name),
+		HolePunching:           c.Bool(HolePunching.Name),
+		Relays:                 c.StringSlice(Relays.Name),
+		QUIC:                   c.Bool(QUIC.Name),
+		QUICPort:               c.Uint(QUICPort.Name),
+		QUICDialbackPort:       c.Uint(QUICDialbackPort.Name),
+		ListenAddresses:        c.StringSlice(ListenAddresses.Name),
+		AnnounceAddresses:      c.StringSlice(AnnounceAddresses.Name),
+		ConnLowWater:           c.Int(ConnLowWater.Name),
+		ConnHighWater:          c.Int(ConnHighWater.Name),
+		ConnGracePeriod:        c.Duration(ConnGracePeriod.Name),
+		AllowPeers:             c.StringSlice(AllowPeers.Name),
+		DenyPeers:              c.StringSlice(DenyPeers.Name),
+		StaticPeers:            c.StringSlice(StaticPeers.Name),
+		PrivateNetworkKey:      c.String(PrivateNetworkKey.Name),
+		PeerBandwidthLimit:     c.Uint(PeerBandwidthLimit.Name),
+		WebsocketTLSCert:       c.String(WebsocketTLSCert.Name),
+		WebsocketTLSKey:        c.String(WebsocketTLSKey.Name),
+		WebsocketAutocert:      c.StringSlice(WebsocketAutocert.Name),
+		WebsocketAutocertCache: c.String(WebsocketAutocertCache.Name),
+	}
+}
+
+// listenQUIC makes the host listen for QUIC connections, the QUIC transport being enabled by b7s along with the TCP
+// and websocket ones it listens on. With a dialback address the host only advertises its external addresses, to which
+// the QUIC one is added.
+func listenQUIC(h host.Host, connectivity config.Connectivity, cfg ConnectivityConfig) error {
+	addr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/udp/%d/quic-v1", connectivity.Address, cfg.QUICPort))
+	if err != nil {
+		return fmt.Errorf("could not create QUIC multiaddress: %w", err)
+	}
+	if err := h.Network().Listen(addr); err != nil {
+		return err
+	}
+
+	// b7s advertises the external addresses only when both the dialback address and port are set
+	if connectivity.DialbackAddress == "" || connectivity.DialbackPort == 0 {
+		return nil
+	}
+	port := cfg.QUICDialbackPort
+	if port == 0 {
+		port = cfg.QUICPort
+	}
+	if port == 0 {
+		return errors.New("the QUIC port must be set to advertise it with the dialback address")
+	}
+	protocol := "dns"
+	if ip := net.ParseIP(connectivity.DialbackAddress); ip != nil {
+		protocol = "ip6"
+		if ip.To4() != nil {
+			protocol = "ip4"
+		}
+	}
+	external, err := multiaddr.NewMultiaddr(fmt.Sprintf("/%s/%s/udp/%d/quic-v1", protocol, connectivity.DialbackAddress, port))
+	if err != nil {
+		return fmt.Errorf("could not create external QUIC multiaddress: %w", err)
+	}
+	basic, ok := h.(*basichost.BasicHost)
+	if !ok {
+		return fmt.Errorf("advertising the QUIC address is not supported by host %T", h)
+	}
+	addrsFactory := basic.AddrsFactory
+	basic.AddrsFactory = func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+		// b7s returns the same slice of external addresses on every call
+		return append(slices.Clip(addrsFactory(addrs)), external)
+	}
+	return nil
+}
+
+// listenAddresses makes the host listen on the extra listen addresses of the config, the websocket ones over TLS
+// when the host serves it, and advertise the announce addresses, if any, instead of the ones it would.
+func listenAddresses(h host.Host, cfg ConnectivityConfig, websocketTLS *tls.Config) error {
+	listen, err := getBootNodeAddresses(cfg.ListenAddresses)
+	if err != nil {
+		return fmt.Errorf("invalid listen address: %w", err)
+	}
+	if websocketTLS != nil {
+		for i, addr := range listen {
+			listen[i] = secureWebsocketAddr(addr)
+		}
+	}
+	if len(listen) > 0 {
+		if err := h.Network().Listen(listen...); err != nil {
+			return fmt.Errorf("could not listen on %v: %w", listen, err)
+		}
+	}
+
+	if len(cfg.AnnounceAddresses) == 0 {
+		return nil
+	}
+	announce, err := getBootNodeAddresses(cfg.AnnounceAddresses)
+	if err != nil {
+		return fmt.Errorf("invalid announce address: %w", err)
+	}
+	for i, addr := range announce {
+		// the peer id is added by the peers
+		transport, id := peer.SplitAddr(addr)
+		if transport == nil || (id != "" && id != h.ID()) {
+			return fmt.Errorf("invalid announce address %s: not an address of the node", addr)
+		}
+		announce[i] = transport
+	}
+	basic, ok := h.(*basichost.BasicHost)
+	if !ok {
+		return fmt.Errorf("announcing addresses is not supported by host %T", h)
+	}
+	basic.AddrsFactory = func([]multiaddr.Multiaddr) []multiaddr.Multiaddr {
+		return slices.Clone(announce)
+	}
+	return nil
+}
+
+// rebuildHost replaces the libp2p host created by b7s with one of the same identity, listen addresses and advertised
+// addresses, for what b7s doesn't support: joining the private network of the pre-shared key, whose hosts only
+// connect to the nodes holding it, and serving the websocket transport over TLS, the websocket addresses becoming wss
+// ones. The host created by b7s is closed.
+func rebuildHost(h host.Host, psk pnet.PSK, websocketTLS *tls.Config) (host.Host, error) {
+	basic, ok := h.(*basichost.BasicHost)
+	if !ok {
+		return nil, fmt.Errorf("recreating host %T is not supported", h)
+	}
+	key := h.Peerstore().PrivKey(h.ID())
+	// the listen addresses are resolved, so the new host listens on the ports b7s picked; the relay transport
+	// listens on its own
+	var listenAddrs []multiaddr.Multiaddr
+	for _, addr := range h.Network().ListenAddresses() {
+		if _, err := addr.ValueForProtocol(multiaddr.P_CIRCUIT); err != nil {
+			listenAddrs = append(listenAddrs, addr)
+		}
+	}
+	addrsFactory := basic.AddrsFactory
+	ws := libp2p.Transport(websocket.New)
+	if websocketTLS != nil {
+		for i, addr := range listenAddrs {
+			listenAddrs[i] = secureWebsocketAddr(addr)
+		}
+		factory := addrsFactory
+		addrsFactory = func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+			// b7s returns the same slice of external addresses on every call
+			advertised := slices.Clone(factory(addrs))
+			for i, addr := range advertised {
+				advertised[i] = secureWebsocketAddr(addr)
+			}
+			return advertised
+		}
+		ws = libp2p.Transport(websocket.New, websocket.WithTLSConfig(websocketTLS))
+	}
+	if err := h.Close(); err != nil {
+		return nil, fmt.Errorf("could not close host: %w", err)
+	}
+
+	opts := []libp2p.Option{
+		libp2p.Identity(key),
+		libp2p.ListenAddrs(listenAddrs...),
+		libp2p.Transport(tcp.NewTCPTransport),
+		ws,
+		libp2p.DefaultMuxers,
+		libp2p.DefaultSecurity,
+		libp2p.NATPortMap(),
+		libp2p.AddrsFactory(addrsFactory),
+	}
+	if psk != nil {
+		// QUIC and WebTransport don't support private networks
+		opts = append(opts, libp2p.PrivateNetwork(psk))
+	} else {
+		opts = append(opts, libp2p.Transport(quic.NewTransport), libp2p.Transport(webtransport.New))
+	}
+	rebuilt, err := libp2p.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not recreate host: %w", err)
+	}
+	return rebuilt, nil
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	// default time a new connection is kept before it may be trimmed
+	defaultConnGracePeriod = time.Minute
+	// interval of checking the number of connections against the high watermark
+	connTrimInterval = 10 * time.Second
+)
+
+// connTrimmer closes the connections of the least valuable peers once the host has more connections than the high
+// watermark, until it's down to the low one. b7s creates the host with the default libp2p connection manager, trimming
+// above 192 connections, which can't be replaced; its peer tags and protections, set by the protocols such as pubsub
+// and the DHT, rank the peers.
+type connTrimmer struct {
+	log     *zerolog.Logger
+	host    host.Host
+	low     int
+	high    int
+	grace   time.Duration
+	metrics *metrics.NodeMetrics
+}
+
+func newConnTrimmer(log *zerolog.Logger, h host.Host, cfg ConnectivityConfig, m *metrics.NodeMetrics) (*connTrimmer, error) {
+	if cfg.ConnLowWater >= cfg.ConnHighWater {
+		return nil, errors.New("the connection low watermark must be lower than the high watermark")
+	}
+	grace := cfg.ConnGracePeriod
+	if grace == 0 {
+		grace = defaultConnGracePeriod
+	}
+	return &connTrimmer{
+		log:     log,
+		host:    h,
+		low:     cfg.ConnLowWater,
+		high:    cfg.ConnHighWater,
+		grace:   grace,
+		metrics: m,
+	}, nil
+}
+
+// run trims the connections every connTrimInterval, until ctx is done.
+func (t *connTrimmer) run(ctx context.Context) {
+	defer reporting.Recover()
+	t.log.Info().Int("low_water", t.low).Int("high_water", t.high).Dur("grace_period", t.grace).Msg("started connection manager")
+	ticker := time.NewTicker(connTrimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if len(t.host.Network().Conns()) > t.high {
+			t.trim()
+		}
+	}
+}
+
+// trim closes the connections of the unprotected peers connected for longer than the grace period, lowest tag value
+// first, until the host is down to the low watermark.
+func (t *connTrimmer) trim() {
+	conns := t.host.Network().Conns()
+	cm := t.host.ConnManager()
+	graceStart := time.Now().Add(-t.grace)
+
+	type candidate struct {
+		peer  peer.ID
+		value int
+		conns []network.Conn
+	}
+	byPeer := map[peer.ID]*candidate{}
+	var candidates []*candidate
+	for _, conn := range conns {
+		id := conn.RemotePeer()
+		if cm.IsProtected(id, "") || conn.Stat().Opened.After(graceStart) {
+			continue
+		}
+		c, ok := byPeer[id]
+		if !ok {
+			c = &candidate{peer: id}
+			if info := cm.GetTagInfo(id); info != nil {
+				c.value = info.Value
+			}
+			byPeer[id] = c
+			candidates = append(candidates, c)
+		}
+		c.conns = append(c.conns, conn)
+	}
+	slices.SortStableFunc(candidates, func(a, b *candidate) int { return a.value - b.value })
+
+	excess := len(conns) - t.low
+	closed := 0
+	for _, c := range candidates {
+		if closed >= excess {
+			break
+		}
+		for _, conn := range c.conns {
+			if err := conn.Close(); err != nil {
+				t.log.Debug().Err(err).Str("peer", c.peer.String()).Msg("could not close connection")
+				continue
+			}
+			closed++
+		}
+	}
+	t.metrics.AddConnectionTrim(closed)
+	t.log.Info().Int("connections", len(conns)).Int("closed", closed).Msg("trimmed connections")
+}
@@ -1,7 +1,14 @@
 package pkg
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/cockroachdb/pebble"
 )
@@ -14,3 +21,174 @@ func InitializeDB(path string) *pebble.DB {
 	}
 	return db
 }
+
+// ErrDBExists is returned when restoring over an existing database without replacing it.
+var ErrDBExists = errors.New("database already exists")
+
+// openExistingDB opens the pebble database at path, which must exist. The database is locked by the running node, so it
+// must be stopped first.
+func openExistingDB(path string, readOnly bool) (*pebble.DB, error) {
+	db, err := pebble.Open(path, &pebble.Options{Logger: &PebbleNoopLogger{}, ErrorIfNotExists: true, ReadOnly: readOnly})
+	if errors.Is(err, pebble.ErrDBDoesNotExist) {
+		return nil, fmt.Errorf("no pebble database at %s: %w", path, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open pebble database (path: %s), is the node stopped?: %w", path, err)
+	}
+	return db, nil
+}
+
+// DBSize returns the size of the files of the pebble database at path, including its write-ahead log, in bytes.
+func DBSize(path string) (uint64, error) {
+	var size uint64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += uint64(info.Size())
+		return nil
+	})
+	return size, err
+}
+
+// BackupDB writes a consistent snapshot of the pebble database at path to dest, which must not exist. The snapshot is
+// a pebble database itself, hard linking the files of the database when possible.
+func BackupDB(path, dest string) error {
+	db, err := openExistingDB(path, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := db.Checkpoint(dest, pebble.WithFlushedWAL()); err != nil {
+		return fmt.Errorf("could not snapshot pebble database %s: %w", path, err)
+	}
+	return nil
+}
+
+// RestoreDB replaces the pebble database at path with a copy of the snapshot, a pebble database written by BackupDB.
+// An existing database is only replaced if replace is set, once the snapshot was copied next to it.
+func RestoreDB(snapshot, path string, replace bool) error {
+	_, err := os.Stat(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+	if exists && !replace {
+		return fmt.Errorf("%w at %s", ErrDBExists, path)
+	}
+	// checks the snapshot is a pebble database before touching the current one
+	db, err := openExistingDB(snapshot, true)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	db.Close()
+
+	restoring := fmt.Sprintf("%s.restoring-%d", path, time.Now().Unix())
+	if err := copyDir(snapshot, restoring); err != nil {
+		os.RemoveAll(restoring)
+		return fmt.Errorf("could not copy snapshot %s: %w", snapshot, err)
+	}
+	if exists {
+		// the database is locked while the node runs
+		db, err := openExistingDB(path, false)
+		if err != nil && !errors.Is(err, pebble.ErrDBDoesNotExist) {
+			os.RemoveAll(restoring)
+			return err
+		}
+		if err == nil {
+			db.Close()
+		}
+	}
+	replaced := path + ".replaced"
+	if err := os.RemoveAll(replaced); err != nil {
+		return err
+	}
+	if err := os.Rename(path, replaced); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		os.RemoveAll(restoring)
+		return fmt.Errorf("could not move %s aside: %w", path, err)
+	}
+	if err := os.Rename(restoring, path); err != nil {
+		// puts the previous database back
+		os.Rename(replaced, path)
+		os.RemoveAll(restoring)
+		return fmt.Errorf("could not restore %s: %w", path, err)
+	}
+	return os.RemoveAll(replaced)
+}
+
+// CompactDB compacts the whole key range of the pebble database at path, dropping the deleted and overwritten records.
+func CompactDB(path string) error {
+	db, err := openExistingDB(path, false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	var first, last []byte
+	if iter.First() {
+		first = append(first, iter.Key()...)
+	}
+	if iter.Last() {
+		last = append(last, iter.Key()...)
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	if first == nil {
+		return nil
+	}
+	// the end of the range is exclusive
+	if err := db.Compact(first, append(last, 0), true); err != nil {
+		return fmt.Errorf("could not compact pebble database %s: %w", path, err)
+	}
+	return nil
+}
+
+// copyDir copies the regular files of the directory src to dst, which must not exist.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if rel == "." {
+				return os.Mkdir(target, 0o755)
+			}
+			return os.MkdirAll(target, 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", path)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
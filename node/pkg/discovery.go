@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+	"github.com/libp2p/go-libp2p/p2p/discovery/routing"
+	"github.com/libp2p/go-libp2p/p2p/discovery/util"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+)
+
+// default mDNS service name the nodes advertise and look for
+const defaultMdnsService = "blockless-avs"
+
+const (
+	// default protocol prefix of the DHT joined with --dht, kept apart from the public ipfs DHT
+	defaultDhtProtocolPrefix = "/blockless-avs"
+	// default namespace under which the nodes advertise their role in the DHT
+	defaultDhtNamespace = "blockless-avs"
+)
+
+const (
+	// timeout of connecting to a peer found with mDNS or in the DHT
+	discoveredPeerConnectTimeout = 10 * time.Second
+	// the DHT bootstrap is retried with an exponential backoff between these delays until the routing table holds peers
+	dhtBootstrapMinBackoff = time.Second
+	dhtBootstrapMaxBackoff = time.Minute
+	// interval of looking up the advertised nodes in the DHT
+	dhtDiscoveryInterval = 30 * time.Second
+)
+
+// DiscoveryConfig configures how the node finds peers besides the boot nodes and dial-back peers.
+type DiscoveryConfig struct {
+	// find the nodes of the local network with mDNS
+	Mdns bool
+	// mDNS service name; only the nodes advertising the same name find each other
+	MdnsService string
+	// join a Kademlia DHT through the boot nodes, advertise the node role in it and connect to the nodes found there
+	Dht bool
+	// protocol prefix of the DHT; only the nodes using the same prefix share a DHT
+	DhtProtocolPrefix string
+	// namespace under which the nodes advertise their role, <namespace>/<role>
+	DhtNamespace string
+}
+
+func ParseDiscoveryFlags(c *cli.Context) DiscoveryConfig {
+	cfg := DiscoveryConfig{
+		Mdns:              c.Bool(Mdns.Name),
+		MdnsService:       c.String(MdnsService.Name),
+		Dht:               c.Bool(Dht.Name),
+		DhtProtocolPrefix: c.String(DhtProtocolPrefix.Name),
+		DhtNamespace:      c.String(DhtNamespace.Name),
+	}
+	if cfg.MdnsService == "" {
+		cfg.MdnsService = defaultMdnsService
+	}
+	if cfg.DhtProtocolPrefix == "" {
+		cfg.DhtProtocolPrefix = defaultDhtProtocolPrefix
+	}
+	if cfg.DhtNamespace == "" {
+		cfg.DhtNamespace = defaultDhtNamespace
+	}
+	return cfg
+}
+
+// mdnsNotifee connects to the peers found with mDNS.
+type mdnsNotifee struct {
+	ctx  context.Context
+	log  *zerolog.Logger
+	host host.Host
+}
+
+func (m *mdnsNotifee) HandlePeerFound(info peer.AddrInfo) {
+	if info.ID == m.host.ID() || len(m.host.Network().ConnsToPeer(info.ID)) > 0 {
+		return
+	}
+	// called from the mDNS resolver loop, which mustn't wait for the connection
+	go func() {
+		ctx, cancel := context.WithTimeout(m.ctx, discoveredPeerConnectTimeout)
+		defer cancel()
+		if err := m.host.Connect(ctx, info); err != nil {
+			m.log.Debug().Err(err).Str("peer", info.ID.String()).Msg("could not connect to peer found with mDNS")
+			return
+		}
+		m.log.Info().Str("peer", info.ID.String()).Msg("connected to peer found with mDNS")
+	}()
+}
+
+// startMdns advertises the node on the local network and connects to the nodes it finds there, until the returned
+// service is closed.
+func startMdns(ctx context.Context, log *zerolog.Logger, h host.Host, serviceName string) (mdns.Service, error) {
+	service := mdns.NewMdnsService(h, serviceName, &mdnsNotifee{ctx: ctx, log: log, host: h})
+	if err := service.Start(); err != nil {
+		return nil, err
+	}
+	log.Info().Str("service", serviceName).Msg("started mDNS peer discovery")
+	return service, nil
+}
+
+// startDht joins the DHT of the discovery config, in server mode like b7s, and starts bootstrapping it from the boot
+// nodes in the background; the node then advertises its role and connects to the nodes of both roles found in the DHT,
+// until ctx is done. The returned DHT must be closed once ctx is done.
+func startDht(ctx context.Context, log *zerolog.Logger, h host.Host, cfg DiscoveryConfig, role string, bootNodes []multiaddr.Multiaddr) (*dht.IpfsDHT, error) {
+	bootPeers, err := peer.AddrInfosFromP2pAddrs(bootNodes...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid boot node address: %w", err)
+	}
+	kad, err := dht.New(ctx, h,
+		dht.Mode(dht.ModeServer),
+		dht.ProtocolPrefix(protocol.ID(cfg.DhtProtocolPrefix)),
+		dht.BootstrapPeers(bootPeers...),
+	)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().
+		Str("protocol_prefix", cfg.DhtProtocolPrefix).
+		Str("namespace", cfg.DhtNamespace).
+		Int("boot_nodes", len(bootPeers)).
+		Msg("joining DHT")
+
+	go func() {
+		if !bootstrapDht(ctx, log, h, kad, bootPeers) {
+			return
+		}
+		discovery := routing.NewRoutingDiscovery(kad)
+		util.Advertise(ctx, discovery, dhtRoleNamespace(cfg.DhtNamespace, role))
+		discoverDhtPeers(ctx, log, h, discovery, cfg.DhtNamespace)
+	}()
+	return kad, nil
+}
+
+// bootstrapDht connects to the boot nodes and bootstraps the DHT, retrying with an exponential backoff until its
+// routing table holds peers, since the boot nodes may start after the node. It returns false once ctx is done.
+func bootstrapDht(ctx context.Context, log *zerolog.Logger, h host.Host, kad *dht.IpfsDHT, bootPeers []peer.AddrInfo) bool {
+	backoff := dhtBootstrapMinBackoff
+	for attempt := 1; ; attempt++ {
+		for _, info := range bootPeers {
+			if len(h.Network().ConnsToPeer(info.ID)) > 0 {
+				continue
+			}
+			connectCtx, cancel := context.WithTimeout(ctx, discoveredPeerConnectTimeout)
+			if err := h.Connect(connectCtx, info); err != nil {
+				log.Debug().Err(err).Str("peer", info.ID.String()).Msg("could not connect to DHT boot node")
+			}
+			cancel()
+		}
+		if err := kad.Bootstrap(ctx); err != nil {
+			log.Debug().Err(err).Msg("could not bootstrap the DHT")
+		}
+		// the routing table is filled as the connected peers are identified
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		if size := kad.RoutingTable().Size(); size > 0 {
+			log.Info().Int("peers", size).Int("attempts", attempt).Msg("bootstrapped DHT")
+			return true
+		}
+		log.Warn().Int("attempt", attempt).Dur("retry_in", backoff).Msg("DHT routing table is empty, retrying the bootstrap")
+		backoff = min(2*backoff, dhtBootstrapMaxBackoff)
+	}
+}
+
+// discoverDhtPeers looks up the nodes of both roles advertised in the DHT every dhtDiscoveryInterval and connects to
+// those the node isn't connected to, until ctx is done.
+func discoverDhtPeers(ctx context.Context, log *zerolog.Logger, h host.Host, discovery *routing.RoutingDiscovery, namespace string) {
+	ticker := time.NewTicker(dhtDiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		for _, role := range []string{blockless.HeadNodeLabel, blockless.WorkerNodeLabel} {
+			peers, err := util.FindPeers(ctx, discovery, dhtRoleNamespace(namespace, role))
+			if err != nil {
+				log.Debug().Err(err).Str("role", role).Msg("could not look up peers in the DHT")
+				continue
+			}
+			for _, info := range peers {
+				if info.ID == h.ID() || len(info.Addrs) == 0 || len(h.Network().ConnsToPeer(info.ID)) > 0 {
+					continue
+				}
+				connectCtx, cancel := context.WithTimeout(ctx, discoveredPeerConnectTimeout)
+				err := h.Connect(connectCtx, info)
+				cancel()
+				if err != nil {
+					log.Debug().Err(err).Str("peer", info.ID.String()).Msg("could not connect to peer found in the DHT")
+					continue
+				}
+				log.Info().Str("peer", info.ID.String()).Str("role", role).Msg("connected to peer found in the DHT")
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dhtRoleNamespace is the DHT key under which the nodes of a role advertise themselves.
+func dhtRoleNamespace(namespace, role string) string {
+	return namespace + "/" + role
+}
@@ -1,10 +1,16 @@
 package pkg
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/blocklessnetwork/b7s/config"
 	"github.com/blocklessnetwork/b7s/models/blockless"
 	"github.com/blocklessnetwork/b7s/node"
 	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/types"
 )
 
 // Default values.
@@ -21,67 +27,73 @@ const (
 
 var (
 	Role = &cli.StringFlag{
-		Name:       "role",
-		Required:   true,
-		Usage:      "role this note will have in the Blockless protocol (head or worker)",
-		Value:      blockless.HeadNodeLabel,
-		HasBeenSet: true,
+		Name:  "role",
+		Usage: "role this note will have in the Blockless protocol (head or worker)",
+		Value: blockless.HeadNodeLabel,
 	}
 	PeerDatabasePath = &cli.StringFlag{
-		Name:       "peer-db",
-		Required:   true,
-		Usage:      "path to the database used for persisting peer data",
-		Value:      defaultPeerDB,
-		HasBeenSet: true,
+		Name:  "peer-db",
+		Usage: "path to the database used for persisting peer data",
+		Value: defaultPeerDB,
 	}
 	FunctionDatabasePath = &cli.StringFlag{
-		Name:       "function-db",
-		Required:   true,
-		Usage:      "path to the database used for persisting function data",
-		Value:      defaultFunctionDB,
-		HasBeenSet: true,
+		Name:  "function-db",
+		Usage: "path to the database used for persisting function data",
+		Value: defaultFunctionDB,
 	}
 	Workspace = &cli.StringFlag{
-		Name:       "workspace",
-		Required:   true,
-		Usage:      "directory that the node can use for file storage",
-		Value:      defaultWorkspace,
-		HasBeenSet: true,
+		Name:  "workspace",
+		Usage: "directory that the node can use for file storage",
+		Value: defaultWorkspace,
 	}
 	Concurrency = &cli.UintFlag{
-		Name:       "concurrency",
-		Required:   true,
-		Usage:      "maximum number of requests node will process in parallel",
-		Value:      defaultConcurrency,
-		HasBeenSet: true,
+		Name:  "concurrency",
+		Usage: "maximum number of requests node will process in parallel",
+		Value: defaultConcurrency,
 	}
 	LoadAttributes = &cli.BoolFlag{
-		Name:       "attributes",
-		Required:   true,
-		Usage:      "node should try to load its attribute data from IPFS",
-		Value:      false,
-		HasBeenSet: true,
+		Name:  "attributes",
+		Usage: "node should try to load its attribute data from IPFS",
+		Value: false,
+	}
+	AttributesFile = &cli.StringFlag{
+		Name:  "attributes-file",
+		Usage: "attributes attestation file (b7s attributes format) a worker node advertises to the head nodes, which select the workers of the executions requiring attributes on it, instead of loading it from IPFS with --attributes",
 	}
 	PrivateKey = &cli.StringFlag{
-		Name:       "private-key",
-		Required:   true,
-		Usage:      "private key that the b7s host will use",
-		Value:      "",   // TODO
-		HasBeenSet: true, // TODO
+		Name:  "private-key",
+		Usage: "private key that the b7s host will use",
+		Value: "", // TODO
+	}
+	Mdns = &cli.BoolFlag{
+		Name:  "mdns",
+		Usage: "find the nodes of the local network with mDNS, without configuring boot nodes (development and edge deployments)",
+	}
+	MdnsService = &cli.StringFlag{
+		Name:  "mdns-service",
+		Usage: "mDNS service name advertised with --mdns; only the nodes using the same name find each other (default " + defaultMdnsService + ")",
+	}
+	Dht = &cli.BoolFlag{
+		Name:  "dht",
+		Usage: "join a Kademlia DHT through the boot nodes, advertise the node role in it and connect to the nodes found there",
+	}
+	DhtProtocolPrefix = &cli.StringFlag{
+		Name:  "dht-protocol-prefix",
+		Usage: "protocol prefix of the DHT joined with --dht; only the nodes using the same prefix share a DHT (default " + defaultDhtProtocolPrefix + ")",
+	}
+	DhtNamespace = &cli.StringFlag{
+		Name:  "dht-namespace",
+		Usage: "namespace under which the nodes advertise their role in the DHT, as <namespace>/<role> (default " + defaultDhtNamespace + ")",
 	}
 	HostAddress = &cli.StringFlag{
-		Name:       "address",
-		Required:   true,
-		Usage:      "address that the b7s host will use",
-		Value:      defaultAddress,
-		HasBeenSet: true,
+		Name:  "address",
+		Usage: "address that the b7s host will use",
+		Value: defaultAddress,
 	}
 	HostPort = &cli.UintFlag{
-		Name:       "port",
-		Required:   true,
-		Usage:      "port that the b7s host will use",
-		Value:      defaultPort,
-		HasBeenSet: true,
+		Name:  "port",
+		Usage: "port that the b7s host will use",
+		Value: defaultPort,
 	}
 	BootNodes = &cli.StringSliceFlag{
 		Name: "boot-nodes",
@@ -111,6 +123,174 @@ var (
 		// Value:      defaultPort,
 		// HasBeenSet: true,
 	}
+	AutoNAT = &cli.BoolFlag{
+		Name:  "autonat",
+		Usage: "answer the AutoNAT requests of the peers finding out whether they're reachable (publicly reachable nodes)",
+	}
+	HolePunching = &cli.BoolFlag{
+		Name:  "hole-punching",
+		Usage: "upgrade the connections relayed to or from the nodes behind NAT to direct ones with DCUtR hole punching",
+	}
+	Relays = &cli.StringSliceFlag{
+		Name:  "relays",
+		Usage: "list of circuit relays, in multiaddr format, through which the node is reachable when behind NAT",
+	}
+	QUIC = &cli.BoolFlag{
+		Name:  "quic",
+		Usage: "listen for QUIC connections on the UDP port --quic-port, besides TCP and websocket",
+	}
+	QUICPort = &cli.UintFlag{
+		Name:  "quic-port",
+		Usage: "UDP port that the b7s host will use for QUIC connections",
+	}
+	QUICDialbackPort = &cli.UintFlag{
+		Name:  "quic-dialback-port",
+		Usage: "external UDP port that the b7s host will advertise for QUIC connections (default --quic-port)",
+	}
+	ListenAddresses = &cli.StringSliceFlag{
+		Name:  "listen-addresses",
+		Usage: "list of extra multiaddrs the b7s host listens on besides --address and the ports, eg. /ip6/::/tcp/9527 or the address of another interface",
+	}
+	AnnounceAddresses = &cli.StringSliceFlag{
+		Name:  "announce-addresses",
+		Usage: "list of multiaddrs the b7s host advertises to its peers instead of its listen and dialback addresses, eg. the ones of a load balancer",
+	}
+	ConnLowWater = &cli.IntFlag{
+		Name:  "conn-low-water",
+		Usage: "number of connections the connection manager trims down to once there are more than --conn-high-water",
+	}
+	ConnHighWater = &cli.IntFlag{
+		Name:  "conn-high-water",
+		Usage: "number of connections above which the connections of the least valuable peers are closed (0 for the libp2p default, trimming above 192)",
+	}
+	ConnGracePeriod = &cli.DurationFlag{
+		Name:  "conn-grace-period",
+		Usage: "time a new connection is kept before the connection manager may close it (default 1m)",
+	}
+	AllowPeers = &cli.StringSliceFlag{
+		Name:  "allow-peers",
+		Usage: "list of the peer ids and CIDRs (eg. 10.0.0.0/8) of the only peers allowed to connect to the node",
+	}
+	DenyPeers = &cli.StringSliceFlag{
+		Name:  "deny-peers",
+		Usage: "list of the peer ids and CIDRs of the peers not allowed to connect to the node, even when in --allow-peers",
+	}
+	StaticPeers = &cli.StringSliceFlag{
+		Name:  "static-peers",
+		Usage: "list of peers, in multiaddr format, the node stays connected to, reconnecting whenever they disconnect and never trimming their connections",
+	}
+	PrivateNetworkKey = &cli.StringFlag{
+		Name:  "private-network-key",
+		Usage: "pre-shared key file (libp2p swarm key format) of the private network to join; only the nodes holding the key connect to each other",
+	}
+	PeerBandwidthLimit = &cli.UintFlag{
+		Name:  "peer-bandwidth-limit",
+		Usage: "KiB per second the node receives from and sends to each peer on the node protocols, throttling the streams above it (0 for unlimited)",
+	}
+	PeerScoring = &cli.BoolFlag{
+		Name:  "peer-scoring",
+		Usage: "score the peers on failed executions, protocol violations and excessive messaging, temporarily banning the ones scoring below --peer-ban-threshold",
+	}
+	PeerBanThreshold = &cli.IntFlag{
+		Name:  "peer-ban-threshold",
+		Usage: "score at or below which a peer is banned (default -100)",
+	}
+	PeerBanDuration = &cli.DurationFlag{
+		Name:  "peer-ban-duration",
+		Usage: "time a peer is banned for, after which its score is reset (default 1h)",
+	}
+	PeerMessageRate = &cli.IntFlag{
+		Name:  "peer-message-rate",
+		Usage: "direct messages a peer may send per minute before each extra one is penalized (default 600)",
+	}
+	WorkerAttributes = &cli.StringSliceFlag{
+		Name:  "worker-attributes",
+		Usage: "attributes advertised by a worker node to the head nodes along with its cpus, memory and runtime version, in the name=value format, eg. region=eu",
+	}
+	ExecutorMinCPUs = &cli.IntFlag{
+		Name:  "executor-min-cpus",
+		Usage: "cpus a worker must advertise for a head node to select it for the executions",
+	}
+	ExecutorMinMemory = &cli.Uint64Flag{
+		Name:  "executor-min-memory",
+		Usage: "memory (MiB) a worker must advertise for a head node to select it for the executions; a worker with a --memory-limit advertises the limit",
+	}
+	ExecutorRuntime = &cli.StringFlag{
+		Name:  "executor-runtime",
+		Usage: "runtime version a worker must advertise for a head node to select it for the executions, matching when contained in the version, eg. v0.3",
+	}
+	ExecutorAttributes = &cli.StringSliceFlag{
+		Name:  "executor-attributes",
+		Usage: "attributes a worker must advertise, with the same value, for a head node to select it for the executions, in the name=value format",
+	}
+	VerifyExecutions = &cli.IntFlag{
+		Name:  "verify-executions",
+		Usage: "number of workers, at least 2, a head node dispatches each execution to, comparing their results; the workers returning another result than the majority are reported, and penalized with --peer-scoring",
+	}
+	FunctionSource = &cli.StringFlag{
+		Name:  "function-source",
+		Usage: "where a worker node fetches the function bundles (<cid>/manifest.json and the archive it names) from, s3://bucket/prefix or ipfs; the manifest url of the install requests when empty",
+	}
+	S3Endpoint = &cli.StringFlag{
+		Name:  "s3-endpoint",
+		Usage: "endpoint of the S3-compatible object storage of --function-source (default https://s3.amazonaws.com); the credentials are read from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY",
+	}
+	S3Region = &cli.StringFlag{
+		Name:  "s3-region",
+		Usage: "region of the S3 bucket of --function-source (default us-east-1)",
+	}
+	IPFSGateway = &cli.StringFlag{
+		Name:  "ipfs-gateway",
+		Usage: "IPFS gateway the function bundles are fetched from with --function-source ipfs (default https://w3s.link)",
+	}
+	FunctionCacheSize = &cli.Int64Flag{
+		Name:  "function-cache-size",
+		Usage: "size (MiB) of the cache of the verified function bundles fetched from --function-source (default 1024)",
+	}
+	WorkspaceGCMaxAge = &cli.DurationFlag{
+		Name:  "workspace-gc-max-age",
+		Usage: "age above which the execution directories left in the workspace of a worker node are removed, negative to keep them (default 24h)",
+	}
+	WorkspaceGCMaxSize = &cli.Int64Flag{
+		Name:  "workspace-gc-max-size",
+		Usage: "total size (MiB) of the execution directories left in the workspace of a worker node above which the oldest ones are removed (default unlimited)",
+	}
+	WorkspaceGCInterval = &cli.DurationFlag{
+		Name:  "workspace-gc-interval",
+		Usage: "how often the execution directories left in the workspace of a worker node are collected (default 10m)",
+	}
+	AdaptiveConcurrency = &cli.BoolFlag{
+		Name:  "adaptive-concurrency",
+		Usage: "adjust the number of functions a worker node executes at once to the cpu load and memory pressure, between --min-concurrency and --max-concurrency",
+	}
+	MinConcurrency = &cli.IntFlag{
+		Name:  "min-concurrency",
+		Usage: "least number of functions executed at once with --adaptive-concurrency (default 1)",
+	}
+	MaxConcurrency = &cli.IntFlag{
+		Name:  "max-concurrency",
+		Usage: "most functions executed at once with --adaptive-concurrency (default --concurrency)",
+	}
+	JobMaxAge = &cli.DurationFlag{
+		Name:  "job-max-age",
+		Usage: "age above which the executions left pending by a restart of a head node, which keeps them in the peer database, aren't re-dispatched (default 10m); negative not to keep them",
+	}
+	WebsocketTLSCert = &cli.StringFlag{
+		Name:  "websocket-tls-cert",
+		Usage: "certificate file (PEM) to serve the websocket transport over TLS (wss) with, along with --websocket-tls-key; reloaded when it changes",
+	}
+	WebsocketTLSKey = &cli.StringFlag{
+		Name:  "websocket-tls-key",
+		Usage: "private key file (PEM) of --websocket-tls-cert",
+	}
+	WebsocketAutocert = &cli.StringSliceFlag{
+		Name:  "websocket-autocert",
+		Usage: "list of domains to serve the websocket transport over TLS (wss) for, with certificates obtained from Let's Encrypt, accepting its terms of service; the websocket port must be reachable on port 443 of the domains",
+	}
+	WebsocketAutocertCache = &cli.StringFlag{
+		Name:  "websocket-autocert-cache",
+		Usage: "directory of the certificates obtained for --websocket-autocert (default <workspace>/autocert)",
+	}
 	Websocket = &cli.BoolFlag{
 		Name: "websocket",
 		// Required:   true,
@@ -125,23 +305,89 @@ var (
 		// Value:      defaultPort,
 		// HasBeenSet: true,
 	}
+	RuntimePath = &cli.StringFlag{
+		Name:  "runtime-path",
+		Usage: "directory of the blockless runtime executing the functions; required by worker nodes",
+	}
+	RuntimeCLI = &cli.StringFlag{
+		Name:  "runtime-cli",
+		Usage: "name of the blockless runtime executable in --runtime-path (default bls-runtime)",
+	}
 	CPUPercentage = &cli.Float64Flag{
 		Name: "cpu-percentage-limit",
 		// Required:   true,
-		Usage:      "amount of CPU time allowed for Blockless Functions in the 0-1 range, 1 being unlimited",
-		Value:      1.0,
-		HasBeenSet: true,
+		Usage: "amount of CPU time allowed for Blockless Functions in the 0-1 range, 1 being unlimited; limits require cgroups v2",
+		Value: 1.0,
+	}
+	LogFile = &cli.StringFlag{
+		Name:  "log-file",
+		Usage: "also write the p2p node logs to `FILE` as json, rotated by size and age",
+	}
+	LogFileMaxSize = &cli.IntFlag{
+		Name:  "log-file-max-size",
+		Usage: "rotate the log file once larger than this many megabytes (default 100)",
+	}
+	LogFileRotateEvery = &cli.DurationFlag{
+		Name:  "log-file-rotate-every",
+		Usage: "rotate the log file once written to for this long (default: rotate by size only)",
+	}
+	LogFileMaxAge = &cli.DurationFlag{
+		Name:  "log-file-max-age",
+		Usage: "delete the rotated log files older than this (default: keep them)",
+	}
+	LogFileMaxBackups = &cli.IntFlag{
+		Name:  "log-file-max-backups",
+		Usage: "number of rotated log files to keep (default: keep all)",
 	}
 	MemoryMaxKB = &cli.Int64Flag{
 		Name: "memory-limit",
 		// Required:   true,
-		Usage:      "memory limit (kB) for Blockless Functions",
-		Value:      0,
-		HasBeenSet: true,
+		Usage: "memory limit (kB) for Blockless Functions, 0 being unlimited; limits require cgroups v2",
+		Value: 0,
 	}
 )
 
-func ParseFlags(c *cli.Context) config.Config {
+// NodeConfig is the config of a p2p node: the b7s config and the ones of the features of the AVS node.
+type NodeConfig struct {
+	config.Config
+	Protocol     ProtocolConfig
+	Discovery    DiscoveryConfig
+	Connectivity ConnectivityConfig
+	Reputation   ReputationConfig
+	Capability   CapabilityConfig
+	Verification VerificationConfig
+	Functions    FunctionSourceConfig
+	WorkspaceGC  WorkspaceGCConfig
+	Concurrency  ConcurrencyConfig
+	JobQueue     JobQueueConfig
+}
+
+// ParseFlags returns the config of the p2p node from its flags.
+func ParseFlags(c *cli.Context) (NodeConfig, error) {
+	capability, err := ParseCapabilityFlags(c)
+	if err != nil {
+		return NodeConfig{}, err
+	}
+	concurrency, err := ParseConcurrencyFlags(c)
+	if err != nil {
+		return NodeConfig{}, err
+	}
+	return NodeConfig{
+		Config:       parseB7sFlags(c),
+		Protocol:     ParseProtocolFlags(c),
+		Discovery:    ParseDiscoveryFlags(c),
+		Connectivity: ParseConnectivityFlags(c),
+		Reputation:   ParseReputationFlags(c),
+		Capability:   capability,
+		Verification: ParseVerificationFlags(c),
+		Functions:    ParseFunctionSourceFlags(c),
+		WorkspaceGC:  ParseWorkspaceGCFlags(c),
+		Concurrency:  concurrency,
+		JobQueue:     ParseJobQueueFlags(c),
+	}, nil
+}
+
+func parseB7sFlags(c *cli.Context) config.Config {
 	role := c.String(Role.Name)
 	peerDB := c.String(PeerDatabasePath.Name)
 	functionDB := c.String(FunctionDatabasePath.Name)
@@ -151,24 +397,27 @@ func ParseFlags(c *cli.Context) config.Config {
 	hostAddress := c.String(HostAddress.Name)
 	hostPort := c.Uint(HostPort.Name)
 	bootNodes := c.StringSlice(BootNodes.Name)
+	topics := c.StringSlice("topic")
 	dialbackAddress := c.String(DialBackAddress.Name)
 	dialbackPort := c.Uint(DialBackPort.Name)
 	websocket := c.Bool(Websocket.Name)
 	websocketPort := c.Uint(WebsocketPort.Name)
 	websocketDialbackPort := c.Uint(DialBackWebsocketPort.Name)
-	// cpuPercentage := c.Float64(CPUPercentage.Name)
-	// memoryMaxKB := c.Int64(MemoryMaxKB.Name)
-
-	// pflag.StringVar(&cfg.RuntimePath, "runtime-path", "", "runtime path (used by the worker node)")
-	// pflag.StringVar(&cfg.RuntimeCLI, "runtime-cli", "", "runtime path (used by the worker node)")
+	workspace := c.String(Workspace.Name)
+	runtimePath := c.String(RuntimePath.Name)
+	runtimeCLI := c.String(RuntimeCLI.Name)
+	cpuPercentage := c.Float64(CPUPercentage.Name)
+	memoryMaxKB := c.Int64(MemoryMaxKB.Name)
 
 	return config.Config{
 		Role:           role,
 		PeerDB:         peerDB,
 		FunctionDB:     functionDB,
 		Concurrency:    concurrency,
+		Workspace:      workspace,
 		LoadAttributes: loadAttributes,
 		BootNodes:      bootNodes,
+		Topics:         topics,
 		Connectivity: config.Connectivity{
 			PrivateKey:            privateKey,
 			Address:               hostAddress,
@@ -179,5 +428,138 @@ func ParseFlags(c *cli.Context) config.Config {
 			WebsocketPort:         websocketPort,
 			WebsocketDialbackPort: websocketDialbackPort,
 		},
+		Worker: config.Worker{
+			RuntimePath:        runtimePath,
+			RuntimeCLI:         runtimeCLI,
+			CPUPercentageLimit: cpuPercentage,
+			MemoryLimitKB:      memoryMaxKB,
+		},
+	}
+}
+
+// ParseLogFileFlags returns the log file config of the p2p node; no file is written unless --log-file is set.
+func ParseLogFileFlags(c *cli.Context) logging.FileConfig {
+	return logging.FileConfig{
+		Path:        c.String(LogFile.Name),
+		MaxSizeMB:   c.Int(LogFileMaxSize.Name),
+		RotateEvery: c.Duration(LogFileRotateEvery.Name),
+		MaxAge:      c.Duration(LogFileMaxAge.Name),
+		MaxBackups:  c.Int(LogFileMaxBackups.Name),
+	}
+}
+
+// ApplyConfig sets the node flags which weren't set on the command line to the settings of the b7s section of the
+// operator config, so the flags take precedence over the config file; unset (zero) settings keep the flag defaults.
+func ApplyConfig(c *cli.Context, cfg types.B7sConfig) error {
+	var legacyProtocolUntil string
+	if !cfg.LegacyProtocolUntil.IsZero() {
+		legacyProtocolUntil = cfg.LegacyProtocolUntil.Format(time.RFC3339)
+	}
+	settings := []struct {
+		flag  cli.Flag
+		value any
+	}{
+		{Role, cfg.Role},
+		{PeerDatabasePath, cfg.PeerDB},
+		{FunctionDatabasePath, cfg.FunctionDB},
+		{Workspace, cfg.Workspace},
+		{RuntimePath, cfg.RuntimePath},
+		{RuntimeCLI, cfg.RuntimeCLI},
+		{CPUPercentage, cfg.CPUPercentageLimit},
+		{MemoryMaxKB, cfg.MemoryLimitKB},
+		{Concurrency, cfg.Concurrency},
+		{LoadAttributes, cfg.LoadAttributes},
+		{AttributesFile, cfg.AttributesFile},
+		{PrivateKey, cfg.PrivateKey},
+		{HostAddress, cfg.Address},
+		{HostPort, cfg.Port},
+		{BootNodes, cfg.BootNodes},
+		{Mdns, cfg.Mdns},
+		{MdnsService, cfg.MdnsService},
+		{Dht, cfg.Dht},
+		{DhtProtocolPrefix, cfg.DhtProtocolPrefix},
+		{DhtNamespace, cfg.DhtNamespace},
+		{DialBackAddress, cfg.DialbackAddress},
+		{DialBackPort, cfg.DialbackPort},
+		{AutoNAT, cfg.AutoNAT},
+		{HolePunching, cfg.HolePunching},
+		{Relays, cfg.Relays},
+		{QUIC, cfg.QUIC},
+		{QUICPort, cfg.QUICPort},
+		{QUICDialbackPort, cfg.QUICDialbackPort},
+		{ListenAddresses, cfg.ListenAddresses},
+		{AnnounceAddresses, cfg.AnnounceAddresses},
+		{ConnLowWater, cfg.ConnLowWater},
+		{ConnHighWater, cfg.ConnHighWater},
+		{ConnGracePeriod, cfg.ConnGracePeriod},
+		{AllowPeers, cfg.AllowPeers},
+		{DenyPeers, cfg.DenyPeers},
+		{StaticPeers, cfg.StaticPeers},
+		{PrivateNetworkKey, cfg.PrivateNetworkKey},
+		{PeerBandwidthLimit, cfg.PeerBandwidthLimit},
+		{PeerScoring, cfg.PeerScoring},
+		{PeerBanThreshold, cfg.PeerBanThreshold},
+		{PeerBanDuration, cfg.PeerBanDuration},
+		{PeerMessageRate, cfg.PeerMessageRate},
+		{WorkerAttributes, cfg.WorkerAttributes},
+		{ExecutorMinCPUs, cfg.ExecutorMinCPUs},
+		{ExecutorMinMemory, cfg.ExecutorMinMemory},
+		{ExecutorRuntime, cfg.ExecutorRuntime},
+		{ExecutorAttributes, cfg.ExecutorAttributes},
+		{VerifyExecutions, cfg.VerifyExecutions},
+		{FunctionSource, cfg.FunctionSource},
+		{S3Endpoint, cfg.S3Endpoint},
+		{S3Region, cfg.S3Region},
+		{IPFSGateway, cfg.IPFSGateway},
+		{FunctionCacheSize, cfg.FunctionCacheSize},
+		{WorkspaceGCMaxAge, cfg.WorkspaceGC.MaxAge},
+		{WorkspaceGCMaxSize, cfg.WorkspaceGC.MaxSize},
+		{WorkspaceGCInterval, cfg.WorkspaceGC.Interval},
+		{AdaptiveConcurrency, cfg.AdaptiveConcurrency},
+		{MinConcurrency, cfg.MinConcurrency},
+		{MaxConcurrency, cfg.MaxConcurrency},
+		{JobMaxAge, cfg.JobMaxAge},
+		{Websocket, cfg.Websocket},
+		{WebsocketPort, cfg.WebsocketPort},
+		{DialBackWebsocketPort, cfg.WebsocketDialbackPort},
+		{WebsocketTLSCert, cfg.WebsocketTLSCert},
+		{WebsocketTLSKey, cfg.WebsocketTLSKey},
+		{WebsocketAutocert, cfg.WebsocketAutocert},
+		{WebsocketAutocertCache, cfg.WebsocketAutocertCache},
+		{LegacyProtocolUntil, legacyProtocolUntil},
+		{DisableLegacyProtocol, cfg.DisableLegacyProtocol},
+		{TopicNamespace, cfg.TopicNamespace},
+		{LogFile, cfg.LogFile.Path},
+		{LogFileMaxSize, cfg.LogFile.MaxSizeMB},
+		{LogFileRotateEvery, cfg.LogFile.RotateEvery},
+		{LogFileMaxAge, cfg.LogFile.MaxAge},
+		{LogFileMaxBackups, cfg.LogFile.MaxBackups},
+	}
+	defined := map[string]bool{}
+	if c.Command != nil {
+		for _, f := range c.Command.Flags {
+			defined[f.Names()[0]] = true
+		}
+	}
+	for _, setting := range settings {
+		name := setting.flag.Names()[0]
+		// the commands only define some of the node flags
+		if !defined[name] || c.IsSet(name) {
+			continue
+		}
+		values, ok := setting.value.([]string)
+		if !ok {
+			values = []string{fmt.Sprint(setting.value)}
+		}
+		for _, value := range values {
+			switch value {
+			case "", "0", "0s", "false":
+				continue
+			}
+			if err := c.Set(name, value); err != nil {
+				return fmt.Errorf("invalid --%s from the b7s config: %w", name, err)
+			}
+		}
 	}
+	return nil
 }
@@ -0,0 +1,290 @@
+package pkg
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/node"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	// directory of the quarantined functions in the workspace
+	functionQuarantineDir = "function-quarantine"
+
+	// quarantine reasons, as reported by the metrics
+	quarantineChecksumMismatch = "checksum_mismatch"
+	quarantineCIDMismatch      = "cid_mismatch"
+)
+
+// functionMismatchError is returned for a function archive which doesn't match its manifest checksum or its CID.
+type functionMismatchError struct {
+	reason string
+	err    error
+}
+
+func (e *functionMismatchError) Error() string {
+	return e.err.Error()
+}
+
+// verifyFunctionArchive checks the sha256 of the archive of a function against the checksum of its manifest and, for
+// the raw sha256 CIDs, which are the hash of the archive itself, against its CID. The other CIDs, of the DAG of the
+// function bundle, can't be derived from the archive and are trusted through the manifest.
+func verifyFunctionArchive(functionCID, checksum, sum string) error {
+	if !strings.EqualFold(sum, checksum) {
+		return &functionMismatchError{
+			reason: quarantineChecksumMismatch,
+			err:    fmt.Errorf("archive sha256 %s does not match the manifest checksum %q", sum, checksum),
+		}
+	}
+	c, err := cid.Decode(functionCID)
+	if err != nil || c.Prefix().Codec != cid.Raw || c.Prefix().MhType != multihash.SHA2_256 {
+		return nil
+	}
+	decoded, err := multihash.Decode(c.Hash())
+	if err != nil {
+		return nil
+	}
+	if digest := hex.EncodeToString(decoded.Digest); digest != strings.ToLower(sum) {
+		return &functionMismatchError{
+			reason: quarantineCIDMismatch,
+			err:    fmt.Errorf("archive sha256 %s does not match the digest %s of the cid", sum, digest),
+		}
+	}
+	return nil
+}
+
+// verifyRecordArchive verifies the archive of the function record against its manifest checksum and its CID.
+func verifyRecordArchive(workspace string, record FunctionRecord) error {
+	archive, err := workspacePath(workspace, record.Archive)
+	if err != nil {
+		return err
+	}
+	sum, err := sha256File(archive)
+	if err != nil {
+		return fmt.Errorf("could not hash function archive: %w", err)
+	}
+	return verifyFunctionArchive(record.CID, record.Manifest.Deployment.Checksum, sum)
+}
+
+// verifiedRecordStore is the record store of the function stores of a worker node, refusing to save the record of a
+// function whose archive doesn't match, so it's never installed. The b7s store only logs the failure to save the
+// record, the refused records are kept until taken by the verified function store.
+type verifiedRecordStore struct {
+	recordStore
+	workspace string
+
+	mu       sync.Mutex
+	rejected map[string]rejectedRecord
+}
+
+type rejectedRecord struct {
+	record FunctionRecord
+	err    *functionMismatchError
+}
+
+func newVerifiedRecordStore(s recordStore, workspace string) *verifiedRecordStore {
+	return &verifiedRecordStore{
+		recordStore: s,
+		workspace:   workspace,
+		rejected:    map[string]rejectedRecord{},
+	}
+}
+
+// SetRecord saves the record, refusing the function records whose archive doesn't match.
+func (s *verifiedRecordStore) SetRecord(key string, value interface{}) error {
+	// the b7s store saves its own record type, of the same layout
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var record FunctionRecord
+	if err := json.Unmarshal(payload, &record); err != nil || record.CID != key {
+		return s.recordStore.SetRecord(key, value)
+	}
+	// b7s saves the record again on each retrieval, the installed archive being verified before it's executed
+	var stored FunctionRecord
+	if err := s.recordStore.GetRecord(key, &stored); err == nil && stored.Archive == record.Archive &&
+		stored.Manifest.Deployment.Checksum == record.Manifest.Deployment.Checksum {
+		return s.recordStore.SetRecord(key, value)
+	}
+	var mismatch *functionMismatchError
+	if err := verifyRecordArchive(s.workspace, record); errors.As(err, &mismatch) {
+		s.mu.Lock()
+		s.rejected[key] = rejectedRecord{record: record, err: mismatch}
+		s.mu.Unlock()
+		return fmt.Errorf("function %s rejected: %w", key, err)
+	} else if err != nil {
+		return err
+	}
+	return s.recordStore.SetRecord(key, value)
+}
+
+// takeRejected returns the record of the function refused by the last save, if any.
+func (s *verifiedRecordStore) takeRejected(cid string) (rejectedRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rejected, ok := s.rejected[cid]
+	delete(s.rejected, cid)
+	return rejected, ok
+}
+
+// archiveStamp identifies the version of an archive which was verified.
+type archiveStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// verifiedFunctionStore verifies the archives of the functions of a worker node when they're installed, before their
+// record is saved, and before they're executed, b7s checking the installation on the roll calls. The functions whose
+// archive doesn't match are moved to the quarantine directory of the workspace and removed from the function database,
+// so they're installed again on the next request.
+type verifiedFunctionStore struct {
+	node.FStore
+	log       *zerolog.Logger
+	store     *verifiedRecordStore
+	workspace string
+	metrics   *metrics.NodeMetrics
+
+	mu sync.Mutex
+	// archives already verified, by cid, only hashed again once they change
+	verified map[string]archiveStamp
+}
+
+func newVerifiedFunctionStore(log *zerolog.Logger, s *verifiedRecordStore, functions node.FStore, workspace string, metrics *metrics.NodeMetrics) *verifiedFunctionStore {
+	return &verifiedFunctionStore{
+		FStore:    functions,
+		log:       log,
+		store:     s,
+		workspace: workspace,
+		metrics:   metrics,
+		verified:  map[string]archiveStamp{},
+	}
+}
+
+// Install installs the function, rejecting it when its archive doesn't match.
+func (f *verifiedFunctionStore) Install(address string, cid string) error {
+	err := f.FStore.Install(address, cid)
+	var qdir string
+	var qerr error
+	if rejected, ok := f.store.takeRejected(cid); ok {
+		// the archive and files were unpacked before the record was refused
+		qdir, qerr = f.quarantine(rejected.record)
+		err = rejected.err
+	}
+	var mismatch *functionMismatchError
+	if errors.As(err, &mismatch) {
+		f.metrics.AddFunctionQuarantine(mismatch.reason)
+		f.log.Warn().Err(err).Str("cid", cid).Str("reason", mismatch.reason).Str("quarantine", qdir).AnErr("quarantine_error", qerr).Msg("function archive mismatch, rejected function")
+		return fmt.Errorf("function %s rejected: %w", cid, mismatch)
+	}
+	if err != nil {
+		return err
+	}
+	return f.check(cid)
+}
+
+// Installed reports whether the function is installed with an archive matching its manifest checksum and CID.
+func (f *verifiedFunctionStore) Installed(cid string) (bool, error) {
+	installed, err := f.FStore.Installed(cid)
+	if err != nil || !installed {
+		return installed, err
+	}
+	var mismatch *functionMismatchError
+	if err := f.check(cid); errors.As(err, &mismatch) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Sync restores the missing files of the function, rejecting it when its archive doesn't match.
+func (f *verifiedFunctionStore) Sync(cid string) error {
+	if err := f.FStore.Sync(cid); err != nil {
+		return err
+	}
+	return f.check(cid)
+}
+
+// check verifies the archive of the installed function, quarantining the function when it doesn't match.
+func (f *verifiedFunctionStore) check(cid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the record is read from the database directly, not to update its retrieval time
+	var record FunctionRecord
+	if err := f.store.GetRecord(cid, &record); err != nil {
+		return fmt.Errorf("could not get function record: %w", err)
+	}
+	archive, err := workspacePath(f.workspace, record.Archive)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(archive)
+	if err != nil {
+		return fmt.Errorf("could not stat function archive: %w", err)
+	}
+	stamp := archiveStamp{size: info.Size(), modTime: info.ModTime()}
+	if verified, ok := f.verified[cid]; ok && verified == stamp {
+		return nil
+	}
+	delete(f.verified, cid)
+
+	sum, err := sha256File(archive)
+	if err != nil {
+		return fmt.Errorf("could not hash function archive: %w", err)
+	}
+	err = verifyFunctionArchive(cid, record.Manifest.Deployment.Checksum, sum)
+	var mismatch *functionMismatchError
+	if !errors.As(err, &mismatch) {
+		f.verified[cid] = stamp
+		return nil
+	}
+
+	f.metrics.AddFunctionQuarantine(mismatch.reason)
+	dir, qerr := f.quarantine(record)
+	f.log.Warn().Err(err).Str("cid", cid).Str("reason", mismatch.reason).Str("quarantine", dir).AnErr("quarantine_error", qerr).Msg("function archive mismatch, quarantined function")
+	return fmt.Errorf("function %s rejected: %w", cid, err)
+}
+
+// quarantine moves the archive and files of the function to a directory of the quarantine, along with its record,
+// and removes it from the function database. It returns the directory.
+func (f *verifiedFunctionStore) quarantine(record FunctionRecord) (string, error) {
+	dir := filepath.Join(f.workspace, functionQuarantineDir, record.CID+"-"+strconv.FormatInt(time.Now().Unix(), 10))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create quarantine directory: %w", err)
+	}
+	var errs []error
+	for _, path := range []string{record.Archive, record.Files} {
+		src, err := workspacePath(f.workspace, path)
+		if err != nil {
+			continue
+		}
+		// the archive is usually within the files directory, moved along with it
+		if err := os.Rename(src, filepath.Join(dir, filepath.Base(src))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if payload, err := json.MarshalIndent(record, "", "  "); err == nil {
+		if err := os.WriteFile(filepath.Join(dir, "record.json"), payload, 0o644); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := f.store.Delete(record.CID); err != nil {
+		errs = append(errs, fmt.Errorf("could not remove function record: %w", err))
+	}
+	return dir, errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+func TestVerifiedRecordStoreSetRecord(t *testing.T) {
+	workspace := t.TempDir()
+	archive := filepath.Join(workspace, "function.tar.gz")
+	if err := os.WriteFile(archive, []byte("function"), 0o644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	sum, err := sha256File(archive)
+	if err != nil {
+		t.Fatalf("Failed to hash archive: %v", err)
+	}
+	rawCID := func(data string) string {
+		hash, err := multihash.Sum([]byte(data), multihash.SHA2_256, -1)
+		if err != nil {
+			t.Fatalf("Failed to hash cid: %v", err)
+		}
+		return cid.NewCidV1(cid.Raw, hash).String()
+	}
+
+	tests := []struct {
+		name     string
+		cid      string
+		checksum string
+		// expected quarantine reason, empty when the record is saved
+		reason string
+	}{
+		{"matching archive", "function", sum, ""},
+		{"matching raw cid", rawCID("function"), sum, ""},
+		{"checksum mismatch", "function", "00" + sum[2:], quarantineChecksumMismatch},
+		{"cid mismatch", rawCID("other"), sum, quarantineCIDMismatch},
+	}
+
+	for _, test := range tests {
+		db, err := pebble.Open("", &pebble.Options{FS: vfs.NewMem()})
+		if err != nil {
+			t.Fatalf("Failed to open database: %v", err)
+		}
+		s := newVerifiedRecordStore(newRecordStore(db), workspace)
+		record := FunctionRecord{CID: test.cid, Archive: "/function.tar.gz"}
+		record.Manifest.Deployment = blockless.Deployment{Checksum: test.checksum}
+
+		err = s.SetRecord(test.cid, record)
+		var stored FunctionRecord
+		saved := s.GetRecord(test.cid, &stored) == nil
+		rejected, ok := s.takeRejected(test.cid)
+		if test.reason == "" {
+			if err != nil || !saved || ok {
+				t.Errorf("%s: Expected record saved, got error: %v, saved: %v", test.name, err, saved)
+			}
+		} else {
+			var mismatch *functionMismatchError
+			if !errors.As(err, &mismatch) || mismatch.reason != test.reason || saved {
+				t.Errorf("%s: Expected record rejected: %v, got error: %v, saved: %v", test.name, test.reason, err, saved)
+			}
+			if !ok || rejected.record.CID != test.cid {
+				t.Errorf("%s: Expected rejected record, got: %v", test.name, rejected.record.CID)
+			}
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/fstore"
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/cockroachdb/pebble"
+	"github.com/rs/zerolog"
+)
+
+// ErrFunctionNotFound is returned for a function which isn't in the function database.
+var ErrFunctionNotFound = errors.New("function not found")
+
+// FunctionRecord is an installed function of the function database, as stored by the b7s function store. The archive
+// and files paths are relative to the workspace.
+type FunctionRecord struct {
+	CID           string                     `json:"cid"`
+	URL           string                     `json:"url"`
+	Manifest      blockless.FunctionManifest `json:"manifest"`
+	Archive       string                     `json:"archive"`
+	Files         string                     `json:"files"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+	LastRetrieved time.Time                  `json:"last_retrieved"`
+}
+
+// FunctionVerification is the result of checking an installed function against its manifest.
+type FunctionVerification struct {
+	CID string `json:"cid"`
+	// sha256 of the function archive, empty when the archive is missing
+	Checksum string   `json:"checksum,omitempty"`
+	Problems []string `json:"problems"`
+}
+
+// FunctionDB manages the wasm functions installed in a pebble function database and its workspace, with the b7s
+// function store. The database is locked by the running node, so it must be stopped first.
+type FunctionDB struct {
+	db        *pebble.DB
+	store     recordStore
+	fstore    *fstore.FStore
+	workspace string
+}
+
+// OpenFunctionDB opens the function database at path, whose functions are installed in the workspace, creating it if
+// create is set, and upgrades it to the schema version of this release.
+func OpenFunctionDB(log zerolog.Logger, path, workspace string, create bool) (*FunctionDB, error) {
+	// the function store records the paths relative to the absolute workspace, like the node does
+	workspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine absolute path for workspace (path: %s): %w", workspace, err)
+	}
+	db, err := pebble.Open(path, &pebble.Options{Logger: &PebbleNoopLogger{}, ErrorIfNotExists: !create})
+	if errors.Is(err, pebble.ErrDBDoesNotExist) {
+		return nil, fmt.Errorf("no pebble function database at %s", path)
+	}
+	if err != nil {
+		// the running node holds the database lock
+		return nil, fmt.Errorf("could not open pebble function database (path: %s), is the node stopped?: %w", path, err)
+	}
+	if _, err := migrateDB(db, FunctionDBSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	s := newRecordStore(db)
+	return &FunctionDB{db: db, store: s, fstore: fstore.New(log, s, workspace), workspace: workspace}, nil
+}
+
+func (f *FunctionDB) Close() error {
+	return f.db.Close()
+}
+
+// List returns the installed functions, most recently updated first.
+func (f *FunctionDB) List() ([]FunctionRecord, error) {
+	functions := []FunctionRecord{}
+	for _, key := range f.store.Keys() {
+		var record FunctionRecord
+		if err := f.store.GetRecord(key, &record); err != nil {
+			return nil, fmt.Errorf("could not retrieve function (cid: %v): %w", key, err)
+		}
+		functions = append(functions, record)
+	}
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].UpdatedAt.After(functions[j].UpdatedAt) })
+	return functions, nil
+}
+
+// Get returns the record of the installed function.
+func (f *FunctionDB) Get(cid string) (FunctionRecord, error) {
+	var record FunctionRecord
+	if err := f.store.GetRecord(cid, &record); err != nil {
+		if errors.Is(err, blockless.ErrNotFound) {
+			return record, ErrFunctionNotFound
+		}
+		return record, err
+	}
+	return record, nil
+}
+
+// Installed reports whether the function is in the database with its archive and files in the workspace.
+func (f *FunctionDB) Installed(cid string) (bool, error) {
+	return f.fstore.Installed(cid)
+}
+
+// Install downloads the function archive referenced by the manifest at manifestUrl and unpacks it in the workspace.
+// It reports whether the function was installed, ie. false when it already was.
+func (f *FunctionDB) Install(cid, manifestUrl string) (FunctionRecord, bool, error) {
+	installed, err := f.Installed(cid)
+	if err != nil {
+		return FunctionRecord{}, false, fmt.Errorf("could not check if function %s is installed: %w", cid, err)
+	}
+	if !installed {
+		if err := f.fstore.Install(manifestUrl, cid); err != nil {
+			return FunctionRecord{}, false, fmt.Errorf("could not install function %s: %w", cid, err)
+		}
+	}
+	record, err := f.Get(cid)
+	return record, !installed, err
+}
+
+// Remove deletes the function record and its files from the workspace.
+func (f *FunctionDB) Remove(cid string) error {
+	record, err := f.Get(cid)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{record.Archive, record.Files} {
+		if path == "" {
+			continue
+		}
+		path, err := workspacePath(f.workspace, path)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("could not remove function files: %w", err)
+		}
+	}
+	if err := f.store.Delete(cid); err != nil {
+		return fmt.Errorf("could not remove function: %w", err)
+	}
+	return nil
+}
+
+// Verify checks that the archive and files of the installed function are in the workspace, that the archive matches
+// the manifest checksum and the CID, and that the entries of the manifest methods were unpacked.
+func (f *FunctionDB) Verify(cid string) (*FunctionVerification, error) {
+	record, err := f.Get(cid)
+	if err != nil {
+		return nil, err
+	}
+	v := &FunctionVerification{CID: cid, Problems: []string{}}
+	addProblem := func(format string, args ...any) {
+		v.Problems = append(v.Problems, fmt.Sprintf(format, args...))
+	}
+
+	if archive, err := workspacePath(f.workspace, record.Archive); err != nil {
+		addProblem("%v", err)
+	} else if sum, err := sha256File(archive); err != nil {
+		addProblem("archive %s: %v", record.Archive, err)
+	} else {
+		v.Checksum = sum
+		if err := verifyFunctionArchive(cid, record.Manifest.Deployment.Checksum, sum); err != nil {
+			addProblem("%v", err)
+		}
+	}
+
+	files, err := workspacePath(f.workspace, record.Files)
+	if err != nil {
+		addProblem("%v", err)
+		return v, nil
+	}
+	if _, err := os.Stat(files); err != nil {
+		addProblem("files %s: %v", record.Files, err)
+		return v, nil
+	}
+	for _, method := range record.Manifest.Deployment.Methods {
+		if method.Entry == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(files, method.Entry)); err != nil {
+			addProblem("entry %s of method %s: %v", method.Entry, method.Name, err)
+		}
+	}
+	return v, nil
+}
+
+// workspacePath resolves a path of a function record, refusing the ones outside the workspace.
+func workspacePath(workspace, path string) (string, error) {
+	if path == "" {
+		return "", errors.New("path not recorded")
+	}
+	resolved := filepath.Join(workspace, path)
+	if rel, err := filepath.Rel(workspace, resolved); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("path %s is not in the workspace %s", path, workspace)
+	}
+	return resolved, nil
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
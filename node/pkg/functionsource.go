@@ -0,0 +1,474 @@
+package pkg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/fstore"
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	defaultIPFSGateway       = "https://w3s.link"
+	defaultFunctionCacheSize = 1024 // MiB
+	// directory of the cache in the workspace
+	functionCacheDir = "function-cache"
+	// time allowed to fetch a manifest or an archive
+	functionFetchTimeout = 5 * time.Minute
+	// manifests are a few kB
+	maxManifestSize = 1 << 20
+	// name of the manifest in the function bundles
+	manifestName = "manifest.json"
+)
+
+// FunctionSourceConfig configures where the worker nodes fetch the functions from. By default b7s fetches the manifest
+// from the url of the install request, or the w3s.link IPFS gateway, and the archive from the url of the manifest. With
+// a source, the function bundles, ie. the manifest.json and the archive it names, are fetched from the <cid>/ directory
+// of an S3-compatible bucket or of an IPFS gateway, and cached.
+type FunctionSourceConfig struct {
+	// s3://bucket/prefix or ipfs; b7s fetches the functions when empty
+	Source string
+	// endpoint of the S3-compatible object storage (default https://s3.amazonaws.com) and its region (default us-east-1)
+	S3Endpoint string
+	S3Region   string
+	// IPFS gateway (default https://w3s.link)
+	IPFSGateway string
+	// bytes of the verified archives and manifests cached in the workspace, the least recently used being evicted first
+	CacheSize int64
+}
+
+func ParseFunctionSourceFlags(c *cli.Context) FunctionSourceConfig {
+	cfg := FunctionSourceConfig{
+		Source:      c.String(FunctionSource.Name),
+		S3Endpoint:  c.String(S3Endpoint.Name),
+		S3Region:    c.String(S3Region.Name),
+		IPFSGateway: c.String(IPFSGateway.Name),
+		CacheSize:   c.Int64(FunctionCacheSize.Name) << 20,
+	}
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = defaultFunctionCacheSize << 20
+	}
+	return cfg
+}
+
+// functionSource is a store of function bundles.
+type functionSource interface {
+	// get returns the content of the file at key, eg. <cid>/manifest.json
+	get(ctx context.Context, key string) (io.ReadCloser, error)
+	// location returns the url of the file at key, as recorded with the installed functions
+	location(key string) string
+}
+
+// newFunctionSource returns the source of the config, nil when b7s fetches the functions itself.
+func newFunctionSource(cfg FunctionSourceConfig) (functionSource, error) {
+	client := &http.Client{Timeout: functionFetchTimeout}
+	switch {
+	case cfg.Source == "":
+		return nil, nil
+	case cfg.Source == "ipfs":
+		gateway := cfg.IPFSGateway
+		if gateway == "" {
+			gateway = defaultIPFSGateway
+		}
+		u, err := url.Parse(gateway)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid IPFS gateway %q", gateway)
+		}
+		return &gatewaySource{gateway: u, client: client}, nil
+	case strings.HasPrefix(cfg.Source, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(cfg.Source, "s3://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("missing bucket in function source %q", cfg.Source)
+		}
+		endpoint := cfg.S3Endpoint
+		if endpoint == "" {
+			endpoint = "https://s3.amazonaws.com"
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+		}
+		region := cfg.S3Region
+		if region == "" {
+			region = defaultS3Region
+		}
+		return &s3Source{
+			endpoint:    u,
+			region:      region,
+			bucket:      bucket,
+			prefix:      strings.Trim(prefix, "/"),
+			credentials: s3CredentialsFromEnv(),
+			client:      client,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported function source %q, expected s3://bucket/prefix or ipfs", cfg.Source)
+	}
+}
+
+// sourcedFunctionStore is the function store of a worker fetching the functions from a function source. The functions
+// are installed like the b7s function store does, so it reads them, and the b7s store is used for the rest.
+type sourcedFunctionStore struct {
+	*fstore.FStore
+	log       *zerolog.Logger
+	store     fstore.Store
+	source    functionSource
+	workspace string
+	cache     *functionCache
+
+	// serializes the installations, b7s installing the function of concurrent roll calls concurrently
+	mu sync.Mutex
+}
+
+func newSourcedFunctionStore(log *zerolog.Logger, s fstore.Store, source functionSource, workspace string, cacheSize int64) (*sourcedFunctionStore, error) {
+	cache, err := newFunctionCache(filepath.Join(workspace, functionCacheDir), cacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &sourcedFunctionStore{
+		FStore:    fstore.New(*log, s, workspace),
+		log:       log,
+		store:     s,
+		source:    source,
+		workspace: workspace,
+		cache:     cache,
+	}, nil
+}
+
+// Install installs the function bundle of the CID from the source, ignoring the manifest address of the request.
+func (f *sourcedFunctionStore) Install(_ string, cid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// a concurrent installation may have installed it
+	if installed, err := f.FStore.Installed(cid); err == nil && installed {
+		return nil
+	}
+	if err := validFunctionCID(cid); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), functionFetchTimeout)
+	defer cancel()
+
+	manifestKey := cid + "/" + manifestName
+	var manifest blockless.FunctionManifest
+	if err := f.fetchManifest(ctx, manifestKey, &manifest); err != nil {
+		return fmt.Errorf("could not get manifest of function %s: %w", cid, err)
+	}
+	checksum, name, err := deploymentArchive(&manifest)
+	if err != nil {
+		return fmt.Errorf("invalid manifest of function %s: %w", cid, err)
+	}
+	archiveKey := cid + "/" + name
+	cached, err := f.fetchArchive(ctx, archiveKey, checksum)
+	if err != nil {
+		return fmt.Errorf("could not get archive of function %s: %w", cid, err)
+	}
+	// the fetched archive matches the checksum, which must match the cid before the function is unpacked
+	if err := verifyFunctionArchive(cid, checksum, checksum); err != nil {
+		return fmt.Errorf("archive of function %s rejected: %w", cid, err)
+	}
+
+	// the archive and files are laid out as by the b7s function store
+	dir := filepath.Join(f.workspace, cid)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("could not clean function directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("could not create function directory: %w", err)
+	}
+	archive := filepath.Join(dir, name)
+	if err := copyFile(cached, archive); err != nil {
+		return fmt.Errorf("could not copy function archive: %w", err)
+	}
+	if err := unpackFunctionArchive(archive, dir); err != nil {
+		return fmt.Errorf("could not unpack function archive: %w", err)
+	}
+
+	manifest.Deployment.URI = f.source.location(archiveKey)
+	manifest.Deployment.Checksum = checksum
+	manifest.Deployment.File = archive
+	record := FunctionRecord{
+		CID:       cid,
+		URL:       f.source.location(manifestKey),
+		Manifest:  manifest,
+		Archive:   string(filepath.Separator) + filepath.Join(cid, name),
+		Files:     string(filepath.Separator) + cid,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := f.store.SetRecord(cid, record); err != nil {
+		return fmt.Errorf("could not save function record: %w", err)
+	}
+	f.log.Info().Str("cid", cid).Str("source", record.URL).Msg("installed function from function source")
+	return nil
+}
+
+// Sync reinstalls the function when its archive or files are missing from the workspace, the b7s store only
+// downloading from the http urls.
+func (f *sourcedFunctionStore) Sync(cid string) error {
+	installed, err := f.FStore.Installed(cid)
+	if err != nil {
+		return err
+	}
+	if installed {
+		return nil
+	}
+	return f.Install("", cid)
+}
+
+func (f *sourcedFunctionStore) fetchManifest(ctx context.Context, key string, manifest *blockless.FunctionManifest) error {
+	if payload, ok := f.cache.get(manifestCacheName(key)); ok {
+		if err := json.Unmarshal(payload, manifest); err == nil {
+			return nil
+		}
+	}
+	body, err := f.source.get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	payload, err := io.ReadAll(io.LimitReader(body, maxManifestSize))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload, manifest); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := f.cache.put(manifestCacheName(key), payload); err != nil {
+		f.log.Warn().Err(err).Str("key", key).Msg("could not cache function manifest")
+	}
+	return nil
+}
+
+// fetchArchive returns the path of the cached archive, fetching it when it isn't cached. The archive is only cached
+// once its sha256 matches checksum.
+func (f *sourcedFunctionStore) fetchArchive(ctx context.Context, key, checksum string) (string, error) {
+	name := strings.ToLower(checksum) + ".tar.gz"
+	if cached, ok := f.cache.path(name); ok {
+		// the cache is in the workspace, where the functions could alter it
+		if sum, err := sha256File(cached); err == nil && strings.EqualFold(sum, checksum) {
+			return cached, nil
+		}
+		f.cache.remove(name)
+	}
+	body, err := f.source.get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	return f.cache.store(name, body, checksum)
+}
+
+// deploymentArchive returns the checksum and file name of the archive of the manifest; the legacy manifests name it in
+// their runtime section.
+func deploymentArchive(manifest *blockless.FunctionManifest) (string, string, error) {
+	uri, checksum := manifest.Deployment.URI, manifest.Deployment.Checksum
+	if manifest.Runtime.URL != "" {
+		uri, checksum = manifest.Runtime.URL, manifest.Runtime.Checksum
+	}
+	if _, err := hex.DecodeString(checksum); err != nil || len(checksum) != sha256.Size*2 {
+		return "", "", fmt.Errorf("invalid archive checksum %q", checksum)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid archive url %q: %w", uri, err)
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" || name == ".." {
+		return "", "", fmt.Errorf("archive url %q has no file name", uri)
+	}
+	return checksum, name, nil
+}
+
+// validFunctionCID refuses the CIDs which aren't a single path segment, as they name directories of the source and of
+// the workspace.
+func validFunctionCID(cid string) error {
+	if cid == "" || cid == "." || cid == ".." || strings.ContainsAny(cid, `/\`) {
+		return fmt.Errorf("invalid function cid %q", cid)
+	}
+	return nil
+}
+
+func manifestCacheName(key string) string {
+	return strings.ReplaceAll(key, "/", "_")
+}
+
+// unpackFunctionArchive unpacks the .tar.gz archive in dir, refusing the entries outside of it (absolute or escaping
+// it through ..) and the links, which could point outside of it.
+func unpackFunctionArchive(archive, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, entry.Name)
+		rel, err := filepath.Rel(dir, target)
+		if err != nil || filepath.IsAbs(entry.Name) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %s is outside of the function directory", entry.Name)
+		}
+		switch entry.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.Create(target)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, reader)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected archive entry %s of type %d", entry.Name, entry.Typeflag)
+		}
+	}
+}
+
+// functionCache caches the files of the function bundles in a directory, up to a total size. The least recently used
+// files are evicted first.
+type functionCache struct {
+	dir  string
+	size int64
+	mu   sync.Mutex
+}
+
+func newFunctionCache(dir string, size int64) (*functionCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create function cache: %w", err)
+	}
+	return &functionCache{dir: dir, size: size}, nil
+}
+
+// path returns the path of the cached file, marking it as used.
+func (c *functionCache) path(name string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := filepath.Join(c.dir, name)
+	if _, err := os.Stat(p); err != nil {
+		return "", false
+	}
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	return p, true
+}
+
+func (c *functionCache) get(name string) ([]byte, bool) {
+	p, ok := c.path(name)
+	if !ok {
+		return nil, false
+	}
+	payload, err := os.ReadFile(p)
+	return payload, err == nil
+}
+
+func (c *functionCache) put(name string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.WriteFile(filepath.Join(c.dir, name), payload, 0o644); err != nil {
+		return err
+	}
+	c.evict(name)
+	return nil
+}
+
+// store caches the content of r once its sha256 matches checksum, returning the path of the cached file.
+func (c *functionCache) store(name string, r io.Reader, checksum string) (string, error) {
+	tmp, err := os.CreateTemp(c.dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		return "", fmt.Errorf("archive sha256 %s does not match the manifest checksum %s", sum, checksum)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := filepath.Join(c.dir, name)
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return "", err
+	}
+	c.evict(name)
+	return p, nil
+}
+
+func (c *functionCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	os.Remove(filepath.Join(c.dir, name))
+}
+
+// evict removes the least recently used files until the cache fits its size, keeping the file just added. c.mu must
+// be held.
+func (c *functionCache) evict(keep string) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var files []os.FileInfo
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || strings.HasPrefix(info.Name(), ".") {
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, info := range files {
+		if total <= c.size {
+			return
+		}
+		if info.Name() == keep {
+			continue
+		}
+		if os.Remove(filepath.Join(c.dir, info.Name())) == nil {
+			total -= info.Size()
+		}
+	}
+}
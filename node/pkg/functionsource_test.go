@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type archiveEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+// writeArchive writes a .tar.gz archive of the entries, returning its path.
+func writeArchive(t *testing.T, entries []archiveEntry) string {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0o644, Size: int64(len(entry.body))}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write archive header: %v", err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatalf("Failed to write archive entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "function.tar.gz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return archive
+}
+
+func TestUnpackFunctionArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		err     bool
+		// file expected to be unpacked in the function directory
+		unpacked string
+	}{
+		{"regular files", []archiveEntry{{name: "lib", typeflag: tar.TypeDir}, {name: "lib/function.wasm", typeflag: tar.TypeReg, body: "wasm"}}, false, "lib/function.wasm"},
+		{"dot dot in file name", []archiveEntry{{name: "..function.wasm", typeflag: tar.TypeReg, body: "wasm"}}, false, "..function.wasm"},
+		{"parent directory", []archiveEntry{{name: "../function.wasm", typeflag: tar.TypeReg, body: "wasm"}}, true, ""},
+		{"nested parent directory", []archiveEntry{{name: "lib/../../function.wasm", typeflag: tar.TypeReg, body: "wasm"}}, true, ""},
+		{"absolute path", []archiveEntry{{name: "/tmp/function.wasm", typeflag: tar.TypeReg, body: "wasm"}}, true, ""},
+		{"symlink", []archiveEntry{{name: "function.wasm", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}}, true, ""},
+		{"hard link", []archiveEntry{{name: "function.wasm", typeflag: tar.TypeLink, linkname: "/etc/passwd"}}, true, ""},
+	}
+
+	for _, test := range tests {
+		archive := writeArchive(t, test.entries)
+		dir := t.TempDir()
+		err := unpackFunctionArchive(archive, dir)
+		if (err != nil) != test.err {
+			t.Errorf("%s: Expected error: %v, got: %v", test.name, test.err, err)
+		}
+		if test.unpacked == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, test.unpacked)); err != nil {
+			t.Errorf("%s: Expected %s to be unpacked: %v", test.name, test.unpacked, err)
+		}
+	}
+}
+
+func TestFunctionCacheStoreChecksum(t *testing.T) {
+	cache, err := newFunctionCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("Failed to create function cache: %v", err)
+	}
+	body := []byte("archive")
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+
+	if _, err := cache.store("mismatch.tar.gz", bytes.NewReader([]byte("tampered")), checksum); err == nil {
+		t.Errorf("Expected checksum mismatch error")
+	}
+	if _, ok := cache.path("mismatch.tar.gz"); ok {
+		t.Errorf("Expected archive not to be cached on checksum mismatch")
+	}
+
+	if _, err := cache.store("match.tar.gz", bytes.NewReader(body), checksum); err != nil {
+		t.Errorf("Expected archive to be cached: %v", err)
+	}
+	if _, ok := cache.path("match.tar.gz"); !ok {
+		t.Errorf("Expected archive to be cached")
+	}
+}
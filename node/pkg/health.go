@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// p2p health statuses
+const (
+	// connected to peers, and to a boot node and DHT peers when configured
+	P2PHealthy = "ok"
+	// connected to peers, but to no boot node or with an empty DHT routing table
+	P2PDegraded = "degraded"
+	// connected to no peer; the node can't receive or dispatch executions
+	P2PIsolated = "isolated"
+	// the host isn't running, ie. the node wasn't started or was stopped
+	P2PDown = "down"
+)
+
+// P2PHealth reports the connectivity of the node, for monitoring to alert on its isolation.
+type P2PHealth struct {
+	Status string `json:"status"`
+	PeerID string `json:"peerId,omitempty"`
+	Role   string `json:"role"`
+	// number of peers the node is connected to
+	Peers     int              `json:"peers"`
+	BootNodes []BootNodeHealth `json:"bootNodes"`
+	// addresses the host listens on, and the ones advertised to the peers
+	ListenAddresses     []string  `json:"listenAddresses"`
+	AdvertisedAddresses []string  `json:"advertisedAddresses"`
+	NAT                 NATHealth `json:"nat"`
+	// nil when DHT discovery is disabled
+	DHT *DHTHealth `json:"dht,omitempty"`
+}
+
+// BootNodeHealth is a boot node of the node.
+type BootNodeHealth struct {
+	Peer      peer.ID `json:"peer"`
+	Connected bool    `json:"connected"`
+}
+
+// NATHealth is the NAT traversal status of the node.
+type NATHealth struct {
+	// reachability determined by AutoNAT: public, private or unknown, which it is without AutoNAT
+	Reachability string `json:"reachability"`
+	AutoNAT      bool   `json:"autonat"`
+	HolePunching bool   `json:"holePunching"`
+	// advertised addresses through a relay
+	RelayAddresses int `json:"relayAddresses"`
+}
+
+// DHTHealth is the status of the DHT the node joined.
+type DHTHealth struct {
+	RoutingTableSize int `json:"routingTableSize"`
+}
+
+// Health returns the connectivity of the node.
+func (n *Node) Health() P2PHealth {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	health := P2PHealth{
+		Status:              P2PDown,
+		Role:                n.cfg.Role,
+		BootNodes:           []BootNodeHealth{},
+		ListenAddresses:     []string{},
+		AdvertisedAddresses: []string{},
+		NAT: NATHealth{
+			Reachability: strings.ToLower(network.ReachabilityUnknown.String()),
+			AutoNAT:      n.connectivity.AutoNAT,
+			HolePunching: n.connectivity.HolePunching,
+		},
+	}
+	if !n.started || n.stopped || n.host == nil {
+		return health
+	}
+
+	h := n.host.Host
+	health.PeerID = h.ID().String()
+	health.Peers = len(h.Network().Peers())
+	for _, addr := range h.Network().ListenAddresses() {
+		health.ListenAddresses = append(health.ListenAddresses, addr.String())
+	}
+	for _, addr := range h.Addrs() {
+		health.AdvertisedAddresses = append(health.AdvertisedAddresses, addr.String())
+		if _, err := addr.ValueForProtocol(multiaddr.P_CIRCUIT); err == nil {
+			health.NAT.RelayAddresses++
+		}
+	}
+	if n.nat != nil && n.nat.autonat != nil {
+		health.NAT.Reachability = strings.ToLower(n.nat.autonat.Status().String())
+	}
+
+	bootNodesConnected := 0
+	for _, id := range n.bootNodes {
+		connected := h.Network().Connectedness(id) == network.Connected
+		if connected {
+			bootNodesConnected++
+		}
+		health.BootNodes = append(health.BootNodes, BootNodeHealth{Peer: id, Connected: connected})
+	}
+	if n.dht != nil {
+		health.DHT = &DHTHealth{RoutingTableSize: n.dht.RoutingTable().Size()}
+	}
+
+	switch {
+	case health.Peers == 0:
+		health.Status = P2PIsolated
+	case len(n.bootNodes) > 0 && bootNodesConnected == 0, health.DHT != nil && health.DHT.RoutingTableSize == 0:
+		health.Status = P2PDegraded
+	default:
+		health.Status = P2PHealthy
+	}
+	return health
+}
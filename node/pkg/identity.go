@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// ErrIdentityExists is returned when writing an identity over the key file of another one.
+var ErrIdentityExists = errors.New("the key file already holds another identity")
+
+// Identity is the libp2p identity of the node, in the portable form written by avs identity export. The node key file
+// (--private-key) holds the protobuf encoded private key, as read by b7s.
+type Identity struct {
+	PeerId string `json:"peerId"`
+	// base64 of the protobuf encoded private key
+	PrivateKey string `json:"privateKey"`
+}
+
+// NewIdentity returns the identity of the private key.
+func NewIdentity(key crypto.PrivKey) (Identity, error) {
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return Identity{}, err
+	}
+	encoded, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{PeerId: id.String(), PrivateKey: base64.StdEncoding.EncodeToString(encoded)}, nil
+}
+
+// Key decodes the private key of the identity, checking that it is the key of its peer id.
+func (i Identity) Key() (crypto.PrivKey, error) {
+	encoded, err := base64.StdEncoding.DecodeString(i.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key encoding: %w", err)
+	}
+	key, err := crypto.UnmarshalPrivateKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	id, err := peer.IDFromPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if i.PeerId != "" && i.PeerId != id.String() {
+		return nil, fmt.Errorf("the private key is the key of peer %s, not of %s", id, i.PeerId)
+	}
+	return key, nil
+}
+
+// GenerateIdentityKey returns a new ed25519 private key, the key type b7s generates.
+func GenerateIdentityKey() (crypto.PrivKey, error) {
+	key, _, err := crypto.GenerateEd25519Key(nil)
+	return key, err
+}
+
+// ReadIdentityKey reads the private key of the node key file.
+func ReadIdentityKey(path string) (crypto.PrivKey, error) {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.UnmarshalPrivateKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a libp2p private key: %w", path, err)
+	}
+	return key, nil
+}
+
+// WriteIdentityKey writes the private key to the node key file, readable by its owner only. A key file holding another
+// key is only replaced when replace is set; writing the key it already holds does nothing.
+func WriteIdentityKey(path string, key crypto.PrivKey, replace bool) error {
+	encoded, err := crypto.MarshalPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(existing, encoded):
+		return nil
+	case err == nil && !replace:
+		return fmt.Errorf("%w: %s", ErrIdentityExists, path)
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
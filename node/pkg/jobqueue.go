@@ -0,0 +1,326 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/host"
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/request"
+	"github.com/blocklessnetwork/b7s/models/response"
+	"github.com/blocklessnetwork/b7s/node"
+	"github.com/cockroachdb/pebble"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	defaultJobMaxAge = 10 * time.Minute
+	// dispatches of an execution request, after which it's abandoned rather than re-dispatched, eg. when it made the
+	// node crash
+	maxJobAttempts = 3
+	// time given to the workers to reconnect after a restart, before the pending executions are re-dispatched
+	jobRecoveryDelay = 15 * time.Second
+	// prefix of the keys of the pending executions in the peer database
+	jobKeyPrefix = reservedKeyPrefix + "job/"
+)
+
+// states of the pending executions
+const (
+	// left pending by a restart, waiting to be re-dispatched
+	jobQueued = "queued"
+	// handed to b7s, which runs the roll call, has the workers execute the function and responds to the client
+	jobDispatched = "dispatched"
+)
+
+// outcomes of the executions left pending by a restart, as reported by the metrics
+const (
+	jobRedispatched = "redispatched"
+	jobExpired      = "expired"
+	jobAbandoned    = "abandoned"
+)
+
+// JobQueueConfig controls the persistence of the execution requests of the clients of a head node.
+type JobQueueConfig struct {
+	// age above which the executions left pending by a restart aren't re-dispatched, the client having likely given
+	// up; the executions aren't persisted when negative
+	MaxAge time.Duration
+}
+
+func ParseJobQueueFlags(c *cli.Context) JobQueueConfig {
+	cfg := JobQueueConfig{MaxAge: c.Duration(JobMaxAge.Name)}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultJobMaxAge
+	}
+	return cfg
+}
+
+// Enabled returns whether the executions are persisted.
+func (c JobQueueConfig) Enabled() bool {
+	return c.MaxAge > 0
+}
+
+// job is an execution request of a client of the head node, kept in the peer database until the node responded to it.
+type job struct {
+	Client peer.ID `json:"client"`
+	// execute message of the client, as handed to b7s
+	Request json.RawMessage `json:"request"`
+	State   string          `json:"state"`
+	// dispatches of the request, the first one included
+	Attempts   int       `json:"attempts"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// jobQueue keeps the execution requests of the clients of a head node in the peer database while b7s processes them,
+// and re-dispatches the ones left pending by a restart once the node runs again, responding to the clients with their
+// results. An execution interrupted by a restart may thus run twice, under two request ids.
+type jobQueue struct {
+	log     *zerolog.Logger
+	cfg     JobQueueConfig
+	metrics *metrics.NodeMetrics
+	ctx     context.Context
+	// b7s host and node, re-dispatching the executions and responding to the clients; the node is set once created
+	host *host.Host
+	node *node.Node
+	// routes the re-dispatched executions requiring attributes
+	capabilities *capabilities
+
+	// guards the database, which is closed along the node while the b7s handlers may still run
+	mu       sync.RWMutex
+	db       *pebble.DB
+	seq      atomic.Uint64
+	pending  atomic.Int64
+	recovery sync.Once
+}
+
+func newJobQueue(ctx context.Context, log *zerolog.Logger, cfg JobQueueConfig, db *pebble.DB, h *host.Host, capabilities *capabilities, metrics *metrics.NodeMetrics) *jobQueue {
+	return &jobQueue{log: log, cfg: cfg, metrics: metrics, ctx: ctx, host: h, capabilities: capabilities, db: db}
+}
+
+// attach returns the host wrapped to persist the execution requests the node receives until it responded to them.
+func (q *jobQueue) attach(h libp2phost.Host) libp2phost.Host {
+	return &queueingHost{Host: h, jobs: q}
+}
+
+// enqueue persists the execution request of a client, returning its id and whether it was persisted. The requests
+// relayed by another head node, which have an id, are left to it.
+func (q *jobQueue) enqueue(client peer.ID, payload []byte) (string, bool) {
+	msg, ok := parseDirectMessage(payload)
+	if !ok || msg.Type != blockless.MessageExecute {
+		return "", false
+	}
+	var req request.Execute
+	if err := json.Unmarshal(payload, &req); err != nil || req.RequestID != "" {
+		return "", false
+	}
+	now := time.Now()
+	id := fmt.Sprintf("%016x%08x", now.UnixNano(), q.seq.Add(1))
+	err := q.put(id, job{Client: client, Request: payload, State: jobDispatched, Attempts: 1, ReceivedAt: now})
+	if err != nil {
+		q.log.Warn().Err(err).Str("peer", client.String()).Str("function", req.FunctionID).Msg("could not persist execution request, it won't be recovered after a restart")
+		return "", false
+	}
+	q.metrics.SetPendingExecutions(int(q.pending.Add(1)))
+	return id, true
+}
+
+// done removes the execution request b7s is done with, unless the node is stopping, in which case b7s couldn't
+// complete it and it's left pending.
+func (q *jobQueue) done(id string) {
+	if q.ctx.Err() != nil {
+		return
+	}
+	q.remove(id)
+}
+
+// recoverPending re-dispatches the executions left pending by a restart, once the workers had time to reconnect.
+// Those older than the max age, or dispatched the max attempts already, are dropped. It must be called before the
+// node handles the direct messages, so the executions it then receives aren't taken for pending ones.
+func (q *jobQueue) recoverPending() {
+	jobs, err := q.list()
+	if err != nil {
+		q.log.Error().Err(err).Msg("could not read pending executions")
+		return
+	}
+	q.pending.Store(int64(len(jobs)))
+	q.metrics.SetPendingExecutions(len(jobs))
+	if len(jobs) > 0 {
+		go q.redispatchPending(jobs)
+	}
+}
+
+func (q *jobQueue) redispatchPending(jobs map[string]job) {
+	defer reporting.Recover()
+	q.log.Info().Int("executions", len(jobs)).Dur("delay", jobRecoveryDelay).Msg("re-dispatching executions left pending by restart")
+	select {
+	case <-q.ctx.Done():
+		return
+	case <-time.After(jobRecoveryDelay):
+	}
+
+	for id, j := range jobs {
+		log := q.log.With().Str("job", id).Str("peer", j.Client.String()).Time("received", j.ReceivedAt).Int("attempts", j.Attempts).Logger()
+		switch {
+		case time.Since(j.ReceivedAt) > q.cfg.MaxAge:
+			log.Warn().Msg("dropping pending execution older than max age")
+			q.remove(id)
+			q.metrics.AddRecoveredExecution(jobExpired)
+		case j.Attempts >= maxJobAttempts:
+			log.Error().Msg("abandoning pending execution dispatched too many times")
+			q.remove(id)
+			q.metrics.AddRecoveredExecution(jobAbandoned)
+		default:
+			go q.redispatch(id, j)
+		}
+	}
+}
+
+// redispatch executes the request of the job again and responds to the client with the results, like b7s does.
+func (q *jobQueue) redispatch(id string, j job) {
+	defer reporting.Recover()
+	log := q.log.With().Str("job", id).Str("peer", j.Client.String()).Logger()
+	var req request.Execute
+	if err := json.Unmarshal(j.Request, &req); err != nil {
+		log.Error().Err(err).Msg("dropping pending execution which could not be decoded")
+		q.remove(id)
+		return
+	}
+	j.State = jobDispatched
+	j.Attempts++
+	if err := q.put(id, j); err != nil {
+		log.Warn().Err(err).Msg("could not update pending execution")
+	}
+
+	release := q.capabilities.route(&req.Request)
+	code, requestID, results, cluster, err := q.node.ExecuteFunction(q.ctx, req.Request, req.Topic)
+	release()
+	if err != nil {
+		log.Warn().Err(err).Msg("could not re-dispatch execution")
+	}
+	if q.ctx.Err() != nil {
+		return
+	}
+	log.Info().Str("request", requestID).Str("function", req.FunctionID).Str("code", code.String()).Msg("re-dispatched pending execution")
+	res, err := json.Marshal(response.Execute{Code: code, RequestID: requestID, Results: results, Cluster: cluster})
+	if err == nil {
+		err = q.host.SendMessage(q.ctx, j.Client, res)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("request", requestID).Msg("could not respond to client with re-dispatched execution")
+	}
+	q.remove(id)
+	q.metrics.AddRecoveredExecution(jobRedispatched)
+}
+
+func (q *jobQueue) put(id string, j job) error {
+	value, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.db == nil {
+		return pebble.ErrClosed
+	}
+	return q.db.Set([]byte(jobKeyPrefix+id), value, pebble.Sync)
+}
+
+func (q *jobQueue) remove(id string) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.db == nil {
+		return
+	}
+	if err := q.db.Delete([]byte(jobKeyPrefix+id), pebble.Sync); err != nil {
+		q.log.Warn().Err(err).Str("job", id).Msg("could not remove pending execution")
+		return
+	}
+	q.metrics.SetPendingExecutions(int(q.pending.Add(-1)))
+}
+
+// list returns the pending executions by id, marking them as queued. The ones which can't be decoded are dropped.
+func (q *jobQueue) list() (map[string]job, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.db == nil {
+		return nil, pebble.ErrClosed
+	}
+	iter, err := q.db.NewIter(&pebble.IterOptions{LowerBound: []byte(jobKeyPrefix), UpperBound: []byte(jobKeyPrefix + "\xff")})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	jobs := map[string]job{}
+	batch := q.db.NewBatch()
+	defer batch.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		var j job
+		if err := json.Unmarshal(iter.Value(), &j); err != nil {
+			q.log.Warn().Err(err).Str("job", string(iter.Key()[len(jobKeyPrefix):])).Msg("dropping pending execution which could not be decoded")
+			if err := batch.Delete(iter.Key(), nil); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		j.State = jobQueued
+		value, err := json.Marshal(j)
+		if err != nil {
+			return nil, err
+		}
+		if err := batch.Set(iter.Key(), value, nil); err != nil {
+			return nil, err
+		}
+		jobs[string(iter.Key()[len(jobKeyPrefix):])] = j
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return jobs, batch.Commit(pebble.Sync)
+}
+
+// close stops the use of the database, before it's closed. The executions still processed are left pending.
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.db = nil
+}
+
+// queueingHost persists the execution requests read by the node handlers until they're done with them, and
+// re-dispatches the ones left pending once b7s handles the direct messages, which it does once subscribed to the
+// topics the roll calls are published on.
+type queueingHost struct {
+	libp2phost.Host
+	jobs *jobQueue
+}
+
+func (h *queueingHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	h.jobs.recovery.Do(h.jobs.recoverPending)
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		reader := bufio.NewReader(stream)
+		// on error, the handler reads the bytes read so far followed by the error
+		payload, _ := reader.ReadBytes('\n')
+		id, queued := h.jobs.enqueue(stream.Conn().RemotePeer(), bytes.TrimSpace(payload))
+		handler(&peekedStream{Stream: stream, reader: io.MultiReader(bytes.NewReader(payload), reader)})
+		if queued {
+			h.jobs.done(id)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/config"
+	"github.com/blocklessnetwork/b7s/executor"
+	"github.com/blocklessnetwork/b7s/executor/limits"
+	"github.com/containerd/cgroups/v3/cgroup2"
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+// how often the throttling stats of the function executions are collected
+const limitStatsInterval = 15 * time.Second
+
+// needLimiter reports whether the function executions of a worker node are resource limited.
+func needLimiter(cfg config.Config) bool {
+	return (cfg.Worker.CPUPercentageLimit > 0 && cfg.Worker.CPUPercentageLimit < 1.0) || cfg.Worker.MemoryLimitKB > 0
+}
+
+// newExecutor creates the executor running the functions of a worker node, in a cgroup enforcing the cpu percentage
+// and memory limits when they are set. The returned limiter is nil when the executions are not limited.
+func newExecutor(log *zerolog.Logger, cfg config.Config) (*executor.Executor, *limits.Limits, error) {
+	opts := []executor.Option{
+		executor.WithWorkDir(cfg.Workspace),
+		executor.WithRuntimeDir(cfg.Worker.RuntimePath),
+	}
+	if cfg.Worker.RuntimeCLI != "" {
+		opts = append(opts, executor.WithExecutableName(cfg.Worker.RuntimeCLI))
+	}
+
+	var limiter *limits.Limits
+	if needLimiter(cfg) {
+		var err error
+		limiter, err = limits.New(limits.WithCPUPercentage(cfg.Worker.CPUPercentageLimit), limits.WithMemoryKB(cfg.Worker.MemoryLimitKB))
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not create resource limiter (requires cgroups v2 and permission to create the %s cgroup): %w", limits.DefaultCgroup, err)
+		}
+		opts = append(opts, executor.WithLimiter(limiter))
+		log.Info().Float64("cpu_percentage", cfg.Worker.CPUPercentageLimit).Int64("memory_kb", cfg.Worker.MemoryLimitKB).Msg("limiting function execution resources")
+	}
+
+	exec, err := executor.New(*log, opts...)
+	if err != nil {
+		if limiter != nil {
+			limiter.Shutdown()
+		}
+		return nil, nil, fmt.Errorf("could not create executor (runtime path: %s): %w", cfg.Worker.RuntimePath, err)
+	}
+	return exec, limiter, nil
+}
+
+// collectLimitStats periodically adds the throttling events of the limited function executions to the metrics, until
+// ctx is cancelled.
+func collectLimitStats(ctx context.Context, log *zerolog.Logger, m *metrics.NodeMetrics) {
+	defer reporting.Recover()
+	cgroup, err := cgroup2.Load(limits.DefaultCgroup)
+	if err != nil {
+		log.Error().Err(err).Msg("could not load function execution cgroup, throttling is not reported")
+		return
+	}
+	// the cgroup counters are cumulative and may predate the node, so only the increase since the first collection is added
+	var last *limitStats
+	ticker := time.NewTicker(limitStatsInterval)
+	defer ticker.Stop()
+	for {
+		stats, err := cgroup.Stat()
+		if err != nil {
+			log.Warn().Err(err).Msg("could not read function execution cgroup stats")
+		} else {
+			current := limitStats{
+				throttledPeriods: stats.GetCPU().GetNrThrottled(),
+				throttledUsec:    stats.GetCPU().GetThrottledUsec(),
+				memoryMax:        stats.GetMemoryEvents().GetMax(),
+				oomKills:         stats.GetMemoryEvents().GetOomKill(),
+			}
+			if last != nil {
+				current.report(*last, m)
+			}
+			last = &current
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type limitStats struct {
+	throttledPeriods uint64
+	throttledUsec    uint64
+	memoryMax        uint64
+	oomKills         uint64
+}
+
+func (s limitStats) report(last limitStats, m *metrics.NodeMetrics) {
+	if s.throttledPeriods > last.throttledPeriods {
+		m.AddCPUThrottled(s.throttledPeriods-last.throttledPeriods, time.Duration(s.throttledUsec-last.throttledUsec)*time.Microsecond)
+	}
+	if s.memoryMax > last.memoryMax {
+		m.AddMemoryLimitEvents("max", s.memoryMax-last.memoryMax)
+	}
+	if s.oomKills > last.oomKills {
+		m.AddMemoryLimitEvents("oom_kill", s.oomKills-last.oomKills)
+	}
+}
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/libp2p/go-libp2p/p2p/host/autonat"
+	"github.com/libp2p/go-libp2p/p2p/host/autorelay"
+	basichost "github.com/libp2p/go-libp2p/p2p/host/basic"
+	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/rs/zerolog"
+)
+
+// natServices are the NAT traversal services attached to the host, each nil unless enabled.
+type natServices struct {
+	autonat autonat.AutoNAT
+	// host dialing back the peers for the AutoNAT service, from another peer id and port than the node
+	autonatDialer host.Host
+	holePunch     *holepunch.Service
+	autoRelay     *autorelay.AutoRelay
+}
+
+// startNAT attaches the NAT traversal services of the config to the host, which b7s creates without them. The returned
+// services must be closed before the host. Their metrics are reported like the ones of the host, see
+// registerLibp2pMetrics.
+func startNAT(log *zerolog.Logger, h host.Host, cfg ConnectivityConfig, psk pnet.PSK) (*natServices, error) {
+	services := &natServices{}
+	if !cfg.AutoNAT && !cfg.HolePunching && len(cfg.Relays) == 0 {
+		return services, nil
+	}
+	basic, ok := h.(*basichost.BasicHost)
+	if !ok {
+		return nil, fmt.Errorf("NAT traversal is not supported by host %T", h)
+	}
+
+	var relays []peer.AddrInfo
+	if len(cfg.Relays) > 0 {
+		addrs, err := getBootNodeAddresses(cfg.Relays)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay address: %w", err)
+		}
+		relays, err = peer.AddrInfosFromP2pAddrs(addrs...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay address: %w", err)
+		}
+	}
+
+	err := services.start(basic, cfg, psk, relays)
+	if err != nil {
+		if cerr := services.Close(); cerr != nil {
+			log.Error().Err(cerr).Msg("could not stop NAT traversal")
+		}
+		return nil, err
+	}
+	log.Info().
+		Bool("autonat", cfg.AutoNAT).
+		Bool("hole_punching", cfg.HolePunching).
+		Int("relays", len(relays)).
+		Msg("started NAT traversal")
+	return services, nil
+}
+
+func (s *natServices) start(h *basichost.BasicHost, cfg ConnectivityConfig, psk pnet.PSK, relays []peer.AddrInfo) error {
+	var err error
+	if cfg.AutoNAT {
+		opts := []libp2p.Option{libp2p.NoListenAddrs, libp2p.DisableRelay()}
+		if psk != nil {
+			// dialing back the peers of a private network takes its key
+			opts = append(opts, privateNetworkOptions(psk)...)
+		}
+		s.autonatDialer, err = libp2p.New(opts...)
+		if err != nil {
+			return fmt.Errorf("could not create AutoNAT dialer: %w", err)
+		}
+		// the AutoNAT client of the host doesn't answer the peers, this instance adds the service
+		s.autonat, err = autonat.New(h,
+			autonat.EnableService(s.autonatDialer.Network()),
+			autonat.UsingAddresses(func() []multiaddr.Multiaddr { return h.Addrs() }),
+			autonat.WithMetricsTracer(autonat.NewMetricsTracer()),
+		)
+		if err != nil {
+			return fmt.Errorf("could not start AutoNAT service: %w", err)
+		}
+	}
+	if cfg.HolePunching {
+		s.holePunch, err = holepunch.NewService(h, h.IDService(), holepunch.WithMetricsTracer(holepunch.NewMetricsTracer()))
+		if err != nil {
+			return fmt.Errorf("could not start hole punching: %w", err)
+		}
+	}
+	if len(relays) > 0 {
+		// the relay transport is enabled by default; autorelay reserves the slots once the node is found unreachable
+		s.autoRelay, err = autorelay.NewAutoRelay(h,
+			autorelay.WithStaticRelays(relays),
+			autorelay.WithMetricsTracer(autorelay.NewMetricsTracer()),
+		)
+		if err != nil {
+			return fmt.Errorf("could not start relay client: %w", err)
+		}
+		s.autoRelay.Start()
+	}
+	return nil
+}
+
+// Close stops the services in reverse order of start.
+func (s *natServices) Close() error {
+	var errs []error
+	if s.autoRelay != nil {
+		if err := s.autoRelay.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop relay client: %w", err))
+		}
+	}
+	if s.holePunch != nil {
+		if err := s.holePunch.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop hole punching: %w", err))
+		}
+	}
+	if s.autonat != nil {
+		if err := s.autonat.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop AutoNAT service: %w", err))
+		}
+	}
+	if s.autonatDialer != nil {
+		if err := s.autonatDialer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close AutoNAT dialer: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
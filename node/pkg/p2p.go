@@ -2,24 +2,31 @@ package pkg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/blocklessnetwork/b7s/config"
+	"github.com/blocklessnetwork/b7s/executor/limits"
 	"github.com/blocklessnetwork/b7s/fstore"
 	"github.com/blocklessnetwork/b7s/host"
 	"github.com/blocklessnetwork/b7s/models/blockless"
 	"github.com/blocklessnetwork/b7s/node"
 	"github.com/blocklessnetwork/b7s/peerstore"
-	"github.com/blocklessnetwork/b7s/store"
 	"github.com/cockroachdb/pebble"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/pnet"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	"github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
-)
 
-const (
-	success = 0
-	failure = 1
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
 )
 
 type PebbleNoopLogger struct{}
@@ -27,48 +34,259 @@ type PebbleNoopLogger struct{}
 func (p *PebbleNoopLogger) Infof(_ string, _ ...any)  {}
 func (p *PebbleNoopLogger) Fatalf(_ string, _ ...any) {}
 
-// // func main() {
-// // 	os.Exit(run())
-// // }
+// Node is a blockless p2p node serving the AVS protocol versions.
+// It owns its databases, libp2p host, message recorder and resource limiter, which are released by Stop.
+type Node struct {
+	log          *zerolog.Logger
+	cfg          config.Config
+	protocolCfg  ProtocolConfig
+	discovery    DiscoveryConfig
+	connectivity ConnectivityConfig
+	reputation   ReputationConfig
+	capability   CapabilityConfig
+	verification VerificationConfig
+	functions    FunctionSourceConfig
+	workspaceGC  WorkspaceGCConfig
+	concurrency  ConcurrencyConfig
+	jobQueue     JobQueueConfig
+	// receives the verifications of the executions of a head node; nil when not reported
+	onVerification func(ExecutionVerification)
+	// signs the binding of the node to its operator; nil when running without an operator
+	operator *OperatorSigner
+	recorder *MessageRecorder
+	metrics  *metrics.NodeMetrics
 
-func RunP2P(ctx context.Context, log *zerolog.Logger, cfg config.Config, done chan struct{}, failed chan struct{}, pdb *pebble.DB, fdb *pebble.DB) int {
-	// Determine node role
-	role := func() blockless.NodeRole {
-		if cfg.Role == blockless.HeadNodeLabel {
-			return blockless.HeadNode
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	cancel  context.CancelFunc
+	pdb     *pebble.DB
+	fdb     *pebble.DB
+	host    *host.Host
+	// peer ids of the boot nodes, reported by Health
+	bootNodes []peer.ID
+	// NAT traversal services attached to the host
+	nat *natServices
+	// advertises the node on the local network; nil unless mDNS discovery is enabled
+	mdns mdns.Service
+	// DHT joined for peer discovery; nil unless DHT discovery is enabled
+	dht *dht.IpfsDHT
+	// verifies the operator bindings of the peers
+	bindings *operatorBindings
+	// advertises the capabilities of a worker node, or gets the ones of the workers of a head node
+	capabilities *capabilities
+	// limits the resources of the function executions of a worker node; nil when unlimited
+	limiter *limits.Limits
+	// keeps the executions of a head node until it responded to them; nil unless enabled on a head node
+	jobs *jobQueue
+	// closed once the node main loop returned
+	done chan struct{}
+	err  error
+}
+
+// NewNode creates a node from its config, see ParseFlags. Messages are recorded or replayed through the recorder, if
+// any; the node takes ownership of the recorder and closes it on Stop. The node and libp2p metrics are registered with
+// reg.
+func NewNode(log *zerolog.Logger, cfg NodeConfig, recorder *MessageRecorder, reg prometheus.Registerer) *Node {
+	registerLibp2pMetrics(reg)
+	return &Node{
+		log:          log,
+		cfg:          cfg.Config,
+		protocolCfg:  cfg.Protocol,
+		discovery:    cfg.Discovery,
+		connectivity: cfg.Connectivity,
+		reputation:   cfg.Reputation,
+		capability:   cfg.Capability,
+		verification: cfg.Verification,
+		functions:    cfg.Functions,
+		workspaceGC:  cfg.WorkspaceGC,
+		concurrency:  cfg.Concurrency,
+		jobQueue:     cfg.JobQueue,
+		recorder:     recorder,
+		metrics:      metrics.NewNodeMetrics(reg),
+		done:         make(chan struct{}),
+	}
+}
+
+// BindOperator makes the node advertise its binding to the operator of signer, see OperatorBinding. It must be called
+// before Start.
+func (n *Node) BindOperator(signer OperatorSigner) {
+	n.operator = &signer
+}
+
+// PeerOperator returns the verified binding of the peer to its operator, if it advertised one since the node started.
+func (n *Node) PeerOperator(id peer.ID) (*OperatorBinding, bool) {
+	if n.bindings == nil {
+		return nil, false
+	}
+	return n.bindings.operator(id)
+}
+
+// OnVerification makes the node report the verifications of its executions to fn, see VerificationConfig. It must be
+// called before Start.
+func (n *Node) OnVerification(fn func(ExecutionVerification)) {
+	n.onVerification = fn
+}
+
+// PeerCapabilities returns the capabilities the worker advertised since the head node started.
+func (n *Node) PeerCapabilities(id peer.ID) (WorkerCapabilities, bool) {
+	if n.capabilities == nil {
+		return WorkerCapabilities{}, false
+	}
+	return n.capabilities.worker(id)
+}
+
+// Start opens the node databases, creates the libp2p host and starts the node main loop in a separate goroutine.
+// The main loop runs until ctx is cancelled or Stop is called. Resources acquired before a failure are released.
+func (n *Node) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.started {
+		return errors.New("node already started")
+	}
+	n.started = true
+
+	ctx, n.cancel = context.WithCancel(ctx)
+	node, err := n.setup(ctx)
+	if err != nil {
+		n.cancel()
+		close(n.done)
+		if cerr := n.closeResources(); cerr != nil {
+			n.log.Error().Err(cerr).Msg("could not release node resources")
 		}
-		return blockless.WorkerNode
+		return err
+	}
+
+	go func() {
+		defer reporting.Recover()
+		defer close(n.done)
+		n.log.Info().Str("role", n.cfg.Role).Msg("Blockless Node starting")
+		if err := node.Run(ctx); err != nil {
+			n.log.Error().Err(err).Msg("Blockless Node failed")
+			n.err = err
+		}
+		n.log.Info().Msg("Blockless Node stopped")
 	}()
+	return nil
+}
+
+// Done returns a channel which is closed once the node main loop returned; Err then reports why.
+func (n *Node) Done() <-chan struct{} {
+	return n.done
+}
+
+// Err returns the error the node main loop failed with, if any. It must only be called after Done is closed.
+func (n *Node) Err() error {
+	return n.err
+}
+
+// Stop stops the node main loop, waits for it to return and releases the node resources.
+// Calling Stop more than once, or on a node that was never started, is a no-op.
+func (n *Node) Stop() error {
+	return n.Shutdown(context.Background())
+}
+
+// Shutdown is like Stop, but gives up waiting for the main loop once ctx is done. The resources are then left open,
+// since the main loop may still use them, and are released by the process exit.
+func (n *Node) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.started || n.stopped {
+		return nil
+	}
+	n.stopped = true
+	n.cancel()
+	select {
+	case <-n.done:
+	case <-ctx.Done():
+		return fmt.Errorf("node main loop did not stop: %w", ctx.Err())
+	}
+	return n.closeResources()
+}
+
+func (n *Node) setup(ctx context.Context) (*node.Node, error) {
+	cfg := n.cfg
+
+	// Determine node role
+	role := blockless.WorkerNode
+	if cfg.Role == blockless.HeadNodeLabel {
+		role = blockless.HeadNode
+	}
 
 	// Convert workspace path to an absolute one.
 	workspace, err := filepath.Abs(cfg.Workspace)
 	if err != nil {
-		log.Error().Err(err).Str("path", cfg.Workspace).Msg("could not determine absolute path for workspace")
-		return failure
+		return nil, fmt.Errorf("could not determine absolute path for workspace (path: %s): %w", cfg.Workspace, err)
 	}
 	cfg.Workspace = workspace
 
+	// Open the pebble peer and function databases.
+	n.log.Info().Msgf("Peer database path %s", cfg.PeerDB)
+	n.pdb, err = pebble.Open(cfg.PeerDB, &pebble.Options{Logger: &PebbleNoopLogger{}})
+	if err != nil {
+		return nil, fmt.Errorf("could not open pebble peer database (path: %s): %w", cfg.PeerDB, err)
+	}
+	n.fdb, err = pebble.Open(cfg.FunctionDB, &pebble.Options{Logger: &PebbleNoopLogger{}})
+	if err != nil {
+		return nil, fmt.Errorf("could not open pebble function database (path: %s): %w", cfg.FunctionDB, err)
+	}
+	for _, db := range []struct {
+		db     *pebble.DB
+		schema DBSchema
+	}{{n.pdb, PeerDBSchema}, {n.fdb, FunctionDBSchema}} {
+		migration, err := migrateDB(db.db, db.schema)
+		if err != nil {
+			return nil, err
+		}
+		if migration.From != migration.To {
+			n.log.Info().Int("from", migration.From).Int("to", migration.To).Msgf("migrated %s database schema", db.schema.name)
+		}
+	}
+
 	// Create a new store.
-	pstore := store.New(pdb)
+	pstore := newRecordStore(n.pdb)
 	peerstore := peerstore.New(pstore)
+	lastSeenPeers := &lastSeenPeerStore{store: pstore}
 
 	// Get the list of dial back peers.
 	peers, err := peerstore.Peers()
 	if err != nil {
-		log.Error().Err(err).Msg("could not get list of dial-back peers")
-		return failure
+		return nil, fmt.Errorf("could not get list of dial-back peers: %w", err)
 	}
 
 	// Get the list of boot nodes addresses.
 	bootNodeAddrs, err := getBootNodeAddresses(cfg.BootNodes)
 	if err != nil {
-		log.Error().Err(err).Msg("could not get boot node addresses")
-		return failure
+		return nil, fmt.Errorf("could not get boot node addresses: %w", err)
+	}
+
+	// the boot nodes without a peer id aren't reported
+	for _, addr := range bootNodeAddrs {
+		if info, err := peer.AddrInfoFromP2pAddr(addr); err == nil && !slices.Contains(n.bootNodes, info.ID) {
+			n.bootNodes = append(n.bootNodes, info.ID)
+		}
+	}
+
+	// Read the pre-shared key of the private network, if any.
+	var psk pnet.PSK
+	if n.connectivity.PrivateNetworkKey != "" {
+		if n.connectivity.QUIC {
+			return nil, errors.New("QUIC is not supported in private networks")
+		}
+		psk, err = ReadPrivateNetworkKey(n.connectivity.PrivateNetworkKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	websocketTLS, err := websocketTLSConfig(n.connectivity, cfg.Connectivity.Websocket, cfg.Workspace)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create libp2p host.
-	log.Info().Str("Addresss", cfg.Connectivity.Address).Uint("Port", cfg.Connectivity.Port).Msg("Creating host")
-	host, err := host.New(*log, cfg.Connectivity.Address, cfg.Connectivity.Port,
+	n.log.Info().Str("Addresss", cfg.Connectivity.Address).Uint("Port", cfg.Connectivity.Port).Msg("Creating host")
+	n.host, err = host.New(*n.log, cfg.Connectivity.Address, cfg.Connectivity.Port,
 		host.WithPrivateKey(cfg.Connectivity.PrivateKey),
 		host.WithBootNodes(bootNodeAddrs),
 		host.WithDialBackPeers(peers),
@@ -79,62 +297,254 @@ func RunP2P(ctx context.Context, log *zerolog.Logger, cfg config.Config, done ch
 		host.WithWebsocketPort(cfg.Connectivity.WebsocketPort),
 	)
 	if err != nil {
-		log.Error().Err(err).Str("key", cfg.Connectivity.PrivateKey).Msg("could not create host")
-		return failure
+		return nil, fmt.Errorf("could not create host: %w", err)
+	}
+	if psk != nil || websocketTLS != nil {
+		n.host.Host, err = rebuildHost(n.host.Host, psk, websocketTLS)
+		if err != nil {
+			return nil, err
+		}
+		if psk != nil {
+			n.log.Info().Str("key", n.connectivity.PrivateNetworkKey).Msg("joined private network")
+		}
+		if websocketTLS != nil {
+			n.log.Info().Uint("port", cfg.Connectivity.WebsocketPort).Strs("autocert", n.connectivity.WebsocketAutocert).Msg("serving websocket over TLS")
+		}
 	}
-	defer host.Close()
 
-	log.Info().
-		Str("id", host.ID().String()).
-		Strs("addresses", host.Addresses()).
+	// the b7s direct messages are carried on the work protocols of the versions served, beneath the other wrappers
+	n.host.Host = n.protocolCfg.attach(n.host.Host)
+	if err := startPeerFilter(n.log, n.host.Host, n.connectivity); err != nil {
+		return nil, err
+	}
+	if n.connectivity.QUIC {
+		if err := listenQUIC(n.host.Host, cfg.Connectivity, n.connectivity); err != nil {
+			return nil, fmt.Errorf("could not listen for QUIC connections: %w", err)
+		}
+	}
+	if len(n.connectivity.ListenAddresses) > 0 || len(n.connectivity.AnnounceAddresses) > 0 {
+		if err := listenAddresses(n.host.Host, n.connectivity, websocketTLS); err != nil {
+			return nil, err
+		}
+		n.log.Info().Strs("listen", n.connectivity.ListenAddresses).Strs("announce", n.connectivity.AnnounceAddresses).Msg("configured host addresses")
+	}
+	n.nat, err = startNAT(n.log, n.host.Host, n.connectivity, psk)
+	if err != nil {
+		return nil, fmt.Errorf("could not start NAT traversal: %w", err)
+	}
+	if n.connectivity.ConnHighWater > 0 {
+		trimmer, err := newConnTrimmer(n.log, n.host.Host, n.connectivity, n.metrics)
+		if err != nil {
+			return nil, err
+		}
+		go trimmer.run(ctx)
+	}
+
+	// meter the streams of the node protocols, which are opened and handled through the wrapped host, throttling them
+	// to the per-peer bandwidth limit
+	n.host.Host = &meteringHost{Host: n.host.Host, metrics: n.metrics, limiter: newBandwidthLimiter(n.connectivity.PeerBandwidthLimit)}
+
+	var scores *reputations
+	if n.reputation.Enabled {
+		scores, err = loadReputations(n.log, n.reputation, lastSeenPeers, n.metrics)
+		if err != nil {
+			return nil, fmt.Errorf("could not load peer reputations: %w", err)
+		}
+		n.host.Host = scores.attach(n.host.Host)
+	}
+
+	if err := startStaticPeers(ctx, n.log, n.host.Host, n.connectivity); err != nil {
+		return nil, err
+	}
+
+	if n.recorder != nil {
+		// record direct messages as they are read by the node handlers
+		n.host.Host = &recordingHost{Host: n.host.Host, recorder: n.recorder}
+	}
+
+	n.capabilities, err = startCapabilities(ctx, n.log, n.host.Host, role, cfg, n.capability, lastSeenPeers)
+	if err != nil {
+		return nil, err
+	}
+	// the roll call responses of the ineligible workers are dropped before they're recorded
+	n.host.Host = n.capabilities.attach(n.host.Host)
+
+	// the executions of a worker are limited to the slots of the adaptive concurrency, which declines the roll calls
+	// while every slot is used
+	var adaptive *adaptiveConcurrency
+	if role == blockless.WorkerNode && n.concurrency.Adaptive {
+		adaptive = newAdaptiveConcurrency(n.log, n.concurrency, n.metrics)
+		go adaptive.run(ctx)
+		n.host.Host = adaptive.attach(n.host.Host)
+		n.log.Info().Int("min", n.concurrency.Min).Int("max", n.concurrency.Max).Msg("adapting execution concurrency to system load")
+	}
+
+	n.log.Info().
+		Str("id", n.host.ID().String()).
+		Strs("addresses", n.host.Addresses()).
 		Int("boot_nodes", len(bootNodeAddrs)).
 		Int("dial_back_peers", len(peers)).
 		Msg("created host")
 
+	n.bindings, err = startOperatorBindings(ctx, n.log, n.host.Host, n.operator, lastSeenPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == blockless.HeadNode && n.verification.Enabled() {
+		v := &verifier{
+			log:         n.log,
+			cfg:         n.verification,
+			metrics:     n.metrics,
+			reputations: scores,
+			bindings:    n.bindings,
+			report:      n.onVerification,
+			functions:   map[string]requestedFunction{},
+		}
+		n.host.Host = v.attach(n.host.Host)
+		n.log.Info().Int("workers", n.verification.Workers).Msg("verifying execution results")
+	}
+	// the execution requests are persisted as dispatched, with the worker count raised for verification
+	if role == blockless.HeadNode && n.jobQueue.Enabled() {
+		n.jobs = newJobQueue(ctx, n.log, n.jobQueue, n.pdb, n.host, n.capabilities, n.metrics)
+		n.host.Host = n.jobs.attach(n.host.Host)
+	}
+
+	if err := serveProtocolVersions(ctx, n.log, n.host, n.protocolCfg); err != nil {
+		return nil, fmt.Errorf("could not serve protocol versions: %w", err)
+	}
+
+	if n.discovery.Mdns {
+		n.mdns, err = startMdns(ctx, n.log, n.host.Host, n.discovery.MdnsService)
+		if err != nil {
+			return nil, fmt.Errorf("could not start mDNS discovery: %w", err)
+		}
+	}
+	if n.discovery.Dht {
+		n.dht, err = startDht(ctx, n.log, n.host.Host, n.discovery, role.String(), bootNodeAddrs)
+		if err != nil {
+			return nil, fmt.Errorf("could not join DHT: %w", err)
+		}
+	}
+
+	// Subscribe to the task announcement topics of every protocol version we serve.
+	topics := cfg.Topics
+	if len(topics) == 0 {
+		topics = []string{node.DefaultTopic}
+	}
+	topics = n.protocolCfg.Topics(topics, time.Now())
+	n.log.Info().Str("version", ProtocolVersion).Strs("topics", topics).Str("namespace", n.protocolCfg.TopicNamespace).Bool("legacy", n.protocolCfg.LegacyEnabled(time.Now())).Msg("serving protocol versions")
+
 	// Set node options.
 	opts := []node.Option{
 		node.WithRole(role),
+		node.WithTopics(topics),
 		node.WithConcurrency(cfg.Concurrency),
 		node.WithAttributeLoading(cfg.LoadAttributes),
 	}
 
-	functionStore := store.New(fdb)
+	// Worker nodes execute the functions, within the resource limits if any.
+	if role == blockless.WorkerNode {
+		executor, limiter, err := newExecutor(n.log, cfg)
+		if err != nil {
+			return nil, err
+		}
+		n.limiter = limiter
+		if limiter != nil {
+			go collectLimitStats(ctx, n.log, n.metrics)
+		}
+		// the execution directories left by the executions interrupted by a stop are collected, the running ones kept
+		tracker := newTrackingExecutor(executor, n.metrics)
+		if n.workspaceGC.Enabled() {
+			go collectWorkspace(ctx, n.log, cfg.Workspace, n.workspaceGC, tracker)
+		}
+		var limited blockless.Executor = tracker
+		if adaptive != nil {
+			limited = adaptive.executor(tracker)
+		}
+		opts = append(opts, node.WithExecutor(limited), node.WithWorkspace(cfg.Workspace))
+	}
 
-	// Create function store.
-	fstore := fstore.New(*log, functionStore, cfg.Workspace)
+	// Create function store, fetching the functions from the function source of a worker, if any, and verifying them.
+	var functions node.FStore = fstore.New(*n.log, newRecordStore(n.fdb), cfg.Workspace)
+	if role == blockless.WorkerNode {
+		// the records of the functions are only saved once their archive is verified
+		records := newVerifiedRecordStore(newRecordStore(n.fdb), cfg.Workspace)
+		functions = fstore.New(*n.log, records, cfg.Workspace)
+		source, err := newFunctionSource(n.functions)
+		if err != nil {
+			return nil, err
+		}
+		if source != nil {
+			functions, err = newSourcedFunctionStore(n.log, records, source, cfg.Workspace, n.functions.CacheSize)
+			if err != nil {
+				return nil, err
+			}
+			n.log.Info().Str("source", n.functions.Source).Int64("cache_mb", n.functions.CacheSize>>20).Msg("fetching functions from function source")
+		}
+		functions = newVerifiedFunctionStore(n.log, records, functions, cfg.Workspace, n.metrics)
+	}
 
 	// Instantiate node.
-	node, err := node.New(*log, host, peerstore, fstore, opts...)
+	node, err := node.New(*n.log, n.host, lastSeenPeers, functions, opts...)
 	if err != nil {
-		log.Error().Err(err).Msg("could not create node")
-		return failure
+		return nil, fmt.Errorf("could not create node: %w", err)
 	}
+	if n.jobs != nil {
+		n.jobs.node = node
+	}
+	return node, nil
+}
 
-	// Start node main loop in a separate goroutine.
-	go func() {
-
-		log.Info().
-			Str("role", role.String()).
-			Msg("Blockless Node starting")
-
-		err := node.Run(ctx)
-		if err != nil {
-			log.Error().Err(err).Msg("Blockless Node failed")
-			close(failed)
-		} else {
-			close(done)
+// closeResources releases the limiter, DHT, mDNS service, NAT traversal services, host, databases and recorder, in reverse order of acquisition.
+func (n *Node) closeResources() error {
+	var errs []error
+	if n.limiter != nil {
+		if err := n.limiter.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("could not shutdown resource limiter: %w", err))
 		}
-
-		log.Info().Msg("Blockless Node stopped")
-	}()
-
-	return success
+	}
+	if n.dht != nil {
+		if err := n.dht.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close DHT: %w", err))
+		}
+	}
+	if n.mdns != nil {
+		if err := n.mdns.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop mDNS discovery: %w", err))
+		}
+	}
+	if n.nat != nil {
+		if err := n.nat.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not stop NAT traversal: %w", err))
+		}
+	}
+	if n.host != nil {
+		if err := n.host.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close host: %w", err))
+		}
+	}
+	if n.jobs != nil {
+		n.jobs.close()
+	}
+	if n.fdb != nil {
+		if err := n.fdb.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close function database: %w", err))
+		}
+	}
+	if n.pdb != nil {
+		if err := n.pdb.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close peer database: %w", err))
+		}
+	}
+	if n.recorder != nil {
+		if err := n.recorder.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close p2p message recording: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// func needLimiter(cfg *config.Config) bool {
-// 	return cfg.CPUPercentage != 1.0 || cfg.MemoryMaxKB > 0
-// }
-
 func getBootNodeAddresses(addrs []string) ([]multiaddr.Multiaddr, error) {
 	var out []multiaddr.Multiaddr
 	for _, addr := range addrs {
@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/host/autonat"
+	"github.com/libp2p/go-libp2p/p2p/host/autorelay"
+	"github.com/libp2p/go-libp2p/p2p/host/eventbus"
+	rcmgr "github.com/libp2p/go-libp2p/p2p/host/resource-manager"
+	"github.com/libp2p/go-libp2p/p2p/net/swarm"
+	"github.com/libp2p/go-libp2p/p2p/protocol/holepunch"
+	"github.com/libp2p/go-libp2p/p2p/protocol/identify"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+// registerLibp2pMetrics registers the libp2p metrics with reg. The hosts created by b7s report them to the default
+// prometheus registerer, through collectors shared by all the hosts of the process, which reg can register too.
+func registerLibp2pMetrics(reg prometheus.Registerer) {
+	rcmgr.MustRegisterWith(reg)
+	swarm.NewMetricsTracer(swarm.WithRegisterer(reg))
+	eventbus.NewMetricsTracer(eventbus.WithRegisterer(reg))
+	identify.NewMetricsTracer(identify.WithRegisterer(reg))
+	autonat.NewMetricsTracer(autonat.WithRegisterer(reg))
+	autorelay.NewMetricsTracer(autorelay.WithRegisterer(reg))
+	holepunch.NewMetricsTracer(holepunch.WithRegisterer(reg))
+}
+
+// meteringHost wraps the libp2p host so the bytes of the streams of the node protocols (b7s direct messages, pubsub,
+// DHT) are metered by protocol and peer, and throttled by peer with a limiter, and the b7s direct messages are counted
+// by type. b7s creates the host without a bandwidth reporter, so the streams opened and handled by libp2p itself, such
+// as identify, aren't metered.
+type meteringHost struct {
+	libp2phost.Host
+	metrics *metrics.NodeMetrics
+	// nil when the bandwidth is unlimited
+	limiter *bandwidthLimiter
+}
+
+func (h *meteringHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		handler(newMeteringStream(stream, h.metrics, h.limiter))
+	})
+}
+
+func (h *meteringHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil {
+		return nil, err
+	}
+	return newMeteringStream(stream, h.metrics, h.limiter), nil
+}
+
+// meteringStream meters the bytes read and written, waiting after reading and before writing them while the peer is
+// above the bandwidth limit; the b7s direct messages it carries are counted once the stream is closed.
+type meteringStream struct {
+	network.Stream
+	metrics *metrics.NodeMetrics
+	limiter *bandwidthLimiter
+	// the bytes of the b7s direct messages, nil for the other protocols
+	read    *bytes.Buffer
+	written *bytes.Buffer
+	once    sync.Once
+}
+
+func newMeteringStream(stream network.Stream, m *metrics.NodeMetrics, limiter *bandwidthLimiter) *meteringStream {
+	s := &meteringStream{Stream: stream, metrics: m, limiter: limiter}
+	if stream.Protocol() == blockless.ProtocolID {
+		s.read, s.written = &bytes.Buffer{}, &bytes.Buffer{}
+	}
+	return s
+}
+
+func (s *meteringStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	if n > 0 {
+		s.metrics.Bandwidth().LogRecvMessageStream(int64(n), s.Protocol(), s.Conn().RemotePeer())
+		if s.read != nil {
+			s.read.Write(p[:n])
+		}
+		s.throttle("received", n)
+	}
+	return n, err
+}
+
+func (s *meteringStream) Write(p []byte) (int, error) {
+	s.throttle("sent", len(p))
+	n, err := s.Stream.Write(p)
+	if n > 0 {
+		s.metrics.Bandwidth().LogSentMessageStream(int64(n), s.Protocol(), s.Conn().RemotePeer())
+		if s.written != nil {
+			s.written.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+// throttle waits for n bytes to be available in the bandwidth of the peer; the bytes read are waited for after, which
+// slows down the peer through the flow control of the connection.
+func (s *meteringStream) throttle(direction string, n int) {
+	if s.limiter == nil {
+		return
+	}
+	if delay := s.limiter.reserve(s.Conn().RemotePeer(), direction, n, time.Now()); delay > 0 {
+		s.metrics.AddThrottledTime(direction, delay)
+		time.Sleep(delay)
+	}
+}
+
+func (s *meteringStream) Close() error {
+	s.count()
+	return s.Stream.Close()
+}
+
+func (s *meteringStream) Reset() error {
+	s.count()
+	return s.Stream.Reset()
+}
+
+func (s *meteringStream) count() {
+	if s.read == nil {
+		return
+	}
+	s.once.Do(func() {
+		s.countMessage("received", s.read.Bytes())
+		s.countMessage("sent", s.written.Bytes())
+	})
+}
+
+func (s *meteringStream) countMessage(direction string, payload []byte) {
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		return
+	}
+	msg, ok := parseDirectMessage(payload)
+	if !ok {
+		return
+	}
+	s.metrics.AddDirectMessage(direction, msg.Type)
+	if msg.Type == blockless.MessageExecuteResponse {
+		s.metrics.AddExecuteResponse(direction, msg.Code.String())
+	}
+}
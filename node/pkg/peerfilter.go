@@ -0,0 +1,111 @@
+package pkg
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/rs/zerolog"
+)
+
+// peerList matches peers by peer id or by the address (CIDR) they're connected from.
+type peerList struct {
+	ids      map[peer.ID]bool
+	networks []*net.IPNet
+}
+
+// parsePeerList parses a list of peer ids and CIDRs, such as 10.0.0.0/8.
+func parsePeerList(entries []string) (peerList, error) {
+	list := peerList{ids: map[peer.ID]bool{}}
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return peerList{}, fmt.Errorf("invalid CIDR %s: %w", entry, err)
+			}
+			list.networks = append(list.networks, network)
+			continue
+		}
+		id, err := peer.Decode(entry)
+		if err != nil {
+			return peerList{}, fmt.Errorf("invalid peer id %s: %w", entry, err)
+		}
+		list.ids[id] = true
+	}
+	return list, nil
+}
+
+func (l peerList) empty() bool {
+	return len(l.ids) == 0 && len(l.networks) == 0
+}
+
+func (l peerList) matches(id peer.ID, addr multiaddr.Multiaddr) bool {
+	if l.ids[id] {
+		return true
+	}
+	// relayed connections are matched by the address of the relay
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerFilter closes the connections of the peers which aren't allowed as soon as they're established, inbound or
+// outbound. b7s creates the host without a connection gater, which can't be set afterwards, so the connections are
+// filtered before any protocol runs on them. When the allowlist is set, only the peers it matches are allowed; the
+// denylist wins over it.
+type peerFilter struct {
+	network.NoopNotifiee
+	log   *zerolog.Logger
+	allow peerList
+	deny  peerList
+}
+
+// startPeerFilter filters the connections of the host with the allowlist and denylist of the config, if set.
+func startPeerFilter(log *zerolog.Logger, h host.Host, cfg ConnectivityConfig) error {
+	allow, err := parsePeerList(cfg.AllowPeers)
+	if err != nil {
+		return fmt.Errorf("invalid peer allowlist: %w", err)
+	}
+	deny, err := parsePeerList(cfg.DenyPeers)
+	if err != nil {
+		return fmt.Errorf("invalid peer denylist: %w", err)
+	}
+	if allow.empty() && deny.empty() {
+		return nil
+	}
+	h.Network().Notify(&peerFilter{log: log, allow: allow, deny: deny})
+	log.Info().Int("allowed", len(cfg.AllowPeers)).Int("denied", len(cfg.DenyPeers)).Msg("filtering peer connections")
+	return nil
+}
+
+func (f *peerFilter) allowed(id peer.ID, addr multiaddr.Multiaddr) bool {
+	if f.deny.matches(id, addr) {
+		return false
+	}
+	return f.allow.empty() || f.allow.matches(id, addr)
+}
+
+func (f *peerFilter) Connected(_ network.Network, conn network.Conn) {
+	if f.allowed(conn.RemotePeer(), conn.RemoteMultiaddr()) {
+		return
+	}
+	f.log.Debug().
+		Str("peer", conn.RemotePeer().String()).
+		Str("address", conn.RemoteMultiaddr().String()).
+		Msg("closing connection of peer not allowed")
+	// the connection is closed outside of the notification, which the swarm delivers holding the connection
+	// notification lock
+	go conn.Close()
+}
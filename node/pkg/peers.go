@@ -0,0 +1,174 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/cockroachdb/pebble"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// ErrPeerNotFound is returned when removing a peer which isn't in the peer database.
+var ErrPeerNotFound = errors.New("peer not found")
+
+// PeerRecord is a dial-back peer of the peer database. b7s reads the same records, ignoring the fields it doesn't know.
+type PeerRecord struct {
+	blockless.Peer
+	// last time the peer connected to the node; zero for the peers added with avs peers add
+	LastSeen time.Time `json:"lastSeen,omitempty"`
+	// score of the peer, kept by the nodes with peer scoring enabled
+	Reputation *Reputation `json:"reputation,omitempty"`
+	// binding of the peer to the operator running it, verified by the node
+	OperatorBinding *OperatorBinding `json:"operatorBinding,omitempty"`
+	// resources and attributes advertised by the worker, fetched by the head nodes
+	Capabilities *WorkerCapabilities `json:"capabilities,omitempty"`
+}
+
+// Dialable returns the multiaddrs the node dials the peer on, with the peer id, eg. for --boot-nodes.
+func (r PeerRecord) Dialable() []string {
+	addrs := r.AddrInfo.Addrs
+	if len(addrs) == 0 {
+		// like b7s, fall back to the address of the last connection
+		if addr, err := multiaddr.NewMultiaddr(r.MultiAddr); err == nil {
+			addrs = []multiaddr.Multiaddr{addr}
+		}
+	}
+	p2pAddrs, err := peer.AddrInfoToP2pAddrs(&peer.AddrInfo{ID: r.ID, Addrs: addrs})
+	if err != nil {
+		return nil
+	}
+	dialable := make([]string, 0, len(p2pAddrs))
+	for _, addr := range p2pAddrs {
+		dialable = append(dialable, addr.String())
+	}
+	return dialable
+}
+
+// lastSeenPeerStore stores the peers connecting to the node with the time they were last seen.
+type lastSeenPeerStore struct {
+	store recordStore
+	// serializes the updates of the records, which b7s and the peer reputations both write
+	mu sync.Mutex
+}
+
+func (s *lastSeenPeerStore) Store(id peer.ID, addr multiaddr.Multiaddr, info peer.AddrInfo) error {
+	return s.update(id, func(record *PeerRecord, _ bool) bool {
+		record.Peer = blockless.Peer{ID: id, MultiAddr: addr.String(), AddrInfo: info}
+		record.LastSeen = time.Now()
+		return true
+	})
+}
+
+// update reads the record of the peer, zero if not found, and stores it back once modified by fn, unless fn returns
+// false.
+func (s *lastSeenPeerStore) update(id peer.ID, fn func(record *PeerRecord, found bool) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var record PeerRecord
+	err := s.store.GetRecord(id.String(), &record)
+	if err != nil && !errors.Is(err, blockless.ErrNotFound) {
+		return fmt.Errorf("could not retrieve peer: %w", err)
+	}
+	if !fn(&record, err == nil) {
+		return nil
+	}
+	if err := s.store.SetRecord(id.String(), record); err != nil {
+		return fmt.Errorf("could not store peer: %w", err)
+	}
+	return nil
+}
+
+func (s *lastSeenPeerStore) records() ([]PeerRecord, error) {
+	return peerRecords(s.store)
+}
+
+func peerRecords(st recordStore) ([]PeerRecord, error) {
+	peers := []PeerRecord{}
+	for _, key := range st.Keys() {
+		var record PeerRecord
+		if err := st.GetRecord(key, &record); err != nil {
+			return nil, fmt.Errorf("could not retrieve peer (id: %v): %w", key, err)
+		}
+		peers = append(peers, record)
+	}
+	return peers, nil
+}
+
+// PeerDB manages the dial-back peers of the pebble peer database, which the node reconnects to on startup. The database
+// is locked by the running node, so it must be stopped first.
+type PeerDB struct {
+	db    *pebble.DB
+	store recordStore
+}
+
+// OpenPeerDB opens the peer database at path, creating it if create is set, and upgrades it to the schema version of
+// this release.
+func OpenPeerDB(path string, create bool) (*PeerDB, error) {
+	db, err := pebble.Open(path, &pebble.Options{Logger: &PebbleNoopLogger{}, ErrorIfNotExists: !create})
+	if errors.Is(err, pebble.ErrDBDoesNotExist) {
+		return nil, fmt.Errorf("no pebble peer database at %s", path)
+	}
+	if err != nil {
+		// the running node holds the database lock
+		return nil, fmt.Errorf("could not open pebble peer database (path: %s), is the node stopped?: %w", path, err)
+	}
+	if _, err := migrateDB(db, PeerDBSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &PeerDB{db: db, store: newRecordStore(db)}, nil
+}
+
+func (p *PeerDB) Close() error {
+	return p.db.Close()
+}
+
+// List returns the peers, most recently seen first.
+func (p *PeerDB) List() ([]PeerRecord, error) {
+	peers, err := peerRecords(p.store)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(peers, func(i, j int) bool { return peers[i].LastSeen.After(peers[j].LastSeen) })
+	return peers, nil
+}
+
+// Add stores a dial-back peer from its multiaddr, which must end with the peer id (/p2p/<id>). The last seen time of a
+// known peer is kept.
+func (p *PeerDB) Add(addr multiaddr.Multiaddr) (PeerRecord, error) {
+	info, err := peer.AddrInfoFromP2pAddr(addr)
+	if err != nil {
+		return PeerRecord{}, fmt.Errorf("invalid peer multiaddr %s, it must end with /p2p/<peer id>: %w", addr, err)
+	}
+	if len(info.Addrs) == 0 {
+		return PeerRecord{}, fmt.Errorf("peer multiaddr %s has no transport address", addr)
+	}
+	var record PeerRecord
+	if err := p.store.GetRecord(info.ID.String(), &record); err != nil && !errors.Is(err, blockless.ErrNotFound) {
+		return PeerRecord{}, err
+	}
+	record.Peer = blockless.Peer{ID: info.ID, MultiAddr: info.Addrs[0].String(), AddrInfo: *info}
+	if err := p.store.SetRecord(info.ID.String(), record); err != nil {
+		return PeerRecord{}, fmt.Errorf("could not store peer: %w", err)
+	}
+	return record, nil
+}
+
+// Remove deletes the peer, so the node doesn't dial it back on startup anymore.
+func (p *PeerDB) Remove(id peer.ID) error {
+	if _, err := p.store.Get(id.String()); err != nil {
+		if errors.Is(err, blockless.ErrNotFound) {
+			return ErrPeerNotFound
+		}
+		return err
+	}
+	if err := p.store.Delete(id.String()); err != nil {
+		return fmt.Errorf("could not remove peer: %w", err)
+	}
+	return nil
+}
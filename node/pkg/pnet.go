@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/pnet"
+)
+
+// ReadPrivateNetworkKey reads the pre-shared key of a private network, in the libp2p swarm key format
+// (/key/swarm/psk/1.0.0/, then /base16/ and the hex encoded 32 bytes key on the next lines).
+func ReadPrivateNetworkKey(path string) (pnet.PSK, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	psk, err := pnet.DecodeV1PSK(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a private network key: %w", path, err)
+	}
+	return psk, nil
+}
+
+// privateNetworkOptions are the libp2p options of the hosts of the private network of the pre-shared key; QUIC and
+// WebTransport don't support private networks, which are limited to TCP and websocket.
+func privateNetworkOptions(psk pnet.PSK) []libp2p.Option {
+	return []libp2p.Option{libp2p.PrivateNetwork(psk), libp2p.DefaultPrivateTransports}
+}
@@ -0,0 +1,264 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/host"
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/libp2p/go-libp2p/core/event"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/version"
+)
+
+const (
+	// ProtocolVersion is the version of the AVS p2p protocols spoken by this node.
+	ProtocolVersion = "1.1.0"
+	// LegacyProtocolVersion is the previous version, still served during the deprecation window so the
+	// fleet can be upgraded gradually instead of restarting every worker at once.
+	// Nodes on 1.0.0 predate protocol versioning; they announce tasks on unversioned topics, send direct messages on the
+	// b7s protocol and don't advertise a version.
+	LegacyProtocolVersion = "1.0.0"
+
+	versionProtocolPrefix = "/blockless-avs/version/"
+	workProtocolPrefix    = "/blockless-avs/work/"
+)
+
+var (
+	LegacyProtocolUntil = &cli.TimestampFlag{
+		Name:   "legacy-protocol-until",
+		Usage:  "serve the previous p2p protocol version until this time (RFC3339); unset serves it indefinitely",
+		Layout: time.RFC3339,
+	}
+	DisableLegacyProtocol = &cli.BoolFlag{
+		Name:  "disable-legacy-protocol",
+		Usage: "only speak the current p2p protocol version, dropping peers which haven't upgraded",
+	}
+	TopicNamespace = &cli.StringFlag{
+		Name:  "topic-namespace",
+		Usage: "namespace prefixed to the pubsub topics, so several AVSs or environments (eg. staging) can share boot nodes without cross-talk",
+	}
+)
+
+// ProtocolConfig controls which p2p protocol versions the node serves.
+type ProtocolConfig struct {
+	// serve the legacy protocol version until this time; zero means indefinitely
+	LegacyUntil   time.Time
+	DisableLegacy bool
+	// prefixed to the task announcement topics; only the nodes of the same namespace hear each other
+	TopicNamespace string
+}
+
+func ParseProtocolFlags(c *cli.Context) ProtocolConfig {
+	cfg := ProtocolConfig{
+		DisableLegacy:  c.Bool(DisableLegacyProtocol.Name),
+		TopicNamespace: strings.Trim(c.String(TopicNamespace.Name), "/"),
+	}
+	if until := c.Timestamp(LegacyProtocolUntil.Name); until != nil {
+		cfg.LegacyUntil = *until
+	}
+	return cfg
+}
+
+// BuildInfo returns the build metadata of the binary with the protocol versions it supports.
+func BuildInfo() version.Info {
+	info := version.Get()
+	info.ProtocolVersions = []string{ProtocolVersion, LegacyProtocolVersion}
+	return info
+}
+
+// LegacyEnabled reports whether the legacy protocol version is still served at the given time.
+func (c ProtocolConfig) LegacyEnabled(now time.Time) bool {
+	if c.DisableLegacy {
+		return false
+	}
+	return c.LegacyUntil.IsZero() || now.Before(c.LegacyUntil)
+}
+
+// Versions returns the protocol versions served at the given time, current version first.
+func (c ProtocolConfig) Versions(now time.Time) []string {
+	if c.LegacyEnabled(now) {
+		return []string{ProtocolVersion, LegacyProtocolVersion}
+	}
+	return []string{ProtocolVersion}
+}
+
+// Topics returns the versioned task announcement topics the node should subscribe to, in the topic namespace if any.
+// Legacy topics are only resolved at startup; nodes pick up the end of the deprecation window on their next restart.
+func (c ProtocolConfig) Topics(topics []string, now time.Time) []string {
+	var out []string
+	for _, version := range c.Versions(now) {
+		for _, topic := range topics {
+			out = append(out, NamespacedTopic(VersionedTopic(topic, version), c.TopicNamespace))
+		}
+	}
+	return out
+}
+
+// NamespacedTopic returns the name of the topic in the namespace; topics aren't namespaced by default.
+func NamespacedTopic(topic string, namespace string) string {
+	if namespace == "" {
+		return topic
+	}
+	return fmt.Sprintf("%s/%s", namespace, topic)
+}
+
+// VersionedTopic returns the name of the topic for the given protocol version.
+func VersionedTopic(topic string, version string) string {
+	if version == LegacyProtocolVersion {
+		return topic
+	}
+	return fmt.Sprintf("%s/%s", topic, version)
+}
+
+func versionProtocolID(version string) protocol.ID {
+	return protocol.ID(versionProtocolPrefix + version)
+}
+
+// WorkProtocolID returns the id of the protocol carrying the b7s direct messages (roll calls, executions and their
+// results) for the given protocol version; the legacy version speaks the b7s protocol itself.
+func WorkProtocolID(version string) protocol.ID {
+	if version == LegacyProtocolVersion {
+		return blockless.ProtocolID
+	}
+	return protocol.ID(workProtocolPrefix + version)
+}
+
+// attach returns the host wrapped to carry the b7s direct messages on the work protocols of the versions served,
+// resolved at startup like the topics. Streams report the b7s protocol, so the wrappers attached on top of it and b7s
+// itself are unaware of the versioning.
+func (c ProtocolConfig) attach(h libp2phost.Host) libp2phost.Host {
+	var pids []protocol.ID
+	for _, version := range c.Versions(time.Now()) {
+		pids = append(pids, WorkProtocolID(version))
+	}
+	return &versionedHost{Host: h, pids: pids}
+}
+
+// versionedHost serves and opens the b7s protocol streams on the work protocols, preferring the current version.
+type versionedHost struct {
+	libp2phost.Host
+	// current version first
+	pids []protocol.ID
+}
+
+func (h *versionedHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	for _, versioned := range h.pids {
+		h.Host.SetStreamHandler(versioned, func(stream network.Stream) {
+			handler(&versionedStream{Stream: stream})
+		})
+	}
+}
+
+func (h *versionedHost) RemoveStreamHandler(pid protocol.ID) {
+	if pid != blockless.ProtocolID {
+		h.Host.RemoveStreamHandler(pid)
+		return
+	}
+	for _, versioned := range h.pids {
+		h.Host.RemoveStreamHandler(versioned)
+	}
+}
+
+func (h *versionedHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	if !slices.Contains(pids, blockless.ProtocolID) {
+		return h.Host.NewStream(ctx, p, pids...)
+	}
+	// libp2p negotiates the first protocol of the list the peer supports
+	stream, err := h.Host.NewStream(ctx, p, h.pids...)
+	if err != nil {
+		return nil, err
+	}
+	return &versionedStream{Stream: stream}, nil
+}
+
+// versionedStream is a work protocol stream reported as a b7s protocol stream.
+type versionedStream struct {
+	network.Stream
+}
+
+func (s *versionedStream) Protocol() protocol.ID {
+	return blockless.ProtocolID
+}
+
+// peerSupportsVersion reports whether the peer advertised the given protocol version.
+// Peers which don't advertise any version are on the legacy version.
+func peerSupportsVersion(h *host.Host, id peer.ID, version string) (bool, error) {
+	protocols, err := h.Peerstore().GetProtocols(id)
+	if err != nil {
+		return false, err
+	}
+	advertised := false
+	for _, p := range protocols {
+		if p == versionProtocolID(version) {
+			return true, nil
+		}
+		if len(p) > len(versionProtocolPrefix) && p[:len(versionProtocolPrefix)] == versionProtocolPrefix {
+			advertised = true
+		}
+	}
+	return version == LegacyProtocolVersion && !advertised, nil
+}
+
+// serveProtocolVersions advertises the protocol versions this node speaks (through identify) and watches identified peers.
+// Peers on the legacy version are logged during the deprecation window and disconnected after it.
+//
+// NOTE: direct messages are versioned through the work protocols (see attach); health pings use the default b7s topic,
+// which is versioned by b7s itself and is unaffected.
+func serveProtocolVersions(ctx context.Context, log *zerolog.Logger, h *host.Host, cfg ProtocolConfig) error {
+	for _, version := range cfg.Versions(time.Now()) {
+		// the handler is never expected to be used, the protocol only serves as a version advertisement
+		h.SetStreamHandler(versionProtocolID(version), func(stream network.Stream) {
+			stream.Close()
+		})
+	}
+
+	sub, err := h.EventBus().Subscribe(new(event.EvtPeerIdentificationCompleted))
+	if err != nil {
+		return fmt.Errorf("could not subscribe to peer identification events: %w", err)
+	}
+	go func() {
+		defer sub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+				id := e.(event.EvtPeerIdentificationCompleted).Peer
+				current, err := peerSupportsVersion(h, id, ProtocolVersion)
+				if err != nil {
+					log.Warn().Err(err).Str("peer", id.String()).Msg("could not get peer protocols")
+					continue
+				}
+				if current {
+					continue
+				}
+				if cfg.LegacyEnabled(time.Now()) {
+					log.Warn().Str("peer", id.String()).Str("version", LegacyProtocolVersion).Msg("peer speaks a deprecated protocol version")
+					continue
+				}
+				log.Warn().Str("peer", id.String()).Str("version", ProtocolVersion).Msg("disconnecting peer which doesn't speak the current protocol version")
+				if err := h.Network().ClosePeer(id); err != nil {
+					log.Warn().Err(err).Str("peer", id.String()).Msg("could not disconnect peer")
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,266 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	RecordMessagesFile = &cli.StringFlag{
+		Name:  "record-p2p-messages",
+		Usage: "record inbound direct p2p messages to `FILE` (json lines), for replaying them later",
+	}
+	RecordMessagesBuffer = &cli.IntFlag{
+		Name:  "record-p2p-buffer",
+		Usage: "only keep the last N recorded messages in memory, writing them to the recording file on shutdown",
+	}
+)
+
+// RecordedMessage is an inbound direct message as received by the node.
+type RecordedMessage struct {
+	Time     time.Time   `json:"time"`
+	From     peer.ID     `json:"from"`
+	Protocol protocol.ID `json:"protocol"`
+	Payload  []byte      `json:"payload"`
+}
+
+// MessageRecorder records inbound direct messages (sent on the b7s work protocol) and replays them through the node handlers.
+// Messages are either appended to the recording file as they arrive, or kept in a ring buffer which is written out on Close.
+//
+// NOTE: topic (gossipsub) messages are consumed inside b7s and can't be recorded, so roll calls and health pings are not captured.
+type MessageRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+	// ring buffer of the last messages, nil when messages are written to the file as they arrive
+	ring []RecordedMessage
+	next int
+	full bool
+
+	// stream handlers registered by the node, before being wrapped with recording
+	handlersMu sync.Mutex
+	handlers   map[protocol.ID]network.StreamHandler
+	registered chan struct{}
+}
+
+// NewMessageRecorder creates a recorder writing to the given file; bufferSize > 0 keeps only the last bufferSize messages.
+func NewMessageRecorder(path string, bufferSize int) (*MessageRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open recording file: %w", err)
+	}
+	r := newRecorder()
+	r.file = file
+	if bufferSize > 0 {
+		r.ring = make([]RecordedMessage, bufferSize)
+	}
+	return r, nil
+}
+
+// NewReplayer creates a recorder which only captures the node handlers, for replaying a recording.
+func NewReplayer() *MessageRecorder {
+	return newRecorder()
+}
+
+func newRecorder() *MessageRecorder {
+	return &MessageRecorder{
+		handlers:   make(map[protocol.ID]network.StreamHandler),
+		registered: make(chan struct{}),
+	}
+}
+
+func ParseRecorderFlags(c *cli.Context) (*MessageRecorder, error) {
+	path := c.String(RecordMessagesFile.Name)
+	if path == "" {
+		return nil, nil
+	}
+	return NewMessageRecorder(path, c.Int(RecordMessagesBuffer.Name))
+}
+
+func (r *MessageRecorder) record(msg RecordedMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	if r.ring != nil {
+		r.ring[r.next] = msg
+		r.next = (r.next + 1) % len(r.ring)
+		r.full = r.full || r.next == 0
+		return nil
+	}
+	return json.NewEncoder(r.file).Encode(msg)
+}
+
+// Close flushes the ring buffer (if any) and closes the recording file.
+func (r *MessageRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	if r.ring != nil {
+		enc := json.NewEncoder(r.file)
+		msgs := r.ring[:r.next]
+		if r.full {
+			msgs = append(r.ring[r.next:], r.ring[:r.next]...)
+		}
+		for _, msg := range msgs {
+			if err := enc.Encode(msg); err != nil {
+				return err
+			}
+		}
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// LoadRecording reads the messages of a recording file, in the order they were received.
+func LoadRecording(path string) ([]RecordedMessage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var msgs []RecordedMessage
+	dec := json.NewDecoder(file)
+	for {
+		var msg RecordedMessage
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return msgs, nil
+			}
+			return nil, fmt.Errorf("could not decode recorded message %d: %w", len(msgs), err)
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// Replay feeds the messages through the node handlers, in order. When preserveTiming is set the original
+// gaps between messages are kept, otherwise messages are replayed back to back.
+func (r *MessageRecorder) Replay(ctx context.Context, msgs []RecordedMessage, preserveTiming bool) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.registered:
+	}
+	for i, msg := range msgs {
+		if preserveTiming && i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(msg.Time.Sub(msgs[i-1].Time)):
+			}
+		}
+		r.handlersMu.Lock()
+		handler, ok := r.handlers[msg.Protocol]
+		r.handlersMu.Unlock()
+		if !ok {
+			return fmt.Errorf("no handler for protocol %s (message %d)", msg.Protocol, i)
+		}
+		// handlers process the stream synchronously, so messages are replayed in order
+		handler(newReplayStream(msg))
+	}
+	return nil
+}
+
+// recordingHost wraps the libp2p host so the stream handlers registered by the node record the messages they read.
+type recordingHost struct {
+	libp2phost.Host
+	recorder *MessageRecorder
+}
+
+func (h *recordingHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	r := h.recorder
+	r.handlersMu.Lock()
+	r.handlers[pid] = handler
+	if len(r.handlers) == 1 {
+		close(r.registered)
+	}
+	r.handlersMu.Unlock()
+
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		handler(&recordingStream{Stream: stream, recorder: r, received: time.Now()})
+	})
+}
+
+// recordingStream records the bytes read by the handler once the stream is closed.
+type recordingStream struct {
+	network.Stream
+	recorder *MessageRecorder
+	received time.Time
+	buf      bytes.Buffer
+	once     sync.Once
+}
+
+func (s *recordingStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	s.buf.Write(p[:n])
+	return n, err
+}
+
+func (s *recordingStream) Close() error {
+	s.flush()
+	return s.Stream.Close()
+}
+
+func (s *recordingStream) Reset() error {
+	s.flush()
+	return s.Stream.Reset()
+}
+
+func (s *recordingStream) flush() {
+	s.once.Do(func() {
+		s.recorder.record(RecordedMessage{
+			Time:     s.received,
+			From:     s.Conn().RemotePeer(),
+			Protocol: s.Protocol(),
+			Payload:  s.buf.Bytes(),
+		})
+	})
+}
+
+// replayStream is a read-only stream serving a recorded message.
+// Only the methods used by the node handlers are implemented, the embedded interfaces are nil.
+type replayStream struct {
+	network.Stream
+	reader *bufio.Reader
+	msg    RecordedMessage
+}
+
+type replayConn struct {
+	network.Conn
+	from peer.ID
+}
+
+func newReplayStream(msg RecordedMessage) *replayStream {
+	return &replayStream{reader: bufio.NewReader(bytes.NewReader(msg.Payload)), msg: msg}
+}
+
+func (s *replayStream) Read(p []byte) (int, error)  { return s.reader.Read(p) }
+func (s *replayStream) Write(p []byte) (int, error) { return len(p), nil }
+func (s *replayStream) Close() error                { return nil }
+func (s *replayStream) Reset() error                { return nil }
+func (s *replayStream) Protocol() protocol.ID       { return s.msg.Protocol }
+func (s *replayStream) Conn() network.Conn          { return &replayConn{from: s.msg.From} }
+func (c *replayConn) RemotePeer() peer.ID           { return c.from }
@@ -0,0 +1,346 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/codes"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	defaultBanThreshold = -100
+	defaultBanDuration  = time.Hour
+	// default number of direct messages a peer may send per minute before each extra one is penalized
+	defaultMessageRate = 600
+
+	// the score of a peer is capped, so a long history of successful executions doesn't outweigh misbehaving
+	maxScore = 100
+
+	failedExecutionPenalty   = -10
+	protocolViolationPenalty = -20
+	excessiveMessagePenalty  = -1
+	resultMismatchPenalty    = -25
+	successfulExecutionScore = 1
+)
+
+// penalty reasons, as reported by the metrics
+const (
+	reasonFailedExecution   = "failed_execution"
+	reasonProtocolViolation = "protocol_violation"
+	reasonExcessiveMessages = "excessive_messages"
+	reasonResultMismatch    = "result_mismatch"
+)
+
+// directMessageTypes are the messages b7s handles on its direct message protocol.
+var directMessageTypes = []string{
+	blockless.MessageHealthCheck,
+	blockless.MessageInstallFunction,
+	blockless.MessageInstallFunctionResponse,
+	blockless.MessageRollCall,
+	blockless.MessageRollCallResponse,
+	blockless.MessageExecute,
+	blockless.MessageExecuteResponse,
+	blockless.MessageFormCluster,
+	blockless.MessageFormClusterResponse,
+	blockless.MessageDisbandCluster,
+}
+
+// directMessage is the part of the b7s direct messages the node looks at.
+type directMessage struct {
+	Type string `json:"type"`
+	// response code of the execution and roll call responses
+	Code codes.Code `json:"code"`
+}
+
+// parseDirectMessage parses a b7s direct message, reporting whether it is one.
+func parseDirectMessage(payload []byte) (directMessage, bool) {
+	var msg directMessage
+	if err := json.Unmarshal(payload, &msg); err != nil || !slices.Contains(directMessageTypes, msg.Type) {
+		return directMessage{}, false
+	}
+	return msg, true
+}
+
+// ReputationConfig controls the scoring of the peers on the direct messages they send, and the temporary bans of the
+// peers whose score drops to the threshold.
+type ReputationConfig struct {
+	Enabled bool
+	// score at or below which a peer is banned (default -100)
+	BanThreshold int
+	// time a peer is banned for (default 1h); its score is reset once the ban expires
+	BanDuration time.Duration
+	// direct messages a peer may send per minute before each extra one is penalized (default 600)
+	MessageRate int
+}
+
+func ParseReputationFlags(c *cli.Context) ReputationConfig {
+	cfg := ReputationConfig{
+		Enabled:      c.Bool(PeerScoring.Name),
+		BanThreshold: c.Int(PeerBanThreshold.Name),
+		BanDuration:  c.Duration(PeerBanDuration.Name),
+		MessageRate:  c.Int(PeerMessageRate.Name),
+	}
+	if cfg.BanThreshold == 0 {
+		cfg.BanThreshold = defaultBanThreshold
+	}
+	if cfg.BanDuration == 0 {
+		cfg.BanDuration = defaultBanDuration
+	}
+	if cfg.MessageRate == 0 {
+		cfg.MessageRate = defaultMessageRate
+	}
+	return cfg
+}
+
+// Reputation is the score of a peer, stored with its record in the peer database.
+type Reputation struct {
+	Score int `json:"score"`
+	// the peer isn't allowed to connect until then
+	BannedUntil time.Time `json:"bannedUntil,omitempty"`
+}
+
+// Banned returns whether the peer is banned at time now.
+func (r Reputation) Banned(now time.Time) bool {
+	return now.Before(r.BannedUntil)
+}
+
+type peerReputation struct {
+	Reputation
+	// start and number of direct messages of the current rate window
+	window   time.Time
+	messages int
+}
+
+// reputations scores the peers: failed executions, protocol violations (messages which aren't valid b7s direct
+// messages) and messages in excess of the rate are penalized, while successful executions slowly restore the score. A
+// peer whose score drops to the threshold is banned, its connections closed and refused until the ban expires.
+type reputations struct {
+	log     *zerolog.Logger
+	cfg     ReputationConfig
+	host    libp2phost.Host
+	peers   *lastSeenPeerStore
+	metrics *metrics.NodeMetrics
+
+	mu     sync.Mutex
+	scores map[peer.ID]*peerReputation
+}
+
+// loadReputations loads the scores of the peers of the peer database.
+func loadReputations(log *zerolog.Logger, cfg ReputationConfig, peers *lastSeenPeerStore, m *metrics.NodeMetrics) (*reputations, error) {
+	records, err := peers.records()
+	if err != nil {
+		return nil, err
+	}
+	r := &reputations{log: log, cfg: cfg, peers: peers, metrics: m, scores: map[peer.ID]*peerReputation{}}
+	banned := 0
+	for _, record := range records {
+		if record.Reputation == nil {
+			continue
+		}
+		r.scores[record.ID] = &peerReputation{Reputation: *record.Reputation}
+		if record.Reputation.Banned(time.Now()) {
+			banned++
+		}
+	}
+	log.Info().Int("scored_peers", len(r.scores)).Int("banned_peers", banned).Msg("loaded peer reputations")
+	return r, nil
+}
+
+// attach refuses the connections of the banned peers of the host, which b7s creates without a connection gater, and
+// returns the host wrapped to score the direct messages read by the node handlers.
+func (r *reputations) attach(h libp2phost.Host) libp2phost.Host {
+	r.host = h
+	h.Network().Notify(&reputationFilter{reputations: r})
+	return &scoringHost{Host: h, reputations: r}
+}
+
+// banned returns whether the peer is banned, resetting the score of a peer whose ban expired.
+func (r *reputations) banned(id peer.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep, ok := r.scores[id]
+	if !ok || rep.BannedUntil.IsZero() {
+		return false
+	}
+	if rep.Banned(time.Now()) {
+		return true
+	}
+	rep.Reputation = Reputation{}
+	r.persist(id, rep.Reputation)
+	return false
+}
+
+// observe scores a direct message read from the peer.
+func (r *reputations) observe(id peer.ID, payload []byte) {
+	payload = bytes.TrimSpace(payload)
+	// the streams closed before any message was read, such as the ones of the protocol negotiation, aren't scored
+	if len(payload) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep, ok := r.scores[id]
+	if !ok {
+		rep = &peerReputation{}
+		r.scores[id] = rep
+	}
+	now := time.Now()
+	if now.Sub(rep.window) >= time.Minute {
+		rep.window = now
+		rep.messages = 0
+	}
+	rep.messages++
+	if rep.messages > r.cfg.MessageRate {
+		r.score(id, rep, excessiveMessagePenalty, reasonExcessiveMessages)
+	}
+
+	msg, ok := parseDirectMessage(payload)
+	if !ok {
+		r.score(id, rep, protocolViolationPenalty, reasonProtocolViolation)
+		return
+	}
+	if msg.Type != blockless.MessageExecuteResponse {
+		return
+	}
+	switch msg.Code {
+	case codes.OK, codes.Accepted:
+		r.score(id, rep, successfulExecutionScore, "")
+	default:
+		r.score(id, rep, failedExecutionPenalty, reasonFailedExecution)
+	}
+}
+
+// penalize lowers the score of the peer for misbehaving beyond its messages, such as returning a result the other
+// workers of the execution disagree with.
+func (r *reputations) penalize(id peer.ID, delta int, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep, ok := r.scores[id]
+	if !ok {
+		rep = &peerReputation{}
+		r.scores[id] = rep
+	}
+	r.score(id, rep, delta, reason)
+}
+
+// score adds delta to the score of the peer, banning it once the score drops to the threshold; the reason of the
+// penalties is reported by the metrics. r.mu must be held.
+func (r *reputations) score(id peer.ID, rep *peerReputation, delta int, reason string) {
+	if rep.Banned(time.Now()) {
+		return
+	}
+	rep.Score = min(rep.Score+delta, maxScore)
+	if reason != "" {
+		r.metrics.AddPeerPenalty(reason)
+		r.log.Debug().Str("peer", id.String()).Str("reason", reason).Int("score", rep.Score).Msg("penalized peer")
+	}
+	if rep.Score <= r.cfg.BanThreshold {
+		rep.BannedUntil = time.Now().Add(r.cfg.BanDuration)
+		r.metrics.AddPeerBan()
+		r.log.Warn().Str("peer", id.String()).Int("score", rep.Score).Time("until", rep.BannedUntil).Msg("banned peer")
+		// the connections are closed outside of the stream handler reading the message
+		go func() {
+			if err := r.host.Network().ClosePeer(id); err != nil {
+				r.log.Debug().Err(err).Str("peer", id.String()).Msg("could not close connections of banned peer")
+			}
+		}()
+	}
+	r.persist(id, rep.Reputation)
+}
+
+// persist stores the reputation with the record of the peer. The peers without a record, which never connected to the
+// node, are only scored in memory, so they aren't dialed back on startup. r.mu must be held.
+func (r *reputations) persist(id peer.ID, rep Reputation) {
+	err := r.peers.update(id, func(record *PeerRecord, found bool) bool {
+		if !found {
+			return false
+		}
+		record.Reputation = &rep
+		return true
+	})
+	if err != nil {
+		r.log.Error().Err(err).Str("peer", id.String()).Msg("could not store peer reputation")
+	}
+}
+
+// reputationFilter closes the connections of the banned peers as soon as they're established.
+type reputationFilter struct {
+	network.NoopNotifiee
+	reputations *reputations
+}
+
+func (f *reputationFilter) Connected(_ network.Network, conn network.Conn) {
+	if !f.reputations.banned(conn.RemotePeer()) {
+		return
+	}
+	f.reputations.log.Debug().Str("peer", conn.RemotePeer().String()).Msg("closing connection of banned peer")
+	// the connection is closed outside of the notification, which the swarm delivers holding the connection
+	// notification lock
+	go conn.Close()
+}
+
+// scoringHost wraps the libp2p host so the direct messages read by the node handlers are scored, and the streams of the
+// banned peers are reset unread.
+type scoringHost struct {
+	libp2phost.Host
+	reputations *reputations
+}
+
+func (h *scoringHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		from := stream.Conn().RemotePeer()
+		if h.reputations.banned(from) {
+			stream.Reset()
+			return
+		}
+		handler(&scoringStream{Stream: stream, reputations: h.reputations, from: from})
+	})
+}
+
+// scoringStream scores the bytes read by the handler once the stream is closed.
+type scoringStream struct {
+	network.Stream
+	reputations *reputations
+	from        peer.ID
+	buf         bytes.Buffer
+	once        sync.Once
+}
+
+func (s *scoringStream) Read(p []byte) (int, error) {
+	n, err := s.Stream.Read(p)
+	s.buf.Write(p[:n])
+	return n, err
+}
+
+func (s *scoringStream) Close() error {
+	s.observe()
+	return s.Stream.Close()
+}
+
+func (s *scoringStream) Reset() error {
+	s.observe()
+	return s.Stream.Reset()
+}
+
+func (s *scoringStream) observe() {
+	s.once.Do(func() {
+		s.reputations.observe(s.from, s.buf.Bytes())
+	})
+}
@@ -0,0 +1,159 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/execute"
+	"github.com/blocklessnetwork/b7s/models/request"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// matchAttributes returns why the worker doesn't have the attributes an execution requires, if it doesn't. The
+// attributes of its attestation, verified when its capabilities were fetched, are matched along with the ones it
+// advertises, unless attested attributes are required.
+func matchAttributes(want execute.Attributes, worker WorkerCapabilities) error {
+	attestation := worker.Attestation
+	if want.AttestationRequired && (attestation == nil || len(attestation.Attestors) == 0) {
+		return errors.New("attested attributes required, the worker has no attestor")
+	}
+
+	var attestors []peer.ID
+	if attestation != nil {
+		for _, attestor := range attestation.Attestors {
+			attestors = append(attestors, attestor.Signer)
+		}
+	}
+	for _, attestor := range want.Attestors.Each {
+		if !slices.Contains(attestors, attestor) {
+			return fmt.Errorf("attestor %s required, not found", attestor)
+		}
+	}
+	if len(want.Attestors.OneOf) > 0 && !slices.ContainsFunc(want.Attestors.OneOf, func(attestor peer.ID) bool { return slices.Contains(attestors, attestor) }) {
+		return fmt.Errorf("one of the attestors %v required, none found", blockless.PeerIDsToStr(want.Attestors.OneOf))
+	}
+
+	have := map[string]string{}
+	if !want.AttestationRequired {
+		for name, value := range worker.Attributes {
+			have[name] = value
+		}
+	}
+	if attestation != nil {
+		for _, attribute := range attestation.Attributes {
+			have[attribute.Name] = attribute.Value
+		}
+	}
+	for _, attribute := range want.Values {
+		if value, ok := have[attribute.Name]; !ok || value != attribute.Value {
+			return fmt.Errorf("attribute %s=%q, %q required", attribute.Name, value, attribute.Value)
+		}
+	}
+	return nil
+}
+
+// functionRoute serializes the executions of a function while one requiring attributes runs. b7s matches the roll
+// call responses to the executions by a request id the head node doesn't see, so the responses for the function are
+// then the ones of that execution.
+type functionRoute struct {
+	lock sync.RWMutex
+	// executions of the function running or waiting to, the route being dropped once there's none
+	executions int
+	// attributes required by the running execution; nil when it requires none
+	required *execute.Attributes
+}
+
+// route strips the attributes the execution requires from the request, so the workers don't select themselves on the
+// attributes b7s loaded, and restricts the workers of the execution to the ones with the attributes until release is
+// called, once the execution is done.
+func (c *capabilities) route(req *execute.Request) (release func()) {
+	required := req.Config.Attributes
+	req.Config.Attributes = nil
+
+	c.mu.Lock()
+	route, ok := c.routes[req.FunctionID]
+	if !ok {
+		route = &functionRoute{}
+		c.routes[req.FunctionID] = route
+	}
+	route.executions++
+	c.mu.Unlock()
+
+	done := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		route.executions--
+		if route.executions == 0 {
+			delete(c.routes, req.FunctionID)
+		}
+	}
+	if required == nil {
+		route.lock.RLock()
+		return func() {
+			route.lock.RUnlock()
+			done()
+		}
+	}
+
+	route.lock.Lock()
+	c.mu.Lock()
+	route.required = required
+	c.mu.Unlock()
+	c.log.Info().Str("function", req.FunctionID).Strs("attributes", executionAttributeList(*required)).Bool("attested", required.AttestationRequired).Msg("selecting workers of execution on their attributes")
+	return func() {
+		c.mu.Lock()
+		route.required = nil
+		c.mu.Unlock()
+		route.lock.Unlock()
+		done()
+	}
+}
+
+// routeMessage routes the execution request of a client, returning the message without the required attributes.
+// The requests relayed by another head node, which have an id, are left unchanged.
+func (c *capabilities) routeMessage(payload []byte) ([]byte, func()) {
+	var msg map[string]json.RawMessage
+	var req request.Execute
+	if err := json.Unmarshal(payload, &msg); err != nil || json.Unmarshal(payload, &req) != nil || req.RequestID != "" {
+		return payload, func() {}
+	}
+	required := req.Config.Attributes != nil
+	release := c.route(&req.Request)
+	if !required {
+		return payload, release
+	}
+	// the fields b7s doesn't know of are kept
+	var cfg map[string]json.RawMessage
+	if err := json.Unmarshal(msg["config"], &cfg); err == nil {
+		delete(cfg, "attributes")
+		msg["config"], _ = json.Marshal(cfg)
+	}
+	out, err := json.Marshal(msg)
+	if err != nil {
+		return payload, release
+	}
+	return append(out, '\n'), release
+}
+
+// required returns the attributes required by the running execution of the function, if any.
+func (c *capabilities) required(functionID string) *execute.Attributes {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if route, ok := c.routes[functionID]; ok {
+		return route.required
+	}
+	return nil
+}
+
+// executionAttributeList returns the attributes required by an execution in the name=value format.
+func executionAttributeList(want execute.Attributes) []string {
+	list := make([]string, 0, len(want.Values))
+	for _, attribute := range want.Values {
+		list = append(list, attribute.Name+"="+attribute.Value)
+	}
+	return list
+}
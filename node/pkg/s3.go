@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	// the objects are fetched with unsigned payloads, GET requests having no body
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// s3Credentials are the credentials the S3 requests are signed with; the requests are anonymous without an access key.
+type s3Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// s3CredentialsFromEnv reads the credentials from the standard AWS environment variables.
+func s3CredentialsFromEnv() s3Credentials {
+	return s3Credentials{
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// s3Source fetches the function bundles from a bucket of an S3-compatible object storage, with path-style requests so
+// the endpoints of self-hosted storages, such as MinIO, work without DNS setup.
+type s3Source struct {
+	endpoint    *url.URL
+	region      string
+	bucket      string
+	prefix      string
+	credentials s3Credentials
+	client      *http.Client
+}
+
+func (s *s3Source) location(key string) string {
+	return "s3://" + s.bucket + "/" + s.objectKey(key)
+}
+
+func (s *s3Source) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+	return s.prefix + "/" + key
+}
+
+func (s *s3Source) get(ctx context.Context, key string) (io.ReadCloser, error) {
+	u := *s.endpoint
+	segments := append([]string{s.bucket}, strings.Split(s.objectKey(key), "/")...)
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + "/" + strings.Join(segments, "/")
+	u.Path, _ = url.PathUnescape(u.RawPath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.credentials.AccessKey != "" {
+		s.sign(req, time.Now().UTC())
+	}
+	return fetch(s.client, req)
+}
+
+// sign signs the request with AWS signature version 4.
+func (s *s3Source) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if s.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.SecretKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.credentials.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape escapes a segment of an object key the way S3 canonicalizes it: every byte but the unreserved characters.
+func s3Escape(segment string) string {
+	var b strings.Builder
+	for _, c := range []byte(segment) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// gatewaySource fetches the function bundles from an IPFS gateway, the key starting with the CID of the bundle.
+type gatewaySource struct {
+	gateway *url.URL
+	client  *http.Client
+}
+
+func (g *gatewaySource) location(key string) string {
+	return strings.TrimSuffix(g.gateway.String(), "/") + "/ipfs/" + key
+}
+
+func (g *gatewaySource) get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.location(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetch(g.client, req)
+}
+
+// fetch returns the body of the response to req, which must be successful.
+func fetch(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		res.Body.Close()
+		return nil, fmt.Errorf("could not get %s: status %d: %s", req.URL.Redacted(), res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return res.Body, nil
+}
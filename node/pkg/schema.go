@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blocklessnetwork/b7s/store"
+	"github.com/cockroachdb/pebble"
+)
+
+const (
+	// prefix of the keys of the peer and function databases which aren't records, since peer ids and CIDs can't start
+	// with it
+	reservedKeyPrefix = "_"
+	schemaVersionKey  = reservedKeyPrefix + "schema_version"
+)
+
+// ErrDBSchemaTooNew is returned for a database written by a newer release, whose records this one may not read.
+var ErrDBSchemaTooNew = errors.New("database schema is newer than this release supports")
+
+// schemaMigration upgrades the records of a database from the previous schema version, writing them to the batch,
+// which is committed along with the new version.
+type schemaMigration func(db *pebble.DB, batch *pebble.Batch) error
+
+// DBSchema is the schema of the records of a database, upgraded from the unversioned records by its migrations: the
+// version after a migration is its index plus one. A nil migration only sets the version.
+type DBSchema struct {
+	name       string
+	migrations []schemaMigration
+}
+
+// Version returns the schema version of the database in this release.
+func (s DBSchema) Version() int {
+	return len(s.migrations)
+}
+
+var (
+	// version 1: the records of the previous releases, b7s peers with the last seen time, reputation, operator binding
+	// and capabilities
+	PeerDBSchema = DBSchema{name: "peer", migrations: []schemaMigration{nil}}
+	// version 1: the records of the b7s function store
+	FunctionDBSchema = DBSchema{name: "function", migrations: []schemaMigration{nil}}
+)
+
+// SchemaMigration is the outcome of the migration of a database, whose schema version was From and is now To.
+type SchemaMigration struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// MigrateDB upgrades the pebble database at path to the schema version of this release. The database is locked by the
+// running node, which migrates it on startup, so it must be stopped first.
+func MigrateDB(path string, schema DBSchema) (SchemaMigration, error) {
+	db, err := openExistingDB(path, false)
+	if err != nil {
+		return SchemaMigration{}, err
+	}
+	defer db.Close()
+	return migrateDB(db, schema)
+}
+
+// migrateDB upgrades the records of the database to the schema version of this release, one migration at a time, each
+// committed atomically with its version, so an interrupted migration resumes from the last one committed. Databases
+// of a newer version are refused.
+func migrateDB(db *pebble.DB, schema DBSchema) (SchemaMigration, error) {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return SchemaMigration{}, err
+	}
+	migration := SchemaMigration{From: version, To: version}
+	if version > schema.Version() {
+		return migration, fmt.Errorf("%w: %s database at version %d, this release supports up to version %d",
+			ErrDBSchemaTooNew, schema.name, version, schema.Version())
+	}
+	for ; version < schema.Version(); version++ {
+		if err := applyMigration(db, schema.migrations[version], version+1); err != nil {
+			return migration, fmt.Errorf("could not migrate %s database to version %d: %w", schema.name, version+1, err)
+		}
+		migration.To = version + 1
+	}
+	return migration, nil
+}
+
+func applyMigration(db *pebble.DB, migrate schemaMigration, version int) error {
+	batch := db.NewBatch()
+	defer batch.Close()
+	if migrate != nil {
+		if err := migrate(db, batch); err != nil {
+			return err
+		}
+	}
+	value, err := json.Marshal(version)
+	if err != nil {
+		return err
+	}
+	if err := batch.Set([]byte(schemaVersionKey), value, nil); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// schemaVersion returns the schema version of the database, 0 for the unversioned ones.
+func schemaVersion(db *pebble.DB) (int, error) {
+	value, closer, err := db.Get([]byte(schemaVersionKey))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not read schema version: %w", err)
+	}
+	defer closer.Close()
+	var version int
+	if err := json.Unmarshal(value, &version); err != nil {
+		return 0, fmt.Errorf("could not decode schema version: %w", err)
+	}
+	return version, nil
+}
+
+// recordStore is the b7s store of a versioned database, whose keys are the ones of the records, without the reserved
+// ones, since b7s reads every key as a record.
+type recordStore struct {
+	*store.Store
+}
+
+func newRecordStore(db *pebble.DB) recordStore {
+	return recordStore{Store: store.New(db)}
+}
+
+func (s recordStore) Keys() []string {
+	return slices.DeleteFunc(s.Store.Keys(), func(key string) bool { return strings.HasPrefix(key, reservedKeyPrefix) })
+}
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/rs/zerolog"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+)
+
+const (
+	// connection manager tag protecting the static peers from being trimmed
+	staticPeerTag = "blockless-avs-static-peer"
+	// interval of checking that the static peers are connected, besides reconnecting as soon as they disconnect
+	staticPeerInterval    = 30 * time.Second
+	staticPeerDialTimeout = 10 * time.Second
+	// delay before redialing a peer which disconnected, so the peers whose connections are closed right away, eg. by the
+	// peer filter, aren't redialed in a loop
+	staticPeerRedialDelay = time.Second
+)
+
+// staticPeers keeps the node connected to the static peers: unlike the boot nodes, which are only dialed on startup,
+// they are reconnected whenever they disconnect, and protected from the connection trimming.
+type staticPeers struct {
+	log   *zerolog.Logger
+	host  host.Host
+	peers []peer.AddrInfo
+	// signaled when a static peer disconnected
+	disconnected chan struct{}
+}
+
+// startStaticPeers connects the host to the static peers of the config, if any, and keeps them connected until ctx is
+// done.
+func startStaticPeers(ctx context.Context, log *zerolog.Logger, h host.Host, cfg ConnectivityConfig) error {
+	if len(cfg.StaticPeers) == 0 {
+		return nil
+	}
+	addrs, err := getBootNodeAddresses(cfg.StaticPeers)
+	if err != nil {
+		return fmt.Errorf("invalid static peer address: %w", err)
+	}
+	peers, err := peer.AddrInfosFromP2pAddrs(addrs...)
+	if err != nil {
+		return fmt.Errorf("invalid static peer address: %w", err)
+	}
+	s := &staticPeers{log: log, host: h, peers: peers, disconnected: make(chan struct{}, 1)}
+	for _, p := range peers {
+		h.ConnManager().Protect(p.ID, staticPeerTag)
+		h.Peerstore().AddAddrs(p.ID, p.Addrs, peerstore.PermanentAddrTTL)
+	}
+	h.Network().Notify(&network.NotifyBundle{DisconnectedF: s.onDisconnected})
+	go s.run(ctx)
+	log.Info().Int("static_peers", len(peers)).Msg("keeping static peers connected")
+	return nil
+}
+
+func (s *staticPeers) onDisconnected(_ network.Network, conn network.Conn) {
+	for _, p := range s.peers {
+		if p.ID == conn.RemotePeer() {
+			select {
+			case s.disconnected <- struct{}{}:
+			default:
+			}
+			return
+		}
+	}
+}
+
+func (s *staticPeers) run(ctx context.Context) {
+	defer reporting.Recover()
+	ticker := time.NewTicker(staticPeerInterval)
+	defer ticker.Stop()
+	for {
+		s.connect(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.disconnected:
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(staticPeerRedialDelay):
+			}
+		}
+	}
+}
+
+// connect dials the static peers which aren't connected.
+func (s *staticPeers) connect(ctx context.Context) {
+	for _, p := range s.peers {
+		// the peer may still have other connections open
+		if s.host.Network().Connectedness(p.ID) == network.Connected {
+			continue
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, staticPeerDialTimeout)
+		err := s.host.Connect(dialCtx, p)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.log.Warn().Err(err).Str("peer", p.ID.String()).Msg("could not connect to static peer")
+			continue
+		}
+		s.log.Info().Str("peer", p.ID.String()).Msg("connected to static peer")
+	}
+}
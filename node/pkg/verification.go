@@ -0,0 +1,329 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/execute"
+	"github.com/blocklessnetwork/b7s/models/request"
+	"github.com/blocklessnetwork/b7s/models/response"
+	"github.com/blocklessnetwork/b7s/node"
+	"github.com/ethereum/go-ethereum/common"
+	libp2phost "github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+// verification outcomes, as reported by the metrics
+const (
+	// every worker returned the same result
+	VerificationAgreed = "agreed"
+	// some workers returned a different result, or no result
+	VerificationDisputed = "disputed"
+	// fewer than two workers returned a result, eg. with raft consensus where only the leader responds
+	VerificationUnverified = "unverified"
+)
+
+// how long the function of an execution request sent to the workers is kept, waiting for the response to the client;
+// twice the time the head node waits for the worker results, the response being sent once the execution timed out
+const verificationRequestTTL = 2 * node.DefaultExecutionTimeout
+
+// VerificationConfig controls the redundant execution of the functions by a head node.
+type VerificationConfig struct {
+	// workers each execution is dispatched to, at least, for their results to be compared; disabled below 2
+	Workers int
+}
+
+func ParseVerificationFlags(c *cli.Context) VerificationConfig {
+	return VerificationConfig{Workers: c.Int(VerifyExecutions.Name)}
+}
+
+// Enabled returns whether the executions are verified.
+func (c VerificationConfig) Enabled() bool {
+	return c.Workers >= 2
+}
+
+// ExecutionVerification is the comparison of the results the workers returned for an execution of the head node.
+type ExecutionVerification struct {
+	RequestID  string `json:"requestId"`
+	FunctionID string `json:"functionId,omitempty"`
+	Outcome    string `json:"outcome"`
+	// hash of the result returned by the majority of the workers; empty without a majority
+	Result  string           `json:"result,omitempty"`
+	Workers []VerifiedWorker `json:"workers"`
+}
+
+// Dissenting returns the workers which didn't return the majority result.
+func (v ExecutionVerification) Dissenting() []VerifiedWorker {
+	var dissenting []VerifiedWorker
+	for _, worker := range v.Workers {
+		if !worker.Agrees {
+			dissenting = append(dissenting, worker)
+		}
+	}
+	return dissenting
+}
+
+// VerifiedWorker is a worker of a verified execution.
+type VerifiedWorker struct {
+	Peer peer.ID `json:"peer"`
+	// operator the worker is bound to, if it advertised a verified binding
+	Operator *common.Address `json:"operator,omitempty"`
+	// hash of the result returned by the worker; empty when it returned none
+	Result string `json:"result,omitempty"`
+	// whether the worker returned the majority result
+	Agrees bool `json:"agrees"`
+}
+
+// resultHash returns the hash of the output of an execution. The stderr and resource usage, which differ from one
+// worker to another, aren't part of it.
+func resultHash(result execute.Result) string {
+	h := sha256.New()
+	h.Write([]byte(result.Code))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(result.Result.ExitCode)))
+	h.Write([]byte{0})
+	h.Write([]byte(result.Result.Stdout))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// verifyResults compares the results of the workers of the cluster of an execution.
+func verifyResults(requestID string, functionID string, cluster []peer.ID, results execute.ResultMap) ExecutionVerification {
+	v := ExecutionVerification{RequestID: requestID, FunctionID: functionID}
+	hashes := make(map[peer.ID]string, len(results))
+	counts := map[string]int{}
+	for id, result := range results {
+		hash := resultHash(result)
+		hashes[id] = hash
+		counts[hash]++
+	}
+	for hash, count := range counts {
+		if count*2 > len(results) {
+			v.Result = hash
+		}
+	}
+
+	workers := slices.Clone(cluster)
+	for id := range results {
+		if !slices.Contains(workers, id) {
+			workers = append(workers, id)
+		}
+	}
+	agreeing := 0
+	for _, id := range workers {
+		worker := VerifiedWorker{Peer: id, Result: hashes[id]}
+		worker.Agrees = v.Result != "" && worker.Result == v.Result
+		if worker.Agrees {
+			agreeing++
+		}
+		v.Workers = append(v.Workers, worker)
+	}
+
+	switch {
+	case len(results) < 2:
+		v.Outcome = VerificationUnverified
+	case agreeing == len(v.Workers):
+		v.Outcome = VerificationAgreed
+	default:
+		v.Outcome = VerificationDisputed
+	}
+	return v
+}
+
+// verifier makes a head node dispatch each execution to several workers, and compares their results once the node
+// responds to the client with them. The workers returning another result than the majority, or none, are reported;
+// the ones returning another result are penalized when the peers are scored.
+type verifier struct {
+	log         *zerolog.Logger
+	cfg         VerificationConfig
+	metrics     *metrics.NodeMetrics
+	reputations *reputations
+	bindings    *operatorBindings
+	report      func(ExecutionVerification)
+
+	mu sync.Mutex
+	// functions of the executions requested of the workers, by request id, until the response or their ttl
+	functions map[string]requestedFunction
+}
+
+type requestedFunction struct {
+	functionID string
+	sentAt     time.Time
+}
+
+// attach returns the host wrapped to raise the worker count of the execution requests the node receives, and to
+// verify the results of the execution responses it sends.
+func (v *verifier) attach(h libp2phost.Host) libp2phost.Host {
+	return &verifyingHost{Host: h, verifier: v}
+}
+
+// dispatch returns the execution request of a client with the worker count raised to the verification one, and
+// whether it was raised.
+func (v *verifier) dispatch(payload []byte) ([]byte, bool) {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, false
+	}
+	var req request.Execute
+	if err := json.Unmarshal(payload, &req); err != nil || req.RequestID != "" || req.Config.NodeCount >= v.cfg.Workers {
+		// the requests with an id are relayed by another head node
+		return nil, false
+	}
+	// the fields b7s doesn't know of are kept
+	var cfg map[string]json.RawMessage
+	if raw, ok := msg["config"]; ok {
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return nil, false
+		}
+	}
+	if cfg == nil {
+		cfg = map[string]json.RawMessage{}
+	}
+	cfg["number_of_nodes"], _ = json.Marshal(v.cfg.Workers)
+	msg["config"], _ = json.Marshal(cfg)
+	out, err := json.Marshal(msg)
+	if err != nil {
+		return nil, false
+	}
+	v.log.Debug().Str("function", req.FunctionID).Int("requested", req.Config.NodeCount).Int("workers", v.cfg.Workers).Msg("dispatching execution to more workers for verification")
+	return append(out, '\n'), true
+}
+
+// sent keeps track of the execution requests sent to the workers, and verifies the results of the execution
+// responses sent to the clients.
+func (v *verifier) sent(payload []byte) {
+	msg, ok := parseDirectMessage(payload)
+	if !ok {
+		return
+	}
+	switch msg.Type {
+	case blockless.MessageExecute:
+		var req request.Execute
+		if err := json.Unmarshal(payload, &req); err != nil || req.RequestID == "" {
+			return
+		}
+		now := time.Now()
+		v.mu.Lock()
+		// the requests whose response wasn't sent, eg. failing to form a cluster, are pruned
+		for id, requested := range v.functions {
+			if now.Sub(requested.sentAt) > verificationRequestTTL {
+				delete(v.functions, id)
+			}
+		}
+		v.functions[req.RequestID] = requestedFunction{functionID: req.FunctionID, sentAt: now}
+		v.mu.Unlock()
+	case blockless.MessageExecuteResponse:
+		var res response.Execute
+		if err := json.Unmarshal(payload, &res); err != nil || res.RequestID == "" {
+			return
+		}
+		v.mu.Lock()
+		requested, ok := v.functions[res.RequestID]
+		delete(v.functions, res.RequestID)
+		v.mu.Unlock()
+		// the responses of the workers to another head node are sent with the same message
+		if !ok {
+			return
+		}
+		go v.verify(verifyResults(res.RequestID, requested.functionID, res.Cluster.Peers, res.Results))
+	}
+}
+
+func (v *verifier) verify(verification ExecutionVerification) {
+	defer reporting.Recover()
+	for i, worker := range verification.Workers {
+		if binding, ok := v.bindings.operator(worker.Peer); ok {
+			verification.Workers[i].Operator = &binding.Operator
+		}
+	}
+	v.metrics.AddExecutionVerification(verification.Outcome)
+
+	log := v.log.With().Str("request", verification.RequestID).Str("function", verification.FunctionID).Int("workers", len(verification.Workers)).Logger()
+	switch verification.Outcome {
+	case VerificationAgreed:
+		log.Debug().Str("result", verification.Result).Msg("workers agreed on execution result")
+	case VerificationUnverified:
+		log.Warn().Msg("could not verify execution result, fewer than two workers returned one")
+	case VerificationDisputed:
+		for _, worker := range verification.Dissenting() {
+			log.Warn().Str("peer", worker.Peer.String()).Str("result", worker.Result).Str("majority", verification.Result).Msg("worker disputed execution result")
+			// the workers which returned no result, eg. timing out, aren't penalized
+			if v.reputations != nil && worker.Result != "" {
+				v.reputations.penalize(worker.Peer, resultMismatchPenalty, reasonResultMismatch)
+			}
+		}
+	}
+	if v.report != nil {
+		v.report(verification)
+	}
+}
+
+// verifyingHost raises the worker count of the execution requests read by the node handlers, and verifies the
+// execution responses the node sends.
+type verifyingHost struct {
+	libp2phost.Host
+	verifier *verifier
+}
+
+func (h *verifyingHost) SetStreamHandler(pid protocol.ID, handler network.StreamHandler) {
+	if pid != blockless.ProtocolID {
+		h.Host.SetStreamHandler(pid, handler)
+		return
+	}
+	h.Host.SetStreamHandler(pid, func(stream network.Stream) {
+		reader := bufio.NewReader(stream)
+		// on error, the handler reads the bytes read so far followed by the error
+		payload, _ := reader.ReadBytes('\n')
+		if msg, ok := parseDirectMessage(bytes.TrimSpace(payload)); ok && msg.Type == blockless.MessageExecute {
+			if dispatched, ok := h.verifier.dispatch(bytes.TrimSpace(payload)); ok {
+				payload = dispatched
+			}
+		}
+		handler(&peekedStream{Stream: stream, reader: io.MultiReader(bytes.NewReader(payload), reader)})
+	})
+}
+
+func (h *verifyingHost) NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error) {
+	stream, err := h.Host.NewStream(ctx, p, pids...)
+	if err != nil || stream.Protocol() != blockless.ProtocolID {
+		return stream, err
+	}
+	return &verifyingStream{Stream: stream, verifier: h.verifier}, nil
+}
+
+// verifyingStream hands the message written to the verifier once the stream is closed.
+type verifyingStream struct {
+	network.Stream
+	verifier *verifier
+	buf      bytes.Buffer
+	once     sync.Once
+}
+
+func (s *verifyingStream) Write(p []byte) (int, error) {
+	n, err := s.Stream.Write(p)
+	s.buf.Write(p[:n])
+	return n, err
+}
+
+func (s *verifyingStream) Close() error {
+	s.once.Do(func() {
+		s.verifier.sent(bytes.TrimSpace(s.buf.Bytes()))
+	})
+	return s.Stream.Close()
+}
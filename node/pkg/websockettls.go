@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/multiformats/go-multiaddr"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// directory of the certificates obtained with autocert in the workspace, by default
+const autocertDir = "autocert"
+
+// websocketTLSConfig returns the TLS config serving the websocket transport over TLS (wss), from the certificate
+// files or autocert; nil when neither is configured.
+func websocketTLSConfig(cfg ConnectivityConfig, websocket bool, workspace string) (*tls.Config, error) {
+	files := cfg.WebsocketTLSCert != "" || cfg.WebsocketTLSKey != ""
+	if !files && len(cfg.WebsocketAutocert) == 0 {
+		return nil, nil
+	}
+	if !websocket {
+		return nil, fmt.Errorf("serving the websocket transport over TLS requires --%s", Websocket.Name)
+	}
+	if files && len(cfg.WebsocketAutocert) > 0 {
+		return nil, fmt.Errorf("--%s and --%s are exclusive", WebsocketTLSCert.Name, WebsocketAutocert.Name)
+	}
+
+	if files {
+		if cfg.WebsocketTLSCert == "" || cfg.WebsocketTLSKey == "" {
+			return nil, fmt.Errorf("both --%s and --%s are required", WebsocketTLSCert.Name, WebsocketTLSKey.Name)
+		}
+		certificate := &certificateFiles{certFile: cfg.WebsocketTLSCert, keyFile: cfg.WebsocketTLSKey}
+		if _, err := certificate.get(nil); err != nil {
+			return nil, err
+		}
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certificate.get,
+			// the websocket connections are upgraded from HTTP/1.1, which the handshake must select over HTTP/2
+			NextProtos: []string{"http/1.1"},
+		}, nil
+	}
+
+	cache := cfg.WebsocketAutocertCache
+	if cache == "" {
+		cache = filepath.Join(workspace, autocertDir)
+	}
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.WebsocketAutocert...),
+		Cache:      autocert.DirCache(cache),
+	}
+	tlsConfig := manager.TLSConfig()
+	// without HTTP/2 for the websocket upgrade; the TLS-ALPN-01 challenges are answered on the websocket port
+	tlsConfig.NextProtos = []string{"http/1.1", acme.ALPNProto}
+	return tlsConfig, nil
+}
+
+// certificateFiles loads the certificate from its files, reloading it when they change, eg. once renewed.
+type certificateFiles struct {
+	certFile, keyFile string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+}
+
+func (c *certificateFiles) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certInfo, certErr := os.Stat(c.certFile)
+	keyInfo, keyErr := os.Stat(c.keyFile)
+	if err := errors.Join(certErr, keyErr); err != nil {
+		return nil, fmt.Errorf("could not read TLS certificate: %w", err)
+	}
+	modTime := certInfo.ModTime()
+	if keyInfo.ModTime().After(modTime) {
+		modTime = keyInfo.ModTime()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.certificate != nil && modTime.Equal(c.modTime) {
+		return c.certificate, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		// the files may be replaced one after the other, the previous certificate is kept meanwhile
+		if c.certificate != nil {
+			return c.certificate, nil
+		}
+		return nil, fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+	c.certificate, c.modTime = &certificate, modTime
+	return c.certificate, nil
+}
+
+// secureWebsocketAddr returns the websocket address over TLS, eg. /ip4/1.2.3.4/tcp/443/tls/ws for
+// /ip4/1.2.3.4/tcp/443/ws; the other addresses are returned unchanged.
+func secureWebsocketAddr(addr multiaddr.Multiaddr) multiaddr.Multiaddr {
+	rest, last := multiaddr.SplitLast(addr)
+	if last == nil || last.Protocol().Code != multiaddr.P_WS || rest == nil {
+		return addr
+	}
+	if _, prev := multiaddr.SplitLast(rest); prev == nil || prev.Protocol().Code != multiaddr.P_TCP {
+		return addr
+	}
+	return rest.Encapsulate(multiaddr.StringCast("/tls/ws"))
+}
@@ -0,0 +1,206 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/models/blockless"
+	"github.com/blocklessnetwork/b7s/models/execute"
+	"github.com/rs/zerolog"
+	"github.com/urfave/cli/v2"
+
+	"github.com/zees-dev/blockless-avs/core/reporting"
+	"github.com/zees-dev/blockless-avs/metrics"
+)
+
+const (
+	defaultWorkspaceGCMaxAge   = 24 * time.Hour
+	defaultWorkspaceGCInterval = 10 * time.Minute
+	// directory of the execution directories in the workspace, one per request, as laid out by the b7s executor
+	executionsDir = "t"
+)
+
+// WorkspaceGCConfig controls the removal of the execution directories of a worker node. b7s removes the directory of
+// an execution once it completes, but not when the node stops during one, so they would otherwise accumulate.
+type WorkspaceGCConfig struct {
+	// age above which the execution directories are removed; disabled when negative
+	MaxAge time.Duration
+	// total size (bytes) of the execution directories above which the oldest ones are removed; disabled when 0
+	MaxSize  int64
+	Interval time.Duration
+}
+
+func ParseWorkspaceGCFlags(c *cli.Context) WorkspaceGCConfig {
+	cfg := WorkspaceGCConfig{
+		MaxAge:   c.Duration(WorkspaceGCMaxAge.Name),
+		MaxSize:  c.Int64(WorkspaceGCMaxSize.Name) << 20,
+		Interval: c.Duration(WorkspaceGCInterval.Name),
+	}
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultWorkspaceGCMaxAge
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultWorkspaceGCInterval
+	}
+	return cfg
+}
+
+// Enabled returns whether any policy removes execution directories.
+func (c WorkspaceGCConfig) Enabled() bool {
+	return c.MaxAge > 0 || c.MaxSize > 0
+}
+
+// WorkspaceGCResult is the outcome of a collection of the execution directories of a workspace.
+type WorkspaceGCResult struct {
+	// request ids of the execution directories removed
+	Removed []string `json:"removed"`
+	// bytes freed by the removed directories
+	Freed int64 `json:"freed"`
+	// execution directories kept, and their total size
+	Kept     int   `json:"kept"`
+	KeptSize int64 `json:"keptSize"`
+}
+
+// executionDir is an execution directory of the workspace.
+type executionDir struct {
+	requestID string
+	path      string
+	modTime   time.Time
+	size      int64
+}
+
+// CollectWorkspace removes the execution directories of the workspace older than the max age, then the oldest ones
+// until the total size fits the max size. The directories of the running executions, for which running returns true,
+// are kept; running may be nil when the node is stopped. With dryRun, the directories are only reported.
+func CollectWorkspace(workspace string, cfg WorkspaceGCConfig, running func(requestID string) bool, dryRun bool) (WorkspaceGCResult, error) {
+	result := WorkspaceGCResult{Removed: []string{}}
+	entries, err := os.ReadDir(filepath.Join(workspace, executionsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return result, fmt.Errorf("could not read execution directories: %w", err)
+	}
+
+	var dirs []executionDir
+	for _, entry := range entries {
+		if !entry.IsDir() || (running != nil && running(entry.Name())) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// removed by the node meanwhile
+			continue
+		}
+		path := filepath.Join(workspace, executionsDir, entry.Name())
+		dirs = append(dirs, executionDir{requestID: entry.Name(), path: path, modTime: info.ModTime(), size: dirSize(path)})
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].modTime.Before(dirs[j].modTime) })
+
+	var total int64
+	for _, dir := range dirs {
+		total += dir.size
+	}
+	now := time.Now()
+	var errs []error
+	for _, dir := range dirs {
+		expired := cfg.MaxAge > 0 && now.Sub(dir.modTime) > cfg.MaxAge
+		oversized := cfg.MaxSize > 0 && total > cfg.MaxSize
+		if !expired && !oversized {
+			result.Kept++
+			result.KeptSize += dir.size
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(dir.path); err != nil {
+				errs = append(errs, fmt.Errorf("could not remove execution directory %s: %w", dir.requestID, err))
+				result.Kept++
+				result.KeptSize += dir.size
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, dir.requestID)
+		result.Freed += dir.size
+		total -= dir.size
+	}
+	return result, errors.Join(errs...)
+}
+
+// dirSize returns the size of the files in the directory, skipping the ones which can't be read.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// trackingExecutor keeps track of the running executions of a worker node, so their directories aren't collected.
+type trackingExecutor struct {
+	blockless.Executor
+	metrics *metrics.NodeMetrics
+
+	mu      sync.Mutex
+	running map[string]struct{}
+}
+
+func newTrackingExecutor(executor blockless.Executor, metrics *metrics.NodeMetrics) *trackingExecutor {
+	return &trackingExecutor{Executor: executor, metrics: metrics, running: map[string]struct{}{}}
+}
+
+func (e *trackingExecutor) ExecuteFunction(requestID string, req execute.Request) (execute.Result, error) {
+	e.mu.Lock()
+	e.running[requestID] = struct{}{}
+	e.mu.Unlock()
+	e.metrics.AddRunningExecutions(1)
+	defer func() {
+		e.mu.Lock()
+		delete(e.running, requestID)
+		e.mu.Unlock()
+		e.metrics.AddRunningExecutions(-1)
+	}()
+	return e.Executor.ExecuteFunction(requestID, req)
+}
+
+// isRunning reports whether the execution of the request is running.
+func (e *trackingExecutor) isRunning(requestID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, ok := e.running[requestID]
+	return ok
+}
+
+// collectWorkspace periodically removes the execution directories of the workspace per the policies, until ctx is
+// cancelled.
+func collectWorkspace(ctx context.Context, log *zerolog.Logger, workspace string, cfg WorkspaceGCConfig, executor *trackingExecutor) {
+	defer reporting.Recover()
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		result, err := CollectWorkspace(workspace, cfg, executor.isRunning, false)
+		if err != nil {
+			log.Warn().Err(err).Msg("could not collect all execution directories")
+		}
+		if len(result.Removed) > 0 {
+			log.Info().Int("removed", len(result.Removed)).Int64("freed_bytes", result.Freed).Int("kept", result.Kept).Int64("kept_bytes", result.KeptSize).Msg("collected execution directories")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
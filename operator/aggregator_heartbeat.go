@@ -0,0 +1,68 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/core/version"
+)
+
+const defaultAggregatorHeartbeatInterval = 30 * time.Second
+
+// aggregatorHeartbeatInterval returns the configured heartbeat interval, or its default.
+func aggregatorHeartbeatInterval(interval time.Duration) (time.Duration, error) {
+	if interval == 0 {
+		return defaultAggregatorHeartbeatInterval, nil
+	}
+	if interval < 0 {
+		return 0, errors.New("aggregator_heartbeat_interval cannot be negative")
+	}
+	return interval, nil
+}
+
+// sendAggregatorHeartbeats periodically sends a signed heartbeat to the aggregators until ctx is cancelled, so they
+// report the operator as alive. Standby instances don't send heartbeats, only the one holding the signing lease.
+func (o *Operator) sendAggregatorHeartbeats(ctx context.Context) {
+	ticker := time.NewTicker(o.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if o.standby == nil || o.standby.canSign() {
+			if err := o.sendAggregatorHeartbeat(ctx); err != nil {
+				o.logger.Warn("Failed to send heartbeat to aggregators", "err", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Operator) sendAggregatorHeartbeat(ctx context.Context) error {
+	blockNumber, err := o.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	heartbeat := &aggregator.Heartbeat{
+		OperatorId:  o.operatorId,
+		Version:     version.Version,
+		BlockNumber: blockNumber,
+		Timestamp:   time.Now().UTC(),
+	}
+	if signedAt := o.lastTaskSignedAt.Load(); signedAt != 0 {
+		heartbeat.LastTaskSignedAt = time.Unix(0, signedAt).UTC()
+	}
+	digest, err := heartbeat.Digest()
+	if err != nil {
+		return err
+	}
+	signature, err := o.blsSigner.SignMessage(ctx, digest)
+	if err != nil {
+		return err
+	}
+	heartbeat.BlsSignature = *signature
+	return o.aggregatorPool.SendHeartbeat(heartbeat)
+}
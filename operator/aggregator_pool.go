@@ -0,0 +1,309 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/rpc"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/metrics"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const (
+	FailoverMode = "failover"
+	FanoutMode   = "fanout"
+
+	defaultAggregatorHealthCheckInterval = 10 * time.Second
+	aggregatorHealthCheckTimeout         = 3 * time.Second
+	// attempts made by SendSignedOracleResponseToAggregator over all the endpoints, waiting in between each attempt
+	aggregatorSendAttempts     = 5
+	aggregatorSendAttemptDelay = 2 * time.Second
+)
+
+// aggregatorEndpoint is a single aggregator the responses can be submitted to.
+type aggregatorEndpoint struct {
+	addr    string
+	client  *AggregatorRpcClient
+	healthy atomic.Bool
+}
+
+// AggregatorPool submits the signed responses to the primary aggregator and its backups. In failover mode a response
+// is submitted to the healthy endpoints in order of preference until one accepts it, falling back to the unhealthy
+// ones when none did; in fanout mode it is submitted to all the endpoints at once.
+// Endpoints are marked unhealthy when a submission fails with a transport error or a 503, and by the periodic
+// health checks, which mark them healthy again once they respond.
+// The endpoints and mode can be changed while running with Reload.
+type AggregatorPool struct {
+	// guards the endpoints, mode and health check interval
+	mu                  sync.RWMutex
+	endpoints           []*aggregatorEndpoint
+	mode                string
+	healthCheckInterval time.Duration
+	httpClient          *http.Client
+	metrics             metrics.Metrics
+	logger              logging.Logger
+}
+
+var _ AggregatorRpcClienter = (*AggregatorPool)(nil)
+
+func NewAggregatorPool(primary string, cfg avstypes.AggregatorsConfig, logger logging.Logger, metrics metrics.Metrics) (*AggregatorPool, error) {
+	pool := &AggregatorPool{
+		httpClient: &http.Client{Timeout: aggregatorHealthCheckTimeout},
+		metrics:    metrics,
+		logger:     logger,
+	}
+	if err := pool.Reload(primary, cfg); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// Reload replaces the endpoints, mode and health check interval of the pool. Endpoints which are kept reuse their
+// connection and health status; new endpoints are assumed healthy.
+func (p *AggregatorPool) Reload(primary string, cfg avstypes.AggregatorsConfig) error {
+	if cfg.Mode == "" {
+		cfg.Mode = FailoverMode
+	}
+	if cfg.Mode != FailoverMode && cfg.Mode != FanoutMode {
+		return fmt.Errorf("invalid aggregators mode %q, must be failover or fanout", cfg.Mode)
+	}
+	if cfg.HealthCheckInterval == 0 {
+		cfg.HealthCheckInterval = defaultAggregatorHealthCheckInterval
+	}
+	if cfg.HealthCheckInterval < 0 {
+		return errors.New("aggregators health_check_interval cannot be negative")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing := make(map[string]*aggregatorEndpoint, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		existing[endpoint.addr] = endpoint
+	}
+	var endpoints []*aggregatorEndpoint
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{primary}, cfg.Backups...) {
+		if addr == "" || seen[addr] {
+			return fmt.Errorf("aggregator addresses must be set and unique, got %q", addr)
+		}
+		seen[addr] = true
+		if endpoint, ok := existing[addr]; ok {
+			endpoints = append(endpoints, endpoint)
+			continue
+		}
+		client, err := NewAggregatorRpcClient(addr, p.logger, p.metrics)
+		if err != nil {
+			return err
+		}
+		endpoint := &aggregatorEndpoint{addr: addr, client: client}
+		// endpoints are assumed healthy until a submission or health check fails
+		endpoint.healthy.Store(true)
+		endpoints = append(endpoints, endpoint)
+	}
+	for addr, endpoint := range existing {
+		if !seen[addr] {
+			endpoint.client.close()
+		}
+	}
+	p.endpoints = endpoints
+	p.mode = cfg.Mode
+	p.healthCheckInterval = cfg.HealthCheckInterval
+	return nil
+}
+
+// current returns the endpoints and mode submissions are made with.
+func (p *AggregatorPool) current() ([]*aggregatorEndpoint, string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.endpoints, p.mode
+}
+
+// SendSignedOracleResponseToAggregator submits the signed response, retrying for a few times when no endpoint accepted it.
+// It is meant to be ran inside a go thread, so doesn't return anything.
+func (p *AggregatorPool) SendSignedOracleResponseToAggregator(signedOracleResponse *aggregator.SignedOracleResponse) {
+	p.logger.Info("Sending signed oracle response header to aggregator", "signedOracleResponse", fmt.Sprintf("%#v", signedOracleResponse))
+	for i := 0; i < aggregatorSendAttempts; i++ {
+		err := p.TrySendSignedOracleResponse(signedOracleResponse)
+		if err == nil {
+			return
+		}
+		if aggregator.IsRejection(err) {
+			p.logger.Error("Signed oracle response rejected by aggregator", "err", err)
+			p.metrics.IncNumTaskResponseSubmissionFailures()
+			return
+		}
+		p.logger.Info("Could not send signed oracle response to aggregator, retrying", "err", err, "retryIn", aggregatorSendAttemptDelay)
+		time.Sleep(aggregatorSendAttemptDelay)
+	}
+	p.logger.Errorf("Could not send signed oracle response to aggregator. Tried %d times.", aggregatorSendAttempts)
+	p.metrics.IncNumTaskResponseSubmissionFailures()
+}
+
+// TrySendSignedOracleResponse makes a single submission of the signed response, according to the pool mode.
+func (p *AggregatorPool) TrySendSignedOracleResponse(signedOracleResponse *aggregator.SignedOracleResponse) error {
+	endpoints, mode := p.current()
+	var err error
+	if mode == FanoutMode {
+		err = p.fanout(endpoints, signedOracleResponse)
+	} else {
+		err = p.failover(endpoints, signedOracleResponse)
+	}
+	if err == nil {
+		p.metrics.IncNumTasksAcceptedByAggregator()
+	}
+	return err
+}
+
+func (p *AggregatorPool) failover(endpoints []*aggregatorEndpoint, signedOracleResponse *aggregator.SignedOracleResponse) error {
+	var healthy, unhealthy []*aggregatorEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.healthy.Load() {
+			healthy = append(healthy, endpoint)
+		} else {
+			unhealthy = append(unhealthy, endpoint)
+		}
+	}
+	var errs []error
+	for _, endpoint := range append(healthy, unhealthy...) {
+		err := p.submit(endpoint, signedOracleResponse)
+		if err == nil {
+			return nil
+		}
+		// the response itself is invalid, the other aggregators would reject it as well
+		if aggregator.IsRejection(err) {
+			return err
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// fanout submits the response to all the endpoints, succeeding when any of them accepted it.
+func (p *AggregatorPool) fanout(endpoints []*aggregatorEndpoint, signedOracleResponse *aggregator.SignedOracleResponse) error {
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = p.submit(endpoint, signedOracleResponse)
+		}()
+	}
+	wg.Wait()
+	var rejected error
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+		if rejected == nil && aggregator.IsRejection(err) {
+			rejected = err
+		}
+	}
+	// report the rejection when there was one, so the response isn't retried
+	if rejected != nil {
+		return rejected
+	}
+	return errors.Join(errs...)
+}
+
+func (p *AggregatorPool) submit(endpoint *aggregatorEndpoint, signedOracleResponse *aggregator.SignedOracleResponse) error {
+	err := endpoint.client.TrySendSignedOracleResponse(signedOracleResponse)
+	switch {
+	case err == nil:
+		p.metrics.IncNumAggregatorSubmissions(endpoint.addr, "accepted")
+		p.setHealthy(endpoint, true)
+		return nil
+	case aggregator.IsRejection(err):
+		p.metrics.IncNumAggregatorSubmissions(endpoint.addr, "rejected")
+	default:
+		p.metrics.IncNumAggregatorSubmissions(endpoint.addr, "error")
+		if !isServerError(err) || strings.HasPrefix(err.Error(), "503.") {
+			p.setHealthy(endpoint, false)
+		}
+	}
+	return fmt.Errorf("aggregator %s: %w", endpoint.addr, err)
+}
+
+// SendHeartbeat sends the heartbeat to all the endpoints, since every aggregator reports the liveness of the operators
+// independently. It fails when no endpoint accepted it.
+func (p *AggregatorPool) SendHeartbeat(heartbeat *aggregator.Heartbeat) error {
+	endpoints, _ := p.current()
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := endpoint.client.SendHeartbeat(heartbeat); err != nil {
+				p.logger.Debug("Aggregator did not accept heartbeat", "endpoint", endpoint.addr, "err", err)
+				errs[i] = fmt.Errorf("aggregator %s: %w", endpoint.addr, err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunHealthChecks health checks the endpoints until ctx is cancelled.
+func (p *AggregatorPool) RunHealthChecks(ctx context.Context) {
+	for {
+		endpoints, _ := p.current()
+		for _, endpoint := range endpoints {
+			p.setHealthy(endpoint, p.checkHealth(ctx, endpoint))
+		}
+		p.mu.RLock()
+		timer := time.NewTimer(p.healthCheckInterval)
+		p.mu.RUnlock()
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// checkHealth checks that the endpoint serves the aggregator rpc server, which answers plain http requests to the rpc
+// path with a 405 (the rpc connections are established with CONNECT requests).
+func (p *AggregatorPool) checkHealth(ctx context.Context, endpoint *aggregatorEndpoint) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+endpoint.addr+rpc.DefaultRPCPath, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusMethodNotAllowed
+}
+
+func (p *AggregatorPool) setHealthy(endpoint *aggregatorEndpoint, healthy bool) {
+	if endpoint.healthy.Swap(healthy) != healthy {
+		if healthy {
+			p.logger.Info("Aggregator endpoint is healthy", "endpoint", endpoint.addr)
+		} else {
+			p.logger.Warn("Aggregator endpoint is unhealthy", "endpoint", endpoint.addr)
+		}
+	}
+	p.metrics.SetAggregatorEndpointHealthy(endpoint.addr, healthy)
+}
+
+func isServerError(err error) bool {
+	var serverErr rpc.ServerError
+	return errors.As(err, &serverErr)
+}
@@ -0,0 +1,57 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/notify"
+	"github.com/zees-dev/blockless-avs/core/operatorevents"
+)
+
+// OperatorEventAlert is sent to the digest webhook when the operator is ejected, churned out, deregistered or frozen
+// by the slasher.
+type OperatorEventAlert struct {
+	OperatorId      string               `json:"operatorId"`
+	OperatorAddress string               `json:"operatorAddress"`
+	Time            time.Time            `json:"time"`
+	Event           operatorevents.Event `json:"event"`
+}
+
+// watchOperatorEvents alerts on every ejection, churn, deregistration and slashing event of the operator until ctx is
+// cancelled.
+func (o *Operator) watchOperatorEvents(ctx context.Context, webhook *notify.Webhook) {
+	err := o.eventWatcher.Watch(ctx, func(e operatorevents.Event) {
+		o.alertOperatorEvent(ctx, e, webhook)
+	})
+	if err != nil {
+		o.logger.Error("Stopped watching operator ejection and slashing events", "err", err)
+	}
+}
+
+func (o *Operator) alertOperatorEvent(ctx context.Context, e operatorevents.Event, webhook *notify.Webhook) {
+	o.metrics.IncNumOperatorEvents(string(e.Kind))
+	switch e.Kind {
+	case operatorevents.FrozenStatusReset:
+		o.logger.Info("Operator frozen status was reset", "event", e)
+	case operatorevents.Deregistered:
+		o.logger.Warn("Operator deregistered from the avs", "event", e)
+	default:
+		o.logger.Error("Operator was removed from the avs or slashed", "event", e)
+	}
+	if e.Kind == operatorevents.Ejected && o.reregistration != nil {
+		o.reregistration.notifyEjected()
+	}
+	if webhook == nil {
+		return
+	}
+	alert := OperatorEventAlert{
+		OperatorId:      fmt.Sprintf("%x", o.operatorId[:]),
+		OperatorAddress: o.operatorAddr.Hex(),
+		Time:            time.Now(),
+		Event:           e,
+	}
+	if err := webhook.Send(ctx, alert); err != nil {
+		o.logger.Error("Failed to send operator event alert", "kind", e.Kind, "err", err)
+	}
+}
@@ -0,0 +1,279 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+)
+
+const (
+	defaultArtifactRetention = 72 * time.Hour
+	artifactPruneInterval    = 10 * time.Minute
+	executionFile            = "execution.json"
+)
+
+// Artifacts stored for every execution of an oracle update request.
+const (
+	// trace of the execution (upstream request, parsed and formatted price)
+	StdoutArtifact = "stdout"
+	// errors which made the execution fail
+	StderrArtifact = "stderr"
+	// raw upstream price response the price was parsed from
+	OutputArtifact = "output.json"
+)
+
+var (
+	ErrExecutionNotFound = errors.New("execution not found")
+	ErrArtifactNotFound  = errors.New("artifact not found")
+
+	executionIdRegex = regexp.MustCompile(`^[0-9]+-[0-9a-f]+$`)
+	artifactNames    = []string{StdoutArtifact, StderrArtifact, OutputArtifact}
+)
+
+// Execution describes a single execution of an oracle update request by the operator.
+type Execution struct {
+	Id     string `json:"id"`
+	Symbol string `json:"symbol"`
+	// cid of the wasm function which computed the price; empty for coingecko prices
+	Function   string    `json:"function,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// digest of the price response signed by the operator, as listed per operator by the aggregator admin api;
+	// empty when the execution failed
+	Digest    string   `json:"digest,omitempty"`
+	Price     string   `json:"price,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Artifacts []string `json:"artifacts"`
+}
+
+// ArtifactStore persists the artifacts of oracle update executions on disk, one directory per execution,
+// and deletes them once they are older than the retention.
+type ArtifactStore struct {
+	dir       string
+	retention time.Duration
+}
+
+func NewArtifactStore(cfg avstypes.ArtifactsConfig) (*ArtifactStore, error) {
+	if cfg.Retention == 0 {
+		cfg.Retention = defaultArtifactRetention
+	}
+	if cfg.Retention < 0 {
+		return nil, fmt.Errorf("artifacts retention cannot be negative")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create artifacts directory: %w", err)
+	}
+	return &ArtifactStore{dir: cfg.Dir, retention: cfg.Retention}, nil
+}
+
+// execution collects the artifacts of an in-progress execution; all methods are no-ops on a nil execution.
+type execution struct {
+	store  *ArtifactStore
+	info   Execution
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	output []byte
+}
+
+// begin starts recording a new execution; returns nil when the store is nil (artifacts are disabled).
+func (s *ArtifactStore) begin(symbol string) *execution {
+	if s == nil {
+		return nil
+	}
+	now := time.Now()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return &execution{
+		store: s,
+		info: Execution{
+			Id:        fmt.Sprintf("%d-%s", now.UnixMilli(), hex.EncodeToString(suffix)),
+			Symbol:    symbol,
+			StartedAt: now,
+		},
+	}
+}
+
+func (e *execution) logf(format string, args ...any) {
+	if e == nil {
+		return
+	}
+	fmt.Fprintf(&e.stdout, "%s %s\n", time.Now().UTC().Format(time.RFC3339Nano), fmt.Sprintf(format, args...))
+}
+
+func (e *execution) setFunction(cid string) {
+	if e == nil {
+		return
+	}
+	e.info.Function = cid
+}
+
+func (e *execution) setOutput(output []byte) {
+	if e == nil {
+		return
+	}
+	e.output = output
+}
+
+// finish records the outcome of the execution and writes its artifacts to the store.
+func (e *execution) finish(price *csavs.IBlocklessAVSPrice, err error) error {
+	if e == nil {
+		return nil
+	}
+	e.info.FinishedAt = time.Now()
+	if err != nil {
+		e.info.Error = err.Error()
+		fmt.Fprintf(&e.stderr, "%s %s\n", e.info.FinishedAt.UTC().Format(time.RFC3339Nano), err)
+	} else if price != nil {
+		e.info.Price = price.Price.String()
+		digest, err := core.GetPriceDigest(price)
+		if err != nil {
+			return fmt.Errorf("could not compute price digest: %w", err)
+		}
+		e.info.Digest = hex.EncodeToString(digest[:])
+	}
+	return e.store.write(e)
+}
+
+// write stores the execution in a temporary directory which is renamed into place, so readers never see partial executions.
+func (s *ArtifactStore) write(e *execution) error {
+	tmpDir, err := os.MkdirTemp(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	artifacts := map[string][]byte{
+		StdoutArtifact: e.stdout.Bytes(),
+		StderrArtifact: e.stderr.Bytes(),
+		OutputArtifact: e.output,
+	}
+	for _, name := range artifactNames {
+		if len(artifacts[name]) == 0 {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, name), artifacts[name], 0o644); err != nil {
+			return err
+		}
+		e.info.Artifacts = append(e.info.Artifacts, name)
+	}
+	info, err := json.Marshal(e.info)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, executionFile), info, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpDir, filepath.Join(s.dir, e.info.Id))
+}
+
+// Get returns the execution with the given id.
+func (s *ArtifactStore) Get(id string) (*Execution, error) {
+	if !executionIdRegex.MatchString(id) {
+		return nil, ErrExecutionNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, id, executionFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrExecutionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var info Execution
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("could not decode execution %s: %w", id, err)
+	}
+	return &info, nil
+}
+
+// List returns the stored executions, most recent first. A non-empty digest only returns the executions which signed it.
+func (s *ArtifactStore) List(digest string) ([]Execution, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	executions := []Execution{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !executionIdRegex.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := s.Get(entry.Name())
+		if err != nil {
+			// pruned in the meantime
+			if errors.Is(err, ErrExecutionNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if digest != "" && info.Digest != digest {
+			continue
+		}
+		executions = append(executions, *info)
+	}
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.After(executions[j].StartedAt) })
+	return executions, nil
+}
+
+// Artifact returns the content of an artifact of the execution.
+func (s *ArtifactStore) Artifact(id, name string) ([]byte, error) {
+	info, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(info.Artifacts, name) {
+		return nil, ErrArtifactNotFound
+	}
+	return os.ReadFile(filepath.Join(s.dir, id, name))
+}
+
+// prune deletes the executions which finished before the retention.
+func (s *ArtifactStore) prune(now time.Time) (int, error) {
+	executions, err := s.List("")
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, info := range executions {
+		if now.Sub(info.FinishedAt) <= s.retention {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, info.Id)); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// runArtifactPruning periodically deletes the execution artifacts which are older than the retention.
+func (o *Operator) runArtifactPruning(ctx context.Context) {
+	ticker := time.NewTicker(artifactPruneInterval)
+	defer ticker.Stop()
+	for {
+		pruned, err := o.artifacts.prune(time.Now())
+		if err != nil {
+			o.logger.Error("Failed to prune execution artifacts", "err", err)
+		} else if pruned > 0 {
+			o.logger.Debug("Pruned execution artifacts", "count", pruned)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package operator
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoEcdsaKey is returned when signing messages with a remote signer, which only signs transactions.
+var ErrNoEcdsaKey = errors.New("the remote signer only signs transactions, messages are signed with the ecdsa keystore")
+
+// OperatorAddress returns the address of the operator.
+func (o *Operator) OperatorAddress() common.Address {
+	return o.operatorAddr
+}
+
+// SignPersonalMessage signs the message with the operator ecdsa key, as an EIP-191 personal message (the signature of
+// personal_sign, with v 27 or 28), so the signature can't be mistaken for the one of a transaction.
+func (o *Operator) SignPersonalMessage(msg []byte) ([]byte, error) {
+	if o.ecdsaKey == nil {
+		return nil, ErrNoEcdsaKey
+	}
+	signature, err := crypto.Sign(accounts.TextHash(msg), o.ecdsaKey)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
@@ -0,0 +1,63 @@
+package operator
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	sdkelcontracts "github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
+	chainioutils "github.com/Layr-Labs/eigensdk-go/chainio/utils"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	sdkmetrics "github.com/Layr-Labs/eigensdk-go/metrics"
+)
+
+// eigenlayerClients are the eigenlayer and avs registry clients used by the operator.
+type eigenlayerClients struct {
+	elReader          *sdkelcontracts.ELChainReader
+	elWriter          *sdkelcontracts.ELChainWriter
+	avsRegistryReader *avsregistry.AvsRegistryChainReader
+	delegationManager common.Address
+}
+
+// buildEigenlayerClients builds the eigenlayer clients on top of the operator tx manager. Unlike clients.BuildAll
+// it doesn't need the ecdsa private key, so the transactions can be signed by a remote signer.
+func buildEigenlayerClients(
+	registryCoordinatorAddr, operatorStateRetrieverAddr common.Address,
+	ethClient eth.Client, txMgr txmgr.TxManager, eigenMetrics sdkmetrics.Metrics, logger logging.Logger,
+) (*eigenlayerClients, error) {
+	avsRegistryBindings, err := chainioutils.NewAVSRegistryContractBindings(registryCoordinatorAddr, operatorStateRetrieverAddr, ethClient, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not create avs registry contract bindings: %w", err)
+	}
+	delegationManagerAddr, err := avsRegistryBindings.StakeRegistry.Delegation(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get delegation manager address: %w", err)
+	}
+	avsDirectoryAddr, err := avsRegistryBindings.ServiceManager.AvsDirectory(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get avs directory address: %w", err)
+	}
+
+	elReader, err := sdkelcontracts.BuildELChainReader(delegationManagerAddr, avsDirectoryAddr, ethClient, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not create eigenlayer reader: %w", err)
+	}
+	elWriter, err := sdkelcontracts.BuildELChainWriter(delegationManagerAddr, avsDirectoryAddr, ethClient, logger, eigenMetrics, txMgr)
+	if err != nil {
+		return nil, fmt.Errorf("could not create eigenlayer writer: %w", err)
+	}
+	avsRegistryReader, err := avsregistry.BuildAvsRegistryChainReader(registryCoordinatorAddr, operatorStateRetrieverAddr, ethClient, logger)
+	if err != nil {
+		return nil, fmt.Errorf("could not create avs registry reader: %w", err)
+	}
+	return &eigenlayerClients{
+		elReader:          elReader,
+		elWriter:          elWriter,
+		avsRegistryReader: avsRegistryReader,
+		delegationManager: delegationManagerAddr,
+	}, nil
+}
@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+// historicalPriceWindow is how far from the requested time the historical price points are searched; coingecko
+// returns a point every 5 minutes for ranges within a day.
+const historicalPriceWindow = 10 * time.Minute
+
 // // Structs to unmarshal JSON data
 // type Coin struct {
 // 	ID     string `json:"id"`
@@ -51,7 +57,13 @@ type Price struct {
 
 // Function to get the price by coin ID
 func getPriceByID(id string) (float64, error) {
+	return fetchPrice(id, nil)
+}
+
+// fetchPrice gets the price by coin ID, recording the upstream request and response in the execution.
+func fetchPrice(id string, exec *execution) (float64, error) {
 	url := fmt.Sprintf("https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=usd", id)
+	exec.logf("GET %s", url)
 	resp, err := http.Get(url)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to get price")
@@ -62,6 +74,8 @@ func getPriceByID(id string) (float64, error) {
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to read response body")
 	}
+	exec.logf("received %s (%d bytes)", resp.Status, len(body))
+	exec.setOutput(body)
 
 	var result map[string]map[string]float64
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -72,6 +86,7 @@ func getPriceByID(id string) (float64, error) {
 	if !ok {
 		return 0, fmt.Errorf("price not found")
 	}
+	exec.logf("parsed price %v usd", price)
 
 	return price, nil
 }
@@ -80,3 +95,66 @@ func getPriceByID(id string) (float64, error) {
 func formatPriceToSixDecimals(price float64) uint {
 	return uint(math.Round(price * 1000000))
 }
+
+// GetPrice returns the current usd price of the coin with the given coingecko id, in 6dp.
+func GetPrice(id string) (*big.Int, error) {
+	price, err := getPriceByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(uint64(formatPriceToSixDecimals(price))), nil
+}
+
+// fetchPriceAt gets the usd price by coin ID at the given time, from the closest historical price point.
+func fetchPriceAt(id string, at time.Time) (float64, error) {
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%s/market_chart/range?vs_currency=usd&from=%d&to=%d",
+		id, at.Add(-historicalPriceWindow).Unix(), at.Add(historicalPriceWindow).Unix())
+	resp, err := http.Get(url)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get historical price")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read response body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to get historical price: %s", resp.Status)
+	}
+
+	var result struct {
+		// [unix time in ms, price] pairs
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, errors.Wrap(err, "failed to unmarshal historical price")
+	}
+	return closestPrice(result.Prices, at)
+}
+
+// closestPrice returns the price of the point closest to the given time, which must be within the historical
+// price window.
+func closestPrice(points [][2]float64, at time.Time) (float64, error) {
+	var price float64
+	closest := time.Duration(math.MaxInt64)
+	for _, point := range points {
+		distance := time.UnixMilli(int64(point[0])).Sub(at).Abs()
+		if distance < closest {
+			price, closest = point[1], distance
+		}
+	}
+	if closest > historicalPriceWindow {
+		return 0, fmt.Errorf("no price within %s of %s", historicalPriceWindow, at.UTC().Format(time.RFC3339))
+	}
+	return price, nil
+}
+
+// GetPriceAt returns the usd price of the coin with the given coingecko id at the given time, in 6dp.
+func GetPriceAt(id string, at time.Time) (*big.Int, error) {
+	price, err := fetchPriceAt(id, at)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(uint64(formatPriceToSixDecimals(price))), nil
+}
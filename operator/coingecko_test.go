@@ -2,6 +2,7 @@ package operator
 
 import (
 	"testing"
+	"time"
 )
 
 // TestGetPriceByID function to test getPrice
@@ -39,3 +40,36 @@ func TestFormatPriceToSixDecimals(t *testing.T) {
 		}
 	}
 }
+
+func TestClosestPrice(t *testing.T) {
+	at := time.Unix(1_700_000_000, 0)
+	ms := func(t time.Time) float64 { return float64(t.UnixMilli()) }
+	points := [][2]float64{
+		{ms(at.Add(-5 * time.Minute)), 100},
+		{ms(at.Add(time.Minute)), 101},
+		{ms(at.Add(6 * time.Minute)), 102},
+	}
+	tests := []struct {
+		points   [][2]float64
+		at       time.Time
+		expected float64
+		err      bool
+	}{
+		{points, at, 101, false},
+		{points, at.Add(-4 * time.Minute), 100, false},
+		{points, at.Add(15 * time.Minute), 102, false},
+		// the closest point is outside of the window
+		{points, at.Add(time.Hour), 0, true},
+		{nil, at, 0, true},
+	}
+
+	for _, test := range tests {
+		price, err := closestPrice(test.points, test.at)
+		if (err != nil) != test.err {
+			t.Errorf("Expected error: %v, got: %v", test.err, err)
+		}
+		if price != test.expected {
+			t.Errorf("Expected price: %v, got: %v", test.expected, price)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// tasks are deduplicated for as long as their responses may be accepted, ie. the task challenge window
+const taskDedupTTL = 100 * 12 * time.Second
+
+// taskKey identifies a task delivery: the aggregator task index and the digest of the task input.
+type taskKey struct {
+	index  uint32
+	digest [32]byte
+}
+
+// taskDedup remembers the tasks being or already processed, so a task delivered several times (eg. by the chain
+// subscription and the p2p network) is only computed and submitted once. Only tasks carrying their index are
+// deduplicated, since nothing tells repeated requests of the other ones apart.
+type taskDedup struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[taskKey]time.Time
+}
+
+func newTaskDedup(ttl time.Duration) *taskDedup {
+	return &taskDedup{ttl: ttl, seen: make(map[taskKey]time.Time)}
+}
+
+// firstDelivery records the task and reports whether it wasn't delivered within the ttl before.
+func (d *taskDedup) firstDelivery(task *OracleTask) bool {
+	key, ok := dedupKey(task)
+	if !ok {
+		return true
+	}
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for k, seenAt := range d.seen {
+		if now.Sub(seenAt) > d.ttl {
+			delete(d.seen, k)
+		}
+	}
+	if _, seen := d.seen[key]; seen {
+		return false
+	}
+	d.seen[key] = now
+	return true
+}
+
+// forget removes the task, so it's processed again if redelivered, eg. after it failed.
+func (d *taskDedup) forget(task *OracleTask) {
+	key, ok := dedupKey(task)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, key)
+}
+
+func dedupKey(task *OracleTask) (taskKey, bool) {
+	if task.TaskIndex == nil {
+		return taskKey{}, false
+	}
+	input, err := task.input()
+	if err != nil {
+		return taskKey{}, false
+	}
+	return taskKey{index: *task.TaskIndex, digest: sha256.Sum256(input)}, true
+}
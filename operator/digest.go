@@ -0,0 +1,146 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/notify"
+
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+)
+
+const defaultDigestSchedule = "daily"
+
+// digestSchedules maps the supported digest schedules to the length of a digest period.
+var digestSchedules = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// Digest summarizes the participation of the operator over a digest period.
+type Digest struct {
+	OperatorId      string    `json:"operatorId"`
+	OperatorAddress string    `json:"operatorAddress"`
+	PeriodStart     time.Time `json:"periodStart"`
+	PeriodEnd       time.Time `json:"periodEnd"`
+	// oracle updates accepted onchain during the period for which the operator was registered in the task quorums
+	TasksInQuorum int `json:"tasksInQuorum"`
+	// oracle updates whose aggregated signature includes the operator signature
+	TasksSigned int `json:"tasksSigned"`
+	// oracle updates for which the operator was a non-signer
+	TasksMissed int `json:"tasksMissed"`
+	// TasksSigned / TasksInQuorum; 0 when the operator was in no task quorum during the period
+	ParticipationRate float64 `json:"participationRate"`
+	// oracle update requests received by this operator
+	RequestsReceived int `json:"requestsReceived"`
+	// signed oracle responses sent to the aggregator
+	ResponsesSent int      `json:"responsesSent"`
+	Warnings      []string `json:"warnings"`
+}
+
+// digestCollector accumulates the operator activity of the current digest period.
+type digestCollector struct {
+	mu               sync.Mutex
+	periodStart      time.Time
+	tasksInQuorum    int
+	tasksSigned      int
+	requestsReceived int
+	responsesSent    int
+	skippedClockSkew int
+	// requests skipped while the operator was below the minimum stake of a quorum
+	skippedBelowMinimumStake int
+	// requests the operator policy didn't allow signing
+	skippedPolicy    int
+	processingErrors int
+	onchainErrors    int
+}
+
+func newDigestCollector() *digestCollector {
+	return &digestCollector{periodStart: time.Now()}
+}
+
+func (d *digestCollector) update(f func(d *digestCollector)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f(d)
+}
+
+// flush returns the digest of the current period and starts a new one.
+func (d *digestCollector) flush(now time.Time) Digest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	digest := Digest{
+		PeriodStart:      d.periodStart,
+		PeriodEnd:        now,
+		TasksInQuorum:    d.tasksInQuorum,
+		TasksSigned:      d.tasksSigned,
+		TasksMissed:      d.tasksInQuorum - d.tasksSigned,
+		RequestsReceived: d.requestsReceived,
+		ResponsesSent:    d.responsesSent,
+		Warnings:         []string{},
+	}
+	if d.tasksInQuorum > 0 {
+		digest.ParticipationRate = float64(d.tasksSigned) / float64(d.tasksInQuorum)
+	}
+	if digest.TasksMissed > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("missed %d of %d tasks", digest.TasksMissed, digest.TasksInQuorum))
+	}
+	if d.skippedClockSkew > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("skipped %d oracle update requests because the local clock was skewed", d.skippedClockSkew))
+	}
+	if d.skippedBelowMinimumStake > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("skipped %d oracle update requests because the operator stake was below the quorum minimum stake", d.skippedBelowMinimumStake))
+	}
+	if d.skippedPolicy > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("skipped %d oracle update requests denied by the operator policy", d.skippedPolicy))
+	}
+	if d.processingErrors > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("failed to process %d oracle update requests", d.processingErrors))
+	}
+	if d.onchainErrors > 0 {
+		digest.Warnings = append(digest.Warnings, fmt.Sprintf("could not check participation in %d oracle updates; participation may be underreported", d.onchainErrors))
+	}
+
+	*d = digestCollector{periodStart: now}
+	return digest
+}
+
+// recordOracleUpdate records whether the operator participated in an oracle update accepted onchain.
+func (o *Operator) recordOracleUpdate(ctx context.Context, event *csavs.ContractBlocklessAVSOracleUpdate) {
+	resp, err := o.avsReader.GetAggregatedOracleResponseForEvent(ctx, event)
+	if err != nil {
+		o.logger.Error("Failed to get aggregated oracle response", "txHash", event.Raw.TxHash, "err", err)
+		o.digest.update(func(d *digestCollector) { d.onchainErrors++ })
+		return
+	}
+	inQuorum, err := o.inQuorumsAtBlock(ctx, resp.OracleRequest.QuorumNumbers, resp.OracleRequest.ReferenceBlockNumber)
+	if err != nil {
+		o.digest.update(func(d *digestCollector) { d.onchainErrors++ })
+		return
+	}
+	if !inQuorum {
+		return
+	}
+	signed := !o.isNonSigner(resp)
+	o.digest.update(func(d *digestCollector) {
+		d.tasksInQuorum++
+		if signed {
+			d.tasksSigned++
+		}
+	})
+}
+
+// sendDigest sends the digest of the current period to the digest webhook.
+func (o *Operator) sendDigest(ctx context.Context, webhook *notify.Webhook) {
+	digest := o.digest.flush(time.Now())
+	digest.OperatorId = fmt.Sprintf("%x", o.operatorId[:])
+	digest.OperatorAddress = o.operatorAddr.Hex()
+	if err := webhook.Send(ctx, digest); err != nil {
+		o.logger.Error("Failed to send operator digest", "err", err)
+		return
+	}
+	o.logger.Info("Sent operator digest", "periodStart", digest.PeriodStart, "tasksSigned", digest.TasksSigned, "tasksMissed", digest.TasksMissed)
+}
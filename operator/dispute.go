@@ -0,0 +1,47 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/notify"
+)
+
+// ExecutionDisputeAlert is sent to the digest webhook when the workers of an execution of the p2p head node disagree on
+// its result.
+type ExecutionDisputeAlert struct {
+	OperatorId      string    `json:"operatorId"`
+	OperatorAddress string    `json:"operatorAddress"`
+	Time            time.Time `json:"time"`
+	RequestId       string    `json:"requestId"`
+	FunctionId      string    `json:"functionId,omitempty"`
+	// hash of the result returned by the majority of the workers; empty without a majority
+	Result     string             `json:"result,omitempty"`
+	Dissenting []DissentingWorker `json:"dissenting"`
+}
+
+// DissentingWorker is a worker which returned another result than the majority, or none.
+type DissentingWorker struct {
+	PeerId string `json:"peerId"`
+	// operator the worker is bound to, when known
+	Operator string `json:"operator,omitempty"`
+	Result   string `json:"result,omitempty"`
+}
+
+// AlertExecutionDispute reports the workers disputing the result of an execution, to the digest webhook when set.
+func (o *Operator) AlertExecutionDispute(ctx context.Context, alert ExecutionDisputeAlert) {
+	alert.OperatorId = fmt.Sprintf("%x", o.operatorId[:])
+	alert.OperatorAddress = o.operatorAddr.Hex()
+	alert.Time = time.Now()
+	for _, worker := range alert.Dissenting {
+		o.logger.Warn("Worker disputed the result of an execution", "request", alert.RequestId, "function", alert.FunctionId,
+			"peer", worker.PeerId, "workerOperator", worker.Operator, "result", worker.Result, "majority", alert.Result)
+	}
+	if o.config.Digest.Webhook.Url == "" {
+		return
+	}
+	if err := notify.NewWebhook(o.config.Digest.Webhook).Send(ctx, alert); err != nil {
+		o.logger.Error("Failed to send execution dispute alert", "request", alert.RequestId, "err", err)
+	}
+}
@@ -0,0 +1,117 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// long enough to deliver the in-flight responses with the aggregator send retries
+const defaultDrainTimeout = 2 * time.Minute
+
+// ErrDraining is returned for the tasks requested once the operator started draining.
+var ErrDraining = errors.New("operator is draining, no new tasks are accepted")
+
+// drainTimeout returns the configured drain timeout, or its default.
+func drainTimeout(timeout time.Duration) (time.Duration, error) {
+	if timeout == 0 {
+		return defaultDrainTimeout, nil
+	}
+	if timeout < 0 {
+		return 0, errors.New("drain_timeout cannot be negative")
+	}
+	return timeout, nil
+}
+
+// inFlightTasks tracks the tasks accepted by the operator until their signed response was submitted, so they can be
+// waited for before shutting down. No task is accepted anymore once draining started.
+type inFlightTasks struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// begin accepts a new task, unless draining started. Accepted tasks must be finished with done.
+func (t *inFlightTasks) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+func (t *inFlightTasks) done() {
+	t.wg.Done()
+}
+
+// drain stops accepting tasks and returns a channel which is closed once the accepted tasks are finished.
+func (t *inFlightTasks) drain() <-chan struct{} {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+	return finished
+}
+
+func (t *inFlightTasks) isDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Drain prepares the operator for a shutdown without missing tasks: it stops accepting new tasks, waits for the
+// in-flight tasks to be signed and submitted and flushes the response queue, for up to the drain timeout. Drained is
+// closed once it returns, after which the operator should be stopped. Responses still queued after a timeout stay in
+// the queue database and are delivered after the restart.
+// Concurrent calls wait for the first one to complete.
+func (o *Operator) Drain(ctx context.Context) error {
+	first := false
+	o.drainOnce.Do(func() { first = true })
+	if !first {
+		select {
+		case <-o.drained:
+			return o.drainErr
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer close(o.drained)
+
+	ctx, cancel := context.WithTimeout(ctx, o.drainTimeout)
+	defer cancel()
+	o.logger.Info("Draining operator, no new tasks are accepted", "timeout", o.drainTimeout)
+	select {
+	case <-o.inFlight.drain():
+	case <-ctx.Done():
+		o.drainErr = fmt.Errorf("in-flight tasks not finished: %w", ctx.Err())
+		o.logger.Error("Failed to drain operator", "err", o.drainErr)
+		return o.drainErr
+	}
+	if o.responseQueue != nil {
+		if err := o.responseQueue.Flush(ctx); err != nil {
+			o.drainErr = fmt.Errorf("response queue not flushed: %w", err)
+			o.logger.Error("Failed to drain operator, the undelivered responses are delivered after the restart", "err", o.drainErr)
+			return o.drainErr
+		}
+	}
+	o.logger.Info("Drained operator, all in-flight responses were submitted")
+	return nil
+}
+
+// Draining reports whether the operator started draining.
+func (o *Operator) Draining() bool {
+	return o.inFlight.isDraining()
+}
+
+// Drained returns a channel which is closed once a drain completed, successfully or not.
+func (o *Operator) Drained() <-chan struct{} {
+	return o.drained
+}
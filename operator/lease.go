@@ -0,0 +1,112 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SigningLease is held by the single operator instance allowed to sign responses.
+type SigningLease interface {
+	// Acquire takes the lease if it is free or expired, or renews it if already held by this instance.
+	// It returns whether the lease is held by this instance and, if so, until when.
+	Acquire(ctx context.Context) (held bool, expiresAt time.Time, err error)
+	// Release gives up the lease if held by this instance, letting a standby take over without waiting for the lease to expire.
+	Release() error
+}
+
+type leaseRecord struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// fileLease is a SigningLease stored in a file shared by the operator instances.
+// Updates of the file are serialized with an advisory lock on a sibling ".lock" file.
+type fileLease struct {
+	path     string
+	holder   string
+	duration time.Duration
+}
+
+func newFileLease(path, holder string, duration time.Duration) *fileLease {
+	return &fileLease{path: path, holder: holder, duration: duration}
+}
+
+func (l *fileLease) Acquire(_ context.Context) (bool, time.Time, error) {
+	var held bool
+	var expiresAt time.Time
+	err := withFileLock(l.path+".lock", func() error {
+		record, err := l.read()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if record != nil && record.Holder != l.holder && now.Before(record.ExpiresAt) {
+			return nil
+		}
+		expiresAt = now.Add(l.duration)
+		if err := l.write(leaseRecord{Holder: l.holder, ExpiresAt: expiresAt}); err != nil {
+			return err
+		}
+		held = true
+		return nil
+	})
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	return held, expiresAt, nil
+}
+
+func (l *fileLease) Release() error {
+	return withFileLock(l.path+".lock", func() error {
+		record, err := l.read()
+		if err != nil || record == nil || record.Holder != l.holder {
+			return err
+		}
+		return l.write(leaseRecord{Holder: l.holder, ExpiresAt: time.Now()})
+	})
+}
+
+func (l *fileLease) read() (*leaseRecord, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read lease file: %w", err)
+	}
+	var record leaseRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("could not decode lease file: %w", err)
+	}
+	return &record, nil
+}
+
+// write replaces the lease file atomically, so readers never see a partially written record.
+func (l *fileLease) write(record leaseRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not write lease file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write lease file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write lease file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write lease file: %w", err)
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
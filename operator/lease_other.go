@@ -0,0 +1,9 @@
+//go:build !unix
+
+package operator
+
+import "errors"
+
+func withFileLock(_ string, _ func() error) error {
+	return errors.New("file signing lease is only supported on unix systems")
+}
@@ -0,0 +1,140 @@
+package operator
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+func TestFileLeaseTwoHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.lease")
+	duration := 200 * time.Millisecond
+	a := newFileLease(path, "a", duration)
+	b := newFileLease(path, "b", duration)
+	ctx := context.Background()
+
+	steps := []struct {
+		lease *fileLease
+		// release the lease instead of acquiring it
+		release bool
+		// wait before the step
+		wait time.Duration
+		held bool
+	}{
+		// a takes the free lease and renews it, b can't take it while it's held
+		{lease: a, held: true},
+		{lease: b, held: false},
+		{lease: a, held: true},
+		// b takes the lease once a releases it
+		{lease: a, release: true},
+		{lease: b, held: true},
+		{lease: a, held: false},
+		// a takes the lease over once b stops renewing it
+		{lease: a, wait: duration + 50*time.Millisecond, held: true},
+		{lease: b, held: false},
+	}
+
+	for i, step := range steps {
+		time.Sleep(step.wait)
+		if step.release {
+			if err := step.lease.Release(); err != nil {
+				t.Fatalf("step %d: Failed to release lease: %v", i, err)
+			}
+			continue
+		}
+		held, expiresAt, err := step.lease.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("step %d: Failed to acquire lease: %v", i, err)
+		}
+		if held != step.held {
+			t.Errorf("step %d: Expected %s to hold the lease: %v, got: %v", i, step.lease.holder, step.held, held)
+		}
+		if held && !expiresAt.After(time.Now()) {
+			t.Errorf("step %d: Expected lease to expire in the future, got: %v", i, expiresAt)
+		}
+	}
+}
+
+func TestFileLeaseConcurrentAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.lease")
+	holders := []string{"a", "b", "c", "d"}
+
+	var mu sync.Mutex
+	var winners []string
+	var wg sync.WaitGroup
+	for _, holder := range holders {
+		wg.Add(1)
+		go func(holder string) {
+			defer wg.Done()
+			held, _, err := newFileLease(path, holder, time.Minute).Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Failed to acquire lease: %v", err)
+				return
+			}
+			if held {
+				mu.Lock()
+				winners = append(winners, holder)
+				mu.Unlock()
+			}
+		}(holder)
+	}
+	wg.Wait()
+	if len(winners) != 1 {
+		t.Errorf("Expected a single lease holder, got: %v", winners)
+	}
+}
+
+func TestStandbyHandover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.lease")
+	newInstance := func(id string) *Operator {
+		s, err := newStandby(avstypes.StandbyConfig{Enabled: true, LeaseFile: path, InstanceId: id})
+		if err != nil {
+			t.Fatalf("Failed to create standby: %v", err)
+		}
+		return &Operator{logger: logging.NewNoopLogger(), standby: s}
+	}
+	a, b := newInstance("a"), newInstance("b")
+	ctx := context.Background()
+
+	a.heartbeat(ctx)
+	b.heartbeat(ctx)
+	if !a.standby.canSign() || b.standby.canSign() {
+		t.Fatalf("Expected only a to sign, got a: %v, b: %v", a.standby.canSign(), b.standby.canSign())
+	}
+
+	// a stops, b takes over on its next heartbeat
+	if err := a.standby.lease.Release(); err != nil {
+		t.Fatalf("Failed to release lease: %v", err)
+	}
+	b.heartbeat(ctx)
+	a.heartbeat(ctx)
+	if a.standby.canSign() || !b.standby.canSign() {
+		t.Errorf("Expected only b to sign, got a: %v, b: %v", a.standby.canSign(), b.standby.canSign())
+	}
+}
+
+func TestStandbyCanSign(t *testing.T) {
+	tests := []struct {
+		heldUntil time.Time
+		expected  bool
+	}{
+		{time.Time{}, false},
+		{time.Now().Add(time.Minute), true},
+		// signing stops before the lease expires
+		{time.Now().Add(leaseSafetyMargin / 2), false},
+		{time.Now().Add(-time.Second), false},
+	}
+
+	for _, test := range tests {
+		s := &standby{heldUntil: test.heldUntil}
+		if canSign := s.canSign(); canSign != test.expected {
+			t.Errorf("Expected can sign until %v: %v, got: %v", test.heldUntil, test.expected, canSign)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package operator
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// withFileLock runs f while holding an exclusive advisory lock on the file at path.
+func withFileLock(path string, f func() error) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("could not open lock file: %w", err)
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("could not lock lock file: %w", err)
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	return f()
+}
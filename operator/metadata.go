@@ -0,0 +1,108 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/zees-dev/blockless-avs/core/chainio"
+)
+
+const (
+	metadataFetchTimeout = 10 * time.Second
+	// eigenlayer rejects operator metadata larger than this
+	maxMetadataSize = 1 << 20
+)
+
+// OperatorMetadata is the public operator profile the metadata uri points to, in the eigenlayer metadata format.
+type OperatorMetadata struct {
+	Name        string `json:"name"`
+	Website     string `json:"website"`
+	Description string `json:"description"`
+	Logo        string `json:"logo"`
+	Twitter     string `json:"twitter"`
+}
+
+// MetadataReport is the metadata uri of the operator and the metadata it points to.
+type MetadataReport struct {
+	chainio.OperatorMetadataURI
+	Metadata *OperatorMetadata `json:"metadata,omitempty"`
+	// error fetching the metadata from the uri
+	FetchError string `json:"fetchError,omitempty"`
+}
+
+// MetadataUpdateReport is the result of updating the operator metadata uri.
+type MetadataUpdateReport struct {
+	Operator common.Address    `json:"operator"`
+	URI      string            `json:"uri"`
+	Metadata *OperatorMetadata `json:"metadata"`
+	TxHash   common.Hash       `json:"txHash"`
+}
+
+// UpdateMetadataURI sets the operator metadata uri on the DelegationManager. The metadata is fetched and validated
+// first, so a broken profile isn't published.
+func (o *Operator) UpdateMetadataURI(ctx context.Context, uri string) (*MetadataUpdateReport, error) {
+	metadata, err := fetchOperatorMetadata(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid operator metadata at %s: %w", uri, err)
+	}
+	receipt, err := o.metadataClient.UpdateOperatorMetadataURI(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	o.logger.Info("Updated operator metadata uri", "uri", uri, "txHash", receipt.TxHash.Hex())
+	return &MetadataUpdateReport{Operator: o.operatorAddr, URI: uri, Metadata: metadata, TxHash: receipt.TxHash}, nil
+}
+
+// Metadata returns the current operator metadata uri, scanning the uri updates from fromBlock, and the metadata it
+// points to.
+func (o *Operator) Metadata(ctx context.Context, fromBlock uint64) (*MetadataReport, error) {
+	uri, err := o.metadataClient.GetOperatorMetadataURI(ctx, o.operatorAddr, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	report := &MetadataReport{OperatorMetadataURI: *uri}
+	report.Metadata, err = fetchOperatorMetadata(ctx, uri.URI)
+	if err != nil {
+		report.FetchError = err.Error()
+	}
+	return report, nil
+}
+
+func fetchOperatorMetadata(ctx context.Context, uri string) (*OperatorMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata uri responded with status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxMetadataSize {
+		return nil, fmt.Errorf("metadata is larger than %d bytes", maxMetadataSize)
+	}
+	var metadata OperatorMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("could not decode metadata: %w", err)
+	}
+	if metadata.Name == "" {
+		return nil, errors.New("metadata has no name")
+	}
+	return &metadata, nil
+}
@@ -2,29 +2,42 @@ package operator
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
-	"os"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/aggregator/types"
 
 	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
 	"github.com/zees-dev/blockless-avs/core"
+	"github.com/zees-dev/blockless-avs/core/blssigner"
 	"github.com/zees-dev/blockless-avs/core/chainio"
+	"github.com/zees-dev/blockless-avs/core/clock"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/keystore"
+	corelogging "github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/notify"
+	"github.com/zees-dev/blockless-avs/core/operatorevents"
+	"github.com/zees-dev/blockless-avs/core/quorum"
+	"github.com/zees-dev/blockless-avs/core/remotesigner"
+	"github.com/zees-dev/blockless-avs/core/version"
 	"github.com/zees-dev/blockless-avs/metrics"
 	avstypes "github.com/zees-dev/blockless-avs/types"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	sdkelcontracts "github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
-	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
-	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	sdkmetrics "github.com/Layr-Labs/eigensdk-go/metrics"
 	"github.com/Layr-Labs/eigensdk-go/metrics/collectors/economic"
@@ -35,7 +48,6 @@ import (
 )
 
 const AVS_NAME = "blockless-avs"
-const SEM_VER = "0.0.1"
 
 type Operator struct {
 	config    avstypes.NodeConfig
@@ -46,39 +58,119 @@ type Operator struct {
 	// this way, auditing this operator code makes it obvious that operators don't need to
 	// write to the chain during the course of their normal operations
 	// writing to the chain should be done via the cli only
-	metricsReg       *prometheus.Registry
-	metrics          metrics.Metrics
+	metricsReg *prometheus.Registry
+	metrics    metrics.Metrics
+	// serves metricsReg while metrics are enabled; guarded by metricsMu
+	metricsMu     sync.Mutex
+	metricsServer *http.Server
+	metricsErrs   chan error
+	// sends the operator transactions; its gas settings are reloadable
+	txMgr *chainio.TxManager
+	// serializes config reloads
+	reloadMu         sync.Mutex
 	nodeApi          *nodeapi.NodeApi
 	avsWriter        *chainio.AvsWriter
 	avsReader        chainio.AvsReaderer
 	avsSubscriber    chainio.AvsSubscriberer
 	eigenlayerReader sdkelcontracts.ELReader
 	eigenlayerWriter sdkelcontracts.ELWriter
-	blsKeypair       *bls.KeyPair
-	operatorId       sdktypes.OperatorId
-	operatorAddr     common.Address
+	metadataClient   *chainio.OperatorMetadataClient
+	// nil unless the bls key is loaded from the keystore; only needed to register the operator
+	blsKeypair   *bls.KeyPair
+	blsSigner    blssigner.Signer
+	operatorId   sdktypes.OperatorId
+	operatorAddr common.Address
+	// nil with a remote signer; signs the messages of the operator, such as the binding of the p2p node identity
+	ecdsaKey *ecdsa.PrivateKey
 	// receive oracle update requests (triggered by HTTP requests)
-	newOracleUpdateChan chan *string
-	// ip address of aggregator
-	aggregatorServerIpPortAddr string
+	newOracleUpdateChan chan *OracleTask
 	// rpc client to send signed task responses to aggregator
 	aggregatorRpcClient AggregatorRpcClienter
+	// primary and backup aggregators the responses are submitted to
+	aggregatorPool *AggregatorPool
+	// monitors local clock skew; responses are not signed while the clock is skewed
+	clockMonitor *clock.SkewMonitor
+	// accumulates the activity reported in the scheduled digests
+	digest            *digestCollector
+	oracleUpdatesChan chan *csavs.ContractBlocklessAVSOracleUpdate
+	// nil unless running as a warm standby candidate
+	standby *standby
+	// stores the artifacts of every oracle update execution; nil when disabled
+	artifacts *ArtifactStore
+	// watches the onchain quorum parameters; responses are not signed while the operator is below a minimum stake
+	quorumWatcher     *quorum.Watcher
+	belowMinimumStake atomic.Bool
+	// decides which tasks are signed; replaced on config reload
+	policy atomic.Pointer[taskPolicy]
+	// quorums the operator participates in; tasks of other quorums are skipped
+	quorums sdktypes.QuorumNums
+	// skips the tasks delivered more than once
+	dedup *taskDedup
+	// watches the ejection, churn and slashing events of the operator
+	eventWatcher *operatorevents.Watcher
+	// re-registers the operator after ejections; nil when disabled
+	reregistration *reregistration
+	// executes tasks referencing a wasm function; nil when no blockless runtime is configured
+	wasm *wasmRunner
+	// persists the signed responses until the aggregator accepted them; nil when disabled
+	responseQueue *ResponseQueue
+	// software version attested alongside the signed responses
+	version aggregator.SoftwareVersion
+	// how often a signed heartbeat is sent to the aggregators
+	heartbeatInterval time.Duration
+	// unix nanoseconds of the last signed task response, reported in the heartbeats; 0 until one was signed
+	lastTaskSignedAt atomic.Int64
+	// tasks accepted until their response was submitted, waited for by Drain
+	inFlight     inFlightTasks
+	drainTimeout time.Duration
+	drainOnce    sync.Once
+	drainErr     error
+	// closed once Drain returned
+	drained chan struct{}
+}
+
+// SharedResources are created once and shared between the roles of a process running several of them (see avs all-in-one).
+// Nil fields are created from the operator config.
+type SharedResources struct {
+	MetricsRegistry *prometheus.Registry
+	EthRpcClient    eth.Client
+	EthWsClient     eth.Client
 }
 
 // TODO(samlaf): config is a mess right now, since the chainio client constructors
 //
 //	take the config in core (which is shared with aggregator and challenger)
 func NewOperatorFromConfig(logger logging.Logger, c avstypes.NodeConfig) (*Operator, error) {
-	reg := prometheus.NewRegistry()
+	return NewOperatorWithSharedResources(logger, c, SharedResources{})
+}
+
+func NewOperatorWithSharedResources(logger logging.Logger, c avstypes.NodeConfig, shared SharedResources) (*Operator, error) {
+	if c.LogFile.Path != "" {
+		zeroLogger, ok := logger.(*corelogging.ZeroLogger)
+		if !ok {
+			return nil, fmt.Errorf("log_file requires a zerolog logger")
+		}
+		fileLogger, err := zeroLogger.WithFile(c.LogFile)
+		if err != nil {
+			return nil, err
+		}
+		logger = fileLogger
+	}
+	reg := shared.MetricsRegistry
+	if reg == nil {
+		reg = prometheus.NewRegistry()
+	}
 	eigenMetrics := sdkmetrics.NewEigenMetrics(AVS_NAME, c.EigenMetricsIpPortAddress, reg, logger)
 	avsAndEigenMetrics := metrics.NewAvsAndEigenMetrics(AVS_NAME, eigenMetrics, reg)
 
 	// Setup Node Api
-	nodeApi := nodeapi.NewNodeApi(AVS_NAME, SEM_VER, c.NodeApiIpPortAddress, logger)
+	nodeApi := nodeapi.NewNodeApi(AVS_NAME, version.Version, c.NodeApiIpPortAddress, logger)
 
-	var ethRpcClient, ethWsClient eth.Client
+	ethRpcClient, ethWsClient := shared.EthRpcClient, shared.EthWsClient
 	var err error
-	if c.EnableMetrics {
+	if ethRpcClient != nil && ethWsClient != nil {
+		logger.Info("Using shared eth clients")
+	} else if c.EnableMetrics {
 		rpcCallsCollector := rpccalls.NewCollector(AVS_NAME, reg)
 		ethRpcClient, err = eth.NewInstrumentedClient(c.EthRpcUrl, rpcCallsCollector)
 		if err != nil {
@@ -103,60 +195,127 @@ func NewOperatorFromConfig(logger logging.Logger, c avstypes.NodeConfig) (*Opera
 		}
 	}
 
-	blsKeyPassword, ok := os.LookupEnv("OPERATOR_BLS_KEY_PASSWORD")
-	if !ok {
-		logger.Warnf("OPERATOR_BLS_KEY_PASSWORD env var not set. using empty string")
+	if c.Digest.Schedule == "" {
+		c.Digest.Schedule = defaultDigestSchedule
 	}
-	blsKeyPair, err := bls.ReadPrivateKeyFromFile(c.BlsPrivateKeyStorePath, blsKeyPassword)
+	if _, ok := digestSchedules[c.Digest.Schedule]; !ok {
+		return nil, fmt.Errorf("invalid digest schedule %q, must be daily or weekly", c.Digest.Schedule)
+	}
+
+	setLogLevel(c.Production)
+
+	gas, err := config.NewGasConfig(c.Gas)
 	if err != nil {
-		logger.Errorf("Cannot parse bls private key", "err", err)
 		return nil, err
 	}
-	// TODO(samlaf): should we add the chainId to the config instead?
-	// this way we can prevent creating a signer that signs on mainnet by mistake
-	// if the config says chainId=5, then we can only create a goerli signer
-	chainId, err := ethRpcClient.ChainID(context.Background())
+	txMgrConfig, err := config.NewTxMgrConfig(c.TxMgr)
 	if err != nil {
-		logger.Error("Cannot get chainId", "err", err)
 		return nil, err
 	}
 
-	ecdsaKeyPassword, ok := os.LookupEnv("OPERATOR_ECDSA_KEY_PASSWORD")
-	if !ok {
-		logger.Warnf("OPERATOR_ECDSA_KEY_PASSWORD env var not set. using empty string")
+	var operatorStandby *standby
+	if c.Standby.Enabled {
+		operatorStandby, err = newStandby(c.Standby)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	signerV2, _, err := signerv2.SignerFromConfig(signerv2.Config{
-		KeystorePath: c.EcdsaPrivateKeyStorePath,
-		Password:     ecdsaKeyPassword,
-	}, chainId)
-	if err != nil {
-		panic(err)
-	}
-	chainioConfig := clients.BuildAllConfig{
-		EthHttpUrl:                 c.EthRpcUrl,
-		EthWsUrl:                   c.EthWsUrl,
-		RegistryCoordinatorAddr:    c.AVSRegistryCoordinatorAddress,
-		OperatorStateRetrieverAddr: c.OperatorStateRetrieverAddress,
-		AvsName:                    AVS_NAME,
-		PromMetricsIpPortAddress:   c.EigenMetricsIpPortAddress,
-	}
-	operatorEcdsaPrivateKey, err := sdkecdsa.ReadKey(
-		c.EcdsaPrivateKeyStorePath,
-		ecdsaKeyPassword,
-	)
+	var artifacts *ArtifactStore
+	if c.Artifacts.Dir != "" {
+		artifacts, err = NewArtifactStore(c.Artifacts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var wasm *wasmRunner
+	if c.Wasm.RuntimeDir != "" {
+		wasm, err = newWasmRunner(c.Wasm, zerologLogger(logger))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the bls key pair is only loaded with the keystore backend; the remote backend never exposes the key
+	var blsKeyPair *bls.KeyPair
+	var blsSigner blssigner.Signer
+	switch c.BlsSigner.Backend {
+	case blssigner.KeystoreBackend, "":
+		blsKeyPair, err = keystore.ReadBlsKey(c.BlsPrivateKeyStorePath, c.BlsKeyPassword(), logger)
+		if err != nil {
+			logger.Errorf("Cannot parse bls private key", "err", err)
+			return nil, err
+		}
+		blsSigner = blssigner.NewKeystoreSigner(blsKeyPair)
+	case blssigner.RemoteBackend:
+		if c.RegisterOperatorOnStartup {
+			return nil, fmt.Errorf("register_operator_on_startup signs the pubkey registration with the bls keystore, it can't be used with a remote bls signer")
+		}
+		blsSigner, err = blssigner.NewRemoteSigner(context.Background(), c.BlsSigner)
+		if err != nil {
+			logger.Error("Cannot create remote bls signer", "err", err)
+			return nil, err
+		}
+		logger.Info("Signing responses with remote bls signer", "url", c.BlsSigner.Url)
+	default:
+		return nil, fmt.Errorf("invalid bls signer backend %q, must be keystore or remote", c.BlsSigner.Backend)
+	}
+	// the chain_id of the config (or of --network) prevents creating a signer that signs on mainnet by mistake
+	chainId, err := ethRpcClient.ChainID(context.Background())
 	if err != nil {
+		logger.Error("Cannot get chainId", "err", err)
+		return nil, err
+	}
+	if err := config.CheckChainId(c.ChainId, chainId); err != nil {
 		return nil, err
 	}
-	sdkClients, err := clients.BuildAll(chainioConfig, operatorEcdsaPrivateKey, logger)
+
+	// transactions are signed either by a remote signer (web3signer or clef) or with the ecdsa keystore
+	var signerV2 signerv2.SignerFn
+	var operatorEcdsaPrivateKey *ecdsa.PrivateKey
+	if c.RemoteSigner.Enabled() {
+		if c.RegisterOperatorOnStartup {
+			return nil, fmt.Errorf("register_operator_on_startup signs the registration with the ecdsa keystore, it can't be used with a remote signer")
+		}
+		if c.Reregistration.Enabled {
+			return nil, fmt.Errorf("reregistration signs the registration with the ecdsa keystore, it can't be used with a remote signer")
+		}
+		remoteSigner, err := remotesigner.NewSigner(context.Background(), c.RemoteSigner, chainId)
+		if err != nil {
+			logger.Error("Cannot create remote signer", "err", err)
+			return nil, err
+		}
+		if remoteSigner.Address() != common.HexToAddress(c.OperatorAddress) {
+			return nil, fmt.Errorf("remote signer address %s is not the operator address %s", remoteSigner.Address().Hex(), c.OperatorAddress)
+		}
+		logger.Info("Signing transactions with remote signer", "api", c.RemoteSigner.Api, "url", c.RemoteSigner.Url)
+		signerV2 = remoteSigner.SignerFn()
+	} else {
+		operatorEcdsaPrivateKey, err = keystore.ReadEcdsaKey(c.EcdsaPrivateKeyStorePath, c.EcdsaKeyPassword(), logger)
+		if err != nil {
+			logger.Error("Cannot parse ecdsa private key", "err", err)
+			return nil, err
+		}
+		signerV2, _, err = signerv2.SignerFromConfig(signerv2.Config{PrivateKey: operatorEcdsaPrivateKey}, chainId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	txMgr := chainio.NewTxManager(ethRpcClient, signerV2, common.HexToAddress(c.OperatorAddress), gas, txMgrConfig, logger)
+	sdkClients, err := buildEigenlayerClients(
+		common.HexToAddress(c.AVSRegistryCoordinatorAddress), common.HexToAddress(c.OperatorStateRetrieverAddress),
+		ethRpcClient, txMgr, eigenMetrics, logger,
+	)
 	if err != nil {
-		panic(err)
+		logger.Error("Cannot create eigenlayer clients", "err", err)
+		return nil, err
 	}
-	skWallet, err := wallet.NewPrivateKeyWallet(ethRpcClient, signerV2, common.HexToAddress(c.OperatorAddress), logger)
+	metadataClient, err := chainio.NewOperatorMetadataClient(sdkClients.delegationManager, ethRpcClient, txMgr, logger)
 	if err != nil {
-		panic(err)
+		logger.Error("Cannot create operator metadata client", "err", err)
+		return nil, err
 	}
-	txMgr := txmgr.NewSimpleTxManager(skWallet, ethRpcClient, logger, common.HexToAddress(c.OperatorAddress))
 
 	avsWriter, err := chainio.BuildAvsWriter(
 		txMgr, common.HexToAddress(c.AVSRegistryCoordinatorAddress),
@@ -189,34 +348,103 @@ func NewOperatorFromConfig(logger logging.Logger, c avstypes.NodeConfig) (*Opera
 		0: "quorum0",
 	}
 	economicMetricsCollector := economic.NewCollector(
-		sdkClients.ElChainReader, sdkClients.AvsRegistryChainReader,
+		sdkClients.elReader, sdkClients.avsRegistryReader,
 		AVS_NAME, logger, common.HexToAddress(c.OperatorAddress), quorumNames)
 	reg.MustRegister(economicMetricsCollector)
+	reg.MustRegister(metrics.NewRegistrationCollector(sdkClients.avsRegistryReader, common.HexToAddress(c.OperatorAddress), logger))
+
+	aggregatorPool, err := NewAggregatorPool(c.AggregatorServerIpPortAddress, c.Aggregators, logger, avsAndEigenMetrics)
+	if err != nil {
+		logger.Error("Cannot create aggregator pool", "err", err)
+		return nil, err
+	}
+	// with the response queue enabled, responses are sent through the queue which retries them until they are accepted
+	var responseSender AggregatorRpcClienter = aggregatorPool
+	var responseQueue *ResponseQueue
+	if c.ResponseQueue.Dir != "" {
+		responseQueue, err = NewResponseQueue(c.ResponseQueue, aggregatorPool, avsAndEigenMetrics, logger)
+		if err != nil {
+			logger.Error("Cannot create response queue", "err", err)
+			return nil, err
+		}
+		responseSender = responseQueue
+	}
 
-	aggregatorRpcClient, err := NewAggregatorRpcClient(c.AggregatorServerIpPortAddress, logger, avsAndEigenMetrics)
+	quorumWatcher, err := quorum.NewWatcher(common.HexToAddress(c.AVSRegistryCoordinatorAddress), ethWsClient, types.QUORUM_NUMBERS.UnderlyingType(), logger)
+	if err != nil {
+		logger.Error("Cannot create quorum parameter watcher", "err", err)
+		return nil, err
+	}
+
+	eventWatcher, err := operatorevents.NewWatcher(common.HexToAddress(c.OperatorAddress), common.HexToAddress(c.AVSRegistryCoordinatorAddress), sdkClients.delegationManager, ethWsClient, logger)
+	if err != nil {
+		logger.Error("Cannot create operator event watcher", "err", err)
+		return nil, err
+	}
+
+	var operatorReregistration *reregistration
+	if c.Reregistration.Enabled {
+		if blsKeyPair == nil {
+			return nil, fmt.Errorf("reregistration registers the bls pubkey, it can't be used with a remote bls signer")
+		}
+		operatorReregistration, err = newReregistration(c.Reregistration, operatorEcdsaPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	heartbeatInterval, err := aggregatorHeartbeatInterval(c.AggregatorHeartbeatInterval)
+	if err != nil {
+		return nil, err
+	}
+	quorums, err := participatingQuorums(c.Quorums)
+	if err != nil {
+		return nil, err
+	}
+	operatorDrainTimeout, err := drainTimeout(c.DrainTimeout)
 	if err != nil {
-		logger.Error("Cannot create AggregatorRpcClient. Is aggregator running?", "err", err)
 		return nil, err
 	}
 
 	operator := &Operator{
-		config:                     c,
-		logger:                     logger,
-		metricsReg:                 reg,
-		metrics:                    avsAndEigenMetrics,
-		nodeApi:                    nodeApi,
-		ethClient:                  ethRpcClient,
-		avsWriter:                  avsWriter,
-		avsReader:                  avsReader,
-		avsSubscriber:              avsSubscriber,
-		eigenlayerReader:           sdkClients.ElChainReader,
-		eigenlayerWriter:           sdkClients.ElChainWriter,
-		blsKeypair:                 blsKeyPair,
-		operatorAddr:               common.HexToAddress(c.OperatorAddress),
-		aggregatorServerIpPortAddr: c.AggregatorServerIpPortAddress,
-		aggregatorRpcClient:        aggregatorRpcClient,
-		newOracleUpdateChan:        make(chan *string),
-		operatorId:                 [32]byte{0}, // this is set below
+		config:              c,
+		logger:              logger,
+		metricsReg:          reg,
+		metricsErrs:         make(chan error, 1),
+		txMgr:               txMgr,
+		metrics:             avsAndEigenMetrics,
+		nodeApi:             nodeApi,
+		ethClient:           ethRpcClient,
+		avsWriter:           avsWriter,
+		avsReader:           avsReader,
+		avsSubscriber:       avsSubscriber,
+		eigenlayerReader:    sdkClients.elReader,
+		eigenlayerWriter:    sdkClients.elWriter,
+		metadataClient:      metadataClient,
+		blsKeypair:          blsKeyPair,
+		blsSigner:           blsSigner,
+		operatorAddr:        common.HexToAddress(c.OperatorAddress),
+		ecdsaKey:            operatorEcdsaPrivateKey,
+		aggregatorRpcClient: responseSender,
+		aggregatorPool:      aggregatorPool,
+		newOracleUpdateChan: make(chan *OracleTask),
+		clockMonitor:        clock.NewSkewMonitor(c.Clock, ethRpcClient, logger),
+		digest:              newDigestCollector(),
+		standby:             operatorStandby,
+		artifacts:           artifacts,
+		quorumWatcher:       quorumWatcher,
+		eventWatcher:        eventWatcher,
+		reregistration:      operatorReregistration,
+		dedup:               newTaskDedup(taskDedupTTL),
+		wasm:                wasm,
+		responseQueue:       responseQueue,
+		version:             softwareVersion(c.Wasm.RuntimeDir),
+		heartbeatInterval:   heartbeatInterval,
+		quorums:             quorums,
+		drainTimeout:        operatorDrainTimeout,
+		drained:             make(chan struct{}),
+		oracleUpdatesChan:   make(chan *csavs.ContractBlocklessAVSOracleUpdate),
+		operatorId:          [32]byte{0}, // this is set below
 	}
 
 	if c.RegisterOperatorOnStartup {
@@ -224,23 +452,40 @@ func NewOperatorFromConfig(logger logging.Logger, c avstypes.NodeConfig) (*Opera
 	}
 
 	// OperatorId is set in contract during registration so we get it after registering operator.
-	operatorId, err := sdkClients.AvsRegistryChainReader.GetOperatorId(&bind.CallOpts{}, operator.operatorAddr)
+	operatorId, err := sdkClients.avsRegistryReader.GetOperatorId(&bind.CallOpts{}, operator.operatorAddr)
 	if err != nil {
 		logger.Error("Cannot get operator id", "err", err)
 		return nil, err
 	}
 	operator.operatorId = operatorId
+
+	policy, err := operator.newTaskPolicy(c.Policy)
+	if err != nil {
+		logger.Error("Cannot create operator policy", "err", err)
+		return nil, err
+	}
+	operator.policy.Store(policy)
 	logger.Info("Operator info",
 		"operatorId", operatorId,
 		"operatorAddr", c.OperatorAddress,
-		"operatorG1Pubkey", operator.blsKeypair.GetPubKeyG1(),
-		"operatorG2Pubkey", operator.blsKeypair.GetPubKeyG2(),
+		"operatorG1Pubkey", operator.blsSigner.GetPubKeyG1(),
+		"operatorG2Pubkey", operator.blsSigner.GetPubKeyG2(),
+		"version", operator.version.Version,
+		"commit", operator.version.Commit,
+		"b7sVersion", operator.version.B7sVersion,
+		"runtimeVersion", operator.version.RuntimeVersion,
 	)
 
 	return operator, nil
 
 }
 
+// MetricsRegistry returns the registry served on the eigen metrics address, so other components running along the
+// operator (eg. the p2p node) can register their metrics with it.
+func (o *Operator) MetricsRegistry() *prometheus.Registry {
+	return o.metricsReg
+}
+
 func (o *Operator) Start(ctx context.Context) error {
 	operatorIsRegistered, err := o.avsReader.IsOperatorRegistered(&bind.CallOpts{}, o.operatorAddr)
 	if err != nil {
@@ -256,11 +501,57 @@ func (o *Operator) Start(ctx context.Context) error {
 	if o.config.EnableNodeApi {
 		o.nodeApi.Start()
 	}
-	var metricsErrChan <-chan error
-	if o.config.EnableMetrics {
-		metricsErrChan = o.metrics.Start(ctx, o.metricsReg)
+	o.clockMonitor.Start(ctx)
+	if o.standby != nil {
+		go o.runStandby(ctx)
+	}
+	if o.artifacts != nil {
+		go o.runArtifactPruning(ctx)
+	}
+	go o.aggregatorPool.RunHealthChecks(ctx)
+	go o.sendAggregatorHeartbeats(ctx)
+	if o.responseQueue != nil {
+		go o.responseQueue.Run(ctx)
+	}
+	if o.wasm != nil {
+		defer func() {
+			if err := o.wasm.close(); err != nil {
+				o.logger.Error("Failed to close wasm function database", "err", err)
+			}
+		}()
+	}
+
+	o.setMetricsEnabled(o.config.EnableMetrics)
+	defer o.setMetricsEnabled(false)
+
+	// digests are built from the oracle updates accepted onchain, so we only subscribe to them when digests are enabled
+	var digestTicker <-chan time.Time
+	var webhook *notify.Webhook
+	if o.config.Digest.Webhook.Url != "" {
+		webhook = notify.NewWebhook(o.config.Digest.Webhook)
+	}
+
+	o.quorumWatcher.OnChange(func(params map[uint8]quorum.Params, changes []quorum.Change) {
+		o.checkQuorumStake(ctx, params, changes, webhook)
+	})
+	if err := o.quorumWatcher.Start(ctx); err != nil {
+		o.logger.Error("Failed to load quorum parameters, the operator minimum stake is not checked", "err", err)
 	} else {
-		metricsErrChan = make(chan error, 1)
+		o.checkQuorumStake(ctx, o.quorumWatcher.Params(), nil, webhook)
+	}
+	go o.watchOperatorEvents(ctx, webhook)
+	if o.reregistration != nil {
+		go o.reregisterAfterEjections(ctx, webhook)
+	}
+
+	if webhook != nil {
+		ticker := time.NewTicker(digestSchedules[o.config.Digest.Schedule])
+		defer ticker.Stop()
+		digestTicker = ticker.C
+		subOracleUpdates := chainio.Resubscribe(o.logger, "oracle updates", func() (event.Subscription, error) {
+			return o.avsSubscriber.SubscribeToOracleUpdateResponses(o.oracleUpdatesChan)
+		})
+		defer subOracleUpdates.Unsubscribe()
 	}
 
 	// TODO(samlaf): wrap this call with increase in avs-node-spec metric
@@ -269,32 +560,110 @@ func (o *Operator) Start(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			return nil
-		case err := <-metricsErrChan:
+		case err := <-o.metricsErrs:
 			// TODO(samlaf); we should also register the service as unhealthy in the node api
 			// https://eigen.nethermind.io/docs/spec/api/
 			o.logger.Fatal("Error in metrics server", "err", err)
-		case symbol := <-o.newOracleUpdateChan:
-			o.metrics.IncNumTasksReceived()
-			price, err := o.ProcessOracleUpdateRequest(*symbol)
-			if err != nil {
-				o.logger.Error("Error processing oracle update request", "err", err)
+		case oracleUpdate := <-o.oracleUpdatesChan:
+			go o.recordOracleUpdate(ctx, oracleUpdate)
+		case <-digestTicker:
+			go o.sendDigest(ctx, webhook)
+		case task := <-o.newOracleUpdateChan:
+			signedOracleResponse := o.handleOracleTask(ctx, task)
+			if signedOracleResponse == nil {
+				o.inFlight.done()
 				continue
 			}
-			signedOracleResponse, err := o.SignOracleResponse(price)
-			if err != nil {
-				o.logger.Error("Error signing oracle response", "err", err)
-				continue
-			}
-
 			o.logger.Info("Sending signed oracle response to aggregator", "signedOracleResponse", signedOracleResponse)
-			go o.aggregatorRpcClient.SendSignedOracleResponseToAggregator(signedOracleResponse)
+			go func() {
+				defer o.inFlight.done()
+				o.aggregatorRpcClient.SendSignedOracleResponseToAggregator(signedOracleResponse)
+			}()
 		}
 	}
 }
 
+// handleOracleTask checks whether the task should be signed and executes it, returning its signed response or nil
+// when the task is skipped or failed.
+func (o *Operator) handleOracleTask(ctx context.Context, task *OracleTask) *aggregator.SignedOracleResponse {
+	receivedAt := time.Now()
+	o.metrics.IncNumTasksReceived()
+	o.digest.update(func(d *digestCollector) { d.requestsReceived++ })
+	if o.standby != nil && !o.standby.canSign() {
+		o.logger.Debug("Not signing oracle update request, this instance doesn't hold the signing lease", "symbol", task.Symbol)
+		return nil
+	}
+	if !o.participates(task) {
+		o.logger.Debug("Skipping oracle update request, the operator doesn't participate in the task quorums", "symbol", task.Symbol, "quorumNumbers", task.QuorumNumbers)
+		return nil
+	}
+	if !o.clockMonitor.WithinTolerance() {
+		skew, _ := o.clockMonitor.Skew()
+		o.logger.Error("Not signing oracle update request, local clock skew exceeds tolerance", "symbol", task.Symbol, "skew", skew)
+		o.digest.update(func(d *digestCollector) { d.skippedClockSkew++ })
+		return nil
+	}
+	if o.belowMinimumStake.Load() {
+		o.logger.Error("Not signing oracle update request, operator stake is below the quorum minimum stake", "symbol", task.Symbol)
+		o.digest.update(func(d *digestCollector) { d.skippedBelowMinimumStake++ })
+		return nil
+	}
+	if err := o.policy.Load().check(ctx, task); err != nil {
+		var violation *PolicyViolation
+		if !errors.As(err, &violation) {
+			o.logger.Error("Error checking operator policy", "symbol", task.Symbol, "err", err)
+			o.digest.update(func(d *digestCollector) { d.processingErrors++ })
+			return nil
+		}
+		o.logger.Warn("Not signing oracle update request, denied by operator policy", "symbol", task.Symbol, "rule", violation.Rule, "reason", violation.Reason)
+		o.metrics.IncNumTasksRejectedByPolicy(violation.Rule)
+		o.digest.update(func(d *digestCollector) { d.skippedPolicy++ })
+		return nil
+	}
+	if !o.dedup.firstDelivery(task) {
+		o.logger.Debug("Skipping duplicate delivery of oracle update request", "symbol", task.Symbol, "taskIndex", *task.TaskIndex)
+		return nil
+	}
+	exec := o.artifacts.begin(task.Symbol)
+	price, err := o.processOracleUpdateRequest(task, exec)
+	if err != nil {
+		o.logger.Error("Error processing oracle update request", "err", err)
+		o.digest.update(func(d *digestCollector) { d.processingErrors++ })
+		o.dedup.forget(task)
+		o.finishExecution(exec, nil, err)
+		return nil
+	}
+	// the lease may have been lost while the price was fetched, a standby which took over signs the task instead
+	if o.standby != nil && !o.standby.canSign() {
+		o.logger.Warn("Not signing oracle response, this instance lost the signing lease while processing it", "symbol", task.Symbol)
+		o.dedup.forget(task)
+		o.finishExecution(exec, nil, errSigningLeaseLost)
+		return nil
+	}
+	signedOracleResponse, err := o.SignOracleResponse(price)
+	if err != nil {
+		o.logger.Error("Error signing oracle response", "err", err)
+		o.digest.update(func(d *digestCollector) { d.processingErrors++ })
+		o.dedup.forget(task)
+		o.finishExecution(exec, nil, err)
+		return nil
+	}
+	o.metrics.IncNumTasksSigned()
+	o.lastTaskSignedAt.Store(time.Now().UnixNano())
+	o.metrics.ObserveSigningLatency(time.Since(receivedAt))
+	o.finishExecution(exec, price, nil)
+	o.digest.update(func(d *digestCollector) { d.responsesSent++ })
+	return signedOracleResponse
+}
+
 // TODO: incorporate quorum numbers and quorum threshold percentage into the oracle request
 // TODO: incorporate deadline into oracle request
 func (o *Operator) ProcessOracleUpdateRequest(symbol string) (*csavs.IBlocklessAVSPrice, error) {
+	return o.processOracleUpdateRequest(&OracleTask{Symbol: symbol}, nil)
+}
+
+func (o *Operator) processOracleUpdateRequest(task *OracleTask, exec *execution) (*csavs.IBlocklessAVSPrice, error) {
+	symbol := task.Symbol
 	o.logger.Info("Received new oracle update request for symbol", "symbol", symbol)
 	// "taskIndex", newTaskCreatedLog.TaskIndex,
 	// "taskCreatedBlock", newTaskCreatedLog.Task.TaskCreatedBlock,
@@ -308,13 +677,23 @@ func (o *Operator) ProcessOracleUpdateRequest(symbol string) (*csavs.IBlocklessA
 		return nil, err
 	}
 	blockTimestamp := block.Time()
+	exec.logf("latest block %d, timestamp %d", block.NumberU64(), blockTimestamp)
 
-	price, err := getPriceByID(symbol)
+	var price float64
+	if task.Function != nil {
+		if o.wasm == nil {
+			return nil, ErrWasmDisabled
+		}
+		price, err = o.wasm.run(task.Function, exec)
+	} else {
+		price, err = fetchPrice(symbol, exec)
+	}
 	if err != nil {
 		o.logger.Error("Error getting price", "err", err)
 		return nil, err
 	}
 	price6Decimals := formatPriceToSixDecimals(price)
+	exec.logf("formatted price %d (6 decimals)", price6Decimals)
 
 	return &csavs.IBlocklessAVSPrice{
 		Symbol:    symbol,
@@ -323,9 +702,34 @@ func (o *Operator) ProcessOracleUpdateRequest(symbol string) (*csavs.IBlocklessA
 	}, nil
 }
 
+// finishExecution stores the artifacts of the execution, if they are enabled.
+func (o *Operator) finishExecution(exec *execution, price *csavs.IBlocklessAVSPrice, err error) {
+	if err := exec.finish(price, err); err != nil {
+		o.logger.Error("Failed to store execution artifacts", "err", err)
+	}
+}
+
+// Artifacts returns the execution artifact store, or nil when artifacts are disabled.
+func (o *Operator) Artifacts() *ArtifactStore {
+	return o.artifacts
+}
+
 func (o *Operator) RequestOracleUpdate(symbol string) {
-	o.logger.Info("Operator requesting oracle update", "symbol", symbol)
-	o.newOracleUpdateChan <- &symbol
+	o.RequestOracleTask(&OracleTask{Symbol: symbol})
+}
+
+// RequestOracleTask queues the task for execution. Tasks referencing a wasm function are rejected with
+// ErrWasmDisabled when no blockless runtime is configured, and all tasks with ErrDraining once the operator is draining.
+func (o *Operator) RequestOracleTask(task *OracleTask) error {
+	if task.Function != nil && o.wasm == nil {
+		return ErrWasmDisabled
+	}
+	if !o.inFlight.begin() {
+		return ErrDraining
+	}
+	o.logger.Info("Operator requesting oracle update", "symbol", task.Symbol, "function", task.Function)
+	o.newOracleUpdateChan <- task
+	return nil
 }
 
 func (o *Operator) SignOracleResponse(price *csavs.IBlocklessAVSPrice) (*aggregator.SignedOracleResponse, error) {
@@ -334,11 +738,27 @@ func (o *Operator) SignOracleResponse(price *csavs.IBlocklessAVSPrice) (*aggrega
 		o.logger.Error("Error getting price response header hash. skipping task (this is not expected and should be investigated)", "err", err)
 		return nil, err
 	}
-	blsSignature := o.blsKeypair.SignMessage(priceHash)
+	blsSignature, err := o.blsSigner.SignMessage(context.Background(), priceHash)
+	if err != nil {
+		o.logger.Error("Error signing price response digest", "err", err)
+		return nil, err
+	}
+	versionDigest, err := o.version.Digest(priceHash)
+	if err != nil {
+		o.logger.Error("Error getting software version digest", "err", err)
+		return nil, err
+	}
+	versionSignature, err := o.blsSigner.SignMessage(context.Background(), versionDigest)
+	if err != nil {
+		o.logger.Error("Error signing software version digest", "err", err)
+		return nil, err
+	}
 	signedOracleResponse := &aggregator.SignedOracleResponse{
-		PriceResponse: *price,
-		BlsSignature:  *blsSignature,
-		OperatorId:    o.operatorId,
+		PriceResponse:    *price,
+		BlsSignature:     *blsSignature,
+		OperatorId:       o.operatorId,
+		Version:          o.version,
+		VersionSignature: *versionSignature,
 	}
 	o.logger.Debug("Signed oracle response", "signedOracleResponse", signedOracleResponse)
 	return signedOracleResponse, nil
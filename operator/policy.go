@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+const (
+	PolicyDeniedTaskIndex  = "denied_task_index"
+	PolicyMaxInputSize     = "max_input_size"
+	PolicyAllowedFunctions = "allowed_function_cids"
+	PolicyRequiredQuorums  = "required_quorums"
+)
+
+// PolicyViolation is returned for the tasks the operator policy doesn't allow signing.
+type PolicyViolation struct {
+	// rule which denied the task, one of the Policy constants
+	Rule   string
+	Reason string
+}
+
+func (e *PolicyViolation) Error() string {
+	return fmt.Sprintf("task denied by operator policy (%s): %s", e.Rule, e.Reason)
+}
+
+// operatorQuorums returns the quorums the operator is currently registered in.
+type operatorQuorums func(ctx context.Context) ([]uint8, error)
+
+// newTaskPolicy creates the policy of the operator from cfg.
+func (o *Operator) newTaskPolicy(cfg avstypes.PolicyConfig) (*taskPolicy, error) {
+	return newTaskPolicy(cfg, func(ctx context.Context) ([]uint8, error) {
+		return o.quorumWatcher.OperatorQuorums(ctx, o.operatorId)
+	})
+}
+
+// taskPolicy decides whether the operator signs a task, according to the policy config.
+type taskPolicy struct {
+	deniedTaskIndices   map[uint32]bool
+	maxInputSize        int
+	allowedFunctionCids map[string]bool
+	requiredQuorums     []uint8
+	quorums             operatorQuorums
+}
+
+func newTaskPolicy(cfg avstypes.PolicyConfig, quorums operatorQuorums) (*taskPolicy, error) {
+	if cfg.MaxInputSize < 0 {
+		return nil, errors.New("policy max_input_size cannot be negative")
+	}
+	p := &taskPolicy{
+		deniedTaskIndices: make(map[uint32]bool, len(cfg.DeniedTaskIndices)),
+		maxInputSize:      cfg.MaxInputSize,
+		requiredQuorums:   cfg.RequiredQuorums,
+		quorums:           quorums,
+	}
+	for _, index := range cfg.DeniedTaskIndices {
+		p.deniedTaskIndices[index] = true
+	}
+	if len(cfg.AllowedFunctionCids) > 0 {
+		p.allowedFunctionCids = make(map[string]bool, len(cfg.AllowedFunctionCids))
+		for _, cid := range cfg.AllowedFunctionCids {
+			p.allowedFunctionCids[cid] = true
+		}
+	}
+	return p, nil
+}
+
+// check returns a *PolicyViolation when the task breaks a rule of the policy. The quorum membership is checked last,
+// since it is the only rule reading the chain.
+func (p *taskPolicy) check(ctx context.Context, task *OracleTask) error {
+	if task.TaskIndex != nil && p.deniedTaskIndices[*task.TaskIndex] {
+		return &PolicyViolation{Rule: PolicyDeniedTaskIndex, Reason: fmt.Sprintf("task %d is denied", *task.TaskIndex)}
+	}
+	if p.maxInputSize > 0 {
+		input, err := task.input()
+		if err != nil {
+			return err
+		}
+		if len(input) > p.maxInputSize {
+			return &PolicyViolation{Rule: PolicyMaxInputSize, Reason: fmt.Sprintf("input is %d bytes, max %d", len(input), p.maxInputSize)}
+		}
+	}
+	if task.Function != nil && p.allowedFunctionCids != nil && !p.allowedFunctionCids[task.Function.Cid] {
+		return &PolicyViolation{Rule: PolicyAllowedFunctions, Reason: fmt.Sprintf("function %s is not allowed", task.Function.Cid)}
+	}
+	if len(p.requiredQuorums) > 0 {
+		quorums, err := p.quorums(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get operator quorums: %w", err)
+		}
+		for _, quorum := range p.requiredQuorums {
+			if !slices.Contains(quorums, quorum) {
+				return &PolicyViolation{Rule: PolicyRequiredQuorums, Reason: fmt.Sprintf("operator is not registered in quorum %d", quorum)}
+			}
+		}
+	}
+	return nil
+}
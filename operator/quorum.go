@@ -0,0 +1,70 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/notify"
+	"github.com/zees-dev/blockless-avs/core/quorum"
+)
+
+// QuorumStakeAlert is sent to the digest webhook when the operator falls below, or gets back above,
+// the minimum stake of one of its quorums.
+type QuorumStakeAlert struct {
+	OperatorId        string          `json:"operatorId"`
+	OperatorAddress   string          `json:"operatorAddress"`
+	Time              time.Time       `json:"time"`
+	BelowMinimumStake bool            `json:"belowMinimumStake"`
+	Quorums           []QuorumStake   `json:"quorums"`
+	Changes           []quorum.Change `json:"changes,omitempty"`
+}
+
+// QuorumStake is the weight of the operator in a quorum compared to the quorum minimum stake.
+type QuorumStake struct {
+	QuorumNumber uint8  `json:"quorumNumber"`
+	Weight       string `json:"weight"`
+	MinimumStake string `json:"minimumStake"`
+}
+
+// checkQuorumStake compares the operator weight in every quorum with the quorum minimum stake, and alerts when the
+// operator status changed. Responses are not signed while the operator is below the minimum stake of a quorum, since
+// the aggregator rejects them.
+func (o *Operator) checkQuorumStake(ctx context.Context, params map[uint8]quorum.Params, changes []quorum.Change, webhook *notify.Webhook) {
+	below := false
+	stakes := make([]QuorumStake, 0, len(params))
+	for quorumNumber, p := range params {
+		weight, err := o.quorumWatcher.OperatorWeight(ctx, quorumNumber, o.operatorAddr)
+		if err != nil {
+			o.logger.Error("Failed to get operator weight", "quorumNumber", quorumNumber, "err", err)
+			return
+		}
+		stakes = append(stakes, QuorumStake{QuorumNumber: quorumNumber, Weight: weight.String(), MinimumStake: p.MinimumStake.String()})
+		if weight.Cmp(p.MinimumStake) < 0 {
+			below = true
+		}
+	}
+	if o.belowMinimumStake.Swap(below) == below {
+		return
+	}
+
+	if below {
+		o.logger.Error("Operator stake is below the quorum minimum stake, oracle update requests are not signed until it's increased", "quorums", stakes, "changes", changes)
+	} else {
+		o.logger.Info("Operator stake is above the quorum minimum stake again", "quorums", stakes)
+	}
+	if webhook == nil {
+		return
+	}
+	alert := QuorumStakeAlert{
+		OperatorId:        fmt.Sprintf("%x", o.operatorId[:]),
+		OperatorAddress:   o.operatorAddr.Hex(),
+		Time:              time.Now(),
+		BelowMinimumStake: below,
+		Quorums:           stakes,
+		Changes:           changes,
+	}
+	if err := webhook.Send(ctx, alert); err != nil {
+		o.logger.Error("Failed to send quorum stake alert", "err", err)
+	}
+}
@@ -0,0 +1,57 @@
+package operator
+
+import (
+	"fmt"
+	"slices"
+
+	eigenSdkTypes "github.com/Layr-Labs/eigensdk-go/types"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+)
+
+// participatingQuorums returns the quorums the operator participates in: the configured ones, or all the avs quorums
+// when none are configured.
+func participatingQuorums(configured []uint8) (eigenSdkTypes.QuorumNums, error) {
+	if len(configured) == 0 {
+		return types.QUORUM_NUMBERS, nil
+	}
+	quorums := make(eigenSdkTypes.QuorumNums, 0, len(configured))
+	for _, quorumNumber := range configured {
+		if slices.Contains(quorums, eigenSdkTypes.QuorumNum(quorumNumber)) {
+			return nil, fmt.Errorf("quorum %d is configured more than once", quorumNumber)
+		}
+		quorums = append(quorums, eigenSdkTypes.QuorumNum(quorumNumber))
+	}
+	return quorums, nil
+}
+
+// Quorums returns the quorums the operator participates in.
+func (o *Operator) Quorums() eigenSdkTypes.QuorumNums {
+	return o.quorums
+}
+
+// participates reports whether any quorum of the task is one the operator participates in. Tasks which don't carry
+// their quorums are in all the avs quorums.
+func (o *Operator) participates(task *OracleTask) bool {
+	taskQuorums := task.QuorumNumbers
+	if len(taskQuorums) == 0 {
+		taskQuorums = types.QUORUM_NUMBERS.UnderlyingType()
+	}
+	for _, quorumNumber := range taskQuorums {
+		if slices.Contains(o.quorums, eigenSdkTypes.QuorumNum(quorumNumber)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkParticipating returns an error when any of the quorums isn't one the operator participates in, so the operator
+// isn't registered in quorums it would never sign tasks of.
+func (o *Operator) checkParticipating(quorumNumbers eigenSdkTypes.QuorumNums) error {
+	for _, quorumNumber := range quorumNumbers {
+		if !slices.Contains(o.quorums, quorumNumber) {
+			return fmt.Errorf("operator doesn't participate in quorum %d, add it to quorums in the config to register in it (participating in %v)", quorumNumber, o.quorums)
+		}
+	}
+	return nil
+}
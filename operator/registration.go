@@ -8,10 +8,11 @@ package operator
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -20,8 +21,43 @@ import (
 	eigenSdkTypes "github.com/Layr-Labs/eigensdk-go/types"
 
 	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+
+	"github.com/zees-dev/blockless-avs/core/chainio"
 )
 
+// RegistrationReport is the result of registering the operator in quorums of the avs, or deregistering it from them.
+type RegistrationReport struct {
+	Operator   common.Address `json:"operator"`
+	OperatorId string         `json:"operatorId"`
+	// registration with eigenlayer, only done by Register
+	Eigenlayer *DelegationReport `json:"eigenlayer,omitempty"`
+	// quorums registered in, or deregistered from, by the transaction
+	QuorumNumbers []int `json:"quorumNumbers"`
+	// quorums skipped because the operator is already registered in them
+	AlreadyRegisteredQuorumNumbers []int  `json:"alreadyRegisteredQuorumNumbers,omitempty"`
+	Socket                         string `json:"socket,omitempty"`
+	// not set when there was nothing to register
+	TxHash *common.Hash `json:"txHash,omitempty"`
+}
+
+func (o *Operator) newRegistrationReport() *RegistrationReport {
+	operatorId := eigenSdkTypes.OperatorIdFromG1Pubkey(o.blsSigner.GetPubKeyG1())
+	return &RegistrationReport{
+		Operator:      o.operatorAddr,
+		OperatorId:    hex.EncodeToString(operatorId[:]),
+		QuorumNumbers: []int{},
+	}
+}
+
+// quorumInts converts the quorum numbers for json, which would encode them as base64 bytes.
+func quorumInts(quorumNumbers eigenSdkTypes.QuorumNums) []int {
+	ints := make([]int, len(quorumNumbers))
+	for i, q := range quorumNumbers {
+		ints[i] = int(q)
+	}
+	return ints
+}
+
 func (o *Operator) registerOperatorOnStartup(
 	operatorEcdsaPrivateKey *ecdsa.PrivateKey,
 	mockTokenStrategyAddr common.Address,
@@ -42,7 +78,7 @@ func (o *Operator) registerOperatorOnStartup(
 	}
 	o.logger.Infof("Deposited %s into strategy %s", amount, mockTokenStrategyAddr)
 
-	err = o.RegisterOperatorWithAvs(operatorEcdsaPrivateKey)
+	_, err = o.RegisterOperatorWithAvs(operatorEcdsaPrivateKey)
 	if err != nil {
 		o.logger.Fatal("Error registering operator with avs", "err", err)
 	}
@@ -62,72 +98,223 @@ func (o *Operator) RegisterOperatorWithEigenlayer() error {
 	return nil
 }
 
+// DepositIntoStrategy mints amount mock tokens to the operator and deposits them into the strategy.
 func (o *Operator) DepositIntoStrategy(strategyAddr common.Address, amount *big.Int) error {
-	_, tokenAddr, err := o.eigenlayerReader.GetStrategyAndUnderlyingToken(&bind.CallOpts{}, strategyAddr)
-	if err != nil {
-		o.logger.Error("Failed to fetch strategy contract", "err", err)
-		return err
-	}
-	contractErc20Mock, err := o.avsReader.GetErc20Mock(context.Background(), tokenAddr)
+	plan, err := o.PlanDeposit(context.Background(), strategyAddr, amount, true)
 	if err != nil {
-		o.logger.Error("Failed to fetch ERC20Mock contract", "err", err)
 		return err
 	}
-	txOpts, err := o.avsWriter.TxMgr.GetNoSendTxOpts()
-	tx, err := contractErc20Mock.Mint(txOpts, o.operatorAddr, amount)
-	if err != nil {
-		o.logger.Errorf("Error assembling Mint tx")
-		return err
-	}
-	_, err = o.avsWriter.TxMgr.Send(context.Background(), tx)
-	if err != nil {
-		o.logger.Errorf("Error submitting Mint tx")
-		return err
-	}
-
-	_, err = o.eigenlayerWriter.DepositERC20IntoStrategy(context.Background(), strategyAddr, amount)
-	if err != nil {
-		o.logger.Errorf("Error depositing into strategy", "err", err)
-		return err
-	}
-	return nil
+	_, err = o.Deposit(context.Background(), plan)
+	return err
 }
 
 // Registration specific functions
 // TODO: address this for actual holesky testnet deployment
 func (o *Operator) RegisterOperatorWithAvs(
 	operatorEcdsaKeyPair *ecdsa.PrivateKey,
-) error {
-	// hardcode these things for now
-	quorumNumbers := eigenSdkTypes.QuorumNums{eigenSdkTypes.QuorumNum(0)}
-	socket := "Not Needed"
-	operatorToAvsRegistrationSigSalt := [32]byte{124}
+) (*RegistrationReport, error) {
+	return o.RegisterOperatorInQuorums(operatorEcdsaKeyPair, o.quorums, o.config.Socket)
+}
+
+// RegisterOperatorInQuorums registers the operator with the avs registry coordinator in the given quorums. The bls
+// pubkey is registered along the first quorum registration, and the operator is registered with the avs directory
+// when it isn't registered with the avs yet.
+func (o *Operator) RegisterOperatorInQuorums(
+	operatorEcdsaKeyPair *ecdsa.PrivateKey,
+	quorumNumbers eigenSdkTypes.QuorumNums,
+	socket string,
+) (*RegistrationReport, error) {
+	if o.blsKeypair == nil {
+		return nil, fmt.Errorf("registering with the avs requires the bls keystore, it can't be done with a remote bls signer")
+	}
+	if socket == "" {
+		socket = "Not Needed"
+	}
+	// the avs directory rejects salts which were already used, eg. when re-registering after deregistering
+	var operatorToAvsRegistrationSigSalt [32]byte
+	if _, err := rand.Read(operatorToAvsRegistrationSigSalt[:]); err != nil {
+		return nil, err
+	}
 	curBlockNum, err := o.ethClient.BlockNumber(context.Background())
 	if err != nil {
 		o.logger.Errorf("Unable to get current block number")
-		return err
+		return nil, err
 	}
 	curBlock, err := o.ethClient.BlockByNumber(context.Background(), big.NewInt(int64(curBlockNum)))
 	if err != nil {
 		o.logger.Errorf("Unable to get current block")
-		return err
+		return nil, err
 	}
 	sigValidForSeconds := int64(1_000_000)
 	operatorToAvsRegistrationSigExpiry := big.NewInt(int64(curBlock.Time()) + sigValidForSeconds)
-	_, err = o.avsWriter.RegisterOperatorInQuorumWithAVSRegistryCoordinator(
+	receipt, err := o.avsWriter.RegisterOperatorInQuorumWithAVSRegistryCoordinator(
 		context.Background(),
 		operatorEcdsaKeyPair, operatorToAvsRegistrationSigSalt, operatorToAvsRegistrationSigExpiry,
 		o.blsKeypair, quorumNumbers, socket,
 	)
 	if err != nil {
 		o.logger.Errorf("Unable to register operator with avs registry coordinator")
+		return nil, err
+	}
+	o.logger.Info("Registered operator with avs registry coordinator", "quorumNumbers", quorumNumbers, "socket", socket)
+
+	report := o.newRegistrationReport()
+	report.QuorumNumbers = quorumInts(quorumNumbers)
+	report.Socket = socket
+	report.TxHash = &receipt.TxHash
+	return report, nil
+}
+
+// Register performs the whole registration flow: it registers the operator with eigenlayer unless it's already
+// registered, then registers it with the avs in the given quorums, which must be ones the operator participates in.
+func (o *Operator) Register(operatorEcdsaKeyPair *ecdsa.PrivateKey, quorumNumbers eigenSdkTypes.QuorumNums, socket string) (*RegistrationReport, error) {
+	if err := o.checkParticipating(quorumNumbers); err != nil {
+		return nil, err
+	}
+	eigenlayer, err := o.DelegateToSelf(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if eigenlayer.AlreadyDelegated {
+		o.logger.Info("Operator is already registered with eigenlayer")
+	} else {
+		o.logger.Info("Registered operator with eigenlayer")
+	}
+
+	registeredQuorums, err := o.registeredQuorums()
+	if err != nil {
+		return nil, err
+	}
+	if len(registeredQuorums) > 0 {
+		return nil, fmt.Errorf("operator is already registered with the avs in quorums %v, use opt-in-quorums to register in more quorums", registeredQuorums)
+	}
+	report, err := o.RegisterOperatorInQuorums(operatorEcdsaKeyPair, quorumNumbers, socket)
+	if err != nil {
+		return nil, err
+	}
+	report.Eigenlayer = eigenlayer
+	return report, nil
+}
+
+// EnsureRegistered registers the operator with eigenlayer and with the avs in its quorums, after depositing amount mock
+// tokens into the strategy, unless the operator is already registered with the avs. It is meant for local chains
+// (see avs all-in-one --devnet) and must be called before Start.
+func (o *Operator) EnsureRegistered(operatorEcdsaKeyPair *ecdsa.PrivateKey, strategyAddr common.Address, amount *big.Int) error {
+	registeredQuorums, err := o.registeredQuorums()
+	if err != nil {
+		return err
+	}
+	if len(registeredQuorums) > 0 {
+		o.logger.Info("Operator is already registered with the avs", "quorumNumbers", registeredQuorums)
+		return nil
+	}
+
+	registered, err := o.eigenlayerReader.IsOperatorRegistered(&bind.CallOpts{}, eigenSdkTypes.Operator{Address: o.operatorAddr.String()})
+	if err != nil {
+		o.logger.Error("Unable to check if operator is registered with eigenlayer", "err", err)
+		return err
+	}
+	if !registered {
+		if err := o.RegisterOperatorWithEigenlayer(); err != nil {
+			return err
+		}
+		o.logger.Info("Registered operator with eigenlayer")
+	}
+	if err := o.DepositIntoStrategy(strategyAddr, amount); err != nil {
+		return err
+	}
+	o.logger.Info("Deposited into strategy", "amount", amount, "strategy", strategyAddr)
+	if _, err := o.RegisterOperatorWithAvs(operatorEcdsaKeyPair); err != nil {
 		return err
 	}
-	o.logger.Infof("Registered operator with avs registry coordinator.")
 
+	// the operator id is only assigned on registration
+	operatorId, err := o.avsReader.GetOperatorId(&bind.CallOpts{}, o.operatorAddr)
+	if err != nil {
+		o.logger.Error("Cannot get operator id", "err", err)
+		return err
+	}
+	o.operatorId = operatorId
 	return nil
 }
 
+// OptInQuorums registers an operator already registered with the avs in additional quorums, which must be ones the
+// operator participates in. Quorums the operator is already registered in are skipped.
+func (o *Operator) OptInQuorums(operatorEcdsaKeyPair *ecdsa.PrivateKey, quorumNumbers eigenSdkTypes.QuorumNums, socket string) (*RegistrationReport, error) {
+	if err := o.checkParticipating(quorumNumbers); err != nil {
+		return nil, err
+	}
+	registeredQuorums, err := o.registeredQuorums()
+	if err != nil {
+		return nil, err
+	}
+	if len(registeredQuorums) == 0 {
+		return nil, fmt.Errorf("operator is not registered with the avs, use register first")
+	}
+	var newQuorums, alreadyRegistered eigenSdkTypes.QuorumNums
+	for _, q := range quorumNumbers {
+		if slices.Contains(registeredQuorums, q) {
+			alreadyRegistered = append(alreadyRegistered, q)
+		} else {
+			newQuorums = append(newQuorums, q)
+		}
+	}
+	report := o.newRegistrationReport()
+	if len(newQuorums) == 0 {
+		o.logger.Info("Operator is already registered in all the quorums", "quorumNumbers", quorumNumbers)
+	} else if report, err = o.RegisterOperatorInQuorums(operatorEcdsaKeyPair, newQuorums, socket); err != nil {
+		return nil, err
+	}
+	report.AlreadyRegisteredQuorumNumbers = quorumInts(alreadyRegistered)
+	return report, nil
+}
+
+// DeregisterOperatorFromAvs deregisters the operator from the given quorums of the avs. The operator is deregistered
+// from the avs directory once it's deregistered from all its quorums.
+func (o *Operator) DeregisterOperatorFromAvs(quorumNumbers eigenSdkTypes.QuorumNums) (*RegistrationReport, error) {
+	registeredQuorums, err := o.registeredQuorums()
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quorumNumbers {
+		if !slices.Contains(registeredQuorums, q) {
+			return nil, fmt.Errorf("operator is not registered in quorum %d", q)
+		}
+	}
+	receipt, err := o.avsWriter.DeregisterOperator(context.Background(), quorumNumbers, pubKeyG1ToBN254G1Point(o.blsSigner.GetPubKeyG1()))
+	if err != nil {
+		o.logger.Errorf("Unable to deregister operator from avs registry coordinator")
+		return nil, err
+	}
+	o.logger.Info("Deregistered operator from avs registry coordinator", "quorumNumbers", quorumNumbers)
+	report := o.newRegistrationReport()
+	report.QuorumNumbers = quorumInts(quorumNumbers)
+	report.TxHash = &receipt.TxHash
+	return report, nil
+}
+
+// registeredQuorums returns the quorums the operator is currently registered in.
+func (o *Operator) registeredQuorums() (eigenSdkTypes.QuorumNums, error) {
+	operatorId, err := o.avsReader.GetOperatorId(&bind.CallOpts{}, o.operatorAddr)
+	if err != nil {
+		return nil, err
+	}
+	if operatorId == [32]byte{} {
+		return nil, nil
+	}
+	stakes, err := o.avsReader.GetOperatorStakeInQuorumsOfOperatorAtCurrentBlock(&bind.CallOpts{}, operatorId)
+	if err != nil {
+		o.logger.Error("Unable to get the quorums of the operator", "err", err)
+		return nil, err
+	}
+	quorums := make(eigenSdkTypes.QuorumNums, 0, len(stakes))
+	for q := range stakes {
+		quorums = append(quorums, q)
+	}
+	slices.Sort(quorums)
+	return quorums, nil
+}
+
 // PRINTING STATUS OF OPERATOR: 1
 // operator address: 0xa0ee7a142d267c1f36714e4a8f75612f20a79720
 // dummy token balance: 0
@@ -152,28 +339,22 @@ type OperatorStatus struct {
 	OperatorId        string
 }
 
-func (o *Operator) PrintOperatorStatus() error {
-	fmt.Println("Printing operator status")
+// Status returns the registration status of the operator.
+func (o *Operator) Status() (*OperatorStatus, error) {
 	operatorId, err := o.avsReader.GetOperatorId(&bind.CallOpts{}, o.operatorAddr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	pubkeysRegistered := operatorId != [32]byte{}
 	registeredWithAvs := o.operatorId != [32]byte{}
-	operatorStatus := OperatorStatus{
+	return &OperatorStatus{
 		EcdsaAddress:      o.operatorAddr.String(),
 		PubkeysRegistered: pubkeysRegistered,
-		G1Pubkey:          o.blsKeypair.GetPubKeyG1().String(),
-		G2Pubkey:          o.blsKeypair.GetPubKeyG2().String(),
+		G1Pubkey:          o.blsSigner.GetPubKeyG1().String(),
+		G2Pubkey:          o.blsSigner.GetPubKeyG2().String(),
 		RegisteredWithAvs: registeredWithAvs,
 		OperatorId:        hex.EncodeToString(o.operatorId[:]),
-	}
-	operatorStatusJson, err := json.MarshalIndent(operatorStatus, "", " ")
-	if err != nil {
-		return err
-	}
-	fmt.Println(string(operatorStatusJson))
-	return nil
+	}, nil
 }
 
 func pubKeyG1ToBN254G1Point(p *bls.G1Point) regcoord.BN254G1Point {
@@ -182,3 +363,13 @@ func pubKeyG1ToBN254G1Point(p *bls.G1Point) regcoord.BN254G1Point {
 		Y: p.Y.BigInt(new(big.Int)),
 	}
 }
+
+// SetDryRun makes the operator simulate its transactions instead of sending them, see chainio.TxManager.SetDryRun.
+func (o *Operator) SetDryRun(dryRun bool) {
+	o.txMgr.SetDryRun(dryRun)
+}
+
+// DryRunTxs returns the transactions the operator simulated in dry run.
+func (o *Operator) DryRunTxs() []chainio.DryRunTx {
+	return o.txMgr.DryRunTxs()
+}
@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/zees-dev/blockless-avs/core/config"
+	corelogging "github.com/zees-dev/blockless-avs/core/logging"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+// Reload applies the settings of c which can be changed while the operator is running: the log level (production),
+// the aggregator endpoints, the gas settings, whether metrics are served and the task policy. The other settings only take effect on
+// restart. Nothing is applied when c is invalid.
+func (o *Operator) Reload(c avstypes.NodeConfig) error {
+	gas, err := config.NewGasConfig(c.Gas)
+	if err != nil {
+		return err
+	}
+	policy, err := o.newTaskPolicy(c.Policy)
+	if err != nil {
+		return err
+	}
+
+	o.reloadMu.Lock()
+	defer o.reloadMu.Unlock()
+	if err := o.aggregatorPool.Reload(c.AggregatorServerIpPortAddress, c.Aggregators); err != nil {
+		return err
+	}
+	setLogLevel(c.Production)
+	o.txMgr.SetGas(gas)
+	o.setMetricsEnabled(c.EnableMetrics)
+	o.policy.Store(policy)
+
+	o.logger.Info("Reloaded operator config", "production", c.Production, "aggregator", c.AggregatorServerIpPortAddress,
+		"backupAggregators", c.Aggregators.Backups, "aggregatorsMode", c.Aggregators.Mode, "gas", gas, "enableMetrics", c.EnableMetrics, "policy", c.Policy)
+	if restartRequired(o.config, c) {
+		o.logger.Warn("Operator config has changed settings which can't be reloaded, they take effect on restart")
+	}
+	return nil
+}
+
+// restartRequired reports whether settings other than the reloadable ones differ between the configs.
+func restartRequired(current, reloaded avstypes.NodeConfig) bool {
+	for _, c := range []*avstypes.NodeConfig{&current, &reloaded} {
+		c.Production = false
+		c.AggregatorServerIpPortAddress = ""
+		c.Aggregators = avstypes.AggregatorsConfig{}
+		c.Gas = config.GasConfigRaw{}
+		c.EnableMetrics = false
+		c.Policy = avstypes.PolicyConfig{}
+	}
+	return !reflect.DeepEqual(current, reloaded)
+}
+
+func setLogLevel(production bool) {
+	if production {
+		corelogging.SetLevel(corelogging.Production)
+	} else {
+		corelogging.SetLevel(corelogging.Development)
+	}
+}
+
+// setMetricsEnabled starts or stops serving the prometheus metrics on the eigen metrics address. Server failures are
+// reported on metricsErrs.
+func (o *Operator) setMetricsEnabled(enabled bool) {
+	o.metricsMu.Lock()
+	defer o.metricsMu.Unlock()
+	if enabled == (o.metricsServer != nil) {
+		return
+	}
+	if !enabled {
+		if err := o.metricsServer.Close(); err != nil {
+			o.logger.Error("Failed to stop metrics server", "err", err)
+		}
+		o.metricsServer = nil
+		o.logger.Info("Stopped metrics server")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(o.metricsReg, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: o.config.EigenMetricsIpPortAddress, Handler: mux}
+	o.metricsServer = server
+	o.logger.Info("Starting metrics server", "address", server.Addr)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			select {
+			case o.metricsErrs <- fmt.Errorf("prometheus server failed: %w", err):
+			default:
+			}
+		}
+	}()
+}
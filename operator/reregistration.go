@@ -0,0 +1,178 @@
+package operator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	eigenSdkTypes "github.com/Layr-Labs/eigensdk-go/types"
+
+	"github.com/zees-dev/blockless-avs/core/notify"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+const (
+	defaultReregistrationMaxAttempts    = 5
+	defaultReregistrationInitialBackoff = time.Minute
+	defaultReregistrationMaxBackoff     = time.Hour
+)
+
+// errManualActionRequired is returned by the re-registration attempts which can't succeed without the operator
+// intervening, eg. adding stake.
+var errManualActionRequired = errors.New("manual action required")
+
+// ReregistrationAlert is sent to the digest webhook when the operator couldn't re-register automatically after an
+// ejection.
+type ReregistrationAlert struct {
+	OperatorId      string    `json:"operatorId"`
+	OperatorAddress string    `json:"operatorAddress"`
+	Time            time.Time `json:"time"`
+	Attempts        int       `json:"attempts"`
+	Reason          string    `json:"reason"`
+}
+
+// reregistration re-registers the operator in the quorums it was ejected from.
+type reregistration struct {
+	// signs the avs registration
+	ecdsaKey       *ecdsa.PrivateKey
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// signaled on every ejection
+	ejected chan struct{}
+}
+
+func newReregistration(cfg avstypes.ReregistrationConfig, ecdsaKey *ecdsa.PrivateKey) (*reregistration, error) {
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = defaultReregistrationMaxAttempts
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = defaultReregistrationInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultReregistrationMaxBackoff
+	}
+	if cfg.MaxAttempts < 0 || cfg.InitialBackoff < 0 || cfg.MaxBackoff < cfg.InitialBackoff {
+		return nil, errors.New("invalid reregistration config, max_attempts and durations must be positive and max_backoff at least initial_backoff")
+	}
+	return &reregistration{
+		ecdsaKey:       ecdsaKey,
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		ejected:        make(chan struct{}, 1),
+	}, nil
+}
+
+// notifyEjected schedules a re-registration; ejections happening while one is in progress are coalesced.
+func (r *reregistration) notifyEjected() {
+	select {
+	case r.ejected <- struct{}{}:
+	default:
+	}
+}
+
+// reregisterAfterEjections re-registers the operator after every ejection until ctx is cancelled.
+func (o *Operator) reregisterAfterEjections(ctx context.Context, webhook *notify.Webhook) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.reregistration.ejected:
+			o.reregister(ctx, webhook)
+		}
+	}
+}
+
+// reregister attempts to re-register the operator with exponential backoff, alerting when it gives up.
+func (o *Operator) reregister(ctx context.Context, webhook *notify.Webhook) {
+	r := o.reregistration
+	backoff := r.initialBackoff
+	for attempt := 1; ; attempt++ {
+		o.logger.Info("Re-registering operator after ejection", "attempt", attempt, "in", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		err := o.tryReregister(ctx)
+		if err == nil {
+			return
+		}
+		if errors.Is(err, errManualActionRequired) || attempt >= r.maxAttempts {
+			o.logger.Error("Operator could not re-register after ejection, manual action is required", "attempts", attempt, "err", err)
+			o.alertReregistrationFailed(ctx, webhook, attempt, err)
+			return
+		}
+		o.logger.Warn("Operator re-registration attempt failed", "attempt", attempt, "err", err)
+		backoff = min(2*backoff, r.maxBackoff)
+	}
+}
+
+// tryReregister registers the operator in the quorums it participates in but isn't registered in anymore. It fails without sending a
+// transaction when a quorum is full, since registering would require churning out another operator, or when the
+// operator stake is below a quorum minimum stake.
+func (o *Operator) tryReregister(ctx context.Context) error {
+	registered, err := o.quorumWatcher.OperatorQuorums(ctx, o.operatorId)
+	if err != nil {
+		return fmt.Errorf("could not get operator quorums: %w", err)
+	}
+	var missing eigenSdkTypes.QuorumNums
+	for _, quorumNumber := range o.quorums.UnderlyingType() {
+		if !slices.Contains(registered, quorumNumber) {
+			missing = append(missing, eigenSdkTypes.QuorumNum(quorumNumber))
+		}
+	}
+	if len(missing) == 0 {
+		o.logger.Info("Operator is registered in all its quorums again")
+		return nil
+	}
+
+	params := o.quorumWatcher.Params()
+	for _, quorumNumber := range missing.UnderlyingType() {
+		p, ok := params[quorumNumber]
+		if !ok {
+			continue
+		}
+		weight, err := o.quorumWatcher.OperatorWeight(ctx, quorumNumber, o.operatorAddr)
+		if err != nil {
+			return fmt.Errorf("could not get operator weight in quorum %d: %w", quorumNumber, err)
+		}
+		if weight.Cmp(p.MinimumStake) < 0 {
+			return fmt.Errorf("%w: operator weight %s in quorum %d is below the minimum stake %s", errManualActionRequired, weight, quorumNumber, p.MinimumStake)
+		}
+		count, err := o.quorumWatcher.OperatorCount(ctx, quorumNumber)
+		if err != nil {
+			return fmt.Errorf("could not get operator count of quorum %d: %w", quorumNumber, err)
+		}
+		if count >= p.MaxOperatorCount {
+			return fmt.Errorf("quorum %d is full (%d operators), registering requires churn", quorumNumber, count)
+		}
+	}
+
+	if _, err := o.RegisterOperatorInQuorums(o.reregistration.ecdsaKey, missing, o.config.Socket); err != nil {
+		return err
+	}
+	o.logger.Info("Re-registered operator after ejection", "quorumNumbers", missing)
+	return nil
+}
+
+func (o *Operator) alertReregistrationFailed(ctx context.Context, webhook *notify.Webhook, attempts int, err error) {
+	if webhook == nil {
+		return
+	}
+	alert := ReregistrationAlert{
+		OperatorId:      fmt.Sprintf("%x", o.operatorId[:]),
+		OperatorAddress: o.operatorAddr.Hex(),
+		Time:            time.Now(),
+		Attempts:        attempts,
+		Reason:          err.Error(),
+	}
+	if err := webhook.Send(ctx, alert); err != nil {
+		o.logger.Error("Failed to send re-registration alert", "err", err)
+	}
+}
@@ -0,0 +1,271 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/metrics"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const (
+	// responses older than the task challenge window (100 blocks of 12s) can't be aggregated anymore
+	defaultResponseMaxAge         = 100 * 12 * time.Second
+	defaultResponseInitialBackoff = time.Second
+	defaultResponseMaxBackoff     = time.Minute
+	// how often Flush checks whether the queue was emptied
+	responseQueueFlushPollInterval = 250 * time.Millisecond
+)
+
+var (
+	responseKeyPrefix = []byte("response/")
+	// first key after all the response keys
+	responseKeyEnd = []byte("response0")
+)
+
+// responseSender makes a single attempt at delivering a signed response to the aggregator.
+type responseSender interface {
+	TrySendSignedOracleResponse(signedOracleResponse *aggregator.SignedOracleResponse) error
+}
+
+// queuedResponse is a signed response waiting to be delivered, as stored in the queue database.
+type queuedResponse struct {
+	Response    aggregator.SignedOracleResponse
+	Deadline    time.Time
+	Attempts    int
+	NextAttempt time.Time
+}
+
+// ResponseQueue persists the signed responses in a pebble database until the aggregator accepted them, so responses
+// signed while the aggregator is unreachable (or across operator restarts) are delivered once it comes back.
+// Failed deliveries are retried with exponential backoff until the response deadline, after which the aggregator
+// would reject the response anyway. Responses rejected by the aggregator (400 errors) are not retried.
+type ResponseQueue struct {
+	db             *pebble.DB
+	sender         responseSender
+	metrics        metrics.Metrics
+	logger         logging.Logger
+	maxAge         time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	// wakes up the delivery loop when a response is queued
+	wakeup chan struct{}
+}
+
+var _ AggregatorRpcClienter = (*ResponseQueue)(nil)
+
+func NewResponseQueue(cfg avstypes.ResponseQueueConfig, sender responseSender, metrics metrics.Metrics, logger logging.Logger) (*ResponseQueue, error) {
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = defaultResponseMaxAge
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = defaultResponseInitialBackoff
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = defaultResponseMaxBackoff
+	}
+	if cfg.MaxAge < 0 || cfg.InitialBackoff < 0 || cfg.MaxBackoff < cfg.InitialBackoff {
+		return nil, errors.New("invalid response queue config, durations must be positive and max_backoff at least initial_backoff")
+	}
+	db, err := pebble.Open(cfg.Dir, &pebble.Options{Logger: &pebbleNoopLogger{}})
+	if err != nil {
+		return nil, fmt.Errorf("could not open response queue database (path: %s): %w", cfg.Dir, err)
+	}
+	return &ResponseQueue{
+		db:             db,
+		sender:         sender,
+		metrics:        metrics,
+		logger:         logger,
+		maxAge:         cfg.MaxAge,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+		wakeup:         make(chan struct{}, 1),
+	}, nil
+}
+
+// SendSignedOracleResponseToAggregator queues the signed response; it is delivered by the queue delivery loop.
+func (q *ResponseQueue) SendSignedOracleResponseToAggregator(signedOracleResponse *aggregator.SignedOracleResponse) {
+	deadline := time.Unix(int64(signedOracleResponse.PriceResponse.Timestamp), 0).Add(q.maxAge)
+	entry := &queuedResponse{Response: *signedOracleResponse, Deadline: deadline, NextAttempt: time.Now()}
+	if err := q.put(newResponseKey(), entry); err != nil {
+		q.logger.Error("Failed to queue signed oracle response, dropping it", "err", err)
+		q.metrics.IncNumTaskResponseSubmissionFailures()
+		return
+	}
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers the queued responses until ctx is cancelled, then closes the queue database.
+func (q *ResponseQueue) Run(ctx context.Context) {
+	defer func() {
+		if err := q.db.Close(); err != nil {
+			q.logger.Error("Failed to close response queue database", "err", err)
+		}
+	}()
+	for {
+		next, err := q.deliverDue(time.Now())
+		if err != nil {
+			q.logger.Error("Failed to deliver queued responses", "err", err)
+			next = time.Now().Add(q.initialBackoff)
+		}
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			timerC = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wakeup:
+		case <-timerC:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Flush waits until the delivery loop emptied the queue, or ctx is cancelled. The loop must be running.
+func (q *ResponseQueue) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(responseQueueFlushPollInterval)
+	defer ticker.Stop()
+	for {
+		empty, err := q.empty()
+		if err != nil {
+			return err
+		}
+		if empty {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *ResponseQueue) empty() (bool, error) {
+	iter, err := q.db.NewIter(&pebble.IterOptions{
+		LowerBound: responseKeyPrefix,
+		UpperBound: responseKeyEnd,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+	return !iter.First(), iter.Error()
+}
+
+// deliverDue attempts to deliver the responses which are due, in the order they were queued, and returns when the next
+// attempt is due (zero when the queue is empty).
+func (q *ResponseQueue) deliverDue(now time.Time) (time.Time, error) {
+	iter, err := q.db.NewIter(&pebble.IterOptions{
+		LowerBound: responseKeyPrefix,
+		UpperBound: responseKeyEnd,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer iter.Close()
+
+	var next time.Time
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := bytes.Clone(iter.Key())
+		var entry queuedResponse
+		if err := gob.NewDecoder(bytes.NewReader(iter.Value())).Decode(&entry); err != nil {
+			q.logger.Error("Dropping undecodable queued response", "err", err)
+			if err := q.db.Delete(key, pebble.Sync); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		if now.After(entry.Deadline) {
+			q.logger.Error("Dropping queued response, its deadline passed before the aggregator accepted it",
+				"symbol", entry.Response.PriceResponse.Symbol, "attempts", entry.Attempts, "deadline", entry.Deadline)
+			q.metrics.IncNumTaskResponseSubmissionFailures()
+			if err := q.db.Delete(key, pebble.Sync); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		if entry.NextAttempt.After(now) {
+			next = earliest(next, entry.NextAttempt)
+			continue
+		}
+
+		entry.Attempts++
+		err := q.sender.TrySendSignedOracleResponse(&entry.Response)
+		if err == nil {
+			q.logger.Info("Signed oracle response accepted by aggregator", "symbol", entry.Response.PriceResponse.Symbol, "attempts", entry.Attempts)
+			if err := q.db.Delete(key, pebble.Sync); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		if aggregator.IsRejection(err) {
+			q.logger.Error("Signed oracle response rejected by aggregator", "symbol", entry.Response.PriceResponse.Symbol, "err", err)
+			q.metrics.IncNumTaskResponseSubmissionFailures()
+			if err := q.db.Delete(key, pebble.Sync); err != nil {
+				return time.Time{}, err
+			}
+			continue
+		}
+		entry.NextAttempt = now.Add(q.backoff(entry.Attempts))
+		q.logger.Warn("Could not deliver signed oracle response to aggregator, retrying",
+			"symbol", entry.Response.PriceResponse.Symbol, "attempts", entry.Attempts, "nextAttempt", entry.NextAttempt, "err", err)
+		if err := q.put(key, &entry); err != nil {
+			return time.Time{}, err
+		}
+		next = earliest(next, entry.NextAttempt)
+	}
+	return next, iter.Error()
+}
+
+// backoff returns the delay before the next attempt, doubling from the initial backoff up to the max backoff.
+func (q *ResponseQueue) backoff(attempts int) time.Duration {
+	delay := q.initialBackoff
+	for i := 1; i < attempts && delay < q.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, q.maxBackoff)
+}
+
+func (q *ResponseQueue) put(key []byte, entry *queuedResponse) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+	return q.db.Set(key, buf.Bytes(), pebble.Sync)
+}
+
+// newResponseKey returns a key ordering the responses by the time they were queued.
+func newResponseKey() []byte {
+	key := make([]byte, len(responseKeyPrefix)+12)
+	copy(key, responseKeyPrefix)
+	binary.BigEndian.PutUint64(key[len(responseKeyPrefix):], uint64(time.Now().UnixNano()))
+	rand.Read(key[len(responseKeyPrefix)+8:])
+	return key
+}
+
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
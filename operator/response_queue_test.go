@@ -0,0 +1,115 @@
+package operator
+
+import (
+	"errors"
+	"net/rpc"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	csavs "github.com/zees-dev/blockless-avs/contracts/bindings/BlocklessAVS"
+	"github.com/zees-dev/blockless-avs/metrics"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+// fakeSender returns the err of the current step for every delivery attempt, counting them.
+type fakeSender struct {
+	err      error
+	attempts int
+}
+
+func (s *fakeSender) TrySendSignedOracleResponse(signedOracleResponse *aggregator.SignedOracleResponse) error {
+	s.attempts++
+	return s.err
+}
+
+func TestResponseQueueDelivery(t *testing.T) {
+	errUnreachable := errors.New("connection refused")
+	type step struct {
+		// time of the delivery, relative to the response timestamp
+		at  time.Duration
+		err error
+		// whether a delivery was attempted
+		sent   bool
+		queued bool
+		// when the next attempt is due, relative to the response timestamp; zero when the queue is empty
+		next time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"retried with backoff until accepted", []step{
+			{at: time.Second, err: errUnreachable, sent: true, queued: true, next: 2 * time.Second},
+			// not due before the backoff
+			{at: 1500 * time.Millisecond, err: errUnreachable, sent: false, queued: true, next: 2 * time.Second},
+			{at: 2 * time.Second, err: errUnreachable, sent: true, queued: true, next: 4 * time.Second},
+			{at: 4 * time.Second, err: errUnreachable, sent: true, queued: true, next: 8 * time.Second},
+			// the backoff is capped to the max backoff
+			{at: 8 * time.Second, err: errUnreachable, sent: true, queued: true, next: 12 * time.Second},
+			{at: 12 * time.Second, err: nil, sent: true, queued: false},
+		}},
+		{"dropped after the deadline", []step{
+			{at: time.Second, err: errUnreachable, sent: true, queued: true, next: 2 * time.Second},
+			{at: 21 * time.Second, err: nil, sent: false, queued: false},
+		}},
+		{"rejected by the aggregator", []step{
+			{at: time.Second, err: rpc.ServerError(aggregator.TaskExpired400.Error()), sent: true, queued: false},
+		}},
+		{"retried while the aggregator is unavailable", []step{
+			{at: time.Second, err: rpc.ServerError(aggregator.ShuttingDown503.Error()), sent: true, queued: true, next: 2 * time.Second},
+			{at: 2 * time.Second, err: nil, sent: true, queued: false},
+		}},
+	}
+
+	for _, test := range tests {
+		sender := &fakeSender{}
+		cfg := avstypes.ResponseQueueConfig{
+			Dir:            filepath.Join(t.TempDir(), "responses"),
+			MaxAge:         20 * time.Second,
+			InitialBackoff: time.Second,
+			MaxBackoff:     4 * time.Second,
+		}
+		q, err := NewResponseQueue(cfg, sender, metrics.NewNoopMetrics(), logging.NewNoopLogger())
+		if err != nil {
+			t.Fatalf("%s: Failed to create response queue: %v", test.name, err)
+		}
+		// the first attempt is due when queued, within the second of the response timestamp
+		timestamp := time.Unix(time.Now().Unix(), 0)
+		q.SendSignedOracleResponseToAggregator(&aggregator.SignedOracleResponse{
+			PriceResponse: csavs.IBlocklessAVSPrice{Symbol: "eth", Timestamp: uint32(timestamp.Unix())},
+		})
+
+		for i, step := range test.steps {
+			sender.err = step.err
+			attempts := sender.attempts
+			next, err := q.deliverDue(timestamp.Add(step.at))
+			if err != nil {
+				t.Fatalf("%s: step %d: Failed to deliver responses: %v", test.name, i, err)
+			}
+			if sent := sender.attempts > attempts; sent != step.sent {
+				t.Errorf("%s: step %d: Expected delivery attempt: %v, got: %v", test.name, i, step.sent, sent)
+			}
+			empty, err := q.empty()
+			if err != nil {
+				t.Fatalf("%s: step %d: Failed to check response queue: %v", test.name, i, err)
+			}
+			if empty == step.queued {
+				t.Errorf("%s: step %d: Expected queued: %v, got: %v", test.name, i, step.queued, !empty)
+			}
+			expectedNext := time.Time{}
+			if step.next != 0 {
+				expectedNext = timestamp.Add(step.next)
+			}
+			if !next.Equal(expectedNext) {
+				t.Errorf("%s: step %d: Expected next attempt: %v, got: %v", test.name, i, expectedNext, next)
+			}
+		}
+		if err := q.db.Close(); err != nil {
+			t.Fatalf("%s: Failed to close response queue: %v", test.name, err)
+		}
+	}
+}
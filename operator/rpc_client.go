@@ -1,8 +1,10 @@
 package operator
 
 import (
+	"errors"
 	"fmt"
 	"net/rpc"
+	"sync"
 	"time"
 
 	"github.com/zees-dev/blockless-avs/aggregator"
@@ -16,6 +18,8 @@ type AggregatorRpcClienter interface {
 	SendSignedOracleResponseToAggregator(signedOracleResponse *aggregator.SignedOracleResponse)
 }
 type AggregatorRpcClient struct {
+	// guards rpcClient in TrySendSignedOracleResponse, which may be called concurrently
+	mu                   sync.Mutex
 	rpcClient            *rpc.Client
 	metrics              metrics.Metrics
 	logger               logging.Logger
@@ -52,6 +56,7 @@ func (c *AggregatorRpcClient) SendSignedOracleResponseToAggregator(signedOracleR
 		err := c.dialAggregatorRpcClient()
 		if err != nil {
 			c.logger.Error("Could not dial aggregator rpc client. Not sending signed oracle response header to aggregator. Is aggregator running?", "err", err)
+			c.metrics.IncNumTaskResponseSubmissionFailures()
 			return
 		}
 	}
@@ -75,4 +80,52 @@ func (c *AggregatorRpcClient) SendSignedOracleResponseToAggregator(signedOracleR
 		time.Sleep(2 * time.Second)
 	}
 	c.logger.Errorf("Could not send signed oracle response to aggregator. Tried 5 times.")
+	c.metrics.IncNumTaskResponseSubmissionFailures()
+}
+
+// TrySendSignedOracleResponse makes a single attempt at sending the signed oracle response to the aggregator,
+// dialing it first if needed. Errors returned by the aggregator are rpc.ServerErrors; on any other error the
+// connection is dropped, so the next attempt redials the aggregator.
+func (c *AggregatorRpcClient) TrySendSignedOracleResponse(signedOracleResponse *aggregator.SignedOracleResponse) error {
+	return c.call("Aggregator.ProcessSignedOracleResponse", signedOracleResponse)
+}
+
+// SendHeartbeat makes a single attempt at sending the signed heartbeat to the aggregator, like TrySendSignedOracleResponse.
+func (c *AggregatorRpcClient) SendHeartbeat(heartbeat *aggregator.Heartbeat) error {
+	return c.call("Aggregator.ProcessHeartbeat", heartbeat)
+}
+
+func (c *AggregatorRpcClient) call(method string, args any) error {
+	c.mu.Lock()
+	if c.rpcClient == nil {
+		if err := c.dialAggregatorRpcClient(); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	client := c.rpcClient
+	c.mu.Unlock()
+
+	var reply bool
+	err := client.Call(method, args, &reply)
+	var serverErr rpc.ServerError
+	if err != nil && !errors.As(err, &serverErr) {
+		c.mu.Lock()
+		if c.rpcClient == client {
+			c.rpcClient.Close()
+			c.rpcClient = nil
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// close drops the connection to the aggregator; the next attempt redials it.
+func (c *AggregatorRpcClient) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rpcClient != nil {
+		c.rpcClient.Close()
+		c.rpcClient = nil
+	}
 }
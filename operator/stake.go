@@ -0,0 +1,156 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	eigenSdkTypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DepositPlan is a deposit of the operator tokens into an eigenlayer strategy, with the balances it's based on, to be
+// confirmed before calling Deposit.
+type DepositPlan struct {
+	Operator common.Address `json:"operator"`
+	Strategy common.Address `json:"strategy"`
+	// underlying token of the strategy
+	Token common.Address `json:"token"`
+	// amount of tokens deposited, in the token base unit
+	Amount *big.Int `json:"amount"`
+	// whether the tokens are minted before depositing; only the mock token of local deployments can be minted
+	Mint           bool     `json:"mint"`
+	TokenBalance   *big.Int `json:"tokenBalance"`
+	OperatorShares *big.Int `json:"operatorShares"`
+}
+
+// DepositReport is the result of a deposit.
+type DepositReport struct {
+	DepositPlan
+	MintTxHash          *common.Hash `json:"mintTxHash,omitempty"`
+	DepositTxHash       common.Hash  `json:"depositTxHash"`
+	OperatorSharesAfter *big.Int     `json:"operatorSharesAfter"`
+}
+
+// DelegationReport is the result of delegating the operator to itself.
+type DelegationReport struct {
+	Operator common.Address `json:"operator"`
+	// true when the operator was registered with eigenlayer, hence delegated to itself, before
+	AlreadyDelegated bool `json:"alreadyDelegated"`
+	// metadata uri registered along, not set when the operator was already registered
+	MetadataURI string       `json:"metadataUri,omitempty"`
+	TxHash      *common.Hash `json:"txHash,omitempty"`
+}
+
+// PlanDeposit checks that the operator can deposit amount tokens into the strategy, minting them first when mint is
+// set, and returns the deposit to confirm.
+func (o *Operator) PlanDeposit(ctx context.Context, strategyAddr common.Address, amount *big.Int, mint bool) (*DepositPlan, error) {
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("deposit amount must be positive")
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	_, token, tokenAddr, err := o.eigenlayerReader.GetStrategyAndUnderlyingERC20Token(opts, strategyAddr)
+	if err != nil {
+		o.logger.Error("Failed to fetch strategy contract", "err", err)
+		return nil, err
+	}
+	balance, err := token.BalanceOf(opts, o.operatorAddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the token balance of the operator: %w", err)
+	}
+	if !mint && balance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("operator token balance %s is lower than the deposit amount %s", balance, amount)
+	}
+	shares, err := o.eigenlayerReader.GetOperatorSharesInStrategy(opts, o.operatorAddr, strategyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the operator shares in the strategy: %w", err)
+	}
+	return &DepositPlan{
+		Operator:       o.operatorAddr,
+		Strategy:       strategyAddr,
+		Token:          tokenAddr,
+		Amount:         amount,
+		Mint:           mint,
+		TokenBalance:   balance,
+		OperatorShares: shares,
+	}, nil
+}
+
+// Deposit mints the tokens if planned, then deposits them into the strategy.
+func (o *Operator) Deposit(ctx context.Context, plan *DepositPlan) (*DepositReport, error) {
+	report := &DepositReport{DepositPlan: *plan}
+	if plan.Mint {
+		contractErc20Mock, err := o.avsReader.GetErc20Mock(ctx, plan.Token)
+		if err != nil {
+			return nil, err
+		}
+		txOpts, err := o.avsWriter.TxMgr.GetNoSendTxOpts()
+		if err != nil {
+			return nil, err
+		}
+		tx, err := contractErc20Mock.Mint(txOpts, o.operatorAddr, plan.Amount)
+		if err != nil {
+			o.logger.Error("Error assembling Mint tx", "err", err)
+			return nil, err
+		}
+		receipt, err := o.avsWriter.TxMgr.Send(ctx, tx)
+		if err != nil {
+			o.logger.Error("Error submitting Mint tx", "err", err)
+			return nil, err
+		}
+		report.MintTxHash = &receipt.TxHash
+	}
+
+	receipt, err := o.eigenlayerWriter.DepositERC20IntoStrategy(ctx, plan.Strategy, plan.Amount)
+	if err != nil {
+		o.logger.Error("Error depositing into strategy", "err", err)
+		return nil, err
+	}
+	report.DepositTxHash = receipt.TxHash
+	report.OperatorSharesAfter, err = o.eigenlayerReader.GetOperatorSharesInStrategy(&bind.CallOpts{Context: ctx}, o.operatorAddr, plan.Strategy)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the operator shares in the strategy: %w", err)
+	}
+	return report, nil
+}
+
+// DelegateToSelf registers the operator with eigenlayer, which delegates its own stake to itself, unless it's already
+// registered. The metadata uri of the config is registered along.
+func (o *Operator) DelegateToSelf(ctx context.Context) (*DelegationReport, error) {
+	return o.RegisterWithEigenlayer(ctx, o.config.MetadataURI)
+}
+
+// RegisterWithEigenlayer registers the operator with the eigenlayer DelegationManager (registerAsOperator) with the
+// metadata uri, unless it's already registered. The metadata is fetched and validated first, like UpdateMetadataURI
+// does; an empty uri registers the operator without a public profile.
+func (o *Operator) RegisterWithEigenlayer(ctx context.Context, metadataURI string) (*DelegationReport, error) {
+	op := eigenSdkTypes.Operator{
+		Address:                 o.operatorAddr.String(),
+		EarningsReceiverAddress: o.operatorAddr.String(),
+		MetadataUrl:             metadataURI,
+	}
+	report := &DelegationReport{Operator: o.operatorAddr}
+	registered, err := o.eigenlayerReader.IsOperatorRegistered(&bind.CallOpts{Context: ctx}, op)
+	if err != nil {
+		o.logger.Error("Unable to check if operator is registered with eigenlayer", "err", err)
+		return nil, err
+	}
+	if registered {
+		report.AlreadyDelegated = true
+		return report, nil
+	}
+	if metadataURI != "" {
+		if _, err := fetchOperatorMetadata(ctx, metadataURI); err != nil {
+			return nil, fmt.Errorf("invalid operator metadata at %s: %w", metadataURI, err)
+		}
+	}
+	receipt, err := o.eigenlayerWriter.RegisterAsOperator(ctx, op)
+	if err != nil {
+		o.logger.Error("Error registering operator with eigenlayer", "err", err)
+		return nil, err
+	}
+	report.MetadataURI = metadataURI
+	report.TxHash = &receipt.TxHash
+	return report, nil
+}
@@ -0,0 +1,107 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	avstypes "github.com/zees-dev/blockless-avs/types"
+)
+
+const (
+	defaultLeaseDuration     = 30 * time.Second
+	defaultHeartbeatInterval = 10 * time.Second
+	// signing stops this long before the lease expires, to tolerate clock drift between instances
+	leaseSafetyMargin = 2 * time.Second
+)
+
+var errSigningLeaseLost = errors.New("signing lease lost while processing the task")
+
+// standby only lets the operator sign while it holds the signing lease.
+type standby struct {
+	cfg   avstypes.StandbyConfig
+	lease SigningLease
+
+	mu        sync.RWMutex
+	heldUntil time.Time
+}
+
+func newStandby(cfg avstypes.StandbyConfig) (*standby, error) {
+	if cfg.LeaseFile == "" {
+		return nil, fmt.Errorf("standby is enabled but no lease file is configured")
+	}
+	if cfg.InstanceId == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("could not determine standby instance id: %w", err)
+		}
+		cfg.InstanceId = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = defaultLeaseDuration
+	}
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if cfg.LeaseDuration < 2*cfg.HeartbeatInterval+leaseSafetyMargin {
+		return nil, fmt.Errorf("standby lease duration %s must be at least twice the heartbeat interval %s plus %s", cfg.LeaseDuration, cfg.HeartbeatInterval, leaseSafetyMargin)
+	}
+	return &standby{
+		cfg:   cfg,
+		lease: newFileLease(cfg.LeaseFile, cfg.InstanceId, cfg.LeaseDuration),
+	}, nil
+}
+
+// canSign reports whether this instance holds the signing lease.
+func (s *standby) canSign() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return time.Now().Before(s.heldUntil.Add(-leaseSafetyMargin))
+}
+
+// runStandby renews or tries to acquire the signing lease on every heartbeat, and releases it when ctx is done.
+func (o *Operator) runStandby(ctx context.Context) {
+	o.logger.Info("Running as standby candidate", "instanceId", o.standby.cfg.InstanceId, "leaseFile", o.standby.cfg.LeaseFile)
+	ticker := time.NewTicker(o.standby.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		o.heartbeat(ctx)
+		select {
+		case <-ctx.Done():
+			o.standby.mu.Lock()
+			o.standby.heldUntil = time.Time{}
+			o.standby.mu.Unlock()
+			if err := o.standby.lease.Release(); err != nil {
+				o.logger.Error("Failed to release signing lease", "err", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Operator) heartbeat(ctx context.Context) {
+	wasSigning := o.standby.canSign()
+	held, expiresAt, err := o.standby.lease.Acquire(ctx)
+	if err != nil {
+		// we keep signing until the lease we hold expires, the standbys won't take over before that
+		o.logger.Error("Failed to renew signing lease", "err", err)
+		return
+	}
+	o.standby.mu.Lock()
+	if held {
+		o.standby.heldUntil = expiresAt
+	} else {
+		o.standby.heldUntil = time.Time{}
+	}
+	o.standby.mu.Unlock()
+
+	if held && !wasSigning {
+		o.logger.Info("Acquired signing lease, this instance is now signing", "instanceId", o.standby.cfg.InstanceId)
+	} else if !held && wasSigning {
+		o.logger.Warn("Lost signing lease, this instance is now standing by", "instanceId", o.standby.cfg.InstanceId)
+	}
+}
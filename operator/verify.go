@@ -0,0 +1,126 @@
+package operator
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/zees-dev/blockless-avs/aggregator/types"
+	"github.com/zees-dev/blockless-avs/core"
+	"github.com/zees-dev/blockless-avs/core/chainio"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdktypes "github.com/Layr-Labs/eigensdk-go/types"
+)
+
+// AttestationReport is the result of an operator auditing one of its past attestations against what was accepted onchain.
+type AttestationReport struct {
+	TaskIndex            types.TaskIndex
+	TxHash               string
+	BlockNumber          uint64
+	ReferenceBlockNumber uint32
+	Symbol               string
+	Price                string
+	Timestamp            uint32
+	Digest               string
+	OperatorId           string
+	// operator was registered in the task quorums at the reference block
+	InQuorum bool
+	// operator signature was included in the aggregated signature (ie. operator is not a non-signer)
+	Signed bool
+	// aggregated signature verifies against the locally recomputed digest
+	SignatureValid bool
+	Mismatches     []string
+}
+
+// VerifyAttestation fetches the onchain aggregated response for the given task, checks whether this operator's signature
+// was included, recomputes the expected digest locally and verifies the aggregated signature over it.
+// Any inconsistency is reported in AttestationReport.Mismatches.
+func (o *Operator) VerifyAttestation(ctx context.Context, taskIndex types.TaskIndex) (*AttestationReport, error) {
+	resp, err := o.avsReader.GetAggregatedOracleResponse(ctx, taskIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AttestationReport{
+		TaskIndex:            taskIndex,
+		TxHash:               resp.Event.Raw.TxHash.Hex(),
+		BlockNumber:          resp.Event.Raw.BlockNumber,
+		ReferenceBlockNumber: resp.OracleRequest.ReferenceBlockNumber,
+		Symbol:               resp.Price.Symbol,
+		Price:                resp.Price.Price.String(),
+		Timestamp:            resp.Price.Timestamp,
+		OperatorId:           hex.EncodeToString(o.operatorId[:]),
+	}
+
+	digest, err := core.GetPriceDigest(&resp.Price)
+	if err != nil {
+		return nil, err
+	}
+	report.Digest = hex.EncodeToString(digest[:])
+
+	eventDigest, err := core.GetPriceDigest(&resp.Event.PriceResponse)
+	if err != nil {
+		return nil, err
+	}
+	if eventDigest != digest {
+		report.Mismatches = append(report.Mismatches, fmt.Sprintf("emitted price digest %x differs from submitted price digest %x", eventDigest, digest))
+	}
+
+	report.InQuorum, err = o.inQuorumsAtBlock(ctx, resp.OracleRequest.QuorumNumbers, resp.OracleRequest.ReferenceBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	nonSigner := o.isNonSigner(resp)
+	report.Signed = report.InQuorum && !nonSigner
+	if !report.InQuorum {
+		report.Mismatches = append(report.Mismatches, "operator was not registered in the task quorums at the reference block")
+	} else if nonSigner {
+		report.Mismatches = append(report.Mismatches, "operator signature was not included in the aggregated signature")
+	}
+
+	sigma := &bls.Signature{G1Point: core.ConvertFromBN254G1Point(resp.NonSignerStakesAndSignature.Sigma)}
+	apkG2 := core.ConvertFromBN254G2Point(resp.NonSignerStakesAndSignature.ApkG2)
+	report.SignatureValid, err = sigma.Verify(apkG2, digest)
+	if err != nil {
+		return nil, err
+	}
+	if !report.SignatureValid {
+		report.Mismatches = append(report.Mismatches, "aggregated signature does not verify against the locally recomputed digest")
+	}
+
+	return report, nil
+}
+
+// inQuorumsAtBlock reports whether the operator was registered in any of the given quorums at the given block.
+func (o *Operator) inQuorumsAtBlock(ctx context.Context, quorumNumbers []byte, blockNumber uint32) (bool, error) {
+	quorumNums := make(sdktypes.QuorumNums, len(quorumNumbers))
+	for i, quorumNum := range quorumNumbers {
+		quorumNums[i] = sdktypes.QuorumNum(quorumNum)
+	}
+	operatorsPerQuorum, err := o.avsReader.GetOperatorsStakeInQuorumsAtBlock(&bind.CallOpts{Context: ctx}, quorumNums, blockNumber)
+	if err != nil {
+		o.logger.Error("Failed to get operators in quorums at reference block", "err", err)
+		return false, err
+	}
+	for _, operators := range operatorsPerQuorum {
+		for _, operator := range operators {
+			if operator.OperatorId == o.operatorId {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// isNonSigner reports whether the operator is one of the non-signers of the aggregated response.
+func (o *Operator) isNonSigner(resp *chainio.AggregatedOracleResponse) bool {
+	for _, pubkey := range resp.NonSignerStakesAndSignature.NonSignerPubkeys {
+		if sdktypes.OperatorIdFromG1Pubkey(core.ConvertFromBN254G1Point(pubkey)) == o.operatorId {
+			return true
+		}
+	}
+	return false
+}
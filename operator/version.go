@@ -0,0 +1,50 @@
+package operator
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/zees-dev/blockless-avs/aggregator"
+	"github.com/zees-dev/blockless-avs/core/version"
+)
+
+const (
+	b7sModulePath = "github.com/blocklessnetwork/b7s"
+	// name of the blockless runtime executable, as run by the b7s executor
+	runtimeExecutableName = "bls-runtime"
+	runtimeVersionTimeout = 5 * time.Second
+)
+
+// softwareVersion returns the version attested alongside the signed responses: the operator version and the vcs
+// revision and b7s version it was built with, and the version of the blockless runtime in runtimeDir, if any.
+func softwareVersion(runtimeDir string) aggregator.SoftwareVersion {
+	build := version.Get()
+	softwareVersion := aggregator.SoftwareVersion{Version: build.Version, Commit: build.Commit}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		softwareVersion.B7sVersion = version.DependencyVersion(info, b7sModulePath)
+	}
+	if runtimeDir != "" {
+		softwareVersion.RuntimeVersion = runtimeVersion(filepath.Join(runtimeDir, runtimeExecutableName))
+	}
+	return softwareVersion
+}
+
+// runtimeVersion returns the version printed by the blockless runtime, or an empty string when it can't be run.
+func runtimeVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	// eg. "blockless-cli 0.3.1"
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
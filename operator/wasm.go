@@ -0,0 +1,197 @@
+package operator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blocklessnetwork/b7s/executor"
+	"github.com/blocklessnetwork/b7s/fstore"
+	"github.com/blocklessnetwork/b7s/models/execute"
+	"github.com/blocklessnetwork/b7s/store"
+	"github.com/cockroachdb/pebble"
+	"github.com/rs/zerolog"
+
+	corelogging "github.com/zees-dev/blockless-avs/core/logging"
+	avstypes "github.com/zees-dev/blockless-avs/types"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const defaultWasmTimeout = 30 * time.Second
+
+var (
+	// ErrWasmDisabled is returned for tasks referencing a wasm function when no blockless runtime is configured.
+	ErrWasmDisabled = errors.New("wasm execution is disabled, no blockless runtime configured")
+
+	// cids and methods are used as paths in the workspace, so they must not contain separators or dot segments
+	cidRegex    = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+	methodRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+)
+
+// OracleTask is an oracle update request. The price is computed by the wasm function when the task references one,
+// and fetched from coingecko otherwise.
+type OracleTask struct {
+	Symbol   string
+	Function *WasmFunction
+	// index of the aggregator task the request answers, if known; only used by the operator policy
+	TaskIndex *uint32
+	// quorums of the aggregator task the request answers; all the avs quorums when not set
+	QuorumNumbers []uint8
+}
+
+// input returns the json encoding of what the task computes, ie. its symbol and function.
+func (t *OracleTask) input() ([]byte, error) {
+	return json.Marshal(OracleTask{Symbol: t.Symbol, Function: t.Function})
+}
+
+// WasmFunction references a Blockless WASM function computing the price of a task. The function must print the usd
+// price (eg. 63123.45) to stdout, as the avs contract only accepts price responses.
+type WasmFunction struct {
+	// cid of the function archive
+	Cid string `json:"cid" validate:"required,max=128"`
+	// url of the function manifest the archive is installed from
+	ManifestUrl string `json:"manifestUrl" validate:"required,max=2048"`
+	// wasm file of the function to execute
+	Method string `json:"method" validate:"required,max=256"`
+	// arguments passed to the function
+	Parameters []string `json:"parameters" validate:"max=32"`
+}
+
+// Validate checks that the cid and method can't escape the function workspace.
+func (f *WasmFunction) Validate() error {
+	if !cidRegex.MatchString(f.Cid) {
+		return fmt.Errorf("invalid function cid %q", f.Cid)
+	}
+	if !methodRegex.MatchString(f.Method) {
+		return fmt.Errorf("invalid function method %q", f.Method)
+	}
+	return nil
+}
+
+// wasmRunner installs and executes wasm functions with the local blockless runtime, the same way a b7s worker does.
+type wasmRunner struct {
+	fdb      *pebble.DB
+	fstore   *fstore.FStore
+	executor *executor.Executor
+	timeout  time.Duration
+	// serializes installs, so concurrent tasks don't download the same function twice
+	installMu sync.Mutex
+}
+
+func newWasmRunner(cfg avstypes.WasmConfig, log zerolog.Logger) (*wasmRunner, error) {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultWasmTimeout
+	}
+	workspace, err := filepath.Abs(cfg.Workspace)
+	if err != nil {
+		return nil, fmt.Errorf("could not determine absolute path for wasm workspace (path: %s): %w", cfg.Workspace, err)
+	}
+	if err := os.MkdirAll(workspace, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create wasm workspace: %w", err)
+	}
+	exec, err := executor.New(log, executor.WithWorkDir(workspace), executor.WithRuntimeDir(cfg.RuntimeDir))
+	if err != nil {
+		return nil, fmt.Errorf("could not create wasm executor: %w", err)
+	}
+	fdb, err := pebble.Open(filepath.Join(workspace, "function-db"), &pebble.Options{Logger: &pebbleNoopLogger{}})
+	if err != nil {
+		return nil, fmt.Errorf("could not open wasm function database: %w", err)
+	}
+	return &wasmRunner{
+		fdb:      fdb,
+		fstore:   fstore.New(log, store.New(fdb), workspace),
+		executor: exec,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+// run installs the function if needed, executes it and parses the usd price it printed.
+func (w *wasmRunner) run(fn *WasmFunction, exec *execution) (float64, error) {
+	exec.setFunction(fn.Cid)
+	if err := fn.Validate(); err != nil {
+		return 0, err
+	}
+	if err := w.install(fn, exec); err != nil {
+		return 0, err
+	}
+
+	params := make([]execute.Parameter, len(fn.Parameters))
+	for i, value := range fn.Parameters {
+		params[i] = execute.Parameter{Value: value}
+	}
+	req := execute.Request{
+		FunctionID: fn.Cid,
+		Method:     fn.Method,
+		Parameters: params,
+		Config: execute.Config{
+			Runtime: execute.BLSRuntimeConfig{ExecutionTime: uint64(w.timeout.Milliseconds())},
+		},
+	}
+	// the request id names the execution working directory, which is removed once the function returns
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	requestId := fmt.Sprintf("%d-%s", time.Now().UnixMilli(), hex.EncodeToString(suffix))
+	exec.logf("executing function %s method %s (request %s)", fn.Cid, fn.Method, requestId)
+	res, err := w.executor.ExecuteFunction(requestId, req)
+	if output, merr := json.Marshal(res); merr == nil {
+		exec.setOutput(output)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not execute function %s: %w", fn.Cid, err)
+	}
+	exec.logf("function exited with code %d (%d bytes of stdout)", res.Result.ExitCode, len(res.Result.Stdout))
+
+	price, err := strconv.ParseFloat(strings.TrimSpace(res.Result.Stdout), 64)
+	if err != nil {
+		return 0, fmt.Errorf("function %s did not output a price: %w", fn.Cid, err)
+	}
+	if price < 0 {
+		return 0, fmt.Errorf("function %s output a negative price %v", fn.Cid, price)
+	}
+	exec.logf("parsed price %v usd", price)
+	return price, nil
+}
+
+func (w *wasmRunner) install(fn *WasmFunction, exec *execution) error {
+	w.installMu.Lock()
+	defer w.installMu.Unlock()
+	installed, err := w.fstore.Installed(fn.Cid)
+	if err != nil {
+		return fmt.Errorf("could not check if function %s is installed: %w", fn.Cid, err)
+	}
+	if installed {
+		return nil
+	}
+	exec.logf("installing function %s from %s", fn.Cid, fn.ManifestUrl)
+	if err := w.fstore.Install(fn.ManifestUrl, fn.Cid); err != nil {
+		return fmt.Errorf("could not install function %s: %w", fn.Cid, err)
+	}
+	return nil
+}
+
+func (w *wasmRunner) close() error {
+	return w.fdb.Close()
+}
+
+// zerologLogger returns the zerolog logger behind the operator logger, which the b7s components log with.
+func zerologLogger(logger logging.Logger) zerolog.Logger {
+	if zl, ok := logger.(*corelogging.ZeroLogger); ok {
+		return *zl.Inner()
+	}
+	return zerolog.Nop()
+}
+
+type pebbleNoopLogger struct{}
+
+func (pebbleNoopLogger) Infof(_ string, _ ...any)  {}
+func (pebbleNoopLogger) Fatalf(_ string, _ ...any) {}
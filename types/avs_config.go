@@ -1,6 +1,20 @@
 package types
 
+import (
+	"time"
+
+	"github.com/zees-dev/blockless-avs/core/blssigner"
+	"github.com/zees-dev/blockless-avs/core/clock"
+	"github.com/zees-dev/blockless-avs/core/config"
+	"github.com/zees-dev/blockless-avs/core/keystore"
+	"github.com/zees-dev/blockless-avs/core/logging"
+	"github.com/zees-dev/blockless-avs/core/notify"
+	"github.com/zees-dev/blockless-avs/core/remotesigner"
+)
+
 type NodeConfig struct {
+	// schema version of the config, see config.ConfigVersion
+	Version int `yaml:"version"`
 	// used to set the logger level (true = info, false = debug)
 	Production                    bool   `yaml:"production"`
 	OperatorAddress               string `yaml:"operator_address"`
@@ -10,12 +24,319 @@ type NodeConfig struct {
 	AVSServiceManagerAddress      string `yaml:"avs_service_manager_addr"`
 	EthRpcUrl                     string `yaml:"eth_rpc_url"`
 	EthWsUrl                      string `yaml:"eth_ws_url"`
-	BlsPrivateKeyStorePath        string `yaml:"bls_private_key_store_path"`
-	EcdsaPrivateKeyStorePath      string `yaml:"ecdsa_private_key_store_path"`
+	// chain the eth rpc node must be on, checked on startup so the operator never signs for another network; not
+	// checked when 0 (default: the chain of --network)
+	ChainId uint64 `yaml:"chain_id"`
+	// encrypted bls keystore; its password is read from bls_key_password_file, the OPERATOR_BLS_KEY_PASSWORD env var or
+	// the terminal
+	BlsPrivateKeyStorePath string `yaml:"bls_private_key_store_path"`
+	BlsKeyPasswordFile     string `yaml:"bls_key_password_file"`
+	// backend producing the bls signatures; the keystore at bls_private_key_store_path by default
+	BlsSigner blssigner.Config `yaml:"bls_signer"`
+	// encrypted ecdsa keystore; its password is read from ecdsa_key_password_file, the OPERATOR_ECDSA_KEY_PASSWORD env
+	// var or the terminal
+	EcdsaPrivateKeyStorePath string `yaml:"ecdsa_private_key_store_path"`
+	EcdsaKeyPasswordFile     string `yaml:"ecdsa_key_password_file"`
+	// signs transactions with a remote signer instead of the ecdsa keystore; disabled when no url is set
+	RemoteSigner remotesigner.Config `yaml:"remote_signer"`
+	// pricing of the transactions sent by the operator (registration, metadata updates)
+	Gas config.GasConfigRaw `yaml:"gas"`
+	// receipt timeouts and fee bumping of the transactions sent by the operator
+	TxMgr config.TxMgrConfig `yaml:"tx_manager"`
+	// primary aggregator the signed responses are submitted to
 	AggregatorServerIpPortAddress string `yaml:"aggregator_server_ip_port_address"`
-	RegisterOperatorOnStartup     bool   `yaml:"register_operator_on_startup"`
-	EigenMetricsIpPortAddress     string `yaml:"eigen_metrics_ip_port_address"`
-	EnableMetrics                 bool   `yaml:"enable_metrics"`
-	NodeApiIpPortAddress          string `yaml:"node_api_ip_port_address"`
-	EnableNodeApi                 bool   `yaml:"enable_node_api"`
+	// backup aggregators and how responses are submitted to them
+	Aggregators AggregatorsConfig `yaml:"aggregators"`
+	// public operator profile (eigenlayer operator metadata json) registered with the eigenlayer DelegationManager when
+	// registering the operator with eigenlayer
+	MetadataURI string `yaml:"metadata_uri"`
+	// socket registered with the avs registry coordinator when registering the operator
+	Socket                    string `yaml:"socket"`
+	RegisterOperatorOnStartup bool   `yaml:"register_operator_on_startup"`
+	EigenMetricsIpPortAddress string `yaml:"eigen_metrics_ip_port_address"`
+	EnableMetrics             bool   `yaml:"enable_metrics"`
+	NodeApiIpPortAddress      string `yaml:"node_api_ip_port_address"`
+	EnableNodeApi             bool   `yaml:"enable_node_api"`
+	// clock skew tolerance; signing is paused while the local clock is skewed
+	Clock clock.Config `yaml:"clock"`
+	// scheduled participation digests; disabled when no webhook url is set
+	Digest DigestConfig `yaml:"digest"`
+	// warm standby; only the instance holding the signing lease signs responses
+	Standby StandbyConfig `yaml:"standby"`
+	// artifacts of every oracle update execution, for debugging diverging response digests
+	Artifacts ArtifactsConfig `yaml:"artifacts"`
+	// executes tasks referencing a blockless wasm function on the local runtime
+	Wasm WasmConfig `yaml:"wasm"`
+	// persists signed responses until the aggregator accepted them
+	ResponseQueue ResponseQueueConfig `yaml:"response_queue"`
+	// which tasks the operator accepts to sign
+	Policy PolicyConfig `yaml:"policy"`
+	// quorums the operator participates in: tasks of other quorums are skipped and the operator is only registered in
+	// these quorums (default: all the avs quorums)
+	Quorums []uint8 `yaml:"quorums"`
+	// re-registers the operator after it was ejected from a quorum
+	Reregistration ReregistrationConfig `yaml:"reregistration"`
+	// writes the operator logs to a rotated file besides stderr
+	LogFile logging.FileConfig `yaml:"log_file"`
+	// how often a signed heartbeat is sent to the aggregators, so they report the operator liveness (default 30s)
+	AggregatorHeartbeatInterval time.Duration `yaml:"aggregator_heartbeat_interval"`
+	// how long a drain waits for the in-flight responses to be submitted before shutting down anyway (default 2m)
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// embedded b7s node; the node flags take precedence over it
+	B7s B7sConfig `yaml:"b7s"`
+}
+
+// EcdsaKeyPassword is where the password of the ecdsa keystore is read from.
+func (c NodeConfig) EcdsaKeyPassword() keystore.PasswordSource {
+	return keystore.PasswordSource{
+		File:   c.EcdsaKeyPasswordFile,
+		EnvVar: "OPERATOR_ECDSA_KEY_PASSWORD",
+		Prompt: "Operator ecdsa keystore password: ",
+	}
+}
+
+// BlsKeyPassword is where the password of the bls keystore is read from.
+func (c NodeConfig) BlsKeyPassword() keystore.PasswordSource {
+	return keystore.PasswordSource{
+		File:   c.BlsKeyPasswordFile,
+		EnvVar: "OPERATOR_BLS_KEY_PASSWORD",
+		Prompt: "Operator bls keystore password: ",
+	}
+}
+
+// ApplyNetwork fills the contract addresses and chain id the config doesn't set with the ones of the network preset.
+// A nil network leaves the config unchanged.
+func (c *NodeConfig) ApplyNetwork(network *config.Network) error {
+	if network == nil {
+		return nil
+	}
+	chainId, err := network.ResolveChainId(c.ChainId)
+	if err != nil {
+		return err
+	}
+	c.ChainId = chainId
+	for _, preset := range []struct {
+		setting *string
+		address string
+	}{
+		{&c.AVSRegistryCoordinatorAddress, network.RegistryCoordinator},
+		{&c.OperatorStateRetrieverAddress, network.OperatorStateRetriever},
+		{&c.TokenStrategyAddr, network.TokenStrategy},
+		{&c.AVSServiceManagerAddress, network.ServiceManager},
+	} {
+		if *preset.setting == "" {
+			*preset.setting = preset.address
+		}
+	}
+	return nil
+}
+
+// B7sConfig configures the embedded b7s node. Each setting replaces the default of the node flag of the same name (eg.
+// peer_db for --peer-db); unset (zero) settings keep the flag defaults.
+type B7sConfig struct {
+	// head or worker
+	Role       string `yaml:"role"`
+	PeerDB     string `yaml:"peer_db"`
+	FunctionDB string `yaml:"function_db"`
+	Workspace  string `yaml:"workspace"`
+	// directory of the blockless runtime executing the functions; required by worker nodes
+	RuntimePath        string  `yaml:"runtime_path"`
+	RuntimeCLI         string  `yaml:"runtime_cli"`
+	CPUPercentageLimit float64 `yaml:"cpu_percentage_limit"`
+	MemoryLimitKB      int64   `yaml:"memory_limit"`
+	Concurrency        uint    `yaml:"concurrency"`
+	LoadAttributes     bool    `yaml:"attributes"`
+	AttributesFile     string  `yaml:"attributes_file"`
+	PrivateKey         string  `yaml:"private_key"`
+	Address            string  `yaml:"address"`
+	Port               uint    `yaml:"port"`
+	// multiaddrs of the peers connected to on startup
+	BootNodes []string `yaml:"boot_nodes"`
+	// find the nodes of the local network with mDNS, advertising mdns_service (default blockless-avs)
+	Mdns        bool   `yaml:"mdns"`
+	MdnsService string `yaml:"mdns_service"`
+	// join a Kademlia DHT through the boot nodes and find the nodes advertised there (defaults /blockless-avs and
+	// blockless-avs)
+	Dht               bool   `yaml:"dht"`
+	DhtProtocolPrefix string `yaml:"dht_protocol_prefix"`
+	DhtNamespace      string `yaml:"dht_namespace"`
+	DialbackAddress   string `yaml:"dialback_address"`
+	DialbackPort      uint   `yaml:"dialback_port"`
+	// NAT traversal: answer the AutoNAT requests of the peers, hole punch the relayed connections and reserve a slot
+	// on the relays (multiaddrs) when unreachable
+	AutoNAT      bool     `yaml:"autonat"`
+	HolePunching bool     `yaml:"hole_punching"`
+	Relays       []string `yaml:"relays"`
+	// listen for QUIC connections on the UDP port quic_port, advertising quic_dialback_port with the dialback address
+	QUIC             bool `yaml:"quic"`
+	QUICPort         uint `yaml:"quic_port"`
+	QUICDialbackPort uint `yaml:"quic_dialback_port"`
+	// multiaddrs listened on besides address and the ports, and advertised instead of the listen and dialback ones
+	ListenAddresses   []string `yaml:"listen_addresses"`
+	AnnounceAddresses []string `yaml:"announce_addresses"`
+	// the connections of the least valuable peers are closed above conn_high_water, down to conn_low_water; new
+	// connections are kept for conn_grace_period (default 1m)
+	ConnLowWater    int           `yaml:"conn_low_water"`
+	ConnHighWater   int           `yaml:"conn_high_water"`
+	ConnGracePeriod time.Duration `yaml:"conn_grace_period"`
+	// peer ids and CIDRs of the only peers allowed to connect, and of the peers denied even when allowed
+	AllowPeers []string `yaml:"allow_peers"`
+	DenyPeers  []string `yaml:"deny_peers"`
+	// multiaddrs of the peers always kept connected, e.g. the operator's own nodes, unlike the boot nodes only dialed on
+	// startup
+	StaticPeers []string `yaml:"static_peers"`
+	// pre-shared key file of the private network to join, whose nodes only connect to each other
+	PrivateNetworkKey string `yaml:"private_network_key"`
+	// KiB per second exchanged with each peer in each direction, unlimited when 0
+	PeerBandwidthLimit uint `yaml:"peer_bandwidth_limit"`
+	// score the peers, banning the ones at or below peer_ban_threshold (default -100) for peer_ban_duration (default 1h);
+	// more than peer_message_rate (default 600) direct messages per minute are penalized
+	PeerScoring           bool          `yaml:"peer_scoring"`
+	PeerBanThreshold      int           `yaml:"peer_ban_threshold"`
+	PeerBanDuration       time.Duration `yaml:"peer_ban_duration"`
+	PeerMessageRate       int           `yaml:"peer_message_rate"`
+	Websocket             bool          `yaml:"websocket"`
+	WebsocketPort         uint          `yaml:"websocket_port"`
+	WebsocketDialbackPort uint          `yaml:"websocket_dialback_port"`
+	// serve the websocket transport over TLS with the certificate and key files, or with certificates obtained from
+	// Let's Encrypt for the autocert domains, cached in websocket_autocert_cache (default <workspace>/autocert)
+	WebsocketTLSCert       string    `yaml:"websocket_tls_cert"`
+	WebsocketTLSKey        string    `yaml:"websocket_tls_key"`
+	WebsocketAutocert      []string  `yaml:"websocket_autocert"`
+	WebsocketAutocertCache string    `yaml:"websocket_autocert_cache"`
+	LegacyProtocolUntil    time.Time `yaml:"legacy_protocol_until"`
+	DisableLegacyProtocol  bool      `yaml:"disable_legacy_protocol"`
+	// name=value attributes advertised by a worker along with its cpus, memory and runtime version
+	WorkerAttributes []string `yaml:"worker_attributes"`
+	// capabilities a worker must advertise for a head node to select it for the executions; memory in MiB, the
+	// runtime version matching when contained in the advertised one
+	ExecutorMinCPUs    int      `yaml:"executor_min_cpus"`
+	ExecutorMinMemory  uint64   `yaml:"executor_min_memory"`
+	ExecutorRuntime    string   `yaml:"executor_runtime"`
+	ExecutorAttributes []string `yaml:"executor_attributes"`
+	// workers a head node dispatches each execution to, comparing their results; disabled below 2
+	VerifyExecutions int `yaml:"verify_executions"`
+	// s3://bucket/prefix or ipfs, where a worker fetches the function bundles from, with the endpoint and region of the
+	// S3-compatible storage, the IPFS gateway, and the size (MiB) of the cache of the bundles
+	FunctionSource    string `yaml:"function_source"`
+	S3Endpoint        string `yaml:"s3_endpoint"`
+	S3Region          string `yaml:"s3_region"`
+	IPFSGateway       string `yaml:"ipfs_gateway"`
+	FunctionCacheSize int64  `yaml:"function_cache_size"`
+	// removal of the execution directories left in the workspace of a worker node
+	WorkspaceGC WorkspaceGCConfig `yaml:"workspace_gc"`
+	// adapts the number of functions a worker executes at once to the system load, within the bounds
+	AdaptiveConcurrency bool `yaml:"adaptive_concurrency"`
+	MinConcurrency      int  `yaml:"min_concurrency"`
+	MaxConcurrency      int  `yaml:"max_concurrency"`
+	// age above which the executions a head node restart left pending aren't re-dispatched (default 10m); negative not
+	// to persist them
+	JobMaxAge time.Duration `yaml:"job_max_age"`
+	// prefixed to the pubsub topics, so several AVSs or environments sharing boot nodes don't hear each other
+	TopicNamespace string `yaml:"topic_namespace"`
+	// writes the p2p node logs to a rotated file, like --log-file
+	LogFile logging.FileConfig `yaml:"log_file"`
+}
+
+// WorkspaceGCConfig configures the removal of the execution directories left in the workspace of a worker node, by the
+// executions interrupted by a stop.
+type WorkspaceGCConfig struct {
+	// directories older than this are removed (default 24h); kept regardless of age when negative
+	MaxAge time.Duration `yaml:"max_age"`
+	// the oldest directories are removed while their total size (MiB) is above this; unlimited when 0
+	MaxSize int64 `yaml:"max_size"`
+	// how often the directories are collected (default 10m)
+	Interval time.Duration `yaml:"interval"`
+}
+
+// DigestConfig configures the scheduled operator digests summarizing tasks signed, participation rate and missed tasks.
+type DigestConfig struct {
+	// daily or weekly (default daily)
+	Schedule string               `yaml:"schedule"`
+	Webhook  notify.WebhookConfig `yaml:"webhook"`
+}
+
+// StandbyConfig configures running several instances of the same operator, of which only one signs at a time.
+// Instances compete for a lease in a file shared between them; the holder renews it on every heartbeat and the
+// others take over once it expires, ie. after the holder missed heartbeats for the lease duration.
+type StandbyConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// lease file shared by all the instances (eg. on a shared volume)
+	LeaseFile string `yaml:"lease_file"`
+	// unique name of this instance (default hostname-pid)
+	InstanceId string `yaml:"instance_id"`
+	// the lease expires when not renewed for this long (default 30s)
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+	// how often the holder renews the lease and the standbys try to acquire it (default 10s)
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+}
+
+// ArtifactsConfig configures storing the artifacts (trace, errors and upstream response) of oracle update executions.
+type ArtifactsConfig struct {
+	// directory the artifacts are stored in; artifacts are not stored when empty
+	Dir string `yaml:"dir"`
+	// artifacts are deleted once they are older than this (default 72h)
+	Retention time.Duration `yaml:"retention"`
+}
+
+// WasmConfig configures executing tasks as Blockless WASM functions. Functions are installed from their manifest into
+// the workspace and run with the blockless runtime (bls-runtime) found in the runtime dir.
+type WasmConfig struct {
+	// directory containing the bls-runtime executable; wasm tasks are rejected when empty
+	RuntimeDir string `yaml:"runtime_dir"`
+	// directory the functions are installed and executed in
+	Workspace string `yaml:"workspace"`
+	// maximum execution time of a function (default 30s)
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ResponseQueueConfig configures the persistent queue of signed responses waiting to be delivered to the aggregator.
+// Undelivered responses are retried with exponential backoff until they are older than max_age.
+type ResponseQueueConfig struct {
+	// directory of the queue database; responses are sent directly, without being persisted, when empty
+	Dir string `yaml:"dir"`
+	// responses are dropped once their price timestamp is older than this (default 20m, the task challenge window)
+	MaxAge time.Duration `yaml:"max_age"`
+	// delay before the first retry, doubled on every failed attempt (default 1s)
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// maximum delay between retries (default 1m)
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// PolicyConfig restricts the tasks the operator signs. Tasks breaking any rule are not executed nor signed; unset rules
+// allow every task.
+type PolicyConfig struct {
+	// indices of the aggregator tasks which are never signed
+	DeniedTaskIndices []uint32 `yaml:"denied_task_indices"`
+	// maximum size in bytes of the task input (symbol and function reference, json encoded)
+	MaxInputSize int `yaml:"max_input_size"`
+	// cids of the wasm functions which may be executed
+	AllowedFunctionCids []string `yaml:"allowed_function_cids"`
+	// quorums the operator must be registered in to sign
+	RequiredQuorums []uint8 `yaml:"required_quorums"`
+}
+
+// ReregistrationConfig configures re-registering the operator in the quorums it was ejected from. Attempts are
+// delayed with exponential backoff, and postponed while the quorums are full since registering would require churning
+// out another operator. An alert is sent to the digest webhook when manual action is required, ie. the operator stake
+// is below the quorum minimum stake or max_attempts failed.
+type ReregistrationConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// attempts before giving up (default 5)
+	MaxAttempts int `yaml:"max_attempts"`
+	// delay before the first attempt, doubled after every failed attempt (default 1m)
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// maximum delay between attempts (default 1h)
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+}
+
+// AggregatorsConfig configures submitting the signed responses to backup aggregators besides the primary one.
+type AggregatorsConfig struct {
+	// rpc addresses of the backup aggregators, in order of preference
+	Backups []string `yaml:"backups"`
+	// failover submits to the first healthy endpoint, trying the next one on error; fanout submits to all endpoints
+	// (default failover)
+	Mode string `yaml:"mode"`
+	// how often the endpoints are health checked (default 10s)
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
 }